@@ -49,11 +49,15 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	}
 
 	dst.Spec.NetworkSpec.PrivateDNSZoneName = restored.Spec.NetworkSpec.PrivateDNSZoneName
+	dst.Spec.NetworkSpec.PrivateDNSZoneResourceGroup = restored.Spec.NetworkSpec.PrivateDNSZoneResourceGroup
+	dst.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID = restored.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID
 
 	dst.Spec.NetworkSpec.APIServerLB.FrontendIPsCount = restored.Spec.NetworkSpec.APIServerLB.FrontendIPsCount
 	dst.Spec.NetworkSpec.APIServerLB.IdleTimeoutInMinutes = restored.Spec.NetworkSpec.APIServerLB.IdleTimeoutInMinutes
 	dst.Spec.CloudProviderConfigOverrides = restored.Spec.CloudProviderConfigOverrides
 	dst.Spec.BastionSpec = restored.Spec.BastionSpec
+	dst.Spec.SecondaryIdentityRef = restored.Spec.SecondaryIdentityRef
+	dst.Spec.KeyVault = restored.Spec.KeyVault
 
 	// set default control plane outbound lb for private v1alpha3 clusters
 	if src.Spec.NetworkSpec.APIServerLB.Type == Internal && restored.Spec.NetworkSpec.ControlPlaneOutboundLB == nil {
@@ -98,6 +102,8 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	// Restore list of virtual network peerings
 	dst.Spec.NetworkSpec.Vnet.Peerings = restored.Spec.NetworkSpec.Vnet.Peerings
 
+	dst.Spec.NetworkSpec.Vnet.SubscriptionID = restored.Spec.NetworkSpec.Vnet.SubscriptionID
+
 	return nil
 }
 
@@ -69,6 +69,8 @@ func (src *AzureClusterIdentity) ConvertTo(dstRaw conversion.Hub) error { // nol
 	}
 	dst.OwnerReferences = restoredOwnerReferences
 
+	dst.Status.SecretExpiryTime = restored.Status.SecretExpiryTime
+
 	return nil
 }
 
@@ -110,3 +112,8 @@ func Convert_v1beta1_AzureClusterIdentitySpec_To_v1alpha3_AzureClusterIdentitySp
 
 	return nil
 }
+
+// Convert_v1beta1_AzureClusterIdentityStatus_To_v1alpha3_AzureClusterIdentityStatus converts from the Hub version (v1beta1) of the AzureClusterIdentityStatus to this version.
+func Convert_v1beta1_AzureClusterIdentityStatus_To_v1alpha3_AzureClusterIdentityStatus(in *infrav1beta1.AzureClusterIdentityStatus, out *AzureClusterIdentityStatus, s apiconversion.Scope) error { // nolint
+	return autoConvert_v1beta1_AzureClusterIdentityStatus_To_v1alpha3_AzureClusterIdentityStatus(in, out, s)
+}
@@ -52,6 +52,13 @@ func (src *AzureMachine) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	}
 
 	dst.Spec.SubnetName = restored.Spec.SubnetName
+	dst.Spec.NetworkInterfaces = restored.Spec.NetworkInterfaces
+	dst.Spec.Diagnostics = restored.Spec.Diagnostics
+	dst.Spec.RoleAssignments = restored.Spec.RoleAssignments
+	if restored.Spec.SpotVMOptions != nil && dst.Spec.SpotVMOptions != nil {
+		dst.Spec.SpotVMOptions.EvictionPolicy = restored.Spec.SpotVMOptions.EvictionPolicy
+		dst.Spec.SpotVMOptions.RecoveryPolicy = restored.Spec.SpotVMOptions.RecoveryPolicy
+	}
 
 	dst.Status.LongRunningOperationStates = restored.Status.LongRunningOperationStates
 
@@ -169,3 +176,13 @@ func Convert_v1beta1_ManagedDiskParameters_To_v1alpha3_ManagedDisk(in *v1beta1.M
 	out.DiskEncryptionSet = (*DiskEncryptionSetParameters)(in.DiskEncryptionSet)
 	return nil
 }
+
+// Convert_v1beta1_SpotVMOptions_To_v1alpha3_SpotVMOptions converts from the Hub version (v1beta1) of the SpotVMOptions to this version.
+func Convert_v1beta1_SpotVMOptions_To_v1alpha3_SpotVMOptions(in *v1beta1.SpotVMOptions, out *SpotVMOptions, s apiconversion.Scope) error { // nolint
+	return autoConvert_v1beta1_SpotVMOptions_To_v1alpha3_SpotVMOptions(in, out, s)
+}
+
+// Convert_v1alpha3_SpotVMOptions_To_v1beta1_SpotVMOptions converts this SpotVMOptions to the Hub version (v1beta1).
+func Convert_v1alpha3_SpotVMOptions_To_v1beta1_SpotVMOptions(in *SpotVMOptions, out *v1beta1.SpotVMOptions, s apiconversion.Scope) error { // nolint
+	return autoConvert_v1alpha3_SpotVMOptions_To_v1beta1_SpotVMOptions(in, out, s)
+}
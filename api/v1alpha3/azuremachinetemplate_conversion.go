@@ -52,7 +52,15 @@ func (src *AzureMachineTemplate) ConvertTo(dstRaw conversion.Hub) error { // nol
 	}
 
 	dst.Spec.Template.Spec.SubnetName = restored.Spec.Template.Spec.SubnetName
+	dst.Spec.Template.Spec.NetworkInterfaces = restored.Spec.Template.Spec.NetworkInterfaces
+	dst.Spec.Template.Spec.Diagnostics = restored.Spec.Template.Spec.Diagnostics
+	dst.Spec.Template.Spec.RoleAssignments = restored.Spec.Template.Spec.RoleAssignments
+	if restored.Spec.Template.Spec.SpotVMOptions != nil && dst.Spec.Template.Spec.SpotVMOptions != nil {
+		dst.Spec.Template.Spec.SpotVMOptions.EvictionPolicy = restored.Spec.Template.Spec.SpotVMOptions.EvictionPolicy
+		dst.Spec.Template.Spec.SpotVMOptions.RecoveryPolicy = restored.Spec.Template.Spec.SpotVMOptions.RecoveryPolicy
+	}
 	dst.Spec.Template.ObjectMeta = restored.Spec.Template.ObjectMeta
+	dst.Status = restored.Status
 
 	return nil
 }
@@ -98,3 +106,8 @@ func Convert_v1beta1_AzureMachineTemplateResource_To_v1alpha3_AzureMachineTempla
 	}
 	return nil
 }
+
+// Convert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate converts an AzureMachineTemplate from v1beta1 to v1alpha3.
+func Convert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(in *infrav1beta1.AzureMachineTemplate, out *AzureMachineTemplate, s apimachineryconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha3_AzureMachineTemplate(in, out, s)
+}
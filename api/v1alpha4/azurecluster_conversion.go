@@ -40,6 +40,12 @@ func (src *AzureCluster) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	// Restore list of virtual network peerings
 	dst.Spec.NetworkSpec.Vnet.Peerings = restored.Spec.NetworkSpec.Vnet.Peerings
 
+	dst.Spec.NetworkSpec.Vnet.SubscriptionID = restored.Spec.NetworkSpec.Vnet.SubscriptionID
+	dst.Spec.NetworkSpec.PrivateDNSZoneResourceGroup = restored.Spec.NetworkSpec.PrivateDNSZoneResourceGroup
+	dst.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID = restored.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID
+	dst.Spec.SecondaryIdentityRef = restored.Spec.SecondaryIdentityRef
+	dst.Spec.KeyVault = restored.Spec.KeyVault
+
 	return nil
 }
 
@@ -70,6 +76,16 @@ func (dst *AzureClusterList) ConvertFrom(srcRaw conversion.Hub) error { // nolin
 	return Convert_v1beta1_AzureClusterList_To_v1alpha4_AzureClusterList(src, dst, nil)
 }
 
+// Convert_v1beta1_AzureClusterSpec_To_v1alpha4_AzureClusterSpec converts from the Hub version (v1beta1) of the AzureClusterSpec to this version.
+func Convert_v1beta1_AzureClusterSpec_To_v1alpha4_AzureClusterSpec(in *infrav1beta1.AzureClusterSpec, out *AzureClusterSpec, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureClusterSpec_To_v1alpha4_AzureClusterSpec(in, out, s)
+}
+
+// Convert_v1beta1_NetworkSpec_To_v1alpha4_NetworkSpec converts from the Hub version (v1beta1) of the NetworkSpec to this version.
+func Convert_v1beta1_NetworkSpec_To_v1alpha4_NetworkSpec(in *infrav1beta1.NetworkSpec, out *NetworkSpec, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_NetworkSpec_To_v1alpha4_NetworkSpec(in, out, s)
+}
+
 // Convert_v1beta1_VnetSpec_To_v1alpha4_VnetSpec.
 func Convert_v1beta1_VnetSpec_To_v1alpha4_VnetSpec(in *infrav1beta1.VnetSpec, out *VnetSpec, s apiconversion.Scope) error { //nolint
 	return autoConvert_v1beta1_VnetSpec_To_v1alpha4_VnetSpec(in, out, s)
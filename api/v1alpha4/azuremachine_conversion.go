@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha4
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	"sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
@@ -25,13 +27,40 @@ import (
 func (src *AzureMachine) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	dst := dstRaw.(*v1beta1.AzureMachine)
 
-	return Convert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(src, dst, nil)
+	if err := Convert_v1alpha4_AzureMachine_To_v1beta1_AzureMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Manually restore data.
+	restored := &v1beta1.AzureMachine{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.NetworkInterfaces = restored.Spec.NetworkInterfaces
+	dst.Spec.Diagnostics = restored.Spec.Diagnostics
+	dst.Spec.RoleAssignments = restored.Spec.RoleAssignments
+	if restored.Spec.SpotVMOptions != nil && dst.Spec.SpotVMOptions != nil {
+		dst.Spec.SpotVMOptions.EvictionPolicy = restored.Spec.SpotVMOptions.EvictionPolicy
+		dst.Spec.SpotVMOptions.RecoveryPolicy = restored.Spec.SpotVMOptions.RecoveryPolicy
+	}
+
+	return nil
 }
 
 // ConvertFrom converts from the Hub version (v1beta1) to this version.
 func (dst *AzureMachine) ConvertFrom(srcRaw conversion.Hub) error { // nolint
 	src := srcRaw.(*v1beta1.AzureMachine)
-	return Convert_v1beta1_AzureMachine_To_v1alpha4_AzureMachine(src, dst, nil)
+	if err := Convert_v1beta1_AzureMachine_To_v1alpha4_AzureMachine(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion.
+	if err := utilconversion.MarshalData(src, dst); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 // ConvertTo converts this AzureMachineList to the Hub version (v1beta1).
@@ -45,3 +74,18 @@ func (dst *AzureMachineList) ConvertFrom(srcRaw conversion.Hub) error { // nolin
 	src := srcRaw.(*v1beta1.AzureMachineList)
 	return Convert_v1beta1_AzureMachineList_To_v1alpha4_AzureMachineList(src, dst, nil)
 }
+
+// Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec converts from the Hub version (v1beta1) of the AzureMachineSpec to this version.
+func Convert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in *v1beta1.AzureMachineSpec, out *AzureMachineSpec, s apiconversion.Scope) error { // nolint
+	return autoConvert_v1beta1_AzureMachineSpec_To_v1alpha4_AzureMachineSpec(in, out, s)
+}
+
+// Convert_v1beta1_SpotVMOptions_To_v1alpha4_SpotVMOptions converts from the Hub version (v1beta1) of the SpotVMOptions to this version.
+func Convert_v1beta1_SpotVMOptions_To_v1alpha4_SpotVMOptions(in *v1beta1.SpotVMOptions, out *SpotVMOptions, s apiconversion.Scope) error { // nolint
+	return autoConvert_v1beta1_SpotVMOptions_To_v1alpha4_SpotVMOptions(in, out, s)
+}
+
+// Convert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions converts this SpotVMOptions to the Hub version (v1beta1).
+func Convert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions(in *SpotVMOptions, out *v1beta1.SpotVMOptions, s apiconversion.Scope) error { // nolint
+	return autoConvert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions(in, out, s)
+}
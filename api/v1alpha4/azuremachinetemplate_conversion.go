@@ -38,6 +38,14 @@ func (src *AzureMachineTemplate) ConvertTo(dstRaw conversion.Hub) error { // nol
 	}
 
 	dst.Spec.Template.ObjectMeta = restored.Spec.Template.ObjectMeta
+	dst.Spec.Template.Spec.NetworkInterfaces = restored.Spec.Template.Spec.NetworkInterfaces
+	dst.Spec.Template.Spec.Diagnostics = restored.Spec.Template.Spec.Diagnostics
+	dst.Spec.Template.Spec.RoleAssignments = restored.Spec.Template.Spec.RoleAssignments
+	if restored.Spec.Template.Spec.SpotVMOptions != nil && dst.Spec.Template.Spec.SpotVMOptions != nil {
+		dst.Spec.Template.Spec.SpotVMOptions.EvictionPolicy = restored.Spec.Template.Spec.SpotVMOptions.EvictionPolicy
+		dst.Spec.Template.Spec.SpotVMOptions.RecoveryPolicy = restored.Spec.Template.Spec.SpotVMOptions.RecoveryPolicy
+	}
+	dst.Status = restored.Status
 
 	return nil
 }
@@ -75,3 +83,8 @@ func Convert_v1beta1_AzureMachineTemplateResource_To_v1alpha4_AzureMachineTempla
 	}
 	return nil
 }
+
+// Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate converts an AzureMachineTemplate from v1beta1 to v1alpha4.
+func Convert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(in *infrav1beta1.AzureMachineTemplate, out *AzureMachineTemplate, s apimachineryconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureMachineTemplate_To_v1alpha4_AzureMachineTemplate(in, out, s)
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 
 	"k8s.io/utils/pointer"
@@ -49,6 +51,7 @@ func (c *AzureCluster) setDefaults() {
 	c.setResourceGroupDefault()
 	c.setAzureEnvironmentDefault()
 	c.setNetworkSpecDefaults()
+	c.setKeyVaultDefaults()
 }
 
 func (c *AzureCluster) setNetworkSpecDefaults() {
@@ -67,6 +70,16 @@ func (c *AzureCluster) setResourceGroupDefault() {
 	}
 }
 
+// setKeyVaultDefaults sets the name of the Key Vault, which must be globally unique and at most 24 characters long, to
+// a hash of the resource group and the name of the cluster.
+func (c *AzureCluster) setKeyVaultDefaults() {
+	if c.Spec.KeyVault == nil || c.Spec.KeyVault.Name != "" {
+		return
+	}
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s", c.Spec.ResourceGroup, c.Name)))
+	c.Spec.KeyVault.Name = fmt.Sprintf("kv-%s", hex.EncodeToString(hash[:])[:20])
+}
+
 func (c *AzureCluster) setAzureEnvironmentDefault() {
 	if c.Spec.AzureEnvironment == "" {
 		c.Spec.AzureEnvironment = DefaultAzureCloud
@@ -151,9 +164,23 @@ func (c *AzureCluster) setSubnetDefaults() {
 }
 
 func (c *AzureCluster) setVnetPeeringDefaults() {
-	for i, peering := range c.Spec.NetworkSpec.Vnet.Peerings {
+	for i := range c.Spec.NetworkSpec.Vnet.Peerings {
+		peering := &c.Spec.NetworkSpec.Vnet.Peerings[i]
+		if peering.RemoteVnetID != "" {
+			if remoteVnet, err := parseVnetID(peering.RemoteVnetID); err == nil {
+				if peering.SubscriptionID == "" {
+					peering.SubscriptionID = remoteVnet.SubscriptionID
+				}
+				if peering.ResourceGroup == "" {
+					peering.ResourceGroup = remoteVnet.ResourceGroup
+				}
+				if peering.RemoteVnetName == "" {
+					peering.RemoteVnetName = remoteVnet.ResourceName
+				}
+			}
+		}
 		if peering.ResourceGroup == "" {
-			c.Spec.NetworkSpec.Vnet.Peerings[i].ResourceGroup = c.Spec.ResourceGroup
+			peering.ResourceGroup = c.Spec.ResourceGroup
 		}
 	}
 }
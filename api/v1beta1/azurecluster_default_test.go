@@ -723,6 +723,46 @@ func TestVnetPeeringDefaults(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "peering with remote vnet ID",
+			cluster: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster-test",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "cluster-test",
+					NetworkSpec: NetworkSpec{
+						Vnet: VnetSpec{
+							Peerings: VnetPeerings{
+								{
+									RemoteVnetID: "/subscriptions/hub-sub/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet",
+								},
+							},
+						},
+					},
+				},
+			},
+			output: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster-test",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "cluster-test",
+					NetworkSpec: NetworkSpec{
+						Vnet: VnetSpec{
+							Peerings: VnetPeerings{
+								{
+									RemoteVnetID:   "/subscriptions/hub-sub/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet",
+									SubscriptionID: "hub-sub",
+									ResourceGroup:  "hub-rg",
+									RemoteVnetName: "hub-vnet",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -1725,3 +1765,91 @@ func TestBastionDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyVaultDefaults(t *testing.T) {
+	cases := map[string]struct {
+		cluster *AzureCluster
+		output  *AzureCluster
+	}{
+		"no Key Vault set": {
+			cluster: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "foo",
+				},
+			},
+			output: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "foo",
+				},
+			},
+		},
+		"Key Vault with no name": {
+			cluster: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "foo",
+					KeyVault: &KeyVaultSpec{
+						BootstrapData: true,
+					},
+				},
+			},
+			output: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "foo",
+					KeyVault: &KeyVaultSpec{
+						Name:          "kv-eb4c73402a1b93e10d81",
+						BootstrapData: true,
+					},
+				},
+			},
+		},
+		"Key Vault with name set": {
+			cluster: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "foo",
+					KeyVault: &KeyVaultSpec{
+						Name: "my-vault",
+					},
+				},
+			},
+			output: &AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "foo",
+				},
+				Spec: AzureClusterSpec{
+					ResourceGroup: "foo",
+					KeyVault: &KeyVaultSpec{
+						Name: "my-vault",
+					},
+				},
+			},
+		},
+	}
+
+	for name := range cases {
+		c := cases[name]
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c.cluster.setKeyVaultDefaults()
+			if !reflect.DeepEqual(c.cluster, c.output) {
+				expected, _ := json.MarshalIndent(c.output, "", "\t")
+				actual, _ := json.MarshalIndent(c.cluster, "", "\t")
+				t.Errorf("Expected %s, got %s", string(expected), string(actual))
+			}
+		})
+	}
+}
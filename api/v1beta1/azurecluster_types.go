@@ -59,6 +59,12 @@ type AzureClusterSpec struct {
 	// +optional
 	IdentityRef *corev1.ObjectReference `json:"identityRef,omitempty"`
 
+	// SecondaryIdentityRef is a reference to the identity used to reconcile the resources of the cluster in other
+	// subscriptions than SubscriptionID, i.e. the remote side of the virtual network peerings and the private DNS zone.
+	// Defaults to IdentityRef.
+	// +optional
+	SecondaryIdentityRef *corev1.ObjectReference `json:"secondaryIdentityRef,omitempty"`
+
 	// AzureEnvironment is the name of the AzureCloud to be used.
 	// The default value that would be used by most users is "AzurePublicCloud", other values are:
 	// - ChinaCloud: "AzureChinaCloud"
@@ -79,6 +85,12 @@ type AzureClusterSpec struct {
 	// Note: All cloud provider config values can be customized by creating the secret beforehand. CloudProviderConfigOverrides is only used when the secret is managed by the Azure Provider.
 	// +optional
 	CloudProviderConfigOverrides *CloudProviderConfigOverrides `json:"cloudProviderConfigOverrides,omitempty"`
+
+	// KeyVault is the Key Vault storing the bootstrap data and the cloud provider config of the machines of the
+	// cluster. The machines not having a managed identity get a system-assigned one to fetch them. Only AzureMachines
+	// running Linux are supported: the other machines keep getting their secrets in their custom data.
+	// +optional
+	KeyVault *KeyVaultSpec `json:"keyVault,omitempty"`
 }
 
 // AzureClusterStatus defines the observed state of AzureCluster.
@@ -17,17 +17,22 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"reflect"
 	"regexp"
+	"strings"
 
 	"k8s.io/utils/pointer"
 
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
 	valid "github.com/asaskevich/govalidator"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -42,6 +47,8 @@ const (
 	// described in https://docs.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules.
 	subnetRegex       = `^[-\w\._]+$`
 	loadBalancerRegex = `^[-\w\._]+$`
+	// described in https://docs.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules#microsoftkeyvault.
+	keyVaultRegex = `^[a-zA-Z][a-zA-Z0-9-]{1,22}[a-zA-Z0-9]$`
 	// MaxLoadBalancerOutboundIPs is the maximum number of outbound IPs in a Standard LoadBalancer frontend configuration.
 	MaxLoadBalancerOutboundIPs = 16
 	// MinLBIdleTimeoutInMinutes is the minimum number of minutes for the LB idle timeout.
@@ -84,9 +91,90 @@ func (c *AzureCluster) validateClusterSpec(old *AzureCluster) field.ErrorList {
 	allErrs = append(allErrs, validateCloudProviderConfigOverrides(c.Spec.CloudProviderConfigOverrides, oldCloudProviderConfigOverrides,
 		field.NewPath("spec").Child("cloudProviderConfigOverrides"))...)
 
+	allErrs = append(allErrs, validateKeyVault(c.Spec.KeyVault, field.NewPath("spec").Child("keyVault"))...)
+
+	allErrs = append(allErrs, ValidateIdentityRef(c.Spec.IdentityRef, c.Namespace, field.NewPath("spec").Child("identityRef"))...)
+	allErrs = append(allErrs, ValidateIdentityRef(c.Spec.SecondaryIdentityRef, c.Namespace, field.NewPath("spec").Child("secondaryIdentityRef"))...)
+
+	return allErrs
+}
+
+// ValidateIdentityRef validates the reference of a cluster in the namespace to its identity. An AzureNamespacedIdentity
+// can only be referenced from its own namespace.
+func ValidateIdentityRef(ref *corev1.ObjectReference, namespace string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ref == nil || ref.Kind != AzureNamespacedIdentityKind {
+		return allErrs
+	}
+
+	if ref.Namespace != "" && namespace != "" && ref.Namespace != namespace {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("namespace"), ref.Namespace,
+			fmt.Sprintf("an %s can only be referenced from its own namespace %s", AzureNamespacedIdentityKind, namespace)))
+	}
+	return allErrs
+}
+
+// validateIdentities validates that the identities of the cluster can get tokens for the tenant of each other when the
+// secondary identity is in another tenant, which only the identities authenticating with a client secret or a
+// certificate support. Identities which do not exist yet are validated when the cluster is reconciled.
+func (c *AzureCluster) validateIdentities(cli client.Client) error {
+	if c.Spec.IdentityRef == nil || c.Spec.SecondaryIdentityRef == nil {
+		return nil
+	}
+
+	identity, err := getIdentityFromRef(cli, c.Namespace, c.Spec.IdentityRef)
+	if err != nil || identity == nil {
+		return err
+	}
+	secondaryIdentity, err := getIdentityFromRef(cli, c.Namespace, c.Spec.SecondaryIdentityRef)
+	if err != nil || secondaryIdentity == nil {
+		return err
+	}
+
+	var allErrs field.ErrorList
+	if identity.Spec.TenantID != secondaryIdentity.Spec.TenantID {
+		allErrs = append(allErrs, validateAuxiliaryTenantIdentity(identity, field.NewPath("spec", "identityRef"))...)
+		allErrs = append(allErrs, validateAuxiliaryTenantIdentity(secondaryIdentity, field.NewPath("spec", "secondaryIdentityRef"))...)
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("AzureCluster").GroupKind(), c.Name, allErrs)
+}
+
+// validateAuxiliaryTenantIdentity validates that an identity can get tokens for an auxiliary tenant.
+func validateAuxiliaryTenantIdentity(identity *AzureClusterIdentity, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if identity.Spec.Type != ManualServicePrincipal && identity.Spec.Type != ServicePrincipalCertificate {
+		allErrs = append(allErrs, field.Forbidden(fldPath,
+			fmt.Sprintf("identity %s/%s of type %s cannot be used with an identity of another tenant, only %s and %s identities can",
+				identity.Namespace, identity.Name, identity.Spec.Type, ManualServicePrincipal, ServicePrincipalCertificate)))
+	}
 	return allErrs
 }
 
+// getIdentityFromRef returns the identity referenced by an AzureCluster, or nil if it does not exist.
+func getIdentityFromRef(cli client.Client, namespace string, ref *corev1.ObjectReference) (*AzureClusterIdentity, error) {
+	key := client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	if key.Namespace == "" {
+		key.Namespace = namespace
+	}
+
+	if ref.Kind == AzureNamespacedIdentityKind {
+		identity := &AzureNamespacedIdentity{}
+		if err := cli.Get(context.Background(), key, identity); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return identity.ToClusterIdentity(), nil
+	}
+
+	identity := &AzureClusterIdentity{}
+	if err := cli.Get(context.Background(), key, identity); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	return identity, nil
+}
+
 // validateClusterName validates ClusterName.
 func (c *AzureCluster) validateClusterName() field.ErrorList {
 	var allErrs field.ErrorList
@@ -263,8 +351,13 @@ func validateVnetPeerings(peerings VnetPeerings, fldPath *field.Path) field.Erro
 	var allErrs field.ErrorList
 	vnetIdentifiers := make(map[string]bool, len(peerings))
 
-	for _, peering := range peerings {
+	for i, peering := range peerings {
+		allErrs = append(allErrs, validateVnetPeering(peering, fldPath.Index(i))...)
+
 		vnetIdentifier := peering.ResourceGroup + "/" + peering.RemoteVnetName
+		if peering.SubscriptionID != "" {
+			vnetIdentifier = peering.SubscriptionID + "/" + vnetIdentifier
+		}
 		if _, ok := vnetIdentifiers[vnetIdentifier]; ok {
 			allErrs = append(allErrs, field.Duplicate(fldPath, vnetIdentifier))
 		}
@@ -273,6 +366,65 @@ func validateVnetPeerings(peerings VnetPeerings, fldPath *field.Path) field.Erro
 	return allErrs
 }
 
+// validateVnetPeering validates the remote virtual network and the properties of a virtual network peering.
+func validateVnetPeering(peering VnetPeeringSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if peering.RemoteVnetID == "" {
+		if peering.RemoteVnetName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("remoteVnetName"), "either remoteVnetName or remoteVnetID must be set"))
+		}
+	} else if remoteVnet, err := parseVnetID(peering.RemoteVnetID); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("remoteVnetID"), peering.RemoteVnetID, err.Error()))
+	} else {
+		for _, f := range []struct {
+			name, value, expected string
+		}{
+			{"subscriptionID", peering.SubscriptionID, remoteVnet.SubscriptionID},
+			{"resourceGroup", peering.ResourceGroup, remoteVnet.ResourceGroup},
+			{"remoteVnetName", peering.RemoteVnetName, remoteVnet.ResourceName},
+		} {
+			if f.value != "" && !strings.EqualFold(f.value, f.expected) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child(f.name), f.value, "must match remoteVnetID"))
+			}
+		}
+	}
+
+	forward, reverse := peering.ForwardPeeringProperties, peering.ReversePeeringProperties
+	allErrs = append(allErrs, validateVnetPeeringProperties(forward, fldPath.Child("forwardPeeringProperties"))...)
+	allErrs = append(allErrs, validateVnetPeeringProperties(reverse, fldPath.Child("reversePeeringProperties"))...)
+	if pointer.BoolDeref(forward.UseRemoteGateways, false) && !pointer.BoolDeref(reverse.AllowGatewayTransit, false) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("reversePeeringProperties", "allowGatewayTransit"), reverse.AllowGatewayTransit,
+			"must be true when forwardPeeringProperties.useRemoteGateways is true"))
+	}
+	if pointer.BoolDeref(reverse.UseRemoteGateways, false) && !pointer.BoolDeref(forward.AllowGatewayTransit, false) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("forwardPeeringProperties", "allowGatewayTransit"), forward.AllowGatewayTransit,
+			"must be true when reversePeeringProperties.useRemoteGateways is true"))
+	}
+	return allErrs
+}
+
+// validateVnetPeeringProperties validates the properties of one direction of a virtual network peering.
+func validateVnetPeeringProperties(properties VnetPeeringProperties, fldPath *field.Path) field.ErrorList {
+	if pointer.BoolDeref(properties.AllowGatewayTransit, false) && pointer.BoolDeref(properties.UseRemoteGateways, false) {
+		return field.ErrorList{field.Forbidden(fldPath.Child("useRemoteGateways"), "cannot be true when allowGatewayTransit is true")}
+	}
+	return nil
+}
+
+// parseVnetID parses the Azure resource ID of a virtual network.
+func parseVnetID(id string) (azureautorest.Resource, error) {
+	resource, err := azureautorest.ParseResourceID(id)
+	if err != nil {
+		return azureautorest.Resource{}, err
+	}
+	if !strings.EqualFold(resource.Provider, "Microsoft.Network") || !strings.EqualFold(resource.ResourceType, "virtualNetworks") ||
+		!strings.HasSuffix(strings.ToLower(id), strings.ToLower("/virtualNetworks/"+resource.ResourceName)) {
+		return azureautorest.Resource{}, fmt.Errorf("%s is not the ID of a virtual network", id)
+	}
+	return resource, nil
+}
+
 // validateLoadBalancerName validates the Name of a Load Balancer.
 func validateLoadBalancerName(name string, fldPath *field.Path) *field.Error {
 	if success, _ := regexp.Match(loadBalancerRegex, []byte(name)); !success {
@@ -490,6 +642,20 @@ func validatePrivateDNSZoneName(networkSpec NetworkSpec, fldPath *field.Path) fi
 	return allErrs
 }
 
+// validateKeyVault validates the name of the Key Vault of a cluster.
+func validateKeyVault(keyVault *KeyVaultSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if keyVault == nil || keyVault.Name == "" {
+		return allErrs
+	}
+
+	if success, _ := regexp.MatchString(keyVaultRegex, keyVault.Name); !success || strings.Contains(keyVault.Name, "--") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), keyVault.Name,
+			fmt.Sprintf("name of Key Vault doesn't match regex %s or contains consecutive hyphens", keyVaultRegex)))
+	}
+	return allErrs
+}
+
 // validateCloudProviderConfigOverrides validates CloudProviderConfigOverrides.
 func validateCloudProviderConfigOverrides(old, new *CloudProviderConfigOverrides, fldPath *field.Path) field.ErrorList {
 	var allErrs field.ErrorList
@@ -22,6 +22,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
@@ -1302,3 +1303,220 @@ func createValidAPIServerInternalLB() LoadBalancerSpec {
 		Type: Internal,
 	}
 }
+
+func TestValidateVnetPeerings(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name        string
+		peerings    VnetPeerings
+		wantErrType field.ErrorType
+	}{
+		{
+			name: "distinct remote vnets",
+			peerings: VnetPeerings{
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1"},
+				{ResourceGroup: "rg", RemoteVnetName: "vnet2"},
+			},
+		},
+		{
+			name: "same remote vnet twice",
+			peerings: VnetPeerings{
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1"},
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1"},
+			},
+			wantErrType: field.ErrorTypeDuplicate,
+		},
+		{
+			name: "remote vnets with the same name in different subscriptions",
+			peerings: VnetPeerings{
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1"},
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1", SubscriptionID: "hub-sub"},
+			},
+		},
+		{
+			name: "same remote vnet twice in another subscription",
+			peerings: VnetPeerings{
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1", SubscriptionID: "hub-sub"},
+				{ResourceGroup: "rg", RemoteVnetName: "vnet1", SubscriptionID: "hub-sub"},
+			},
+			wantErrType: field.ErrorTypeDuplicate,
+		},
+		{
+			name: "remote vnet referenced by ID",
+			peerings: VnetPeerings{
+				{
+					RemoteVnetID:   "/subscriptions/hub-sub/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet",
+					SubscriptionID: "hub-sub",
+					ResourceGroup:  "hub-rg",
+					RemoteVnetName: "hub-vnet",
+				},
+			},
+		},
+		{
+			name: "remote vnet without name or ID",
+			peerings: VnetPeerings{
+				{ResourceGroup: "rg"},
+			},
+			wantErrType: field.ErrorTypeRequired,
+		},
+		{
+			name: "remote vnet ID of a subnet",
+			peerings: VnetPeerings{
+				{RemoteVnetID: "/subscriptions/hub-sub/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet/subnets/gateway"},
+			},
+			wantErrType: field.ErrorTypeInvalid,
+		},
+		{
+			name: "remote vnet name not matching the remote vnet ID",
+			peerings: VnetPeerings{
+				{
+					RemoteVnetID:   "/subscriptions/hub-sub/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet",
+					RemoteVnetName: "other-vnet",
+				},
+			},
+			wantErrType: field.ErrorTypeInvalid,
+		},
+		{
+			name: "spoke vnet using the gateway of the hub vnet",
+			peerings: VnetPeerings{
+				{
+					ResourceGroup:            "rg",
+					RemoteVnetName:           "hub-vnet",
+					ForwardPeeringProperties: VnetPeeringProperties{AllowForwardedTraffic: pointer.Bool(true), UseRemoteGateways: pointer.Bool(true)},
+					ReversePeeringProperties: VnetPeeringProperties{AllowForwardedTraffic: pointer.Bool(true), AllowGatewayTransit: pointer.Bool(true)},
+				},
+			},
+		},
+		{
+			name: "using remote gateways without gateway transit in the other direction",
+			peerings: VnetPeerings{
+				{
+					ResourceGroup:            "rg",
+					RemoteVnetName:           "hub-vnet",
+					ForwardPeeringProperties: VnetPeeringProperties{UseRemoteGateways: pointer.Bool(true)},
+				},
+			},
+			wantErrType: field.ErrorTypeInvalid,
+		},
+		{
+			name: "allowing gateway transit and using remote gateways in the same direction",
+			peerings: VnetPeerings{
+				{
+					ResourceGroup:            "rg",
+					RemoteVnetName:           "hub-vnet",
+					ForwardPeeringProperties: VnetPeeringProperties{AllowGatewayTransit: pointer.Bool(true)},
+					ReversePeeringProperties: VnetPeeringProperties{AllowGatewayTransit: pointer.Bool(true), UseRemoteGateways: pointer.Bool(true)},
+				},
+			},
+			wantErrType: field.ErrorTypeForbidden,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateVnetPeerings(tc.peerings, field.NewPath("spec", "networkSpec", "vnet", "peerings"))
+			if tc.wantErrType != "" {
+				g.Expect(errs).To(HaveLen(1))
+				g.Expect(errs[0].Type).To(Equal(tc.wantErrType))
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateIdentityRef(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		ref     *corev1.ObjectReference
+		wantErr bool
+	}{
+		{
+			name:    "no identity",
+			wantErr: false,
+		},
+		{
+			name:    "cluster identity in another namespace",
+			ref:     &corev1.ObjectReference{Kind: AzureClusterIdentityKind, Name: "identity", Namespace: "other"},
+			wantErr: false,
+		},
+		{
+			name:    "namespaced identity without namespace",
+			ref:     &corev1.ObjectReference{Kind: AzureNamespacedIdentityKind, Name: "identity"},
+			wantErr: false,
+		},
+		{
+			name:    "namespaced identity in the same namespace",
+			ref:     &corev1.ObjectReference{Kind: AzureNamespacedIdentityKind, Name: "identity", Namespace: "tenant"},
+			wantErr: false,
+		},
+		{
+			name:    "namespaced identity in another namespace",
+			ref:     &corev1.ObjectReference{Kind: AzureNamespacedIdentityKind, Name: "identity", Namespace: "other"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := ValidateIdentityRef(tc.ref, "tenant", field.NewPath("spec", "identityRef"))
+			if tc.wantErr {
+				g.Expect(errs).To(HaveLen(1))
+				g.Expect(errs[0].Field).To(Equal("spec.identityRef.namespace"))
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestValidateKeyVault(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name     string
+		keyVault *KeyVaultSpec
+		wantErr  bool
+	}{
+		{
+			name: "no Key Vault",
+		},
+		{
+			name:     "valid name",
+			keyVault: &KeyVaultSpec{Name: "kv-eb4c73402a1b93e10d81"},
+		},
+		{
+			name:     "name starting with a digit",
+			keyVault: &KeyVaultSpec{Name: "1-vault"},
+			wantErr:  true,
+		},
+		{
+			name:     "name ending with a hyphen",
+			keyVault: &KeyVaultSpec{Name: "my-vault-"},
+			wantErr:  true,
+		},
+		{
+			name:     "name too long",
+			keyVault: &KeyVaultSpec{Name: "my-very-long-vault-name-1"},
+			wantErr:  true,
+		},
+		{
+			name:     "name with consecutive hyphens",
+			keyVault: &KeyVaultSpec{Name: "my--vault"},
+			wantErr:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validateKeyVault(tc.keyVault, field.NewPath("spec", "keyVault"))
+			if tc.wantErr {
+				g.Expect(errs).To(HaveLen(1))
+				g.Expect(errs[0].Type).To(Equal(field.ErrorTypeInvalid))
+				g.Expect(errs[0].Field).To(Equal("spec.keyVault.name"))
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
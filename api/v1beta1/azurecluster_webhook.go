@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
@@ -36,7 +37,6 @@ func (c *AzureCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azurecluster,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azureclusters,versions=v1beta1,name=validation.azurecluster.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-azurecluster,mutating=true,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azureclusters,versions=v1beta1,name=default.azurecluster.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
-var _ webhook.Validator = &AzureCluster{}
 var _ webhook.Defaulter = &AzureCluster{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type.
@@ -45,12 +45,15 @@ func (c *AzureCluster) Default() {
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
-func (c *AzureCluster) ValidateCreate() error {
-	return c.validateCluster(nil)
+func (c *AzureCluster) ValidateCreate(client client.Client) error {
+	if err := c.validateCluster(nil); err != nil {
+		return err
+	}
+	return c.validateIdentities(client)
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
-func (c *AzureCluster) ValidateUpdate(oldRaw runtime.Object) error {
+func (c *AzureCluster) ValidateUpdate(oldRaw runtime.Object, client client.Client) error {
 	var allErrs field.ErrorList
 	old := oldRaw.(*AzureCluster)
 
@@ -106,6 +109,13 @@ func (c *AzureCluster) ValidateUpdate(oldRaw runtime.Object) error {
 		}
 	}
 
+	if !reflect.DeepEqual(c.Spec.NetworkSpec.Vnet.SubscriptionID, old.Spec.NetworkSpec.Vnet.SubscriptionID) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "NetworkSpec", "Vnet", "SubscriptionID"),
+				c.Spec.NetworkSpec.Vnet.SubscriptionID, "field is immutable"),
+		)
+	}
+
 	if !reflect.DeepEqual(c.Spec.NetworkSpec.PrivateDNSZoneName, old.Spec.NetworkSpec.PrivateDNSZoneName) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec", "NetworkSpec", "PrivateDNSZoneName"),
@@ -113,6 +123,27 @@ func (c *AzureCluster) ValidateUpdate(oldRaw runtime.Object) error {
 		)
 	}
 
+	if !reflect.DeepEqual(c.Spec.NetworkSpec.PrivateDNSZoneResourceGroup, old.Spec.NetworkSpec.PrivateDNSZoneResourceGroup) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "NetworkSpec", "PrivateDNSZoneResourceGroup"),
+				c.Spec.NetworkSpec.PrivateDNSZoneResourceGroup, "field is immutable"),
+		)
+	}
+
+	if !reflect.DeepEqual(c.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID, old.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "NetworkSpec", "PrivateDNSZoneSubscriptionID"),
+				c.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID, "field is immutable"),
+		)
+	}
+
+	if old.Spec.KeyVault != nil && (c.Spec.KeyVault == nil || c.Spec.KeyVault.Name != old.Spec.KeyVault.Name) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "KeyVault", "Name"),
+				c.Spec.KeyVault, "field is immutable"),
+		)
+	}
+
 	// Allow enabling azure bastion but avoid disabling it.
 	if old.Spec.BastionSpec.AzureBastion != nil && !reflect.DeepEqual(old.Spec.BastionSpec.AzureBastion, c.Spec.BastionSpec.AzureBastion) {
 		allErrs = append(allErrs,
@@ -129,13 +160,16 @@ func (c *AzureCluster) ValidateUpdate(oldRaw runtime.Object) error {
 	}
 
 	if len(allErrs) == 0 {
-		return c.validateCluster(old)
+		if err := c.validateCluster(old); err != nil {
+			return err
+		}
+		return c.validateIdentities(client)
 	}
 
 	return apierrors.NewInvalid(GroupVersion.WithKind("AzureCluster").GroupKind(), c.Name, allErrs)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
-func (c *AzureCluster) ValidateDelete() error {
+func (c *AzureCluster) ValidateDelete(client client.Client) error {
 	return nil
 }
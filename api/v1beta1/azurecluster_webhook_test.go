@@ -20,7 +20,12 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestAzureCluster_ValidateCreate(t *testing.T) {
@@ -99,7 +104,66 @@ func TestAzureCluster_ValidateCreate(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := tc.cluster.ValidateCreate()
+			err := tc.cluster.ValidateCreate(newClusterIdentitiesClient())
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureCluster_ValidateIdentities(t *testing.T) {
+	tests := []struct {
+		name              string
+		identity          string
+		secondaryIdentity string
+		wantErr           bool
+	}{
+		{
+			name:     "azurecluster without secondary identity",
+			identity: "workload-identity-tenant-a",
+		},
+		{
+			name:              "azurecluster with identities of the same tenant",
+			identity:          "workload-identity-tenant-a",
+			secondaryIdentity: "pod-identity-tenant-a",
+		},
+		{
+			name:              "azurecluster with client secret and certificate identities of different tenants",
+			identity:          "client-secret-tenant-a",
+			secondaryIdentity: "certificate-tenant-b",
+		},
+		{
+			name:              "azurecluster with a secondary workload identity of another tenant",
+			identity:          "client-secret-tenant-a",
+			secondaryIdentity: "workload-identity-tenant-b",
+			wantErr:           true,
+		},
+		{
+			name:              "azurecluster with a pod identity and a secondary identity of another tenant",
+			identity:          "pod-identity-tenant-a",
+			secondaryIdentity: "certificate-tenant-b",
+			wantErr:           true,
+		},
+		{
+			name:              "azurecluster with a secondary identity which does not exist yet",
+			identity:          "pod-identity-tenant-a",
+			secondaryIdentity: "missing",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			cluster := createValidCluster()
+			cluster.Namespace = "default"
+			cluster.Spec.IdentityRef = &corev1.ObjectReference{Kind: AzureClusterIdentityKind, Name: tc.identity}
+			if tc.secondaryIdentity != "" {
+				cluster.Spec.SecondaryIdentityRef = &corev1.ObjectReference{Kind: AzureClusterIdentityKind, Name: tc.secondaryIdentity}
+			}
+			err := cluster.ValidateCreate(newClusterIdentitiesClient())
 			if tc.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -292,6 +356,60 @@ func TestAzureCluster_ValidateUpdate(t *testing.T) {
 			}(),
 			wantErr: false,
 		},
+		{
+			name: "azurecluster vnet subscription ID is immutable",
+			oldCluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					NetworkSpec: NetworkSpec{
+						Vnet: VnetSpec{SubscriptionID: "212ec1q8"},
+					},
+				},
+			},
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					NetworkSpec: NetworkSpec{
+						Vnet: VnetSpec{SubscriptionID: "212ec1q9"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "azurecluster private DNS zone resource group is immutable",
+			oldCluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					NetworkSpec: NetworkSpec{
+						PrivateDNSZoneResourceGroup: "dns-rg",
+					},
+				},
+			},
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					NetworkSpec: NetworkSpec{
+						PrivateDNSZoneResourceGroup: "other-dns-rg",
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "azurecluster private DNS zone subscription ID is immutable",
+			oldCluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					NetworkSpec: NetworkSpec{
+						PrivateDNSZoneSubscriptionID: "212ec1q8",
+					},
+				},
+			},
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					NetworkSpec: NetworkSpec{
+						PrivateDNSZoneSubscriptionID: "212ec1q9",
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "control plane outbound lb is immutable",
 			oldCluster: &AzureCluster{
@@ -310,12 +428,38 @@ func TestAzureCluster_ValidateUpdate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Key Vault name is immutable",
+			oldCluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					KeyVault: &KeyVaultSpec{Name: "kv-test"},
+				},
+			},
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					KeyVault: &KeyVaultSpec{Name: "kv-test-new"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Key Vault cannot be removed",
+			oldCluster: &AzureCluster{
+				Spec: AzureClusterSpec{
+					KeyVault: &KeyVaultSpec{Name: "kv-test"},
+				},
+			},
+			cluster: &AzureCluster{
+				Spec: AzureClusterSpec{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			err := tc.cluster.ValidateUpdate(tc.oldCluster)
+			err := tc.cluster.ValidateUpdate(tc.oldCluster, newClusterIdentitiesClient())
 			if tc.wantErr {
 				g.Expect(err).To(HaveOccurred())
 			} else {
@@ -324,3 +468,25 @@ func TestAzureCluster_ValidateUpdate(t *testing.T) {
 		})
 	}
 }
+
+func newClusterIdentitiesClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = AddToScheme(scheme)
+	newIdentity := func(name string, identityType IdentityType, tenantID string) *AzureClusterIdentity {
+		return &AzureClusterIdentity{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: AzureClusterIdentitySpec{
+				Type:     identityType,
+				ClientID: "fake-client-id",
+				TenantID: tenantID,
+			},
+		}
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newIdentity("client-secret-tenant-a", ManualServicePrincipal, "tenant-a"),
+		newIdentity("pod-identity-tenant-a", ServicePrincipal, "tenant-a"),
+		newIdentity("workload-identity-tenant-a", WorkloadIdentity, "tenant-a"),
+		newIdentity("certificate-tenant-b", ServicePrincipalCertificate, "tenant-b"),
+		newIdentity("workload-identity-tenant-b", WorkloadIdentity, "tenant-b"),
+	).Build()
+}
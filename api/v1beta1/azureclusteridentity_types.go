@@ -43,7 +43,7 @@ type AllowedNamespaces struct {
 
 // AzureClusterIdentitySpec defines the parameters that are used to create an AzureIdentity.
 type AzureClusterIdentitySpec struct {
-	// UserAssignedMSI or Service Principal
+	// UserAssignedMSI, Service Principal, Service Principal Certificate or Workload Identity
 	Type IdentityType `json:"type"`
 	// User assigned MSI resource id.
 	// +optional
@@ -51,6 +51,8 @@ type AzureClusterIdentitySpec struct {
 	// Both User Assigned MSI and SP can use this field.
 	ClientID string `json:"clientID"`
 	// ClientSecret is a secret reference which should contain either a Service Principal password or certificate secret.
+	// The certificate of a ServicePrincipalCertificate identity is read from the `certificate` key of the secret, in
+	// PEM or PFX format, and the password of an encrypted PFX certificate from its `password` key.
 	// +optional
 	ClientSecret corev1.SecretReference `json:"clientSecret,omitempty"`
 	// Service principal primary tenant id.
@@ -71,12 +73,21 @@ type AzureClusterIdentityStatus struct {
 	// Conditions defines current service state of the AzureClusterIdentity.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// SecretExpiryTime is the time the credentials in the secret of the identity expire, when it can be determined,
+	// e.g. the expiry of the certificate of a ServicePrincipalCertificate identity.
+	// +optional
+	SecretExpiryTime *metav1.Time `json:"secretExpiryTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=azureclusteridentities,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type",description="Type of the identity"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status",description="Whether the identity can authenticate and access its subscriptions"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].reason"
+// +kubebuilder:printcolumn:name="Expiry",type="string",priority=1,JSONPath=".status.secretExpiryTime",description="Time the credentials in the secret of the identity expire"
 
 // AzureClusterIdentity is the Schema for the azureclustersidentities API.
 type AzureClusterIdentity struct {
@@ -17,12 +17,90 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"encoding/pem"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// certificateSecretKey is the key of the secret of a ServicePrincipalCertificate identity holding its certificate.
+const certificateSecretKey = "certificate"
+
 // SetupWebhookWithManager sets up and registers the webhook with the manager.
 func (c *AzureClusterIdentity) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(c).
 		Complete()
 }
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azureclusteridentity,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azureclusteridentities,versions=v1beta1,name=validation.azureclusteridentity.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (c *AzureClusterIdentity) ValidateCreate(client client.Client) error {
+	return c.validateClusterIdentity(client)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (c *AzureClusterIdentity) ValidateUpdate(oldRaw runtime.Object, client client.Client) error {
+	return c.validateClusterIdentity(client)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (c *AzureClusterIdentity) ValidateDelete(client client.Client) error {
+	return nil
+}
+
+func (c *AzureClusterIdentity) validateClusterIdentity(cli client.Client) error {
+	var allErrs field.ErrorList
+	if c.Spec.Type == ServicePrincipalCertificate {
+		if c.Spec.ClientSecret.Name == "" {
+			allErrs = append(allErrs, field.Required(field.NewPath("spec", "clientSecret", "name"),
+				"a ServicePrincipalCertificate identity requires a secret with the certificate of the service principal"))
+		} else if err := validateCertificateSecret(cli, c.Spec.ClientSecret); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "clientSecret"), c.Spec.ClientSecret, err.Error()))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("AzureClusterIdentity").GroupKind(), c.Name, allErrs)
+}
+
+// validateCertificateSecret validates that the secret of a ServicePrincipalCertificate identity holds a certificate,
+// with its private key when it is PEM encoded. PFX certificates may be protected by a password, and are only parsed
+// when the identity is used.
+func validateCertificateSecret(cli client.Client, ref corev1.SecretReference) error {
+	secret := &corev1.Secret{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return errors.Errorf("secret %s/%s must be created before the identity", ref.Namespace, ref.Name)
+		}
+		return errors.Wrapf(err, "failed to get secret %s/%s", ref.Namespace, ref.Name)
+	}
+
+	data, ok := secret.Data[certificateSecretKey]
+	if !ok {
+		return errors.Errorf("secret %s/%s does not have a %s key", ref.Namespace, ref.Name, certificateSecretKey)
+	}
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return nil
+	}
+	var hasCertificate, hasPrivateKey bool
+	for ; block != nil; block, rest = pem.Decode(rest) {
+		hasCertificate = hasCertificate || block.Type == "CERTIFICATE"
+		hasPrivateKey = hasPrivateKey || strings.HasSuffix(block.Type, "PRIVATE KEY")
+	}
+	if !hasCertificate || !hasPrivateKey {
+		return errors.Errorf("the %s key of secret %s/%s must hold both the PEM encoded certificate and its private key", certificateSecretKey, ref.Namespace, ref.Name)
+	}
+	return nil
+}
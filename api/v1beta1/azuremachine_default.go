@@ -82,6 +82,32 @@ func (s *AzureMachineSpec) SetIdentityDefaults() {
 	}
 }
 
+// SetNetworkInterfacesDefaults sets the defaults for the network interfaces of an AzureMachine.
+// When no network interface is specified, a single primary one is defaulted from the machine's network settings.
+func (s *AzureMachineSpec) SetNetworkInterfacesDefaults() {
+	if len(s.NetworkInterfaces) == 0 {
+		s.NetworkInterfaces = []NetworkInterface{
+			{
+				SubnetName:            s.SubnetName,
+				EnableIPForwarding:    s.EnableIPForwarding,
+				AcceleratedNetworking: s.AcceleratedNetworking,
+				Primary:               true,
+			},
+		}
+	}
+
+	hasPrimary := false
+	for i, nic := range s.NetworkInterfaces {
+		if nic.PrivateIPAllocationMethod == "" {
+			s.NetworkInterfaces[i].PrivateIPAllocationMethod = PrivateIPAllocationMethodDynamic
+		}
+		hasPrimary = hasPrimary || nic.Primary
+	}
+	if !hasPrimary {
+		s.NetworkInterfaces[0].Primary = true
+	}
+}
+
 // SetDefaults sets to the defaults for the AzureMachineSpec.
 func (s *AzureMachineSpec) SetDefaults() {
 	if err := s.SetDefaultSSHPublicKey(); err != nil {
@@ -90,4 +116,5 @@ func (s *AzureMachineSpec) SetDefaults() {
 	s.SetDefaultCachingType()
 	s.SetDataDisksDefaults()
 	s.SetIdentityDefaults()
+	s.SetNetworkInterfacesDefaults()
 }
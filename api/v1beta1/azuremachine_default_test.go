@@ -279,3 +279,68 @@ func hardcodedAzureMachineWithSSHKey(sshPublicKey string) *AzureMachine {
 		},
 	}
 }
+
+func TestAzureMachineSpec_SetNetworkInterfacesDefaults(t *testing.T) {
+	cases := []struct {
+		name    string
+		machine *AzureMachine
+		output  []NetworkInterface
+	}{
+		{
+			name: "no network interfaces specified",
+			machine: &AzureMachine{Spec: AzureMachineSpec{
+				SubnetName:            "node-subnet",
+				EnableIPForwarding:    true,
+				AcceleratedNetworking: to.BoolPtr(false),
+			}},
+			output: []NetworkInterface{
+				{
+					SubnetName:                "node-subnet",
+					PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic,
+					EnableIPForwarding:        true,
+					AcceleratedNetworking:     to.BoolPtr(false),
+					Primary:                   true,
+				},
+			},
+		},
+		{
+			name: "first network interface becomes primary",
+			machine: &AzureMachine{Spec: AzureMachineSpec{
+				NetworkInterfaces: []NetworkInterface{
+					{SubnetName: "node-subnet"},
+					{SubnetName: "storage-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.4"},
+				},
+			}},
+			output: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic, Primary: true},
+				{SubnetName: "storage-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.4"},
+			},
+		},
+		{
+			name: "primary network interface is kept",
+			machine: &AzureMachine{Spec: AzureMachineSpec{
+				NetworkInterfaces: []NetworkInterface{
+					{SubnetName: "storage-subnet"},
+					{SubnetName: "node-subnet", Primary: true},
+				},
+			}},
+			output: []NetworkInterface{
+				{SubnetName: "storage-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic},
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic, Primary: true},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			tc.machine.Spec.SetNetworkInterfacesDefaults()
+			if !reflect.DeepEqual(tc.machine.Spec.NetworkInterfaces, tc.output) {
+				expected, _ := json.MarshalIndent(tc.output, "", "\t")
+				actual, _ := json.MarshalIndent(tc.machine.Spec.NetworkInterfaces, "", "\t")
+				t.Errorf("Expected %s, got %s", string(expected), string(actual))
+			}
+		})
+	}
+}
@@ -28,6 +28,18 @@ const (
 	// MachineFinalizer allows ReconcileAzureMachine to clean up Azure resources associated with AzureMachine before
 	// removing it from the apiserver.
 	MachineFinalizer = "azuremachine.infrastructure.cluster.x-k8s.io"
+
+	// AllowInPlaceResizeAnnotation, when set to "true" on an AzureMachine, allows changing its VMSize. The controller
+	// then drains the node and resizes the virtual machine in place instead of requiring a replacement.
+	AllowInPlaceResizeAnnotation = "sigs.k8s.io/cluster-api-provider-azure-allow-in-place-resize"
+
+	// CloudProviderConfigHashAnnotation is set on the azure.json secrets generated by CAPZ to the hash of the cloud
+	// provider config they contain, including the client secret when it is stored in a Key Vault instead.
+	CloudProviderConfigHashAnnotation = "sigs.k8s.io/cluster-api-provider-azure-cloud-provider-config-hash"
+
+	// CloudProviderConfigLastAppliedAnnotation is set on an AzureMachine to the hash of the cloud provider config last
+	// written on its virtual machine.
+	CloudProviderConfigLastAppliedAnnotation = "sigs.k8s.io/cluster-api-provider-azure-last-applied-cloud-provider-config"
 )
 
 // AzureMachineSpec defines the desired state of AzureMachine.
@@ -71,6 +83,11 @@ type AzureMachineSpec struct {
 	// +optional
 	RoleAssignmentName string `json:"roleAssignmentName,omitempty"`
 
+	// RoleAssignments is a list of roles to assign to the system-assigned or user-assigned identities of the machine,
+	// e.g. to pull images from a container registry. The role assignments are deleted with the machine.
+	// +optional
+	RoleAssignments []RoleAssignment `json:"roleAssignments,omitempty"`
+
 	// OSDisk specifies the parameters for the operating system disk of the machine
 	OSDisk OSDisk `json:"osDisk"`
 
@@ -93,12 +110,14 @@ type AzureMachineSpec struct {
 	// EnableIPForwarding enables IP Forwarding in Azure which is required for some CNI's to send traffic from a pods on one machine
 	// to another. This is required for IpV6 with Calico in combination with User Defined Routes (set by the Azure Cloud Controller
 	// manager). Default is false for disabled.
+	// It is ignored when NetworkInterfaces is set.
 	// +optional
 	EnableIPForwarding bool `json:"enableIPForwarding,omitempty"`
 
 	// AcceleratedNetworking enables or disables Azure accelerated networking. If omitted, it will be set based on
 	// whether the requested VMSize supports accelerated networking.
 	// If AcceleratedNetworking is set to true with a VMSize that does not support it, Azure will return an error.
+	// It is ignored when NetworkInterfaces is set.
 	// +kubebuilder:validation:nullable
 	// +optional
 	AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
@@ -111,9 +130,20 @@ type AzureMachineSpec struct {
 	// +optional
 	SecurityProfile *SecurityProfile `json:"securityProfile,omitempty"`
 
+	// Diagnostics specifies the diagnostics settings for a virtual machine.
+	// If not specified, boot diagnostics are enabled with a managed storage account.
+	// +optional
+	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
+
 	// SubnetName selects the Subnet where the VM will be placed
 	// +optional
 	SubnetName string `json:"subnetName,omitempty"`
+
+	// NetworkInterfaces is the list of network interfaces attached to the VM, in order.
+	// If omitted, a single primary network interface is defaulted from SubnetName, EnableIPForwarding and
+	// AcceleratedNetworking.
+	// +optional
+	NetworkInterfaces []NetworkInterface `json:"networkInterfaces,omitempty"`
 }
 
 // SpotVMOptions defines the options relevant to running the Machine on Spot VMs.
@@ -121,8 +151,43 @@ type SpotVMOptions struct {
 	// MaxPrice defines the maximum price the user is willing to pay for Spot VM instances
 	// +optional
 	MaxPrice *resource.Quantity `json:"maxPrice,omitempty"`
+
+	// EvictionPolicy defines the behavior of the virtual machine when it is evicted. It can be either Delete or Deallocate.
+	// Defaults to Deallocate.
+	// +optional
+	EvictionPolicy *SpotEvictionPolicy `json:"evictionPolicy,omitempty"`
+
+	// RecoveryPolicy defines how the controller handles a virtual machine that was deallocated by an eviction.
+	// Remediate marks the VM as not running and its Machine as waiting for remediation so that its owner replaces it,
+	// Restart tries to start the VM again until capacity is available. Only valid with the Deallocate eviction policy.
+	// Defaults to Remediate.
+	// +optional
+	RecoveryPolicy *SpotRecoveryPolicy `json:"recoveryPolicy,omitempty"`
 }
 
+// SpotEvictionPolicy defines the eviction policy for spot VMs.
+// +kubebuilder:validation:Enum=Deallocate;Delete
+type SpotEvictionPolicy string
+
+const (
+	// SpotEvictionPolicyDeallocate is the default eviction policy and will deallocate the VM when the node is marked for eviction.
+	SpotEvictionPolicyDeallocate SpotEvictionPolicy = "Deallocate"
+	// SpotEvictionPolicyDelete will delete the VM when the node is marked for eviction.
+	SpotEvictionPolicyDelete SpotEvictionPolicy = "Delete"
+)
+
+// SpotRecoveryPolicy defines how a deallocated spot VM is recovered.
+// +kubebuilder:validation:Enum=Remediate;Restart
+type SpotRecoveryPolicy string
+
+const (
+	// SpotRecoveryPolicyRemediate marks a deallocated spot VM as not running and requests the remediation of its Machine,
+	// or deletes the instance of a machine pool, so that it is replaced.
+	SpotRecoveryPolicyRemediate SpotRecoveryPolicy = "Remediate"
+	// SpotRecoveryPolicyRestart tries to start a deallocated spot VM again.
+	SpotRecoveryPolicyRestart SpotRecoveryPolicy = "Restart"
+)
+
 // AzureMachineStatus defines the observed state of AzureMachine.
 type AzureMachineStatus struct {
 	// Ready is true when the provider resource is ready.
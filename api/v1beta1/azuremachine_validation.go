@@ -17,8 +17,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"net"
+	"reflect"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -51,10 +55,168 @@ func ValidateAzureMachineSpec(spec AzureMachineSpec) field.ErrorList {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateRoleAssignments(spec.Identity, spec.UserAssignedIdentities, spec.RoleAssignments, field.NewPath("roleAssignments")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if errs := ValidateDataDisks(spec.DataDisks, field.NewPath("dataDisks")); len(errs) > 0 {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := ValidateNetworkInterfaces(spec.SubnetName, spec.NetworkInterfaces, field.NewPath("networkInterfaces")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateDiagnostics(spec.Diagnostics, field.NewPath("diagnostics")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := ValidateSpotVMOptions(spec.SpotVMOptions, field.NewPath("spotVMOptions")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	return allErrs
+}
+
+// ValidateSpotVMOptions validates the spot options of a virtual machine.
+func ValidateSpotVMOptions(spotVMOptions *SpotVMOptions, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if spotVMOptions == nil || spotVMOptions.RecoveryPolicy == nil {
+		return allErrs
+	}
+
+	if spotVMOptions.EvictionPolicy != nil && *spotVMOptions.EvictionPolicy == SpotEvictionPolicyDelete {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("recoveryPolicy"), "recoveryPolicy can only be set with the Deallocate eviction policy"))
+	}
+
+	return allErrs
+}
+
+// ValidateDiagnostics validates the diagnostics settings of a virtual machine.
+func ValidateDiagnostics(diagnostics *Diagnostics, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if diagnostics == nil || diagnostics.Boot == nil {
+		return allErrs
+	}
+
+	bootPath := fldPath.Child("boot")
+	switch diagnostics.Boot.StorageAccountType {
+	case UserManagedDiagnosticsStorage:
+		if diagnostics.Boot.UserManaged == nil {
+			allErrs = append(allErrs, field.Required(bootPath.Child("userManaged"), fmt.Sprintf("userManaged must be specified when storageAccountType is %s", UserManagedDiagnosticsStorage)))
+		}
+	case ManagedDiagnosticsStorage, DisabledDiagnosticsStorage:
+		if diagnostics.Boot.UserManaged != nil {
+			allErrs = append(allErrs, field.Forbidden(bootPath.Child("userManaged"), fmt.Sprintf("userManaged must only be specified when storageAccountType is %s", UserManagedDiagnosticsStorage)))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(bootPath.Child("storageAccountType"), diagnostics.Boot.StorageAccountType,
+			[]string{string(ManagedDiagnosticsStorage), string(UserManagedDiagnosticsStorage), string(DisabledDiagnosticsStorage)}))
+	}
+
+	if diagnostics.Boot.StorageAccountType == DisabledDiagnosticsStorage && diagnostics.Boot.SerialConsoleLogConfigMapName != "" {
+		allErrs = append(allErrs, field.Forbidden(bootPath.Child("serialConsoleLogConfigMapName"), "the serial console log is not available when boot diagnostics are disabled"))
+	}
+
+	return allErrs
+}
+
+// ValidateNetworkInterfaces validates a list of network interfaces.
+func ValidateNetworkInterfaces(subnetName string, nics []NetworkInterface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	primary := -1
+	for i, nic := range nics {
+		if !nic.Primary {
+			continue
+		}
+		if primary >= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("primary"), nic.Primary, "only one network interface can be primary"))
+			continue
+		}
+		primary = i
+	}
+	if primary < 0 {
+		primary = 0
+	}
+
+	ipSet := make(map[string]struct{})
+	for i, nic := range nics {
+		if i == primary {
+			if subnetName != "" && nic.SubnetName != "" && nic.SubnetName != subnetName {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("subnetName"), nic.SubnetName, "the subnet of the primary network interface must match the machine subnetName"))
+			}
+		} else if nic.SubnetName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("subnetName"), "the subnet name can only be omitted on the primary network interface"))
+		}
+
+		switch nic.PrivateIPAllocationMethod {
+		case PrivateIPAllocationMethodStatic:
+			var first, last net.IP
+			if nic.PrivateIPAddressRange != "" {
+				var err error
+				if first, last, err = ParsePrivateIPAddressRange(nic.PrivateIPAddressRange); err != nil {
+					allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("privateIPAddressRange"), nic.PrivateIPAddressRange, err.Error()))
+				}
+			}
+			if nic.PrivateIPAddress == "" {
+				if nic.PrivateIPAddressRange == "" {
+					allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("privateIPAddress"), "a private IP address or a private IP address range is required when the allocation method is Static"))
+				}
+			} else if ip := net.ParseIP(nic.PrivateIPAddress); ip == nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("privateIPAddress"), nic.PrivateIPAddress, "must be a valid IP address"))
+			} else if _, ok := ipSet[ip.String()]; ok {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("privateIPAddress"), nic.PrivateIPAddress))
+			} else {
+				ipSet[ip.String()] = struct{}{}
+				if first != nil && (bytes.Compare(ip.To16(), first.To16()) < 0 || bytes.Compare(ip.To16(), last.To16()) > 0) {
+					allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("privateIPAddress"), nic.PrivateIPAddress, "must be within the private IP address range"))
+				}
+			}
+		default:
+			if nic.PrivateIPAddress != "" {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("privateIPAddress"), "a private IP address can only be set when the allocation method is Static"))
+			}
+			if nic.PrivateIPAddressRange != "" {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("privateIPAddressRange"), "a private IP address range can only be set when the allocation method is Static"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateNetworkInterfacesUpdate validates updates to network interfaces.
+func ValidateNetworkInterfacesUpdate(oldNICs, newNICs []NetworkInterface, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// Machines created before network interfaces were introduced get them defaulted on their first update.
+	if len(oldNICs) == 0 {
+		return allErrs
+	}
+
+	if len(oldNICs) != len(newNICs) {
+		allErrs = append(allErrs, field.Invalid(fldPath, newNICs, "adding/removing network interfaces after machine creation is not allowed"))
+		return allErrs
+	}
+
+	for i := range newNICs {
+		oldNIC := oldNICs[i]
+		// The subnet of the primary network interface is defaulted by the controller when omitted.
+		if oldNIC.SubnetName == "" {
+			oldNIC.SubnetName = newNICs[i].SubnetName
+		}
+		// The private IP address allocated from the private IP address range is recorded by the controller.
+		if oldNIC.PrivateIPAddress == "" && oldNIC.PrivateIPAddressRange != "" {
+			oldNIC.PrivateIPAddress = newNICs[i].PrivateIPAddress
+		}
+		if !reflect.DeepEqual(oldNIC, newNICs[i]) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), newNICs[i], "modifying network interfaces after machine creation is not allowed"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -104,6 +266,41 @@ func ValidateUserAssignedIdentity(identityType VMIdentity, userAssignedIdentetie
 	return allErrs
 }
 
+// ValidateRoleAssignments validates the role assignments of the identities of a virtual machine or scale set.
+func ValidateRoleAssignments(identityType VMIdentity, userAssignedIdentities []UserAssignedIdentity, roleAssignments []RoleAssignment, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, roleAssignment := range roleAssignments {
+		idxPath := fldPath.Index(i)
+		if (roleAssignment.RoleDefinitionID == "") == (roleAssignment.RoleDefinitionName == "") {
+			allErrs = append(allErrs, field.Invalid(idxPath, roleAssignment, "exactly one of roleDefinitionID and roleDefinitionName must be set"))
+		}
+
+		if roleAssignment.UserAssignedIdentity == "" {
+			if identityType != VMIdentitySystemAssigned {
+				allErrs = append(allErrs, field.Required(idxPath.Child("userAssignedIdentity"), "must be specified when not using the 'SystemAssigned' identity type"))
+			}
+			continue
+		}
+		if identityType != VMIdentityUserAssigned {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("userAssignedIdentity"), "should only be set when using the 'UserAssigned' identity type"))
+			continue
+		}
+		found := false
+		for _, identity := range userAssignedIdentities {
+			if strings.EqualFold(identity.ProviderID, roleAssignment.UserAssignedIdentity) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.NotFound(idxPath.Child("userAssignedIdentity"), roleAssignment.UserAssignedIdentity))
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateDataDisks validates a list of data disks.
 func ValidateDataDisks(dataDisks []DataDisk, fieldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
@@ -456,6 +456,86 @@ func TestAzureMachine_ValidateSystemAssignedIdentity(t *testing.T) {
 	}
 }
 
+func TestAzureMachine_ValidateRoleAssignments(t *testing.T) {
+	g := NewWithT(t)
+
+	identityID := "azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/acr-pull"
+	tests := []struct {
+		name            string
+		identity        VMIdentity
+		roleAssignments []RoleAssignment
+		wantErr         bool
+	}{
+		{
+			name:     "role assignment of the system assigned identity",
+			identity: VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{
+				{RoleDefinitionName: "Reader", Scope: "/subscriptions/123/resourceGroups/my-rg"},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "role assignment of a user assigned identity",
+			identity: VMIdentityUserAssigned,
+			roleAssignments: []RoleAssignment{
+				{RoleDefinitionID: "7f951dda-4ed3-4680-a7ca-43fe172d538d", UserAssignedIdentity: identityID},
+			},
+			wantErr: false,
+		},
+		{
+			name:     "both role definition ID and name",
+			identity: VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{
+				{RoleDefinitionID: "7f951dda-4ed3-4680-a7ca-43fe172d538d", RoleDefinitionName: "AcrPull"},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "no role definition",
+			identity: VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{
+				{Scope: "/subscriptions/123/resourceGroups/my-rg"},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "system assigned identity without system assigned identity type",
+			identity: VMIdentityNone,
+			roleAssignments: []RoleAssignment{
+				{RoleDefinitionName: "AcrPull"},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "user assigned identity with system assigned identity type",
+			identity: VMIdentitySystemAssigned,
+			roleAssignments: []RoleAssignment{
+				{RoleDefinitionName: "AcrPull", UserAssignedIdentity: identityID},
+			},
+			wantErr: true,
+		},
+		{
+			name:     "user assigned identity not assigned to the machine",
+			identity: VMIdentityUserAssigned,
+			roleAssignments: []RoleAssignment{
+				{RoleDefinitionName: "AcrPull", UserAssignedIdentity: identityID + "-other"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateRoleAssignments(tc.identity, []UserAssignedIdentity{{ProviderID: identityID}}, tc.roleAssignments, field.NewPath("roleAssignments"))
+			if tc.wantErr {
+				g.Expect(err).ToNot(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
 func TestAzureMachine_ValidateDataDisksUpdate(t *testing.T) {
 	g := NewWithT(t)
 
@@ -651,3 +731,308 @@ func TestAzureMachine_ValidateDataDisksUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestAzureMachine_ValidateNetworkInterfaces(t *testing.T) {
+	g := NewWithT(t)
+
+	testcases := []struct {
+		name       string
+		subnetName string
+		nics       []NetworkInterface
+		wantErr    bool
+	}{
+		{
+			name:    "valid nil network interfaces",
+			nics:    nil,
+			wantErr: false,
+		},
+		{
+			name:       "valid network interfaces",
+			subnetName: "node-subnet",
+			nics: []NetworkInterface{
+				{PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic, Primary: true},
+				{SubnetName: "storage-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.4", AcceleratedNetworking: to.BoolPtr(true)},
+			},
+			wantErr: false,
+		},
+		{
+			name: "multiple primary network interfaces",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", Primary: true},
+				{SubnetName: "storage-subnet", Primary: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "secondary network interface without subnet",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", Primary: true},
+				{},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "primary network interface subnet does not match machine subnet",
+			subnetName: "node-subnet",
+			nics: []NetworkInterface{
+				{SubnetName: "storage-subnet", Primary: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "static allocation without private IP address",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic},
+			},
+			wantErr: true,
+		},
+		{
+			name: "static allocation with invalid private IP address",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.256"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate private IP addresses",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.4"},
+				{SubnetName: "storage-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.4"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "static allocation with private IP address range",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddressRange: "10.1.0.16/28"},
+				{SubnetName: "storage-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddressRange: "10.2.0.10-10.2.0.20"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "static allocation with private IP address within range",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.20", PrivateIPAddressRange: "10.1.0.16/28"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "static allocation with private IP address outside of range",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.4", PrivateIPAddressRange: "10.1.0.16/28"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "static allocation with invalid private IP address range",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddressRange: "10.1.0.20-10.1.0.10"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dynamic allocation with private IP address range",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic, PrivateIPAddressRange: "10.1.0.16/28"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "dynamic allocation with private IP address",
+			nics: []NetworkInterface{
+				{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic, PrivateIPAddress: "10.1.0.4"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testcases {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateNetworkInterfaces(test.subnetName, test.nics, field.NewPath("networkInterfaces"))
+			if test.wantErr {
+				g.Expect(err).NotTo(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateNetworkInterfacesUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name    string
+		oldNICs []NetworkInterface
+		nics    []NetworkInterface
+		wantErr bool
+	}{
+		{
+			name:    "network interfaces defaulted on existing machine",
+			oldNICs: nil,
+			nics:    []NetworkInterface{{SubnetName: "node-subnet", Primary: true}},
+			wantErr: false,
+		},
+		{
+			name:    "primary network interface subnet defaulted",
+			oldNICs: []NetworkInterface{{Primary: true}},
+			nics:    []NetworkInterface{{SubnetName: "node-subnet", Primary: true}},
+			wantErr: false,
+		},
+		{
+			name:    "private IP address allocated from range",
+			oldNICs: []NetworkInterface{{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddressRange: "10.1.0.16/28", Primary: true}},
+			nics:    []NetworkInterface{{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.20", PrivateIPAddressRange: "10.1.0.16/28", Primary: true}},
+			wantErr: false,
+		},
+		{
+			name:    "private IP address changed",
+			oldNICs: []NetworkInterface{{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.20", PrivateIPAddressRange: "10.1.0.16/28", Primary: true}},
+			nics:    []NetworkInterface{{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.21", PrivateIPAddressRange: "10.1.0.16/28", Primary: true}},
+			wantErr: true,
+		},
+		{
+			name:    "network interface subnet changed",
+			oldNICs: []NetworkInterface{{SubnetName: "node-subnet", Primary: true}},
+			nics:    []NetworkInterface{{SubnetName: "other-subnet", Primary: true}},
+			wantErr: true,
+		},
+		{
+			name:    "network interface added",
+			oldNICs: []NetworkInterface{{SubnetName: "node-subnet", Primary: true}},
+			nics:    []NetworkInterface{{SubnetName: "node-subnet", Primary: true}, {SubnetName: "storage-subnet"}},
+			wantErr: true,
+		},
+		{
+			name:    "accelerated networking changed",
+			oldNICs: []NetworkInterface{{SubnetName: "node-subnet", Primary: true}},
+			nics:    []NetworkInterface{{SubnetName: "node-subnet", Primary: true, AcceleratedNetworking: to.BoolPtr(true)}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateNetworkInterfacesUpdate(test.oldNICs, test.nics, field.NewPath("networkInterfaces"))
+			if test.wantErr {
+				g.Expect(err).NotTo(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateDiagnostics(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name        string
+		diagnostics *Diagnostics
+		wantErr     bool
+	}{
+		{
+			name:        "nil diagnostics",
+			diagnostics: nil,
+			wantErr:     false,
+		},
+		{
+			name:        "managed boot diagnostics",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: ManagedDiagnosticsStorage, SerialConsoleLogConfigMapName: "boot-logs"}},
+			wantErr:     false,
+		},
+		{
+			name: "user-managed boot diagnostics",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{
+				StorageAccountType: UserManagedDiagnosticsStorage,
+				UserManaged:        &UserManagedBootDiagnostics{StorageAccountURI: "https://fakestorage.blob.core.windows.net/"},
+			}},
+			wantErr: false,
+		},
+		{
+			name:        "user-managed boot diagnostics without storage account",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: UserManagedDiagnosticsStorage}},
+			wantErr:     true,
+		},
+		{
+			name: "managed boot diagnostics with storage account",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{
+				StorageAccountType: ManagedDiagnosticsStorage,
+				UserManaged:        &UserManagedBootDiagnostics{StorageAccountURI: "https://fakestorage.blob.core.windows.net/"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:        "disabled boot diagnostics with serial console log ConfigMap",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: DisabledDiagnosticsStorage, SerialConsoleLogConfigMapName: "boot-logs"}},
+			wantErr:     true,
+		},
+		{
+			name:        "unknown storage account type",
+			diagnostics: &Diagnostics{Boot: &BootDiagnostics{StorageAccountType: "Unknown"}},
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateDiagnostics(test.diagnostics, field.NewPath("diagnostics"))
+			if test.wantErr {
+				g.Expect(err).NotTo(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
+
+func TestAzureMachine_ValidateSpotVMOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	deallocate := SpotEvictionPolicyDeallocate
+	deletePolicy := SpotEvictionPolicyDelete
+	restart := SpotRecoveryPolicyRestart
+
+	tests := []struct {
+		name          string
+		spotVMOptions *SpotVMOptions
+		wantErr       bool
+	}{
+		{
+			name:          "nil spot options",
+			spotVMOptions: nil,
+			wantErr:       false,
+		},
+		{
+			name:          "default eviction policy with recovery policy",
+			spotVMOptions: &SpotVMOptions{RecoveryPolicy: &restart},
+			wantErr:       false,
+		},
+		{
+			name:          "deallocate eviction policy with recovery policy",
+			spotVMOptions: &SpotVMOptions{EvictionPolicy: &deallocate, RecoveryPolicy: &restart},
+			wantErr:       false,
+		},
+		{
+			name:          "delete eviction policy without recovery policy",
+			spotVMOptions: &SpotVMOptions{EvictionPolicy: &deletePolicy},
+			wantErr:       false,
+		},
+		{
+			name:          "delete eviction policy with recovery policy",
+			spotVMOptions: &SpotVMOptions{EvictionPolicy: &deletePolicy, RecoveryPolicy: &restart},
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSpotVMOptions(test.spotVMOptions, field.NewPath("spotVMOptions"))
+			if test.wantErr {
+				g.Expect(err).NotTo(HaveLen(0))
+			} else {
+				g.Expect(err).To(HaveLen(0))
+			}
+		})
+	}
+}
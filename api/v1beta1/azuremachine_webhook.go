@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"fmt"
 	"reflect"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -59,6 +60,13 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 		)
 	}
 
+	if m.Spec.VMSize != old.Spec.VMSize && m.Annotations[AllowInPlaceResizeAnnotation] != "true" {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "vmSize"),
+				m.Spec.VMSize, fmt.Sprintf("field is immutable unless the %s annotation is set to \"true\"", AllowInPlaceResizeAnnotation)),
+		)
+	}
+
 	if !reflect.DeepEqual(m.Spec.Identity, old.Spec.Identity) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec", "identity"),
@@ -80,6 +88,13 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 		)
 	}
 
+	if !reflect.DeepEqual(m.Spec.RoleAssignments, old.Spec.RoleAssignments) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "roleAssignments"),
+				m.Spec.RoleAssignments, "field is immutable"),
+		)
+	}
+
 	if !reflect.DeepEqual(m.Spec.OSDisk, old.Spec.OSDisk) {
 		allErrs = append(allErrs,
 			field.Invalid(field.NewPath("spec", "osDisk"),
@@ -136,6 +151,17 @@ func (m *AzureMachine) ValidateUpdate(oldRaw runtime.Object) error {
 		)
 	}
 
+	if !reflect.DeepEqual(m.Spec.Diagnostics, old.Spec.Diagnostics) {
+		allErrs = append(allErrs,
+			field.Invalid(field.NewPath("spec", "diagnostics"),
+				m.Spec.Diagnostics, "field is immutable"),
+		)
+	}
+
+	if errs := ValidateNetworkInterfacesUpdate(old.Spec.NetworkInterfaces, m.Spec.NetworkInterfaces, field.NewPath("spec", "networkInterfaces")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
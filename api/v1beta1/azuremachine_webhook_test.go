@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
@@ -126,6 +127,37 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 		newMachine *AzureMachine
 		wantErr    bool
 	}{
+		{
+			name: "invalidTest: azuremachine.spec.vmSize is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D2s_v3",
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D4s_v3",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "validTest: azuremachine.spec.vmSize can change with the in-place resize annotation",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D2s_v3",
+				},
+			},
+			newMachine: &AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{AllowInPlaceResizeAnnotation: "true"},
+				},
+				Spec: AzureMachineSpec{
+					VMSize: "Standard_D4s_v3",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalidTest: azuremachine.spec.image is immutable",
 			oldMachine: &AzureMachine{
@@ -254,6 +286,20 @@ func TestAzureMachine_ValidateUpdate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "invalidTest: azuremachine.spec.RoleAssignments is immutable",
+			oldMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					RoleAssignments: []RoleAssignment{{RoleDefinitionName: "AcrPull"}},
+				},
+			},
+			newMachine: &AzureMachine{
+				Spec: AzureMachineSpec{
+					RoleAssignments: []RoleAssignment{{RoleDefinitionName: "Reader"}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalidTest: azuremachine.spec.OSDisk is immutable",
 			oldMachine: &AzureMachine{
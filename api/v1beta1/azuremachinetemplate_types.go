@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -26,16 +27,37 @@ type AzureMachineTemplateSpec struct {
 	Template AzureMachineTemplateResource `json:"template"`
 }
 
+// AzureMachineTemplateStatus defines the observed state of AzureMachineTemplate.
+type AzureMachineTemplateStatus struct {
+	// Capacity is the resource capacity of a node created from the template, which lets the cluster autoscaler
+	// scale a MachineDeployment up from zero replicas.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// NodeLabels are the labels of a node created from the template. They include well-known labels, e.g. its
+	// instance type and operating system, and the node labels set by the KubeadmConfigTemplate of the MachineDeployment
+	// created from the template.
+	// +optional
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	// NodeTaints are the taints of a node created from the template, as set by the KubeadmConfigTemplate of the
+	// MachineDeployment created from the template.
+	// +optional
+	NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:path=azuremachinetemplates,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
+// +kubebuilder:subresource:status
 
 // AzureMachineTemplate is the Schema for the azuremachinetemplates API.
 type AzureMachineTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec AzureMachineTemplateSpec `json:"spec,omitempty"`
+	Spec   AzureMachineTemplateSpec   `json:"spec,omitempty"`
+	Status AzureMachineTemplateStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -46,12 +46,26 @@ var _ webhook.Validator = &AzureMachineTemplate{}
 func (r *AzureMachineTemplate) ValidateCreate() error {
 	spec := r.Spec.Template.Spec
 
-	if allErrs := ValidateAzureMachineSpec(spec); len(allErrs) > 0 {
+	allErrs := ValidateAzureMachineSpec(spec)
+	allErrs = append(allErrs, validateTemplateNetworkInterfaces(spec.NetworkInterfaces, field.NewPath("spec", "template", "spec", "networkInterfaces"))...)
+	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("AzureMachineTemplate").GroupKind(), r.Name, allErrs)
 	}
 	return nil
 }
 
+// validateTemplateNetworkInterfaces forbids fixed private IP addresses on the network interfaces of a template, as
+// every machine created from the template would get the same address.
+func validateTemplateNetworkInterfaces(nics []NetworkInterface, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, nic := range nics {
+		if nic.PrivateIPAddress != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("privateIPAddress"), "a fixed private IP address cannot be shared by the machines created from a template, set privateIPAddressRange instead"))
+		}
+	}
+	return allErrs
+}
+
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (r *AzureMachineTemplate) ValidateUpdate(oldRaw runtime.Object) error {
 	var allErrs field.ErrorList
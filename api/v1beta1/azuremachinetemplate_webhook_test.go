@@ -124,6 +124,24 @@ func TestAzureMachineTemplate_ValidateCreate(t *testing.T) {
 			),
 			wantErr: true,
 		},
+		{
+			name: "azuremachinetemplate with a static private IP address range",
+			machineTemplate: createAzureMachineTemplateFromMachine(
+				createMachineWithNetworkInterfaces([]NetworkInterface{
+					{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddressRange: "10.1.0.16/28"},
+				}),
+			),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinetemplate with a fixed private IP address",
+			machineTemplate: createAzureMachineTemplateFromMachine(
+				createMachineWithNetworkInterfaces([]NetworkInterface{
+					{SubnetName: "node-subnet", PrivateIPAllocationMethod: PrivateIPAllocationMethodStatic, PrivateIPAddress: "10.1.0.20"},
+				}),
+			),
+			wantErr: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -261,6 +279,12 @@ func TestAzureMachineTemplate_ValidateUpdate(t *testing.T) {
 							},
 							DataDisks:    []DataDisk{},
 							SSHPublicKey: "fake ssh key",
+							NetworkInterfaces: []NetworkInterface{
+								{
+									PrivateIPAllocationMethod: PrivateIPAllocationMethodDynamic,
+									Primary:                   true,
+								},
+							},
 						},
 					},
 				},
@@ -339,3 +363,9 @@ func createAzureMachineTemplateFromMachine(machine *AzureMachine) *AzureMachineT
 		},
 	}
 }
+
+func createMachineWithNetworkInterfaces(nics []NetworkInterface) *AzureMachine {
+	machine := hardcodedAzureMachineWithSSHKey(generateSSHPublicKey(true))
+	machine.Spec.NetworkInterfaces = nics
+	return machine
+}
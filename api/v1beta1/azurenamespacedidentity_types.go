@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AzureClusterIdentityKind is the kind of AzureClusterIdentity.
+	AzureClusterIdentityKind = "AzureClusterIdentity"
+	// AzureNamespacedIdentityKind is the kind of AzureNamespacedIdentity.
+	AzureNamespacedIdentityKind = "AzureNamespacedIdentity"
+)
+
+// AzureNamespacedIdentitySpec defines the parameters of an AzureNamespacedIdentity.
+type AzureNamespacedIdentitySpec struct {
+	// Type is the type of the identity, either ManualServicePrincipal or ServicePrincipalCertificate.
+	Type IdentityType `json:"type"`
+	// ClientID is the client id of the service principal.
+	ClientID string `json:"clientID"`
+	// ClientSecret is a reference to a secret in the namespace of the identity, which should contain either the
+	// password of the service principal in its `clientSecret` key or its certificate in its `certificate` key.
+	ClientSecret corev1.LocalObjectReference `json:"clientSecret"`
+	// TenantID is the tenant id of the service principal.
+	TenantID string `json:"tenantID"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=azurenamespacedidentities,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type",description="Type of the identity"
+// +kubebuilder:printcolumn:name="ClientID",type="string",JSONPath=".spec.clientID",description="Client id of the service principal"
+
+// AzureNamespacedIdentity is the Schema for the azurenamespacedidentities API. Unlike an AzureClusterIdentity, it can
+// be created by the tenants of a namespace and only be used by the clusters in its own namespace.
+type AzureNamespacedIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AzureNamespacedIdentitySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AzureNamespacedIdentityList contains a list of AzureNamespacedIdentity.
+type AzureNamespacedIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AzureNamespacedIdentity `json:"items"`
+}
+
+// ToClusterIdentity returns the AzureClusterIdentity equivalent to the namespaced identity, which only allows its own
+// namespace and references the secret in it.
+func (i *AzureNamespacedIdentity) ToClusterIdentity() *AzureClusterIdentity {
+	return &AzureClusterIdentity{
+		ObjectMeta: *i.ObjectMeta.DeepCopy(),
+		Spec: AzureClusterIdentitySpec{
+			Type:     i.Spec.Type,
+			ClientID: i.Spec.ClientID,
+			ClientSecret: corev1.SecretReference{
+				Name:      i.Spec.ClientSecret.Name,
+				Namespace: i.Namespace,
+			},
+			TenantID: i.Spec.TenantID,
+			AllowedNamespaces: &AllowedNamespaces{
+				NamespaceList: []string{i.Namespace},
+			},
+		},
+	}
+}
+
+func init() {
+	SchemeBuilder.Register(&AzureNamespacedIdentity{}, &AzureNamespacedIdentityList{})
+}
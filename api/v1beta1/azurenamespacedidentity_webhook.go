@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager sets up and registers the webhook with the manager.
+func (i *AzureNamespacedIdentity) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(i).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azurenamespacedidentity,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=infrastructure.cluster.x-k8s.io,resources=azurenamespacedidentities,versions=v1beta1,name=validation.azurenamespacedidentity.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &AzureNamespacedIdentity{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+func (i *AzureNamespacedIdentity) ValidateCreate() error {
+	return i.validateNamespacedIdentity()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+func (i *AzureNamespacedIdentity) ValidateUpdate(oldRaw runtime.Object) error {
+	return i.validateNamespacedIdentity()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+func (i *AzureNamespacedIdentity) ValidateDelete() error {
+	return nil
+}
+
+func (i *AzureNamespacedIdentity) validateNamespacedIdentity() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// identities authenticating as the controller itself, such as pod or workload identities, would let the tenants of
+	// a namespace use any identity the controller has access to
+	switch i.Spec.Type {
+	case ManualServicePrincipal, ServicePrincipalCertificate:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("type"), i.Spec.Type,
+			[]string{string(ManualServicePrincipal), string(ServicePrincipalCertificate)}))
+	}
+
+	if i.Spec.ClientID == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("clientID"), "the client id of the service principal is required"))
+	}
+	if i.Spec.TenantID == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("tenantID"), "the tenant id of the service principal is required"))
+	}
+	if i.Spec.ClientSecret.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("clientSecret", "name"),
+			"a secret with the password or certificate of the service principal is required"))
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(AzureNamespacedIdentityKind).GroupKind(), i.Name, allErrs)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAzureNamespacedIdentity_ValidateCreate(t *testing.T) {
+	g := NewWithT(t)
+
+	tests := []struct {
+		name     string
+		identity *AzureNamespacedIdentity
+		wantErr  bool
+	}{
+		{
+			name:     "manual service principal identity",
+			identity: createAzureNamespacedIdentity(ManualServicePrincipal, "sp-secret"),
+			wantErr:  false,
+		},
+		{
+			name:     "service principal certificate identity",
+			identity: createAzureNamespacedIdentity(ServicePrincipalCertificate, "sp-certificate"),
+			wantErr:  false,
+		},
+		{
+			name:     "identity without secret",
+			identity: createAzureNamespacedIdentity(ManualServicePrincipal, ""),
+			wantErr:  true,
+		},
+		{
+			name:     "pod identity",
+			identity: createAzureNamespacedIdentity(ServicePrincipal, "sp-secret"),
+			wantErr:  true,
+		},
+		{
+			name:     "workload identity",
+			identity: createAzureNamespacedIdentity(WorkloadIdentity, ""),
+			wantErr:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.identity.ValidateCreate()
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestAzureNamespacedIdentity_ToClusterIdentity(t *testing.T) {
+	g := NewWithT(t)
+
+	identity := createAzureNamespacedIdentity(ServicePrincipalCertificate, "sp-certificate")
+	clusterIdentity := identity.ToClusterIdentity()
+
+	g.Expect(clusterIdentity.Name).To(Equal("my-identity"))
+	g.Expect(clusterIdentity.Namespace).To(Equal("tenant"))
+	g.Expect(clusterIdentity.Spec.Type).To(Equal(ServicePrincipalCertificate))
+	g.Expect(clusterIdentity.Spec.ClientSecret).To(Equal(corev1.SecretReference{Name: "sp-certificate", Namespace: "tenant"}))
+	g.Expect(clusterIdentity.Spec.AllowedNamespaces).To(Equal(&AllowedNamespaces{NamespaceList: []string{"tenant"}}))
+}
+
+func createAzureNamespacedIdentity(identityType IdentityType, secretName string) *AzureNamespacedIdentity {
+	return &AzureNamespacedIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-identity",
+			Namespace: "tenant",
+		},
+		Spec: AzureNamespacedIdentitySpec{
+			Type:         identityType,
+			ClientID:     "my-client-id",
+			TenantID:     "my-tenant-id",
+			ClientSecret: corev1.LocalObjectReference{Name: secretName},
+		},
+	}
+}
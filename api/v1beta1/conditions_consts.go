@@ -38,6 +38,27 @@ const (
 	VMDeletingReason = "VMDeleting"
 	// VMProvisionFailedReason used for failures during vm provisioning.
 	VMProvisionFailedReason = "VMProvisionFailed"
+	// VMDeallocatedReason used when a spot vm was deallocated by an eviction.
+	VMDeallocatedReason = "VMDeallocated"
+	// VMResizedCondition reports on the status of an in-place resize of the Azure VM.
+	VMResizedCondition clusterv1.ConditionType = "VMResized"
+	// VMResizeDrainingReason used when the node is being drained before resizing the vm.
+	VMResizeDrainingReason = "VMResizeDraining"
+	// VMResizeDeallocatingReason used when the vm is being deallocated before resizing it.
+	VMResizeDeallocatingReason = "VMResizeDeallocating"
+	// VMResizingReason used when the size of the deallocated vm is being changed.
+	VMResizingReason = "VMResizing"
+	// VMResizeStartingReason used when the vm is being started after resizing it.
+	VMResizeStartingReason = "VMResizeStarting"
+	// VMResizeFailedReason used when a step of the vm resize failed.
+	VMResizeFailedReason = "VMResizeFailed"
+	// CloudProviderConfigUpdatedCondition reports on the status of the update of the cloud provider config on the Azure VM
+	// after the credentials of the cluster were rotated.
+	CloudProviderConfigUpdatedCondition clusterv1.ConditionType = "CloudProviderConfigUpdated"
+	// CloudProviderConfigUpdatingReason used when the cloud provider config is being written on the vm.
+	CloudProviderConfigUpdatingReason = "CloudProviderConfigUpdating"
+	// CloudProviderConfigUpdateFailedReason used when writing the cloud provider config on the vm failed.
+	CloudProviderConfigUpdateFailedReason = "CloudProviderConfigUpdateFailed"
 	// WaitingForClusterInfrastructureReason used when machine is waiting for cluster infrastructure to be ready before proceeding.
 	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
 	// WaitingForBootstrapDataReason used when machine is waiting for bootstrap data to be ready before proceeding.
@@ -74,6 +95,36 @@ const (
 	ScaleSetModelUpdatedCondition clusterv1.ConditionType = "ScaleSetModelUpdated"
 	// ScaleSetModelOutOfDateReason describes the machine pool model being out of date.
 	ScaleSetModelOutOfDateReason = "ScaleSetModelOutOfDate"
+
+	// SpotCapacityCondition reports on the Spot capacity of a machine pool mixing on-demand and Spot instances.
+	SpotCapacityCondition clusterv1.ConditionType = "SpotCapacity"
+	// SpotCapacityUnavailableReason describes the machine pool falling back to on-demand instances because Azure
+	// could not allocate Spot capacity.
+	SpotCapacityUnavailableReason = "SpotCapacityUnavailable"
+)
+
+// AzureMachinePoolMachine Conditions and Reasons.
+const (
+	// ScheduledEventCondition reports on Azure scheduled events pending for the instance of a machine pool machine.
+	ScheduledEventCondition clusterv1.ConditionType = "ScheduledEvent"
+	// ScheduledEventDrainingReason used when the node is being drained ahead of a pending scheduled event.
+	ScheduledEventDrainingReason = "ScheduledEventDraining"
+	// ScheduledEventApprovedReason used when a pending scheduled event was approved to start early after the node was drained.
+	ScheduledEventApprovedReason = "ScheduledEventApproved"
+
+	// InstanceReimagedCondition reports on the reimage in place of the instance of a machine pool machine with the
+	// latest model.
+	InstanceReimagedCondition clusterv1.ConditionType = "InstanceReimaged"
+	// InstanceReimageDrainingReason used when the node is being drained before reimaging the instance.
+	InstanceReimageDrainingReason = "InstanceReimageDraining"
+	// InstanceReimageUpdatingModelReason used when the latest model is being applied to the instance.
+	InstanceReimageUpdatingModelReason = "InstanceReimageUpdatingModel"
+	// InstanceReimagingReason used when the instance is being reimaged.
+	InstanceReimagingReason = "InstanceReimaging"
+	// InstanceReimageWaitingForNodeReason used when waiting for the node of the reimaged instance to be Ready.
+	InstanceReimageWaitingForNodeReason = "InstanceReimageWaitingForNode"
+	// InstanceReimageFailedReason used when a step of the reimage failed.
+	InstanceReimageFailedReason = "InstanceReimageFailed"
 )
 
 // AzureManagedCluster Conditions and Reasons.
@@ -92,6 +143,8 @@ const (
 	VNetReadyCondition clusterv1.ConditionType = "VNetReady"
 	// VnetPeeringReadyCondition means the virtual network peerings exist and are ready to be used.
 	VnetPeeringReadyCondition clusterv1.ConditionType = "VnetPeeringReady"
+	// KeyVaultReadyCondition means the Key Vault exists and is ready to be used.
+	KeyVaultReadyCondition clusterv1.ConditionType = "KeyVaultReady"
 	// SecurityGroupsReadyCondition means the security groups exist and are ready to be used.
 	SecurityGroupsReadyCondition clusterv1.ConditionType = "SecurityGroupsReady"
 	// RouteTablesReadyCondition means the route tables exist and are ready to be used.
@@ -132,3 +185,13 @@ const (
 	// UpdatingReason means the resource is being updated.
 	UpdatingReason = "Updating"
 )
+
+// AzureClusterIdentity Conditions and Reasons.
+const (
+	// TokenRequestFailedReason used when the identity fails to obtain a token from Azure Active Directory.
+	TokenRequestFailedReason = "TokenRequestFailed"
+	// SubscriptionAccessFailedReason used when the identity cannot read a subscription of the clusters using it.
+	SubscriptionAccessFailedReason = "SubscriptionAccessFailed"
+	// SecretExpiredReason used when the credentials in the secret of the identity have expired.
+	SecretExpiredReason = "SecretExpired"
+)
@@ -17,6 +17,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	"bytes"
+	"net"
+	"strings"
+
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -40,6 +44,8 @@ const (
 	PutFuture string = "PUT"
 	// DeleteFuture is a future that was derived from a DELETE request.
 	DeleteFuture string = "DELETE"
+	// PostFuture is a future that was derived from a POST request.
+	PostFuture string = "POST"
 )
 
 // Future contains the data needed for an Azure long-running operation to continue across reconcile loops.
@@ -89,6 +95,16 @@ type NetworkSpec struct {
 	// PrivateDNSZoneName defines the zone name for the Azure Private DNS.
 	// +optional
 	PrivateDNSZoneName string `json:"privateDNSZoneName,omitempty"`
+
+	// PrivateDNSZoneResourceGroup is the resource group of the Azure Private DNS zone.
+	// Defaults to the resource group of the cluster.
+	// +optional
+	PrivateDNSZoneResourceGroup string `json:"privateDNSZoneResourceGroup,omitempty"`
+
+	// PrivateDNSZoneSubscriptionID is the subscription of the Azure Private DNS zone.
+	// Defaults to the subscription of the cluster.
+	// +optional
+	PrivateDNSZoneSubscriptionID string `json:"privateDNSZoneSubscriptionID,omitempty"`
 }
 
 // VnetSpec configures an Azure virtual network.
@@ -98,6 +114,12 @@ type VnetSpec struct {
 	// +optional
 	ResourceGroup string `json:"resourceGroup,omitempty"`
 
+	// SubscriptionID is the subscription of the existing virtual network.
+	// Defaults to the subscription of the cluster. A virtual network in another subscription must already exist, along
+	// with its subnets, and its requests are authorized with the secondary identity of the cluster.
+	// +optional
+	SubscriptionID string `json:"subscriptionID,omitempty"`
+
 	// ID is the Azure resource ID of the virtual network.
 	// READ-ONLY
 	// +optional
@@ -126,7 +148,48 @@ type VnetPeeringSpec struct {
 	ResourceGroup string `json:"resourceGroup,omitempty"`
 
 	// RemoteVnetName defines name of the remote virtual network.
-	RemoteVnetName string `json:"remoteVnetName"`
+	// Either RemoteVnetName or RemoteVnetID must be set.
+	// +optional
+	RemoteVnetName string `json:"remoteVnetName,omitempty"`
+
+	// SubscriptionID is the subscription of the remote virtual network.
+	// Defaults to the subscription of the cluster.
+	// +optional
+	SubscriptionID string `json:"subscriptionID,omitempty"`
+
+	// RemoteVnetID is the Azure resource ID of the remote virtual network, e.g.
+	// /subscriptions/<subscription>/resourceGroups/<resource group>/providers/Microsoft.Network/virtualNetworks/<name>.
+	// When set, the subscription, resource group and name of the remote virtual network are taken from it.
+	// +optional
+	RemoteVnetID string `json:"remoteVnetID,omitempty"`
+
+	// ForwardPeeringProperties are the properties of the peering from the AzureCluster's virtual network to the remote
+	// virtual network.
+	// +optional
+	ForwardPeeringProperties VnetPeeringProperties `json:"forwardPeeringProperties,omitempty"`
+
+	// ReversePeeringProperties are the properties of the peering from the remote virtual network to the AzureCluster's
+	// virtual network.
+	// +optional
+	ReversePeeringProperties VnetPeeringProperties `json:"reversePeeringProperties,omitempty"`
+}
+
+// VnetPeeringProperties specifies the properties of one direction of a virtual network peering.
+type VnetPeeringProperties struct {
+	// AllowForwardedTraffic specifies whether the traffic forwarded by the virtual machines in the local virtual
+	// network is allowed in the remote virtual network.
+	// +optional
+	AllowForwardedTraffic *bool `json:"allowForwardedTraffic,omitempty"`
+
+	// AllowGatewayTransit specifies whether the remote virtual network can use the gateway of the local virtual
+	// network, e.g. to reach an ExpressRoute circuit of a hub virtual network.
+	// +optional
+	AllowGatewayTransit *bool `json:"allowGatewayTransit,omitempty"`
+
+	// UseRemoteGateways specifies whether the local virtual network uses the gateway of the remote virtual network.
+	// The peering in the other direction must allow gateway transit.
+	// +optional
+	UseRemoteGateways *bool `json:"useRemoteGateways,omitempty"`
 }
 
 // VnetPeerings is a slice of VnetPeering.
@@ -412,6 +475,17 @@ const (
 	VMIdentityUserAssigned VMIdentity = "UserAssigned"
 )
 
+// OrchestrationModeType represents the orchestration mode for a Virtual Machine Scale Set backing an AzureMachinePool.
+// +kubebuilder:validation:Enum=Flexible;Uniform
+type OrchestrationModeType string
+
+const (
+	// FlexibleOrchestrationMode treats VMs as individual resources accessible by standard VM APIs.
+	FlexibleOrchestrationMode OrchestrationModeType = "Flexible"
+	// UniformOrchestrationMode treats VMs as identical instances accessible by the VMSS VM API.
+	UniformOrchestrationMode OrchestrationModeType = "Uniform"
+)
+
 // UserAssignedIdentity defines the user-assigned identities provided
 // by the user to be assigned to Azure resources.
 type UserAssignedIdentity struct {
@@ -420,6 +494,82 @@ type UserAssignedIdentity struct {
 	ProviderID string `json:"providerID"`
 }
 
+// RoleAssignment defines a role to assign to an identity of a virtual machine or virtual machine scale set.
+type RoleAssignment struct {
+	// RoleDefinitionID is the role definition to assign, either its GUID or its resource ID, e.g.
+	// '/subscriptions/{subscriptionId}/providers/Microsoft.Authorization/roleDefinitions/{roleDefinitionId}'.
+	// Exactly one of RoleDefinitionID and RoleDefinitionName must be set.
+	// +optional
+	RoleDefinitionID string `json:"roleDefinitionID,omitempty"`
+
+	// RoleDefinitionName is the name of the role definition to assign, e.g. 'AcrPull'.
+	// +optional
+	RoleDefinitionName string `json:"roleDefinitionName,omitempty"`
+
+	// Scope is the resource ID of the resource, resource group or subscription the role is assigned on.
+	// Defaults to the resource group of the cluster.
+	// +optional
+	Scope string `json:"scope,omitempty"`
+
+	// UserAssignedIdentity is the ProviderID of the user-assigned identity, from UserAssignedIdentities, the role is
+	// assigned to. When not set, the role is assigned to the system-assigned identity.
+	// +optional
+	UserAssignedIdentity string `json:"userAssignedIdentity,omitempty"`
+}
+
+// PrivateIPAllocationMethod defines how the private IP address of a network interface is allocated.
+// +kubebuilder:validation:Enum=Dynamic;Static
+type PrivateIPAllocationMethod string
+
+const (
+	// PrivateIPAllocationMethodDynamic lets Azure pick a free private IP address from the subnet.
+	PrivateIPAllocationMethodDynamic PrivateIPAllocationMethod = "Dynamic"
+	// PrivateIPAllocationMethodStatic assigns the private IP address set on the network interface.
+	PrivateIPAllocationMethodStatic PrivateIPAllocationMethod = "Static"
+)
+
+// NetworkInterface defines a network interface attached to a virtual machine.
+type NetworkInterface struct {
+	// SubnetName is the name of the subnet of the cluster virtual network the network interface is placed in.
+	// It can only be omitted on the primary network interface, in which case the machine's SubnetName is used.
+	// +optional
+	SubnetName string `json:"subnetName,omitempty"`
+
+	// PrivateIPAllocationMethod is the private IP address allocation method of the network interface.
+	// Defaults to Dynamic.
+	// +optional
+	PrivateIPAllocationMethod PrivateIPAllocationMethod `json:"privateIPAllocationMethod,omitempty"`
+
+	// PrivateIPAddress is the private IP address of the network interface.
+	// It can only be set when PrivateIPAllocationMethod is Static. When it is not set, a free private IP address
+	// is allocated from PrivateIPAddressRange and recorded here once the network interface is created.
+	// +optional
+	PrivateIPAddress string `json:"privateIPAddress,omitempty"`
+
+	// PrivateIPAddressRange is the range static private IP addresses are allocated from when PrivateIPAllocationMethod
+	// is Static and PrivateIPAddress is not set, so that machines created from an AzureMachineTemplate get stable
+	// private IP addresses. It is either a CIDR block, such as 10.0.0.16/28, or an inclusive range of IP addresses,
+	// such as 10.0.0.10-10.0.0.20.
+	// +optional
+	PrivateIPAddressRange string `json:"privateIPAddressRange,omitempty"`
+
+	// EnableIPForwarding enables IP Forwarding on the network interface.
+	// +optional
+	EnableIPForwarding bool `json:"enableIPForwarding,omitempty"`
+
+	// AcceleratedNetworking enables or disables Azure accelerated networking on the network interface. If omitted,
+	// it will be set based on whether the requested VMSize supports accelerated networking.
+	// +kubebuilder:validation:nullable
+	// +optional
+	AcceleratedNetworking *bool `json:"acceleratedNetworking,omitempty"`
+
+	// Primary marks the network interface as the primary network interface of the virtual machine.
+	// Only the primary network interface is associated with the load balancers and public IP of the machine.
+	// If no network interface is marked as primary, the first one is.
+	// +optional
+	Primary bool `json:"primary,omitempty"`
+}
+
 const (
 	// AzureIdentityBindingSelector is the label used to match with the AzureIdentityBinding
 	// For the controller to match an identity binding, it needs a [label] with the key `aadpodidbinding`
@@ -428,7 +578,7 @@ const (
 )
 
 // IdentityType represents different types of identities.
-// +kubebuilder:validation:Enum=ServicePrincipal;ManualServicePrincipal;UserAssignedMSI
+// +kubebuilder:validation:Enum=ServicePrincipal;ManualServicePrincipal;ServicePrincipalCertificate;UserAssignedMSI;WorkloadIdentity
 type IdentityType string
 
 const (
@@ -440,6 +590,13 @@ const (
 
 	// ManualServicePrincipal represents a manual service principal.
 	ManualServicePrincipal IdentityType = "ManualServicePrincipal"
+
+	// ServicePrincipalCertificate represents a service principal authenticating with a certificate.
+	ServicePrincipalCertificate IdentityType = "ServicePrincipalCertificate"
+
+	// WorkloadIdentity represents a service principal or user-assigned identity with a federated credential trusting
+	// the service account token of the controller.
+	WorkloadIdentity IdentityType = "WorkloadIdentity"
 )
 
 // OSDisk defines the operating system disk for a VM.
@@ -585,6 +742,38 @@ func (s SubnetSpec) IsNatGatewayEnabled() bool {
 	return s.NatGateway.Name != ""
 }
 
+// ParsePrivateIPAddressRange returns the first and last IP addresses of a PrivateIPAddressRange, which is either a
+// CIDR block or an inclusive range of IP addresses of the same family separated by a dash.
+func ParsePrivateIPAddressRange(ipRange string) (net.IP, net.IP, error) {
+	if strings.Contains(ipRange, "/") {
+		_, ipNet, err := net.ParseCIDR(ipRange)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "invalid CIDR block %s", ipRange)
+		}
+		last := make(net.IP, len(ipNet.IP))
+		for i := range ipNet.IP {
+			last[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+		}
+		return ipNet.IP, last, nil
+	}
+
+	bounds := strings.Split(ipRange, "-")
+	if len(bounds) != 2 {
+		return nil, nil, errors.Errorf("invalid IP address range %s, it must be a CIDR block or two IP addresses separated by a dash", ipRange)
+	}
+	first, last := net.ParseIP(strings.TrimSpace(bounds[0])), net.ParseIP(strings.TrimSpace(bounds[1]))
+	if first == nil || last == nil {
+		return nil, nil, errors.Errorf("invalid IP address range %s, it must be a CIDR block or two IP addresses separated by a dash", ipRange)
+	}
+	if (first.To4() == nil) != (last.To4() == nil) {
+		return nil, nil, errors.Errorf("invalid IP address range %s, both IP addresses must be of the same family", ipRange)
+	}
+	if bytes.Compare(first.To16(), last.To16()) > 0 {
+		return nil, nil, errors.Errorf("invalid IP address range %s, the first IP address must not be greater than the last one", ipRange)
+	}
+	return first, last, nil
+}
+
 // SecurityProfile specifies the Security profile settings for a
 // virtual machine or virtual machine scale set.
 type SecurityProfile struct {
@@ -595,6 +784,55 @@ type SecurityProfile struct {
 	EncryptionAtHost *bool `json:"encryptionAtHost,omitempty"`
 }
 
+// BootDiagnosticsStorageAccountType defines the type of storage account used to store boot diagnostics data.
+// +kubebuilder:validation:Enum=Managed;UserManaged;Disabled
+type BootDiagnosticsStorageAccountType string
+
+const (
+	// ManagedDiagnosticsStorage stores boot diagnostics data in a storage account managed by Azure.
+	ManagedDiagnosticsStorage BootDiagnosticsStorageAccountType = "Managed"
+	// UserManagedDiagnosticsStorage stores boot diagnostics data in a storage account provided by the user.
+	UserManagedDiagnosticsStorage BootDiagnosticsStorageAccountType = "UserManaged"
+	// DisabledDiagnosticsStorage disables boot diagnostics.
+	DisabledDiagnosticsStorage BootDiagnosticsStorageAccountType = "Disabled"
+)
+
+// Diagnostics is used to configure the diagnostic settings of the virtual machine.
+type Diagnostics struct {
+	// Boot configures the boot diagnostics settings for the virtual machine.
+	// Boot diagnostics use a managed storage account when omitted.
+	// +optional
+	Boot *BootDiagnostics `json:"boot,omitempty"`
+}
+
+// BootDiagnostics configures the boot diagnostics settings for the virtual machine, which allow viewing the serial
+// console output and a screenshot of the virtual machine to diagnose its status.
+type BootDiagnostics struct {
+	// StorageAccountType is the type of storage account used to store boot diagnostics data: Managed, UserManaged, or
+	// Disabled.
+	StorageAccountType BootDiagnosticsStorageAccountType `json:"storageAccountType"`
+
+	// UserManaged provides a reference to the user-managed storage account.
+	// It must be set when StorageAccountType is UserManaged, and must not be set otherwise.
+	// +optional
+	UserManaged *UserManagedBootDiagnostics `json:"userManaged,omitempty"`
+
+	// SerialConsoleLogConfigMapName is the name of a ConfigMap in the namespace of the machine the tail of the serial
+	// console log of the virtual machine is written to when bootstrapping fails, under a key named after the machine.
+	// The ConfigMap is created if it does not exist. The tail of the serial console log is always attached to a
+	// Warning event on the machine.
+	// +optional
+	SerialConsoleLogConfigMapName string `json:"serialConsoleLogConfigMapName,omitempty"`
+}
+
+// UserManagedBootDiagnostics provides a reference to a user-managed storage account.
+type UserManagedBootDiagnostics struct {
+	// StorageAccountURI is the URI of the user-managed storage account, such as
+	// https://mystorageaccount.blob.core.windows.net/.
+	// +kubebuilder:validation:Pattern=`^https://`
+	StorageAccountURI string `json:"storageAccountURI"`
+}
+
 // AddressRecord specifies a DNS record mapping a hostname to an IPV4 or IPv6 address.
 type AddressRecord struct {
 	Hostname string
@@ -700,6 +938,24 @@ type AzureBastion struct {
 	PublicIP PublicIPSpec `json:"publicIP,omitempty"`
 }
 
+// KeyVaultSpec specifies the Key Vault storing the secrets of the cluster, which its virtual machines fetch with their
+// managed identity rather than getting them in their custom data.
+type KeyVaultSpec struct {
+	// Name is the name of the Key Vault, which must be globally unique. Defaults to a name derived from the resource
+	// group and the name of the cluster.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// BootstrapData stores the bootstrap data of the machines in the Key Vault, their custom data only fetching it.
+	// +optional
+	BootstrapData bool `json:"bootstrapData,omitempty"`
+
+	// CloudProviderCredentials stores the cloud provider config of the machines in the Key Vault, the azure.json
+	// secrets in the management cluster being stripped of the client secret.
+	// +optional
+	CloudProviderCredentials bool `json:"cloudProviderCredentials,omitempty"`
+}
+
 // IsTerminalProvisioningState returns true if the ProvisioningState is a terminal state for an Azure resource.
 func IsTerminalProvisioningState(state ProvisioningState) bool {
 	return state == Failed || state == Succeeded
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParsePrivateIPAddressRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		ipRange   string
+		wantFirst string
+		wantLast  string
+		wantErr   bool
+	}{
+		{
+			name:      "IPv4 CIDR block",
+			ipRange:   "10.0.0.16/28",
+			wantFirst: "10.0.0.16",
+			wantLast:  "10.0.0.31",
+		},
+		{
+			name:      "IPv6 CIDR block",
+			ipRange:   "2001:1234:5678:9abd::/120",
+			wantFirst: "2001:1234:5678:9abd::",
+			wantLast:  "2001:1234:5678:9abd::ff",
+		},
+		{
+			name:      "IPv4 range",
+			ipRange:   "10.0.0.10-10.0.0.20",
+			wantFirst: "10.0.0.10",
+			wantLast:  "10.0.0.20",
+		},
+		{
+			name:      "single IP address range",
+			ipRange:   "10.0.0.10-10.0.0.10",
+			wantFirst: "10.0.0.10",
+			wantLast:  "10.0.0.10",
+		},
+		{
+			name:    "invalid CIDR block",
+			ipRange: "10.0.0.16/33",
+			wantErr: true,
+		},
+		{
+			name:    "single IP address",
+			ipRange: "10.0.0.10",
+			wantErr: true,
+		},
+		{
+			name:    "reversed range",
+			ipRange: "10.0.0.20-10.0.0.10",
+			wantErr: true,
+		},
+		{
+			name:    "mixed IP families",
+			ipRange: "10.0.0.10-2001:1234:5678:9abd::10",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			first, last, err := ParsePrivateIPAddressRange(tc.ipRange)
+			if tc.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(first.String()).To(Equal(tc.wantFirst))
+			g.Expect(last.String()).To(Equal(tc.wantLast))
+		})
+	}
+}
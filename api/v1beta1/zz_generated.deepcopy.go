@@ -203,6 +203,10 @@ func (in *AzureClusterIdentityStatus) DeepCopyInto(out *AzureClusterIdentityStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SecretExpiryTime != nil {
+		in, out := &in.SecretExpiryTime, &out.SecretExpiryTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureClusterIdentityStatus.
@@ -264,12 +268,22 @@ func (in *AzureClusterSpec) DeepCopyInto(out *AzureClusterSpec) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.SecondaryIdentityRef != nil {
+		in, out := &in.SecondaryIdentityRef, &out.SecondaryIdentityRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 	in.BastionSpec.DeepCopyInto(&out.BastionSpec)
 	if in.CloudProviderConfigOverrides != nil {
 		in, out := &in.CloudProviderConfigOverrides, &out.CloudProviderConfigOverrides
 		*out = new(CloudProviderConfigOverrides)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.KeyVault != nil {
+		in, out := &in.KeyVault, &out.KeyVault
+		*out = new(KeyVaultSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureClusterSpec.
@@ -398,6 +412,11 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 		*out = make([]UserAssignedIdentity, len(*in))
 		copy(*out, *in)
 	}
+	if in.RoleAssignments != nil {
+		in, out := &in.RoleAssignments, &out.RoleAssignments
+		*out = make([]RoleAssignment, len(*in))
+		copy(*out, *in)
+	}
 	in.OSDisk.DeepCopyInto(&out.OSDisk)
 	if in.DataDisks != nil {
 		in, out := &in.DataDisks, &out.DataDisks
@@ -428,6 +447,18 @@ func (in *AzureMachineSpec) DeepCopyInto(out *AzureMachineSpec) {
 		*out = new(SecurityProfile)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(Diagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkInterfaces != nil {
+		in, out := &in.NetworkInterfaces, &out.NetworkInterfaces
+		*out = make([]NetworkInterface, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineSpec.
@@ -493,6 +524,7 @@ func (in *AzureMachineTemplate) DeepCopyInto(out *AzureMachineTemplate) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineTemplate.
@@ -578,6 +610,42 @@ func (in *AzureMachineTemplateSpec) DeepCopy() *AzureMachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachineTemplateStatus) DeepCopyInto(out *AzureMachineTemplateStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeTaints != nil {
+		in, out := &in.NodeTaints, &out.NodeTaints
+		*out = make([]v1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachineTemplateStatus.
+func (in *AzureMachineTemplateStatus) DeepCopy() *AzureMachineTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachineTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMarketplaceImage) DeepCopyInto(out *AzureMarketplaceImage) {
 	*out = *in
@@ -593,6 +661,80 @@ func (in *AzureMarketplaceImage) DeepCopy() *AzureMarketplaceImage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureNamespacedIdentity) DeepCopyInto(out *AzureNamespacedIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureNamespacedIdentity.
+func (in *AzureNamespacedIdentity) DeepCopy() *AzureNamespacedIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureNamespacedIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AzureNamespacedIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureNamespacedIdentityList) DeepCopyInto(out *AzureNamespacedIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AzureNamespacedIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureNamespacedIdentityList.
+func (in *AzureNamespacedIdentityList) DeepCopy() *AzureNamespacedIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureNamespacedIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AzureNamespacedIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureNamespacedIdentitySpec) DeepCopyInto(out *AzureNamespacedIdentitySpec) {
+	*out = *in
+	out.ClientSecret = in.ClientSecret
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureNamespacedIdentitySpec.
+func (in *AzureNamespacedIdentitySpec) DeepCopy() *AzureNamespacedIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureNamespacedIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureSharedGalleryImage) DeepCopyInto(out *AzureSharedGalleryImage) {
 	*out = *in
@@ -668,6 +810,26 @@ func (in *BastionSpec) DeepCopy() *BastionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootDiagnostics) DeepCopyInto(out *BootDiagnostics) {
+	*out = *in
+	if in.UserManaged != nil {
+		in, out := &in.UserManaged, &out.UserManaged
+		*out = new(UserManagedBootDiagnostics)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootDiagnostics.
+func (in *BootDiagnostics) DeepCopy() *BootDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(BootDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildParams) DeepCopyInto(out *BuildParams) {
 	*out = *in
@@ -748,6 +910,26 @@ func (in *DataDisk) DeepCopy() *DataDisk {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Diagnostics) DeepCopyInto(out *Diagnostics) {
+	*out = *in
+	if in.Boot != nil {
+		in, out := &in.Boot, &out.Boot
+		*out = new(BootDiagnostics)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Diagnostics.
+func (in *Diagnostics) DeepCopy() *Diagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(Diagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiffDiskSettings) DeepCopyInto(out *DiffDiskSettings) {
 	*out = *in
@@ -862,6 +1044,21 @@ func (in *Image) DeepCopy() *Image {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyVaultSpec) DeepCopyInto(out *KeyVaultSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyVaultSpec.
+func (in *KeyVaultSpec) DeepCopy() *KeyVaultSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyVaultSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerSpec) DeepCopyInto(out *LoadBalancerSpec) {
 	*out = *in
@@ -930,6 +1127,26 @@ func (in *NatGateway) DeepCopy() *NatGateway {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkInterface) DeepCopyInto(out *NetworkInterface) {
+	*out = *in
+	if in.AcceleratedNetworking != nil {
+		in, out := &in.AcceleratedNetworking, &out.AcceleratedNetworking
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkInterface.
+func (in *NetworkInterface) DeepCopy() *NetworkInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 	*out = *in
@@ -1050,6 +1267,21 @@ func (in *RateLimitSpec) DeepCopy() *RateLimitSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleAssignment) DeepCopyInto(out *RoleAssignment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleAssignment.
+func (in *RoleAssignment) DeepCopy() *RoleAssignment {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleAssignment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RouteTable) DeepCopyInto(out *RouteTable) {
 	*out = *in
@@ -1178,6 +1410,16 @@ func (in *SpotVMOptions) DeepCopyInto(out *SpotVMOptions) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.EvictionPolicy != nil {
+		in, out := &in.EvictionPolicy, &out.EvictionPolicy
+		*out = new(SpotEvictionPolicy)
+		**out = **in
+	}
+	if in.RecoveryPolicy != nil {
+		in, out := &in.RecoveryPolicy, &out.RecoveryPolicy
+		*out = new(SpotRecoveryPolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotVMOptions.
@@ -1270,9 +1512,56 @@ func (in *UserAssignedIdentity) DeepCopy() *UserAssignedIdentity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserManagedBootDiagnostics) DeepCopyInto(out *UserManagedBootDiagnostics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserManagedBootDiagnostics.
+func (in *UserManagedBootDiagnostics) DeepCopy() *UserManagedBootDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(UserManagedBootDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VnetPeeringProperties) DeepCopyInto(out *VnetPeeringProperties) {
+	*out = *in
+	if in.AllowForwardedTraffic != nil {
+		in, out := &in.AllowForwardedTraffic, &out.AllowForwardedTraffic
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowGatewayTransit != nil {
+		in, out := &in.AllowGatewayTransit, &out.AllowGatewayTransit
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UseRemoteGateways != nil {
+		in, out := &in.UseRemoteGateways, &out.UseRemoteGateways
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VnetPeeringProperties.
+func (in *VnetPeeringProperties) DeepCopy() *VnetPeeringProperties {
+	if in == nil {
+		return nil
+	}
+	out := new(VnetPeeringProperties)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VnetPeeringSpec) DeepCopyInto(out *VnetPeeringSpec) {
 	*out = *in
+	in.ForwardPeeringProperties.DeepCopyInto(&out.ForwardPeeringProperties)
+	in.ReversePeeringProperties.DeepCopyInto(&out.ReversePeeringProperties)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VnetPeeringSpec.
@@ -1290,7 +1579,9 @@ func (in VnetPeerings) DeepCopyInto(out *VnetPeerings) {
 	{
 		in := &in
 		*out = make(VnetPeerings, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -1315,7 +1606,9 @@ func (in *VnetSpec) DeepCopyInto(out *VnetSpec) {
 	if in.Peerings != nil {
 		in, out := &in.Peerings, &out.Peerings
 		*out = make(VnetPeerings, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
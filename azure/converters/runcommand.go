@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+const (
+	// cloudProviderConfigRunCommandTimeout is how long the script writing the cloud provider config may run, in seconds.
+	cloudProviderConfigRunCommandTimeout = 300
+
+	// runCommandErrorLength is the maximum length of the error stream of a run command included in its error.
+	runCommandErrorLength = 512
+)
+
+// CloudProviderConfigToSDKRunCommand converts the cloud provider config of a virtual machine to the managed run command
+// writing it. The hash of the config and the time of the request are passed as parameters, so that the command runs
+// again whenever it is requested, e.g. when a failed update is retried.
+func CloudProviderConfigToSDKRunCommand(config azure.CloudProviderConfig, location string, requestedAt time.Time) compute.VirtualMachineRunCommand {
+	parameters := []compute.RunCommandInputParameter{
+		{Name: to.StringPtr("CONFIG_HASH"), Value: to.StringPtr(config.Hash)},
+		{Name: to.StringPtr("REQUESTED_AT"), Value: to.StringPtr(requestedAt.UTC().Format(time.RFC3339))},
+	}
+
+	names := make([]string, 0, len(config.ProtectedParameters))
+	for name := range config.ProtectedParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	protectedParameters := make([]compute.RunCommandInputParameter, 0, len(names))
+	for _, name := range names {
+		protectedParameters = append(protectedParameters, compute.RunCommandInputParameter{
+			Name:  to.StringPtr(name),
+			Value: to.StringPtr(config.ProtectedParameters[name]),
+		})
+	}
+
+	return compute.VirtualMachineRunCommand{
+		Location: to.StringPtr(location),
+		VirtualMachineRunCommandProperties: &compute.VirtualMachineRunCommandProperties{
+			Source: &compute.VirtualMachineRunCommandScriptSource{
+				Script: to.StringPtr(config.Script),
+			},
+			Parameters:          &parameters,
+			ProtectedParameters: &protectedParameters,
+			AsyncExecution:      to.BoolPtr(false),
+			TimeoutInSeconds:    to.Int32Ptr(cloudProviderConfigRunCommandTimeout),
+		},
+	}
+}
+
+// SDKRunCommandError returns an error with the exit code and the end of the error stream of a managed run command
+// which did not succeed, or nil if it succeeded.
+func SDKRunCommandError(runCommand compute.VirtualMachineRunCommand) error {
+	if runCommand.VirtualMachineRunCommandProperties == nil || runCommand.InstanceView == nil {
+		return errors.New("run command has no instance view")
+	}
+
+	view := runCommand.InstanceView
+	if view.ExecutionState == compute.ExecutionStateSucceeded && to.Int32(view.ExitCode) == 0 {
+		return nil
+	}
+	message := strings.TrimSpace(to.String(view.Error))
+	if message == "" {
+		message = strings.TrimSpace(to.String(view.ExecutionMessage))
+	}
+	if len(message) > runCommandErrorLength {
+		message = "..." + message[len(message)-runCommandErrorLength:]
+	}
+	return errors.Errorf("run command %s with exit code %d: %s", strings.ToLower(string(view.ExecutionState)), to.Int32(view.ExitCode), message)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+func TestCloudProviderConfigToSDKRunCommand(t *testing.T) {
+	g := NewWithT(t)
+
+	config := azure.CloudProviderConfig{
+		Hash:   "hash",
+		Script: "script",
+		ProtectedParameters: map[string]string{
+			"SECOND": "b",
+			"FIRST":  "a",
+		},
+	}
+	runCommand := CloudProviderConfigToSDKRunCommand(config, "westus", time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	g.Expect(runCommand.Location).To(Equal(to.StringPtr("westus")))
+	g.Expect(runCommand.Source.Script).To(Equal(to.StringPtr("script")))
+	g.Expect(*runCommand.Parameters).To(Equal([]compute.RunCommandInputParameter{
+		{Name: to.StringPtr("CONFIG_HASH"), Value: to.StringPtr("hash")},
+		{Name: to.StringPtr("REQUESTED_AT"), Value: to.StringPtr("2022-01-02T03:04:05Z")},
+	}))
+	g.Expect(*runCommand.ProtectedParameters).To(Equal([]compute.RunCommandInputParameter{
+		{Name: to.StringPtr("FIRST"), Value: to.StringPtr("a")},
+		{Name: to.StringPtr("SECOND"), Value: to.StringPtr("b")},
+	}))
+	g.Expect(runCommand.AsyncExecution).To(Equal(to.BoolPtr(false)))
+}
+
+func TestSDKRunCommandError(t *testing.T) {
+	tests := []struct {
+		name          string
+		instanceView  *compute.VirtualMachineRunCommandInstanceView
+		expectedError string
+	}{
+		{
+			name: "succeeded",
+			instanceView: &compute.VirtualMachineRunCommandInstanceView{
+				ExecutionState: compute.ExecutionStateSucceeded,
+				ExitCode:       to.Int32Ptr(0),
+			},
+		},
+		{
+			name:          "no instance view",
+			expectedError: "run command has no instance view",
+		},
+		{
+			name: "non-zero exit code",
+			instanceView: &compute.VirtualMachineRunCommandInstanceView{
+				ExecutionState: compute.ExecutionStateFailed,
+				ExitCode:       to.Int32Ptr(1),
+				Error:          to.StringPtr("base64: invalid input\n"),
+			},
+			expectedError: "run command failed with exit code 1: base64: invalid input",
+		},
+		{
+			name: "timed out",
+			instanceView: &compute.VirtualMachineRunCommandInstanceView{
+				ExecutionState:   compute.ExecutionStateTimedOut,
+				ExecutionMessage: to.StringPtr("Execution timed out"),
+			},
+			expectedError: "run command timedout with exit code 0: Execution timed out",
+		},
+		{
+			name: "long error stream is truncated",
+			instanceView: &compute.VirtualMachineRunCommandInstanceView{
+				ExecutionState: compute.ExecutionStateFailed,
+				ExitCode:       to.Int32Ptr(2),
+				Error:          to.StringPtr(strings.Repeat("a", 1000) + "end"),
+			},
+			expectedError: "run command failed with exit code 2: ..." + strings.Repeat("a", runCommandErrorLength-3) + "end",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			runCommand := compute.VirtualMachineRunCommand{}
+			if tc.instanceView != nil {
+				runCommand.VirtualMachineRunCommandProperties = &compute.VirtualMachineRunCommandProperties{InstanceView: tc.instanceView}
+			}
+			err := SDKRunCommandError(runCommand)
+			if tc.expectedError == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+			} else {
+				g.Expect(err).To(MatchError(tc.expectedError))
+			}
+		})
+	}
+}
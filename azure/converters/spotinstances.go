@@ -20,9 +20,13 @@ import (
 	"strconv"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
+// powerStateDeallocated is the instance view status code of a deallocated virtual machine.
+const powerStateDeallocated = "PowerState/deallocated"
+
 // GetSpotVMOptions takes the spot vm options
 // and returns the individual vm priority, eviction policy and billing profile.
 func GetSpotVMOptions(spotVMOptions *infrav1.SpotVMOptions) (compute.VirtualMachinePriorityTypes, compute.VirtualMachineEvictionPolicyTypes, *compute.BillingProfile, error) {
@@ -40,5 +44,22 @@ func GetSpotVMOptions(spotVMOptions *infrav1.SpotVMOptions) (compute.VirtualMach
 			MaxPrice: &maxPrice,
 		}
 	}
-	return compute.VirtualMachinePriorityTypesSpot, compute.VirtualMachineEvictionPolicyTypesDeallocate, billingProfile, nil
+	evictionPolicy := compute.VirtualMachineEvictionPolicyTypesDeallocate
+	if spotVMOptions.EvictionPolicy != nil {
+		evictionPolicy = compute.VirtualMachineEvictionPolicyTypes(*spotVMOptions.EvictionPolicy)
+	}
+	return compute.VirtualMachinePriorityTypesSpot, evictionPolicy, billingProfile, nil
+}
+
+// IsDeallocated returns true if the instance view statuses report a deallocated power state.
+func IsDeallocated(statuses *[]compute.InstanceViewStatus) bool {
+	if statuses == nil {
+		return false
+	}
+	for _, status := range *statuses {
+		if to.String(status.Code) == powerStateDeallocated {
+			return true
+		}
+	}
+	return false
 }
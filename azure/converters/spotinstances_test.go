@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package converters
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+)
+
+func TestGetSpotVMOptions(t *testing.T) {
+	deletePolicy := infrav1.SpotEvictionPolicyDelete
+	deallocatePolicy := infrav1.SpotEvictionPolicyDeallocate
+	maxPrice := resource.MustParse("0.001")
+
+	tests := []struct {
+		name               string
+		spotVMOptions      *infrav1.SpotVMOptions
+		wantPriority       compute.VirtualMachinePriorityTypes
+		wantEvictionPolicy compute.VirtualMachineEvictionPolicyTypes
+		wantBillingProfile *compute.BillingProfile
+	}{
+		{
+			name:          "spot not requested",
+			spotVMOptions: nil,
+		},
+		{
+			name:               "deallocate by default",
+			spotVMOptions:      &infrav1.SpotVMOptions{},
+			wantPriority:       compute.VirtualMachinePriorityTypesSpot,
+			wantEvictionPolicy: compute.VirtualMachineEvictionPolicyTypesDeallocate,
+		},
+		{
+			name:               "deallocate eviction policy",
+			spotVMOptions:      &infrav1.SpotVMOptions{EvictionPolicy: &deallocatePolicy},
+			wantPriority:       compute.VirtualMachinePriorityTypesSpot,
+			wantEvictionPolicy: compute.VirtualMachineEvictionPolicyTypesDeallocate,
+		},
+		{
+			name:               "delete eviction policy with max price",
+			spotVMOptions:      &infrav1.SpotVMOptions{EvictionPolicy: &deletePolicy, MaxPrice: &maxPrice},
+			wantPriority:       compute.VirtualMachinePriorityTypesSpot,
+			wantEvictionPolicy: compute.VirtualMachineEvictionPolicyTypesDelete,
+			wantBillingProfile: &compute.BillingProfile{MaxPrice: to.Float64Ptr(0.001)},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			priority, evictionPolicy, billingProfile, err := GetSpotVMOptions(tc.spotVMOptions)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(priority).To(Equal(tc.wantPriority))
+			g.Expect(evictionPolicy).To(Equal(tc.wantEvictionPolicy))
+			g.Expect(billingProfile).To(Equal(tc.wantBillingProfile))
+		})
+	}
+}
+
+func TestIsDeallocated(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses *[]compute.InstanceViewStatus
+		want     bool
+	}{
+		{
+			name:     "no instance view",
+			statuses: nil,
+			want:     false,
+		},
+		{
+			name: "running",
+			statuses: &[]compute.InstanceViewStatus{
+				{Code: to.StringPtr("ProvisioningState/succeeded")},
+				{Code: to.StringPtr("PowerState/running")},
+			},
+			want: false,
+		},
+		{
+			name: "deallocated",
+			statuses: &[]compute.InstanceViewStatus{
+				{Code: to.StringPtr("ProvisioningState/succeeded")},
+				{Code: to.StringPtr("PowerState/deallocated")},
+			},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(IsDeallocated(tc.statuses)).To(Equal(tc.want))
+		})
+	}
+}
@@ -58,6 +58,10 @@ func SDKToVMSS(sdkvmss compute.VirtualMachineScaleSet, sdkinstances []compute.Vi
 		vmss.Image = SDKImageToImage(imageRef, sdkvmss.Plan != nil)
 	}
 
+	if sdkvmss.VirtualMachineScaleSetProperties != nil && sdkvmss.AutomaticRepairsPolicy != nil && to.Bool(sdkvmss.AutomaticRepairsPolicy.Enabled) {
+		vmss.AutomaticRepairsGracePeriod = to.String(sdkvmss.AutomaticRepairsPolicy.GracePeriod)
+	}
+
 	return vmss
 }
 
@@ -91,6 +95,45 @@ func SDKToVMSSVM(sdkInstance compute.VirtualMachineScaleSetVM) *azure.VMSSVM {
 		instance.AvailabilityZone = to.StringSlice(sdkInstance.Zones)[0]
 	}
 
+	if sdkInstance.ProtectionPolicy != nil {
+		instance.ProtectFromScaleIn = to.Bool(sdkInstance.ProtectionPolicy.ProtectFromScaleIn)
+		instance.ProtectFromScaleSetActions = to.Bool(sdkInstance.ProtectionPolicy.ProtectFromScaleSetActions)
+	}
+
+	return &instance
+}
+
+// SDKVMToVMSSVM converts an Azure SDK VirtualMachine of a Flexible scale set into an azure.VMSSVM. Flexible instances
+// are standalone virtual machines, so their resource name is used as the instance ID.
+func SDKVMToVMSSVM(sdkInstance compute.VirtualMachine) *azure.VMSSVM {
+	instance := azure.VMSSVM{
+		ID:         to.String(sdkInstance.ID),
+		InstanceID: to.String(sdkInstance.Name),
+	}
+
+	if sdkInstance.VirtualMachineProperties == nil {
+		return &instance
+	}
+
+	instance.State = infrav1.Creating
+	if sdkInstance.ProvisioningState != nil {
+		instance.State = infrav1.ProvisioningState(to.String(sdkInstance.ProvisioningState))
+	}
+
+	if sdkInstance.OsProfile != nil && sdkInstance.OsProfile.ComputerName != nil {
+		instance.Name = *sdkInstance.OsProfile.ComputerName
+	}
+
+	if sdkInstance.StorageProfile != nil && sdkInstance.StorageProfile.ImageReference != nil {
+		imageRef := sdkInstance.StorageProfile.ImageReference
+		instance.Image = SDKImageToImage(imageRef, sdkInstance.Plan != nil)
+	}
+
+	if sdkInstance.Zones != nil && len(*sdkInstance.Zones) > 0 {
+		// an instance should only have 1 zone, so we select the first item of the slice
+		instance.AvailabilityZone = to.StringSlice(sdkInstance.Zones)[0]
+	}
+
 	return &instance
 }
 
@@ -109,6 +109,65 @@ func Test_SDKToVMSS(t *testing.T) {
 				g.Expect(actual).To(gomega.Equal(&expected))
 			},
 		},
+		{
+			Name: "ShouldPopulateInstanceProtection",
+			SubjectFactory: func(g *gomega.GomegaWithT) (compute.VirtualMachineScaleSet, []compute.VirtualMachineScaleSetVM) {
+				return compute.VirtualMachineScaleSet{
+						ID:   to.StringPtr("vmssID"),
+						Name: to.StringPtr("vmssName"),
+						VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+							ProvisioningState: to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+						},
+					},
+					[]compute.VirtualMachineScaleSetVM{
+						{
+							InstanceID: to.StringPtr("0"),
+							ID:         to.StringPtr("vm/0"),
+							VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+								ProvisioningState: to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+								ProtectionPolicy: &compute.VirtualMachineScaleSetVMProtectionPolicy{
+									ProtectFromScaleIn:         to.BoolPtr(true),
+									ProtectFromScaleSetActions: to.BoolPtr(false),
+								},
+							},
+						},
+					}
+			},
+			Expect: func(g *gomega.GomegaWithT, actual *azure.VMSS) {
+				g.Expect(actual.Instances).To(gomega.Equal([]azure.VMSSVM{
+					{
+						ID:                 "vm/0",
+						InstanceID:         "0",
+						State:              "Succeeded",
+						ProtectFromScaleIn: true,
+					},
+				}))
+			},
+		},
+		{
+			Name: "ShouldPopulateAutomaticRepairsGracePeriod",
+			SubjectFactory: func(g *gomega.GomegaWithT) (compute.VirtualMachineScaleSet, []compute.VirtualMachineScaleSetVM) {
+				return compute.VirtualMachineScaleSet{
+					ID:   to.StringPtr("vmssID"),
+					Name: to.StringPtr("vmssName"),
+					VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+						ProvisioningState: to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+						AutomaticRepairsPolicy: &compute.AutomaticRepairsPolicy{
+							Enabled:     to.BoolPtr(true),
+							GracePeriod: to.StringPtr("PT30M"),
+						},
+					},
+				}, nil
+			},
+			Expect: func(g *gomega.GomegaWithT, actual *azure.VMSS) {
+				g.Expect(actual).To(gomega.Equal(&azure.VMSS{
+					ID:                          "vmssID",
+					Name:                        "vmssName",
+					State:                       "Succeeded",
+					AutomaticRepairsGracePeriod: "PT30M",
+				}))
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -122,3 +181,53 @@ func Test_SDKToVMSS(t *testing.T) {
 		})
 	}
 }
+
+func Test_SDKVMToVMSSVM(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Subject  compute.VirtualMachine
+		Expected *azure.VMSSVM
+	}{
+		{
+			Name: "ShouldPopulateWithData",
+			Subject: compute.VirtualMachine{
+				ID:    to.StringPtr("/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vmss_0123abcd"),
+				Name:  to.StringPtr("vmss_0123abcd"),
+				Zones: to.StringSlicePtr([]string{"1"}),
+				VirtualMachineProperties: &compute.VirtualMachineProperties{
+					ProvisioningState: to.StringPtr(string(compute.ProvisioningState1Succeeded)),
+					OsProfile: &compute.OSProfile{
+						ComputerName: to.StringPtr("vmssa1b2c3"),
+					},
+				},
+			},
+			Expected: &azure.VMSSVM{
+				ID:               "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vmss_0123abcd",
+				InstanceID:       "vmss_0123abcd",
+				Name:             "vmssa1b2c3",
+				AvailabilityZone: "1",
+				State:            "Succeeded",
+			},
+		},
+		{
+			Name: "ShouldReturnOnlyIDsWithoutProperties",
+			Subject: compute.VirtualMachine{
+				ID:   to.StringPtr("vm/vmss_0123abcd"),
+				Name: to.StringPtr("vmss_0123abcd"),
+			},
+			Expected: &azure.VMSSVM{
+				ID:         "vm/vmss_0123abcd",
+				InstanceID: "vmss_0123abcd",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			g := gomega.NewGomegaWithT(t)
+			g.Expect(converters.SDKVMToVMSSVM(c.Subject)).To(gomega.Equal(c.Expected))
+		})
+	}
+}
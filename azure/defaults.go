@@ -78,6 +78,12 @@ const (
 	bootstrapSentinelFile = "/run/cluster-api/bootstrap-success.complete"
 )
 
+const (
+	// CloudProviderConfigRunCommandName is the name of the run command writing the cloud provider config on a
+	// virtual machine.
+	CloudProviderConfigRunCommandName = "capz-cloud-provider-config"
+)
+
 const (
 	// ProviderIDPrefix will be appended to the beginning of Azure resource IDs to form the Kubernetes Provider ID.
 	// NOTE: this format matches the 2 slashes format used in cloud-provider and cluster-autoscaler.
@@ -255,6 +261,11 @@ func AvailabilitySetID(subscriptionID, resourceGroup, availabilitySetName string
 	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/availabilitySets/%s", subscriptionID, resourceGroup, availabilitySetName)
 }
 
+// KeyVaultID returns the azure resource ID for a given Key Vault.
+func KeyVaultID(subscriptionID, resourceGroup, keyVaultName string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.KeyVault/vaults/%s", subscriptionID, resourceGroup, keyVaultName)
+}
+
 // GetDefaultImageSKUID gets the SKU ID of the image to use for the provided version of Kubernetes.
 func getDefaultImageSKUID(k8sVersion, os, osVersion string) (string, error) {
 	version, err := semver.ParseTolerant(k8sVersion)
@@ -367,6 +378,33 @@ func GetBootstrappingVMExtension(osType string, cloud string, vmName string) *Ex
 	return nil
 }
 
+// GetApplicationHealthExtension returns the application health extension for a scale set. See
+// https://docs.microsoft.com/en-us/azure/virtual-machine-scale-sets/virtual-machine-scale-sets-health-extension.
+// The extension probes the given endpoint on each instance and reports its health to the scale set, which is
+// required for automatic instance repairs.
+func GetApplicationHealthExtension(osType string, vmName string, protocol string, port int32, requestPath string) *ExtensionSpec {
+	name := "ApplicationHealthLinux"
+	if osType == WindowsOS {
+		name = "ApplicationHealthWindows"
+	}
+
+	settings := map[string]interface{}{
+		"protocol": protocol,
+		"port":     port,
+	}
+	if requestPath != "" {
+		settings["requestPath"] = requestPath
+	}
+
+	return &ExtensionSpec{
+		Name:      name,
+		VMName:    vmName,
+		Publisher: "Microsoft.ManagedServices",
+		Version:   "1.0",
+		Settings:  settings,
+	}
+}
+
 // UserAgent specifies a string to append to the agent identifier.
 func UserAgent() string {
 	return fmt.Sprintf("cluster-api-provider-azure/%s", version.Get().String())
@@ -389,6 +427,15 @@ func SetAutoRestClientDefaults(c *autorest.Client, auth autorest.Authorizer) {
 	AutoRestClientAppendUserAgent(c, UserAgent())
 }
 
+// AuthorizerForSubscription returns the authorizer of the requests to the subscription, which is the secondary one for
+// the subscriptions other than the one of the cluster.
+func AuthorizerForSubscription(auth CrossSubscriptionAuthorizer, subscriptionID string) autorest.Authorizer {
+	if subscriptionID == "" || subscriptionID == auth.SubscriptionID() {
+		return auth.Authorizer()
+	}
+	return auth.SecondaryAuthorizer()
+}
+
 // AutoRestClientAppendUserAgent autorest client calls "AddToUserAgent" but ignores errors.
 func AutoRestClientAppendUserAgent(c *autorest.Client, extension string) {
 	_ = c.AddToUserAgent(extension) // intentionally ignore error as it doesn't matter
@@ -290,3 +290,37 @@ func TestMSCorrelationIDSendDecorator(t *testing.T) {
 		receivedReq.Header.Get(string(tele.CorrIDKeyVal)),
 	).To(Equal(string(corrID)))
 }
+
+// scopeAuthorizer lets fakeCrossSubscriptionAuthorizer embed the methods of Authorizer it does not implement.
+type scopeAuthorizer = Authorizer
+
+// fakeCrossSubscriptionAuthorizer is a CrossSubscriptionAuthorizer with distinct primary and secondary authorizers.
+type fakeCrossSubscriptionAuthorizer struct {
+	scopeAuthorizer
+	primary   autorest.Authorizer
+	secondary autorest.Authorizer
+}
+
+func (a fakeCrossSubscriptionAuthorizer) SubscriptionID() string {
+	return "123"
+}
+
+func (a fakeCrossSubscriptionAuthorizer) Authorizer() autorest.Authorizer {
+	return a.primary
+}
+
+func (a fakeCrossSubscriptionAuthorizer) SecondaryAuthorizer() autorest.Authorizer {
+	return a.secondary
+}
+
+func TestAuthorizerForSubscription(t *testing.T) {
+	g := NewWithT(t)
+
+	auth := fakeCrossSubscriptionAuthorizer{
+		primary:   autorest.NewBearerAuthorizer(nil),
+		secondary: autorest.NewBearerAuthorizer(nil),
+	}
+	g.Expect(AuthorizerForSubscription(auth, "")).To(BeIdenticalTo(auth.primary))
+	g.Expect(AuthorizerForSubscription(auth, "123")).To(BeIdenticalTo(auth.primary))
+	g.Expect(AuthorizerForSubscription(auth, "456")).To(BeIdenticalTo(auth.secondary))
+}
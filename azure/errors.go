@@ -30,7 +30,11 @@ import (
 // ErrNotOwned is returned when a resource can't be deleted because it isn't owned.
 var ErrNotOwned = errors.New("resource is not managed and cannot be deleted")
 
-const codeResourceGroupNotFound = "ResourceGroupNotFound"
+const (
+	codeResourceGroupNotFound            = "ResourceGroupNotFound"
+	codeSkuNotAvailable                  = "SkuNotAvailable"
+	codeOverconstrainedAllocationRequest = "OverconstrainedAllocationRequest"
+)
 
 // ResourceGroupNotFound parses the error to check if it's a resource group not found error.
 func ResourceGroupNotFound(err error) bool {
@@ -51,6 +55,21 @@ func ResourceConflict(err error) bool {
 	return errors.As(err, &derr) && derr.StatusCode == 409
 }
 
+// CapacityUnavailable parses the error to check if Azure could not allocate the requested VM capacity, either
+// synchronously or as the result of a long-running operation.
+func CapacityUnavailable(err error) bool {
+	code := ""
+	serr := &azure.ServiceError{}
+	rerr := &azure.RequestError{}
+	switch {
+	case errors.As(err, &serr):
+		code = serr.Code
+	case errors.As(err, &rerr) && rerr.ServiceError != nil:
+		code = rerr.ServiceError.Code
+	}
+	return code == codeSkuNotAvailable || code == codeOverconstrainedAllocationRequest
+}
+
 // VMDeletedError is returned when a virtual machine is deleted outside of capz.
 type VMDeletedError struct {
 	ProviderID string
@@ -51,6 +51,13 @@ type Authorizer interface {
 	HashKey() string
 }
 
+// CrossSubscriptionAuthorizer is an Authorizer which can also authorize the requests to the resources of a cluster in
+// other subscriptions than its own, e.g. a hub virtual network or private DNS zone.
+type CrossSubscriptionAuthorizer interface {
+	Authorizer
+	SecondaryAuthorizer() autorest.Authorizer
+}
+
 // NetworkDescriber is an interface which can get common Azure Cluster Networking information.
 type NetworkDescriber interface {
 	Vnet() *infrav1.VnetSpec
@@ -93,6 +100,15 @@ type AsyncStatusUpdater interface {
 	UpdatePatchStatus(clusterv1.ConditionType, string, error)
 }
 
+// KeyVaultDescriber is an interface which can get the Key Vault storing the secrets of the machines of a cluster, and
+// authorize the requests to its data plane.
+type KeyVaultDescriber interface {
+	KeyVault() *infrav1.KeyVaultSpec
+	KeyVaultID() string
+	KeyVaultURL() string
+	KeyVaultAuthorizer() autorest.Authorizer
+}
+
 // ClusterScoper combines the ClusterDescriber and NetworkDescriber interfaces.
 type ClusterScoper interface {
 	ClusterDescriber
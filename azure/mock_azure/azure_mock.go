@@ -282,6 +282,155 @@ func (mr *MockAuthorizerMockRecorder) TenantID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantID", reflect.TypeOf((*MockAuthorizer)(nil).TenantID))
 }
 
+// MockCrossSubscriptionAuthorizer is a mock of CrossSubscriptionAuthorizer interface.
+type MockCrossSubscriptionAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockCrossSubscriptionAuthorizerMockRecorder
+}
+
+// MockCrossSubscriptionAuthorizerMockRecorder is the mock recorder for MockCrossSubscriptionAuthorizer.
+type MockCrossSubscriptionAuthorizerMockRecorder struct {
+	mock *MockCrossSubscriptionAuthorizer
+}
+
+// NewMockCrossSubscriptionAuthorizer creates a new mock instance.
+func NewMockCrossSubscriptionAuthorizer(ctrl *gomock.Controller) *MockCrossSubscriptionAuthorizer {
+	mock := &MockCrossSubscriptionAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockCrossSubscriptionAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCrossSubscriptionAuthorizer) EXPECT() *MockCrossSubscriptionAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// Authorizer mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) Authorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// Authorizer indicates an expected call of Authorizer.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) Authorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorizer", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).Authorizer))
+}
+
+// BaseURI mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) BaseURI() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BaseURI")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// BaseURI indicates an expected call of BaseURI.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) BaseURI() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BaseURI", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).BaseURI))
+}
+
+// ClientID mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) ClientID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ClientID indicates an expected call of ClientID.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) ClientID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientID", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).ClientID))
+}
+
+// ClientSecret mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) ClientSecret() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClientSecret")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ClientSecret indicates an expected call of ClientSecret.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) ClientSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClientSecret", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).ClientSecret))
+}
+
+// CloudEnvironment mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) CloudEnvironment() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudEnvironment")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// CloudEnvironment indicates an expected call of CloudEnvironment.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) CloudEnvironment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).CloudEnvironment))
+}
+
+// HashKey mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) HashKey() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HashKey")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// HashKey indicates an expected call of HashKey.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) HashKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).HashKey))
+}
+
+// SecondaryAuthorizer mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) SecondaryAuthorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecondaryAuthorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// SecondaryAuthorizer indicates an expected call of SecondaryAuthorizer.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) SecondaryAuthorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecondaryAuthorizer", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).SecondaryAuthorizer))
+}
+
+// SubscriptionID mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) SubscriptionID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscriptionID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SubscriptionID indicates an expected call of SubscriptionID.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) SubscriptionID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionID", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).SubscriptionID))
+}
+
+// TenantID mocks base method.
+func (m *MockCrossSubscriptionAuthorizer) TenantID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// TenantID indicates an expected call of TenantID.
+func (mr *MockCrossSubscriptionAuthorizerMockRecorder) TenantID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantID", reflect.TypeOf((*MockCrossSubscriptionAuthorizer)(nil).TenantID))
+}
+
 // MockNetworkDescriber is a mock of NetworkDescriber interface.
 type MockNetworkDescriber struct {
 	ctrl     *gomock.Controller
@@ -857,6 +1006,85 @@ func (mr *MockAsyncStatusUpdaterMockRecorder) UpdatePutStatus(arg0, arg1, arg2 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePutStatus", reflect.TypeOf((*MockAsyncStatusUpdater)(nil).UpdatePutStatus), arg0, arg1, arg2)
 }
 
+// MockKeyVaultDescriber is a mock of KeyVaultDescriber interface.
+type MockKeyVaultDescriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyVaultDescriberMockRecorder
+}
+
+// MockKeyVaultDescriberMockRecorder is the mock recorder for MockKeyVaultDescriber.
+type MockKeyVaultDescriberMockRecorder struct {
+	mock *MockKeyVaultDescriber
+}
+
+// NewMockKeyVaultDescriber creates a new mock instance.
+func NewMockKeyVaultDescriber(ctrl *gomock.Controller) *MockKeyVaultDescriber {
+	mock := &MockKeyVaultDescriber{ctrl: ctrl}
+	mock.recorder = &MockKeyVaultDescriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyVaultDescriber) EXPECT() *MockKeyVaultDescriberMockRecorder {
+	return m.recorder
+}
+
+// KeyVault mocks base method.
+func (m *MockKeyVaultDescriber) KeyVault() *v1beta1.KeyVaultSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVault")
+	ret0, _ := ret[0].(*v1beta1.KeyVaultSpec)
+	return ret0
+}
+
+// KeyVault indicates an expected call of KeyVault.
+func (mr *MockKeyVaultDescriberMockRecorder) KeyVault() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVault", reflect.TypeOf((*MockKeyVaultDescriber)(nil).KeyVault))
+}
+
+// KeyVaultAuthorizer mocks base method.
+func (m *MockKeyVaultDescriber) KeyVaultAuthorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultAuthorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// KeyVaultAuthorizer indicates an expected call of KeyVaultAuthorizer.
+func (mr *MockKeyVaultDescriberMockRecorder) KeyVaultAuthorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultAuthorizer", reflect.TypeOf((*MockKeyVaultDescriber)(nil).KeyVaultAuthorizer))
+}
+
+// KeyVaultID mocks base method.
+func (m *MockKeyVaultDescriber) KeyVaultID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyVaultID indicates an expected call of KeyVaultID.
+func (mr *MockKeyVaultDescriberMockRecorder) KeyVaultID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultID", reflect.TypeOf((*MockKeyVaultDescriber)(nil).KeyVaultID))
+}
+
+// KeyVaultURL mocks base method.
+func (m *MockKeyVaultDescriber) KeyVaultURL() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultURL")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyVaultURL indicates an expected call of KeyVaultURL.
+func (mr *MockKeyVaultDescriberMockRecorder) KeyVaultURL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultURL", reflect.TypeOf((*MockKeyVaultDescriber)(nil).KeyVaultURL))
+}
+
 // MockClusterScoper is a mock of ClusterScoper interface.
 type MockClusterScoper struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+)
+
+const (
+	// defaultMaxPods is the maximum number of pods kubelet runs on a node unless it is configured otherwise.
+	defaultMaxPods = 110
+
+	// gpuResourceName is the extended resource advertised by the NVIDIA device plugin for the GPUs of a node.
+	gpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+)
+
+// NodeCapacity returns the resource capacity of a node running on a virtual machine of the given SKU. The cluster
+// autoscaler uses it to scale a node group up from zero replicas, when there is no node to look at.
+func NodeCapacity(sku resourceskus.SKU, osDisk infrav1.OSDisk) (corev1.ResourceList, error) {
+	capacity := corev1.ResourceList{
+		corev1.ResourcePods: *resource.NewQuantity(defaultMaxPods, resource.DecimalSI),
+	}
+
+	if vCPUs, ok := sku.GetCapability(resourceskus.VCPUs); ok {
+		cpu, err := resource.ParseQuantity(vCPUs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the vCPUs of VM size %s", to.String(sku.Name))
+		}
+		capacity[corev1.ResourceCPU] = cpu
+	}
+
+	if memoryGB, ok := sku.GetCapability(resourceskus.MemoryGB); ok {
+		memory, err := resource.ParseQuantity(memoryGB + "Gi")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the memory of VM size %s", to.String(sku.Name))
+		}
+		capacity[corev1.ResourceMemory] = memory
+	}
+
+	if gpus, ok := sku.GetCapability(resourceskus.GPUs); ok {
+		gpu, err := resource.ParseQuantity(gpus)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the GPUs of VM size %s", to.String(sku.Name))
+		}
+		if !gpu.IsZero() {
+			capacity[gpuResourceName] = gpu
+		}
+	}
+
+	// the ephemeral storage of a node is backed by its OS disk
+	if osDisk.DiskSizeGB != nil {
+		capacity[corev1.ResourceEphemeralStorage] = *resource.NewQuantity(int64(*osDisk.DiskSizeGB)<<30, resource.BinarySI)
+	}
+
+	return capacity, nil
+}
+
+// NodeLabels returns the well-known labels which kubelet and the Azure cloud provider set on a node running on a
+// virtual machine of the given SKU. The zone label is only returned when zone is not empty.
+func NodeLabels(sku resourceskus.SKU, osType, location, zone string) map[string]string {
+	arch := "amd64"
+	if value, ok := sku.GetCapability(resourceskus.CPUArchitectureType); ok && strings.EqualFold(value, resourceskus.ArchitectureArm64) {
+		arch = "arm64"
+	}
+
+	labels := map[string]string{
+		corev1.LabelInstanceTypeStable: to.String(sku.Name),
+		corev1.LabelOSStable:           strings.ToLower(osType),
+		corev1.LabelArchStable:         arch,
+		corev1.LabelTopologyRegion:     location,
+	}
+	if zone != "" {
+		labels[corev1.LabelTopologyZone] = fmt.Sprintf("%s-%s", location, zone)
+	}
+
+	return labels
+}
+
+// AddBootstrapNodeLabels adds the node labels of the bootstrap provider to the well-known labels of a node. kubelet does
+// not let node labels override the well-known labels.
+func AddBootstrapNodeLabels(labels, bootstrapLabels map[string]string) {
+	for key, value := range bootstrapLabels {
+		if _, ok := labels[key]; !ok {
+			labels[key] = value
+		}
+	}
+}
+
+// BootstrapNodeRegistration returns the node labels and taints which the KubeadmConfig, or KubeadmConfigTemplate,
+// referenced by configRef registers its nodes with. Other bootstrap providers are not supported, so they return no
+// labels and taints, like a missing config.
+func BootstrapNodeRegistration(ctx context.Context, c client.Client, namespace string, configRef *corev1.ObjectReference) (map[string]string, []corev1.Taint, error) {
+	if configRef == nil {
+		return nil, nil, nil
+	}
+
+	var nodeRegistration []string
+	switch configRef.Kind {
+	case "KubeadmConfig":
+		nodeRegistration = []string{"spec", "joinConfiguration", "nodeRegistration"}
+	case "KubeadmConfigTemplate":
+		nodeRegistration = []string{"spec", "template", "spec", "joinConfiguration", "nodeRegistration"}
+	default:
+		return nil, nil, nil
+	}
+
+	config := &unstructured.Unstructured{}
+	config.SetAPIVersion(configRef.APIVersion)
+	config.SetKind(configRef.Kind)
+	key := client.ObjectKey{Namespace: namespace, Name: configRef.Name}
+	if err := c.Get(ctx, key, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrapf(err, "failed to get %s %s", configRef.Kind, key)
+	}
+
+	nodeLabels, _, err := unstructured.NestedString(config.Object, append(nodeRegistration, "kubeletExtraArgs", "node-labels")...)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read the node labels of %s %s", configRef.Kind, key)
+	}
+
+	labels := map[string]string{}
+	for _, label := range strings.Split(nodeLabels, ",") {
+		if parts := strings.SplitN(strings.TrimSpace(label), "=", 2); len(parts) == 2 && parts[0] != "" {
+			labels[parts[0]] = parts[1]
+		}
+	}
+
+	rawTaints, _, err := unstructured.NestedSlice(config.Object, append(nodeRegistration, "taints")...)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to read the taints of %s %s", configRef.Kind, key)
+	}
+
+	var taints []corev1.Taint
+	for _, rawTaint := range rawTaints {
+		rawTaint, ok := rawTaint.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var taint corev1.Taint
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawTaint, &taint); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to read the taints of %s %s", configRef.Kind, key)
+		}
+		taints = append(taints, taint)
+	}
+
+	return labels, taints, nil
+}
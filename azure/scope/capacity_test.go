@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+)
+
+func TestNodeCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		sku     resourceskus.SKU
+		osDisk  infrav1.OSDisk
+		want    corev1.ResourceList
+		wantErr bool
+	}{
+		{
+			name:   "cpu, memory, pods and ephemeral storage",
+			sku:    newSKU("Standard_D2s_v3", map[string]string{resourceskus.VCPUs: "2", resourceskus.MemoryGB: "8"}),
+			osDisk: infrav1.OSDisk{DiskSizeGB: to.Int32Ptr(128)},
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("2"),
+				corev1.ResourceMemory:           resource.MustParse("8Gi"),
+				corev1.ResourcePods:             resource.MustParse("110"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("128Gi"),
+			},
+		},
+		{
+			name: "fractional memory and GPUs",
+			sku:  newSKU("Standard_NC6", map[string]string{resourceskus.VCPUs: "6", resourceskus.MemoryGB: "0.75", resourceskus.GPUs: "1"}),
+			want: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("6"),
+				corev1.ResourceMemory: resource.MustParse("768Mi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+				gpuResourceName:       resource.MustParse("1"),
+			},
+		},
+		{
+			name: "no GPUs",
+			sku:  newSKU("Standard_D2s_v3", map[string]string{resourceskus.GPUs: "0"}),
+			want: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("110"),
+			},
+		},
+		{
+			name:    "invalid vCPUs",
+			sku:     newSKU("Standard_D2s_v3", map[string]string{resourceskus.VCPUs: "two"}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := NodeCapacity(tt.sku, tt.osDisk)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(HaveLen(len(tt.want)))
+			for name, quantity := range tt.want {
+				actual, ok := got[name]
+				g.Expect(ok).To(BeTrue(), "missing %s", name)
+				g.Expect(actual.Cmp(quantity)).To(BeZero(), "unexpected %s: %s", name, actual.String())
+			}
+		})
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		sku    resourceskus.SKU
+		osType string
+		zone   string
+		want   map[string]string
+	}{
+		{
+			name:   "linux amd64 without zone",
+			sku:    newSKU("Standard_D2s_v3", map[string]string{resourceskus.CPUArchitectureType: "x64"}),
+			osType: "Linux",
+			want: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+				corev1.LabelOSStable:           "linux",
+				corev1.LabelArchStable:         "amd64",
+				corev1.LabelTopologyRegion:     "eastus",
+			},
+		},
+		{
+			name:   "windows arm64 in a zone",
+			sku:    newSKU("Standard_D2ps_v5", map[string]string{resourceskus.CPUArchitectureType: "Arm64"}),
+			osType: "Windows",
+			zone:   "2",
+			want: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2ps_v5",
+				corev1.LabelOSStable:           "windows",
+				corev1.LabelArchStable:         "arm64",
+				corev1.LabelTopologyRegion:     "eastus",
+				corev1.LabelTopologyZone:       "eastus-2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(NodeLabels(tt.sku, tt.osType, "eastus", tt.zone)).To(Equal(tt.want))
+		})
+	}
+}
+
+func newSKU(name string, capabilities map[string]string) resourceskus.SKU {
+	skuCapabilities := []compute.ResourceSkuCapabilities{}
+	for capability, value := range capabilities {
+		skuCapabilities = append(skuCapabilities, compute.ResourceSkuCapabilities{
+			Name:  to.StringPtr(capability),
+			Value: to.StringPtr(value),
+		})
+	}
+
+	return resourceskus.SKU{
+		Name:         to.StringPtr(name),
+		Capabilities: &skuCapabilities,
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	azureCertificateKey         = "certificate"
+	azureCertificatePasswordKey = "password"
+)
+
+// servicePrincipalCertificate is the parsed certificate of a service principal and its private key.
+type servicePrincipalCertificate struct {
+	certificate     *x509.Certificate
+	privateKey      *rsa.PrivateKey
+	resourceVersion string
+}
+
+// certificateCache keeps the parsed certificates of the identity secrets so they are only parsed again when the secret
+// changes, e.g. when the certificate is rotated.
+type certificateCache struct {
+	lock         sync.Mutex
+	certificates map[types.NamespacedName]*servicePrincipalCertificate
+}
+
+var identityCertificates = &certificateCache{
+	certificates: map[types.NamespacedName]*servicePrincipalCertificate{},
+}
+
+// get returns the certificate and private key stored in the secret, parsing them if the secret changed since they were
+// last parsed.
+func (c *certificateCache) get(secret *corev1.Secret) (*x509.Certificate, *rsa.PrivateKey, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name}
+	if cached, ok := c.certificates[key]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.certificate, cached.privateKey, nil
+	}
+
+	data, ok := secret.Data[azureCertificateKey]
+	if !ok {
+		return nil, nil, errors.Errorf("secret %s does not have a %s key", key, azureCertificateKey)
+	}
+
+	certificate, privateKey, err := parseCertificate(data, string(secret.Data[azureCertificatePasswordKey]))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to parse certificate of secret %s", key)
+	}
+
+	c.certificates[key] = &servicePrincipalCertificate{
+		certificate:     certificate,
+		privateKey:      privateKey,
+		resourceVersion: secret.ResourceVersion,
+	}
+	return certificate, privateKey, nil
+}
+
+// parseCertificate parses a PEM encoded certificate and RSA private key, or a PFX certificate protected by the password.
+func parseCertificate(data []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	block, rest := pem.Decode(data)
+	if block == nil {
+		return adal.DecodePfxCertificateData(data, password)
+	}
+
+	var (
+		certificates []*x509.Certificate
+		privateKey   *rsa.PrivateKey
+	)
+	for ; block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to parse certificate")
+			}
+			certificates = append(certificates, certificate)
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to parse private key")
+			}
+			privateKey = key
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to parse private key")
+			}
+			rsaKey, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, nil, errors.New("private key is not an RSA key")
+			}
+			privateKey = rsaKey
+		}
+	}
+
+	if privateKey == nil {
+		return nil, nil, errors.New("no private key found")
+	}
+
+	// the PEM data may contain the chain of the certificate, so pick the one matching the private key
+	publicKey := x509.MarshalPKCS1PublicKey(&privateKey.PublicKey)
+	for _, certificate := range certificates {
+		if rsaKey, ok := certificate.PublicKey.(*rsa.PublicKey); ok && bytes.Equal(x509.MarshalPKCS1PublicKey(rsaKey), publicKey) {
+			return certificate, privateKey, nil
+		}
+	}
+
+	return nil, nil, errors.New("no certificate matching the private key found")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseCertificate(t *testing.T) {
+	g := NewWithT(t)
+
+	caPEM, _ := newTestCertificate(t, "ca")
+	certPEM, key := newTestCertificate(t, "service-principal")
+	pkcs1Key := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(key)
+	g.Expect(err).NotTo(HaveOccurred())
+	pkcs8Key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes})
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expectedErr string
+	}{
+		{
+			name: "PEM certificate with PKCS1 private key",
+			data: append(append([]byte{}, certPEM...), pkcs1Key...),
+		},
+		{
+			name: "PEM certificate chain with PKCS8 private key",
+			data: append(append(append([]byte{}, pkcs8Key...), caPEM...), certPEM...),
+		},
+		{
+			name:        "PEM certificate without private key",
+			data:        certPEM,
+			expectedErr: "no private key found",
+		},
+		{
+			name:        "PEM private key without matching certificate",
+			data:        append(append([]byte{}, caPEM...), pkcs1Key...),
+			expectedErr: "no certificate matching the private key found",
+		},
+		{
+			name:        "invalid PFX certificate",
+			data:        []byte("not a certificate"),
+			expectedErr: "pkcs12",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			certificate, privateKey, err := parseCertificate(tc.data, "")
+			if tc.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(certificate.Subject.CommonName).To(Equal("service-principal"))
+			g.Expect(privateKey.Equal(key)).To(BeTrue())
+		})
+	}
+}
+
+func TestCertificateCache(t *testing.T) {
+	g := NewWithT(t)
+
+	certPEM, key := newTestCertificate(t, "service-principal")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "sp-certificate",
+			Namespace:       "default",
+			ResourceVersion: "1",
+		},
+		Data: map[string][]byte{
+			azureCertificateKey: append(append([]byte{}, certPEM...), keyPEM...),
+		},
+	}
+
+	cache := &certificateCache{certificates: map[types.NamespacedName]*servicePrincipalCertificate{}}
+	certificate, _, err := cache.get(secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(certificate.Subject.CommonName).To(Equal("service-principal"))
+
+	// the certificate is not parsed again while the secret is unchanged
+	cached := secret.DeepCopy()
+	cached.Data[azureCertificateKey] = []byte("not a certificate")
+	certificate, _, err = cache.get(cached)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(certificate.Subject.CommonName).To(Equal("service-principal"))
+
+	// a rotated certificate is parsed again
+	rotatedPEM, rotatedKey := newTestCertificate(t, "rotated-service-principal")
+	rotatedKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rotatedKey)})
+	rotated := secret.DeepCopy()
+	rotated.ResourceVersion = "2"
+	rotated.Data[azureCertificateKey] = append(append([]byte{}, rotatedPEM...), rotatedKeyPEM...)
+	certificate, privateKey, err := cache.get(rotated)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(certificate.Subject.CommonName).To(Equal("rotated-service-principal"))
+	g.Expect(privateKey.Equal(rotatedKey)).To(BeTrue())
+
+	missing := secret.DeepCopy()
+	missing.ResourceVersion = "3"
+	delete(missing.Data, azureCertificateKey)
+	_, _, err = cache.get(missing)
+	g.Expect(err).To(HaveOccurred())
+}
+
+// newTestCertificate returns a PEM encoded self-signed certificate with the common name and its private key.
+func newTestCertificate(t *testing.T, commonName string) ([]byte, *rsa.PrivateKey) {
+	t.Helper()
+	g := NewWithT(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
 // AzureClients contains all the Azure clients used by the scopes.
@@ -36,6 +38,8 @@ type AzureClients struct {
 	Authorizer                 autorest.Authorizer
 	ResourceManagerEndpoint    string
 	ResourceManagerVMDNSSuffix string
+	// credentialsType is the type of the AzureClusterIdentity the credentials come from, if any.
+	credentialsType infrav1.IdentityType
 }
 
 // CloudEnvironment returns the Azure environment the controller runs in.
@@ -58,6 +62,12 @@ func (c *AzureClients) ClientSecret() string {
 	return c.Values[auth.ClientSecret]
 }
 
+// CredentialsType returns the type of the AzureClusterIdentity the credentials come from, or an empty type when they
+// come from the controller environment.
+func (c *AzureClients) CredentialsType() infrav1.IdentityType {
+	return c.credentialsType
+}
+
 // SubscriptionID returns the Azure subscription id of the cluster,
 // either specified or from the environment.
 func (c *AzureClients) SubscriptionID() string {
@@ -94,11 +104,61 @@ func (c *AzureClients) setCredentials(subscriptionID, environmentName string) er
 	c.Values[auth.TenantID] = strings.TrimSuffix(c.Values[auth.TenantID], "\n")
 
 	if c.Authorizer == nil {
-		c.Authorizer, err = c.GetAuthorizer()
+		c.Authorizer, err = c.getEnvironmentAuthorizer()
 	}
 	return err
 }
 
+// getEnvironmentAuthorizer returns the authorizer of the credentials from the environment of the controller, which is
+// shared by all the scopes not referencing an identity.
+func (c *AzureClients) getEnvironmentAuthorizer() (autorest.Authorizer, error) {
+	cache, err := getCredentialsCache()
+	if err != nil {
+		return nil, err
+	}
+
+	key := credentialsCacheKey{
+		tenantID:                c.TenantID(),
+		auxiliaryTenantIDs:      c.Values[auth.AuxiliaryTenantIDs],
+		clientID:                c.ClientID(),
+		activeDirectoryEndpoint: c.Environment.ActiveDirectoryEndpoint,
+		resourceManagerEndpoint: c.ResourceManagerEndpoint,
+	}
+	// the credentials from the environment cannot change while the controller runs. They also get tokens for the
+	// tenants in AZURE_AUXILIARY_TENANT_IDS, if any.
+	return cache.getOrCreate(key, "", c.GetAuthorizer)
+}
+
+// getEnvironmentKeyVaultAuthorizer returns the authorizer of the credentials from the environment of the controller for
+// the data plane of Key Vault.
+func (c *AzureClients) getEnvironmentKeyVaultAuthorizer() (autorest.Authorizer, error) {
+	cache, err := getCredentialsCache()
+	if err != nil {
+		return nil, err
+	}
+
+	resource := c.Environment.ResourceIdentifiers.KeyVault
+	key := credentialsCacheKey{
+		tenantID:                c.TenantID(),
+		clientID:                c.ClientID(),
+		activeDirectoryEndpoint: c.Environment.ActiveDirectoryEndpoint,
+		resourceManagerEndpoint: resource,
+	}
+	return cache.getOrCreate(key, "", func() (autorest.Authorizer, error) {
+		settings := auth.EnvironmentSettings{
+			Environment: c.Environment,
+			Values:      map[string]string{},
+		}
+		for k, v := range c.Values {
+			settings.Values[k] = v
+		}
+		// Key Vault does not accept the tokens of the auxiliary tenants.
+		delete(settings.Values, auth.AuxiliaryTenantIDs)
+		settings.Values[auth.Resource] = resource
+		return settings.GetAuthorizer()
+	})
+}
+
 func (c *AzureClients) setCredentialsWithProvider(ctx context.Context, subscriptionID, environmentName string, credentialsProvider CredentialsProvider) error {
 	if credentialsProvider == nil {
 		return fmt.Errorf("credentials provider cannot have an empty value")
@@ -128,6 +188,7 @@ func (c *AzureClients) setCredentialsWithProvider(ctx context.Context, subscript
 		return err
 	}
 	c.Values[auth.ClientSecret] = strings.TrimSuffix(clientSecret, "\n")
+	c.credentialsType = credentialsProvider.GetType()
 
 	c.Authorizer, err = credentialsProvider.GetAuthorizer(ctx, c.ResourceManagerEndpoint, c.Environment.ActiveDirectoryEndpoint)
 	return err
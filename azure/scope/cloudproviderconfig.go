@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+const (
+	// cloudProviderConfigPath is the path of the cloud provider config on Linux virtual machines.
+	cloudProviderConfigPath = "/etc/kubernetes/azure.json"
+
+	// cloudProviderConfigParameter is the protected parameter of the run command holding the base64 encoded cloud
+	// provider config, which the script reads from its environment.
+	cloudProviderConfigParameter = "CLOUD_PROVIDER_CONFIG"
+
+	// restartCloudProviderScript restarts the components which only read the cloud provider config when they start:
+	// the kubelet, and the controller managers of the control plane, whose containers are started again by the kubelet.
+	restartCloudProviderScript = `systemctl restart kubelet
+if command -v crictl > /dev/null; then
+  for name in kube-controller-manager cloud-controller-manager; do
+    crictl ps --quiet --name "^${name}\$" | xargs -r crictl stop
+  done
+fi
+`
+)
+
+// cloudProviderConfigParams are the parameters of the cloud provider config of a virtual machine.
+type cloudProviderConfigParams struct {
+	// ownerName is the name of the object owning the azure json secret.
+	ownerName string
+	// dataKey is the key of the azure json secret holding the config of the virtual machine.
+	dataKey string
+	// lastAppliedHash is the hash of the config last written on the virtual machine, if any.
+	lastAppliedHash string
+	// fetchScript returns the script fetching the config from the Key Vault, if the virtual machine fetches it from
+	// there.
+	fetchScript func() (string, error)
+}
+
+// getCloudProviderConfig returns the cloud provider config of a Linux virtual machine from its azure json secret, or nil
+// when it is not generated by CAPZ. The config is either fetched from the Key Vault again by the script, or passed to
+// it as a protected parameter, so it is never part of the script itself.
+func getCloudProviderConfig(ctx context.Context, c client.Client, namespace, clusterName string, params cloudProviderConfigParams) (*azure.CloudProviderConfig, error) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: params.ownerName + "-azure-json"}
+	if err := c.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get azure json secret %s", key.Name)
+	}
+	hash := secret.Annotations[infrav1.CloudProviderConfigHashAnnotation]
+	if secret.Labels[clusterName] != string(infrav1.ResourceLifecycleOwned) || hash == "" {
+		return nil, nil
+	}
+
+	config := &azure.CloudProviderConfig{
+		Hash:            hash,
+		LastAppliedHash: params.lastAppliedHash,
+	}
+	if params.fetchScript != nil {
+		script, err := params.fetchScript()
+		if err != nil {
+			return nil, err
+		}
+		config.Script = script + restartCloudProviderScript
+		return config, nil
+	}
+
+	data, ok := secret.Data[params.dataKey]
+	if !ok {
+		return nil, errors.Errorf("azure json secret %s is missing key %s", key.Name, params.dataKey)
+	}
+	config.Script = fmt.Sprintf("#!/bin/sh\nset -e\numask 077\nprintf '%%s' \"$%s\" | base64 -d > %s\n", cloudProviderConfigParameter, cloudProviderConfigPath) + restartCloudProviderScript
+	config.ProtectedParameters = map[string]string{
+		cloudProviderConfigParameter: base64.StdEncoding.EncodeToString(data),
+	}
+	return config, nil
+}
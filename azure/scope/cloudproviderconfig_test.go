@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+func TestGetCloudProviderConfig(t *testing.T) {
+	azureJSONSecret := func(labels, annotations map[string]string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "owner-azure-json",
+				Namespace:   "default",
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Data: map[string][]byte{
+				"worker-node-azure.json": []byte(`{"aadClientSecret": "secret"}`),
+			},
+		}
+	}
+	owned := map[string]string{"my-cluster": string(infrav1.ResourceLifecycleOwned)}
+	hashed := map[string]string{infrav1.CloudProviderConfigHashAnnotation: "hash"}
+
+	tests := []struct {
+		name          string
+		secret        *corev1.Secret
+		params        cloudProviderConfigParams
+		expectedError string
+		verify        func(g *WithT, config *azure.CloudProviderConfig)
+	}{
+		{
+			name:   "azure json secret does not exist",
+			params: cloudProviderConfigParams{ownerName: "owner", dataKey: "worker-node-azure.json"},
+			verify: func(g *WithT, config *azure.CloudProviderConfig) {
+				g.Expect(config).To(BeNil())
+			},
+		},
+		{
+			name:   "azure json secret is not generated by capz",
+			secret: azureJSONSecret(nil, hashed),
+			params: cloudProviderConfigParams{ownerName: "owner", dataKey: "worker-node-azure.json"},
+			verify: func(g *WithT, config *azure.CloudProviderConfig) {
+				g.Expect(config).To(BeNil())
+			},
+		},
+		{
+			name:   "config is passed as a protected parameter",
+			secret: azureJSONSecret(owned, hashed),
+			params: cloudProviderConfigParams{ownerName: "owner", dataKey: "worker-node-azure.json", lastAppliedHash: "old"},
+			verify: func(g *WithT, config *azure.CloudProviderConfig) {
+				g.Expect(config.Hash).To(Equal("hash"))
+				g.Expect(config.LastAppliedHash).To(Equal("old"))
+				encoded := base64.StdEncoding.EncodeToString([]byte(`{"aadClientSecret": "secret"}`))
+				g.Expect(config.ProtectedParameters).To(Equal(map[string]string{cloudProviderConfigParameter: encoded}))
+				g.Expect(config.Script).NotTo(ContainSubstring(encoded))
+				g.Expect(config.Script).To(ContainSubstring(`"$CLOUD_PROVIDER_CONFIG" | base64 -d > /etc/kubernetes/azure.json`))
+				g.Expect(config.Script).To(HaveSuffix(restartCloudProviderScript))
+			},
+		},
+		{
+			name:   "config is fetched from the key vault",
+			secret: azureJSONSecret(owned, hashed),
+			params: cloudProviderConfigParams{ownerName: "owner", dataKey: "worker-node-azure.json", fetchScript: func() (string, error) {
+				return "fetch\n", nil
+			}},
+			verify: func(g *WithT, config *azure.CloudProviderConfig) {
+				g.Expect(config.ProtectedParameters).To(BeEmpty())
+				g.Expect(config.Script).To(Equal("fetch\n" + restartCloudProviderScript))
+			},
+		},
+		{
+			name:          "azure json secret is missing the key of the machine",
+			secret:        azureJSONSecret(owned, hashed),
+			params:        cloudProviderConfigParams{ownerName: "owner", dataKey: "control-plane-azure.json"},
+			expectedError: "azure json secret owner-azure-json is missing key control-plane-azure.json",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			cb := fake.NewClientBuilder().WithScheme(scheme)
+			if tc.secret != nil {
+				cb = cb.WithObjects(tc.secret)
+			}
+
+			config, err := getCloudProviderConfig(context.TODO(), cb.Build(), "default", "my-cluster", tc.params)
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			tc.verify(g, config)
+		})
+	}
+}
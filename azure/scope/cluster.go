@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bastionhosts"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/loadbalancers"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/natgateways"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/routetables"
@@ -67,20 +68,64 @@ func NewClusterScope(ctx context.Context, params ClusterScopeParams) (*ClusterSc
 		return nil, errors.New("failed to generate new scope from nil AzureCluster")
 	}
 
+	var secondaryCredentialsProvider *AzureClusterCredentialsProvider
+	if params.AzureCluster.Spec.SecondaryIdentityRef != nil {
+		var err error
+		secondaryCredentialsProvider, err = NewAzureClusterSecondaryCredentialsProvider(ctx, params.Client, params.AzureCluster)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to init secondary credentials provider")
+		}
+	}
+
+	var keyVaultAuthorizer autorest.Authorizer
 	if params.AzureCluster.Spec.IdentityRef == nil {
 		err := params.AzureClients.setCredentials(params.AzureCluster.Spec.SubscriptionID, params.AzureCluster.Spec.AzureEnvironment)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to configure azure settings and credentials from environment")
 		}
+		if params.AzureCluster.Spec.KeyVault != nil {
+			keyVaultAuthorizer, err = params.AzureClients.getEnvironmentKeyVaultAuthorizer()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to configure Key Vault credentials from environment")
+			}
+		}
 	} else {
 		credentialsProvider, err := NewAzureClusterCredentialsProvider(ctx, params.Client, params.AzureCluster)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to init credentials provider")
 		}
+		// the requests referencing resources of the tenant of the secondary identity, e.g. the peering with a
+		// virtual network in that tenant, need a token of this tenant too.
+		if secondaryCredentialsProvider != nil && secondaryCredentialsProvider.GetTenantID() != credentialsProvider.GetTenantID() {
+			credentialsProvider.AuxiliaryTenantIDs = []string{secondaryCredentialsProvider.GetTenantID()}
+		}
 		err = params.AzureClients.setCredentialsWithProvider(ctx, params.AzureCluster.Spec.SubscriptionID, params.AzureCluster.Spec.AzureEnvironment, credentialsProvider)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to configure azure settings and credentials for Identity")
 		}
+		if params.AzureCluster.Spec.KeyVault != nil {
+			// the Key Vault is in the tenant of the cluster, so its token does not need the tenant of the secondary
+			// identity.
+			keyVaultCredentialsProvider := *credentialsProvider
+			keyVaultCredentialsProvider.AuxiliaryTenantIDs = nil
+			environment := params.AzureClients.Environment
+			keyVaultAuthorizer, err = keyVaultCredentialsProvider.GetAuthorizer(ctx, environment.ResourceIdentifiers.KeyVault, environment.ActiveDirectoryEndpoint)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to configure Key Vault credentials for Identity")
+			}
+		}
+	}
+
+	secondaryAuthorizer := params.AzureClients.Authorizer
+	if secondaryCredentialsProvider != nil {
+		if tenantID := params.AzureClients.TenantID(); tenantID != "" && tenantID != secondaryCredentialsProvider.GetTenantID() {
+			secondaryCredentialsProvider.AuxiliaryTenantIDs = []string{tenantID}
+		}
+		var err error
+		secondaryAuthorizer, err = secondaryCredentialsProvider.GetAuthorizer(ctx, params.AzureClients.ResourceManagerEndpoint, params.AzureClients.Environment.ActiveDirectoryEndpoint)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to configure credentials for secondary Identity")
+		}
 	}
 
 	helper, err := patch.NewHelper(params.AzureCluster, params.Client)
@@ -89,18 +134,22 @@ func NewClusterScope(ctx context.Context, params ClusterScopeParams) (*ClusterSc
 	}
 
 	return &ClusterScope{
-		Client:       params.Client,
-		AzureClients: params.AzureClients,
-		Cluster:      params.Cluster,
-		AzureCluster: params.AzureCluster,
-		patchHelper:  helper,
+		Client:              params.Client,
+		AzureClients:        params.AzureClients,
+		Cluster:             params.Cluster,
+		AzureCluster:        params.AzureCluster,
+		patchHelper:         helper,
+		secondaryAuthorizer: secondaryAuthorizer,
+		keyVaultAuthorizer:  keyVaultAuthorizer,
 	}, nil
 }
 
 // ClusterScope defines the basic context for an actuator to operate upon.
 type ClusterScope struct {
-	Client      client.Client
-	patchHelper *patch.Helper
+	Client              client.Client
+	patchHelper         *patch.Helper
+	secondaryAuthorizer autorest.Authorizer
+	keyVaultAuthorizer  autorest.Authorizer
 
 	AzureClients
 	Cluster      *clusterv1.Cluster
@@ -117,6 +166,19 @@ func (s *ClusterScope) Authorizer() autorest.Authorizer {
 	return s.AzureClients.Authorizer
 }
 
+// SecondaryAuthorizer returns the Azure client Authorizer for the resources of the cluster in other subscriptions.
+func (s *ClusterScope) SecondaryAuthorizer() autorest.Authorizer {
+	if s.secondaryAuthorizer == nil {
+		return s.Authorizer()
+	}
+	return s.secondaryAuthorizer
+}
+
+// KeyVaultAuthorizer returns the Azure client Authorizer for the data plane of the Key Vault of the cluster.
+func (s *ClusterScope) KeyVaultAuthorizer() autorest.Authorizer {
+	return s.keyVaultAuthorizer
+}
+
 // PublicIPSpecs returns the public IP specs.
 func (s *ClusterScope) PublicIPSpecs() []azure.PublicIPSpec {
 	var publicIPSpecs []azure.PublicIPSpec
@@ -179,6 +241,7 @@ func (s *ClusterScope) LBSpecs() []azure.ResourceSpecGetter {
 			Location:             s.Location(),
 			VNetName:             s.Vnet().Name,
 			VNetResourceGroup:    s.Vnet().ResourceGroup,
+			VNetSubscriptionID:   s.Vnet().SubscriptionID,
 			SubnetName:           s.ControlPlaneSubnet().Name,
 			FrontendIPConfigs:    s.APIServerLB().FrontendIPs,
 			APIServerPort:        s.APIServerPort(),
@@ -201,6 +264,7 @@ func (s *ClusterScope) LBSpecs() []azure.ResourceSpecGetter {
 			Location:             s.Location(),
 			VNetName:             s.Vnet().Name,
 			VNetResourceGroup:    s.Vnet().ResourceGroup,
+			VNetSubscriptionID:   s.Vnet().SubscriptionID,
 			FrontendIPConfigs:    s.NodeOutboundLB().FrontendIPs,
 			Type:                 s.NodeOutboundLB().Type,
 			SKU:                  s.NodeOutboundLB().SKU,
@@ -221,6 +285,7 @@ func (s *ClusterScope) LBSpecs() []azure.ResourceSpecGetter {
 			Location:             s.Location(),
 			VNetName:             s.Vnet().Name,
 			VNetResourceGroup:    s.Vnet().ResourceGroup,
+			VNetSubscriptionID:   s.Vnet().SubscriptionID,
 			FrontendIPConfigs:    s.ControlPlaneOutboundLB().FrontendIPs,
 			Type:                 s.ControlPlaneOutboundLB().Type,
 			SKU:                  s.ControlPlaneOutboundLB().SKU,
@@ -338,34 +403,63 @@ func (s *ClusterScope) GroupSpec() azure.ResourceSpecGetter {
 // VnetPeeringSpecs returns the virtual network peering specs.
 func (s *ClusterScope) VnetPeeringSpecs() []azure.ResourceSpecGetter {
 	peeringSpecs := make([]azure.ResourceSpecGetter, 2*len(s.Vnet().Peerings))
+	vnetSubscriptionID := s.vnetSubscriptionID()
 	for i, peering := range s.Vnet().Peerings {
+		remoteSubscriptionID := peering.SubscriptionID
+		if remoteSubscriptionID == "" {
+			remoteSubscriptionID = s.SubscriptionID()
+		}
 		forwardPeering := &vnetpeerings.VnetPeeringSpec{
-			PeeringName:         azure.GenerateVnetPeeringName(s.Vnet().Name, peering.RemoteVnetName),
-			SourceVnetName:      s.Vnet().Name,
-			SourceResourceGroup: s.Vnet().ResourceGroup,
-			RemoteVnetName:      peering.RemoteVnetName,
-			RemoteResourceGroup: peering.ResourceGroup,
-			SubscriptionID:      s.SubscriptionID(),
+			PeeringName:           azure.GenerateVnetPeeringName(s.Vnet().Name, peering.RemoteVnetName),
+			SourceSubscriptionID:  vnetSubscriptionID,
+			SourceVnetName:        s.Vnet().Name,
+			SourceResourceGroup:   s.Vnet().ResourceGroup,
+			RemoteSubscriptionID:  remoteSubscriptionID,
+			RemoteVnetName:        peering.RemoteVnetName,
+			RemoteResourceGroup:   peering.ResourceGroup,
+			AllowForwardedTraffic: peering.ForwardPeeringProperties.AllowForwardedTraffic,
+			AllowGatewayTransit:   peering.ForwardPeeringProperties.AllowGatewayTransit,
+			UseRemoteGateways:     peering.ForwardPeeringProperties.UseRemoteGateways,
 		}
 		reversePeering := &vnetpeerings.VnetPeeringSpec{
-			PeeringName:         azure.GenerateVnetPeeringName(peering.RemoteVnetName, s.Vnet().Name),
-			SourceVnetName:      peering.RemoteVnetName,
-			SourceResourceGroup: peering.ResourceGroup,
-			RemoteVnetName:      s.Vnet().Name,
-			RemoteResourceGroup: s.Vnet().ResourceGroup,
-			SubscriptionID:      s.SubscriptionID(),
+			PeeringName:           azure.GenerateVnetPeeringName(peering.RemoteVnetName, s.Vnet().Name),
+			SourceSubscriptionID:  remoteSubscriptionID,
+			SourceVnetName:        peering.RemoteVnetName,
+			SourceResourceGroup:   peering.ResourceGroup,
+			RemoteSubscriptionID:  vnetSubscriptionID,
+			RemoteVnetName:        s.Vnet().Name,
+			RemoteResourceGroup:   s.Vnet().ResourceGroup,
+			AllowForwardedTraffic: peering.ReversePeeringProperties.AllowForwardedTraffic,
+			AllowGatewayTransit:   peering.ReversePeeringProperties.AllowGatewayTransit,
+			UseRemoteGateways:     peering.ReversePeeringProperties.UseRemoteGateways,
+		}
+		// A peering can only use the gateways of the remote virtual network once the peering in the other direction
+		// allows gateway transit, so the latter is reconciled first.
+		if to.Bool(forwardPeering.UseRemoteGateways) {
+			peeringSpecs[i*2] = reversePeering
+			peeringSpecs[i*2+1] = forwardPeering
+		} else {
+			peeringSpecs[i*2] = forwardPeering
+			peeringSpecs[i*2+1] = reversePeering
 		}
-		peeringSpecs[i*2] = forwardPeering
-		peeringSpecs[i*2+1] = reversePeering
 	}
 
 	return peeringSpecs
 }
 
+// vnetSubscriptionID returns the subscription of the virtual network, which defaults to the subscription of the cluster.
+func (s *ClusterScope) vnetSubscriptionID() string {
+	if s.Vnet().SubscriptionID != "" {
+		return s.Vnet().SubscriptionID
+	}
+	return s.SubscriptionID()
+}
+
 // VNetSpec returns the virtual network spec.
 func (s *ClusterScope) VNetSpec() azure.ResourceSpecGetter {
 	return &virtualnetworks.VNetSpec{
 		ResourceGroup:  s.Vnet().ResourceGroup,
+		SubscriptionID: s.Vnet().SubscriptionID,
 		Name:           s.Vnet().Name,
 		CIDRs:          s.Vnet().CIDRBlocks,
 		Location:       s.Location(),
@@ -380,20 +474,24 @@ func (s *ClusterScope) PrivateDNSSpec() *azure.PrivateDNSSpec {
 	if s.IsAPIServerPrivate() {
 		links := make([]azure.PrivateDNSLinkSpec, 1+len(s.Vnet().Peerings))
 		links[0] = azure.PrivateDNSLinkSpec{
-			VNetName:          s.Vnet().Name,
-			VNetResourceGroup: s.Vnet().ResourceGroup,
-			LinkName:          azure.GenerateVNetLinkName(s.Vnet().Name),
+			VNetName:           s.Vnet().Name,
+			VNetResourceGroup:  s.Vnet().ResourceGroup,
+			VNetSubscriptionID: s.Vnet().SubscriptionID,
+			LinkName:           azure.GenerateVNetLinkName(s.Vnet().Name),
 		}
 		for i, peering := range s.Vnet().Peerings {
 			links[i+1] = azure.PrivateDNSLinkSpec{
-				VNetName:          peering.RemoteVnetName,
-				VNetResourceGroup: peering.ResourceGroup,
-				LinkName:          azure.GenerateVNetLinkName(peering.RemoteVnetName),
+				VNetName:           peering.RemoteVnetName,
+				VNetResourceGroup:  peering.ResourceGroup,
+				VNetSubscriptionID: peering.SubscriptionID,
+				LinkName:           azure.GenerateVNetLinkName(peering.RemoteVnetName),
 			}
 		}
 		specs = &azure.PrivateDNSSpec{
-			ZoneName: s.GetPrivateDNSZoneName(),
-			Links:    links,
+			ZoneName:       s.GetPrivateDNSZoneName(),
+			ResourceGroup:  s.AzureCluster.Spec.NetworkSpec.PrivateDNSZoneResourceGroup,
+			SubscriptionID: s.AzureCluster.Spec.NetworkSpec.PrivateDNSZoneSubscriptionID,
+			Links:          links,
 			Records: []infrav1.AddressRecord{
 				{
 					Hostname: azure.PrivateAPIServerHostname,
@@ -419,7 +517,7 @@ func (s *ClusterScope) AzureBastion() *infrav1.AzureBastion {
 // AzureBastionSpec returns the bastion spec.
 func (s *ClusterScope) AzureBastionSpec() azure.ResourceSpecGetter {
 	if s.IsAzureBastionEnabled() {
-		subnetID := azure.SubnetID(s.SubscriptionID(), s.ResourceGroup(), s.Vnet().Name, s.AzureBastion().Subnet.Name)
+		subnetID := azure.SubnetID(s.vnetSubscriptionID(), s.Vnet().ResourceGroup, s.Vnet().Name, s.AzureBastion().Subnet.Name)
 		publicIPID := azure.PublicIPID(s.SubscriptionID(), s.ResourceGroup(), s.AzureBastion().PublicIP.Name)
 
 		return &bastionhosts.AzureBastionSpec{
@@ -435,6 +533,43 @@ func (s *ClusterScope) AzureBastionSpec() azure.ResourceSpecGetter {
 	return nil
 }
 
+// KeyVault returns the cluster Key Vault.
+func (s *ClusterScope) KeyVault() *infrav1.KeyVaultSpec {
+	return s.AzureCluster.Spec.KeyVault
+}
+
+// KeyVaultID returns the resource ID of the cluster Key Vault, or "" if there is no Key Vault.
+func (s *ClusterScope) KeyVaultID() string {
+	if s.KeyVault() == nil {
+		return ""
+	}
+	return azure.KeyVaultID(s.SubscriptionID(), s.ResourceGroup(), s.KeyVault().Name)
+}
+
+// KeyVaultURL returns the URL of the data plane of the cluster Key Vault, or "" if there is no Key Vault.
+func (s *ClusterScope) KeyVaultURL() string {
+	if s.KeyVault() == nil {
+		return ""
+	}
+	return fmt.Sprintf("https://%s.%s/", s.KeyVault().Name, s.Environment.KeyVaultDNSSuffix)
+}
+
+// KeyVaultSpec returns the Key Vault spec.
+func (s *ClusterScope) KeyVaultSpec() azure.ResourceSpecGetter {
+	if s.KeyVault() == nil {
+		return nil
+	}
+
+	return &keyvaults.KeyVaultSpec{
+		Name:           s.KeyVault().Name,
+		ResourceGroup:  s.ResourceGroup(),
+		Location:       s.Location(),
+		TenantID:       s.TenantID(),
+		ClusterName:    s.ClusterName(),
+		AdditionalTags: s.AdditionalTags(),
+	}
+}
+
 // Vnet returns the cluster Vnet.
 func (s *ClusterScope) Vnet() *infrav1.VnetSpec {
 	return &s.AzureCluster.Spec.NetworkSpec.Vnet
@@ -662,6 +797,7 @@ func (s *ClusterScope) PatchObject(ctx context.Context) error {
 			infrav1.RouteTablesReadyCondition,
 			infrav1.NetworkInfrastructureReadyCondition,
 			infrav1.VnetPeeringReadyCondition,
+			infrav1.KeyVaultReadyCondition,
 			infrav1.DisksReadyCondition,
 			infrav1.NATGatewaysReadyCondition,
 			infrav1.LoadBalancersReadyCondition,
@@ -679,6 +815,7 @@ func (s *ClusterScope) PatchObject(ctx context.Context) error {
 			infrav1.RouteTablesReadyCondition,
 			infrav1.NetworkInfrastructureReadyCondition,
 			infrav1.VnetPeeringReadyCondition,
+			infrav1.KeyVaultReadyCondition,
 			infrav1.DisksReadyCondition,
 			infrav1.NATGatewaysReadyCondition,
 			infrav1.LoadBalancersReadyCondition,
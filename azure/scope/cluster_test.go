@@ -18,15 +18,21 @@ package scope
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/Azure/go-autorest/autorest/to"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/vnetpeerings"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -324,3 +330,320 @@ func TestOutboundLBName(t *testing.T) {
 		})
 	}
 }
+
+func TestVnetPeeringSpecs(t *testing.T) {
+	tests := []struct {
+		name               string
+		vnetSubscriptionID string
+		peerings           infrav1.VnetPeerings
+		want               []azure.ResourceSpecGetter
+	}{
+		{
+			name:     "no peerings",
+			peerings: infrav1.VnetPeerings{},
+			want:     []azure.ResourceSpecGetter{},
+		},
+		{
+			name: "peering in the subscription of the cluster",
+			peerings: infrav1.VnetPeerings{
+				{ResourceGroup: "hub-rg", RemoteVnetName: "hub-vnet"},
+			},
+			want: []azure.ResourceSpecGetter{
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "my-vnet-To-hub-vnet",
+					SourceSubscriptionID: "123",
+					SourceResourceGroup:  "my-rg",
+					SourceVnetName:       "my-vnet",
+					RemoteSubscriptionID: "123",
+					RemoteResourceGroup:  "hub-rg",
+					RemoteVnetName:       "hub-vnet",
+				},
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "hub-vnet-To-my-vnet",
+					SourceSubscriptionID: "123",
+					SourceResourceGroup:  "hub-rg",
+					SourceVnetName:       "hub-vnet",
+					RemoteSubscriptionID: "123",
+					RemoteResourceGroup:  "my-rg",
+					RemoteVnetName:       "my-vnet",
+				},
+			},
+		},
+		{
+			name: "peering in another subscription",
+			peerings: infrav1.VnetPeerings{
+				{ResourceGroup: "hub-rg", RemoteVnetName: "hub-vnet", SubscriptionID: "456"},
+			},
+			want: []azure.ResourceSpecGetter{
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "my-vnet-To-hub-vnet",
+					SourceSubscriptionID: "123",
+					SourceResourceGroup:  "my-rg",
+					SourceVnetName:       "my-vnet",
+					RemoteSubscriptionID: "456",
+					RemoteResourceGroup:  "hub-rg",
+					RemoteVnetName:       "hub-vnet",
+				},
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "hub-vnet-To-my-vnet",
+					SourceSubscriptionID: "456",
+					SourceResourceGroup:  "hub-rg",
+					SourceVnetName:       "hub-vnet",
+					RemoteSubscriptionID: "123",
+					RemoteResourceGroup:  "my-rg",
+					RemoteVnetName:       "my-vnet",
+				},
+			},
+		},
+		{
+			name:               "peering of a vnet in another subscription",
+			vnetSubscriptionID: "789",
+			peerings: infrav1.VnetPeerings{
+				{ResourceGroup: "hub-rg", RemoteVnetName: "hub-vnet", SubscriptionID: "456"},
+			},
+			want: []azure.ResourceSpecGetter{
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "my-vnet-To-hub-vnet",
+					SourceSubscriptionID: "789",
+					SourceResourceGroup:  "my-rg",
+					SourceVnetName:       "my-vnet",
+					RemoteSubscriptionID: "456",
+					RemoteResourceGroup:  "hub-rg",
+					RemoteVnetName:       "hub-vnet",
+				},
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "hub-vnet-To-my-vnet",
+					SourceSubscriptionID: "456",
+					SourceResourceGroup:  "hub-rg",
+					SourceVnetName:       "hub-vnet",
+					RemoteSubscriptionID: "789",
+					RemoteResourceGroup:  "my-rg",
+					RemoteVnetName:       "my-vnet",
+				},
+			},
+		},
+		{
+			name: "peering using the gateway of the remote vnet",
+			peerings: infrav1.VnetPeerings{
+				{
+					ResourceGroup:  "hub-rg",
+					RemoteVnetName: "hub-vnet",
+					SubscriptionID: "456",
+					ForwardPeeringProperties: infrav1.VnetPeeringProperties{
+						AllowForwardedTraffic: to.BoolPtr(true),
+						UseRemoteGateways:     to.BoolPtr(true),
+					},
+					ReversePeeringProperties: infrav1.VnetPeeringProperties{
+						AllowGatewayTransit: to.BoolPtr(true),
+					},
+				},
+			},
+			want: []azure.ResourceSpecGetter{
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:          "hub-vnet-To-my-vnet",
+					SourceSubscriptionID: "456",
+					SourceResourceGroup:  "hub-rg",
+					SourceVnetName:       "hub-vnet",
+					RemoteSubscriptionID: "123",
+					RemoteResourceGroup:  "my-rg",
+					RemoteVnetName:       "my-vnet",
+					AllowGatewayTransit:  to.BoolPtr(true),
+				},
+				&vnetpeerings.VnetPeeringSpec{
+					PeeringName:           "my-vnet-To-hub-vnet",
+					SourceSubscriptionID:  "123",
+					SourceResourceGroup:   "my-rg",
+					SourceVnetName:        "my-vnet",
+					RemoteSubscriptionID:  "456",
+					RemoteResourceGroup:   "hub-rg",
+					RemoteVnetName:        "hub-vnet",
+					AllowForwardedTraffic: to.BoolPtr(true),
+					UseRemoteGateways:     to.BoolPtr(true),
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterScope := &ClusterScope{
+				AzureClients: AzureClients{
+					EnvironmentSettings: auth.EnvironmentSettings{
+						Values: map[string]string{auth.SubscriptionID: "123"},
+					},
+				},
+				AzureCluster: &infrav1.AzureCluster{
+					Spec: infrav1.AzureClusterSpec{
+						NetworkSpec: infrav1.NetworkSpec{
+							Vnet: infrav1.VnetSpec{
+								Name:           "my-vnet",
+								ResourceGroup:  "my-rg",
+								SubscriptionID: tc.vnetSubscriptionID,
+								Peerings:       tc.peerings,
+							},
+						},
+					},
+				},
+			}
+			g.Expect(clusterScope.VnetPeeringSpecs()).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestPrivateDNSSpec(t *testing.T) {
+	g := NewWithT(t)
+	clusterScope := &ClusterScope{
+		Cluster: &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-cluster"},
+		},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				NetworkSpec: infrav1.NetworkSpec{
+					Vnet: infrav1.VnetSpec{
+						Name:           "my-vnet",
+						ResourceGroup:  "my-rg",
+						SubscriptionID: "789",
+						Peerings: infrav1.VnetPeerings{
+							{ResourceGroup: "hub-rg", RemoteVnetName: "hub-vnet", SubscriptionID: "456"},
+						},
+					},
+					APIServerLB: infrav1.LoadBalancerSpec{
+						Type: infrav1.Internal,
+						FrontendIPs: []infrav1.FrontendIP{
+							{
+								Name:             "my-frontend-ip",
+								PrivateIPAddress: "10.0.0.100",
+							},
+						},
+					},
+					PrivateDNSZoneName:           "example.private",
+					PrivateDNSZoneResourceGroup:  "dns-rg",
+					PrivateDNSZoneSubscriptionID: "456",
+				},
+			},
+		},
+	}
+
+	g.Expect(clusterScope.PrivateDNSSpec()).To(Equal(&azure.PrivateDNSSpec{
+		ZoneName:       "example.private",
+		ResourceGroup:  "dns-rg",
+		SubscriptionID: "456",
+		Links: []azure.PrivateDNSLinkSpec{
+			{
+				VNetName:           "my-vnet",
+				VNetResourceGroup:  "my-rg",
+				VNetSubscriptionID: "789",
+				LinkName:           "my-vnet-link",
+			},
+			{
+				VNetName:           "hub-vnet",
+				VNetResourceGroup:  "hub-rg",
+				VNetSubscriptionID: "456",
+				LinkName:           "hub-vnet-link",
+			},
+		},
+		Records: []infrav1.AddressRecord{
+			{
+				Hostname: azure.PrivateAPIServerHostname,
+				IP:       "10.0.0.100",
+			},
+		},
+	}))
+}
+
+func TestNewClusterScopeWithSecondaryIdentity(t *testing.T) {
+	tests := []struct {
+		name                    string
+		secondaryType           infrav1.IdentityType
+		secondaryTenantID       string
+		expectErr               bool
+		expectMultiTenantTokens bool
+	}{
+		{
+			name:              "secondary identity in the same tenant",
+			secondaryType:     infrav1.ManualServicePrincipal,
+			secondaryTenantID: "my-tenant-id",
+		},
+		{
+			name:                    "secondary identity in another tenant",
+			secondaryType:           infrav1.ManualServicePrincipal,
+			secondaryTenantID:       "hub-tenant-id",
+			expectMultiTenantTokens: true,
+		},
+		{
+			name:              "secondary workload identity in another tenant",
+			secondaryType:     infrav1.WorkloadIdentity,
+			secondaryTenantID: "hub-tenant-id",
+			expectErr:         true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme := runtime.NewScheme()
+			_ = clusterv1.AddToScheme(scheme)
+			_ = infrav1.AddToScheme(scheme)
+			_ = corev1.AddToScheme(scheme)
+
+			// the authorizers are cached by identity, so each case needs its own
+			suffix := strings.ReplaceAll(tc.name, " ", "-")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "identity-secret-" + suffix, Namespace: "default"},
+				Data:       map[string][]byte{azureSecretKey: []byte("my-client-secret")},
+			}
+			identity := &infrav1.AzureClusterIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "identity-" + suffix, Namespace: "default"},
+				Spec: infrav1.AzureClusterIdentitySpec{
+					Type:         infrav1.ManualServicePrincipal,
+					ClientID:     "my-client-id",
+					ClientSecret: corev1.SecretReference{Name: secret.Name, Namespace: "default"},
+					TenantID:     "my-tenant-id",
+				},
+			}
+			secondaryIdentity := &infrav1.AzureClusterIdentity{
+				ObjectMeta: metav1.ObjectMeta{Name: "hub-identity-" + suffix, Namespace: "default"},
+				Spec: infrav1.AzureClusterIdentitySpec{
+					Type:         tc.secondaryType,
+					ClientID:     "hub-client-id",
+					ClientSecret: corev1.SecretReference{Name: secret.Name, Namespace: "default"},
+					TenantID:     tc.secondaryTenantID,
+				},
+			}
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+			}
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "default"},
+				Spec: infrav1.AzureClusterSpec{
+					SubscriptionID:       "123",
+					IdentityRef:          &corev1.ObjectReference{Name: identity.Name},
+					SecondaryIdentityRef: &corev1.ObjectReference{Name: secondaryIdentity.Name},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(secret, identity, secondaryIdentity, cluster, azureCluster).Build()
+
+			clusterScope, err := NewClusterScope(context.TODO(), ClusterScopeParams{
+				Client:       fakeClient,
+				Cluster:      cluster,
+				AzureCluster: azureCluster,
+			})
+			if tc.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(clusterScope.SecondaryAuthorizer()).NotTo(BeIdenticalTo(clusterScope.Authorizer()))
+			if tc.expectMultiTenantTokens {
+				g.Expect(clusterScope.Authorizer()).To(BeAssignableToTypeOf(&autorest.MultiTenantBearerAuthorizer{}))
+				g.Expect(clusterScope.SecondaryAuthorizer()).To(BeAssignableToTypeOf(&autorest.MultiTenantBearerAuthorizer{}))
+			} else {
+				g.Expect(clusterScope.Authorizer()).To(BeAssignableToTypeOf(&autorest.BearerAuthorizer{}))
+				g.Expect(clusterScope.SecondaryAuthorizer()).To(BeAssignableToTypeOf(&autorest.BearerAuthorizer{}))
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/cluster-api-provider-azure/util/cache/ttllru"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	credentialsCacheSize = 1024
+	// credentialsCacheTTL is how long the credentials of an identity are kept after they were last used.
+	credentialsCacheTTL = 2 * time.Hour
+	// tokenRefreshWithin is how long before its expiry the token of a cached authorizer is refreshed.
+	tokenRefreshWithin = 10 * time.Minute
+)
+
+var (
+	credentialsCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "capz_credentials_cache_requests_total",
+		Help: "Number of lookups of Azure credentials in the credentials cache, partitioned by result (hit or miss).",
+	}, []string{"result"})
+	credentialsTokenRefreshes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "capz_credentials_token_refreshes_total",
+		Help: "Number of Azure AD tokens obtained or refreshed for the cached credentials.",
+	})
+
+	credentialsCacheOnce sync.Once
+	credentialsCache     *authorizerCache
+	credentialsCacheErr  error
+)
+
+func init() {
+	metrics.Registry.MustRegister(credentialsCacheRequests, credentialsTokenRefreshes)
+}
+
+// credentialsCacheKey identifies the credentials of an identity in a tenant and cloud environment. The identity is
+// empty for the credentials from the environment of the controller.
+type credentialsCacheKey struct {
+	identityNamespace       string
+	identityName            string
+	tenantID                string
+	auxiliaryTenantIDs      string
+	clientID                string
+	activeDirectoryEndpoint string
+	resourceManagerEndpoint string
+}
+
+// cachedAuthorizer is an authorizer along with the version of the credentials it was created from.
+type cachedAuthorizer struct {
+	authorizer autorest.Authorizer
+	version    string
+}
+
+// authorizerCache is a process-wide cache of the authorizers of the identities, so that the scopes of all the clusters
+// and machines using an identity share its token instead of each requesting a new one.
+type authorizerCache struct {
+	cache ttllru.Cacher
+}
+
+// getCredentialsCache returns the process-wide credentials cache.
+func getCredentialsCache() (*authorizerCache, error) {
+	credentialsCacheOnce.Do(func() {
+		var cache ttllru.PeekingCacher
+		cache, credentialsCacheErr = ttllru.New(credentialsCacheSize, credentialsCacheTTL)
+		credentialsCache = &authorizerCache{cache: cache}
+	})
+
+	if credentialsCacheErr != nil {
+		return nil, errors.Wrap(credentialsCacheErr, "failed creating LRU cache for credentials")
+	}
+	return credentialsCache, nil
+}
+
+// get returns the cached authorizer of the key if it was created from the same version of the credentials. An
+// authorizer created from an older version, e.g. before the secret of the identity was rotated, is removed.
+func (c *authorizerCache) get(key credentialsCacheKey, version string) (autorest.Authorizer, bool) {
+	if value, ok := c.cache.Get(key); ok {
+		if cached, ok := value.(*cachedAuthorizer); ok && cached.version == version {
+			credentialsCacheRequests.WithLabelValues("hit").Inc()
+			return cached.authorizer, true
+		}
+		c.cache.Remove(key)
+	}
+
+	credentialsCacheRequests.WithLabelValues("miss").Inc()
+	return nil, false
+}
+
+// getOrCreate returns the cached authorizer of the key, creating a new one when the cache has none for this version of
+// the credentials.
+func (c *authorizerCache) getOrCreate(key credentialsCacheKey, version string, create func() (autorest.Authorizer, error)) (autorest.Authorizer, error) {
+	if authorizer, ok := c.get(key, version); ok {
+		return authorizer, nil
+	}
+
+	authorizer, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	c.cache.Add(key, &cachedAuthorizer{
+		authorizer: authorizer,
+		version:    version,
+	})
+	return authorizer, nil
+}
+
+// newCachedTokenAuthorizer returns a bearer authorizer for a token shared by the scopes using the cached authorizer. The
+// token is obtained on first use and refreshed ahead of its expiry by the requests of any of the scopes.
+func newCachedTokenAuthorizer(spt *adal.ServicePrincipalToken) autorest.Authorizer {
+	setCachedTokenRefresh(spt)
+	return autorest.NewBearerAuthorizer(spt)
+}
+
+// newCachedMultiTenantTokenAuthorizer is newCachedTokenAuthorizer for a token of several tenants, which authorizes the
+// requests with the token of the primary tenant along with the tokens of the auxiliary tenants.
+func newCachedMultiTenantTokenAuthorizer(mtSPT *adal.MultiTenantServicePrincipalToken) autorest.Authorizer {
+	setCachedTokenRefresh(mtSPT.PrimaryToken)
+	for _, spt := range mtSPT.AuxiliaryTokens {
+		setCachedTokenRefresh(spt)
+	}
+	return autorest.NewMultiTenantBearerAuthorizer(mtSPT)
+}
+
+// setCachedTokenRefresh makes the token refresh itself ahead of its expiry.
+func setCachedTokenRefresh(spt *adal.ServicePrincipalToken) {
+	spt.SetAutoRefresh(true)
+	spt.SetRefreshWithin(tokenRefreshWithin)
+	spt.SetRefreshCallbacks([]adal.TokenRefreshCallback{
+		func(adal.Token) error {
+			credentialsTokenRefreshes.Inc()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/util/cache/ttllru"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAuthorizerCache(t *testing.T) {
+	g := NewWithT(t)
+
+	ttlCache, err := ttllru.New(10, time.Hour)
+	g.Expect(err).NotTo(HaveOccurred())
+	cache := &authorizerCache{cache: ttlCache}
+
+	var created int
+	create := func() (autorest.Authorizer, error) {
+		created++
+		return autorest.NewBearerAuthorizer(&adal.Token{}), nil
+	}
+	key := credentialsCacheKey{identityNamespace: "default", identityName: "identity", tenantID: "tenant"}
+
+	hits := testutil.ToFloat64(credentialsCacheRequests.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(credentialsCacheRequests.WithLabelValues("miss"))
+
+	first, err := cache.getOrCreate(key, "1", create)
+	g.Expect(err).NotTo(HaveOccurred())
+	second, err := cache.getOrCreate(key, "1", create)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(second).To(BeIdenticalTo(first))
+	g.Expect(created).To(Equal(1))
+
+	// another tenant of the same identity has its own authorizer
+	otherTenant := key
+	otherTenant.tenantID = "other-tenant"
+	third, err := cache.getOrCreate(otherTenant, "1", create)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(third).NotTo(BeIdenticalTo(first))
+	g.Expect(created).To(Equal(2))
+
+	// a new version of the credentials replaces the authorizer
+	rotated, err := cache.getOrCreate(key, "2", create)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotated).NotTo(BeIdenticalTo(first))
+	g.Expect(created).To(Equal(3))
+
+	g.Expect(testutil.ToFloat64(credentialsCacheRequests.WithLabelValues("hit"))).To(Equal(hits + 1))
+	g.Expect(testutil.ToFloat64(credentialsCacheRequests.WithLabelValues("miss"))).To(Equal(misses + 3))
+
+	// failures are not cached
+	_, err = cache.getOrCreate(credentialsCacheKey{tenantID: "failing"}, "1", func() (autorest.Authorizer, error) {
+		return nil, errors.New("failed to create authorizer")
+	})
+	g.Expect(err).To(HaveOccurred())
+	_, ok := cache.get(credentialsCacheKey{tenantID: "failing"}, "1")
+	g.Expect(ok).To(BeFalse())
+}
+
+func TestCachedTokenAuthorizerRefreshes(t *testing.T) {
+	g := NewWithT(t)
+
+	oauthConfig, err := adal.NewOAuthConfig("https://login.microsoftonline.com/", "my-tenant-id")
+	g.Expect(err).NotTo(HaveOccurred())
+	spt, err := adal.NewServicePrincipalToken(*oauthConfig, "my-client-id", "my-client-secret", "https://management.azure.com/")
+	g.Expect(err).NotTo(HaveOccurred())
+	spt.SetSender(tokenSender{})
+
+	refreshes := testutil.ToFloat64(credentialsTokenRefreshes)
+	_ = newCachedTokenAuthorizer(spt)
+	g.Expect(spt.EnsureFresh()).To(Succeed())
+	g.Expect(spt.OAuthToken()).To(Equal("access-token"))
+	g.Expect(testutil.ToFloat64(credentialsTokenRefreshes)).To(Equal(refreshes + 1))
+
+	// the token is not refreshed while it is valid for longer than the refresh window
+	g.Expect(spt.EnsureFresh()).To(Succeed())
+	g.Expect(testutil.ToFloat64(credentialsTokenRefreshes)).To(Equal(refreshes + 1))
+}
+
+func TestGetAuthorizerIsCached(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cached-identity-secret",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			azureSecretKey: []byte("my-client-secret"),
+		},
+	}
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cached-identity",
+			Namespace: "default",
+			UID:       "1234",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:         infrav1.ManualServicePrincipal,
+			ClientID:     "my-client-id",
+			ClientSecret: corev1.SecretReference{Name: "cached-identity-secret", Namespace: "default"},
+			TenantID:     "my-tenant-id",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(secret).Build()
+	provider := &AzureCredentialsProvider{
+		Client:   fakeClient,
+		Identity: identity,
+	}
+	getAuthorizer := func() autorest.Authorizer {
+		authorizer, err := provider.GetAuthorizer(context.TODO(), "https://management.azure.com/", "https://login.microsoftonline.com/", metav1.ObjectMeta{Name: "cluster-name", Namespace: "default"})
+		g.Expect(err).NotTo(HaveOccurred())
+		return authorizer
+	}
+
+	first := getAuthorizer()
+	g.Expect(getAuthorizer()).To(BeIdenticalTo(first))
+
+	// a rotated secret invalidates the cached authorizer
+	g.Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+	secret.Data[azureSecretKey] = []byte("my-rotated-client-secret")
+	g.Expect(fakeClient.Update(context.TODO(), secret)).To(Succeed())
+	rotated := getAuthorizer()
+	g.Expect(rotated).NotTo(BeIdenticalTo(first))
+	g.Expect(getAuthorizer()).To(BeIdenticalTo(rotated))
+
+	// so does a change of the identity
+	identity.Generation++
+	g.Expect(getAuthorizer()).NotTo(BeIdenticalTo(rotated))
+}
+
+// tokenSender responds to token requests with a token valid for an hour.
+type tokenSender struct{}
+
+func (tokenSender) Do(req *http.Request) (*http.Response, error) {
+	expiresOn := time.Now().Add(time.Hour).Unix()
+	body := `{"access_token":"access-token","expires_in":"3600","expires_on":"` + strconv.FormatInt(expiresOn, 10) + `","not_before":"` + strconv.FormatInt(expiresOn-3600, 10) + `","resource":"https://management.azure.com/","token_type":"Bearer"}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
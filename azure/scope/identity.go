@@ -18,8 +18,14 @@ package scope
 
 import (
 	"context"
+	"crypto/rsa"
+	"crypto/x509"
 	"fmt"
+	"net/url"
+	"os"
 	"reflect"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -40,7 +46,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const azureSecretKey = "clientSecret"
+const (
+	azureSecretKey = "clientSecret"
+
+	// federatedTokenFileEnv is the environment variable overriding the path of the projected service account token
+	// exchanged for an AAD token by workload identities.
+	federatedTokenFileEnv = "AZURE_FEDERATED_TOKEN_FILE"
+	// defaultFederatedTokenFile is the path the service account token is projected to in the controller pod.
+	defaultFederatedTokenFile = "/var/run/secrets/azure/tokens/azure-identity-token"
+	// jwtBearerAssertionType is the OAuth client assertion type of a JWT used as client credential.
+	jwtBearerAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
 
 // CredentialsProvider defines the behavior for azure identity based credential providers.
 type CredentialsProvider interface {
@@ -48,12 +64,16 @@ type CredentialsProvider interface {
 	GetClientID() string
 	GetClientSecret(ctx context.Context) (string, error)
 	GetTenantID() string
+	GetType() infrav1.IdentityType
 }
 
 // AzureCredentialsProvider represents a credential provider with azure cluster identity.
 type AzureCredentialsProvider struct {
 	Client   client.Client
 	Identity *infrav1.AzureClusterIdentity
+	// AuxiliaryTenantIDs are the other tenants the identity also gets tokens for, which authorize the requests
+	// involving resources of these tenants, e.g. the peering with a virtual network in another tenant.
+	AuxiliaryTenantIDs []string
 }
 
 // AzureClusterCredentialsProvider wraps AzureCredentialsProvider with AzureCluster.
@@ -73,24 +93,27 @@ var _ CredentialsProvider = (*ManagedControlPlaneCredentialsProvider)(nil)
 
 // NewAzureClusterCredentialsProvider creates a new AzureClusterCredentialsProvider from the supplied inputs.
 func NewAzureClusterCredentialsProvider(ctx context.Context, kubeClient client.Client, azureCluster *infrav1.AzureCluster) (*AzureClusterCredentialsProvider, error) {
-	if azureCluster.Spec.IdentityRef == nil {
+	return newAzureClusterCredentialsProvider(ctx, kubeClient, azureCluster, azureCluster.Spec.IdentityRef)
+}
+
+// NewAzureClusterSecondaryCredentialsProvider creates a new AzureClusterCredentialsProvider for the secondary identity
+// of the AzureCluster.
+func NewAzureClusterSecondaryCredentialsProvider(ctx context.Context, kubeClient client.Client, azureCluster *infrav1.AzureCluster) (*AzureClusterCredentialsProvider, error) {
+	return newAzureClusterCredentialsProvider(ctx, kubeClient, azureCluster, azureCluster.Spec.SecondaryIdentityRef)
+}
+
+func newAzureClusterCredentialsProvider(ctx context.Context, kubeClient client.Client, azureCluster *infrav1.AzureCluster, ref *corev1.ObjectReference) (*AzureClusterCredentialsProvider, error) {
+	if ref == nil {
 		return nil, errors.New("failed to generate new AzureClusterCredentialsProvider from empty identityName")
 	}
 
-	ref := azureCluster.Spec.IdentityRef
-	// if the namespace isn't specified then assume it's in the same namespace as the AzureCluster
-	namespace := ref.Namespace
-	if namespace == "" {
-		namespace = azureCluster.Namespace
-	}
-	identity := &infrav1.AzureClusterIdentity{}
-	key := client.ObjectKey{Name: ref.Name, Namespace: namespace}
-	if err := kubeClient.Get(ctx, key, identity); err != nil {
-		return nil, errors.Errorf("failed to retrieve AzureClusterIdentity external object %q/%q: %v", key.Namespace, key.Name, err)
+	identity, err := GetClusterIdentityFromRef(ctx, kubeClient, azureCluster.Namespace, ref)
+	if err != nil {
+		return nil, err
 	}
 
-	if identity.Spec.Type != infrav1.ServicePrincipal {
-		return nil, errors.New("AzureClusterIdentity is not of type Service Principal")
+	if !isCredentialsProviderIdentityType(identity.Spec.Type) {
+		return nil, errors.Errorf("identity %s/%s is not of type Service Principal, Manual Service Principal, Service Principal Certificate or Workload Identity", identity.Namespace, identity.Name)
 	}
 
 	return &AzureClusterCredentialsProvider{
@@ -102,6 +125,39 @@ func NewAzureClusterCredentialsProvider(ctx context.Context, kubeClient client.C
 	}, nil
 }
 
+// GetClusterIdentityFromRef returns the identity referenced by a cluster in the namespace. The identity is in the
+// namespace of the cluster unless the reference sets another one. A reference to an AzureNamespacedIdentity resolves to
+// its equivalent AzureClusterIdentity and cannot cross namespaces.
+func GetClusterIdentityFromRef(ctx context.Context, kubeClient client.Client, namespace string, ref *corev1.ObjectReference) (*infrav1.AzureClusterIdentity, error) {
+	if ref == nil {
+		return nil, nil
+	}
+
+	identityNamespace := ref.Namespace
+	if identityNamespace == "" {
+		identityNamespace = namespace
+	}
+	key := client.ObjectKey{Name: ref.Name, Namespace: identityNamespace}
+
+	if ref.Kind == infrav1.AzureNamespacedIdentityKind {
+		if identityNamespace != namespace {
+			return nil, errors.Errorf("%s %q/%q cannot be used from namespace %q, namespaced identities can only be used by the clusters in their own namespace",
+				ref.Kind, key.Namespace, key.Name, namespace)
+		}
+		identity := &infrav1.AzureNamespacedIdentity{}
+		if err := kubeClient.Get(ctx, key, identity); err != nil {
+			return nil, errors.Wrapf(err, "failed to retrieve %s external object %q/%q", ref.Kind, key.Namespace, key.Name)
+		}
+		return identity.ToClusterIdentity(), nil
+	}
+
+	identity := &infrav1.AzureClusterIdentity{}
+	if err := kubeClient.Get(ctx, key, identity); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve %s external object %q/%q", infrav1.AzureClusterIdentityKind, key.Namespace, key.Name)
+	}
+	return identity, nil
+}
+
 // GetAuthorizer returns an Azure authorizer based on the provided azure identity. It delegates to AzureCredentialsProvider with AzureCluster metadata.
 func (p *AzureClusterCredentialsProvider) GetAuthorizer(ctx context.Context, resourceManagerEndpoint, activeDirectoryEndpoint string) (autorest.Authorizer, error) {
 	return p.AzureCredentialsProvider.GetAuthorizer(ctx, resourceManagerEndpoint, activeDirectoryEndpoint, p.AzureCluster.ObjectMeta)
@@ -113,20 +169,13 @@ func NewManagedControlPlaneCredentialsProvider(ctx context.Context, kubeClient c
 		return nil, errors.New("failed to generate new ManagedControlPlaneCredentialsProvider from empty identityName")
 	}
 
-	ref := managedControlPlane.Spec.IdentityRef
-	// if the namespace isn't specified then assume it's in the same namespace as the AzureManagedControlPlane
-	namespace := ref.Namespace
-	if namespace == "" {
-		namespace = managedControlPlane.Namespace
-	}
-	identity := &infrav1.AzureClusterIdentity{}
-	key := client.ObjectKey{Name: ref.Name, Namespace: namespace}
-	if err := kubeClient.Get(ctx, key, identity); err != nil {
-		return nil, errors.Errorf("failed to retrieve AzureClusterIdentity external object %q/%q: %v", key.Namespace, key.Name, err)
+	identity, err := GetClusterIdentityFromRef(ctx, kubeClient, managedControlPlane.Namespace, managedControlPlane.Spec.IdentityRef)
+	if err != nil {
+		return nil, err
 	}
 
-	if identity.Spec.Type != infrav1.ServicePrincipal {
-		return nil, errors.New("AzureClusterIdentity is not of type Service Principal")
+	if !isCredentialsProviderIdentityType(identity.Spec.Type) {
+		return nil, errors.Errorf("identity %s/%s is not of type Service Principal, Manual Service Principal, Service Principal Certificate or Workload Identity", identity.Namespace, identity.Name)
 	}
 
 	return &ManagedControlPlaneCredentialsProvider{
@@ -143,15 +192,77 @@ func (p *ManagedControlPlaneCredentialsProvider) GetAuthorizer(ctx context.Conte
 	return p.AzureCredentialsProvider.GetAuthorizer(ctx, resourceManagerEndpoint, activeDirectoryEndpoint, p.AzureManagedControlPlane.ObjectMeta)
 }
 
-// GetAuthorizer returns an Azure authorizer based on the provided azure identity and cluster metadata.
+// GetAuthorizer returns an Azure authorizer based on the provided azure identity and cluster metadata. The authorizer
+// is shared by all the clusters using the identity until the identity or its secret changes.
 func (p *AzureCredentialsProvider) GetAuthorizer(ctx context.Context, resourceManagerEndpoint, activeDirectoryEndpoint string, clusterMeta metav1.ObjectMeta) (autorest.Authorizer, error) {
-	var spt *adal.ServicePrincipalToken
-	switch p.Identity.Spec.Type {
-	case infrav1.ServicePrincipal:
+	if p.Identity.Spec.Type == infrav1.ServicePrincipal {
+		// aad-pod-identity needs the AzureIdentity of each cluster, even when the authorizer of the identity is cached
 		if err := createAzureIdentityWithBindings(ctx, p.Identity, resourceManagerEndpoint, activeDirectoryEndpoint, clusterMeta, p.Client); err != nil {
 			return nil, err
 		}
+	}
+
+	cache, err := getCredentialsCache()
+	if err != nil {
+		return nil, err
+	}
 
+	version, err := p.credentialsVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := credentialsCacheKey{
+		identityNamespace:       p.Identity.Namespace,
+		identityName:            p.Identity.Name,
+		tenantID:                p.GetTenantID(),
+		auxiliaryTenantIDs:      strings.Join(p.AuxiliaryTenantIDs, ";"),
+		clientID:                p.GetClientID(),
+		activeDirectoryEndpoint: activeDirectoryEndpoint,
+		resourceManagerEndpoint: resourceManagerEndpoint,
+	}
+	return cache.getOrCreate(key, version, func() (autorest.Authorizer, error) {
+		if len(p.AuxiliaryTenantIDs) > 0 {
+			mtSPT, err := p.newMultiTenantServicePrincipalToken(ctx, resourceManagerEndpoint, activeDirectoryEndpoint)
+			if err != nil {
+				return nil, err
+			}
+			return newCachedMultiTenantTokenAuthorizer(mtSPT), nil
+		}
+		spt, err := p.newServicePrincipalToken(ctx, resourceManagerEndpoint, activeDirectoryEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		return newCachedTokenAuthorizer(spt), nil
+	})
+}
+
+// credentialsVersion returns the version of the credentials of the identity, which changes whenever the identity is
+// recreated, its spec changes or its secret changes.
+func (p *AzureCredentialsProvider) credentialsVersion(ctx context.Context) (string, error) {
+	version := fmt.Sprintf("%s/%d", p.Identity.UID, p.Identity.Generation)
+
+	secretRef := p.Identity.Spec.ClientSecret
+	if secretRef.Name == "" {
+		return version, nil
+	}
+
+	key := types.NamespacedName{
+		Namespace: secretRef.Namespace,
+		Name:      secretRef.Name,
+	}
+	secret := &corev1.Secret{}
+	if err := p.Client.Get(ctx, key, secret); err != nil {
+		return "", errors.Wrap(err, "Unable to fetch identity secret")
+	}
+	return fmt.Sprintf("%s/%s", version, secret.ResourceVersion), nil
+}
+
+// newServicePrincipalToken returns a token for the resource manager, authenticating according to the identity type.
+func (p *AzureCredentialsProvider) newServicePrincipalToken(ctx context.Context, resourceManagerEndpoint, activeDirectoryEndpoint string) (*adal.ServicePrincipalToken, error) {
+	var spt *adal.ServicePrincipalToken
+	switch p.Identity.Spec.Type {
+	case infrav1.ServicePrincipal:
 		msiEndpoint, err := adal.GetMSIVMEndpoint()
 		if err != nil {
 			return nil, errors.Errorf("failed to get MSI endpoint: %v", err)
@@ -178,11 +289,138 @@ func (p *AzureCredentialsProvider) GetAuthorizer(ctx context.Context, resourceMa
 			return nil, errors.Errorf("failed to get token from service principal identity: %v", err)
 		}
 
+	case infrav1.ServicePrincipalCertificate:
+		oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, p.GetTenantID())
+		if err != nil {
+			return nil, err
+		}
+
+		certificate, privateKey, err := p.getCertificate(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get client certificate")
+		}
+
+		spt, err = adal.NewServicePrincipalTokenFromCertificate(*oauthConfig, p.Identity.Spec.ClientID, certificate, privateKey, resourceManagerEndpoint)
+		if err != nil {
+			return nil, errors.Errorf("failed to get token from service principal certificate: %v", err)
+		}
+
+	case infrav1.WorkloadIdentity:
+		// the service account token is exchanged for an AAD token directly, so no AzureIdentity is needed
+		oauthConfig, err := adal.NewOAuthConfig(activeDirectoryEndpoint, p.GetTenantID())
+		if err != nil {
+			return nil, err
+		}
+
+		spt, err = adal.NewServicePrincipalTokenWithSecret(*oauthConfig, p.Identity.Spec.ClientID, resourceManagerEndpoint, &federatedTokenSecret{
+			tokenFile: federatedTokenFile(),
+		})
+		if err != nil {
+			return nil, errors.Errorf("failed to get token from workload identity: %v", err)
+		}
+
 	default:
 		return nil, errors.Errorf("identity type %s not supported", p.Identity.Spec.Type)
 	}
 
-	return autorest.NewBearerAuthorizer(spt), nil
+	return spt, nil
+}
+
+// newMultiTenantServicePrincipalToken returns a token for the resource manager in the tenant of the identity and in its
+// auxiliary tenants. Only the identities authenticating with a client secret or certificate support auxiliary tenants.
+func (p *AzureCredentialsProvider) newMultiTenantServicePrincipalToken(ctx context.Context, resourceManagerEndpoint, activeDirectoryEndpoint string) (*adal.MultiTenantServicePrincipalToken, error) {
+	switch p.Identity.Spec.Type {
+	case infrav1.ManualServicePrincipal:
+		oauthConfig, err := adal.NewMultiTenantOAuthConfig(activeDirectoryEndpoint, p.GetTenantID(), p.AuxiliaryTenantIDs, adal.OAuthOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		clientSecret, err := p.GetClientSecret(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get client secret")
+		}
+
+		mtSPT, err := adal.NewMultiTenantServicePrincipalToken(oauthConfig, p.Identity.Spec.ClientID, clientSecret, resourceManagerEndpoint)
+		if err != nil {
+			return nil, errors.Errorf("failed to get multi-tenant token from service principal identity: %v", err)
+		}
+		return mtSPT, nil
+
+	case infrav1.ServicePrincipalCertificate:
+		oauthConfig, err := adal.NewMultiTenantOAuthConfig(activeDirectoryEndpoint, p.GetTenantID(), p.AuxiliaryTenantIDs, adal.OAuthOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		certificate, privateKey, err := p.getCertificate(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get client certificate")
+		}
+
+		mtSPT, err := adal.NewMultiTenantServicePrincipalTokenFromCertificate(oauthConfig, p.Identity.Spec.ClientID, certificate, privateKey, resourceManagerEndpoint)
+		if err != nil {
+			return nil, errors.Errorf("failed to get multi-tenant token from service principal certificate: %v", err)
+		}
+		return mtSPT, nil
+
+	default:
+		return nil, errors.Errorf("identity type %s does not support auxiliary tenants", p.Identity.Spec.Type)
+	}
+}
+
+// GetToken requests a new token for the resource manager with the credentials of the identity. Unlike GetAuthorizer,
+// it bypasses the credentials cache, so that revoked or expired credentials are detected.
+func (p *AzureCredentialsProvider) GetToken(ctx context.Context, resourceManagerEndpoint, activeDirectoryEndpoint string) (*adal.ServicePrincipalToken, error) {
+	spt, err := p.newServicePrincipalToken(ctx, resourceManagerEndpoint, activeDirectoryEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := spt.RefreshWithContext(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to request token")
+	}
+	return spt, nil
+}
+
+// GetSecretExpiry returns the time the credentials in the secret of the identity expire, or nil when it cannot be
+// determined. Only the expiry of certificates is known, client secrets do not carry their expiry.
+func (p *AzureCredentialsProvider) GetSecretExpiry(ctx context.Context) (*time.Time, error) {
+	if p.Identity.Spec.Type != infrav1.ServicePrincipalCertificate {
+		return nil, nil
+	}
+
+	certificate, _, err := p.getCertificate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &certificate.NotAfter, nil
+}
+
+// federatedTokenSecret authenticates a client with a federated credential, using the projected service account token
+// of the controller as client assertion.
+type federatedTokenSecret struct {
+	tokenFile string
+}
+
+// SetAuthenticationValues implements adal.ServicePrincipalSecret. The token is read again on every refresh since the
+// kubelet rotates it before it expires.
+func (s *federatedTokenSecret) SetAuthenticationValues(_ *adal.ServicePrincipalToken, v *url.Values) error {
+	token, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read service account token from %s", s.tokenFile)
+	}
+
+	v.Set("client_assertion", strings.TrimSpace(string(token)))
+	v.Set("client_assertion_type", jwtBearerAssertionType)
+	return nil
+}
+
+// federatedTokenFile returns the path of the service account token exchanged by workload identities.
+func federatedTokenFile() string {
+	if path := os.Getenv(federatedTokenFileEnv); path != "" {
+		return path
+	}
+	return defaultFederatedTokenFile
 }
 
 // GetClientID returns the Client ID associated with the AzureCredentialsProvider's Identity.
@@ -194,6 +432,11 @@ func (p *AzureCredentialsProvider) GetClientID() string {
 // NOTE: this only works if the Identity references a Service Principal Client Secret.
 // If using another type of credentials, such a Certificate, we return an empty string.
 func (p *AzureCredentialsProvider) GetClientSecret(ctx context.Context) (string, error) {
+	if p.Identity.Spec.Type == infrav1.WorkloadIdentity {
+		// workload identities authenticate with the service account token and have no secret
+		return "", nil
+	}
+
 	secretRef := p.Identity.Spec.ClientSecret
 	key := types.NamespacedName{
 		Namespace: secretRef.Namespace,
@@ -207,11 +450,41 @@ func (p *AzureCredentialsProvider) GetClientSecret(ctx context.Context) (string,
 	return string(secret.Data[azureSecretKey]), nil
 }
 
+// getCertificate returns the certificate and private key of the secret referenced by the AzureCredentialsProvider's
+// Identity. The secret is read on every call, so a rotated certificate is used as soon as the secret is updated.
+func (p *AzureCredentialsProvider) getCertificate(ctx context.Context) (*x509.Certificate, *rsa.PrivateKey, error) {
+	secretRef := p.Identity.Spec.ClientSecret
+	key := types.NamespacedName{
+		Namespace: secretRef.Namespace,
+		Name:      secretRef.Name,
+	}
+	secret := &corev1.Secret{}
+
+	if err := p.Client.Get(ctx, key, secret); err != nil {
+		return nil, nil, errors.Wrap(err, "Unable to fetch client certificate")
+	}
+	return identityCertificates.get(secret)
+}
+
 // GetTenantID returns the Tenant ID associated with the AzureCredentialsProvider's Identity.
 func (p *AzureCredentialsProvider) GetTenantID() string {
 	return p.Identity.Spec.TenantID
 }
 
+// GetType returns the type of the AzureCredentialsProvider's Identity.
+func (p *AzureCredentialsProvider) GetType() infrav1.IdentityType {
+	return p.Identity.Spec.Type
+}
+
+// isCredentialsProviderIdentityType returns whether the identity type can be used to create a credentials provider.
+func isCredentialsProviderIdentityType(identityType infrav1.IdentityType) bool {
+	switch identityType {
+	case infrav1.ServicePrincipal, infrav1.ManualServicePrincipal, infrav1.ServicePrincipalCertificate, infrav1.WorkloadIdentity:
+		return true
+	}
+	return false
+}
+
 func createAzureIdentityWithBindings(ctx context.Context, azureIdentity *infrav1.AzureClusterIdentity, resourceManagerEndpoint, activeDirectoryEndpoint string, clusterMeta metav1.ObjectMeta,
 	kubeClient client.Client) error {
 	azureIdentityType, err := getAzureIdentityType(azureIdentity)
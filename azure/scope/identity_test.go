@@ -18,6 +18,11 @@ package scope
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	aadpodv1 "github.com/Azure/aad-pod-identity/pkg/apis/aadpodidentity/v1"
@@ -25,6 +30,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -265,3 +271,298 @@ func TestCreateAzureIdentityWithBindings(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkloadIdentityGetAuthorizer(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+	_ = aadpodv1.AddToScheme(scheme)
+
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-identity",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:     infrav1.WorkloadIdentity,
+			ClientID: "my-client-id",
+			TenantID: "my-tenant-id",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(identity).Build()
+	provider := &AzureCredentialsProvider{
+		Client:   fakeClient,
+		Identity: identity,
+	}
+
+	authorizer, err := provider.GetAuthorizer(context.TODO(), "https://management.azure.com/", "https://login.microsoftonline.com/", metav1.ObjectMeta{Name: "cluster-name", Namespace: "default"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(authorizer).NotTo(BeNil())
+
+	clientSecret, err := provider.GetClientSecret(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clientSecret).To(BeEmpty())
+
+	// workload identities do not depend on aad-pod-identity
+	identities := &aadpodv1.AzureIdentityList{}
+	g.Expect(fakeClient.List(context.TODO(), identities)).To(Succeed())
+	g.Expect(identities.Items).To(BeEmpty())
+	bindings := &aadpodv1.AzureIdentityBindingList{}
+	g.Expect(fakeClient.List(context.TODO(), bindings)).To(Succeed())
+	g.Expect(bindings.Items).To(BeEmpty())
+}
+
+func TestFederatedTokenSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	g.Expect(os.WriteFile(tokenFile, []byte("service-account-token\n"), 0600)).To(Succeed())
+
+	secret := &federatedTokenSecret{tokenFile: tokenFile}
+	values := url.Values{}
+	g.Expect(secret.SetAuthenticationValues(nil, &values)).To(Succeed())
+	g.Expect(values.Get("client_assertion")).To(Equal("service-account-token"))
+	g.Expect(values.Get("client_assertion_type")).To(Equal(jwtBearerAssertionType))
+
+	// the token is read again after the kubelet rotates it
+	g.Expect(os.WriteFile(tokenFile, []byte("rotated-token"), 0600)).To(Succeed())
+	g.Expect(secret.SetAuthenticationValues(nil, &values)).To(Succeed())
+	g.Expect(values.Get("client_assertion")).To(Equal("rotated-token"))
+
+	secret.tokenFile = filepath.Join(t.TempDir(), "missing")
+	g.Expect(secret.SetAuthenticationValues(nil, &values)).NotTo(Succeed())
+}
+
+func TestFederatedTokenFile(t *testing.T) {
+	g := NewWithT(t)
+
+	t.Setenv(federatedTokenFileEnv, "")
+	g.Expect(federatedTokenFile()).To(Equal(defaultFederatedTokenFile))
+
+	t.Setenv(federatedTokenFileEnv, "/custom/token")
+	g.Expect(federatedTokenFile()).To(Equal("/custom/token"))
+}
+
+func TestServicePrincipalCertificateGetAuthorizer(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	certPEM, key := newTestCertificate(t, "service-principal")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sp-certificate",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			azureCertificateKey: append(certPEM, keyPEM...),
+		},
+	}
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-identity",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:         infrav1.ServicePrincipalCertificate,
+			ClientID:     "my-client-id",
+			ClientSecret: corev1.SecretReference{Name: "sp-certificate", Namespace: "default"},
+			TenantID:     "my-tenant-id",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(identity, secret).Build()
+	provider := &AzureCredentialsProvider{
+		Client:   fakeClient,
+		Identity: identity,
+	}
+
+	authorizer, err := provider.GetAuthorizer(context.TODO(), "https://management.azure.com/", "https://login.microsoftonline.com/", metav1.ObjectMeta{Name: "cluster-name", Namespace: "default"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(authorizer).NotTo(BeNil())
+
+	provider.Identity.Spec.ClientSecret.Name = "missing"
+	_, err = provider.GetAuthorizer(context.TODO(), "https://management.azure.com/", "https://login.microsoftonline.com/", metav1.ObjectMeta{Name: "cluster-name", Namespace: "default"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestGetSecretExpiry(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	certPEM, key := newTestCertificate(t, "expiring-service-principal")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	block, _ := pem.Decode(certPEM)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the parsed certificates are cached by secret, so the secret must not be shared with other tests
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "expiring-sp-certificate",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			azureCertificateKey: append(certPEM, keyPEM...),
+		},
+	}
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-identity",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:         infrav1.ServicePrincipalCertificate,
+			ClientID:     "my-client-id",
+			ClientSecret: corev1.SecretReference{Name: "expiring-sp-certificate", Namespace: "default"},
+			TenantID:     "my-tenant-id",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(identity, secret).Build()
+	provider := &AzureCredentialsProvider{
+		Client:   fakeClient,
+		Identity: identity,
+	}
+
+	expiry, err := provider.GetSecretExpiry(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiry).NotTo(BeNil())
+	g.Expect(expiry.Equal(certificate.NotAfter)).To(BeTrue())
+
+	// the expiry of a client secret is unknown
+	provider.Identity.Spec.Type = infrav1.ManualServicePrincipal
+	expiry, err = provider.GetSecretExpiry(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiry).To(BeNil())
+}
+
+func TestGetClusterIdentityFromRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+
+	clusterIdentity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared-identity",
+			Namespace: "identities",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:         infrav1.ManualServicePrincipal,
+			ClientID:     "shared-client-id",
+			ClientSecret: corev1.SecretReference{Name: "shared-secret", Namespace: "identities"},
+			TenantID:     "my-tenant-id",
+		},
+	}
+	namespacedIdentity := &infrav1.AzureNamespacedIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant-identity",
+			Namespace: "tenant",
+		},
+		Spec: infrav1.AzureNamespacedIdentitySpec{
+			Type:         infrav1.ManualServicePrincipal,
+			ClientID:     "tenant-client-id",
+			ClientSecret: corev1.LocalObjectReference{Name: "tenant-secret"},
+			TenantID:     "my-tenant-id",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterIdentity, namespacedIdentity).Build()
+
+	tests := []struct {
+		name             string
+		ref              *corev1.ObjectReference
+		expectedClientID string
+		expectedSecret   corev1.SecretReference
+		expectedErr      string
+	}{
+		{
+			name: "no identity",
+		},
+		{
+			name:             "cluster identity in another namespace",
+			ref:              &corev1.ObjectReference{Kind: infrav1.AzureClusterIdentityKind, Name: "shared-identity", Namespace: "identities"},
+			expectedClientID: "shared-client-id",
+			expectedSecret:   corev1.SecretReference{Name: "shared-secret", Namespace: "identities"},
+		},
+		{
+			name:             "namespaced identity in the namespace of the cluster",
+			ref:              &corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Name: "tenant-identity"},
+			expectedClientID: "tenant-client-id",
+			expectedSecret:   corev1.SecretReference{Name: "tenant-secret", Namespace: "tenant"},
+		},
+		{
+			name:        "namespaced identity in another namespace",
+			ref:         &corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Name: "tenant-identity", Namespace: "other-tenant"},
+			expectedErr: `AzureNamespacedIdentity "other-tenant"/"tenant-identity" cannot be used from namespace "tenant"`,
+		},
+		{
+			name:        "missing namespaced identity",
+			ref:         &corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Name: "shared-identity"},
+			expectedErr: `failed to retrieve AzureNamespacedIdentity external object "tenant"/"shared-identity"`,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			identity, err := GetClusterIdentityFromRef(context.TODO(), fakeClient, "tenant", tc.ref)
+			if tc.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.expectedErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			if tc.ref == nil {
+				g.Expect(identity).To(BeNil())
+				return
+			}
+			g.Expect(identity.Spec.ClientID).To(Equal(tc.expectedClientID))
+			g.Expect(identity.Spec.ClientSecret).To(Equal(tc.expectedSecret))
+		})
+	}
+}
+
+func TestNamespacedIdentityCredentialsProviders(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = infrav1exp.AddToScheme(scheme)
+
+	namespacedIdentity := &infrav1.AzureNamespacedIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tenant-identity",
+			Namespace: "tenant",
+		},
+		Spec: infrav1.AzureNamespacedIdentitySpec{
+			Type:         infrav1.ServicePrincipalCertificate,
+			ClientID:     "tenant-client-id",
+			ClientSecret: corev1.LocalObjectReference{Name: "tenant-certificate"},
+			TenantID:     "my-tenant-id",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespacedIdentity).Build()
+	ref := &corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Name: "tenant-identity"}
+
+	azureCluster := &infrav1.AzureCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-cluster", Namespace: "tenant"},
+		Spec:       infrav1.AzureClusterSpec{IdentityRef: ref},
+	}
+	clusterProvider, err := NewAzureClusterCredentialsProvider(context.TODO(), fakeClient, azureCluster)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(clusterProvider.GetClientID()).To(Equal("tenant-client-id"))
+
+	controlPlane := &infrav1exp.AzureManagedControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-control-plane", Namespace: "tenant"},
+		Spec:       infrav1exp.AzureManagedControlPlaneSpec{IdentityRef: ref},
+	}
+	controlPlaneProvider, err := NewManagedControlPlaneCredentialsProvider(context.TODO(), fakeClient, controlPlane)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(controlPlaneProvider.Identity.Spec.ClientSecret).To(Equal(corev1.SecretReference{Name: "tenant-certificate", Namespace: "tenant"}))
+
+	// a cluster in another namespace cannot use the namespaced identity
+	azureCluster.Namespace = "other-tenant"
+	azureCluster.Spec.IdentityRef = &corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Name: "tenant-identity", Namespace: "tenant"}
+	_, err = NewAzureClusterCredentialsProvider(context.TODO(), fakeClient, azureCluster)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"github.com/Azure/go-autorest/autorest"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+)
+
+// keyVaultScope describes the Key Vault of the cluster to the scope of a machine or machine pool. It describes no Key
+// Vault when the scope was created without one.
+type keyVaultScope struct {
+	describer azure.KeyVaultDescriber
+}
+
+// KeyVault returns the Key Vault of the cluster, or nil if it has none.
+func (s keyVaultScope) KeyVault() *infrav1.KeyVaultSpec {
+	if s.describer == nil {
+		return nil
+	}
+	return s.describer.KeyVault()
+}
+
+// KeyVaultID returns the resource ID of the Key Vault of the cluster.
+func (s keyVaultScope) KeyVaultID() string {
+	if s.describer == nil {
+		return ""
+	}
+	return s.describer.KeyVaultID()
+}
+
+// KeyVaultURL returns the URL of the data plane of the Key Vault of the cluster.
+func (s keyVaultScope) KeyVaultURL() string {
+	if s.describer == nil {
+		return ""
+	}
+	return s.describer.KeyVaultURL()
+}
+
+// KeyVaultAuthorizer returns the Azure client Authorizer for the data plane of the Key Vault of the cluster.
+func (s keyVaultScope) KeyVaultAuthorizer() autorest.Authorizer {
+	if s.describer == nil {
+		return nil
+	}
+	return s.describer.KeyVaultAuthorizer()
+}
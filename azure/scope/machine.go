@@ -24,12 +24,18 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	kubedrain "k8s.io/kubectl/pkg/drain"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -41,6 +47,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/availabilitysets"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines"
@@ -48,6 +55,9 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
+// MachineScopeName is the sourceName, or more specifically the UserAgent, of the client used to cordon and drain nodes.
+const MachineScopeName = "azuremachine-scope"
+
 // MachineScopeParams defines the input parameters used to create a new MachineScope.
 type MachineScopeParams struct {
 	Client       client.Client
@@ -55,6 +65,8 @@ type MachineScopeParams struct {
 	Machine      *clusterv1.Machine
 	AzureMachine *infrav1.AzureMachine
 	Cache        *MachineCache
+	// KeyVaultScope describes the Key Vault of the cluster, if any.
+	KeyVaultScope azure.KeyVaultDescriber
 }
 
 // NewMachineScope creates a new MachineScope from the supplied parameters.
@@ -81,6 +93,7 @@ func NewMachineScope(params MachineScopeParams) (*MachineScope, error) {
 		AzureMachine:  params.AzureMachine,
 		patchHelper:   helper,
 		ClusterScoper: params.ClusterScope,
+		keyVaultScope: keyVaultScope{describer: params.KeyVaultScope},
 		cache:         params.Cache,
 	}, nil
 }
@@ -91,6 +104,7 @@ type MachineScope struct {
 	patchHelper *patch.Helper
 
 	azure.ClusterScoper
+	keyVaultScope
 	Machine      *clusterv1.Machine
 	AzureMachine *infrav1.AzureMachine
 	cache        *MachineCache
@@ -102,6 +116,8 @@ type MachineCache struct {
 	VMImage            *infrav1.Image
 	VMSKU              resourceskus.SKU
 	availabilitySetSKU resourceskus.SKU
+	// bootstrapDataSecretValue is the value of the Key Vault secret storing the bootstrap data, if any.
+	bootstrapDataSecretValue string
 }
 
 // InitMachineCache sets cached information about the machine to be used in the scope.
@@ -113,11 +129,23 @@ func (m *MachineScope) InitMachineCache(ctx context.Context) error {
 		var err error
 		m.cache = &MachineCache{}
 
-		m.cache.BootstrapData, err = m.GetBootstrapData(ctx)
+		bootstrapData, err := m.getBootstrapDataValue(ctx)
+		if err != nil {
+			return err
+		}
+
+		m.cache.BootstrapData, err = m.customData(bootstrapData)
 		if err != nil {
 			return err
 		}
 
+		if keyVault := m.machineKeyVault(); keyVault != nil && keyVault.BootstrapData {
+			m.cache.bootstrapDataSecretValue, err = keyvaults.EncodeBootstrapData(bootstrapData)
+			if err != nil {
+				return err
+			}
+		}
+
 		m.cache.VMImage, err = m.GetVMImage(ctx)
 		if err != nil {
 			return err
@@ -151,6 +179,8 @@ func (m *MachineScope) VMSpec() azure.ResourceSpecGetter {
 		ClusterName:            m.ClusterName(),
 		Role:                   m.Role(),
 		NICIDs:                 m.NICIDs(),
+		PrimaryNICID:           m.PrimaryNICID(),
+		AcceleratedNetworking:  m.acceleratedNetworking(),
 		SSHKeyData:             m.AzureMachine.Spec.SSHPublicKey,
 		Size:                   m.AzureMachine.Spec.VMSize,
 		OSDisk:                 m.AzureMachine.Spec.OSDisk,
@@ -161,6 +191,7 @@ func (m *MachineScope) VMSpec() azure.ResourceSpecGetter {
 		UserAssignedIdentities: m.AzureMachine.Spec.UserAssignedIdentities,
 		SpotVMOptions:          m.AzureMachine.Spec.SpotVMOptions,
 		SecurityProfile:        m.AzureMachine.Spec.SecurityProfile,
+		Diagnostics:            m.AzureMachine.Spec.Diagnostics,
 		AdditionalTags:         m.AdditionalTags(),
 		ProviderID:             m.ProviderID(),
 	}
@@ -172,6 +203,69 @@ func (m *MachineScope) VMSpec() azure.ResourceSpecGetter {
 	return spec
 }
 
+// ValidateKeyVaultIdentity returns an error if the virtual machine stores any of its secrets in the Key Vault of the
+// cluster without a managed identity to fetch them with.
+func (m *MachineScope) ValidateKeyVaultIdentity() error {
+	if m.machineKeyVault() == nil {
+		return nil
+	}
+	if m.AzureMachine.Spec.Identity == "" || m.AzureMachine.Spec.Identity == infrav1.VMIdentityNone {
+		return errors.Errorf("AzureMachine %s must have a SystemAssigned or UserAssigned identity to fetch its secrets from Key Vault %s", m.AzureMachine.Name, m.KeyVault().Name)
+	}
+	return nil
+}
+
+// KeyVaultSecretSpecs returns the specs of the secrets of the machine in the Key Vault of the cluster.
+func (m *MachineScope) KeyVaultSecretSpecs() []azure.ResourceSpecGetter {
+	keyVault := m.machineKeyVault()
+	if keyVault == nil || !keyVault.BootstrapData {
+		return nil
+	}
+
+	spec := &keyvaults.SecretSpec{
+		Name:          keyvaults.BootstrapDataSecretName(m.AzureMachine.Name),
+		KeyVaultName:  keyVault.Name,
+		ResourceGroup: m.ResourceGroup(),
+		ContentType:   keyvaults.BootstrapDataContentType,
+	}
+	if m.cache != nil {
+		spec.Value = m.cache.bootstrapDataSecretValue
+	}
+	return []azure.ResourceSpecGetter{spec}
+}
+
+// machineKeyVault returns the Key Vault of the cluster if the machine stores any of its secrets in it, or nil. Machines
+// running Windows keep getting their secrets in their custom data.
+func (m *MachineScope) machineKeyVault() *infrav1.KeyVaultSpec {
+	return keyVaultForMachine(m.KeyVault(), m.AzureMachine)
+}
+
+// CloudProviderConfig returns the cloud provider config of the virtual machine from its azure json secret, or nil when
+// it is not generated by CAPZ. Machines running Windows are not supported.
+func (m *MachineScope) CloudProviderConfig(ctx context.Context) (*azure.CloudProviderConfig, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.CloudProviderConfig")
+	defer done()
+
+	if m.AzureMachine.Spec.OSDisk.OSType == azure.WindowsOS {
+		return nil, nil
+	}
+
+	params := cloudProviderConfigParams{
+		ownerName:       cloudProviderConfigOwnerName(m.AzureMachine),
+		dataKey:         "worker-node-azure.json",
+		lastAppliedHash: m.AzureMachine.Annotations[infrav1.CloudProviderConfigLastAppliedAnnotation],
+	}
+	if m.IsControlPlane() {
+		params.dataKey = "control-plane-azure.json"
+	}
+	if keyVault := m.machineKeyVault(); keyVault != nil && keyVault.CloudProviderCredentials {
+		params.fetchScript = func() (string, error) {
+			return keyvaults.CloudProviderConfigScript(m.customDataOptions(keyVault))
+		}
+	}
+	return getCloudProviderConfig(ctx, m.client, m.Namespace(), m.ClusterName(), params)
+}
+
 // TagsSpecs returns the tags for the AzureMachine.
 func (m *MachineScope) TagsSpecs() []azure.TagsSpec {
 	return []azure.TagsSpec{
@@ -218,6 +312,16 @@ func (m *MachineScope) InboundNatSpecs(portsInUse map[int32]struct{}) []azure.Re
 
 // NICSpecs returns the network interface specs.
 func (m *MachineScope) NICSpecs() []azure.ResourceSpecGetter {
+	nics := m.NetworkInterfaces()
+	specs := make([]azure.ResourceSpecGetter, len(nics))
+	for i, nic := range nics {
+		specs[i] = m.nicSpec(nic, i)
+	}
+	return specs
+}
+
+// nicSpec returns the spec of the network interface at the given index.
+func (m *MachineScope) nicSpec(nic infrav1.NetworkInterface, index int) *networkinterfaces.NICSpec {
 	spec := &networkinterfaces.NICSpec{
 		Name:                  azure.GenerateNICName(m.Name()),
 		ResourceGroup:         m.ResourceGroup(),
@@ -226,12 +330,38 @@ func (m *MachineScope) NICSpecs() []azure.ResourceSpecGetter {
 		MachineName:           m.Name(),
 		VNetName:              m.Vnet().Name,
 		VNetResourceGroup:     m.Vnet().ResourceGroup,
-		SubnetName:            m.AzureMachine.Spec.SubnetName,
-		AcceleratedNetworking: m.AzureMachine.Spec.AcceleratedNetworking,
-		IPv6Enabled:           m.IsIPv6Enabled(),
-		EnableIPForwarding:    m.AzureMachine.Spec.EnableIPForwarding,
+		VNetSubscriptionID:    m.Vnet().SubscriptionID,
+		SubnetName:            nic.SubnetName,
+		AcceleratedNetworking: nic.AcceleratedNetworking,
+		EnableIPForwarding:    nic.EnableIPForwarding,
+	}
+
+	if nic.Primary && spec.SubnetName == "" {
+		spec.SubnetName = m.AzureMachine.Spec.SubnetName
+	}
+
+	if nic.PrivateIPAllocationMethod == infrav1.PrivateIPAllocationMethodStatic {
+		spec.StaticIPAddress = nic.PrivateIPAddress
+		spec.PrivateIPAddressRange = nic.PrivateIPAddressRange
+		for _, subnet := range m.Subnets() {
+			if subnet.Name == spec.SubnetName {
+				spec.SubnetCIDRs = subnet.CIDRBlocks
+			}
+		}
+	}
+
+	if m.cache != nil {
+		spec.SKU = &m.cache.VMSKU
+	}
+
+	// Secondary network interfaces are not associated with load balancers or public IPs.
+	if !nic.Primary {
+		spec.Name = azure.WithIndex(spec.Name, index)
+		return spec
 	}
 
+	spec.IPv6Enabled = m.IsIPv6Enabled()
+
 	if m.Role() == infrav1.ControlPlane {
 		spec.PublicLBName = m.OutboundLBName(m.Role())
 		spec.PublicLBAddressPoolName = m.OutboundPoolName(m.OutboundLBName(m.Role()))
@@ -254,11 +384,24 @@ func (m *MachineScope) NICSpecs() []azure.ResourceSpecGetter {
 		spec.PublicIPName = azure.GenerateNodePublicIPName(m.Name())
 	}
 
-	if m.cache != nil {
-		spec.SKU = &m.cache.VMSKU
+	return spec
+}
+
+// SetNICPrivateIPAddress records the private IP address allocated to the network interface with the given name.
+func (m *MachineScope) SetNICPrivateIPAddress(nicName string, privateIPAddress string) {
+	for i, spec := range m.NICSpecs() {
+		if spec.ResourceName() == nicName && i < len(m.AzureMachine.Spec.NetworkInterfaces) {
+			m.AzureMachine.Spec.NetworkInterfaces[i].PrivateIPAddress = privateIPAddress
+		}
 	}
+}
 
-	return []azure.ResourceSpecGetter{spec}
+// NetworkInterfaces returns the network interfaces of the AzureMachine. Machines that do not specify any network
+// interface get a single primary network interface defaulted from their network settings.
+func (m *MachineScope) NetworkInterfaces() []infrav1.NetworkInterface {
+	spec := m.AzureMachine.Spec.DeepCopy()
+	spec.SetNetworkInterfacesDefaults()
+	return spec.NetworkInterfaces
 }
 
 // NICIDs returns the NIC resource IDs.
@@ -271,6 +414,34 @@ func (m *MachineScope) NICIDs() []string {
 	return nicIDs
 }
 
+// acceleratedNetworking returns whether accelerated networking is enabled on a network interface of the machine, or
+// nil when it is left to the capability of the VM size for some of them.
+func (m *MachineScope) acceleratedNetworking() *bool {
+	var unset bool
+	for _, nic := range m.NetworkInterfaces() {
+		switch {
+		case to.Bool(nic.AcceleratedNetworking):
+			return to.BoolPtr(true)
+		case nic.AcceleratedNetworking == nil:
+			unset = true
+		}
+	}
+	if unset {
+		return nil
+	}
+	return to.BoolPtr(false)
+}
+
+// PrimaryNICID returns the resource ID of the primary NIC.
+func (m *MachineScope) PrimaryNICID() string {
+	for i, nic := range m.NetworkInterfaces() {
+		if nic.Primary {
+			return m.NICIDs()[i]
+		}
+	}
+	return ""
+}
+
 // DiskSpecs returns the disk specs.
 func (m *MachineScope) DiskSpecs() []azure.ResourceSpecGetter {
 	diskSpecs := make([]azure.ResourceSpecGetter, 1+len(m.AzureMachine.Spec.DataDisks))
@@ -288,20 +459,47 @@ func (m *MachineScope) DiskSpecs() []azure.ResourceSpecGetter {
 	return diskSpecs
 }
 
+// SecondaryAuthorizer returns the Azure client Authorizer for the resources of the cluster in other subscriptions, e.g.
+// its virtual network.
+func (m *MachineScope) SecondaryAuthorizer() autorest.Authorizer {
+	if auth, ok := m.ClusterScoper.(azure.CrossSubscriptionAuthorizer); ok {
+		return auth.SecondaryAuthorizer()
+	}
+	return m.Authorizer()
+}
+
 // RoleAssignmentSpecs returns the role assignment specs.
 func (m *MachineScope) RoleAssignmentSpecs() []azure.RoleAssignmentSpec {
+	specs := []azure.RoleAssignmentSpec{}
 	if m.AzureMachine.Spec.Identity == infrav1.VMIdentitySystemAssigned {
-		return []azure.RoleAssignmentSpec{
-			{
-				MachineName:  m.Name(),
-				Name:         m.AzureMachine.Spec.RoleAssignmentName,
-				ResourceType: azure.VirtualMachine,
-			},
-		}
+		specs = append(specs, azure.RoleAssignmentSpec{
+			MachineName:  m.Name(),
+			Name:         m.AzureMachine.Spec.RoleAssignmentName,
+			ResourceType: azure.VirtualMachine,
+		})
 	}
-	return []azure.RoleAssignmentSpec{}
+	roleAssignments := append([]infrav1.RoleAssignment{}, m.AzureMachine.Spec.RoleAssignments...)
+	roleAssignments = append(roleAssignments, keyVaultRoleAssignments(m.KeyVault(), m.KeyVaultID(), m.AzureMachine, m.IsControlPlane())...)
+	if len(roleAssignments) > 0 {
+		specs = append(specs, declaredRoleAssignmentSpecs(m.SubscriptionID(), m.ResourceGroup(), m.Name(), azure.VirtualMachine, roleAssignments)...)
+	}
+	return specs
+}
+
+// IsRoleAssignmentShared returns true if the role assignment is also declared by another machine of the cluster.
+func (m *MachineScope) IsRoleAssignmentShared(ctx context.Context, spec azure.RoleAssignmentSpec) (bool, error) {
+	return isRoleAssignmentShared(ctx, m.client, m.AzureMachine, m.ClusterName(), m.SubscriptionID(), m.ResourceGroup(), m.KeyVault(), m.KeyVaultID(), spec)
 }
 
+// RoleAssignmentIDs returns nil as the role assignments of an AzureMachine cannot be changed, and are thus all
+// declared by its spec.
+func (m *MachineScope) RoleAssignmentIDs() []string {
+	return nil
+}
+
+// SetRoleAssignmentIDs does nothing as the role assignments of an AzureMachine cannot be changed.
+func (m *MachineScope) SetRoleAssignmentIDs(_ []string) {}
+
 // VMExtensionSpecs returns the vm extension specs.
 func (m *MachineScope) VMExtensionSpecs() []azure.ExtensionSpec {
 	var extensionSpecs = []azure.ExtensionSpec{}
@@ -546,6 +744,113 @@ func (m *MachineScope) SetAddresses(addrs []corev1.NodeAddress) {
 	m.AzureMachine.Status.Addresses = addrs
 }
 
+// InPlaceResizeAllowed returns true if the AzureMachine allows its virtual machine to be resized in place.
+func (m *MachineScope) InPlaceResizeAllowed() bool {
+	return m.AzureMachine.Annotations[infrav1.AllowInPlaceResizeAnnotation] == "true"
+}
+
+// CordonAndDrain cordons and drains the Kubernetes node of the machine, unless the Machine excludes it from draining.
+func (m *MachineScope) CordonAndDrain(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.CordonAndDrain")
+	defer done()
+
+	node, drainer, err := m.nodeDrainer(ctx)
+	if err != nil || node == nil {
+		return err
+	}
+
+	if err := kubedrain.RunCordonOrUncordon(drainer, node, true); err != nil {
+		return azure.WithTransientError(errors.Errorf("unable to cordon node %s: %v", node.Name, err), 20*time.Second)
+	}
+
+	if _, exists := m.Machine.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; exists {
+		return nil
+	}
+
+	if err := kubedrain.RunNodeDrain(drainer, node.Name); err != nil {
+		return azure.WithTransientError(errors.Wrap(err, "Drain failed, retry in 20s"), 20*time.Second)
+	}
+
+	log.V(4).Info("Drain successful", "node", node.Name)
+	return nil
+}
+
+// Uncordon marks the Kubernetes node of the machine as schedulable again.
+func (m *MachineScope) Uncordon(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.Uncordon")
+	defer done()
+
+	node, drainer, err := m.nodeDrainer(ctx)
+	if err != nil || node == nil {
+		return err
+	}
+
+	if err := kubedrain.RunCordonOrUncordon(drainer, node, false); err != nil {
+		return azure.WithTransientError(errors.Errorf("unable to uncordon node %s: %v", node.Name, err), 20*time.Second)
+	}
+	return nil
+}
+
+// RequestRemediation marks the Machine as unhealthy and waiting for its owner to remediate it, the same way a
+// MachineHealthCheck does, so that its MachineSet or control plane replaces it.
+func (m *MachineScope) RequestRemediation(ctx context.Context, message string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.RequestRemediation")
+	defer done()
+
+	if conditions.IsFalse(m.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+		return nil
+	}
+
+	helper, err := patch.NewHelper(m.Machine, m.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to init patch helper")
+	}
+	log.V(2).Info("requesting remediation of machine", "machine", m.Machine.Name)
+	conditions.MarkFalse(m.Machine, clusterv1.MachineHealthCheckSucceededCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, message)
+	conditions.MarkFalse(m.Machine, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+	if err := helper.Patch(ctx, m.Machine, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+		clusterv1.MachineHealthCheckSucceededCondition,
+		clusterv1.MachineOwnerRemediatedCondition,
+	}}); err != nil {
+		return errors.Wrapf(err, "failed to request remediation of machine %s", m.Machine.Name)
+	}
+	return nil
+}
+
+// nodeDrainer returns the Kubernetes node of the machine, if any, and a helper to cordon and drain it.
+func (m *MachineScope) nodeDrainer(ctx context.Context) (*corev1.Node, *kubedrain.Helper, error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.nodeDrainer")
+	defer done()
+
+	nodeRef := m.Machine.Status.NodeRef
+	if nodeRef == nil || nodeRef.Name == "" {
+		return nil, nil, nil
+	}
+
+	restConfig, err := remote.RESTConfig(ctx, MachineScopeName, m.client, client.ObjectKey{
+		Name:      m.ClusterName(),
+		Namespace: m.AzureMachine.Namespace,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create a REST config for the workload cluster")
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create a client for the workload cluster")
+	}
+
+	node, err := kubeClient.CoreV1().Nodes().Get(ctx, nodeRef.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, nil, nil
+	case err != nil:
+		return nil, nil, errors.Wrapf(err, "failed to get node %s", nodeRef.Name)
+	}
+
+	return node, newDrainer(ctx, log, kubeClient), nil
+}
+
 // PatchObject persists the machine spec and status.
 func (m *MachineScope) PatchObject(ctx context.Context) error {
 	conditions.SetSummary(m.AzureMachine,
@@ -564,6 +869,8 @@ func (m *MachineScope) PatchObject(ctx context.Context) error {
 			infrav1.VMRunningCondition,
 			infrav1.AvailabilitySetReadyCondition,
 			infrav1.NetworkInterfaceReadyCondition,
+			infrav1.VMResizedCondition,
+			infrav1.CloudProviderConfigUpdatedCondition,
 		}})
 }
 
@@ -586,25 +893,62 @@ func (m *MachineScope) AdditionalTags() infrav1.Tags {
 	return tags
 }
 
-// GetBootstrapData returns the bootstrap data from the secret in the Machine's bootstrap.dataSecretName.
+// GetBootstrapData returns the custom data of the virtual machine: the bootstrap data from the secret in the Machine's
+// bootstrap.dataSecretName or, when the machine stores its secrets in the Key Vault of the cluster, the user data
+// fetching them.
 func (m *MachineScope) GetBootstrapData(ctx context.Context) (string, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachineScope.GetBootstrapData")
 	defer done()
 
+	value, err := m.getBootstrapDataValue(ctx)
+	if err != nil {
+		return "", err
+	}
+	return m.customData(value)
+}
+
+// getBootstrapDataValue returns the bootstrap data from the secret in the Machine's bootstrap.dataSecretName.
+func (m *MachineScope) getBootstrapDataValue(ctx context.Context) ([]byte, error) {
 	if m.Machine.Spec.Bootstrap.DataSecretName == nil {
-		return "", errors.New("error retrieving bootstrap data: linked Machine's bootstrap.dataSecretName is nil")
+		return nil, errors.New("error retrieving bootstrap data: linked Machine's bootstrap.dataSecretName is nil")
 	}
 	secret := &corev1.Secret{}
 	key := types.NamespacedName{Namespace: m.Namespace(), Name: *m.Machine.Spec.Bootstrap.DataSecretName}
 	if err := m.client.Get(ctx, key, secret); err != nil {
-		return "", errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachine %s/%s", m.Namespace(), m.Name())
+		return nil, errors.Wrapf(err, "failed to retrieve bootstrap data secret for AzureMachine %s/%s", m.Namespace(), m.Name())
 	}
 
 	value, ok := secret.Data["value"]
 	if !ok {
-		return "", errors.New("error retrieving bootstrap data: secret value key is missing")
+		return nil, errors.New("error retrieving bootstrap data: secret value key is missing")
+	}
+	return value, nil
+}
+
+// customData returns the base64 encoded custom data of the virtual machine for the given bootstrap data.
+func (m *MachineScope) customData(bootstrapData []byte) (string, error) {
+	keyVault := m.machineKeyVault()
+	if keyVault == nil {
+		return base64.StdEncoding.EncodeToString(bootstrapData), nil
+	}
+	return keyvaults.CustomData(bootstrapData, m.customDataOptions(keyVault))
+}
+
+// customDataOptions returns the options of the custom data of a virtual machine fetching its secrets from the Key Vault.
+func (m *MachineScope) customDataOptions(keyVault *infrav1.KeyVaultSpec) keyvaults.CustomDataOptions {
+	options := keyvaults.CustomDataOptions{
+		KeyVaultURL: m.KeyVaultURL(),
+	}
+	if keyVault.BootstrapData {
+		options.BootstrapDataSecretName = keyvaults.BootstrapDataSecretName(m.AzureMachine.Name)
+	}
+	if keyVault.CloudProviderCredentials {
+		options.CloudProviderConfigSecretName = keyvaults.CloudProviderConfigSecretName(cloudProviderConfigOwnerName(m.AzureMachine), m.IsControlPlane())
+	}
+	if m.AzureMachine.Spec.Identity == infrav1.VMIdentityUserAssigned && len(m.AzureMachine.Spec.UserAssignedIdentities) > 0 {
+		options.UserAssignedIdentityID = strings.TrimPrefix(m.AzureMachine.Spec.UserAssignedIdentities[0].ProviderID, azure.ProviderIDPrefix)
 	}
-	return base64.StdEncoding.EncodeToString(value), nil
+	return options
 }
 
 // GetVMImage returns the image from the machine configuration, or a default one.
@@ -627,10 +971,18 @@ func (m *MachineScope) GetVMImage(ctx context.Context) (*infrav1.Image, error) {
 	return azure.GetDefaultUbuntuImage(to.String(m.Machine.Spec.Version))
 }
 
-// SetSubnetName defaults the AzureMachine subnet name to the name of one the subnets with the machine role when there is only one of them.
-// Note: this logic exists only for purposes of ensuring backwards compatibility for old clusters created without the `subnetName` field being
-// set, and should be removed in the future when this field is no longer optional.
+// SetSubnetName defaults the AzureMachine subnet name to the subnet of its primary network interface or, when that is not set,
+// to the name of one the subnets with the machine role when there is only one of them. The subnet of the primary network
+// interface is then defaulted to the AzureMachine subnet name.
+// Note: the role based logic exists only for purposes of ensuring backwards compatibility for old clusters created without
+// the `subnetName` field being set, and should be removed in the future when this field is no longer optional.
 func (m *MachineScope) SetSubnetName() error {
+	for _, nic := range m.AzureMachine.Spec.NetworkInterfaces {
+		if nic.Primary && m.AzureMachine.Spec.SubnetName == "" {
+			m.AzureMachine.Spec.SubnetName = nic.SubnetName
+		}
+	}
+
 	if m.AzureMachine.Spec.SubnetName == "" {
 		subnetName := ""
 		subnets := m.Subnets()
@@ -648,6 +1000,12 @@ func (m *MachineScope) SetSubnetName() error {
 		m.AzureMachine.Spec.SubnetName = subnetName
 	}
 
+	for i, nic := range m.AzureMachine.Spec.NetworkInterfaces {
+		if nic.Primary && nic.SubnetName == "" {
+			m.AzureMachine.Spec.NetworkInterfaces[i].SubnetName = m.AzureMachine.Spec.SubnetName
+		}
+	}
+
 	return nil
 }
 
@@ -695,6 +1053,16 @@ func (m *MachineScope) UpdatePutStatus(condition clusterv1.ConditionType, servic
 	}
 }
 
+// SetConditionFalse sets the specified condition to false on the AzureMachine.
+func (m *MachineScope) SetConditionFalse(condition clusterv1.ConditionType, reason string, severity clusterv1.ConditionSeverity, message string) {
+	conditions.MarkFalse(m.AzureMachine, condition, reason, severity, "%s", message)
+}
+
+// IsConditionFalse returns true if the specified condition of the AzureMachine is false.
+func (m *MachineScope) IsConditionFalse(condition clusterv1.ConditionType) bool {
+	return conditions.IsFalse(m.AzureMachine, condition)
+}
+
 // UpdatePatchStatus updates a condition on the AzureMachine status after a PATCH operation.
 func (m *MachineScope) UpdatePatchStatus(condition clusterv1.ConditionType, service string, err error) {
 	switch {
@@ -28,8 +28,12 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -37,6 +41,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines"
 )
 
 func specArrayToString(specs []azure.ResourceSpecGetter) string {
@@ -385,6 +390,9 @@ func TestMachineScope_RoleAssignmentSpecs(t *testing.T) {
 		{
 			name: "returns empty if VM identity is system assigned",
 			machineScope: MachineScope{
+				ClusterScoper: &ClusterScope{
+					AzureCluster: &infrav1.AzureCluster{},
+				},
 				Machine: &clusterv1.Machine{},
 				AzureMachine: &infrav1.AzureMachine{
 					ObjectMeta: metav1.ObjectMeta{
@@ -397,6 +405,9 @@ func TestMachineScope_RoleAssignmentSpecs(t *testing.T) {
 		{
 			name: "returns RoleAssignmentSpec if VM identity is not system assigned",
 			machineScope: MachineScope{
+				ClusterScoper: &ClusterScope{
+					AzureCluster: &infrav1.AzureCluster{},
+				},
 				Machine: &clusterv1.Machine{},
 				AzureMachine: &infrav1.AzureMachine{
 					ObjectMeta: metav1.ObjectMeta{
@@ -426,6 +437,72 @@ func TestMachineScope_RoleAssignmentSpecs(t *testing.T) {
 	}
 }
 
+func TestMachineScope_KeyVaultRoleAssignmentSpecs(t *testing.T) {
+	g := NewWithT(t)
+
+	clusterScope := &ClusterScope{
+		AzureClients: AzureClients{
+			EnvironmentSettings: auth.EnvironmentSettings{
+				Values: map[string]string{
+					auth.SubscriptionID: "123",
+				},
+			},
+		},
+		Cluster: &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "my-cluster",
+			},
+		},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				KeyVault: &infrav1.KeyVaultSpec{
+					Name:                     "kv-test",
+					BootstrapData:            true,
+					CloudProviderCredentials: true,
+				},
+			},
+		},
+	}
+	machineScope := MachineScope{
+		ClusterScoper: clusterScope,
+		keyVaultScope: keyVaultScope{describer: clusterScope},
+		Machine:       &clusterv1.Machine{},
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "machine-name",
+				Annotations: map[string]string{
+					clusterv1.TemplateClonedFromGroupKindAnnotation: infrav1.GroupVersion.WithKind("AzureMachineTemplate").GroupKind().String(),
+					clusterv1.TemplateClonedFromNameAnnotation:      "template-name",
+				},
+			},
+		},
+	}
+
+	specs := machineScope.RoleAssignmentSpecs()
+	g.Expect(specs).To(HaveLen(2))
+	keyVaultID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.KeyVault/vaults/kv-test"
+	g.Expect(specs[0].Scope).To(Equal(keyVaultID + "/secrets/machine-name-bootstrap-data"))
+	g.Expect(specs[0].RoleDefinitionID).To(Equal(keyVaultSecretsUserRoleID))
+	g.Expect(specs[0].UserAssignedIdentityID).To(BeEmpty())
+	g.Expect(specs[1].Scope).To(Equal(keyVaultID + "/secrets/template-name-worker-node-azure-json"))
+
+	// the virtual machine needs a managed identity to fetch its secrets
+	g.Expect(machineScope.ValidateKeyVaultIdentity()).To(MatchError("AzureMachine machine-name must have a SystemAssigned or UserAssigned identity to fetch its secrets from Key Vault kv-test"))
+	machineScope.AzureMachine.Spec.Identity = infrav1.VMIdentitySystemAssigned
+	g.Expect(machineScope.ValidateKeyVaultIdentity()).To(Succeed())
+	vmSpec, ok := machineScope.VMSpec().(*virtualmachines.VMSpec)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(vmSpec.Identity).To(Equal(infrav1.VMIdentitySystemAssigned))
+
+	// machines running Windows keep getting their secrets in their custom data
+	machineScope.AzureMachine.Spec.OSDisk.OSType = azure.WindowsOS
+	machineScope.AzureMachine.Spec.Identity = infrav1.VMIdentityNone
+	g.Expect(machineScope.ValidateKeyVaultIdentity()).To(Succeed())
+	g.Expect(machineScope.RoleAssignmentSpecs()).To(BeEmpty())
+	g.Expect(machineScope.KeyVaultSecretSpecs()).To(BeEmpty())
+}
+
 func TestMachineScope_VMExtensionSpecs(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -862,6 +939,48 @@ func TestMachineScope_IsControlPlane(t *testing.T) {
 	}
 }
 
+func TestMachineScope_InPlaceResizeAllowed(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{
+			name: "returns false when annotation is not set",
+			want: false,
+		},
+		{
+			name: "returns false when annotation is not true",
+			annotations: map[string]string{
+				infrav1.AllowInPlaceResizeAnnotation: "false",
+			},
+			want: false,
+		},
+		{
+			name: "returns true when annotation is true",
+			annotations: map[string]string{
+				infrav1.AllowInPlaceResizeAnnotation: "true",
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			machineScope := MachineScope{
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "machine-name",
+						Annotations: tt.annotations,
+					},
+				},
+			}
+			if got := machineScope.InPlaceResizeAllowed(); got != tt.want {
+				t.Errorf("InPlaceResizeAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMachineScope_Role(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1904,6 +2023,130 @@ func TestMachineScope_NICSpecs(t *testing.T) {
 				},
 			},
 		},
+
+		{
+			name: "Node Machine with multiple network interfaces",
+			machineScope: MachineScope{
+				ClusterScoper: &ClusterScope{
+					AzureClients: AzureClients{
+						EnvironmentSettings: auth.EnvironmentSettings{
+							Values: map[string]string{
+								auth.SubscriptionID: "123",
+							},
+						},
+					},
+					Cluster: &clusterv1.Cluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "cluster",
+							Namespace: "default",
+						},
+					},
+					AzureCluster: &infrav1.AzureCluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "cluster",
+							Namespace: "default",
+							OwnerReferences: []metav1.OwnerReference{
+								{
+									APIVersion: "cluster.x-k8s.io/v1beta1",
+									Kind:       "Cluster",
+									Name:       "cluster",
+								},
+							},
+						},
+						Spec: infrav1.AzureClusterSpec{
+							ResourceGroup: "my-rg",
+							Location:      "westus",
+							NetworkSpec: infrav1.NetworkSpec{
+								Vnet: infrav1.VnetSpec{
+									Name:          "vnet1",
+									ResourceGroup: "rg1",
+								},
+								Subnets: []infrav1.SubnetSpec{
+									{
+										Role:       infrav1.SubnetNode,
+										Name:       "subnet1",
+										CIDRBlocks: []string{"10.0.0.0/16"},
+									},
+								},
+								NodeOutboundLB: &infrav1.LoadBalancerSpec{
+									Name: "outbound-lb",
+								},
+							},
+						},
+					},
+				},
+				AzureMachine: &infrav1.AzureMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machine",
+					},
+					Spec: infrav1.AzureMachineSpec{
+						ProviderID: to.StringPtr("azure://compute/virtual-machines/machine-name"),
+						SubnetName: "subnet1",
+						NetworkInterfaces: []infrav1.NetworkInterface{
+							{
+								SubnetName:                "subnet1",
+								PrivateIPAllocationMethod: infrav1.PrivateIPAllocationMethodStatic,
+								PrivateIPAddressRange:     "10.0.0.16/28",
+								Primary:                   true,
+							},
+							{
+								SubnetName:                "storage-subnet",
+								PrivateIPAllocationMethod: infrav1.PrivateIPAllocationMethodStatic,
+								PrivateIPAddress:          "10.1.0.4",
+								AcceleratedNetworking:     to.BoolPtr(true),
+							},
+						},
+					},
+				},
+				Machine: &clusterv1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "machine",
+						Labels: map[string]string{
+							//clusterv1.MachineControlPlaneLabelName: "true",
+						},
+					},
+				},
+			},
+			want: []azure.ResourceSpecGetter{
+				&networkinterfaces.NICSpec{
+					Name:                      "machine-name-nic",
+					ResourceGroup:             "my-rg",
+					Location:                  "westus",
+					SubscriptionID:            "123",
+					MachineName:               "machine-name",
+					SubnetName:                "subnet1",
+					VNetName:                  "vnet1",
+					VNetResourceGroup:         "rg1",
+					PrivateIPAddressRange:     "10.0.0.16/28",
+					SubnetCIDRs:               []string{"10.0.0.0/16"},
+					PublicLBName:              "outbound-lb",
+					PublicLBAddressPoolName:   "outbound-lb-outboundBackendPool",
+					PublicLBNATRuleName:       "",
+					InternalLBName:            "",
+					InternalLBAddressPoolName: "",
+					PublicIPName:              "",
+					AcceleratedNetworking:     nil,
+					IPv6Enabled:               false,
+					EnableIPForwarding:        false,
+					SKU:                       nil,
+				},
+				&networkinterfaces.NICSpec{
+					Name:                  "machine-name-nic-1",
+					ResourceGroup:         "my-rg",
+					Location:              "westus",
+					SubscriptionID:        "123",
+					MachineName:           "machine-name",
+					SubnetName:            "storage-subnet",
+					VNetName:              "vnet1",
+					VNetResourceGroup:     "rg1",
+					StaticIPAddress:       "10.1.0.4",
+					AcceleratedNetworking: to.BoolPtr(true),
+					IPv6Enabled:           false,
+					EnableIPForwarding:    false,
+					SKU:                   nil,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1915,6 +2158,59 @@ func TestMachineScope_NICSpecs(t *testing.T) {
 	}
 }
 
+func TestMachineScope_SetNICPrivateIPAddress(t *testing.T) {
+	g := NewWithT(t)
+
+	machineScope := MachineScope{
+		ClusterScoper: &ClusterScope{
+			Cluster: &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster",
+				},
+			},
+			AzureCluster: &infrav1.AzureCluster{
+				Spec: infrav1.AzureClusterSpec{
+					ResourceGroup: "my-rg",
+					NetworkSpec: infrav1.NetworkSpec{
+						Subnets: []infrav1.SubnetSpec{
+							{
+								Role:       infrav1.SubnetNode,
+								Name:       "subnet1",
+								NatGateway: infrav1.NatGateway{Name: "nat-gw"},
+							},
+						},
+					},
+				},
+			},
+		},
+		AzureMachine: &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "machine",
+			},
+			Spec: infrav1.AzureMachineSpec{
+				ProviderID: to.StringPtr("azure://compute/virtual-machines/machine-name"),
+				SubnetName: "subnet1",
+				NetworkInterfaces: []infrav1.NetworkInterface{
+					{
+						SubnetName: "subnet1",
+						Primary:    true,
+					},
+					{
+						SubnetName:                "storage-subnet",
+						PrivateIPAllocationMethod: infrav1.PrivateIPAllocationMethodStatic,
+						PrivateIPAddressRange:     "10.1.0.16/28",
+					},
+				},
+			},
+		},
+		Machine: &clusterv1.Machine{},
+	}
+
+	machineScope.SetNICPrivateIPAddress("machine-name-nic-1", "10.1.0.20")
+	g.Expect(machineScope.AzureMachine.Spec.NetworkInterfaces[0].PrivateIPAddress).To(BeEmpty())
+	g.Expect(machineScope.AzureMachine.Spec.NetworkInterfaces[1].PrivateIPAddress).To(Equal("10.1.0.20"))
+}
+
 func TestDiskSpecs(t *testing.T) {
 	testcases := []struct {
 		name         string
@@ -2084,3 +2380,34 @@ func TestDiskSpecs(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineScope_RequestRemediation(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine1",
+			Namespace: "default",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+	machineScope := &MachineScope{
+		client:       fakeClient,
+		Machine:      machine,
+		AzureMachine: &infrav1.AzureMachine{},
+	}
+
+	g.Expect(machineScope.RequestRemediation(context.TODO(), "spot VM was deallocated by an eviction")).To(Succeed())
+	remediated := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "machine1"}, remediated)).To(Succeed())
+	g.Expect(conditions.IsFalse(remediated, clusterv1.MachineHealthCheckSucceededCondition)).To(BeTrue())
+	g.Expect(conditions.GetMessage(remediated, clusterv1.MachineHealthCheckSucceededCondition)).To(Equal("spot VM was deallocated by an eviction"))
+	g.Expect(conditions.GetReason(remediated, clusterv1.MachineOwnerRemediatedCondition)).To(Equal(clusterv1.WaitingForRemediationReason))
+
+	// a machine already waiting for remediation is left as is
+	g.Expect(machineScope.RequestRemediation(context.TODO(), "another message")).To(Succeed())
+	g.Expect(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "machine1"}, remediated)).To(Succeed())
+	g.Expect(conditions.GetMessage(remediated, clusterv1.MachineHealthCheckSucceededCondition)).To(Equal("spot VM was deallocated by an eviction"))
+}
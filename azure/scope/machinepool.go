@@ -19,16 +19,22 @@ package scope
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
 	"strings"
 	"time"
 
 	"sigs.k8s.io/cluster-api-provider-azure/util/futures"
 
 	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
@@ -36,12 +42,15 @@ import (
 	capiv1exp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	machinepool "sigs.k8s.io/cluster-api-provider-azure/azure/scope/strategies/machinepool_deployments"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
@@ -51,6 +60,10 @@ import (
 // added here to avoid a circular dependency.
 const ScalesetsServiceName = "scalesets"
 
+// spotFallbackDuration is how long the Spot replicas of a machine pool run as on-demand instances after Azure could
+// not allocate Spot capacity, before Spot capacity is tried again.
+const spotFallbackDuration = 30 * time.Minute
+
 type (
 	// MachinePoolScopeParams defines the input parameters used to create a new MachinePoolScope.
 	MachinePoolScopeParams struct {
@@ -58,16 +71,21 @@ type (
 		MachinePool      *capiv1exp.MachinePool
 		AzureMachinePool *infrav1exp.AzureMachinePool
 		ClusterScope     azure.ClusterScoper
+		// KeyVaultScope describes the Key Vault of the cluster, if any.
+		KeyVaultScope azure.KeyVaultDescriber
 	}
 
 	// MachinePoolScope defines a scope defined around a machine pool and its cluster.
 	MachinePoolScope struct {
 		azure.ClusterScoper
-		AzureMachinePool *infrav1exp.AzureMachinePool
-		MachinePool      *capiv1exp.MachinePool
-		client           client.Client
-		patchHelper      *patch.Helper
-		vmssState        *azure.VMSS
+		keyVaultScope
+		AzureMachinePool   *infrav1exp.AzureMachinePool
+		MachinePool        *capiv1exp.MachinePool
+		client             client.Client
+		patchHelper        *patch.Helper
+		vmssState          *azure.VMSS
+		onDemandState      *azure.VMSS
+		fellBackToOnDemand bool
 	}
 
 	// NodeStatus represents the status of a Kubernetes node.
@@ -103,33 +121,136 @@ func NewMachinePoolScope(params MachinePoolScopeParams) (*MachinePoolScope, erro
 		AzureMachinePool: params.AzureMachinePool,
 		patchHelper:      helper,
 		ClusterScoper:    params.ClusterScope,
+		keyVaultScope:    keyVaultScope{describer: params.KeyVaultScope},
 	}, nil
 }
 
 // ScaleSetSpec returns the scale set spec.
 func (m *MachinePoolScope) ScaleSetSpec() azure.ScaleSetSpec {
+	spotReplicas, _ := m.spotAndOnDemandReplicas()
 	return azure.ScaleSetSpec{
 		Name:                         m.Name(),
 		Size:                         m.AzureMachinePool.Spec.Template.VMSize,
-		Capacity:                     int64(to.Int32(m.MachinePool.Spec.Replicas)),
+		Capacity:                     int64(spotReplicas),
 		SSHKeyData:                   m.AzureMachinePool.Spec.Template.SSHPublicKey,
 		OSDisk:                       m.AzureMachinePool.Spec.Template.OSDisk,
 		DataDisks:                    m.AzureMachinePool.Spec.Template.DataDisks,
 		SubnetName:                   m.AzureMachinePool.Spec.Template.SubnetName,
 		VNetName:                     m.Vnet().Name,
 		VNetResourceGroup:            m.Vnet().ResourceGroup,
+		VNetSubscriptionID:           m.Vnet().SubscriptionID,
 		PublicLBName:                 m.OutboundLBName(infrav1.Node),
 		PublicLBAddressPoolName:      azure.GenerateOutboundBackendAddressPoolName(m.OutboundLBName(infrav1.Node)),
 		AcceleratedNetworking:        m.AzureMachinePool.Spec.Template.AcceleratedNetworking,
 		Identity:                     m.AzureMachinePool.Spec.Identity,
 		UserAssignedIdentities:       m.AzureMachinePool.Spec.UserAssignedIdentities,
 		SecurityProfile:              m.AzureMachinePool.Spec.Template.SecurityProfile,
+		Diagnostics:                  m.AzureMachinePool.Spec.Template.Diagnostics,
 		SpotVMOptions:                m.AzureMachinePool.Spec.Template.SpotVMOptions,
 		FailureDomains:               m.MachinePool.Spec.FailureDomains,
 		TerminateNotificationTimeout: m.AzureMachinePool.Spec.Template.TerminateNotificationTimeout,
+		OrchestrationMode:            m.AzureMachinePool.Spec.OrchestrationMode,
+		AutomaticRepairsGracePeriod:  m.automaticRepairsGracePeriod(),
+		ZoneBalance:                  m.AzureMachinePool.Spec.ZoneBalance,
+		PlatformFaultDomainCount:     m.AzureMachinePool.Spec.PlatformFaultDomainCount,
 	}
 }
 
+// automaticRepairsGracePeriod returns the grace period of the automatic repairs policy in the ISO 8601 format
+// expected by Azure, or an empty string if automatic repairs are disabled.
+func (m *MachinePoolScope) automaticRepairsGracePeriod() string {
+	policy := m.AzureMachinePool.Spec.AutomaticRepairsPolicy
+	if policy == nil || policy.GracePeriod == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("PT%dM", int(policy.GracePeriod.Minutes()))
+}
+
+// OnDemandScaleSetSpec returns the spec of the scale set running the on-demand instances of a machine pool mixing
+// on-demand and Spot instances, or nil if the machine pool does not mix them.
+func (m *MachinePoolScope) OnDemandScaleSetSpec() *azure.ScaleSetSpec {
+	if m.AzureMachinePool.Spec.SpotCapacityPolicy == nil {
+		return nil
+	}
+
+	_, onDemandReplicas := m.spotAndOnDemandReplicas()
+	spec := m.ScaleSetSpec()
+	spec.Name = m.onDemandScaleSetName()
+	spec.Capacity = int64(onDemandReplicas)
+	spec.SpotVMOptions = nil
+	return &spec
+}
+
+// spotAndOnDemandReplicas splits the desired replicas between Spot and on-demand instances following the Spot
+// capacity policy. Without a policy, all replicas run in the machine pool's scale set.
+func (m *MachinePoolScope) spotAndOnDemandReplicas() (int32, int32) {
+	replicas := m.DesiredReplicas()
+	policy := m.AzureMachinePool.Spec.SpotCapacityPolicy
+	if policy == nil {
+		return replicas, 0
+	}
+
+	base := to.Int32(policy.BaseOnDemandCount)
+	if m.spotFallbackActive() || replicas <= base {
+		return 0, replicas
+	}
+
+	spotPercentage := int32(100)
+	if policy.SpotPercentageAboveBase != nil {
+		spotPercentage = *policy.SpotPercentageAboveBase
+	}
+
+	// round the Spot replicas down, so that the remainder runs on-demand
+	spotReplicas := (replicas - base) * spotPercentage / 100
+	return spotReplicas, replicas - spotReplicas
+}
+
+// onDemandScaleSetName returns the name of the scale set running the on-demand instances of the machine pool.
+func (m *MachinePoolScope) onDemandScaleSetName() string {
+	// Windows Machine pools names cannot be longer than 9 chars
+	if m.AzureMachinePool.Spec.Template.OSDisk.OSType == azure.WindowsOS {
+		name := m.AzureMachinePool.Name
+		if len(name) > 5 {
+			name = name[len(name)-5:]
+		}
+		return "wod-" + name
+	}
+	return m.AzureMachinePool.Name + "-ondemand"
+}
+
+// spotFallbackActive returns true if the Spot replicas of the machine pool recently fell back to on-demand instances.
+func (m *MachinePoolScope) spotFallbackActive() bool {
+	fallbackTime := m.AzureMachinePool.Status.SpotFallbackTime
+	return fallbackTime != nil && time.Since(fallbackTime.Time) < spotFallbackDuration
+}
+
+// FallBackToOnDemand moves the Spot replicas of the machine pool to on-demand instances because Azure could not
+// allocate Spot capacity.
+func (m *MachinePoolScope) FallBackToOnDemand(err error) {
+	now := metav1.Now()
+	m.AzureMachinePool.Status.SpotFallbackTime = &now
+	m.fellBackToOnDemand = true
+	conditions.MarkFalse(m.AzureMachinePool, infrav1.SpotCapacityCondition, infrav1.SpotCapacityUnavailableReason, clusterv1.ConditionSeverityWarning, "%s", err.Error())
+}
+
+// FellBackToOnDemand returns true if the Spot replicas of the machine pool fell back to on-demand instances during
+// this reconciliation.
+func (m *MachinePoolScope) FellBackToOnDemand() bool {
+	return m.fellBackToOnDemand
+}
+
+// SetSpotCapacityAvailable marks the Spot capacity of the machine pool as available, unless the machine pool is still
+// running its Spot replicas as on-demand instances.
+func (m *MachinePoolScope) SetSpotCapacityAvailable() {
+	if m.spotFallbackActive() {
+		return
+	}
+
+	m.AzureMachinePool.Status.SpotFallbackTime = nil
+	conditions.MarkTrue(m.AzureMachinePool, infrav1.SpotCapacityCondition)
+}
+
 // Name returns the Azure Machine Pool Name.
 func (m *MachinePoolScope) Name() string {
 	// Windows Machine pools names cannot be longer than 9 chars
@@ -166,6 +287,11 @@ func (m *MachinePoolScope) SetVMSSState(vmssState *azure.VMSS) {
 	m.vmssState = vmssState
 }
 
+// SetOnDemandVMSSState updates the machine pool scope with the current state of the on-demand VMSS.
+func (m *MachinePoolScope) SetOnDemandVMSSState(vmssState *azure.VMSS) {
+	m.onDemandState = vmssState
+}
+
 // NeedsRequeue return true if any machines are not on the latest model or the VMSS is not in a terminal provisioning
 // state.
 func (m *MachinePoolScope) NeedsRequeue() bool {
@@ -178,7 +304,15 @@ func (m *MachinePoolScope) NeedsRequeue() bool {
 		return true
 	}
 
-	desiredMatchesActual := len(m.vmssState.Instances) == int(m.DesiredReplicas())
+	instanceCount := len(m.vmssState.Instances)
+	if m.onDemandState != nil {
+		if !m.onDemandState.HasLatestModelAppliedToAll() {
+			return true
+		}
+		instanceCount += len(m.onDemandState.Instances)
+	}
+
+	desiredMatchesActual := instanceCount == int(m.DesiredReplicas())
 	return !(state != nil && infrav1.IsTerminalProvisioningState(*state) && desiredMatchesActual)
 }
 
@@ -195,12 +329,251 @@ func (m MachinePoolScope) MaxSurge() (int, error) {
 			return 0, errors.Wrap(err, "failed to calculate surge for the machine pool")
 		}
 
+		maxReplacements, limited, err := m.maxRolloutReplacements()
+		if err != nil {
+			return 0, err
+		}
+		if limited && maxReplacements < surgeCount {
+			// surged machines run the latest model, so they count towards the canary batch of the rollout
+			return maxReplacements, nil
+		}
+
 		return surgeCount, nil
 	}
 
 	return 0, nil
 }
 
+// canaryReplicas returns the number of machines in the canary batch of a rollout, or 0 if the deployment strategy has
+// no canary batch.
+func (m MachinePoolScope) canaryReplicas() (int, error) {
+	canary := m.AzureMachinePool.Spec.Strategy.Canary
+	if canary == nil {
+		return 0, nil
+	}
+
+	if canary.Replicas == nil {
+		return 1, nil
+	}
+
+	replicas, err := intstr.GetScaledValueFromIntOrPercent(canary.Replicas, int(m.DesiredReplicas()), true)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to calculate the canary replicas for the machine pool")
+	}
+
+	if replicas < 1 {
+		return 1, nil
+	}
+
+	return replicas, nil
+}
+
+// maxRolloutReplacements returns the number of machines the current rollout may still replace with the latest model,
+// and whether the rollout limits the replacements at all. The replacements are limited while the canary batch of the
+// rollout is replaced and while the rollout is paused after its canary batch.
+func (m MachinePoolScope) maxRolloutReplacements() (int, bool, error) {
+	canaryReplicas, err := m.canaryReplicas()
+	if err != nil || canaryReplicas == 0 {
+		return 0, false, err
+	}
+
+	rollout := m.AzureMachinePool.Status.Rollout
+	switch {
+	case rollout == nil || rollout.Phase == infrav1exp.CompletedRolloutPhase || m.rolloutModelChanged(rollout):
+		// a new rollout, or the rollout of a new model pushed before the previous one completed, starts with its
+		// canary batch
+		return canaryReplicas, true, nil
+	case rollout.Phase == infrav1exp.CanaryRolloutPhase:
+		if int(rollout.UpdatedReplicas) >= canaryReplicas {
+			return 0, true, nil
+		}
+		return canaryReplicas - int(rollout.UpdatedReplicas), true, nil
+	case rollout.Phase == infrav1exp.PausedRolloutPhase:
+		return 0, true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// updateRolloutStatus reports the progress of the rollout of the latest model to the machines of the machine pool, and
+// continues a rollout paused after its canary batch once it is approved or the canary batch passes its health gate.
+func (m *MachinePoolScope) updateRolloutStatus(ctx context.Context, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.updateRolloutStatus")
+	defer done()
+
+	var (
+		replicas int
+		updated  []string
+	)
+	for _, state := range []*azure.VMSS{m.vmssState, m.onDemandState} {
+		if state == nil {
+			continue
+		}
+
+		replicas += len(state.Instances)
+		for _, instance := range state.Instances {
+			if state.HasLatestModelApplied(instance) {
+				updated = append(updated, instance.ProviderID())
+			}
+		}
+	}
+
+	canaryReplicas, err := m.canaryReplicas()
+	if err != nil {
+		return err
+	}
+
+	rollout := m.AzureMachinePool.Status.Rollout
+	if len(updated) == replicas {
+		if rollout != nil {
+			if rollout.Phase != infrav1exp.CompletedRolloutPhase {
+				log.Info("completed the rollout of the latest model")
+			}
+			rollout.Phase = infrav1exp.CompletedRolloutPhase
+			rollout.Replicas = int32(replicas)
+			rollout.UpdatedReplicas = int32(len(updated))
+			rollout.CanaryHealthySince = nil
+			rollout.Message = "all machines run the latest model"
+		}
+		delete(m.AzureMachinePool.Annotations, infrav1exp.ApproveRolloutAnnotation)
+		return nil
+	}
+
+	now := metav1.Now()
+	modelHash := m.modelHash()
+	if rollout != nil && m.rolloutModelChanged(rollout) {
+		// the canary batch and any approval of the rollout were meant for the previous model
+		log.Info("restarting the rollout since the model changed before the rollout completed", "phase", rollout.Phase)
+		delete(m.AzureMachinePool.Annotations, infrav1exp.ApproveRolloutAnnotation)
+		rollout = nil
+	}
+	if rollout == nil || rollout.Phase == infrav1exp.CompletedRolloutPhase {
+		rollout = &infrav1exp.AzureMachinePoolRolloutStatus{
+			Phase:     infrav1exp.ProgressingRolloutPhase,
+			StartTime: &now,
+		}
+		if canaryReplicas > 0 {
+			rollout.Phase = infrav1exp.CanaryRolloutPhase
+		}
+		log.Info("started the rollout of the latest model", "phase", rollout.Phase)
+		m.AzureMachinePool.Status.Rollout = rollout
+	}
+	rollout.ModelHash = modelHash
+
+	rollout.Replicas = int32(replicas)
+	rollout.UpdatedReplicas = int32(len(updated))
+	rollout.CanaryReplicas = int32(canaryReplicas)
+
+	if rollout.Phase == infrav1exp.CanaryRolloutPhase && len(updated) >= canaryReplicas {
+		log.Info("pausing the rollout after its canary batch", "canaryReplicas", canaryReplicas)
+		rollout.Phase = infrav1exp.PausedRolloutPhase
+	}
+
+	if rollout.Phase == infrav1exp.PausedRolloutPhase {
+		m.continueRolloutIfHealthy(ctx, rollout, updated, machinesByProviderID, now)
+	}
+
+	switch rollout.Phase {
+	case infrav1exp.CanaryRolloutPhase:
+		rollout.Message = fmt.Sprintf("rolling out the latest model to %d of %d canary machines", len(updated), canaryReplicas)
+	case infrav1exp.ProgressingRolloutPhase:
+		rollout.Message = fmt.Sprintf("rolling out the latest model to the remaining %d machines", replicas-len(updated))
+	}
+
+	return nil
+}
+
+// modelHash returns a hash of the latest model of the scale set, or an empty string if it is not known.
+func (m MachinePoolScope) modelHash() string {
+	if m.vmssState == nil {
+		return ""
+	}
+	image, err := json.Marshal(m.vmssState.Image)
+	if err != nil {
+		return ""
+	}
+	h := fnv.New32a()
+	if _, err := h.Write(image); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", h.Sum32())
+}
+
+// rolloutModelChanged returns true if the latest model of the scale set is not the one of a rollout that has not
+// completed yet.
+func (m MachinePoolScope) rolloutModelChanged(rollout *infrav1exp.AzureMachinePoolRolloutStatus) bool {
+	if rollout.Phase == infrav1exp.CompletedRolloutPhase || rollout.ModelHash == "" {
+		return false
+	}
+	modelHash := m.modelHash()
+	return modelHash != "" && modelHash != rollout.ModelHash
+}
+
+// continueRolloutIfHealthy moves a rollout paused after its canary batch on to the remaining machines once it is
+// approved with the ApproveRolloutAnnotation, or the nodes of the canary batch have been Ready without any
+// AzureMachinePoolMachine failures for the duration of the health gate.
+func (m *MachinePoolScope) continueRolloutIfHealthy(ctx context.Context, rollout *infrav1exp.AzureMachinePoolRolloutStatus, updated []string, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine, now metav1.Time) {
+	log := ctrl.LoggerFrom(ctx)
+
+	canary := m.AzureMachinePool.Spec.Strategy.Canary
+	if canary == nil {
+		log.Info("continuing the rollout since the deployment strategy no longer has a canary batch")
+		rollout.Phase = infrav1exp.ProgressingRolloutPhase
+		rollout.CanaryHealthySince = nil
+		return
+	}
+
+	if _, ok := m.AzureMachinePool.Annotations[infrav1exp.ApproveRolloutAnnotation]; ok {
+		log.Info("continuing the approved rollout")
+		delete(m.AzureMachinePool.Annotations, infrav1exp.ApproveRolloutAnnotation)
+		rollout.Phase = infrav1exp.ProgressingRolloutPhase
+		rollout.CanaryHealthySince = nil
+		return
+	}
+
+	if canary.HealthGate == nil {
+		rollout.Message = fmt.Sprintf("waiting for the rollout to be approved with the %s annotation", infrav1exp.ApproveRolloutAnnotation)
+		return
+	}
+
+	if !isCanaryHealthy(updated, machinesByProviderID) {
+		rollout.CanaryHealthySince = nil
+		rollout.Message = "waiting for the nodes of the canary batch to be Ready"
+		return
+	}
+
+	if rollout.CanaryHealthySince == nil {
+		rollout.CanaryHealthySince = &now
+	}
+
+	stableFor := time.Duration(canary.HealthGate.StableMinutes) * time.Minute
+	if now.Sub(rollout.CanaryHealthySince.Time) < stableFor {
+		rollout.Message = fmt.Sprintf("waiting for the canary batch to be healthy for %s", stableFor)
+		return
+	}
+
+	log.Info("continuing the rollout since the canary batch passed its health gate", "stableFor", stableFor)
+	rollout.Phase = infrav1exp.ProgressingRolloutPhase
+	rollout.CanaryHealthySince = nil
+}
+
+// isCanaryHealthy returns true if the nodes of all machines running the latest model are Ready and none of their
+// AzureMachinePoolMachines failed.
+func isCanaryHealthy(updated []string, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) bool {
+	for _, providerID := range updated {
+		machine, ok := machinesByProviderID[providerID]
+		if !ok || !machine.Status.Ready || machine.Status.FailureReason != nil {
+			return false
+		}
+
+		if state := machine.Status.ProvisioningState; state != nil && *state == infrav1.Failed {
+			return false
+		}
+	}
+
+	return true
+}
+
 // updateReplicasAndProviderIDs ties the Azure VMSS instance data and the Node status data together to build and update
 // the AzureMachinePool replica count and providerIDList.
 func (m *MachinePoolScope) updateReplicasAndProviderIDs(ctx context.Context) error {
@@ -223,9 +596,95 @@ func (m *MachinePoolScope) updateReplicasAndProviderIDs(ctx context.Context) err
 
 	m.AzureMachinePool.Status.Replicas = readyReplicas
 	m.AzureMachinePool.Spec.ProviderIDList = providerIDs
+	m.updateZones(machines)
 	return nil
 }
 
+// updateZones reports the number of total and ready instances of the machine pool in each of its availability zones.
+// The zones of the failure domains of the machine pool are always reported, even when they have no instances.
+func (m *MachinePoolScope) updateZones(machines []infrav1exp.AzureMachinePoolMachine) {
+	readyByProviderID := make(map[string]bool, len(machines))
+	for _, machine := range machines {
+		readyByProviderID[machine.Spec.ProviderID] = machine.Status.Ready
+	}
+
+	zones := make(map[string]*infrav1exp.AzureMachinePoolZoneStatus)
+	if m.MachinePool != nil {
+		for _, zone := range m.MachinePool.Spec.FailureDomains {
+			zones[zone] = &infrav1exp.AzureMachinePoolZoneStatus{Zone: zone}
+		}
+	}
+
+	for _, vmss := range []*azure.VMSS{m.vmssState, m.onDemandState} {
+		if vmss == nil {
+			continue
+		}
+
+		for _, instance := range vmss.Instances {
+			if instance.AvailabilityZone == "" {
+				continue
+			}
+
+			zone, ok := zones[instance.AvailabilityZone]
+			if !ok {
+				zone = &infrav1exp.AzureMachinePoolZoneStatus{Zone: instance.AvailabilityZone}
+				zones[instance.AvailabilityZone] = zone
+			}
+
+			zone.Replicas++
+			if readyByProviderID[instance.ProviderID()] {
+				zone.ReadyReplicas++
+			}
+		}
+	}
+
+	var statuses []infrav1exp.AzureMachinePoolZoneStatus
+	for _, zone := range zones {
+		statuses = append(statuses, *zone)
+	}
+
+	// keep a stable order to not patch the status needlessly
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Zone < statuses[j].Zone
+	})
+	m.AzureMachinePool.Status.Zones = statuses
+}
+
+// updateProtectedInstances reports the instances of the scale sets of the machine pool which are protected from scale in
+// or from all the actions of their scale set.
+func (m *MachinePoolScope) updateProtectedInstances() {
+	var protected []infrav1exp.AzureMachinePoolProtectedInstance
+	for _, vmss := range []*azure.VMSS{m.vmssState, m.onDemandState} {
+		if vmss == nil {
+			continue
+		}
+
+		for _, instance := range vmss.Instances {
+			var protection infrav1exp.InstanceProtectionType
+			switch {
+			case instance.ProtectFromScaleSetActions:
+				protection = infrav1exp.ScaleSetActionsInstanceProtection
+			case instance.ProtectFromScaleIn:
+				protection = infrav1exp.ScaleInInstanceProtection
+			default:
+				continue
+			}
+
+			protected = append(protected, infrav1exp.AzureMachinePoolProtectedInstance{
+				InstanceID: instance.InstanceID,
+				ProviderID: instance.ProviderID(),
+				Protection: protection,
+			})
+		}
+	}
+
+	// keep a stable order to not patch the status needlessly
+	sort.Slice(protected, func(i, j int) bool {
+		return protected[i].ProviderID < protected[j].ProviderID
+	})
+	m.AzureMachinePool.Status.ProtectedInstances = protected
+}
+
 func (m *MachinePoolScope) getMachinePoolMachines(ctx context.Context) ([]infrav1exp.AzureMachinePoolMachine, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.getMachinePoolMachines")
 	defer done()
@@ -267,15 +726,32 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 
 	// determine which machines need to be created to reflect the current state in Azure
 	azureMachinesByProviderID := m.vmssState.InstancesByProviderID()
+	onDemandMachinesByProviderID := map[string]azure.VMSSVM{}
+	if m.onDemandState != nil {
+		onDemandMachinesByProviderID = m.onDemandState.InstancesByProviderID()
+	}
 	for key, val := range azureMachinesByProviderID {
 		if _, ok := existingMachinesByProviderID[key]; !ok {
 			log.V(4).Info("creating AzureMachinePoolMachine", "providerID", key)
-			if err := m.createMachine(ctx, val); err != nil {
+			if err := m.createMachine(ctx, val, m.AzureMachinePool.Name); err != nil {
 				return errors.Wrap(err, "failed creating AzureMachinePoolMachine")
 			}
 			continue
 		}
 	}
+	for key, val := range onDemandMachinesByProviderID {
+		azureMachinesByProviderID[key] = val
+		if _, ok := existingMachinesByProviderID[key]; !ok {
+			log.V(4).Info("creating on-demand AzureMachinePoolMachine", "providerID", key)
+			if err := m.createMachine(ctx, val, m.onDemandScaleSetName()); err != nil {
+				return errors.Wrap(err, "failed creating AzureMachinePoolMachine")
+			}
+		}
+	}
+
+	if err := m.updateRolloutStatus(ctx, existingMachinesByProviderID); err != nil {
+		return errors.Wrap(err, "failed to update the rollout status")
+	}
 
 	deleted := false
 	// delete machines that no longer exist in Azure
@@ -297,7 +773,7 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 		return nil
 	}
 
-	if futures.Has(m.AzureMachinePool, m.Name(), ScalesetsServiceName) {
+	if futures.Has(m.AzureMachinePool, m.Name(), ScalesetsServiceName) || futures.Has(m.AzureMachinePool, m.onDemandScaleSetName(), ScalesetsServiceName) {
 		log.V(4).Info("exiting early due an in-progress long running operation on the ScaleSet")
 		// exit early to be less greedy about delete
 		return nil
@@ -309,8 +785,16 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 		return nil
 	}
 
+	maxReplacements, limited, err := m.maxRolloutReplacements()
+	if err != nil {
+		return err
+	}
+	if limited {
+		deleteSelector = machinepool.LimitReplacements(deleteSelector, maxReplacements)
+	}
+
 	// select machines to delete to lower the replica count
-	toDelete, err := deleteSelector.SelectMachinesToDelete(ctx, m.DesiredReplicas(), existingMachinesByProviderID)
+	toDelete, err := m.selectMachinesToDelete(ctx, deleteSelector, existingMachinesByProviderID, onDemandMachinesByProviderID)
 	if err != nil {
 		return errors.Wrap(err, "failed selecting AzureMachinePoolMachine(s) to delete")
 	}
@@ -321,13 +805,95 @@ func (m *MachinePoolScope) applyAzureMachinePoolMachines(ctx context.Context) er
 		if err := m.client.Delete(ctx, &machine); err != nil {
 			return errors.Wrap(err, "failed deleting AzureMachinePoolMachine to reduce replica count")
 		}
+		delete(existingMachinesByProviderID, machine.Spec.ProviderID)
+	}
+
+	if reimageSelector, ok := deleteSelector.(machinepool.ReimageSelector); ok {
+		if err := m.reimageAzureMachinePoolMachines(ctx, reimageSelector, existingMachinesByProviderID); err != nil {
+			return err
+		}
 	}
 
 	log.V(4).Info("done reconciling AzureMachinePoolMachine(s)")
 	return nil
 }
 
-func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSSVM) error {
+// reimageAzureMachinePoolMachines requests the machines selected by the deployment strategy to be reimaged in place with
+// the latest model. The AzureMachinePoolMachine controller drains, updates and reimages each requested machine.
+func (m *MachinePoolScope) reimageAzureMachinePoolMachines(ctx context.Context, reimageSelector machinepool.ReimageSelector, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.reimageAzureMachinePoolMachines")
+	defer done()
+
+	toReimage, err := reimageSelector.SelectMachinesToReimage(ctx, m.DesiredReplicas(), machinesByProviderID)
+	if err != nil {
+		return errors.Wrap(err, "failed selecting AzureMachinePoolMachine(s) to reimage")
+	}
+
+	for _, machine := range toReimage {
+		machine := machine
+		log.Info("reimaging selected AzureMachinePoolMachine", "providerID", machine.Spec.ProviderID)
+		original := machine.DeepCopy()
+		if machine.Annotations == nil {
+			machine.Annotations = map[string]string{}
+		}
+		machine.Annotations[infrav1exp.ReimageMachineAnnotation] = ""
+		if err := m.client.Patch(ctx, &machine, client.MergeFrom(original)); err != nil {
+			return errors.Wrap(err, "failed requesting AzureMachinePoolMachine to be reimaged")
+		}
+	}
+
+	return nil
+}
+
+// selectMachinesToDelete selects the machines to delete to lower the replica count. The machines of a machine pool
+// mixing on-demand and Spot instances are selected separately for each scale set, so that each scale set is lowered
+// to its share of the replicas without dropping below the replicas the other scale set is still missing.
+func (m *MachinePoolScope) selectMachinesToDelete(ctx context.Context, deleteSelector machinepool.TypedDeleteSelector, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine, onDemandMachinesByProviderID map[string]azure.VMSSVM) ([]infrav1exp.AzureMachinePoolMachine, error) {
+	if m.AzureMachinePool.Spec.SpotCapacityPolicy == nil {
+		return deleteSelector.SelectMachinesToDelete(ctx, m.DesiredReplicas(), machinesByProviderID)
+	}
+
+	spotMachines := map[string]infrav1exp.AzureMachinePoolMachine{}
+	onDemandMachines := map[string]infrav1exp.AzureMachinePoolMachine{}
+	for providerID, machine := range machinesByProviderID {
+		if _, ok := onDemandMachinesByProviderID[providerID]; ok {
+			onDemandMachines[providerID] = machine
+		} else {
+			spotMachines[providerID] = machine
+		}
+	}
+
+	spotReplicas, onDemandReplicas := m.spotAndOnDemandReplicas()
+	toDelete, err := deleteSelector.SelectMachinesToDelete(ctx, spotReplicas+missingReplicas(onDemandMachines, onDemandReplicas), spotMachines)
+	if err != nil {
+		return nil, err
+	}
+
+	onDemandToDelete, err := deleteSelector.SelectMachinesToDelete(ctx, onDemandReplicas+missingReplicas(spotMachines, spotReplicas), onDemandMachines)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(toDelete, onDemandToDelete...), nil
+}
+
+// missingReplicas returns the number of ready machines missing to reach the desired replicas.
+func missingReplicas(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine, desiredReplicas int32) int32 {
+	var ready int32
+	for _, machine := range machinesByProviderID {
+		state := machine.Status.ProvisioningState
+		if machine.Status.Ready && state != nil && *state == infrav1.Succeeded {
+			ready++
+		}
+	}
+
+	if ready >= desiredReplicas {
+		return 0
+	}
+	return desiredReplicas - ready
+}
+
+func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSSVM, namePrefix string) error {
 	if machine.InstanceID == "" {
 		return errors.New("machine.InstanceID must not be empty")
 	}
@@ -336,9 +902,16 @@ func (m *MachinePoolScope) createMachine(ctx context.Context, machine azure.VMSS
 		return errors.New("machine.Name must not be empty")
 	}
 
+	name := strings.Join([]string{namePrefix, machine.InstanceID}, "-")
+	if m.AzureMachinePool.Spec.OrchestrationMode == infrav1.FlexibleOrchestrationMode {
+		// Flexible instances are named after the scale set with a suffix that is not a valid Kubernetes name, so use
+		// the computer name, which is prefixed with the scale set name, instead.
+		name = strings.ToLower(machine.Name)
+	}
+
 	ampm := infrav1exp.AzureMachinePoolMachine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      strings.Join([]string{m.AzureMachinePool.Name, machine.InstanceID}, "-"),
+			Name:      name,
 			Namespace: m.AzureMachinePool.Namespace,
 			OwnerReferences: []metav1.OwnerReference{
 				{
@@ -505,10 +1078,18 @@ func (m *MachinePoolScope) Close(ctx context.Context) error {
 			return errors.Wrap(err, "failed to apply changes to AzureMachinePoolMachines")
 		}
 
-		m.setProvisioningStateAndConditions(m.vmssState.State)
+		state := m.vmssState.State
+		if m.onDemandState != nil && (state == infrav1.Succeeded || (state == infrav1.Failed && m.spotFallbackActive())) {
+			// the machine pool is only provisioned once both of its scale sets are, and a failed Spot scale set is
+			// expected while its replicas fell back to on-demand instances
+			state = m.onDemandState.State
+		}
+
+		m.setProvisioningStateAndConditions(state)
 		if err := m.updateReplicasAndProviderIDs(ctx); err != nil {
 			return errors.Wrap(err, "failed to update replicas and providerIDs")
 		}
+		m.updateProtectedInstances()
 	}
 
 	return m.patchHelper.Patch(ctx, m.AzureMachinePool)
@@ -533,7 +1114,35 @@ func (m *MachinePoolScope) GetBootstrapData(ctx context.Context) (string, error)
 	if !ok {
 		return "", errors.New("error retrieving bootstrap data: secret value key is missing")
 	}
-	return base64.StdEncoding.EncodeToString(value), nil
+	if keyVaultForMachinePool(m.KeyVault(), m.AzureMachinePool) == nil {
+		return base64.StdEncoding.EncodeToString(value), nil
+	}
+	return keyvaults.CustomData(value, m.customDataOptions())
+}
+
+// customDataOptions returns the options of the custom data fetching the cloud provider config of the instances of the
+// scale set from the Key Vault of the cluster.
+func (m *MachinePoolScope) customDataOptions() keyvaults.CustomDataOptions {
+	options := keyvaults.CustomDataOptions{
+		KeyVaultURL:                   m.KeyVaultURL(),
+		CloudProviderConfigSecretName: keyvaults.CloudProviderConfigSecretName(m.AzureMachinePool.Name, false),
+	}
+	if m.AzureMachinePool.Spec.Identity == infrav1.VMIdentityUserAssigned && len(m.AzureMachinePool.Spec.UserAssignedIdentities) > 0 {
+		options.UserAssignedIdentityID = strings.TrimPrefix(m.AzureMachinePool.Spec.UserAssignedIdentities[0].ProviderID, azure.ProviderIDPrefix)
+	}
+	return options
+}
+
+// ValidateKeyVaultIdentity returns an error if the scale set fetches its cloud provider config from the Key Vault of the
+// cluster without a managed identity to fetch it with.
+func (m *MachinePoolScope) ValidateKeyVaultIdentity() error {
+	if keyVaultForMachinePool(m.KeyVault(), m.AzureMachinePool) == nil {
+		return nil
+	}
+	if m.AzureMachinePool.Spec.Identity == "" || m.AzureMachinePool.Spec.Identity == infrav1.VMIdentityNone {
+		return errors.Errorf("AzureMachinePool %s must have a SystemAssigned or UserAssigned identity to fetch its cloud provider config from Key Vault %s", m.AzureMachinePool.Name, m.KeyVault().Name)
+	}
+	return nil
 }
 
 // GetVMImage picks an image from the machine configuration, or uses a default one.
@@ -572,27 +1181,79 @@ func (m *MachinePoolScope) SaveVMImageToStatus(image *infrav1.Image) {
 
 // RoleAssignmentSpecs returns the role assignment specs.
 func (m *MachinePoolScope) RoleAssignmentSpecs() []azure.RoleAssignmentSpec {
+	specs := []azure.RoleAssignmentSpec{}
 	if m.AzureMachinePool.Spec.Identity == infrav1.VMIdentitySystemAssigned {
-		return []azure.RoleAssignmentSpec{
-			{
-				MachineName:  m.Name(),
-				Name:         m.AzureMachinePool.Spec.RoleAssignmentName,
+		specs = append(specs, azure.RoleAssignmentSpec{
+			MachineName:  m.Name(),
+			Name:         m.AzureMachinePool.Spec.RoleAssignmentName,
+			ResourceType: azure.VirtualMachineScaleSet,
+		})
+		if onDemandSpec := m.OnDemandScaleSetSpec(); onDemandSpec != nil {
+			// role assignment names must be unique GUIDs, so derive a stable one for the on-demand scale set
+			specs = append(specs, azure.RoleAssignmentSpec{
+				MachineName:  onDemandSpec.Name,
+				Name:         uuid.NewSHA1(uuid.NameSpaceOID, []byte(m.AzureMachinePool.Spec.RoleAssignmentName+"/"+onDemandSpec.Name)).String(),
 				ResourceType: azure.VirtualMachineScaleSet,
-			},
+			})
+		}
+	}
+
+	roleAssignments := append([]infrav1.RoleAssignment{}, m.AzureMachinePool.Spec.RoleAssignments...)
+	roleAssignments = append(roleAssignments, keyVaultRoleAssignmentsForMachinePool(m.KeyVault(), m.KeyVaultID(), m.AzureMachinePool)...)
+	if len(roleAssignments) == 0 {
+		return specs
+	}
+	specs = append(specs, declaredRoleAssignmentSpecs(m.SubscriptionID(), m.ResourceGroup(), m.Name(), azure.VirtualMachineScaleSet, roleAssignments)...)
+	if onDemandSpec := m.OnDemandScaleSetSpec(); onDemandSpec != nil {
+		for _, spec := range declaredRoleAssignmentSpecs(m.SubscriptionID(), m.ResourceGroup(), onDemandSpec.Name, azure.VirtualMachineScaleSet, roleAssignments) {
+			// the role assignments of user-assigned identities are shared with the spot scale set
+			if spec.UserAssignedIdentityID == "" {
+				specs = append(specs, spec)
+			}
 		}
 	}
-	return []azure.RoleAssignmentSpec{}
+	return specs
+}
+
+// IsRoleAssignmentShared returns true if the role assignment is also declared by another machine of the cluster.
+func (m *MachinePoolScope) IsRoleAssignmentShared(ctx context.Context, spec azure.RoleAssignmentSpec) (bool, error) {
+	return isRoleAssignmentShared(ctx, m.client, m.AzureMachinePool, m.ClusterName(), m.SubscriptionID(), m.ResourceGroup(), m.KeyVault(), m.KeyVaultID(), spec)
+}
+
+// RoleAssignmentIDs returns the resource IDs of the role assignments created for the scale set.
+func (m *MachinePoolScope) RoleAssignmentIDs() []string {
+	return m.AzureMachinePool.Status.RoleAssignmentIDs
+}
+
+// SetRoleAssignmentIDs sets the resource IDs of the role assignments created for the scale set.
+func (m *MachinePoolScope) SetRoleAssignmentIDs(ids []string) {
+	m.AzureMachinePool.Status.RoleAssignmentIDs = ids
 }
 
 // VMSSExtensionSpecs returns the vmss extension specs.
 func (m *MachinePoolScope) VMSSExtensionSpecs() []azure.ExtensionSpec {
 	var extensionSpecs = []azure.ExtensionSpec{}
-	extensionSpec := azure.GetBootstrappingVMExtension(m.AzureMachinePool.Spec.Template.OSDisk.OSType, m.CloudEnvironment(), m.Name())
+	extensionSpecs = append(extensionSpecs, m.scaleSetExtensionSpecs(m.Name())...)
+
+	if onDemandSpec := m.OnDemandScaleSetSpec(); onDemandSpec != nil {
+		extensionSpecs = append(extensionSpecs, m.scaleSetExtensionSpecs(onDemandSpec.Name)...)
+	}
+
+	return extensionSpecs
+}
 
-	if extensionSpec != nil {
+// scaleSetExtensionSpecs returns the extension specs of a single scale set of the machine pool.
+func (m *MachinePoolScope) scaleSetExtensionSpecs(vmssName string) []azure.ExtensionSpec {
+	var extensionSpecs []azure.ExtensionSpec
+	osType := m.AzureMachinePool.Spec.Template.OSDisk.OSType
+	if extensionSpec := azure.GetBootstrappingVMExtension(osType, m.CloudEnvironment(), vmssName); extensionSpec != nil {
 		extensionSpecs = append(extensionSpecs, *extensionSpec)
 	}
 
+	if probe := m.AzureMachinePool.Spec.HealthProbe; probe != nil {
+		extensionSpecs = append(extensionSpecs, *azure.GetApplicationHealthExtension(osType, vmssName, string(probe.Protocol), probe.Port, probe.RequestPath))
+	}
+
 	return extensionSpecs
 }
 
@@ -604,6 +1265,35 @@ func (m *MachinePoolScope) getDeploymentStrategy() machinepool.TypedDeleteSelect
 	return machinepool.NewMachinePoolDeploymentStrategy(m.AzureMachinePool.Spec.Strategy)
 }
 
+// SetNodeCapacity records the capacity, labels and taints of the nodes of the machine pool in its status, which lets
+// the cluster autoscaler scale the machine pool up from zero replicas.
+func (m *MachinePoolScope) SetNodeCapacity(ctx context.Context, sku resourceskus.SKU) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolScope.SetNodeCapacity")
+	defer done()
+
+	capacity, err := NodeCapacity(sku, m.AzureMachinePool.Spec.Template.OSDisk)
+	if err != nil {
+		return err
+	}
+
+	var zone string
+	if len(m.MachinePool.Spec.FailureDomains) == 1 {
+		zone = m.MachinePool.Spec.FailureDomains[0]
+	}
+	labels := NodeLabels(sku, m.AzureMachinePool.Spec.Template.OSDisk.OSType, m.Location(), zone)
+
+	bootstrapLabels, taints, err := BootstrapNodeRegistration(ctx, m.client, m.MachinePool.Namespace, m.MachinePool.Spec.Template.Spec.Bootstrap.ConfigRef)
+	if err != nil {
+		return err
+	}
+	AddBootstrapNodeLabels(labels, bootstrapLabels)
+
+	m.AzureMachinePool.Status.Capacity = capacity
+	m.AzureMachinePool.Status.NodeLabels = labels
+	m.AzureMachinePool.Status.NodeTaints = taints
+	return nil
+}
+
 // SetSubnetName defaults the AzureMachinePool subnet name to the name of the subnet with role 'node' when there is only one of them.
 // Note: this logic exists only for purposes of ensuring backwards compatibility for old clusters created without the `subnetName` field being
 // set, and should be removed in the future when this field is no longer optional.
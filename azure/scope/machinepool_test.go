@@ -18,9 +18,12 @@ package scope
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	autorestazure "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
@@ -33,10 +36,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	machinepool "sigs.k8s.io/cluster-api-provider-azure/azure/scope/strategies/machinepool_deployments"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
 	clusterv1exp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -217,6 +223,62 @@ func TestMachinePoolScope_MaxSurge(t *testing.T) {
 				g.Expect(err).NotTo(HaveOccurred())
 			},
 		},
+		{
+			Name: "surge should be capped to the canary replicas of a new rollout",
+			Setup: func(mp *clusterv1exp.MachinePool, amp *infrav1exp.AzureMachinePool) {
+				mp.Spec.Replicas = to.Int32Ptr(4)
+				two := intstr.FromInt(2)
+				amp.Spec.Strategy = infrav1exp.AzureMachinePoolDeploymentStrategy{
+					Type: infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+					RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{
+						MaxSurge: &two,
+					},
+					Canary: &infrav1exp.AzureMachinePoolCanary{},
+				}
+			},
+			Verify: func(g *WithT, surge int, err error) {
+				g.Expect(surge).To(Equal(1))
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+		{
+			Name: "surge should be 0 while the rollout is paused",
+			Setup: func(mp *clusterv1exp.MachinePool, amp *infrav1exp.AzureMachinePool) {
+				mp.Spec.Replicas = to.Int32Ptr(4)
+				amp.Spec.Strategy = infrav1exp.AzureMachinePoolDeploymentStrategy{
+					Type:   infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+					Canary: &infrav1exp.AzureMachinePoolCanary{},
+				}
+				amp.Status.Rollout = &infrav1exp.AzureMachinePoolRolloutStatus{
+					Phase: infrav1exp.PausedRolloutPhase,
+				}
+			},
+			Verify: func(g *WithT, surge int, err error) {
+				g.Expect(surge).To(Equal(0))
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+		{
+			Name: "surge should not be capped once the rollout continues after the canary batch",
+			Setup: func(mp *clusterv1exp.MachinePool, amp *infrav1exp.AzureMachinePool) {
+				mp.Spec.Replicas = to.Int32Ptr(4)
+				two := intstr.FromInt(2)
+				amp.Spec.Strategy = infrav1exp.AzureMachinePoolDeploymentStrategy{
+					Type: infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+					RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{
+						MaxSurge: &two,
+					},
+					Canary: &infrav1exp.AzureMachinePoolCanary{},
+				}
+				amp.Status.Rollout = &infrav1exp.AzureMachinePoolRolloutStatus{
+					Phase: infrav1exp.ProgressingRolloutPhase,
+				}
+			},
+			Verify: func(g *WithT, surge int, err error) {
+				g.Expect(surge).To(Equal(2))
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -620,6 +682,465 @@ func TestMachinePoolScope_updateReplicasAndProviderIDs(t *testing.T) {
 	}
 }
 
+func TestMachinePoolScope_updateProtectedInstances(t *testing.T) {
+	g := NewWithT(t)
+	s := &MachinePoolScope{
+		AzureMachinePool: &infrav1exp.AzureMachinePool{
+			Status: infrav1exp.AzureMachinePoolStatus{
+				ProtectedInstances: []infrav1exp.AzureMachinePoolProtectedInstance{
+					{InstanceID: "9", ProviderID: "azure:///vmss/9", Protection: infrav1exp.ScaleInInstanceProtection},
+				},
+			},
+		},
+		vmssState: &azure.VMSS{
+			Instances: []azure.VMSSVM{
+				{ID: "/vmss/2", InstanceID: "2", ProtectFromScaleIn: true},
+				{ID: "/vmss/0", InstanceID: "0"},
+				{ID: "/vmss/1", InstanceID: "1", ProtectFromScaleIn: true, ProtectFromScaleSetActions: true},
+			},
+		},
+		onDemandState: &azure.VMSS{
+			Instances: []azure.VMSSVM{
+				{ID: "/vmss-ondemand/0", InstanceID: "0", ProtectFromScaleSetActions: true},
+			},
+		},
+	}
+
+	s.updateProtectedInstances()
+	g.Expect(s.AzureMachinePool.Status.ProtectedInstances).To(Equal([]infrav1exp.AzureMachinePoolProtectedInstance{
+		{InstanceID: "0", ProviderID: "azure:///vmss-ondemand/0", Protection: infrav1exp.ScaleSetActionsInstanceProtection},
+		{InstanceID: "1", ProviderID: "azure:///vmss/1", Protection: infrav1exp.ScaleSetActionsInstanceProtection},
+		{InstanceID: "2", ProviderID: "azure:///vmss/2", Protection: infrav1exp.ScaleInInstanceProtection},
+	}))
+
+	s.vmssState.Instances = nil
+	s.onDemandState = nil
+	s.updateProtectedInstances()
+	g.Expect(s.AzureMachinePool.Status.ProtectedInstances).To(BeEmpty())
+}
+
+func TestMachinePoolScope_updateZones(t *testing.T) {
+	g := NewWithT(t)
+	s := &MachinePoolScope{
+		MachinePool: &clusterv1exp.MachinePool{
+			Spec: clusterv1exp.MachinePoolSpec{
+				FailureDomains: []string{"1", "2", "3"},
+			},
+		},
+		AzureMachinePool: &infrav1exp.AzureMachinePool{},
+		vmssState: &azure.VMSS{
+			Instances: []azure.VMSSVM{
+				{ID: "/vmss/0", InstanceID: "0", AvailabilityZone: "1"},
+				{ID: "/vmss/1", InstanceID: "1", AvailabilityZone: "1"},
+				{ID: "/vmss/2", InstanceID: "2", AvailabilityZone: "3"},
+				{ID: "/vmss/3", InstanceID: "3"},
+			},
+		},
+		onDemandState: &azure.VMSS{
+			Instances: []azure.VMSSVM{
+				{ID: "/vmss-ondemand/0", InstanceID: "0", AvailabilityZone: "3"},
+			},
+		},
+	}
+
+	machines := []infrav1exp.AzureMachinePoolMachine{
+		{
+			Spec:   infrav1exp.AzureMachinePoolMachineSpec{ProviderID: "azure:///vmss/0"},
+			Status: infrav1exp.AzureMachinePoolMachineStatus{Ready: true},
+		},
+		{
+			Spec: infrav1exp.AzureMachinePoolMachineSpec{ProviderID: "azure:///vmss/1"},
+		},
+		{
+			Spec:   infrav1exp.AzureMachinePoolMachineSpec{ProviderID: "azure:///vmss-ondemand/0"},
+			Status: infrav1exp.AzureMachinePoolMachineStatus{Ready: true},
+		},
+	}
+
+	s.updateZones(machines)
+	g.Expect(s.AzureMachinePool.Status.Zones).To(Equal([]infrav1exp.AzureMachinePoolZoneStatus{
+		{Zone: "1", Replicas: 2, ReadyReplicas: 1},
+		{Zone: "2"},
+		{Zone: "3", Replicas: 2, ReadyReplicas: 1},
+	}))
+
+	s.MachinePool.Spec.FailureDomains = nil
+	s.vmssState.Instances = nil
+	s.onDemandState = nil
+	s.updateZones(nil)
+	g.Expect(s.AzureMachinePool.Status.Zones).To(BeEmpty())
+}
+
+func TestMachinePoolScope_createMachine(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+	_ = infrav1exp.AddToScheme(scheme)
+
+	cases := []struct {
+		Name              string
+		OrchestrationMode infrav1.OrchestrationModeType
+		NamePrefix        string
+		Instance          azure.VMSSVM
+		ExpectedName      string
+	}{
+		{
+			Name:              "uniform instance is named after its instance ID",
+			OrchestrationMode: infrav1.UniformOrchestrationMode,
+			NamePrefix:        "amp1",
+			Instance: azure.VMSSVM{
+				ID:         "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachineScaleSets/amp1/virtualMachines/3",
+				InstanceID: "3",
+				Name:       "amp1000003",
+			},
+			ExpectedName: "amp1-3",
+		},
+		{
+			Name:              "on-demand uniform instance is named after the on-demand scale set",
+			OrchestrationMode: infrav1.UniformOrchestrationMode,
+			NamePrefix:        "amp1-ondemand",
+			Instance: azure.VMSSVM{
+				ID:         "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachineScaleSets/amp1-ondemand/virtualMachines/3",
+				InstanceID: "3",
+				Name:       "amp1-ondemand000003",
+			},
+			ExpectedName: "amp1-ondemand-3",
+		},
+		{
+			Name:              "flexible instance is named after its computer name",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			NamePrefix:        "amp1",
+			Instance: azure.VMSSVM{
+				ID:         "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/amp1_0123abcd",
+				InstanceID: "amp1_0123abcd",
+				Name:       "amp1A1B2C3",
+			},
+			ExpectedName: "amp1a1b2c3",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			amp := &infrav1exp.AzureMachinePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "amp1",
+					Namespace: "default",
+				},
+				Spec: infrav1exp.AzureMachinePoolSpec{
+					OrchestrationMode: c.OrchestrationMode,
+				},
+			}
+			s := &MachinePoolScope{
+				client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+				ClusterScoper: &ClusterScope{
+					Cluster: &clusterv1.Cluster{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "cluster1",
+							Namespace: "default",
+						},
+					},
+				},
+				AzureMachinePool: amp,
+			}
+
+			g.Expect(s.createMachine(context.TODO(), c.Instance, c.NamePrefix)).To(Succeed())
+
+			ampm := &infrav1exp.AzureMachinePoolMachine{}
+			g.Expect(s.client.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: c.ExpectedName}, ampm)).To(Succeed())
+			g.Expect(ampm.Spec.ProviderID).To(Equal(azure.ProviderIDPrefix + c.Instance.ID))
+			g.Expect(ampm.Spec.InstanceID).To(Equal(c.Instance.InstanceID))
+		})
+	}
+}
+
+func TestMachinePoolScope_spotAndOnDemandReplicas(t *testing.T) {
+	cases := []struct {
+		Name             string
+		Replicas         int32
+		Policy           *infrav1exp.SpotCapacityPolicy
+		FallbackTime     *metav1.Time
+		ExpectedSpot     int32
+		ExpectedOnDemand int32
+	}{
+		{
+			Name:         "without a policy all replicas run in the pool's scale set",
+			Replicas:     5,
+			ExpectedSpot: 5,
+		},
+		{
+			Name:             "replicas below the base run on-demand",
+			Replicas:         2,
+			Policy:           &infrav1exp.SpotCapacityPolicy{BaseOnDemandCount: to.Int32Ptr(3), SpotPercentageAboveBase: to.Int32Ptr(100)},
+			ExpectedOnDemand: 2,
+		},
+		{
+			Name:             "replicas above the base are split by the spot percentage",
+			Replicas:         8,
+			Policy:           &infrav1exp.SpotCapacityPolicy{BaseOnDemandCount: to.Int32Ptr(2), SpotPercentageAboveBase: to.Int32Ptr(50)},
+			ExpectedSpot:     3,
+			ExpectedOnDemand: 5,
+		},
+		{
+			Name:             "partial spot replicas run on-demand",
+			Replicas:         5,
+			Policy:           &infrav1exp.SpotCapacityPolicy{BaseOnDemandCount: to.Int32Ptr(0), SpotPercentageAboveBase: to.Int32Ptr(70)},
+			ExpectedSpot:     3,
+			ExpectedOnDemand: 2,
+		},
+		{
+			Name:             "a recent fallback runs all replicas on-demand",
+			Replicas:         8,
+			Policy:           &infrav1exp.SpotCapacityPolicy{BaseOnDemandCount: to.Int32Ptr(2), SpotPercentageAboveBase: to.Int32Ptr(50)},
+			FallbackTime:     &metav1.Time{Time: time.Now().Add(-time.Minute)},
+			ExpectedOnDemand: 8,
+		},
+		{
+			Name:             "an expired fallback splits the replicas again",
+			Replicas:         8,
+			Policy:           &infrav1exp.SpotCapacityPolicy{BaseOnDemandCount: to.Int32Ptr(2), SpotPercentageAboveBase: to.Int32Ptr(50)},
+			FallbackTime:     &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			ExpectedSpot:     3,
+			ExpectedOnDemand: 5,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{
+					Spec: clusterv1exp.MachinePoolSpec{
+						Replicas: to.Int32Ptr(c.Replicas),
+					},
+				},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						SpotCapacityPolicy: c.Policy,
+					},
+					Status: infrav1exp.AzureMachinePoolStatus{
+						SpotFallbackTime: c.FallbackTime,
+					},
+				},
+			}
+
+			spot, onDemand := s.spotAndOnDemandReplicas()
+			g.Expect(spot).To(Equal(c.ExpectedSpot))
+			g.Expect(onDemand).To(Equal(c.ExpectedOnDemand))
+		})
+	}
+}
+
+func TestMachinePoolScope_selectMachinesToDelete(t *testing.T) {
+	newMachines := func(prefix string, count int) map[string]infrav1exp.AzureMachinePoolMachine {
+		succeeded := infrav1.Succeeded
+		machines := map[string]infrav1exp.AzureMachinePoolMachine{}
+		for i := 0; i < count; i++ {
+			providerID := fmt.Sprintf("azure:///subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachineScaleSets/%s/virtualMachines/%d", prefix, i)
+			machines[providerID] = infrav1exp.AzureMachinePoolMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              fmt.Sprintf("%s-%d", prefix, i),
+					CreationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(i) * time.Minute)),
+				},
+				Spec: infrav1exp.AzureMachinePoolMachineSpec{
+					ProviderID: providerID,
+				},
+				Status: infrav1exp.AzureMachinePoolMachineStatus{
+					Ready:              true,
+					ProvisioningState:  &succeeded,
+					LatestModelApplied: true,
+				},
+			}
+		}
+		return machines
+	}
+
+	cases := []struct {
+		Name             string
+		SpotCount        int
+		OnDemandCount    int
+		ExpectedDeletion []string
+	}{
+		{
+			Name:          "on-demand machines are kept while spot machines are missing",
+			SpotCount:     0,
+			OnDemandCount: 8,
+		},
+		{
+			Name:             "on-demand machines above their share are deleted once spot machines are ready",
+			SpotCount:        3,
+			OnDemandCount:    8,
+			ExpectedDeletion: []string{"amp1-ondemand-0", "amp1-ondemand-1", "amp1-ondemand-2"},
+		},
+		{
+			Name:             "spot machines above their share are deleted",
+			SpotCount:        4,
+			OnDemandCount:    5,
+			ExpectedDeletion: []string{"amp1-0"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{
+					Spec: clusterv1exp.MachinePoolSpec{
+						Replicas: to.Int32Ptr(8),
+					},
+				},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "amp1",
+					},
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						SpotCapacityPolicy: &infrav1exp.SpotCapacityPolicy{
+							BaseOnDemandCount:       to.Int32Ptr(2),
+							SpotPercentageAboveBase: to.Int32Ptr(50),
+						},
+						Strategy: infrav1exp.AzureMachinePoolDeploymentStrategy{
+							Type: infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+							RollingUpdate: &infrav1exp.MachineRollingUpdateDeployment{
+								DeletePolicy: infrav1exp.OldestDeletePolicyType,
+							},
+						},
+					},
+				},
+			}
+
+			machines := newMachines("amp1", c.SpotCount)
+			onDemandInstances := map[string]azure.VMSSVM{}
+			for providerID, machine := range newMachines("amp1-ondemand", c.OnDemandCount) {
+				machines[providerID] = machine
+				onDemandInstances[providerID] = azure.VMSSVM{}
+			}
+
+			toDelete, err := s.selectMachinesToDelete(context.TODO(), s.getDeploymentStrategy(), machines, onDemandInstances)
+			g.Expect(err).NotTo(HaveOccurred())
+			names := make([]string, len(toDelete))
+			for i, machine := range toDelete {
+				names[i] = machine.Name
+			}
+			g.Expect(names).To(ConsistOf(c.ExpectedDeletion))
+		})
+	}
+}
+
+func TestMachinePoolScope_FallBackToOnDemand(t *testing.T) {
+	g := NewWithT(t)
+	s := &MachinePoolScope{
+		AzureMachinePool: &infrav1exp.AzureMachinePool{
+			Spec: infrav1exp.AzureMachinePoolSpec{
+				SpotCapacityPolicy: &infrav1exp.SpotCapacityPolicy{},
+			},
+		},
+	}
+
+	s.FallBackToOnDemand(errors.New("allocation failed"))
+	g.Expect(s.FellBackToOnDemand()).To(BeTrue())
+	g.Expect(s.AzureMachinePool.Status.SpotFallbackTime).NotTo(BeNil())
+	g.Expect(conditions.IsFalse(s.AzureMachinePool, infrav1.SpotCapacityCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(s.AzureMachinePool, infrav1.SpotCapacityCondition)).To(Equal(infrav1.SpotCapacityUnavailableReason))
+
+	// spot capacity stays unavailable until the fallback expires
+	s.SetSpotCapacityAvailable()
+	g.Expect(conditions.IsFalse(s.AzureMachinePool, infrav1.SpotCapacityCondition)).To(BeTrue())
+
+	s.AzureMachinePool.Status.SpotFallbackTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	s.SetSpotCapacityAvailable()
+	g.Expect(s.AzureMachinePool.Status.SpotFallbackTime).To(BeNil())
+	g.Expect(conditions.IsTrue(s.AzureMachinePool, infrav1.SpotCapacityCondition)).To(BeTrue())
+}
+
+func TestMachinePoolScope_KeyVault(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	bootstrapSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bootstrap-data",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"value": []byte("#cloud-config\n"),
+		},
+	}
+	clusterScope := &ClusterScope{
+		AzureClients: AzureClients{
+			EnvironmentSettings: auth.EnvironmentSettings{
+				Values: map[string]string{
+					auth.SubscriptionID: "123",
+				},
+			},
+		},
+		Cluster: &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "my-cluster",
+			},
+		},
+		AzureCluster: &infrav1.AzureCluster{
+			Spec: infrav1.AzureClusterSpec{
+				ResourceGroup: "my-rg",
+				KeyVault: &infrav1.KeyVaultSpec{
+					Name:                     "kv-test",
+					BootstrapData:            true,
+					CloudProviderCredentials: true,
+				},
+			},
+		},
+	}
+	s := &MachinePoolScope{
+		client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(bootstrapSecret).Build(),
+		ClusterScoper: clusterScope,
+		keyVaultScope: keyVaultScope{describer: clusterScope},
+		MachinePool: &clusterv1exp.MachinePool{
+			Spec: clusterv1exp.MachinePoolSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						Bootstrap: clusterv1.Bootstrap{
+							DataSecretName: to.StringPtr("bootstrap-data"),
+						},
+					},
+				},
+			},
+		},
+		AzureMachinePool: &infrav1exp.AzureMachinePool{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "amp1",
+				Namespace: "default",
+			},
+		},
+	}
+
+	// the scale set needs a managed identity to fetch its cloud provider config
+	g.Expect(s.ValidateKeyVaultIdentity()).To(MatchError("AzureMachinePool amp1 must have a SystemAssigned or UserAssigned identity to fetch its cloud provider config from Key Vault kv-test"))
+	s.AzureMachinePool.Spec.Identity = infrav1.VMIdentityUserAssigned
+	s.AzureMachinePool.Spec.UserAssignedIdentities = []infrav1.UserAssignedIdentity{{ProviderID: "azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1"}}
+	g.Expect(s.ValidateKeyVaultIdentity()).To(Succeed())
+
+	specs := s.RoleAssignmentSpecs()
+	g.Expect(specs).To(HaveLen(1))
+	g.Expect(specs[0].Scope).To(Equal("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.KeyVault/vaults/kv-test/secrets/amp1-worker-node-azure-json"))
+	g.Expect(specs[0].RoleDefinitionID).To(Equal(keyVaultSecretsUserRoleID))
+	g.Expect(specs[0].UserAssignedIdentityID).To(Equal("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1"))
+
+	// the bootstrap data stays in the custom data, which fetches the cloud provider config
+	customData, err := s.GetBootstrapData(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	decoded, err := base64.StdEncoding.DecodeString(customData)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(decoded)).To(ContainSubstring("multipart/mixed"))
+
+	// machine pools running Windows keep getting their cloud provider config from their azure json secret
+	s.AzureMachinePool.Spec.Template.OSDisk.OSType = azure.WindowsOS
+	s.AzureMachinePool.Spec.Identity = infrav1.VMIdentityNone
+	g.Expect(s.ValidateKeyVaultIdentity()).To(Succeed())
+	g.Expect(s.RoleAssignmentSpecs()).To(BeEmpty())
+	customData, err = s.GetBootstrapData(context.TODO())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(customData).To(Equal(base64.StdEncoding.EncodeToString([]byte("#cloud-config\n"))))
+}
+
 func TestMachinePoolScope_VMSSExtensionSpecs(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -816,6 +1337,103 @@ func TestMachinePoolScope_VMSSExtensionSpecs(t *testing.T) {
 			},
 			want: []azure.ExtensionSpec{},
 		},
+		{
+			name: "If a health probe is set, it returns the application health extension after the bootstrapping one",
+			machinePoolScope: MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "machinepool-name",
+					},
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						Template: infrav1exp.AzureMachinePoolMachineTemplate{
+							OSDisk: infrav1.OSDisk{
+								OSType: "Linux",
+							},
+						},
+						HealthProbe: &infrav1exp.ApplicationHealthProbe{
+							Protocol:    infrav1exp.HTTPApplicationHealthProbeProtocol,
+							Port:        10256,
+							RequestPath: "/healthz",
+						},
+					},
+				},
+				ClusterScoper: &ClusterScope{
+					AzureClients: AzureClients{
+						EnvironmentSettings: auth.EnvironmentSettings{
+							Environment: autorestazure.Environment{
+								Name: autorestazure.PublicCloud.Name,
+							},
+						},
+					},
+				},
+			},
+			want: []azure.ExtensionSpec{
+				{
+					Name:      "CAPZ.Linux.Bootstrapping",
+					VMName:    "machinepool-name",
+					Publisher: "Microsoft.Azure.ContainerUpstream",
+					Version:   "1.0",
+					ProtectedSettings: map[string]string{
+						"commandToExecute": azure.LinuxBootstrapExtensionCommand,
+					},
+				},
+				{
+					Name:      "ApplicationHealthLinux",
+					VMName:    "machinepool-name",
+					Publisher: "Microsoft.ManagedServices",
+					Version:   "1.0",
+					Settings: map[string]interface{}{
+						"protocol":    "http",
+						"port":        int32(10256),
+						"requestPath": "/healthz",
+					},
+				},
+			},
+		},
+		{
+			name: "If a tcp health probe is set on Windows and cloud is not AzurePublicCloud, it returns only the application health extension",
+			machinePoolScope: MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "winpool",
+					},
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						Template: infrav1exp.AzureMachinePoolMachineTemplate{
+							OSDisk: infrav1.OSDisk{
+								OSType: "Windows",
+							},
+						},
+						HealthProbe: &infrav1exp.ApplicationHealthProbe{
+							Protocol: infrav1exp.TCPApplicationHealthProbeProtocol,
+							Port:     10250,
+						},
+					},
+				},
+				ClusterScoper: &ClusterScope{
+					AzureClients: AzureClients{
+						EnvironmentSettings: auth.EnvironmentSettings{
+							Environment: autorestazure.Environment{
+								Name: autorestazure.USGovernmentCloud.Name,
+							},
+						},
+					},
+				},
+			},
+			want: []azure.ExtensionSpec{
+				{
+					Name:      "ApplicationHealthWindows",
+					VMName:    "winpool",
+					Publisher: "Microsoft.ManagedServices",
+					Version:   "1.0",
+					Settings: map[string]interface{}{
+						"protocol": "tcp",
+						"port":     int32(10250),
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -856,3 +1474,420 @@ func getReadyAzureMachinePoolMachines(count int32) []infrav1exp.AzureMachinePool
 
 	return machines
 }
+
+func TestMachinePoolScope_SetNodeCapacity(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = bootstrapv1.AddToScheme(scheme)
+
+	cases := []struct {
+		Name           string
+		FailureDomains []string
+		ConfigRef      *corev1.ObjectReference
+		Config         *bootstrapv1.KubeadmConfig
+		Verify         func(g *WithT, amp *infrav1exp.AzureMachinePool)
+	}{
+		{
+			Name:           "should set the capacity and well-known labels of a machine pool in a single zone",
+			FailureDomains: []string{"1"},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				cpu := amp.Status.Capacity[corev1.ResourceCPU]
+				g.Expect(cpu.String()).To(Equal("2"))
+				memory := amp.Status.Capacity[corev1.ResourceMemory]
+				g.Expect(memory.String()).To(Equal("8Gi"))
+				g.Expect(amp.Status.NodeLabels).To(Equal(map[string]string{
+					corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+					corev1.LabelOSStable:           "linux",
+					corev1.LabelArchStable:         "amd64",
+					corev1.LabelTopologyRegion:     "eastus",
+					corev1.LabelTopologyZone:       "eastus-1",
+				}))
+				g.Expect(amp.Status.NodeTaints).To(BeEmpty())
+			},
+		},
+		{
+			Name: "should add the node labels and taints of the KubeadmConfig",
+			ConfigRef: &corev1.ObjectReference{
+				APIVersion: bootstrapv1.GroupVersion.String(),
+				Kind:       "KubeadmConfig",
+				Name:       "mp1-config",
+			},
+			Config: &bootstrapv1.KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mp1-config",
+					Namespace: "default",
+				},
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: map[string]string{
+								"node-labels": "workload=gpu, kubernetes.io/os=other",
+							},
+							Taints: []corev1.Taint{
+								{Key: "workload", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+							},
+						},
+					},
+				},
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.NodeLabels).To(HaveKeyWithValue("workload", "gpu"))
+				g.Expect(amp.Status.NodeLabels).To(HaveKeyWithValue(corev1.LabelOSStable, "linux"))
+				g.Expect(amp.Status.NodeLabels).NotTo(HaveKey(corev1.LabelTopologyZone))
+				g.Expect(amp.Status.NodeTaints).To(Equal([]corev1.Taint{
+					{Key: "workload", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				}))
+			},
+		},
+		{
+			Name: "should ignore a missing KubeadmConfig",
+			ConfigRef: &corev1.ObjectReference{
+				APIVersion: bootstrapv1.GroupVersion.String(),
+				Kind:       "KubeadmConfig",
+				Name:       "mp1-config",
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.NodeLabels).To(HaveKeyWithValue(corev1.LabelInstanceTypeStable, "Standard_D2s_v3"))
+				g.Expect(amp.Status.NodeTaints).To(BeEmpty())
+			},
+		},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				g  = NewWithT(t)
+				cb = fake.NewClientBuilder().WithScheme(scheme)
+			)
+			if c.Config != nil {
+				cb.WithObjects(c.Config)
+			}
+
+			s := &MachinePoolScope{
+				client: cb.Build(),
+				ClusterScoper: &ClusterScope{
+					AzureCluster: &infrav1.AzureCluster{
+						Spec: infrav1.AzureClusterSpec{
+							Location: "eastus",
+						},
+					},
+				},
+				MachinePool: &clusterv1exp.MachinePool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "mp1",
+						Namespace: "default",
+					},
+					Spec: clusterv1exp.MachinePoolSpec{
+						FailureDomains: c.FailureDomains,
+						Template: clusterv1.MachineTemplateSpec{
+							Spec: clusterv1.MachineSpec{
+								Bootstrap: clusterv1.Bootstrap{
+									ConfigRef: c.ConfigRef,
+								},
+							},
+						},
+					},
+				},
+				AzureMachinePool: &infrav1exp.AzureMachinePool{
+					Spec: infrav1exp.AzureMachinePoolSpec{
+						Template: infrav1exp.AzureMachinePoolMachineTemplate{
+							VMSize: "Standard_D2s_v3",
+							OSDisk: infrav1.OSDisk{
+								OSType: "Linux",
+							},
+						},
+					},
+				},
+			}
+
+			sku := newSKU("Standard_D2s_v3", map[string]string{"vCPUs": "2", "MemoryGB": "8"})
+			g.Expect(s.SetNodeCapacity(context.TODO(), sku)).To(Succeed())
+			c.Verify(g, s.AzureMachinePool)
+		})
+	}
+}
+
+func TestMachinePoolScope_updateRolloutStatus(t *testing.T) {
+	var (
+		oldImage  = infrav1.Image{ID: to.StringPtr("old")}
+		newImage  = infrav1.Image{ID: to.StringPtr("new")}
+		succeeded = infrav1.Succeeded
+		failed    = infrav1.Failed
+	)
+
+	instance := func(id string, image infrav1.Image) azure.VMSSVM {
+		return azure.VMSSVM{
+			ID:    "/subscriptions/123/resourceGroups/rg/providers/Microsoft.Compute/virtualMachineScaleSets/amp1/virtualMachines/" + id,
+			Image: image,
+		}
+	}
+
+	readyMachines := func(instances ...azure.VMSSVM) map[string]infrav1exp.AzureMachinePoolMachine {
+		machines := make(map[string]infrav1exp.AzureMachinePoolMachine, len(instances))
+		for _, instance := range instances {
+			machines[instance.ProviderID()] = infrav1exp.AzureMachinePoolMachine{
+				Spec: infrav1exp.AzureMachinePoolMachineSpec{
+					ProviderID: instance.ProviderID(),
+				},
+				Status: infrav1exp.AzureMachinePoolMachineStatus{
+					Ready:             true,
+					ProvisioningState: &succeeded,
+				},
+			}
+		}
+		return machines
+	}
+
+	canary := func(healthGate *infrav1exp.AzureMachinePoolHealthGate) infrav1exp.AzureMachinePoolDeploymentStrategy {
+		return infrav1exp.AzureMachinePoolDeploymentStrategy{
+			Type: infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType,
+			Canary: &infrav1exp.AzureMachinePoolCanary{
+				HealthGate: healthGate,
+			},
+		}
+	}
+
+	cases := []struct {
+		Name      string
+		Strategy  infrav1exp.AzureMachinePoolDeploymentStrategy
+		Rollout   *infrav1exp.AzureMachinePoolRolloutStatus
+		Approved  bool
+		Instances []azure.VMSSVM
+		Machines  func(instances []azure.VMSSVM) map[string]infrav1exp.AzureMachinePoolMachine
+		Verify    func(g *WithT, amp *infrav1exp.AzureMachinePool)
+	}{
+		{
+			Name:      "should not report a rollout if all machines run the latest model",
+			Strategy:  canary(nil),
+			Instances: []azure.VMSSVM{instance("0", newImage), instance("1", newImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout).To(BeNil())
+			},
+		},
+		{
+			Name:      "should start a rollout without a canary batch",
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", newImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.ProgressingRolloutPhase))
+				g.Expect(amp.Status.Rollout.StartTime).NotTo(BeNil())
+				g.Expect(amp.Status.Rollout.Replicas).To(Equal(int32(2)))
+				g.Expect(amp.Status.Rollout.UpdatedReplicas).To(Equal(int32(1)))
+			},
+		},
+		{
+			Name:      "should start a rollout with its canary batch",
+			Strategy:  canary(nil),
+			Rollout:   &infrav1exp.AzureMachinePoolRolloutStatus{Phase: infrav1exp.CompletedRolloutPhase},
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", oldImage), instance("2", oldImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.CanaryRolloutPhase))
+				g.Expect(amp.Status.Rollout.CanaryReplicas).To(Equal(int32(1)))
+				g.Expect(amp.Status.Rollout.UpdatedReplicas).To(Equal(int32(0)))
+			},
+		},
+		{
+			Name:      "should pause the rollout once the canary batch runs the latest model",
+			Strategy:  canary(nil),
+			Rollout:   &infrav1exp.AzureMachinePoolRolloutStatus{Phase: infrav1exp.CanaryRolloutPhase},
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", oldImage), instance("2", newImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.PausedRolloutPhase))
+				g.Expect(amp.Status.Rollout.Message).To(ContainSubstring(infrav1exp.ApproveRolloutAnnotation))
+			},
+		},
+		{
+			Name:      "should continue an approved rollout",
+			Strategy:  canary(nil),
+			Rollout:   &infrav1exp.AzureMachinePoolRolloutStatus{Phase: infrav1exp.PausedRolloutPhase},
+			Approved:  true,
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", oldImage), instance("2", newImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.ProgressingRolloutPhase))
+				g.Expect(amp.Annotations).NotTo(HaveKey(infrav1exp.ApproveRolloutAnnotation))
+			},
+		},
+		{
+			Name:      "should start the health gate once the canary batch is healthy",
+			Strategy:  canary(&infrav1exp.AzureMachinePoolHealthGate{StableMinutes: 10}),
+			Rollout:   &infrav1exp.AzureMachinePoolRolloutStatus{Phase: infrav1exp.PausedRolloutPhase},
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", newImage)},
+			Machines: func(instances []azure.VMSSVM) map[string]infrav1exp.AzureMachinePoolMachine {
+				return readyMachines(instances...)
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.PausedRolloutPhase))
+				g.Expect(amp.Status.Rollout.CanaryHealthySince).NotTo(BeNil())
+			},
+		},
+		{
+			Name:     "should continue the rollout once the canary batch passed the health gate",
+			Strategy: canary(&infrav1exp.AzureMachinePoolHealthGate{StableMinutes: 10}),
+			Rollout: &infrav1exp.AzureMachinePoolRolloutStatus{
+				Phase:              infrav1exp.PausedRolloutPhase,
+				CanaryHealthySince: &metav1.Time{Time: time.Now().Add(-11 * time.Minute)},
+			},
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", newImage)},
+			Machines: func(instances []azure.VMSSVM) map[string]infrav1exp.AzureMachinePoolMachine {
+				return readyMachines(instances...)
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.ProgressingRolloutPhase))
+				g.Expect(amp.Status.Rollout.CanaryHealthySince).To(BeNil())
+			},
+		},
+		{
+			Name:     "should restart the health gate if a machine of the canary batch failed",
+			Strategy: canary(&infrav1exp.AzureMachinePoolHealthGate{StableMinutes: 10}),
+			Rollout: &infrav1exp.AzureMachinePoolRolloutStatus{
+				Phase:              infrav1exp.PausedRolloutPhase,
+				CanaryHealthySince: &metav1.Time{Time: time.Now().Add(-11 * time.Minute)},
+			},
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", newImage)},
+			Machines: func(instances []azure.VMSSVM) map[string]infrav1exp.AzureMachinePoolMachine {
+				machines := readyMachines(instances...)
+				machine := machines[instances[1].ProviderID()]
+				machine.Status.ProvisioningState = &failed
+				machines[instances[1].ProviderID()] = machine
+				return machines
+			},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.PausedRolloutPhase))
+				g.Expect(amp.Status.Rollout.CanaryHealthySince).To(BeNil())
+			},
+		},
+		{
+			Name:     "should restart a paused rollout with its canary batch when the model changes",
+			Strategy: canary(nil),
+			Rollout: &infrav1exp.AzureMachinePoolRolloutStatus{
+				Phase:     infrav1exp.PausedRolloutPhase,
+				ModelHash: "previous",
+			},
+			Approved:  true,
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", infrav1.Image{ID: to.StringPtr("canary")})},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.CanaryRolloutPhase))
+				g.Expect(amp.Status.Rollout.ModelHash).NotTo(BeEmpty())
+				g.Expect(amp.Status.Rollout.ModelHash).NotTo(Equal("previous"))
+				g.Expect(amp.Status.Rollout.UpdatedReplicas).To(Equal(int32(0)))
+				g.Expect(amp.Annotations).NotTo(HaveKey(infrav1exp.ApproveRolloutAnnotation))
+			},
+		},
+		{
+			Name:     "should keep a paused rollout paused while the model is unchanged",
+			Strategy: canary(nil),
+			Rollout: &infrav1exp.AzureMachinePoolRolloutStatus{
+				Phase:     infrav1exp.PausedRolloutPhase,
+				ModelHash: (&MachinePoolScope{vmssState: &azure.VMSS{Image: newImage}}).modelHash(),
+			},
+			Instances: []azure.VMSSVM{instance("0", oldImage), instance("1", newImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.PausedRolloutPhase))
+			},
+		},
+		{
+			Name:      "should complete the rollout once all machines run the latest model",
+			Strategy:  canary(nil),
+			Rollout:   &infrav1exp.AzureMachinePoolRolloutStatus{Phase: infrav1exp.ProgressingRolloutPhase},
+			Approved:  true,
+			Instances: []azure.VMSSVM{instance("0", newImage), instance("1", newImage)},
+			Verify: func(g *WithT, amp *infrav1exp.AzureMachinePool) {
+				g.Expect(amp.Status.Rollout.Phase).To(Equal(infrav1exp.CompletedRolloutPhase))
+				g.Expect(amp.Status.Rollout.UpdatedReplicas).To(Equal(int32(2)))
+				g.Expect(amp.Annotations).NotTo(HaveKey(infrav1exp.ApproveRolloutAnnotation))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			amp := &infrav1exp.AzureMachinePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "amp1",
+					Namespace:   "default",
+					Annotations: map[string]string{},
+				},
+				Spec: infrav1exp.AzureMachinePoolSpec{
+					Strategy: c.Strategy,
+				},
+				Status: infrav1exp.AzureMachinePoolStatus{
+					Rollout: c.Rollout,
+				},
+			}
+			if c.Approved {
+				amp.Annotations[infrav1exp.ApproveRolloutAnnotation] = ""
+			}
+
+			machines := map[string]infrav1exp.AzureMachinePoolMachine{}
+			if c.Machines != nil {
+				machines = c.Machines(c.Instances)
+			}
+
+			s := &MachinePoolScope{
+				MachinePool: &clusterv1exp.MachinePool{
+					Spec: clusterv1exp.MachinePoolSpec{
+						Replicas: to.Int32Ptr(int32(len(c.Instances))),
+					},
+				},
+				AzureMachinePool: amp,
+				vmssState: &azure.VMSS{
+					Image:     newImage,
+					Instances: c.Instances,
+				},
+			}
+
+			g.Expect(s.updateRolloutStatus(context.TODO(), machines)).To(Succeed())
+			c.Verify(g, amp)
+		})
+	}
+}
+
+func TestMachinePoolScope_reimageAzureMachinePoolMachines(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	_ = infrav1exp.AddToScheme(scheme)
+
+	var (
+		succeeded = infrav1.Succeeded
+		cb        = fake.NewClientBuilder().WithScheme(scheme)
+		machines  = getReadyAzureMachinePoolMachines(3)
+	)
+	for i := range machines {
+		machines[i].Status.ProvisioningState = &succeeded
+		machines[i].CreationTimestamp = metav1.NewTime(time.Now().Add(time.Duration(-i) * time.Hour).Truncate(time.Second))
+	}
+	machines[2].Status.LatestModelApplied = true
+
+	machinesByProviderID := map[string]infrav1exp.AzureMachinePoolMachine{}
+	for _, machine := range machines {
+		obj := machine
+		cb.WithObjects(&obj)
+		machinesByProviderID[machine.Spec.ProviderID] = machine
+	}
+
+	s := &MachinePoolScope{
+		client: cb.Build(),
+		MachinePool: &clusterv1exp.MachinePool{
+			Spec: clusterv1exp.MachinePoolSpec{
+				Replicas: to.Int32Ptr(3),
+			},
+		},
+	}
+
+	strategy := machinepool.NewMachinePoolDeploymentStrategy(infrav1exp.AzureMachinePoolDeploymentStrategy{
+		Type: infrav1exp.ReimageInPlaceAzureMachinePoolDeploymentStrategyType,
+	})
+	reimageSelector, ok := strategy.(machinepool.ReimageSelector)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(s.reimageAzureMachinePoolMachines(context.TODO(), reimageSelector, machinesByProviderID)).To(Succeed())
+
+	// only the oldest machine without the latest model is reimaged, since maxUnavailable defaults to 1
+	for i, want := range []bool{false, true, false} {
+		machine := &infrav1exp.AzureMachinePoolMachine{}
+		g.Expect(s.client.Get(context.TODO(), client.ObjectKeyFromObject(&machines[i]), machine)).To(Succeed())
+		_, reimaged := machine.Annotations[infrav1exp.ReimageMachineAnnotation]
+		g.Expect(reimaged).To(Equal(want))
+	}
+}
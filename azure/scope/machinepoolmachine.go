@@ -18,13 +18,18 @@ package scope
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	kubedrain "k8s.io/kubectl/pkg/drain"
@@ -40,6 +45,7 @@ import (
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/futures"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
@@ -68,6 +74,8 @@ type (
 		Client                  client.Client
 		ClusterScope            azure.ClusterScoper
 		MachinePool             *capiv1exp.MachinePool
+		// KeyVaultScope describes the Key Vault of the cluster, if any.
+		KeyVaultScope azure.KeyVaultDescriber
 
 		// workloadNodeGetter is only used for testing purposes and provides a way for mocking requests to the workload cluster
 		workloadNodeGetter nodeGetter
@@ -127,6 +135,7 @@ func NewMachinePoolMachineScope(params MachinePoolMachineScopeParams) (*MachineP
 		MachinePool:      params.MachinePool,
 		AzureMachinePool: params.AzureMachinePool,
 		ClusterScope:     params.ClusterScope,
+		KeyVaultScope:    params.KeyVaultScope,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build machine pool scope")
@@ -161,9 +170,18 @@ func (s *MachinePoolMachineScope) InstanceID() string {
 
 // ScaleSetName is the name of the VMSS.
 func (s *MachinePoolMachineScope) ScaleSetName() string {
+	// a machine pool mixing on-demand and Spot instances runs its instances in two scale sets
+	if name := scaleSetNameFromProviderID(s.ProviderID()); name != "" {
+		return name
+	}
 	return s.MachinePoolScope.Name()
 }
 
+// OrchestrationMode is the orchestration mode of the VMSS.
+func (s *MachinePoolMachineScope) OrchestrationMode() infrav1.OrchestrationModeType {
+	return s.AzureMachinePool.Spec.OrchestrationMode
+}
+
 // SetLongRunningOperationState will set the future on the AzureMachinePoolMachine status to allow the resource to continue
 // in the next reconciliation.
 func (s *MachinePoolMachineScope) SetLongRunningOperationState(future *infrav1.Future) {
@@ -222,6 +240,105 @@ func (s *MachinePoolMachineScope) UpdatePatchStatus(condition clusterv1.Conditio
 	}
 }
 
+// SetConditionFalse sets the specified condition to false on the AzureMachinePoolMachine.
+func (s *MachinePoolMachineScope) SetConditionFalse(condition clusterv1.ConditionType, reason string, severity clusterv1.ConditionSeverity, message string) {
+	conditions.MarkFalse(s.AzureMachinePoolMachine, condition, reason, severity, "%s", message)
+}
+
+// GetConditionReason returns the reason of the specified condition of the AzureMachinePoolMachine.
+func (s *MachinePoolMachineScope) GetConditionReason(condition clusterv1.ConditionType) string {
+	return conditions.GetReason(s.AzureMachinePoolMachine, condition)
+}
+
+// ReimageRequested returns true if the deployment strategy of the AzureMachinePool selected the instance to be
+// reimaged in place.
+func (s *MachinePoolMachineScope) ReimageRequested() bool {
+	_, ok := s.AzureMachinePoolMachine.Annotations[infrav1exp.ReimageMachineAnnotation]
+	return ok
+}
+
+// InstanceProtection returns the protection of the instance requested by the annotations of the
+// AzureMachinePoolMachine, or an empty string if the instance is not protected.
+func (s *MachinePoolMachineScope) InstanceProtection() infrav1exp.InstanceProtectionType {
+	if _, ok := s.AzureMachinePoolMachine.Annotations[infrav1exp.ProtectFromScaleSetActionsAnnotation]; ok {
+		return infrav1exp.ScaleSetActionsInstanceProtection
+	}
+	if _, ok := s.AzureMachinePoolMachine.Annotations[infrav1exp.ProtectFromScaleInAnnotation]; ok {
+		return infrav1exp.ScaleInInstanceProtection
+	}
+	return ""
+}
+
+// CompleteReimage marks the reimage in place of the instance as done.
+func (s *MachinePoolMachineScope) CompleteReimage() {
+	delete(s.AzureMachinePoolMachine.Annotations, infrav1exp.ReimageMachineAnnotation)
+	conditions.MarkTrue(s.AzureMachinePoolMachine, infrav1.InstanceReimagedCondition)
+}
+
+// ScheduledEventsEnabled returns true if the AzureMachinePool enables terminate notifications, in which case pending
+// scheduled events of the instance are handled before they start.
+func (s *MachinePoolMachineScope) ScheduledEventsEnabled() bool {
+	return s.AzureMachinePool.Spec.Template.TerminateNotificationTimeout != nil
+}
+
+// IsConditionFalse returns true if the specified condition of the AzureMachinePoolMachine is false.
+func (s *MachinePoolMachineScope) IsConditionFalse(condition clusterv1.ConditionType) bool {
+	return conditions.IsFalse(s.AzureMachinePoolMachine, condition)
+}
+
+// SetAnnotation sets a key value annotation on the AzureMachinePoolMachine.
+func (s *MachinePoolMachineScope) SetAnnotation(key, value string) {
+	if s.AzureMachinePoolMachine.Annotations == nil {
+		s.AzureMachinePoolMachine.Annotations = map[string]string{}
+	}
+	s.AzureMachinePoolMachine.Annotations[key] = value
+}
+
+// CloudProviderConfig returns the cloud provider config to write on the instance, along with the hash of the config
+// last written on it, or nil if the instance runs Windows or its config is not generated by CAPZ.
+func (s *MachinePoolMachineScope) CloudProviderConfig(ctx context.Context) (*azure.CloudProviderConfig, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolMachineScope.CloudProviderConfig")
+	defer done()
+
+	if s.AzureMachinePool.Spec.Template.OSDisk.OSType == azure.WindowsOS {
+		return nil, nil
+	}
+
+	params := cloudProviderConfigParams{
+		ownerName:       s.AzureMachinePool.Name,
+		dataKey:         "worker-node-azure.json",
+		lastAppliedHash: s.AzureMachinePoolMachine.Annotations[infrav1.CloudProviderConfigLastAppliedAnnotation],
+	}
+	if keyVaultForMachinePool(s.MachinePoolScope.KeyVault(), s.AzureMachinePool) != nil {
+		params.fetchScript = func() (string, error) {
+			return keyvaults.CloudProviderConfigScript(s.MachinePoolScope.customDataOptions())
+		}
+	}
+	return getCloudProviderConfig(ctx, s.client, s.AzureMachinePoolMachine.Namespace, s.ClusterName(), params)
+}
+
+// RequestRemediation deletes the AzureMachinePoolMachine, which deletes its instance so that the scale set replaces it.
+// Instances of a machine pool have no Machine a MachineHealthCheck or an owner could remediate.
+func (s *MachinePoolMachineScope) RequestRemediation(ctx context.Context, message string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scope.MachinePoolMachineScope.RequestRemediation")
+	defer done()
+
+	if !s.AzureMachinePoolMachine.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	log.V(2).Info("deleting machine pool machine to replace its instance", "reason", message)
+	if err := s.client.Delete(ctx, s.AzureMachinePoolMachine); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete AzureMachinePoolMachine %s", s.AzureMachinePoolMachine.Name)
+	}
+	return nil
+}
+
+// SpotVMOptions returns the spot options of the AzureMachinePool the instance belongs to.
+func (s *MachinePoolMachineScope) SpotVMOptions() *infrav1.SpotVMOptions {
+	return s.AzureMachinePool.Spec.Template.SpotVMOptions
+}
+
 // SetVMSSVM update the scope with the current state of the VMSS VM.
 func (s *MachinePoolMachineScope) SetVMSSVM(instance *azure.VMSSVM) {
 	s.instance = instance
@@ -301,6 +418,7 @@ func (s *MachinePoolMachineScope) UpdateStatus(ctx context.Context) error {
 		}
 
 		s.AzureMachinePoolMachine.Status.Ready = noderefutil.IsNodeReady(node)
+		s.AzureMachinePoolMachine.Status.Cordoned = node.Spec.Unschedulable
 		s.AzureMachinePoolMachine.Status.Version = node.Status.NodeInfo.KubeletVersion
 	}
 
@@ -312,6 +430,7 @@ func (s *MachinePoolMachineScope) UpdateStatus(ctx context.Context) error {
 
 		s.AzureMachinePoolMachine.Status.LatestModelApplied = hasLatestModel
 		s.AzureMachinePoolMachine.Status.ProvisioningState = &s.instance.State
+		s.AzureMachinePoolMachine.Status.AvailabilityZone = s.instance.AvailabilityZone
 	}
 
 	return nil
@@ -319,12 +438,146 @@ func (s *MachinePoolMachineScope) UpdateStatus(ctx context.Context) error {
 
 // CordonAndDrain will cordon and drain the Kubernetes node associated with this AzureMachinePoolMachine.
 func (s *MachinePoolMachineScope) CordonAndDrain(ctx context.Context) error {
-	ctx, log, done := tele.StartSpanWithLogger(
+	ctx, _, done := tele.StartSpanWithLogger(
 		ctx,
 		"scope.MachinePoolMachineScope.CordonAndDrain",
 	)
 	defer done()
 
+	return s.cordonAndDrain(ctx, "Draining the node before deletion")
+}
+
+// CordonAndDrainForScheduledEvent will cordon and drain the Kubernetes node associated with this AzureMachinePoolMachine
+// ahead of a pending Azure scheduled event of the given type.
+func (s *MachinePoolMachineScope) CordonAndDrainForScheduledEvent(ctx context.Context, eventType string) error {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.CordonAndDrainForScheduledEvent",
+	)
+	defer done()
+
+	return s.cordonAndDrain(ctx, fmt.Sprintf("Draining the node before the %s scheduled event", eventType))
+}
+
+// CordonAndDrainForReimage will cordon and drain the Kubernetes node associated with this AzureMachinePoolMachine
+// before the instance is reimaged in place.
+func (s *MachinePoolMachineScope) CordonAndDrainForReimage(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.CordonAndDrainForReimage",
+	)
+	defer done()
+
+	return s.cordonAndDrain(ctx, "Draining the node before reimaging the instance")
+}
+
+// IsNodeReadyAfterReimage returns true if the Kubernetes node associated with this AzureMachinePoolMachine became Ready
+// after the reimage in place of the instance started.
+func (s *MachinePoolMachineScope) IsNodeReadyAfterReimage(ctx context.Context) (bool, error) {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.IsNodeReadyAfterReimage",
+	)
+	defer done()
+
+	node, err := s.getNode(ctx)
+	if err != nil || node == nil {
+		return false, err
+	}
+
+	// the condition turned False when the node was drained, so a node which is still Ready from before is not counted
+	started := conditions.GetLastTransitionTime(s.AzureMachinePoolMachine, infrav1.InstanceReimagedCondition)
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue && (started == nil || condition.LastTransitionTime.After(started.Time)), nil
+		}
+	}
+	return false, nil
+}
+
+// Uncordon marks the Kubernetes node associated with this AzureMachinePoolMachine as schedulable again and forgets about
+// the previous drain, so that a later deletion drains the node again.
+func (s *MachinePoolMachineScope) Uncordon(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.Uncordon",
+	)
+	defer done()
+
+	node, err := s.getNode(ctx)
+	if err != nil || node == nil {
+		return err
+	}
+
+	drainer, err := s.newNodeDrainer(ctx, log)
+	if err != nil {
+		return err
+	}
+
+	if err := kubedrain.RunCordonOrUncordon(drainer, node, false); err != nil {
+		return azure.WithTransientError(errors.Errorf("unable to uncordon node %s: %v", node.Name, err), 20*time.Second)
+	}
+
+	conditions.Delete(s.AzureMachinePoolMachine, clusterv1.DrainingSucceededCondition)
+	return nil
+}
+
+// GetNode returns the Kubernetes node associated with this AzureMachinePoolMachine, or nil if there is none.
+func (s *MachinePoolMachineScope) GetNode(ctx context.Context) (*corev1.Node, error) {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.GetNode",
+	)
+	defer done()
+
+	return s.getNode(ctx)
+}
+
+// SetScheduledEventApproval approves the scheduled event with the given ID on the Kubernetes node associated with this
+// AzureMachinePoolMachine, so that the scheduled events handler running on the node starts it. An empty ID removes the
+// approval.
+func (s *MachinePoolMachineScope) SetScheduledEventApproval(ctx context.Context, eventID string) error {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.SetScheduledEventApproval",
+	)
+	defer done()
+
+	node, err := s.getNode(ctx)
+	if err != nil || node == nil {
+		return err
+	}
+	if node.Annotations[infrav1exp.ApprovedScheduledEventAnnotation] == eventID {
+		return nil
+	}
+
+	var value interface{}
+	if eventID != "" {
+		value = eventID
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				infrav1exp.ApprovedScheduledEventAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal node patch")
+	}
+
+	kubeClient, err := s.newWorkloadKubeClient(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := kubeClient.CoreV1().Nodes().Patch(ctx, node.Name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to patch node %s", node.Name)
+	}
+	return nil
+}
+
+// getNode returns the Kubernetes node associated with this AzureMachinePoolMachine, or nil if there is none.
+func (s *MachinePoolMachineScope) getNode(ctx context.Context) (*corev1.Node, error) {
 	var (
 		nodeRef = s.AzureMachinePoolMachine.Status.NodeRef
 		node    *corev1.Node
@@ -339,13 +592,26 @@ func (s *MachinePoolMachineScope) CordonAndDrain(ctx context.Context) error {
 	switch {
 	case err != nil && !apierrors.IsNotFound(err):
 		// failed due to an unexpected error
-		return errors.Wrap(err, "failed to find node")
+		return nil, errors.Wrap(err, "failed to find node")
 	case err != nil && apierrors.IsNotFound(err):
 		// node was not found due to 404 when finding by ObjectReference
-		return nil
-	case node == nil:
-		// node was not found due to not finding a nodes with the ProviderID
-		return nil
+		return nil, nil
+	}
+
+	// node is nil when no node was found with the ProviderID
+	return node, nil
+}
+
+func (s *MachinePoolMachineScope) cordonAndDrain(ctx context.Context, message string) error {
+	ctx, log, done := tele.StartSpanWithLogger(
+		ctx,
+		"scope.MachinePoolMachineScope.cordonAndDrain",
+	)
+	defer done()
+
+	node, err := s.getNode(ctx)
+	if err != nil || node == nil {
+		return err
 	}
 
 	// Drain node before deletion and issue a patch in order to make this operation visible to the users.
@@ -360,7 +626,7 @@ func (s *MachinePoolMachineScope) CordonAndDrain(ctx context.Context) error {
 		// so its transition time can be used to record the first time draining.
 		// This `if` condition prevents the transition time to be changed more than once.
 		if conditions.Get(s.AzureMachinePoolMachine, clusterv1.DrainingSucceededCondition) == nil {
-			conditions.MarkFalse(s.AzureMachinePoolMachine, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
+			conditions.MarkFalse(s.AzureMachinePoolMachine, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "%s", message)
 		}
 
 		if err := patchHelper.Patch(ctx, s.AzureMachinePoolMachine); err != nil {
@@ -391,44 +657,12 @@ func (s *MachinePoolMachineScope) drainNode(ctx context.Context, node *corev1.No
 	)
 	defer done()
 
-	restConfig, err := remote.RESTConfig(ctx, MachinePoolMachineScopeName, s.client, client.ObjectKey{
-		Name:      s.ClusterName(),
-		Namespace: s.AzureMachinePoolMachine.Namespace,
-	})
-
+	drainer, err := s.newNodeDrainer(ctx, log)
 	if err != nil {
 		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
 		return nil
 	}
 
-	kubeClient, err := kubernetes.NewForConfig(restConfig)
-	if err != nil {
-		log.Error(err, "Error creating a remote client while deleting Machine, won't retry")
-		return nil
-	}
-
-	drainer := &kubedrain.Helper{
-		Client:              kubeClient,
-		Ctx:                 ctx,
-		Force:               true,
-		IgnoreAllDaemonSets: true,
-		DeleteEmptyDirData:  true,
-		GracePeriodSeconds:  -1,
-		// If a pod is not evicted in 20 seconds, retry the eviction next time the
-		// machine gets reconciled again (to allow other machines to be reconciled).
-		Timeout: 20 * time.Second,
-		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
-			verbStr := "Deleted"
-			if usingEviction {
-				verbStr = "Evicted"
-			}
-			log.V(4).Info(fmt.Sprintf("%s pod from Node", verbStr),
-				"pod", fmt.Sprintf("%s/%s", pod.Name, pod.Namespace))
-		},
-		Out:    writer{klog.Info},
-		ErrOut: writer{klog.Error},
-	}
-
 	if noderefutil.IsNodeUnreachable(node) {
 		// When the node is unreachable and some pods are not evicted for as long as this timeout, we ignore them.
 		drainer.SkipWaitForDeleteTimeoutSeconds = 60 * 5 // 5 minutes
@@ -448,6 +682,34 @@ func (s *MachinePoolMachineScope) drainNode(ctx context.Context, node *corev1.No
 	return nil
 }
 
+// newNodeDrainer returns a helper to cordon and drain nodes of the workload cluster.
+func (s *MachinePoolMachineScope) newNodeDrainer(ctx context.Context, log logr.Logger) (*kubedrain.Helper, error) {
+	kubeClient, err := s.newWorkloadKubeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDrainer(ctx, log, kubeClient), nil
+}
+
+// newWorkloadKubeClient returns a client for the workload cluster.
+func (s *MachinePoolMachineScope) newWorkloadKubeClient(ctx context.Context) (kubernetes.Interface, error) {
+	restConfig, err := remote.RESTConfig(ctx, MachinePoolMachineScopeName, s.client, client.ObjectKey{
+		Name:      s.ClusterName(),
+		Namespace: s.AzureMachinePoolMachine.Namespace,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a REST config for the workload cluster")
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a client for the workload cluster")
+	}
+
+	return kubeClient, nil
+}
+
 // isNodeDrainAllowed checks to see the node is excluded from draining or if the NodeDrainTimeout has expired.
 func (s *MachinePoolMachineScope) isNodeDrainAllowed() bool {
 	if _, exists := s.AzureMachinePoolMachine.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; exists {
@@ -506,6 +768,18 @@ func (s *MachinePoolMachineScope) hasLatestModelApplied(ctx context.Context) (bo
 	return reflect.DeepEqual(s.instance.Image, *image), nil
 }
 
+// scaleSetNameFromProviderID returns the name of the scale set referenced by the provider ID of a scale set instance,
+// or an empty string if the provider ID does not reference a scale set.
+func scaleSetNameFromProviderID(providerID string) string {
+	segments := strings.Split(providerID, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if strings.EqualFold(segments[i], "virtualMachineScaleSets") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
+
 func newWorkloadClusterProxy(c client.Client, cluster client.ObjectKey) *workloadClusterProxy {
 	return &workloadClusterProxy{
 		Client:  c,
@@ -582,6 +856,31 @@ func getWorkloadClient(ctx context.Context, c client.Client, cluster client.Obje
 	return remote.NewClusterClient(ctx, MachinePoolMachineScopeName, c, cluster)
 }
 
+// newDrainer returns a helper to cordon and drain nodes of a workload cluster.
+func newDrainer(ctx context.Context, log logr.Logger, kubeClient kubernetes.Interface) *kubedrain.Helper {
+	return &kubedrain.Helper{
+		Client:              kubeClient,
+		Ctx:                 ctx,
+		Force:               true,
+		IgnoreAllDaemonSets: true,
+		DeleteEmptyDirData:  true,
+		GracePeriodSeconds:  -1,
+		// If a pod is not evicted in 20 seconds, retry the eviction next time the
+		// machine gets reconciled again (to allow other machines to be reconciled).
+		Timeout: 20 * time.Second,
+		OnPodDeletedOrEvicted: func(pod *corev1.Pod, usingEviction bool) {
+			verbStr := "Deleted"
+			if usingEviction {
+				verbStr = "Evicted"
+			}
+			log.V(4).Info(fmt.Sprintf("%s pod from Node", verbStr),
+				"pod", fmt.Sprintf("%s/%s", pod.Name, pod.Namespace))
+		},
+		Out:    writer{klog.Info},
+		ErrOut: writer{klog.Error},
+	}
+}
+
 // writer implements io.Writer interface as a pass-through for klog.
 type writer struct {
 	logFunc func(args ...interface{})
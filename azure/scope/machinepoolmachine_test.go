@@ -19,6 +19,7 @@ package scope
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
@@ -27,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	mock_scope "sigs.k8s.io/cluster-api-provider-azure/azure/scope/mocks"
@@ -186,6 +188,25 @@ func TestMachineScope_UpdateStatus(t *testing.T) {
 				}))
 			},
 		},
+		{
+			Name: "should mark AMPM cordoned if node is unschedulable",
+			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
+				node := getReadyNode()
+				node.Spec.Unschedulable = true
+				mockNodeGetter.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(node, nil)
+				return nil, ampm
+			},
+			Verify: func(g *WithT, scope *MachinePoolMachineScope) {
+				g.Expect(scope.AzureMachinePoolMachine.Status).To(Equal(infrav1.AzureMachinePoolMachineStatus{
+					Ready:    true,
+					Cordoned: true,
+					Version:  "1.2.3",
+					NodeRef: &corev1.ObjectReference{
+						Name: "node1",
+					},
+				}))
+			},
+		},
 		{
 			Name: "fails fetching the node",
 			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
@@ -264,6 +285,32 @@ func TestMachineScope_UpdateStatus(t *testing.T) {
 				}))
 			},
 		},
+		{
+			Name: "instance availability zone populates the AMPM status",
+			Setup: func(mockNodeGetter *mock_scope.MocknodeGetter, ampm *infrav1.AzureMachinePoolMachine) (*azure.VMSSVM, *infrav1.AzureMachinePoolMachine) {
+				mockNodeGetter.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(nil, nil)
+				return &azure.VMSSVM{
+					State:            v1beta1.Succeeded,
+					AvailabilityZone: "2",
+					Image: v1beta1.Image{
+						Marketplace: &v1beta1.AzureMarketplaceImage{
+							Publisher: "cncf-upstream",
+							Offer:     "capi",
+							SKU:       "k8s-1dot19dot11-ubuntu-1804",
+							Version:   "latest",
+						},
+					},
+				}, ampm
+			},
+			Verify: func(g *WithT, scope *MachinePoolMachineScope) {
+				succeeded := v1beta1.Succeeded
+				g.Expect(scope.AzureMachinePoolMachine.Status).To(Equal(infrav1.AzureMachinePoolMachineStatus{
+					ProvisioningState:  &succeeded,
+					LatestModelApplied: true,
+					AvailabilityZone:   "2",
+				}))
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -409,6 +456,115 @@ func TestMachinePoolMachineScope_CordonAndDrain(t *testing.T) {
 	}
 }
 
+func TestMachinePoolMachineScope_IsNodeReadyAfterReimage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = capiv1exp.AddToScheme(scheme)
+	_ = infrav1.AddToScheme(scheme)
+
+	reimageStarted := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+	cases := []struct {
+		Name string
+		Node *corev1.Node
+		Want bool
+	}{
+		{
+			Name: "should not be ready if the node does not exist",
+		},
+		{
+			Name: "should not be ready if the node is not Ready",
+			Node: withReadyTransition(getNotReadyNode(), metav1.Now()),
+		},
+		{
+			Name: "should not be ready if the node is still Ready from before the reimage",
+			Node: withReadyTransition(getReadyNode(), metav1.NewTime(reimageStarted.Add(-time.Hour))),
+		},
+		{
+			Name: "should be ready if the node became Ready after the reimage",
+			Node: withReadyTransition(getReadyNode(), metav1.Now()),
+			Want: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				controller = gomock.NewController(t)
+				mockClient = mock_scope.NewMocknodeGetter(controller)
+				g          = NewWithT(t)
+				ampm       = &infrav1.AzureMachinePoolMachine{
+					Spec: infrav1.AzureMachinePoolMachineSpec{
+						ProviderID: FakeProviderID,
+					},
+					Status: infrav1.AzureMachinePoolMachineStatus{
+						Conditions: clusterv1.Conditions{
+							{
+								Type:               v1beta1.InstanceReimagedCondition,
+								Status:             corev1.ConditionFalse,
+								Reason:             v1beta1.InstanceReimageWaitingForNodeReason,
+								LastTransitionTime: reimageStarted,
+							},
+						},
+					},
+				}
+			)
+			defer controller.Finish()
+
+			s, err := NewMachinePoolMachineScope(MachinePoolMachineScopeParams{
+				Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+				ClusterScope: &ClusterScope{
+					Cluster: &clusterv1.Cluster{},
+				},
+				MachinePool:             new(capiv1exp.MachinePool),
+				AzureMachinePool:        new(infrav1.AzureMachinePool),
+				AzureMachinePoolMachine: ampm,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			s.workloadNodeGetter = mockClient
+			mockClient.EXPECT().GetNodeByProviderID(gomock2.AContext(), FakeProviderID).Return(c.Node, nil)
+
+			ready, err := s.IsNodeReadyAfterReimage(context.TODO())
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ready).To(Equal(c.Want))
+		})
+	}
+}
+
+func TestMachinePoolMachineScope_RequestRemediation(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	_ = capiv1exp.AddToScheme(scheme)
+	_ = infrav1.AddToScheme(scheme)
+
+	ampm := &infrav1.AzureMachinePoolMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "ampm1",
+			Namespace:  "default",
+			Finalizers: []string{infrav1.AzureMachinePoolMachineFinalizer},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ampm).Build()
+	s, err := NewMachinePoolMachineScope(MachinePoolMachineScopeParams{
+		Client: fakeClient,
+		ClusterScope: &ClusterScope{
+			Cluster: &clusterv1.Cluster{},
+		},
+		MachinePool:             new(capiv1exp.MachinePool),
+		AzureMachinePool:        new(infrav1.AzureMachinePool),
+		AzureMachinePoolMachine: ampm,
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(s.RequestRemediation(context.TODO(), "spot instance was deallocated by an eviction")).To(Succeed())
+	deleted := &infrav1.AzureMachinePoolMachine{}
+	g.Expect(fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "ampm1"}, deleted)).To(Succeed())
+	g.Expect(deleted.DeletionTimestamp.IsZero()).To(BeFalse())
+}
+
+func withReadyTransition(node *corev1.Node, transition metav1.Time) *corev1.Node {
+	node.Status.Conditions[0].LastTransitionTime = transition
+	return node
+}
+
 func getReadyNode() *corev1.Node {
 	return &corev1.Node{
 		ObjectMeta: metav1.ObjectMeta{
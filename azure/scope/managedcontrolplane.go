@@ -179,6 +179,12 @@ func (s *ManagedControlPlaneScope) Authorizer() autorest.Authorizer {
 	return s.AzureClients.Authorizer
 }
 
+// SecondaryAuthorizer returns the Azure client Authorizer, as the resources of a managed cluster all live in its own
+// subscription.
+func (s *ManagedControlPlaneScope) SecondaryAuthorizer() autorest.Authorizer {
+	return s.Authorizer()
+}
+
 // PatchObject persists the cluster configuration and status.
 func (s *ManagedControlPlaneScope) PatchObject(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scope.ManagedControlPlaneScope.PatchObject")
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+)
+
+// declaredRoleAssignmentSpecs returns the specs of the role assignments declared for the identities of a virtual
+// machine or scale set. Their names are derived from the identity, the role and the scope, so that reconciling them is
+// idempotent and the role assignments of a user-assigned identity are shared by all the machines using it.
+func declaredRoleAssignmentSpecs(subscriptionID, resourceGroup, machineName, resourceType string, roleAssignments []infrav1.RoleAssignment) []azure.RoleAssignmentSpec {
+	specs := make([]azure.RoleAssignmentSpec, 0, len(roleAssignments))
+	for _, roleAssignment := range roleAssignments {
+		scope := roleAssignment.Scope
+		if scope == "" {
+			scope = azure.ResourceGroupID(subscriptionID, resourceGroup)
+		}
+		identityID := strings.TrimPrefix(roleAssignment.UserAssignedIdentity, azure.ProviderIDPrefix)
+		principal := identityID
+		if principal == "" {
+			principal = strings.Join([]string{subscriptionID, resourceGroup, resourceType, machineName}, "/")
+		}
+		role := roleAssignment.RoleDefinitionID + roleAssignment.RoleDefinitionName
+		name := uuid.NewSHA1(uuid.NameSpaceOID, []byte(strings.ToLower(strings.Join([]string{principal, role, scope}, "|"))))
+
+		specs = append(specs, azure.RoleAssignmentSpec{
+			MachineName:            machineName,
+			Name:                   name.String(),
+			ResourceType:           resourceType,
+			RoleDefinitionID:       roleAssignment.RoleDefinitionID,
+			RoleDefinitionName:     roleAssignment.RoleDefinitionName,
+			Scope:                  scope,
+			UserAssignedIdentityID: identityID,
+		})
+	}
+	return specs
+}
+
+// keyVaultSecretsUserRoleID is the ID of the built-in role granting read access to the secrets of a Key Vault.
+const keyVaultSecretsUserRoleID = "4633458b-17de-408a-b874-0445c86b69e6"
+
+// keyVaultForMachine returns the Key Vault of the cluster if the AzureMachine stores any of its secrets in it, or nil.
+// Machines running Windows keep getting their secrets in their custom data.
+func keyVaultForMachine(keyVault *infrav1.KeyVaultSpec, machine *infrav1.AzureMachine) *infrav1.KeyVaultSpec {
+	if keyVault == nil || machine.Spec.OSDisk.OSType == azure.WindowsOS {
+		return nil
+	}
+	if !keyVault.BootstrapData && !keyVault.CloudProviderCredentials {
+		return nil
+	}
+	return keyVault
+}
+
+// keyVaultRoleAssignments returns the role assignments granting the identity of an AzureMachine read access to its
+// secrets in the Key Vault of the cluster, each of them being scoped to a single secret.
+func keyVaultRoleAssignments(keyVault *infrav1.KeyVaultSpec, keyVaultID string, machine *infrav1.AzureMachine, controlPlane bool) []infrav1.RoleAssignment {
+	keyVault = keyVaultForMachine(keyVault, machine)
+	if keyVault == nil {
+		return nil
+	}
+
+	var secretNames []string
+	if keyVault.BootstrapData {
+		secretNames = append(secretNames, keyvaults.BootstrapDataSecretName(machine.Name))
+	}
+	if keyVault.CloudProviderCredentials {
+		secretNames = append(secretNames, keyvaults.CloudProviderConfigSecretName(cloudProviderConfigOwnerName(machine), controlPlane))
+	}
+
+	var identity string
+	if machine.Spec.Identity == infrav1.VMIdentityUserAssigned && len(machine.Spec.UserAssignedIdentities) > 0 {
+		identity = machine.Spec.UserAssignedIdentities[0].ProviderID
+	}
+
+	roleAssignments := make([]infrav1.RoleAssignment, 0, len(secretNames))
+	for _, secretName := range secretNames {
+		roleAssignments = append(roleAssignments, infrav1.RoleAssignment{
+			RoleDefinitionID:     keyVaultSecretsUserRoleID,
+			Scope:                keyVaultID + "/secrets/" + secretName,
+			UserAssignedIdentity: identity,
+		})
+	}
+	return roleAssignments
+}
+
+// keyVaultForMachinePool returns the Key Vault of the cluster if the AzureMachinePool fetches its cloud provider config
+// from it, or nil. The bootstrap data of a scale set is always sent as its custom data, and machine pools running
+// Windows keep getting their cloud provider config from their azure json secret.
+func keyVaultForMachinePool(keyVault *infrav1.KeyVaultSpec, machinePool *infrav1exp.AzureMachinePool) *infrav1.KeyVaultSpec {
+	if keyVault == nil || !keyVault.CloudProviderCredentials || machinePool.Spec.Template.OSDisk.OSType == azure.WindowsOS {
+		return nil
+	}
+	return keyVault
+}
+
+// keyVaultRoleAssignmentsForMachinePool returns the role assignment granting the identity of an AzureMachinePool read
+// access to its cloud provider config in the Key Vault of the cluster.
+func keyVaultRoleAssignmentsForMachinePool(keyVault *infrav1.KeyVaultSpec, keyVaultID string, machinePool *infrav1exp.AzureMachinePool) []infrav1.RoleAssignment {
+	if keyVaultForMachinePool(keyVault, machinePool) == nil {
+		return nil
+	}
+
+	var identity string
+	if machinePool.Spec.Identity == infrav1.VMIdentityUserAssigned && len(machinePool.Spec.UserAssignedIdentities) > 0 {
+		identity = machinePool.Spec.UserAssignedIdentities[0].ProviderID
+	}
+	return []infrav1.RoleAssignment{{
+		RoleDefinitionID:     keyVaultSecretsUserRoleID,
+		Scope:                keyVaultID + "/secrets/" + keyvaults.CloudProviderConfigSecretName(machinePool.Name, false),
+		UserAssignedIdentity: identity,
+	}}
+}
+
+// cloudProviderConfigOwnerName returns the name of the object owning the cloud provider config of an AzureMachine: the
+// AzureMachineTemplate it was cloned from, if any, or the AzureMachine itself.
+func cloudProviderConfigOwnerName(machine *infrav1.AzureMachine) string {
+	gvk := infrav1.GroupVersion.WithKind("AzureMachineTemplate")
+	if machine.GetAnnotations()[clusterv1.TemplateClonedFromGroupKindAnnotation] == gvk.GroupKind().String() {
+		if name := machine.GetAnnotations()[clusterv1.TemplateClonedFromNameAnnotation]; name != "" {
+			return name
+		}
+	}
+	return machine.Name
+}
+
+// isRoleAssignmentShared returns true if a role assignment of a user-assigned identity is also declared by another
+// AzureMachine or AzureMachinePool of the cluster that is not being deleted, and must thus be kept.
+func isRoleAssignmentShared(ctx context.Context, c client.Client, owner client.Object, clusterName, subscriptionID, resourceGroup string, keyVault *infrav1.KeyVaultSpec, keyVaultID string, spec azure.RoleAssignmentSpec) (bool, error) {
+	listOptions := []client.ListOption{
+		client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{clusterv1.ClusterLabelName: clusterName},
+	}
+
+	isShared := func(obj client.Object, machineName, resourceType string, roleAssignments []infrav1.RoleAssignment) bool {
+		if obj.GetUID() == owner.GetUID() || !obj.GetDeletionTimestamp().IsZero() {
+			return false
+		}
+		for _, other := range declaredRoleAssignmentSpecs(subscriptionID, resourceGroup, machineName, resourceType, roleAssignments) {
+			if other.Name == spec.Name {
+				return true
+			}
+		}
+		return false
+	}
+
+	machines := &infrav1.AzureMachineList{}
+	if err := c.List(ctx, machines, listOptions...); err != nil {
+		return false, errors.Wrap(err, "failed to list AzureMachines")
+	}
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		_, controlPlane := machine.Labels[clusterv1.MachineControlPlaneLabelName]
+		roleAssignments := append([]infrav1.RoleAssignment{}, machine.Spec.RoleAssignments...)
+		roleAssignments = append(roleAssignments, keyVaultRoleAssignments(keyVault, keyVaultID, machine, controlPlane)...)
+		if isShared(machine, machine.Name, azure.VirtualMachine, roleAssignments) {
+			return true, nil
+		}
+	}
+
+	machinePools := &infrav1exp.AzureMachinePoolList{}
+	if err := c.List(ctx, machinePools, listOptions...); err != nil {
+		return false, errors.Wrap(err, "failed to list AzureMachinePools")
+	}
+	for i := range machinePools.Items {
+		machinePool := &machinePools.Items[i]
+		roleAssignments := append([]infrav1.RoleAssignment{}, machinePool.Spec.RoleAssignments...)
+		roleAssignments = append(roleAssignments, keyVaultRoleAssignmentsForMachinePool(keyVault, keyVaultID, machinePool)...)
+		if isShared(machinePool, machinePool.Name, azure.VirtualMachineScaleSet, roleAssignments) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
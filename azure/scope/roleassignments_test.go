@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+)
+
+const fakeUserAssignedIdentity = "azure:///subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/acr-pull"
+
+func TestDeclaredRoleAssignmentSpecs(t *testing.T) {
+	g := NewWithT(t)
+
+	roleAssignments := []infrav1.RoleAssignment{
+		{
+			RoleDefinitionName: "Reader",
+		},
+		{
+			RoleDefinitionName:   "AcrPull",
+			Scope:                "/subscriptions/123/resourceGroups/acr-rg/providers/Microsoft.ContainerRegistry/registries/myacr",
+			UserAssignedIdentity: fakeUserAssignedIdentity,
+		},
+	}
+
+	specs := declaredRoleAssignmentSpecs("123", "my-rg", "machine-1", azure.VirtualMachine, roleAssignments)
+	g.Expect(specs).To(HaveLen(2))
+	g.Expect(specs[0].MachineName).To(Equal("machine-1"))
+	g.Expect(specs[0].ResourceType).To(Equal(azure.VirtualMachine))
+	g.Expect(specs[0].RoleDefinitionName).To(Equal("Reader"))
+	g.Expect(specs[0].Scope).To(Equal("/subscriptions/123/resourceGroups/my-rg"))
+	g.Expect(specs[0].UserAssignedIdentityID).To(BeEmpty())
+	g.Expect(specs[1].Scope).To(Equal("/subscriptions/123/resourceGroups/acr-rg/providers/Microsoft.ContainerRegistry/registries/myacr"))
+	g.Expect(specs[1].UserAssignedIdentityID).To(Equal("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/acr-pull"))
+
+	// the names are stable across reconciles
+	g.Expect(declaredRoleAssignmentSpecs("123", "my-rg", "machine-1", azure.VirtualMachine, roleAssignments)).To(Equal(specs))
+
+	// the role assignments of the system-assigned identities of different machines differ, but the ones of a
+	// user-assigned identity are shared
+	otherSpecs := declaredRoleAssignmentSpecs("123", "my-rg", "machine-2", azure.VirtualMachine, roleAssignments)
+	g.Expect(otherSpecs[0].Name).NotTo(Equal(specs[0].Name))
+	g.Expect(otherSpecs[1].Name).To(Equal(specs[1].Name))
+}
+
+func TestIsRoleAssignmentShared(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = infrav1exp.AddToScheme(scheme)
+
+	roleAssignments := []infrav1.RoleAssignment{
+		{
+			RoleDefinitionName:   "AcrPull",
+			UserAssignedIdentity: fakeUserAssignedIdentity,
+		},
+	}
+	newMachine := func(name string, roleAssignments []infrav1.RoleAssignment) *infrav1.AzureMachine {
+		return &infrav1.AzureMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(name),
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "my-cluster"},
+			},
+			Spec: infrav1.AzureMachineSpec{
+				Identity:               infrav1.VMIdentityUserAssigned,
+				UserAssignedIdentities: []infrav1.UserAssignedIdentity{{ProviderID: fakeUserAssignedIdentity}},
+				RoleAssignments:        roleAssignments,
+			},
+		}
+	}
+	owner := newMachine("machine-1", roleAssignments)
+	spec := declaredRoleAssignmentSpecs("123", "my-rg", owner.Name, azure.VirtualMachine, roleAssignments)[0]
+
+	tests := []struct {
+		name    string
+		objects []client.Object
+		want    bool
+	}{
+		{
+			name:    "only machine using the role assignment",
+			objects: []client.Object{owner, newMachine("machine-2", nil)},
+			want:    false,
+		},
+		{
+			name:    "role assignment used by another machine",
+			objects: []client.Object{owner, newMachine("machine-2", roleAssignments)},
+			want:    true,
+		},
+		{
+			name: "role assignment used by a machine pool",
+			objects: []client.Object{owner, &infrav1exp.AzureMachinePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pool-1",
+					Namespace: "default",
+					Labels:    map[string]string{clusterv1.ClusterLabelName: "my-cluster"},
+				},
+				Spec: infrav1exp.AzureMachinePoolSpec{
+					Identity:               infrav1.VMIdentityUserAssigned,
+					UserAssignedIdentities: []infrav1.UserAssignedIdentity{{ProviderID: fakeUserAssignedIdentity}},
+					RoleAssignments:        roleAssignments,
+				},
+			}},
+			want: true,
+		},
+		{
+			name: "role assignment used by a machine of another cluster",
+			objects: []client.Object{owner, func() client.Object {
+				machine := newMachine("machine-2", roleAssignments)
+				machine.Labels[clusterv1.ClusterLabelName] = "other-cluster"
+				return machine
+			}()},
+			want: false,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+
+			shared, err := isRoleAssignmentShared(context.Background(), c, owner, "my-cluster", "123", "my-rg", nil, "", spec)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(shared).To(Equal(tc.want))
+		})
+	}
+}
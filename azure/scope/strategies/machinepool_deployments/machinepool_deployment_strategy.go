@@ -27,6 +27,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -48,8 +49,24 @@ type (
 		Type() infrav1exp.AzureMachinePoolDeploymentStrategyType
 	}
 
+	// ReimageSelector is the ability to select machines to be reimaged in place with the latest model with respect to a
+	// desired number of replicas.
+	ReimageSelector interface {
+		SelectMachinesToReimage(ctx context.Context, desiredReplicas int32, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) ([]infrav1exp.AzureMachinePoolMachine, error)
+	}
+
 	rollingUpdateStrategy struct {
 		infrav1exp.MachineRollingUpdateDeployment
+		// maxReplacements caps the number of machines without the latest model replaced within the disruption budget,
+		// or is nil if the replacements are not capped
+		maxReplacements *int
+	}
+
+	reimageInPlaceStrategy struct {
+		infrav1exp.MachineReimageInPlaceDeployment
+		// maxReplacements caps the number of machines without the latest model reimaged at the same time, or is nil if
+		// the reimages are not capped
+		maxReplacements *int
 	}
 )
 
@@ -66,6 +83,15 @@ func NewMachinePoolDeploymentStrategy(strategy infrav1exp.AzureMachinePoolDeploy
 		return &rollingUpdateStrategy{
 			MachineRollingUpdateDeployment: *rollingUpdate,
 		}
+	case infrav1exp.ReimageInPlaceAzureMachinePoolDeploymentStrategyType:
+		reimageInPlace := strategy.ReimageInPlace
+		if reimageInPlace == nil {
+			reimageInPlace = &infrav1exp.MachineReimageInPlaceDeployment{}
+		}
+
+		return &reimageInPlaceStrategy{
+			MachineReimageInPlaceDeployment: *reimageInPlace,
+		}
 	default:
 		// default to a rolling update strategy if unknown type
 		return &rollingUpdateStrategy{
@@ -74,6 +100,24 @@ func NewMachinePoolDeploymentStrategy(strategy infrav1exp.AzureMachinePoolDeploy
 	}
 }
 
+// LimitReplacements caps the number of machines without the latest model the strategy replaces within its disruption
+// budget, e.g. while the canary batch of a rollout is replaced or the rollout is paused. Machines deleted to lower an
+// over-provisioned replica count are not replaced, so they do not count towards the limit.
+func LimitReplacements(selector TypedDeleteSelector, maxReplacements int) TypedDeleteSelector {
+	switch strategy := selector.(type) {
+	case *rollingUpdateStrategy:
+		limited := *strategy
+		limited.maxReplacements = &maxReplacements
+		return &limited
+	case *reimageInPlaceStrategy:
+		limited := *strategy
+		limited.maxReplacements = &maxReplacements
+		return &limited
+	default:
+		return selector
+	}
+}
+
 // Type is the AzureMachinePoolDeploymentStrategyType for the strategy.
 func (rollingUpdateStrategy *rollingUpdateStrategy) Type() infrav1exp.AzureMachinePoolDeploymentStrategyType {
 	return infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType
@@ -117,21 +161,25 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 	}
 
 	var (
-		order = func() func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+		order = orderDeleteAnnotatedFirst(func() func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
 			switch rollingUpdateStrategy.DeletePolicy {
 			case infrav1exp.OldestDeletePolicyType:
 				return orderByOldest
 			case infrav1exp.NewestDeletePolicyType:
 				return orderByNewest
+			case infrav1exp.ZoneBalancedDeletePolicyType:
+				return orderByZoneBalance(machinesByProviderID)
+			case infrav1exp.UnhealthyFirstDeletePolicyType:
+				return orderByUnhealthyFirst
 			default:
 				return orderRandom
 			}
-		}()
+		}())
 		log                        = ctrl.LoggerFrom(ctx).V(4)
 		failedMachines             = order(getFailedMachines(machinesByProviderID))
 		deletingMachines           = order(getDeletingMachines(machinesByProviderID))
 		readyMachines              = order(getReadyMachines(machinesByProviderID))
-		machinesWithoutLatestModel = order(getMachinesWithoutLatestModel(machinesByProviderID))
+		machinesWithoutLatestModel = order(getUnprotectedMachines(getMachinesWithoutLatestModel(machinesByProviderID)))
 		overProvisionCount         = len(readyMachines) - int(desiredReplicaCount)
 		disruptionBudget           = func() int {
 			if maxUnavailable > int(desiredReplicaCount) {
@@ -140,6 +188,21 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 
 			return len(readyMachines) - int(desiredReplicaCount) + maxUnavailable
 		}()
+		replacementBudget = func() int {
+			if rollingUpdateStrategy.maxReplacements != nil && *rollingUpdateStrategy.maxReplacements < disruptionBudget {
+				return *rollingUpdateStrategy.maxReplacements
+			}
+
+			return disruptionBudget
+		}()
+		notReadyMachines = func() []infrav1exp.AzureMachinePoolMachine {
+			// only the UnhealthyFirst policy considers machines whose node is not Ready as surplus
+			if rollingUpdateStrategy.DeletePolicy != infrav1exp.UnhealthyFirstDeletePolicyType {
+				return nil
+			}
+
+			return order(getUnprotectedMachines(getNotReadyMachines(machinesByProviderID)))
+		}()
 	)
 
 	log.Info("selecting machines to delete",
@@ -171,27 +234,52 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 
 	// we have too many machines, let's choose the oldest to remove
 	if overProvisionCount > 0 {
-		var toDelete []infrav1exp.AzureMachinePoolMachine
-		log.Info("over-provisioned", "desiredReplicaCount", desiredReplicaCount, "overProvisionCount", overProvisionCount, "machinesWithoutLatestModel", getProviderIDs(machinesWithoutLatestModel))
-		// we are over-provisioned try to remove old models
-		for _, v := range machinesWithoutLatestModel {
-			if len(toDelete) >= overProvisionCount {
-				return toDelete, nil
+		var (
+			toDelete []infrav1exp.AzureMachinePoolMachine
+			selected = make(map[string]bool)
+		)
+		// selectMachines appends the machines accepted by the filter to toDelete, skipping the ones already selected,
+		// and returns whether enough machines are selected
+		selectMachines := func(machines []infrav1exp.AzureMachinePoolMachine, filter func(machine infrav1exp.AzureMachinePoolMachine) bool) bool {
+			for _, v := range machines {
+				if len(toDelete) >= overProvisionCount {
+					return true
+				}
+
+				if !selected[v.Spec.ProviderID] && filter(v) {
+					selected[v.Spec.ProviderID] = true
+					toDelete = append(toDelete, v)
+				}
 			}
 
-			toDelete = append(toDelete, v)
+			return len(toDelete) >= overProvisionCount
 		}
 
-		log.Info("over-provisioned ready", "desiredReplicaCount", desiredReplicaCount, "overProvisionCount", overProvisionCount, "readyMachines", getProviderIDs(readyMachines))
-		// remove ready machines
-		for _, v := range readyMachines {
-			if len(toDelete) >= overProvisionCount {
-				return toDelete, nil
-			}
+		log.Info("over-provisioned", "desiredReplicaCount", desiredReplicaCount, "overProvisionCount", overProvisionCount, "machinesWithoutLatestModel", getProviderIDs(machinesWithoutLatestModel))
+		// we are over-provisioned, remove machines explicitly marked for deletion first, whether their node is Ready or not
+		markedForDeletion := func(v infrav1exp.AzureMachinePoolMachine) bool {
+			return hasDeleteMachineAnnotation(v) && !isProtected(v)
+		}
+		if selectMachines(readyMachines, markedForDeletion) || selectMachines(order(getNotReadyMachines(machinesByProviderID)), markedForDeletion) {
+			return toDelete, nil
+		}
 
-			toDelete = append(toDelete, v)
+		// then remove machines whose node is not Ready
+		if selectMachines(notReadyMachines, func(infrav1exp.AzureMachinePoolMachine) bool { return true }) {
+			return toDelete, nil
 		}
 
+		// then try to remove old models
+		if selectMachines(machinesWithoutLatestModel, func(v infrav1exp.AzureMachinePoolMachine) bool { return !hasDeleteMachineAnnotation(v) }) {
+			return toDelete, nil
+		}
+
+		log.Info("over-provisioned ready", "desiredReplicaCount", desiredReplicaCount, "overProvisionCount", overProvisionCount, "readyMachines", getProviderIDs(readyMachines))
+		// remove ready machines
+		selectMachines(readyMachines, func(v infrav1exp.AzureMachinePoolMachine) bool {
+			return !hasDeleteMachineAnnotation(v) && !isProtected(v)
+		})
+
 		return toDelete, nil
 	}
 
@@ -205,14 +293,19 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 		return []infrav1exp.AzureMachinePoolMachine{}, nil
 	}
 
+	if replacementBudget <= 0 {
+		log.Info("exit early since the rollout does not allow replacing more machines", "disruptionBudget", disruptionBudget, "maxReplacements", *rollingUpdateStrategy.maxReplacements)
+		return []infrav1exp.AzureMachinePoolMachine{}, nil
+	}
+
 	var toDelete []infrav1exp.AzureMachinePoolMachine
-	log.Info("removing ready machines within disruption budget", "desiredReplicaCount", desiredReplicaCount, "maxUnavailable", maxUnavailable, "readyMachines", getProviderIDs(readyMachines), "readyMachinesCount", len(readyMachines))
+	log.Info("removing ready machines within disruption budget", "desiredReplicaCount", desiredReplicaCount, "maxUnavailable", maxUnavailable, "replacementBudget", replacementBudget, "readyMachines", getProviderIDs(readyMachines), "readyMachinesCount", len(readyMachines))
 	for _, v := range readyMachines {
-		if len(toDelete) >= disruptionBudget {
+		if len(toDelete) >= replacementBudget {
 			return toDelete, nil
 		}
 
-		if !v.Status.LatestModelApplied {
+		if !v.Status.LatestModelApplied && !isProtected(v) {
 			toDelete = append(toDelete, v)
 		}
 	}
@@ -221,6 +314,105 @@ func (rollingUpdateStrategy rollingUpdateStrategy) SelectMachinesToDelete(ctx co
 	return toDelete, nil
 }
 
+// Type is the AzureMachinePoolDeploymentStrategyType for the strategy.
+func (reimageInPlaceStrategy *reimageInPlaceStrategy) Type() infrav1exp.AzureMachinePoolDeploymentStrategyType {
+	return infrav1exp.ReimageInPlaceAzureMachinePoolDeploymentStrategyType
+}
+
+// maxUnavailable calculates the maximum number of replicas which can be unavailable at any time. At least one replica
+// is allowed to be unavailable, since reimaging a machine makes it unavailable.
+func (reimageInPlaceStrategy *reimageInPlaceStrategy) maxUnavailable(desiredReplicaCount int) (int, error) {
+	if reimageInPlaceStrategy.MaxUnavailable == nil {
+		return 1, nil
+	}
+
+	val, err := intstr.GetScaledValueFromIntOrPercent(reimageInPlaceStrategy.MaxUnavailable, desiredReplicaCount, false)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get scaled value or int from maxUnavailable")
+	}
+
+	if val < 1 {
+		return 1, nil
+	}
+
+	return val, nil
+}
+
+// SelectMachinesToDelete selects failed and deleting machines, and the machines to delete to lower an over-provisioned
+// replica count according to the DeletePolicy. Machines without the latest model are reimaged rather than deleted.
+func (reimageInPlaceStrategy *reimageInPlaceStrategy) SelectMachinesToDelete(ctx context.Context, desiredReplicaCount int32, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) ([]infrav1exp.AzureMachinePoolMachine, error) {
+	// a rolling update without any unavailable machines never replaces a machine with an older model
+	zero := intstr.FromInt(0)
+	rollingUpdate := rollingUpdateStrategy{
+		MachineRollingUpdateDeployment: infrav1exp.MachineRollingUpdateDeployment{
+			MaxUnavailable: &zero,
+			DeletePolicy:   reimageInPlaceStrategy.DeletePolicy,
+		},
+	}
+
+	return rollingUpdate.SelectMachinesToDelete(ctx, desiredReplicaCount, machinesByProviderID)
+}
+
+// SelectMachinesToReimage selects the ready machines without the latest model to reimage in place, oldest first, so
+// that no more than maxUnavailable machines are unavailable or being reimaged at any time.
+func (reimageInPlaceStrategy *reimageInPlaceStrategy) SelectMachinesToReimage(ctx context.Context, desiredReplicaCount int32, machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) ([]infrav1exp.AzureMachinePoolMachine, error) {
+	ctx, _, done := tele.StartSpanWithLogger(
+		ctx,
+		"strategies.reimageInPlaceStrategy.SelectMachinesToReimage",
+	)
+	defer done()
+
+	maxUnavailable, err := reimageInPlaceStrategy.maxUnavailable(int(desiredReplicaCount))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		log         = ctrl.LoggerFrom(ctx).V(4)
+		unavailable int
+		reimaging   int
+		candidates  []infrav1exp.AzureMachinePoolMachine
+	)
+	for _, v := range machinesByProviderID {
+		switch {
+		case isReimaging(v):
+			unavailable++
+			if !v.Status.LatestModelApplied {
+				reimaging++
+			}
+		case v.Status.ProvisioningState == nil || *v.Status.ProvisioningState != infrav1.Succeeded || !v.Status.Ready:
+			unavailable++
+		case !v.Status.LatestModelApplied && !hasDeleteMachineAnnotation(v) && !isProtectedFromScaleSetActions(v):
+			candidates = append(candidates, v)
+		}
+	}
+
+	budget := maxUnavailable - unavailable
+	if reimageInPlaceStrategy.maxReplacements != nil && *reimageInPlaceStrategy.maxReplacements-reimaging < budget {
+		// machines still being reimaged do not run the latest model yet, so they count towards the limit
+		budget = *reimageInPlaceStrategy.maxReplacements - reimaging
+	}
+
+	log.Info("selecting machines to reimage",
+		"desiredReplicaCount", desiredReplicaCount,
+		"maxUnavailable", maxUnavailable,
+		"unavailable", unavailable,
+		"budget", budget,
+		"machinesWithoutTheLatestModel", len(candidates),
+	)
+
+	if budget <= 0 || len(candidates) == 0 {
+		return []infrav1exp.AzureMachinePoolMachine{}, nil
+	}
+
+	candidates = orderByOldest(candidates)
+	if len(candidates) > budget {
+		candidates = candidates[:budget]
+	}
+
+	return candidates, nil
+}
+
 func getFailedMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
 	var machines []infrav1exp.AzureMachinePoolMachine
 	for _, v := range machinesByProviderID {
@@ -257,6 +449,30 @@ func getReadyMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoo
 	return readyMachines
 }
 
+func getNotReadyMachines(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	var notReadyMachines []infrav1exp.AzureMachinePoolMachine
+	for _, v := range machinesByProviderID {
+		// not ready status, with provisioning state Succeeded
+		if !v.Status.Ready && v.Status.ProvisioningState != nil && *v.Status.ProvisioningState == infrav1.Succeeded {
+			notReadyMachines = append(notReadyMachines, v)
+		}
+	}
+
+	return notReadyMachines
+}
+
+// getUnprotectedMachines filters out the machines protected from scale in, which are never selected for deletion.
+func getUnprotectedMachines(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	var unprotectedMachines []infrav1exp.AzureMachinePoolMachine
+	for _, v := range machines {
+		if !isProtected(v) {
+			unprotectedMachines = append(unprotectedMachines, v)
+		}
+	}
+
+	return unprotectedMachines
+}
+
 func getMachinesWithoutLatestModel(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
 	var machinesWithLatestModel []infrav1exp.AzureMachinePoolMachine
 	for _, v := range machinesByProviderID {
@@ -290,6 +506,93 @@ func orderRandom(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.Azu
 	return machines
 }
 
+// orderByZoneBalance orders machines so that removing them in order keeps the machines in machinesByProviderID spread
+// as evenly as possible across availability zones. Machines in the same zone are ordered oldest first.
+func orderByZoneBalance(machinesByProviderID map[string]infrav1exp.AzureMachinePoolMachine) func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	return func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+		machinesPerZone := make(map[string]int)
+		for _, v := range machinesByProviderID {
+			// failed and deleting machines are going away regardless, so they do not count towards a zone
+			if v.Status.ProvisioningState != nil && (*v.Status.ProvisioningState == infrav1.Failed || *v.Status.ProvisioningState == infrav1.Deleting) {
+				continue
+			}
+
+			machinesPerZone[v.Status.AvailabilityZone]++
+		}
+
+		remaining := orderByOldest(append([]infrav1exp.AzureMachinePoolMachine{}, machines...))
+		ordered := make([]infrav1exp.AzureMachinePoolMachine, 0, len(machines))
+		for len(remaining) > 0 {
+			// pick the oldest machine in the zone which currently has the most machines
+			next := 0
+			for i := range remaining {
+				if machinesPerZone[remaining[i].Status.AvailabilityZone] > machinesPerZone[remaining[next].Status.AvailabilityZone] {
+					next = i
+				}
+			}
+
+			machinesPerZone[remaining[next].Status.AvailabilityZone]--
+			ordered = append(ordered, remaining[next])
+			remaining = append(remaining[:next], remaining[next+1:]...)
+		}
+
+		return ordered
+	}
+}
+
+// orderByUnhealthyFirst orders machines whose node is not Ready or is cordoned first, then by oldest creation date.
+func orderByUnhealthyFirst(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	sort.SliceStable(machines, func(i, j int) bool {
+		iUnhealthy, jUnhealthy := isUnhealthy(machines[i]), isUnhealthy(machines[j])
+		if iUnhealthy != jUnhealthy {
+			return iUnhealthy
+		}
+
+		return machines[j].ObjectMeta.CreationTimestamp.After(machines[i].ObjectMeta.CreationTimestamp.Time)
+	})
+
+	return machines
+}
+
+// orderDeleteAnnotatedFirst wraps an ordering so that machines carrying the delete machine annotation come first while
+// otherwise preserving the wrapped order.
+func orderDeleteAnnotatedFirst(order func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine) func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+	return func(machines []infrav1exp.AzureMachinePoolMachine) []infrav1exp.AzureMachinePoolMachine {
+		machines = order(machines)
+		sort.SliceStable(machines, func(i, j int) bool {
+			return hasDeleteMachineAnnotation(machines[i]) && !hasDeleteMachineAnnotation(machines[j])
+		})
+
+		return machines
+	}
+}
+
+func isUnhealthy(machine infrav1exp.AzureMachinePoolMachine) bool {
+	return !machine.Status.Ready || machine.Status.Cordoned
+}
+
+func isReimaging(machine infrav1exp.AzureMachinePoolMachine) bool {
+	_, ok := machine.Annotations[infrav1exp.ReimageMachineAnnotation]
+	return ok
+}
+
+// isProtected returns true if the machine is protected from scale in, either explicitly or as part of its protection
+// from all the actions of its scale set.
+func isProtected(machine infrav1exp.AzureMachinePoolMachine) bool {
+	_, ok := machine.Annotations[infrav1exp.ProtectFromScaleInAnnotation]
+	return ok || isProtectedFromScaleSetActions(machine)
+}
+
+func isProtectedFromScaleSetActions(machine infrav1exp.AzureMachinePoolMachine) bool {
+	_, ok := machine.Annotations[infrav1exp.ProtectFromScaleSetActionsAnnotation]
+	return ok
+}
+
+func hasDeleteMachineAnnotation(machine infrav1exp.AzureMachinePoolMachine) bool {
+	_, ok := machine.Annotations[clusterv1.DeleteMachineAnnotation]
+	return ok
+}
+
 func getProviderIDs(machines []infrav1exp.AzureMachinePoolMachine) []string {
 	ids := make([]string, len(machines))
 	for i, machine := range machines {
@@ -28,6 +28,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 func TestMachinePoolRollingUpdateStrategy_Type(t *testing.T) {
@@ -38,6 +39,16 @@ func TestMachinePoolRollingUpdateStrategy_Type(t *testing.T) {
 	g.Expect(strategy.Type()).To(Equal(infrav1exp.RollingUpdateAzureMachinePoolDeploymentStrategyType))
 }
 
+func TestMachinePoolReimageInPlaceStrategy_Type(t *testing.T) {
+	g := NewWithT(t)
+	strategy := NewMachinePoolDeploymentStrategy(infrav1exp.AzureMachinePoolDeploymentStrategy{
+		Type: infrav1exp.ReimageInPlaceAzureMachinePoolDeploymentStrategyType,
+	})
+	g.Expect(strategy.Type()).To(Equal(infrav1exp.ReimageInPlaceAzureMachinePoolDeploymentStrategyType))
+	_, isSurger := strategy.(Surger)
+	g.Expect(isSurger).To(BeFalse())
+}
+
 func TestMachinePoolRollingUpdateStrategy_Surge(t *testing.T) {
 	var (
 		two           = intstr.FromInt(2)
@@ -193,7 +204,7 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{}),
 			desiredReplicas: 1,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
 			},
 			want: Equal([]infrav1exp.AzureMachinePoolMachine{}),
 		},
@@ -202,12 +213,12 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{}),
 			desiredReplicas: 2,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			},
 			want: Equal([]infrav1exp.AzureMachinePoolMachine{
-				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			}),
 		},
 		{
@@ -215,12 +226,12 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
 			desiredReplicas: 2,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
 			},
 			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
-				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
 			}),
 		},
 		{
@@ -228,14 +239,14 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
 			desiredReplicas: 2,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
-				"bar": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
 			},
 			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
-				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
-				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
 			}),
 		},
 		{
@@ -243,14 +254,14 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.NewestDeletePolicyType}),
 			desiredReplicas: 2,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
-				"bar": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
 			},
 			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
-				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
-				makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
 			}),
 		},
 		{
@@ -258,12 +269,12 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &one}),
 			desiredReplicas: 3,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			},
 			want: Equal([]infrav1exp.AzureMachinePoolMachine{
-				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			}),
 		},
 		{
@@ -271,9 +282,9 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &one}),
 			desiredReplicas: 3,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
 			},
 			want: HaveLen(0),
 		},
@@ -282,13 +293,51 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &two}),
 			desiredReplicas: 3,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: ConsistOf(
+				makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			),
+		},
+		{
+			name:            "if maxUnavailable is 2, but the replacements are limited to 1, delete 1.",
+			strategy:        LimitReplacements(makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &two}), 1),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: SatisfyAny(
+				Equal([]infrav1exp.AzureMachinePoolMachine{makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded})}),
+				Equal([]infrav1exp.AzureMachinePoolMachine{makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded})}),
+			),
+		},
+		{
+			name:            "if the replacements are limited to 0, delete nothing.",
+			strategy:        LimitReplacements(makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &two}), 0),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+		{
+			name:            "if over-provisioned, select a machine with an out-of-date model even if the replacements are limited to 0",
+			strategy:        LimitReplacements(makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{}), 0),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
 			},
 			want: Equal([]infrav1exp.AzureMachinePoolMachine{
-				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
-				makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			}),
 		},
 		{
@@ -296,9 +345,9 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &fortyFivePercent}),
 			desiredReplicas: 3,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
 			},
 			want: HaveLen(1),
 		},
@@ -307,12 +356,172 @@ func TestMachinePoolRollingUpdateStrategy_SelectMachinesToDelete(t *testing.T) {
 			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &thirtyPercent}),
 			desiredReplicas: 3,
 			input: map[string]infrav1exp.AzureMachinePoolMachine{
-				"foo": makeAMPM(ampmOptions{Ready: true, LatestModel: true, ProvisioningState: succeeded}),
-				"bin": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
-				"baz": makeAMPM(ampmOptions{Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+
+		{
+			name:            "if over-provisioned with ZoneBalanced, select machines from the most populated zone",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.ZoneBalancedDeletePolicyType}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "1", CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "3", CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+				"qux": makeAMPM(ampmOptions{ProviderID: "qux", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(5 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned with ZoneBalanced, alternate zones once they are balanced",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.ZoneBalancedDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "1", CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "1", CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "1", CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, Zone: "2", CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned with UnhealthyFirst, select a cordoned machine before older ones",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.UnhealthyFirstDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, Cordoned: true, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, Cordoned: true, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned with UnhealthyFirst, select a machine whose node is not Ready",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.UnhealthyFirstDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned with UnhealthyFirst, select a machine whose node is not Ready and has an out-of-date model only once",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.UnhealthyFirstDeletePolicyType}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if not over-provisioned with UnhealthyFirst, do not select a machine whose node is not Ready",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.UnhealthyFirstDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: true, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+		{
+			name:            "if over-provisioned, select a machine with the delete machine annotation before an out-of-date model",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned, select a machine with the delete machine annotation whose node is not Ready",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: true, ProvisioningState: succeeded, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: true, ProvisioningState: succeeded, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(4 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if maxUnavailable is 1, select an out-of-date machine with the delete machine annotation first",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &one, DeletePolicy: infrav1exp.NewestDeletePolicyType}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned, do not select a machine protected from scale in",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, Protection: infrav1exp.ScaleInInstanceProtection, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bin": makeAMPM(ampmOptions{ProviderID: "bin", Ready: true, LatestModel: true, ProvisioningState: succeeded, Protection: infrav1exp.ScaleSetActionsInstanceProtection, DeleteAnnotation: true, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(3 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if over-provisioned with only protected machines, select nothing",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded, Protection: infrav1exp.ScaleInInstanceProtection}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded, Protection: infrav1exp.ScaleInInstanceProtection}),
 			},
 			want: HaveLen(0),
 		},
+		{
+			name:            "if maxUnavailable is 1, do not replace a protected machine with an out-of-date model",
+			strategy:        makeRollingUpdateStrategy(infrav1exp.MachineRollingUpdateDeployment{MaxUnavailable: &one, DeletePolicy: infrav1exp.OldestDeletePolicyType}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, Protection: infrav1exp.ScaleInInstanceProtection, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+			},
+			want: gomega.DiffEq([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+			}),
+		},
 	}
 
 	for _, tt := range tests {
@@ -335,22 +544,222 @@ func makeRollingUpdateStrategy(rolling infrav1exp.MachineRollingUpdateDeployment
 	}
 }
 
+func TestMachinePoolReimageInPlaceStrategy_SelectMachinesToReimage(t *testing.T) {
+	var (
+		two       = intstr.FromInt(2)
+		succeeded = infrav1.Succeeded
+		baseTime  = time.Now().Add(-24 * time.Hour).Truncate(time.Microsecond)
+	)
+
+	tests := []struct {
+		name            string
+		strategy        TypedDeleteSelector
+		input           map[string]infrav1exp.AzureMachinePoolMachine
+		desiredReplicas int32
+		want            types.GomegaMatcher
+	}{
+		{
+			name:            "if all machines run the latest model, reimage nothing",
+			strategy:        makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+		{
+			name:            "if maxUnavailable defaults to 1, reimage the oldest machine without the latest model",
+			strategy:        makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(2 * time.Hour))}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: true, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime)}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "if a machine is being reimaged, do not reimage another one with maxUnavailable 1",
+			strategy:        makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, Reimaging: true}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+		{
+			name:            "if a machine is not ready, it counts towards maxUnavailable",
+			strategy:        makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{MaxUnavailable: &two}),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: false, ProvisioningState: succeeded}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(1),
+		},
+		{
+			name:            "if the reimages are limited by a canary batch, reimages in progress count towards the limit",
+			strategy:        LimitReplacements(makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{MaxUnavailable: &two}), 1),
+			desiredReplicas: 3,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, Reimaging: true}),
+				"baz": makeAMPM(ampmOptions{ProviderID: "baz", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+		{
+			name:            "do not reimage machines protected from scale set actions",
+			strategy:        makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{}),
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, Protection: infrav1exp.ScaleSetActionsInstanceProtection, CreationTime: metav1.NewTime(baseTime)}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, Protection: infrav1exp.ScaleInInstanceProtection, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded, Protection: infrav1exp.ScaleInInstanceProtection, CreationTime: metav1.NewTime(baseTime.Add(1 * time.Hour))}),
+			}),
+		},
+		{
+			name:            "do not reimage machines marked for deletion",
+			strategy:        makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{}),
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded, DeleteAnnotation: true}),
+			},
+			want: HaveLen(0),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			reimageSelector, ok := tt.strategy.(ReimageSelector)
+			g.Expect(ok).To(BeTrue())
+			got, err := reimageSelector.SelectMachinesToReimage(context.Background(), tt.desiredReplicas, tt.input)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(tt.want)
+		})
+	}
+}
+
+func TestMachinePoolReimageInPlaceStrategy_SelectMachinesToDelete(t *testing.T) {
+	var (
+		succeeded = infrav1.Succeeded
+		failed    = infrav1.Failed
+	)
+
+	tests := []struct {
+		name            string
+		input           map[string]infrav1exp.AzureMachinePoolMachine
+		desiredReplicas int32
+		want            types.GomegaMatcher
+	}{
+		{
+			name:            "do not delete machines without the latest model",
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: HaveLen(0),
+		},
+		{
+			name:            "if over-provisioned, delete a machine without the latest model",
+			desiredReplicas: 1,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: true, LatestModel: false, ProvisioningState: succeeded}),
+			}),
+		},
+		{
+			name:            "delete failed machines",
+			desiredReplicas: 2,
+			input: map[string]infrav1exp.AzureMachinePoolMachine{
+				"foo": makeAMPM(ampmOptions{ProviderID: "foo", Ready: true, LatestModel: true, ProvisioningState: succeeded}),
+				"bar": makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: false, ProvisioningState: failed}),
+			},
+			want: Equal([]infrav1exp.AzureMachinePoolMachine{
+				makeAMPM(ampmOptions{ProviderID: "bar", Ready: false, LatestModel: false, ProvisioningState: failed}),
+			}),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			strategy := makeReimageInPlaceStrategy(infrav1exp.MachineReimageInPlaceDeployment{DeletePolicy: infrav1exp.OldestDeletePolicyType})
+			got, err := strategy.SelectMachinesToDelete(context.Background(), tt.desiredReplicas, tt.input)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(tt.want)
+		})
+	}
+}
+
+func makeReimageInPlaceStrategy(reimage infrav1exp.MachineReimageInPlaceDeployment) *reimageInPlaceStrategy {
+	return &reimageInPlaceStrategy{
+		MachineReimageInPlaceDeployment: reimage,
+	}
+}
+
 type ampmOptions struct {
+	ProviderID        string
 	Ready             bool
 	LatestModel       bool
 	ProvisioningState infrav1.ProvisioningState
 	CreationTime      metav1.Time
+	Cordoned          bool
+	Zone              string
+	DeleteAnnotation  bool
+	Reimaging         bool
+	Protection        infrav1exp.InstanceProtectionType
 }
 
 func makeAMPM(opts ampmOptions) infrav1exp.AzureMachinePoolMachine {
-	return infrav1exp.AzureMachinePoolMachine{
+	ampm := infrav1exp.AzureMachinePoolMachine{
 		ObjectMeta: metav1.ObjectMeta{
 			CreationTimestamp: opts.CreationTime,
 		},
+		Spec: infrav1exp.AzureMachinePoolMachineSpec{
+			ProviderID: opts.ProviderID,
+		},
 		Status: infrav1exp.AzureMachinePoolMachineStatus{
 			Ready:              opts.Ready,
 			LatestModelApplied: opts.LatestModel,
 			ProvisioningState:  &opts.ProvisioningState,
+			Cordoned:           opts.Cordoned,
+			AvailabilityZone:   opts.Zone,
 		},
 	}
+
+	annotations := map[string]string{}
+	if opts.DeleteAnnotation {
+		annotations[clusterv1.DeleteMachineAnnotation] = "yes"
+	}
+
+	if opts.Reimaging {
+		annotations[infrav1exp.ReimageMachineAnnotation] = ""
+	}
+
+	switch opts.Protection {
+	case infrav1exp.ScaleInInstanceProtection:
+		annotations[infrav1exp.ProtectFromScaleInAnnotation] = ""
+	case infrav1exp.ScaleSetActionsInstanceProtection:
+		annotations[infrav1exp.ProtectFromScaleSetActionsAnnotation] = ""
+	}
+
+	if len(annotations) > 0 {
+		ampm.Annotations = annotations
+	}
+
+	return ampm
 }
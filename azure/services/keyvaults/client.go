@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// apiVersion is the version of the Key Vault management API.
+const apiVersion = "2019-09-01"
+
+// azureClient contains the Azure go-sdk Client. The Key Vaults are managed as generic resources, as the Key Vault
+// management SDK depends on a uuid module that CAPZ does not otherwise use.
+type azureClient struct {
+	subscriptionID string
+	resources      resources.Client
+}
+
+// newClient creates a new Key Vault client from subscription ID.
+func newClient(auth azure.Authorizer) *azureClient {
+	c := newResourcesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &azureClient{
+		subscriptionID: auth.SubscriptionID(),
+		resources:      c,
+	}
+}
+
+// newResourcesClient creates a new resources client from subscription ID.
+func newResourcesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) resources.Client {
+	resourcesClient := resources.NewClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&resourcesClient.Client, authorizer)
+	return resourcesClient
+}
+
+// Get gets the specified Key Vault.
+func (ac *azureClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureClient.Get")
+	defer done()
+
+	return ac.resources.GetByID(ctx, azure.KeyVaultID(ac.subscriptionID, spec.ResourceGroupName(), spec.ResourceName()), apiVersion)
+}
+
+// CreateOrUpdateAsync creates or updates a Key Vault asynchronously.
+// It sends a PUT request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
+// progress of the operation.
+func (ac *azureClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureClient.CreateOrUpdateAsync")
+	defer done()
+
+	keyVault, ok := parameters.(resources.GenericResource)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a resources.GenericResource", parameters)
+	}
+
+	createFuture, err := ac.resources.CreateOrUpdateByID(ctx, azure.KeyVaultID(ac.subscriptionID, spec.ResourceGroupName(), spec.ResourceName()), apiVersion, keyVault)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	err = createFuture.WaitForCompletionRef(ctx, ac.resources.Client)
+	if err != nil {
+		// if an error occurs, return the future.
+		// this means the long-running operation didn't finish in the specified timeout.
+		return nil, &createFuture, err
+	}
+
+	result, err = createFuture.Result(ac.resources)
+	// if the operation completed, return a nil future
+	return result, nil, err
+}
+
+// DeleteAsync deletes a Key Vault and purges it, so that a new cluster can reuse its name. The deletion of a Key Vault
+// is not a long running operation, so we don't ever return a future.
+func (ac *azureClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureClient.Delete")
+	defer done()
+
+	existing, err := ac.resources.GetByID(ctx, azure.KeyVaultID(ac.subscriptionID, spec.ResourceGroupName(), spec.ResourceName()), apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteFuture, err := ac.resources.DeleteByID(ctx, azure.KeyVaultID(ac.subscriptionID, spec.ResourceGroupName(), spec.ResourceName()), apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureCallTimeout)
+	defer cancel()
+
+	if err := deleteFuture.WaitForCompletionRef(ctx, ac.resources.Client); err != nil {
+		return nil, err
+	}
+	if _, err := deleteFuture.Result(ac.resources); err != nil {
+		return nil, err
+	}
+
+	return nil, ac.purge(ctx, spec.ResourceName(), to.String(existing.Location))
+}
+
+// purge permanently deletes a soft-deleted Key Vault. The purge completes in the background.
+func (ac *azureClient) purge(ctx context.Context, name, location string) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureClient.purge")
+	defer done()
+
+	pathParameters := map[string]interface{}{
+		"location":       autorest.Encode("path", location),
+		"subscriptionId": autorest.Encode("path", ac.subscriptionID),
+		"vaultName":      autorest.Encode("path", name),
+	}
+	queryParameters := map[string]interface{}{
+		"api-version": apiVersion,
+	}
+	req, err := autorest.Prepare((&http.Request{}).WithContext(ctx),
+		autorest.AsPost(),
+		autorest.WithBaseURL(ac.resources.BaseURI),
+		autorest.WithPathParameters("/subscriptions/{subscriptionId}/providers/Microsoft.KeyVault/locations/{location}/deletedVaults/{vaultName}/purge", pathParameters),
+		autorest.WithQueryParameters(queryParameters))
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare the purge request")
+	}
+
+	resp, err := ac.resources.Send(req, azureautorest.DoRetryWithRegistration(ac.resources.Client))
+	if err != nil {
+		return errors.Wrap(err, "failed to send the purge request")
+	}
+	return autorest.Respond(resp,
+		azureautorest.WithErrorUnlessStatusCode(http.StatusOK, http.StatusAccepted),
+		autorest.ByClosing())
+}
+
+// IsDone returns true if the long-running operation has completed.
+func (ac *azureClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureClient.IsDone")
+	defer done()
+
+	isDone, err = future.DoneWithContext(ctx, ac.resources)
+	if err != nil {
+		return false, errors.Wrap(err, "failed checking if the operation was complete")
+	}
+
+	return isDone, nil
+}
+
+// Result fetches the result of a long-running operation future.
+func (ac *azureClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	_, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureClient.Result")
+	defer done()
+
+	if future == nil {
+		return nil, errors.Errorf("cannot get result from nil future")
+	}
+
+	switch futureType {
+	case infrav1.PutFuture:
+		// Marshal and Unmarshal the future to put it into the correct future type so we can access the Result function.
+		// Unfortunately the FutureAPI can't be casted directly to CreateOrUpdateByIDFuture because it is a azureautorest.Future, which doesn't implement the Result function. See PR #1686 for discussion on alternatives.
+		// It was converted back to a generic azureautorest.Future from the CAPZ infrav1.Future type stored in Status: https://github.com/kubernetes-sigs/cluster-api-provider-azure/blob/main/azure/converters/futures.go#L49.
+		var createFuture *resources.CreateOrUpdateByIDFuture
+		jsonData, err := future.MarshalJSON()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal future")
+		}
+		if err := json.Unmarshal(jsonData, &createFuture); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal future data")
+		}
+		return (*createFuture).Result(ac.resources)
+
+	case infrav1.DeleteFuture:
+		// Delete does not return a future.
+		return nil, nil
+
+	default:
+		return nil, errors.Errorf("unknown future type %q", futureType)
+	}
+}
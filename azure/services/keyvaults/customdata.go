@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// mimeBoundary is the boundary between the parts of the custom data.
+	mimeBoundary = "MIMEBOUNDARY"
+
+	// bootstrapDataDir is the directory the bootstrap data is written to on the virtual machine.
+	bootstrapDataDir = "/etc/capz"
+
+	// bootstrapDataPath is the path the bootstrap data is written to on the virtual machine before cloud-init includes it.
+	bootstrapDataPath = bootstrapDataDir + "/bootstrap-data.txt"
+
+	// cloudProviderConfigPath is the path of the cloud provider config on the virtual machine.
+	cloudProviderConfigPath = "/etc/kubernetes/azure.json"
+
+	// BootstrapDataContentType is the content type of the secrets storing bootstrap data.
+	BootstrapDataContentType = "application/gzip"
+
+	// CloudProviderConfigContentType is the content type of the secrets storing cloud provider config.
+	CloudProviderConfigContentType = "application/json"
+)
+
+// fetchSecretScript fetches a secret from a Key Vault with the managed identity of the virtual machine, retrying until
+// the role assignment granting access to it has propagated, and writes its value to a file. Its arguments are the URL
+// of the Key Vault, the name of the secret, the resource to request a token for, the resource ID of the user-assigned
+// identity to use (if any), the path of the file, and the encoding of the secret value ("gzip" or "plain").
+const fetchSecretScript = `import base64, gzip, json, sys, time, urllib.parse, urllib.request
+
+vault_url, secret_name, resource, identity, path, encoding = sys.argv[1:7]
+query = {"api-version": "2018-02-01", "resource": resource}
+if identity:
+    query["msi_res_id"] = identity
+for attempt in range(180):
+    try:
+        request = urllib.request.Request("http://169.254.169.254/metadata/identity/oauth2/token?" + urllib.parse.urlencode(query), headers={"Metadata": "true"})
+        token = json.load(urllib.request.urlopen(request, timeout=10))["access_token"]
+        request = urllib.request.Request(vault_url + "secrets/" + secret_name + "?api-version=7.1", headers={"Authorization": "Bearer " + token})
+        value = json.load(urllib.request.urlopen(request, timeout=10))["value"]
+        break
+    except Exception as e:
+        print("failed to fetch secret %s: %s" % (secret_name, e), file=sys.stderr)
+        time.sleep(10)
+else:
+    sys.exit(1)
+data = gzip.decompress(base64.b64decode(value)) if encoding == "gzip" else value.encode()
+with open(path, "wb") as f:
+    f.write(data)
+`
+
+// CustomDataOptions are the options of the custom data of a virtual machine fetching its secrets from a Key Vault.
+type CustomDataOptions struct {
+	// KeyVaultURL is the URL of the data plane of the Key Vault.
+	KeyVaultURL string
+	// BootstrapDataSecretName is the name of the secret storing the bootstrap data. The bootstrap data is embedded in
+	// the custom data when it is empty.
+	BootstrapDataSecretName string
+	// CloudProviderConfigSecretName is the name of the secret storing the cloud provider config. The cloud provider
+	// config is not fetched when it is empty.
+	CloudProviderConfigSecretName string
+	// UserAssignedIdentityID is the resource ID of the user-assigned identity fetching the secrets. The system-assigned
+	// identity fetches them when it is empty.
+	UserAssignedIdentityID string
+}
+
+// CustomData returns the base64 encoded custom data of a Linux virtual machine fetching its secrets from a Key Vault,
+// as a multipart cloud-init user data.
+// When the bootstrap data is stored in the Key Vault, a boothook fetches it before cloud-init processes the user data,
+// which then includes it. The cloud provider config is fetched by a script running before the commands of the
+// bootstrap data, as cloud-init runs the scripts of the user data in order of their names.
+func CustomData(bootstrapData []byte, options CustomDataOptions) (string, error) {
+	resource, err := keyVaultResource(options.KeyVaultURL)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(mimeBoundary); err != nil {
+		return "", errors.Wrap(err, "failed to set the boundary of the custom data")
+	}
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\nMIME-Version: 1.0\r\n\r\n", w.Boundary())
+
+	if options.BootstrapDataSecretName != "" {
+		boothook := fmt.Sprintf("#cloud-boothook\n#!/bin/sh\nset -e\numask 077\nif [ ! -f %[1]s ]; then\nmkdir -p %[2]s\n%[3]sfi\n",
+			bootstrapDataPath, bootstrapDataDir, fetchSecretCommand(options, resource, options.BootstrapDataSecretName, bootstrapDataPath, "gzip"))
+		if err := writePart(w, "text/cloud-boothook", boothook); err != nil {
+			return "", err
+		}
+		if err := writePart(w, "text/x-include-url", "#include\nfile://"+bootstrapDataPath+"\n"); err != nil {
+			return "", err
+		}
+	} else {
+		// the type of the bootstrap data is detected from its content.
+		if err := writePart(w, "text/plain", string(bootstrapData)); err != nil {
+			return "", err
+		}
+	}
+
+	if options.CloudProviderConfigSecretName != "" {
+		script := "#!/bin/sh\nset -e\numask 077\n" + fetchSecretCommand(options, resource, options.CloudProviderConfigSecretName, cloudProviderConfigPath, "plain")
+		if err := writePart(w, "text/x-shellscript", script); err != nil {
+			return "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to write the custom data")
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// CloudProviderConfigScript returns a shell script fetching the cloud provider config of a Linux virtual machine from a
+// Key Vault again, e.g. after the credentials of the cluster were rotated.
+func CloudProviderConfigScript(options CustomDataOptions) (string, error) {
+	resource, err := keyVaultResource(options.KeyVaultURL)
+	if err != nil {
+		return "", err
+	}
+	return "#!/bin/sh\nset -e\numask 077\n" + fetchSecretCommand(options, resource, options.CloudProviderConfigSecretName, cloudProviderConfigPath, "plain"), nil
+}
+
+// fetchSecretCommand returns a shell command fetching a secret from the Key Vault and writing it to a file.
+func fetchSecretCommand(options CustomDataOptions, resource, secretName, path, encoding string) string {
+	return fmt.Sprintf("python3 - %s %s %s %s %s %s <<'EOF'\n%sEOF\n",
+		shellQuote(options.KeyVaultURL), shellQuote(secretName), shellQuote(resource),
+		shellQuote(options.UserAssignedIdentityID), shellQuote(path), encoding, fetchSecretScript)
+}
+
+// writePart writes a base64 encoded part of the given content type.
+func writePart(w *multipart.Writer, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+`; charset="utf-8"`)
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return errors.Wrap(err, "failed to create a part of the custom data")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	_, err = fmt.Fprintf(part, "%s\r\n", encoded)
+	return errors.Wrap(err, "failed to write a part of the custom data")
+}
+
+// keyVaultResource returns the resource to request a token for to access the Key Vault with the given URL, e.g.
+// https://vault.azure.net for https://example.vault.azure.net/.
+func keyVaultResource(keyVaultURL string) (string, error) {
+	u, err := url.Parse(keyVaultURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse Key Vault URL %s", keyVaultURL)
+	}
+	i := strings.Index(u.Host, ".")
+	if i < 0 {
+		return "", errors.Errorf("invalid Key Vault URL %s", keyVaultURL)
+	}
+	return u.Scheme + "://" + u.Host[i+1:], nil
+}
+
+// shellQuote quotes a string for the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// EncodeBootstrapData returns the value of the secret storing the given bootstrap data, compressed to fit in the
+// size limit of the secrets.
+func EncodeBootstrapData(bootstrapData []byte) (string, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(bootstrapData); err != nil {
+		return "", errors.Wrap(err, "failed to compress bootstrap data")
+	}
+	if err := gz.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to compress bootstrap data")
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// BootstrapDataSecretName returns the name of the secret storing the bootstrap data of a machine.
+func BootstrapDataSecretName(machineName string) string {
+	return secretName(machineName + "-bootstrap-data")
+}
+
+// CloudProviderConfigSecretName returns the name of the secret storing the cloud provider config of the control plane
+// or worker nodes of an AzureMachine or AzureMachineTemplate.
+func CloudProviderConfigSecretName(ownerName string, controlPlane bool) string {
+	if controlPlane {
+		return secretName(ownerName + "-control-plane-azure-json")
+	}
+	return secretName(ownerName + "-worker-node-azure-json")
+}
+
+// secretName returns a valid secret name, which can only contain alphanumeric characters and dashes.
+func secretName(name string) string {
+	return strings.ReplaceAll(name, ".", "-")
+}
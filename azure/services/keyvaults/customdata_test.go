@@ -0,0 +1,185 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// part is a decoded part of a multipart custom data.
+type part struct {
+	contentType string
+	content     string
+}
+
+func TestCustomData(t *testing.T) {
+	testcases := []struct {
+		name          string
+		options       CustomDataOptions
+		expect        func(g *WithT, parts []part)
+		expectedError string
+	}{
+		{
+			name: "bootstrap data in Key Vault",
+			options: CustomDataOptions{
+				KeyVaultURL:             "https://kv-test.vault.azure.net/",
+				BootstrapDataSecretName: "test-machine-bootstrap-data",
+			},
+			expect: func(g *WithT, parts []part) {
+				g.Expect(parts).To(HaveLen(2))
+				g.Expect(parts[0].contentType).To(Equal("text/cloud-boothook"))
+				g.Expect(parts[0].content).To(HavePrefix("#cloud-boothook\n#!/bin/sh\n"))
+				g.Expect(parts[0].content).To(ContainSubstring("python3 - 'https://kv-test.vault.azure.net/' 'test-machine-bootstrap-data' 'https://vault.azure.net' '' '/etc/capz/bootstrap-data.txt' gzip <<'EOF'\n"))
+				g.Expect(parts[0].content).NotTo(ContainSubstring("fake bootstrap data"))
+				g.Expect(parts[1].contentType).To(Equal("text/x-include-url"))
+				g.Expect(parts[1].content).To(Equal("#include\nfile:///etc/capz/bootstrap-data.txt\n"))
+			},
+		},
+		{
+			name: "cloud provider config in Key Vault with a user-assigned identity",
+			options: CustomDataOptions{
+				KeyVaultURL:                   "https://kv-test.vault.azure.cn/",
+				CloudProviderConfigSecretName: "test-template-worker-node-azure-json",
+				UserAssignedIdentityID:        "/subscriptions/123/resourceGroups/test-group/providers/Microsoft.ManagedIdentity/userAssignedIdentities/test-identity",
+			},
+			expect: func(g *WithT, parts []part) {
+				g.Expect(parts).To(HaveLen(2))
+				g.Expect(parts[0].contentType).To(Equal("text/plain"))
+				g.Expect(parts[0].content).To(Equal("#cloud-config\nfake bootstrap data\n"))
+				g.Expect(parts[1].contentType).To(Equal("text/x-shellscript"))
+				g.Expect(parts[1].content).To(HavePrefix("#!/bin/sh\n"))
+				g.Expect(parts[1].content).To(ContainSubstring("python3 - 'https://kv-test.vault.azure.cn/' 'test-template-worker-node-azure-json' 'https://vault.azure.cn' '/subscriptions/123/resourceGroups/test-group/providers/Microsoft.ManagedIdentity/userAssignedIdentities/test-identity' '/etc/kubernetes/azure.json' plain <<'EOF'\n"))
+			},
+		},
+		{
+			name: "bootstrap data and cloud provider config in Key Vault",
+			options: CustomDataOptions{
+				KeyVaultURL:                   "https://kv-test.vault.azure.net/",
+				BootstrapDataSecretName:       "test-machine-bootstrap-data",
+				CloudProviderConfigSecretName: "test-machine-control-plane-azure-json",
+			},
+			expect: func(g *WithT, parts []part) {
+				g.Expect(parts).To(HaveLen(3))
+				g.Expect(parts[0].contentType).To(Equal("text/cloud-boothook"))
+				g.Expect(parts[1].contentType).To(Equal("text/x-include-url"))
+				g.Expect(parts[2].contentType).To(Equal("text/x-shellscript"))
+				g.Expect(parts[2].content).To(ContainSubstring("'test-machine-control-plane-azure-json'"))
+			},
+		},
+		{
+			name: "invalid Key Vault URL",
+			options: CustomDataOptions{
+				KeyVaultURL:             "https://localhost/",
+				BootstrapDataSecretName: "test-machine-bootstrap-data",
+			},
+			expectedError: "invalid Key Vault URL https://localhost/",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			customData, err := CustomData([]byte("#cloud-config\nfake bootstrap data\n"), tc.options)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			tc.expect(g, decodeCustomData(g, customData))
+		})
+	}
+}
+
+func TestCloudProviderConfigScript(t *testing.T) {
+	g := NewWithT(t)
+
+	script, err := CloudProviderConfigScript(CustomDataOptions{
+		KeyVaultURL:                   "https://kv-test.vault.azure.net/",
+		CloudProviderConfigSecretName: "test-machine-control-plane-azure-json",
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(script).To(HavePrefix("#!/bin/sh\nset -e\numask 077\n"))
+	g.Expect(script).To(ContainSubstring("python3 - 'https://kv-test.vault.azure.net/' 'test-machine-control-plane-azure-json' 'https://vault.azure.net' '' '/etc/kubernetes/azure.json' plain <<'EOF'\n"))
+
+	_, err = CloudProviderConfigScript(CustomDataOptions{KeyVaultURL: "https://localhost/"})
+	g.Expect(err).To(MatchError("invalid Key Vault URL https://localhost/"))
+}
+
+func TestEncodeBootstrapData(t *testing.T) {
+	g := NewWithT(t)
+
+	encoded, err := EncodeBootstrapData([]byte("#cloud-config\nfake bootstrap data\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	compressed, err := base64.StdEncoding.DecodeString(encoded)
+	g.Expect(err).NotTo(HaveOccurred())
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	g.Expect(err).NotTo(HaveOccurred())
+	decoded, err := io.ReadAll(gz)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(decoded)).To(Equal("#cloud-config\nfake bootstrap data\n"))
+}
+
+func TestSecretNames(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(BootstrapDataSecretName("my.machine")).To(Equal("my-machine-bootstrap-data"))
+	g.Expect(CloudProviderConfigSecretName("my-template", true)).To(Equal("my-template-control-plane-azure-json"))
+	g.Expect(CloudProviderConfigSecretName("my-template", false)).To(Equal("my-template-worker-node-azure-json"))
+}
+
+// decodeCustomData returns the decoded parts of a base64 encoded multipart custom data.
+func decodeCustomData(g *WithT, customData string) []part {
+	raw, err := base64.StdEncoding.DecodeString(customData)
+	g.Expect(err).NotTo(HaveOccurred())
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	g.Expect(err).NotTo(HaveOccurred())
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(mediaType).To(Equal("multipart/mixed"))
+
+	var parts []part
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		contentType, _, err := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(p.Header.Get("Content-Transfer-Encoding")).To(Equal("base64"))
+		encoded, err := io.ReadAll(p)
+		g.Expect(err).NotTo(HaveOccurred())
+		content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+		g.Expect(err).NotTo(HaveOccurred())
+		parts = append(parts, part{contentType: contentType, content: string(content)})
+	}
+}
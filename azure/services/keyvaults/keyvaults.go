@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const serviceName = "keyvault"
+
+// KeyVaultScope defines the scope interface for a Key Vault service.
+type KeyVaultScope interface {
+	azure.Authorizer
+	azure.AsyncStatusUpdater
+	KeyVaultSpec() azure.ResourceSpecGetter
+	ClusterName() string
+}
+
+// Service provides operations on Azure resources.
+type Service struct {
+	Scope KeyVaultScope
+	async.Reconciler
+	client async.Getter
+}
+
+// New creates a new service.
+func New(scope KeyVaultScope) *Service {
+	client := newClient(scope)
+	return &Service{
+		Scope:      scope,
+		client:     client,
+		Reconciler: async.New(scope, client, client),
+	}
+}
+
+// Reconcile gets/creates the Key Vault of the cluster.
+func (s *Service) Reconcile(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.Service.Reconcile")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	keyVaultSpec := s.Scope.KeyVaultSpec()
+	if keyVaultSpec == nil {
+		return nil
+	}
+
+	_, err := s.CreateResource(ctx, keyVaultSpec, serviceName)
+	s.Scope.UpdatePutStatus(infrav1.KeyVaultReadyCondition, serviceName, err)
+	return err
+}
+
+// Delete deletes and purges the Key Vault of the cluster if it is managed by capz.
+func (s *Service) Delete(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "keyvaults.Service.Delete")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	keyVaultSpec := s.Scope.KeyVaultSpec()
+	if keyVaultSpec == nil {
+		return nil
+	}
+
+	existing, err := s.client.Get(ctx, keyVaultSpec)
+	if err != nil {
+		if azure.ResourceNotFound(err) {
+			// already deleted or doesn't exist, cleanup status and return.
+			s.Scope.DeleteLongRunningOperationState(keyVaultSpec.ResourceName(), serviceName)
+			s.Scope.UpdateDeleteStatus(infrav1.KeyVaultReadyCondition, serviceName, nil)
+			return nil
+		}
+		return errors.Wrap(err, "failed to get Key Vault")
+	}
+	keyVault, ok := existing.(resources.GenericResource)
+	if !ok {
+		return errors.Errorf("%T is not a resources.GenericResource", existing)
+	}
+	if !converters.MapToTags(keyVault.Tags).HasOwned(s.Scope.ClusterName()) {
+		log.V(2).Info("Skipping deletion of unmanaged Key Vault", "keyVault", keyVaultSpec.ResourceName())
+		return nil
+	}
+
+	err = s.DeleteResource(ctx, keyVaultSpec, serviceName)
+	s.Scope.UpdateDeleteStatus(infrav1.KeyVaultReadyCondition, serviceName, err)
+	return err
+}
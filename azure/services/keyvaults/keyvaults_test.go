@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults/mock_keyvaults"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+var (
+	internalError      = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error")
+	notFoundError      = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found")
+	sampleManagedVault = resources.GenericResource{
+		Name: to.StringPtr("kv-test"),
+		Tags: map[string]*string{"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned")},
+	}
+	sampleBYOVault = resources.GenericResource{
+		Name: to.StringPtr("kv-test"),
+		Tags: map[string]*string{"foo": to.StringPtr("bar")},
+	}
+)
+
+func TestReconcileKeyVault(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "noop if no Key Vault is specified",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(nil)
+			},
+		},
+		{
+			name:          "create Key Vault succeeds",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(&fakeKeyVaultSpec)
+				r.CreateResource(gomockinternal.AContext(), &fakeKeyVaultSpec, serviceName).Return(nil, nil)
+				s.UpdatePutStatus(infrav1.KeyVaultReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "create Key Vault fails",
+			expectedError: "#: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(&fakeKeyVaultSpec)
+				r.CreateResource(gomockinternal.AContext(), &fakeKeyVaultSpec, serviceName).Return(nil, internalError)
+				s.UpdatePutStatus(infrav1.KeyVaultReadyCondition, serviceName, internalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_keyvaults.NewMockKeyVaultScope(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT())
+
+			s := &Service{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteKeyVault(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "noop if no Key Vault is specified",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(nil)
+			},
+		},
+		{
+			name:          "delete operation is successful for managed Key Vault",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(&fakeKeyVaultSpec)
+				m.Get(gomockinternal.AContext(), &fakeKeyVaultSpec).Return(sampleManagedVault, nil)
+				s.ClusterName().Return("test-cluster")
+				r.DeleteResource(gomockinternal.AContext(), &fakeKeyVaultSpec, serviceName).Return(nil)
+				s.UpdateDeleteStatus(infrav1.KeyVaultReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "Key Vault is not managed by capz",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(&fakeKeyVaultSpec)
+				m.Get(gomockinternal.AContext(), &fakeKeyVaultSpec).Return(sampleBYOVault, nil)
+				s.ClusterName().Return("test-cluster")
+			},
+		},
+		{
+			name:          "Key Vault doesn't exist",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(&fakeKeyVaultSpec)
+				m.Get(gomockinternal.AContext(), &fakeKeyVaultSpec).Return(resources.GenericResource{}, notFoundError)
+				s.DeleteLongRunningOperationState("kv-test", serviceName)
+				s.UpdateDeleteStatus(infrav1.KeyVaultReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "error occurs when deleting Key Vault",
+			expectedError: "#: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_keyvaults.MockKeyVaultScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSpec().Return(&fakeKeyVaultSpec)
+				m.Get(gomockinternal.AContext(), &fakeKeyVaultSpec).Return(sampleManagedVault, nil)
+				s.ClusterName().Return("test-cluster")
+				r.DeleteResource(gomockinternal.AContext(), &fakeKeyVaultSpec, serviceName).Return(internalError)
+				s.UpdateDeleteStatus(infrav1.KeyVaultReadyCondition, serviceName, internalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_keyvaults.NewMockKeyVaultScope(mockCtrl)
+			getterMock := mock_async.NewMockGetter(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), getterMock.EXPECT(), asyncMock.EXPECT())
+
+			s := &Service{
+				Scope:      scopeMock,
+				client:     getterMock,
+				Reconciler: asyncMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Run go generate to regenerate this mock.
+//go:generate ../../../../hack/tools/bin/mockgen -destination keyvaults_mock.go -package mock_keyvaults -source ../keyvaults.go KeyVaultScope
+//go:generate ../../../../hack/tools/bin/mockgen -destination secrets_mock.go -package mock_keyvaults -source ../secrets.go SecretScope
+//go:generate /usr/bin/env bash -c "cat ../../../../hack/boilerplate/boilerplate.generatego.txt keyvaults_mock.go > _keyvaults_mock.go && mv _keyvaults_mock.go keyvaults_mock.go"
+//go:generate /usr/bin/env bash -c "cat ../../../../hack/boilerplate/boilerplate.generatego.txt secrets_mock.go > _secrets_mock.go && mv _secrets_mock.go secrets_mock.go"
+package mock_keyvaults //nolint
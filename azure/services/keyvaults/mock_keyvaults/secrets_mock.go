@@ -0,0 +1,198 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../secrets.go
+
+// Package mock_keyvaults is a generated GoMock package.
+package mock_keyvaults
+
+import (
+	reflect "reflect"
+
+	autorest "github.com/Azure/go-autorest/autorest"
+	gomock "github.com/golang/mock/gomock"
+	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	azure "sigs.k8s.io/cluster-api-provider-azure/azure"
+	v1beta10 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// MockSecretScope is a mock of SecretScope interface.
+type MockSecretScope struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretScopeMockRecorder
+}
+
+// MockSecretScopeMockRecorder is the mock recorder for MockSecretScope.
+type MockSecretScopeMockRecorder struct {
+	mock *MockSecretScope
+}
+
+// NewMockSecretScope creates a new mock instance.
+func NewMockSecretScope(ctrl *gomock.Controller) *MockSecretScope {
+	mock := &MockSecretScope{ctrl: ctrl}
+	mock.recorder = &MockSecretScopeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretScope) EXPECT() *MockSecretScopeMockRecorder {
+	return m.recorder
+}
+
+// DeleteLongRunningOperationState mocks base method.
+func (m *MockSecretScope) DeleteLongRunningOperationState(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DeleteLongRunningOperationState", arg0, arg1)
+}
+
+// DeleteLongRunningOperationState indicates an expected call of DeleteLongRunningOperationState.
+func (mr *MockSecretScopeMockRecorder) DeleteLongRunningOperationState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLongRunningOperationState", reflect.TypeOf((*MockSecretScope)(nil).DeleteLongRunningOperationState), arg0, arg1)
+}
+
+// GetLongRunningOperationState mocks base method.
+func (m *MockSecretScope) GetLongRunningOperationState(arg0, arg1 string) *v1beta1.Future {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLongRunningOperationState", arg0, arg1)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	return ret0
+}
+
+// GetLongRunningOperationState indicates an expected call of GetLongRunningOperationState.
+func (mr *MockSecretScopeMockRecorder) GetLongRunningOperationState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLongRunningOperationState", reflect.TypeOf((*MockSecretScope)(nil).GetLongRunningOperationState), arg0, arg1)
+}
+
+// KeyVault mocks base method.
+func (m *MockSecretScope) KeyVault() *v1beta1.KeyVaultSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVault")
+	ret0, _ := ret[0].(*v1beta1.KeyVaultSpec)
+	return ret0
+}
+
+// KeyVault indicates an expected call of KeyVault.
+func (mr *MockSecretScopeMockRecorder) KeyVault() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVault", reflect.TypeOf((*MockSecretScope)(nil).KeyVault))
+}
+
+// KeyVaultAuthorizer mocks base method.
+func (m *MockSecretScope) KeyVaultAuthorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultAuthorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// KeyVaultAuthorizer indicates an expected call of KeyVaultAuthorizer.
+func (mr *MockSecretScopeMockRecorder) KeyVaultAuthorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultAuthorizer", reflect.TypeOf((*MockSecretScope)(nil).KeyVaultAuthorizer))
+}
+
+// KeyVaultID mocks base method.
+func (m *MockSecretScope) KeyVaultID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyVaultID indicates an expected call of KeyVaultID.
+func (mr *MockSecretScopeMockRecorder) KeyVaultID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultID", reflect.TypeOf((*MockSecretScope)(nil).KeyVaultID))
+}
+
+// KeyVaultSecretSpecs mocks base method.
+func (m *MockSecretScope) KeyVaultSecretSpecs() []azure.ResourceSpecGetter {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultSecretSpecs")
+	ret0, _ := ret[0].([]azure.ResourceSpecGetter)
+	return ret0
+}
+
+// KeyVaultSecretSpecs indicates an expected call of KeyVaultSecretSpecs.
+func (mr *MockSecretScopeMockRecorder) KeyVaultSecretSpecs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultSecretSpecs", reflect.TypeOf((*MockSecretScope)(nil).KeyVaultSecretSpecs))
+}
+
+// KeyVaultURL mocks base method.
+func (m *MockSecretScope) KeyVaultURL() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeyVaultURL")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// KeyVaultURL indicates an expected call of KeyVaultURL.
+func (mr *MockSecretScopeMockRecorder) KeyVaultURL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeyVaultURL", reflect.TypeOf((*MockSecretScope)(nil).KeyVaultURL))
+}
+
+// SetLongRunningOperationState mocks base method.
+func (m *MockSecretScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLongRunningOperationState", arg0)
+}
+
+// SetLongRunningOperationState indicates an expected call of SetLongRunningOperationState.
+func (mr *MockSecretScopeMockRecorder) SetLongRunningOperationState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLongRunningOperationState", reflect.TypeOf((*MockSecretScope)(nil).SetLongRunningOperationState), arg0)
+}
+
+// UpdateDeleteStatus mocks base method.
+func (m *MockSecretScope) UpdateDeleteStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateDeleteStatus", arg0, arg1, arg2)
+}
+
+// UpdateDeleteStatus indicates an expected call of UpdateDeleteStatus.
+func (mr *MockSecretScopeMockRecorder) UpdateDeleteStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeleteStatus", reflect.TypeOf((*MockSecretScope)(nil).UpdateDeleteStatus), arg0, arg1, arg2)
+}
+
+// UpdatePatchStatus mocks base method.
+func (m *MockSecretScope) UpdatePatchStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePatchStatus", arg0, arg1, arg2)
+}
+
+// UpdatePatchStatus indicates an expected call of UpdatePatchStatus.
+func (mr *MockSecretScopeMockRecorder) UpdatePatchStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePatchStatus", reflect.TypeOf((*MockSecretScope)(nil).UpdatePatchStatus), arg0, arg1, arg2)
+}
+
+// UpdatePutStatus mocks base method.
+func (m *MockSecretScope) UpdatePutStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePutStatus", arg0, arg1, arg2)
+}
+
+// UpdatePutStatus indicates an expected call of UpdatePutStatus.
+func (mr *MockSecretScopeMockRecorder) UpdatePutStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePutStatus", reflect.TypeOf((*MockSecretScope)(nil).UpdatePutStatus), arg0, arg1, arg2)
+}
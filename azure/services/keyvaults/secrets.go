@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"context"
+
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const secretsServiceName = "keyvaultsecrets"
+
+// SecretScope defines the scope interface for a Key Vault secrets service.
+type SecretScope interface {
+	azure.AsyncStatusUpdater
+	azure.KeyVaultDescriber
+	KeyVaultSecretSpecs() []azure.ResourceSpecGetter
+}
+
+// SecretsService provides operations on the secrets of the Key Vault of a cluster.
+type SecretsService struct {
+	Scope SecretScope
+	async.Reconciler
+}
+
+// NewSecretsService creates a new secrets service.
+func NewSecretsService(scope SecretScope) *SecretsService {
+	client := newSecretsClient(scope)
+	return &SecretsService{
+		Scope:      scope,
+		Reconciler: async.New(scope, client, client),
+	}
+}
+
+// Reconcile sets the value of the secrets of the scope in the Key Vault of the cluster.
+func (s *SecretsService) Reconcile(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.SecretsService.Reconcile")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	for _, secretSpec := range s.Scope.KeyVaultSecretSpecs() {
+		if _, err := s.CreateResource(ctx, secretSpec, secretsServiceName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes the secrets of the scope from the Key Vault of the cluster.
+func (s *SecretsService) Delete(ctx context.Context) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.SecretsService.Delete")
+	defer done()
+
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultAzureServiceReconcileTimeout)
+	defer cancel()
+
+	for _, secretSpec := range s.Scope.KeyVaultSecretSpecs() {
+		if err := s.DeleteResource(ctx, secretSpec, secretsServiceName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// azureSecretsClient contains the Azure go-sdk Client for the data plane of a Key Vault.
+type azureSecretsClient struct {
+	vaultURL string
+	secrets  keyvault.BaseClient
+}
+
+// newSecretsClient creates a new secrets client for the Key Vault of the cluster.
+func newSecretsClient(scope azure.KeyVaultDescriber) *azureSecretsClient {
+	c := keyvault.New()
+	azure.SetAutoRestClientDefaults(&c.Client, scope.KeyVaultAuthorizer())
+	return &azureSecretsClient{
+		vaultURL: scope.KeyVaultURL(),
+		secrets:  c,
+	}
+}
+
+// Get gets the current version of the specified secret.
+func (ac *azureSecretsClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureSecretsClient.Get")
+	defer done()
+
+	return ac.secrets.GetSecret(ctx, ac.vaultURL, spec.ResourceName(), "")
+}
+
+// CreateOrUpdateAsync sets the value of a secret. Setting a secret is not a long running operation, so we don't ever
+// return a future.
+func (ac *azureSecretsClient) CreateOrUpdateAsync(ctx context.Context, spec azure.ResourceSpecGetter, parameters interface{}) (result interface{}, future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureSecretsClient.CreateOrUpdateAsync")
+	defer done()
+
+	secret, ok := parameters.(keyvault.SecretSetParameters)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a keyvault.SecretSetParameters", parameters)
+	}
+
+	result, err = ac.secrets.SetSecret(ctx, ac.vaultURL, spec.ResourceName(), secret)
+	return result, nil, err
+}
+
+// DeleteAsync deletes a secret. The secret is kept in a soft-deleted state until the end of the retention period of
+// the Key Vault. Deleting a secret is not a long running operation, so we don't ever return a future.
+func (ac *azureSecretsClient) DeleteAsync(ctx context.Context, spec azure.ResourceSpecGetter) (future azureautorest.FutureAPI, err error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "keyvaults.AzureSecretsClient.DeleteAsync")
+	defer done()
+
+	_, err = ac.secrets.DeleteSecret(ctx, ac.vaultURL, spec.ResourceName())
+	return nil, err
+}
+
+// IsDone always returns true as secrets don't have long running operations.
+func (ac *azureSecretsClient) IsDone(ctx context.Context, future azureautorest.FutureAPI) (isDone bool, err error) {
+	return true, nil
+}
+
+// Result always returns a nil result as secrets don't have long running operations.
+func (ac *azureSecretsClient) Result(ctx context.Context, future azureautorest.FutureAPI, futureType string) (result interface{}, err error) {
+	return nil, nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults/mock_keyvaults"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+)
+
+var fakeCloudProviderConfigSecretSpec = SecretSpec{
+	Name:          "test-template-worker-node-azure-json",
+	KeyVaultName:  "kv-test",
+	ResourceGroup: "test-group",
+	Value:         "{}",
+	ContentType:   CloudProviderConfigContentType,
+}
+
+func TestReconcileSecrets(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "noop if there are no secrets",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSecretSpecs().Return(nil)
+			},
+		},
+		{
+			name:          "set secrets succeeds",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSecretSpecs().Return([]azure.ResourceSpecGetter{&fakeSecretSpec, &fakeCloudProviderConfigSecretSpec})
+				r.CreateResource(gomockinternal.AContext(), &fakeSecretSpec, secretsServiceName).Return(nil, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeCloudProviderConfigSecretSpec, secretsServiceName).Return(nil, nil)
+			},
+		},
+		{
+			name:          "set secret fails",
+			expectedError: "#: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSecretSpecs().Return([]azure.ResourceSpecGetter{&fakeSecretSpec, &fakeCloudProviderConfigSecretSpec})
+				r.CreateResource(gomockinternal.AContext(), &fakeSecretSpec, secretsServiceName).Return(nil, internalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_keyvaults.NewMockSecretScope(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT())
+
+			s := &SecretsService{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestDeleteSecrets(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expectedError string
+		expect        func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "delete secrets succeeds",
+			expectedError: "",
+			expect: func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSecretSpecs().Return([]azure.ResourceSpecGetter{&fakeSecretSpec})
+				r.DeleteResource(gomockinternal.AContext(), &fakeSecretSpec, secretsServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "delete secret fails",
+			expectedError: "#: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_keyvaults.MockSecretScopeMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.KeyVaultSecretSpecs().Return([]azure.ResourceSpecGetter{&fakeSecretSpec})
+				r.DeleteResource(gomockinternal.AContext(), &fakeSecretSpec, secretsServiceName).Return(internalError)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_keyvaults.NewMockSecretScope(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), asyncMock.EXPECT())
+
+			s := &SecretsService{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+)
+
+// softDeleteRetentionInDays is the minimum number of days a deleted Key Vault or secret is kept for.
+const softDeleteRetentionInDays = 7
+
+// KeyVaultSpec defines the specification for a Key Vault.
+type KeyVaultSpec struct {
+	Name           string
+	ResourceGroup  string
+	Location       string
+	TenantID       string
+	ClusterName    string
+	AdditionalTags infrav1.Tags
+}
+
+// ResourceName returns the name of the Key Vault.
+func (s *KeyVaultSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group.
+func (s *KeyVaultSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName is a no-op for Key Vaults.
+func (s *KeyVaultSpec) OwnerResourceName() string {
+	return ""
+}
+
+// Parameters returns the parameters for the Key Vault. The access to its data plane is authorized with Azure RBAC, so
+// that the identity of each machine can only read its own secrets.
+func (s *KeyVaultSpec) Parameters(existing interface{}) (params interface{}, err error) {
+	if existing != nil {
+		// Key Vault already exists, nothing to update.
+		return nil, nil
+	}
+
+	return resources.GenericResource{
+		Location: to.StringPtr(s.Location),
+		Tags: converters.TagsToMap(infrav1.Build(infrav1.BuildParams{
+			ClusterName: s.ClusterName,
+			Lifecycle:   infrav1.ResourceLifecycleOwned,
+			Name:        to.StringPtr(s.Name),
+			Role:        to.StringPtr(infrav1.CommonRole),
+			Additional:  s.AdditionalTags,
+		})),
+		Properties: map[string]interface{}{
+			"tenantId": s.TenantID,
+			"sku": map[string]interface{}{
+				"family": "A",
+				"name":   "standard",
+			},
+			"enableRbacAuthorization":   true,
+			"enableSoftDelete":          true,
+			"softDeleteRetentionInDays": softDeleteRetentionInDays,
+		},
+	}, nil
+}
+
+// SecretSpec defines the specification for a secret of a Key Vault.
+type SecretSpec struct {
+	Name          string
+	KeyVaultName  string
+	ResourceGroup string
+	Value         string
+	ContentType   string
+}
+
+// ResourceName returns the name of the secret.
+func (s *SecretSpec) ResourceName() string {
+	return s.Name
+}
+
+// ResourceGroupName returns the name of the resource group of the Key Vault.
+func (s *SecretSpec) ResourceGroupName() string {
+	return s.ResourceGroup
+}
+
+// OwnerResourceName returns the name of the Key Vault.
+func (s *SecretSpec) OwnerResourceName() string {
+	return s.KeyVaultName
+}
+
+// Parameters returns the parameters for the secret.
+func (s *SecretSpec) Parameters(existing interface{}) (params interface{}, err error) {
+	if existing != nil {
+		existingSecret, ok := existing.(keyvault.SecretBundle)
+		if !ok {
+			return nil, errors.Errorf("%T is not a keyvault.SecretBundle", existing)
+		}
+
+		if to.String(existingSecret.Value) == s.Value && to.String(existingSecret.ContentType) == s.ContentType {
+			// secret already has the expected value
+			return nil, nil
+		}
+	}
+
+	parameters := keyvault.SecretSetParameters{
+		Value: to.StringPtr(s.Value),
+		Tags:  map[string]*string{},
+	}
+	if s.ContentType != "" {
+		parameters.ContentType = to.StringPtr(s.ContentType)
+	}
+	return parameters, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keyvaults
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	fakeKeyVaultSpec = KeyVaultSpec{
+		Name:           "kv-test",
+		ResourceGroup:  "test-group",
+		Location:       "test-location",
+		TenantID:       "test-tenant",
+		ClusterName:    "test-cluster",
+		AdditionalTags: map[string]string{"foo": "bar"},
+	}
+	fakeSecretSpec = SecretSpec{
+		Name:          "test-machine-bootstrap-data",
+		KeyVaultName:  "kv-test",
+		ResourceGroup: "test-group",
+		Value:         "test-value",
+		ContentType:   BootstrapDataContentType,
+	}
+)
+
+func TestKeyVaultParameters(t *testing.T) {
+	testcases := []struct {
+		name     string
+		existing interface{}
+		expect   func(g *WithT, result interface{})
+	}{
+		{
+			name:     "new Key Vault",
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(resources.GenericResource{
+					Location: to.StringPtr("test-location"),
+					Tags: map[string]*string{
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_test-cluster": to.StringPtr("owned"),
+						"sigs.k8s.io_cluster-api-provider-azure_role":                 to.StringPtr("common"),
+						"Name": to.StringPtr("kv-test"),
+						"foo":  to.StringPtr("bar"),
+					},
+					Properties: map[string]interface{}{
+						"tenantId": "test-tenant",
+						"sku": map[string]interface{}{
+							"family": "A",
+							"name":   "standard",
+						},
+						"enableRbacAuthorization":   true,
+						"enableSoftDelete":          true,
+						"softDeleteRetentionInDays": softDeleteRetentionInDays,
+					},
+				}))
+			},
+		},
+		{
+			name:     "existing Key Vault",
+			existing: resources.GenericResource{Name: to.StringPtr("kv-test")},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			result, err := fakeKeyVaultSpec.Parameters(tc.existing)
+			g.Expect(err).NotTo(HaveOccurred())
+			tc.expect(g, result)
+		})
+	}
+}
+
+func TestSecretParameters(t *testing.T) {
+	testcases := []struct {
+		name          string
+		existing      interface{}
+		expect        func(g *WithT, result interface{})
+		expectedError string
+	}{
+		{
+			name:     "new secret",
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(keyvault.SecretSetParameters{
+					Value:       to.StringPtr("test-value"),
+					Tags:        map[string]*string{},
+					ContentType: to.StringPtr(BootstrapDataContentType),
+				}))
+			},
+		},
+		{
+			name: "existing secret with the expected value",
+			existing: keyvault.SecretBundle{
+				Value:       to.StringPtr("test-value"),
+				ContentType: to.StringPtr(BootstrapDataContentType),
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "existing secret with another value",
+			existing: keyvault.SecretBundle{
+				Value:       to.StringPtr("old-value"),
+				ContentType: to.StringPtr(BootstrapDataContentType),
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(keyvault.SecretSetParameters{
+					Value:       to.StringPtr("test-value"),
+					Tags:        map[string]*string{},
+					ContentType: to.StringPtr(BootstrapDataContentType),
+				}))
+			},
+		},
+		{
+			name:          "existing is not a secret",
+			existing:      "not a secret",
+			expectedError: "string is not a keyvault.SecretBundle",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			result, err := fakeSecretSpec.Parameters(tc.existing)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			tc.expect(g, result)
+		})
+	}
+}
@@ -37,6 +37,7 @@ type LBSpec struct {
 	SKU                  infrav1.SKU
 	VNetName             string
 	VNetResourceGroup    string
+	VNetSubscriptionID   string
 	SubnetName           string
 	BackendPoolName      string
 	FrontendIPConfigs    []infrav1.FrontendIP
@@ -55,6 +56,14 @@ func (s *LBSpec) ResourceGroupName() string {
 	return s.ResourceGroup
 }
 
+// vnetSubscriptionID returns the subscription of the virtual network, which defaults to the one of the load balancer.
+func (s *LBSpec) vnetSubscriptionID() string {
+	if s.VNetSubscriptionID != "" {
+		return s.VNetSubscriptionID
+	}
+	return s.SubscriptionID
+}
+
 // OwnerResourceName is a no-op for load balancers.
 func (s *LBSpec) OwnerResourceName() string {
 	return ""
@@ -167,7 +176,7 @@ func getFrontendIPConfigs(lbSpec LBSpec) ([]network.FrontendIPConfiguration, []n
 			properties = network.FrontendIPConfigurationPropertiesFormat{
 				PrivateIPAllocationMethod: network.IPAllocationMethodStatic,
 				Subnet: &network.Subnet{
-					ID: to.StringPtr(azure.SubnetID(lbSpec.SubscriptionID, lbSpec.VNetResourceGroup, lbSpec.VNetName, lbSpec.SubnetName)),
+					ID: to.StringPtr(azure.SubnetID(lbSpec.vnetSubscriptionID(), lbSpec.VNetResourceGroup, lbSpec.VNetName, lbSpec.SubnetName)),
 				},
 				PrivateIPAddress: to.StringPtr(ipConfig.PrivateIPAddress),
 			}
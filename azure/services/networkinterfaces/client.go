@@ -31,15 +31,40 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
+// Client wraps go-sdk.
+type Client interface {
+	Get(context.Context, azure.ResourceSpecGetter) (interface{}, error)
+	CheckIPAddressAvailability(ctx context.Context, vnetSubscriptionID, vnetResourceGroup, vnetName, ipAddress string) (network.IPAddressAvailabilityResult, error)
+}
+
 // AzureClient contains the Azure go-sdk Client.
 type AzureClient struct {
-	interfaces network.InterfacesClient
+	interfaces          network.InterfacesClient
+	virtualnetworks     network.VirtualNetworksClient
+	baseURI             string
+	secondaryAuthorizer autorest.Authorizer
 }
 
+var _ Client = &AzureClient{}
+
 // NewClient creates a new VM client from subscription ID.
 func NewClient(auth azure.Authorizer) *AzureClient {
-	c := newInterfacesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
-	return &AzureClient{c}
+	return newClient(auth, auth.Authorizer())
+}
+
+// newCrossSubscriptionClient creates a new VM client from subscription ID, which checks the availability of private IP
+// addresses in virtual networks of other subscriptions with the secondary authorizer.
+func newCrossSubscriptionClient(auth azure.CrossSubscriptionAuthorizer) *AzureClient {
+	return newClient(auth, auth.SecondaryAuthorizer())
+}
+
+func newClient(auth azure.Authorizer, secondaryAuthorizer autorest.Authorizer) *AzureClient {
+	return &AzureClient{
+		interfaces:          newInterfacesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		virtualnetworks:     newVirtualNetworksClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		baseURI:             auth.BaseURI(),
+		secondaryAuthorizer: secondaryAuthorizer,
+	}
 }
 
 // newInterfacesClient creates a new network interfaces client from subscription ID.
@@ -49,6 +74,13 @@ func newInterfacesClient(subscriptionID string, baseURI string, authorizer autor
 	return nicClient
 }
 
+// newVirtualNetworksClient creates a new virtual networks client from subscription ID.
+func newVirtualNetworksClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) network.VirtualNetworksClient {
+	vnetsClient := network.NewVirtualNetworksClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&vnetsClient.Client, authorizer)
+	return vnetsClient
+}
+
 // Get gets the specified network interface.
 func (ac *AzureClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "networkinterfaces.AzureClient.Get")
@@ -57,6 +89,19 @@ func (ac *AzureClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (
 	return ac.interfaces.Get(ctx, spec.ResourceGroupName(), spec.ResourceName(), "")
 }
 
+// CheckIPAddressAvailability checks whether a private IP address is available for use in a virtual network, which may
+// be in another subscription.
+func (ac *AzureClient) CheckIPAddressAvailability(ctx context.Context, vnetSubscriptionID, vnetResourceGroup, vnetName, ipAddress string) (network.IPAddressAvailabilityResult, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "networkinterfaces.AzureClient.CheckIPAddressAvailability")
+	defer done()
+
+	vnetsClient := ac.virtualnetworks
+	if vnetSubscriptionID != "" && vnetSubscriptionID != ac.virtualnetworks.SubscriptionID {
+		vnetsClient = newVirtualNetworksClient(vnetSubscriptionID, ac.baseURI, ac.secondaryAuthorizer)
+	}
+	return vnetsClient.CheckIPAddressAvailability(ctx, vnetResourceGroup, vnetName, ipAddress)
+}
+
 // CreateOrUpdateAsync creates or updates a network interface asynchronously.
 // It sends a PUT request to Azure and if accepted without error, the func will return a Future which can be used to track the ongoing
 // progress of the operation.
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkinterfaces
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+type fakeCrossSubscriptionAuthorizer struct {
+	baseURI string
+}
+
+func (a fakeCrossSubscriptionAuthorizer) SubscriptionID() string   { return "123" }
+func (a fakeCrossSubscriptionAuthorizer) ClientID() string         { return "" }
+func (a fakeCrossSubscriptionAuthorizer) ClientSecret() string     { return "" }
+func (a fakeCrossSubscriptionAuthorizer) CloudEnvironment() string { return "" }
+func (a fakeCrossSubscriptionAuthorizer) TenantID() string         { return "" }
+func (a fakeCrossSubscriptionAuthorizer) BaseURI() string          { return a.baseURI }
+func (a fakeCrossSubscriptionAuthorizer) HashKey() string          { return "" }
+func (a fakeCrossSubscriptionAuthorizer) Authorizer() autorest.Authorizer {
+	return autorest.NewAPIKeyAuthorizerWithHeaders(map[string]interface{}{"Authorization": "primary"})
+}
+func (a fakeCrossSubscriptionAuthorizer) SecondaryAuthorizer() autorest.Authorizer {
+	return autorest.NewAPIKeyAuthorizerWithHeaders(map[string]interface{}{"Authorization": "secondary"})
+}
+
+func TestCheckIPAddressAvailability(t *testing.T) {
+	testcases := []struct {
+		name               string
+		vnetSubscriptionID string
+		expectedPath       string
+		expectedAuthorizer string
+	}{
+		{
+			name:               "virtual network in the subscription of the cluster",
+			vnetSubscriptionID: "123",
+			expectedPath:       "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet/CheckIPAddressAvailability",
+			expectedAuthorizer: "primary",
+		},
+		{
+			name:               "virtual network in another subscription",
+			vnetSubscriptionID: "456",
+			expectedPath:       "/subscriptions/456/resourceGroups/my-rg/providers/Microsoft.Network/virtualNetworks/my-vnet/CheckIPAddressAvailability",
+			expectedAuthorizer: "secondary",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var request *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"available": true}`)
+			}))
+			defer server.Close()
+
+			ac := newCrossSubscriptionClient(fakeCrossSubscriptionAuthorizer{baseURI: server.URL})
+			result, err := ac.CheckIPAddressAvailability(context.TODO(), tc.vnetSubscriptionID, "my-rg", "my-vnet", "10.0.0.10")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(to.Bool(result.Available)).To(BeTrue())
+			g.Expect(request.URL.Path).To(Equal(tc.expectedPath))
+			g.Expect(request.URL.Query().Get("ipAddress")).To(Equal("10.0.0.10"))
+			g.Expect(request.Header.Get("Authorization")).To(Equal(tc.expectedAuthorizer))
+		})
+	}
+}
@@ -275,6 +275,20 @@ func (mr *MockNICScopeMockRecorder) ResourceGroup() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroup", reflect.TypeOf((*MockNICScope)(nil).ResourceGroup))
 }
 
+// SecondaryAuthorizer mocks base method.
+func (m *MockNICScope) SecondaryAuthorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecondaryAuthorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// SecondaryAuthorizer indicates an expected call of SecondaryAuthorizer.
+func (mr *MockNICScopeMockRecorder) SecondaryAuthorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecondaryAuthorizer", reflect.TypeOf((*MockNICScope)(nil).SecondaryAuthorizer))
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockNICScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -287,6 +301,18 @@ func (mr *MockNICScopeMockRecorder) SetLongRunningOperationState(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLongRunningOperationState", reflect.TypeOf((*MockNICScope)(nil).SetLongRunningOperationState), arg0)
 }
 
+// SetNICPrivateIPAddress mocks base method.
+func (m *MockNICScope) SetNICPrivateIPAddress(nicName, privateIPAddress string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetNICPrivateIPAddress", nicName, privateIPAddress)
+}
+
+// SetNICPrivateIPAddress indicates an expected call of SetNICPrivateIPAddress.
+func (mr *MockNICScopeMockRecorder) SetNICPrivateIPAddress(nicName, privateIPAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNICPrivateIPAddress", reflect.TypeOf((*MockNICScope)(nil).SetNICPrivateIPAddress), nicName, privateIPAddress)
+}
+
 // SubscriptionID mocks base method.
 func (m *MockNICScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -17,7 +17,15 @@ limitations under the License.
 package networkinterfaces
 
 import (
+	"bytes"
 	"context"
+	"net"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -29,26 +37,38 @@ import (
 
 const serviceName = "interfaces"
 
+// privateIPAddressInUseRequeue is how long to wait before checking again whether a static private IP address has been
+// released when it is already in use.
+const privateIPAddressInUseRequeue = 30 * time.Second
+
+// maxPrivateIPAddressChecks caps the number of availability checks made to allocate a private IP address from a range
+// in a single reconcile.
+const maxPrivateIPAddressChecks = 10
+
 // NICScope defines the scope interface for a network interfaces service.
 type NICScope interface {
 	azure.ClusterDescriber
 	azure.AsyncStatusUpdater
+	SecondaryAuthorizer() autorest.Authorizer
 	NICSpecs() []azure.ResourceSpecGetter
+	SetNICPrivateIPAddress(nicName string, privateIPAddress string)
 }
 
 // Service provides operations on Azure resources.
 type Service struct {
 	Scope NICScope
 	async.Reconciler
+	client           Client
 	resourceSKUCache *resourceskus.Cache
 }
 
 // New creates a new service.
 func New(scope NICScope, skuCache *resourceskus.Cache) *Service {
-	Client := NewClient(scope)
+	Client := newCrossSubscriptionClient(scope)
 	return &Service{
 		Scope:            scope,
 		Reconciler:       async.New(scope, Client, Client),
+		client:           Client,
 		resourceSKUCache: skuCache,
 	}
 }
@@ -66,10 +86,26 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
 	var result error
 	for _, nicSpec := range s.Scope.NICSpecs() {
-		if _, err := s.CreateResource(ctx, nicSpec, serviceName); err != nil {
+		spec, ok := nicSpec.(*NICSpec)
+		if ok {
+			if err := s.reserveStaticIPAddress(ctx, spec); err != nil {
+				if !azure.IsOperationNotDoneError(err) || result == nil {
+					result = err
+				}
+				continue
+			}
+		}
+		nic, err := s.CreateResource(ctx, nicSpec, serviceName)
+		if err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
+			continue
+		}
+		if ok && spec.PrivateIPAddressRange != "" {
+			if ip := primaryPrivateIPAddress(nic); ip != "" {
+				s.Scope.SetNICPrivateIPAddress(spec.Name, ip)
+			}
 		}
 	}
 
@@ -100,3 +136,144 @@ func (s *Service) Delete(ctx context.Context) error {
 	s.Scope.UpdateDeleteStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, result)
 	return result
 }
+
+// reserveStaticIPAddress makes sure the static private IP address of a network interface that has not been created yet
+// is within its subnet and not already in use, allocating a free one from the private IP address range when the
+// network interface does not specify one.
+func (s *Service) reserveStaticIPAddress(ctx context.Context, spec *NICSpec) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "networkinterfaces.Service.reserveStaticIPAddress")
+	defer done()
+
+	if spec.StaticIPAddress == "" && spec.PrivateIPAddressRange == "" {
+		return nil
+	}
+
+	// The private IP address of a network interface that is being or has been created cannot be changed.
+	if s.Scope.GetLongRunningOperationState(spec.ResourceName(), serviceName) != nil {
+		return nil
+	}
+	if _, err := s.client.Get(ctx, spec); err == nil {
+		return nil
+	} else if !azure.ResourceNotFound(err) {
+		return errors.Wrapf(err, "failed to get network interface %s", spec.ResourceName())
+	}
+
+	if spec.StaticIPAddress != "" {
+		ip := net.ParseIP(spec.StaticIPAddress)
+		if ip == nil || !inCIDRs(ip, spec.SubnetCIDRs) {
+			return azure.WithTerminalError(errors.Errorf("private IP address %s of network interface %s is not within subnet %s", spec.StaticIPAddress, spec.ResourceName(), spec.SubnetName))
+		}
+		available, _, err := s.isPrivateIPAddressAvailable(ctx, spec, spec.StaticIPAddress)
+		if err != nil {
+			return err
+		}
+		if !available {
+			return azure.WithTransientError(errors.Errorf("private IP address %s of network interface %s is already in use", spec.StaticIPAddress, spec.ResourceName()), privateIPAddressInUseRequeue)
+		}
+		return nil
+	}
+
+	first, last, err := infrav1.ParsePrivateIPAddressRange(spec.PrivateIPAddressRange)
+	if err != nil {
+		return azure.WithTerminalError(errors.Wrapf(err, "failed to parse private IP address range of network interface %s", spec.ResourceName()))
+	}
+	inRange := func(ip net.IP) bool {
+		return ip != nil && bytes.Compare(ip.To16(), first.To16()) >= 0 && bytes.Compare(ip.To16(), last.To16()) <= 0 && inCIDRs(ip, spec.SubnetCIDRs)
+	}
+
+	checks := 0
+	for ip := first; bytes.Compare(ip.To16(), last.To16()) <= 0; {
+		if !inCIDRs(ip, spec.SubnetCIDRs) {
+			ip = nextIP(ip)
+			continue
+		}
+		if checks == maxPrivateIPAddressChecks {
+			return azure.WithTransientError(errors.Errorf("no private IP address available in range %s of subnet %s for network interface %s after %d checks", spec.PrivateIPAddressRange, spec.SubnetName, spec.ResourceName(), checks), privateIPAddressInUseRequeue)
+		}
+		checks++
+
+		available, suggestions, err := s.isPrivateIPAddressAvailable(ctx, spec, ip.String())
+		if err != nil {
+			return err
+		}
+		if available {
+			log.V(2).Info("allocated private IP address from range", "networkInterface", spec.ResourceName(), "privateIPAddress", ip.String(), "range", spec.PrivateIPAddressRange)
+			spec.StaticIPAddress = ip.String()
+			return nil
+		}
+
+		// Azure suggests the next available IP addresses of the subnet when the requested one is taken, so the IP
+		// addresses up to the last suggestion are not checked one at a time.
+		next := nextIP(ip)
+		for _, suggestion := range suggestions {
+			suggested := net.ParseIP(suggestion)
+			if inRange(suggested) {
+				log.V(2).Info("allocated private IP address from range", "networkInterface", spec.ResourceName(), "privateIPAddress", suggestion, "range", spec.PrivateIPAddressRange)
+				spec.StaticIPAddress = suggestion
+				return nil
+			}
+			if suggested != nil && bytes.Compare(suggested.To16(), next.To16()) >= 0 {
+				next = nextIP(suggested)
+			}
+		}
+		ip = next
+	}
+
+	return azure.WithTransientError(errors.Errorf("no private IP address available in range %s of subnet %s for network interface %s", spec.PrivateIPAddressRange, spec.SubnetName, spec.ResourceName()), privateIPAddressInUseRequeue)
+}
+
+// isPrivateIPAddressAvailable returns whether a private IP address is available in the virtual network of the network
+// interface, along with other available IP addresses suggested by Azure when it is not.
+func (s *Service) isPrivateIPAddressAvailable(ctx context.Context, spec *NICSpec, ipAddress string) (bool, []string, error) {
+	result, err := s.client.CheckIPAddressAvailability(ctx, spec.vnetSubscriptionID(), spec.VNetResourceGroup, spec.VNetName, ipAddress)
+	if err != nil {
+		return false, nil, errors.Wrapf(err, "failed to check availability of private IP address %s in virtual network %s", ipAddress, spec.VNetName)
+	}
+	var suggestions []string
+	if result.AvailableIPAddresses != nil {
+		suggestions = *result.AvailableIPAddresses
+	}
+	available := to.Bool(result.Available) && !to.Bool(result.IsPlatformReserved)
+	return available, suggestions, nil
+}
+
+// primaryPrivateIPAddress returns the private IP address of the primary IP configuration of a network interface.
+func primaryPrivateIPAddress(result interface{}) string {
+	nic, ok := result.(network.Interface)
+	if !ok || nic.InterfacePropertiesFormat == nil || nic.IPConfigurations == nil {
+		return ""
+	}
+	for _, ipConfig := range *nic.IPConfigurations {
+		if ipConfig.InterfaceIPConfigurationPropertiesFormat != nil && to.Bool(ipConfig.Primary) {
+			return to.String(ipConfig.PrivateIPAddress)
+		}
+	}
+	return ""
+}
+
+// inCIDRs returns whether an IP address is within one of the CIDR blocks. Any IP address is considered within an
+// empty list of CIDR blocks, as the CIDR blocks of subnets that are not managed by the cluster are not known.
+func inCIDRs(ip net.IP, cidrs []string) bool {
+	if len(cidrs) == 0 {
+		return true
+	}
+	for _, cidr := range cidrs {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextIP returns the IP address following the given one.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
@@ -22,7 +22,9 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
@@ -128,6 +130,235 @@ func TestReconcileNetworkInterface(t *testing.T) {
 	}
 }
 
+func TestReconcileNetworkInterfaceStaticIPAddress(t *testing.T) {
+	notFoundError := autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found")
+	staticNICSpec := func() *NICSpec {
+		spec := fakeNICSpec1
+		spec.StaticIPAddress = "10.0.0.10"
+		spec.SubnetCIDRs = []string{"10.0.0.0/24"}
+		return &spec
+	}
+	rangeNICSpec := func() *NICSpec {
+		spec := fakeNICSpec1
+		spec.PrivateIPAddressRange = "10.0.0.16/30"
+		spec.SubnetCIDRs = []string{"10.0.0.0/24"}
+		return &spec
+	}
+	allocatedNICSpec := func(ip string) *NICSpec {
+		spec := rangeNICSpec()
+		spec.StaticIPAddress = ip
+		return spec
+	}
+	nicWithIP := func(ip string) network.Interface {
+		return network.Interface{
+			InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+				IPConfigurations: &[]network.InterfaceIPConfiguration{
+					{
+						InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+							Primary:          to.BoolPtr(true),
+							PrivateIPAddress: to.StringPtr(ip),
+						},
+					},
+				},
+			},
+		}
+	}
+	available := network.IPAddressAvailabilityResult{Available: to.BoolPtr(true)}
+	inUse := network.IPAddressAvailabilityResult{Available: to.BoolPtr(false)}
+
+	testcases := []struct {
+		name          string
+		spec          *NICSpec
+		extraSpecs    []azure.ResourceSpecGetter
+		expectedError string
+		expect        func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder)
+	}{
+		{
+			name:          "create a network interface with an available static private IP address",
+			spec:          staticNICSpec(),
+			expectedError: "",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), staticNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.10").Return(available, nil)
+				r.CreateResource(gomockinternal.AContext(), staticNICSpec(), serviceName).Return(nicWithIP("10.0.0.10"), nil)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name: "check the availability of a static private IP address in a virtual network of another subscription",
+			spec: func() *NICSpec {
+				spec := staticNICSpec()
+				spec.VNetSubscriptionID = "456"
+				return spec
+			}(),
+			expectedError: "",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				spec := staticNICSpec()
+				spec.VNetSubscriptionID = "456"
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), spec).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "456", "my-rg", "my-vnet", "10.0.0.10").Return(available, nil)
+				r.CreateResource(gomockinternal.AContext(), spec, serviceName).Return(nicWithIP("10.0.0.10"), nil)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name: "static private IP address outside of the subnet",
+			spec: func() *NICSpec {
+				spec := staticNICSpec()
+				spec.StaticIPAddress = "10.1.0.10"
+				return spec
+			}(),
+			expectedError: "private IP address 10.1.0.10 of network interface nic-1 is not within subnet my-subnet",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, notFoundError)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name:          "static private IP address already in use",
+			spec:          staticNICSpec(),
+			expectedError: "private IP address 10.0.0.10 of network interface nic-1 is already in use",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), staticNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.10").Return(inUse, nil)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name:          "allocate a private IP address suggested by Azure from the range",
+			spec:          rangeNICSpec(),
+			expectedError: "",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), rangeNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.16").Return(network.IPAddressAvailabilityResult{
+					Available:            to.BoolPtr(false),
+					AvailableIPAddresses: &[]string{"10.0.0.5", "10.0.0.18"},
+				}, nil)
+				r.CreateResource(gomockinternal.AContext(), allocatedNICSpec("10.0.0.18"), serviceName).Return(nicWithIP("10.0.0.18"), nil)
+				s.SetNICPrivateIPAddress("nic-1", "10.0.0.18")
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "skip platform reserved private IP addresses of the range",
+			spec:          rangeNICSpec(),
+			expectedError: "",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), rangeNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.16").Return(network.IPAddressAvailabilityResult{
+					Available:          to.BoolPtr(true),
+					IsPlatformReserved: to.BoolPtr(true),
+				}, nil)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.17").Return(available, nil)
+				r.CreateResource(gomockinternal.AContext(), allocatedNICSpec("10.0.0.17"), serviceName).Return(nicWithIP("10.0.0.17"), nil)
+				s.SetNICPrivateIPAddress("nic-1", "10.0.0.17")
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "record the private IP address of an existing network interface",
+			spec:          rangeNICSpec(),
+			expectedError: "",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), rangeNICSpec()).Return(nicWithIP("10.0.0.19"), nil)
+				r.CreateResource(gomockinternal.AContext(), rangeNICSpec(), serviceName).Return(nicWithIP("10.0.0.19"), nil)
+				s.SetNICPrivateIPAddress("nic-1", "10.0.0.19")
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "no private IP address available in the range",
+			spec:          rangeNICSpec(),
+			expectedError: "no private IP address available in range 10.0.0.16/30 of subnet my-subnet for network interface nic-1",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), rangeNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", gomock.Any()).Return(inUse, nil).Times(4)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name:          "private IP address in use takes precedence over another network interface being created",
+			spec:          staticNICSpec(),
+			extraSpecs:    []azure.ResourceSpecGetter{&fakeNICSpec2},
+			expectedError: "private IP address 10.0.0.10 of network interface nic-1 is already in use",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), staticNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.10").Return(inUse, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeNICSpec2, serviceName).Return(nil, azure.NewOperationNotDoneError(&infrav1.Future{}))
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name:          "skip the private IP addresses of the range before the next available one suggested by Azure",
+			spec:          rangeNICSpec(),
+			expectedError: "no private IP address available in range 10.0.0.16/30 of subnet my-subnet for network interface nic-1",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), rangeNICSpec()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", "10.0.0.16").Return(network.IPAddressAvailabilityResult{
+					Available:            to.BoolPtr(false),
+					AvailableIPAddresses: &[]string{"10.0.0.30", "10.0.0.31"},
+				}, nil)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, gomock.Any())
+			},
+		},
+		{
+			name: "cap the availability checks of the private IP addresses of a large range",
+			spec: func() *NICSpec {
+				spec := rangeNICSpec()
+				spec.PrivateIPAddressRange = "10.0.0.0/24"
+				return spec
+			}(),
+			expectedError: "no private IP address available in range 10.0.0.0/24 of subnet my-subnet for network interface nic-1 after 10 checks",
+			expect: func(s *mock_networkinterfaces.MockNICScopeMockRecorder, c *mock_networkinterfaces.MockClientMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.GetLongRunningOperationState("nic-1", serviceName).Return(nil)
+				c.Get(gomockinternal.AContext(), gomock.Any()).Return(nil, notFoundError)
+				c.CheckIPAddressAvailability(gomockinternal.AContext(), "123", "my-rg", "my-vnet", gomock.Any()).Return(inUse, nil).Times(maxPrivateIPAddressChecks)
+				s.UpdatePutStatus(infrav1.NetworkInterfaceReadyCondition, serviceName, gomock.Any())
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_networkinterfaces.NewMockNICScope(mockCtrl)
+			clientMock := mock_networkinterfaces.NewMockClient(mockCtrl)
+			asyncMock := mock_async.NewMockReconciler(mockCtrl)
+
+			scopeMock.EXPECT().NICSpecs().Return(append([]azure.ResourceSpecGetter{tc.spec}, tc.extraSpecs...))
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT(), asyncMock.EXPECT())
+
+			s := &Service{
+				Scope:      scopeMock,
+				Reconciler: asyncMock,
+				client:     clientMock,
+			}
+
+			err := s.Reconcile(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 func TestDeleteNetworkInterface(t *testing.T) {
 	testcases := []struct {
 		name          string
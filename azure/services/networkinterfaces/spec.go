@@ -34,7 +34,10 @@ type NICSpec struct {
 	SubnetName                string
 	VNetName                  string
 	VNetResourceGroup         string
+	VNetSubscriptionID        string
 	StaticIPAddress           string
+	PrivateIPAddressRange     string
+	SubnetCIDRs               []string
 	PublicLBName              string
 	PublicLBAddressPoolName   string
 	PublicLBNATRuleName       string
@@ -62,6 +65,14 @@ func (s *NICSpec) OwnerResourceName() string {
 	return ""
 }
 
+// vnetSubscriptionID returns the subscription of the virtual network, which defaults to the one of the network interface.
+func (s *NICSpec) vnetSubscriptionID() string {
+	if s.VNetSubscriptionID != "" {
+		return s.VNetSubscriptionID
+	}
+	return s.SubscriptionID
+}
+
 // Parameters returns the parameters for the network interface.
 func (s *NICSpec) Parameters(existing interface{}) (parameters interface{}, err error) {
 	if existing != nil {
@@ -75,7 +86,7 @@ func (s *NICSpec) Parameters(existing interface{}) (parameters interface{}, err
 	nicConfig := &network.InterfaceIPConfigurationPropertiesFormat{}
 
 	subnet := &network.Subnet{
-		ID: to.StringPtr(azure.SubnetID(s.SubscriptionID, s.VNetResourceGroup, s.VNetName, s.SubnetName)),
+		ID: to.StringPtr(azure.SubnetID(s.vnetSubscriptionID(), s.VNetResourceGroup, s.VNetName, s.SubnetName)),
 	}
 	nicConfig.Subnet = subnet
 
@@ -89,6 +89,22 @@ var (
 		SKU:                     &fakeSku,
 	}
 
+	fakeOtherSubscriptionVNetNICSpec = NICSpec{
+		Name:                    "my-net-interface",
+		ResourceGroup:           "my-rg",
+		Location:                "fake-location",
+		SubscriptionID:          "123",
+		MachineName:             "azure-test1",
+		SubnetName:              "my-subnet",
+		VNetName:                "hub-vnet",
+		VNetResourceGroup:       "hub-rg",
+		VNetSubscriptionID:      "456",
+		PublicLBName:            "my-public-lb",
+		PublicLBAddressPoolName: "cluster-name-outboundBackendPool",
+		AcceleratedNetworking:   nil,
+		SKU:                     &fakeSku,
+	}
+
 	fakeControlPlaneNICSpec = NICSpec{
 		Name:                      "my-net-interface",
 		ResourceGroup:             "my-rg",
@@ -221,6 +237,32 @@ func TestParameters(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		{
+			name:     "get parameters for network interface in a vnet of another subscription",
+			spec:     &fakeOtherSubscriptionVNetNICSpec,
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.Interface{}))
+				g.Expect(result.(network.Interface)).To(Equal(network.Interface{
+					Location: to.StringPtr("fake-location"),
+					InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+						EnableAcceleratedNetworking: to.BoolPtr(true),
+						EnableIPForwarding:          to.BoolPtr(false),
+						IPConfigurations: &[]network.InterfaceIPConfiguration{
+							{
+								Name: to.StringPtr("pipConfig"),
+								InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+									LoadBalancerBackendAddressPools: &[]network.BackendAddressPool{{ID: to.StringPtr("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Network/loadBalancers/my-public-lb/backendAddressPools/cluster-name-outboundBackendPool")}},
+									PrivateIPAllocationMethod:       network.IPAllocationMethodDynamic,
+									Subnet:                          &network.Subnet{ID: to.StringPtr("/subscriptions/456/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet/subnets/my-subnet")},
+								},
+							},
+						},
+					},
+				}))
+			},
+			expectedError: "",
+		},
 		{
 			name:     "get parameters for control plane network interface",
 			spec:     &fakeControlPlaneNICSpec,
@@ -47,11 +47,12 @@ type azureClient struct {
 
 var _ client = (*azureClient)(nil)
 
-// newClient creates a new VM client from subscription ID.
-func newClient(auth azure.Authorizer) *azureClient {
-	c := newPrivateZonesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
-	v := newVirtualNetworkLinksClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
-	r := newRecordSetsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+// newClient creates a new private DNS client for the zones of the subscription.
+func newClient(auth azure.CrossSubscriptionAuthorizer, subscriptionID string) *azureClient {
+	authorizer := azure.AuthorizerForSubscription(auth, subscriptionID)
+	c := newPrivateZonesClient(subscriptionID, auth.BaseURI(), authorizer)
+	v := newVirtualNetworkLinksClient(subscriptionID, auth.BaseURI(), authorizer)
+	r := newRecordSetsClient(subscriptionID, auth.BaseURI(), authorizer)
 	return &azureClient{c, v, r}
 }
 
@@ -248,6 +248,20 @@ func (mr *MockScopeMockRecorder) ResourceGroup() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroup", reflect.TypeOf((*MockScope)(nil).ResourceGroup))
 }
 
+// SecondaryAuthorizer mocks base method.
+func (m *MockScope) SecondaryAuthorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecondaryAuthorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// SecondaryAuthorizer indicates an expected call of SecondaryAuthorizer.
+func (mr *MockScopeMockRecorder) SecondaryAuthorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecondaryAuthorizer", reflect.TypeOf((*MockScope)(nil).SecondaryAuthorizer))
+}
+
 // SubscriptionID mocks base method.
 func (m *MockScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -22,6 +22,7 @@ import (
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 
 	"github.com/Azure/azure-sdk-for-go/services/privatedns/mgmt/2018-09-01/privatedns"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 
@@ -33,6 +34,7 @@ import (
 // Scope defines the scope interface for a private dns service.
 type Scope interface {
 	azure.ClusterDescriber
+	SecondaryAuthorizer() autorest.Authorizer
 	PrivateDNSSpec() *azure.PrivateDNSSpec
 }
 
@@ -42,11 +44,15 @@ type Service struct {
 	client
 }
 
-// New creates a new private dns service.
+// New creates a new private dns service, whose client targets the subscription of the private DNS zone.
 func New(scope Scope) *Service {
+	subscriptionID := scope.SubscriptionID()
+	if zoneSpec := scope.PrivateDNSSpec(); zoneSpec != nil && zoneSpec.SubscriptionID != "" {
+		subscriptionID = zoneSpec.SubscriptionID
+	}
 	return &Service{
 		Scope:  scope,
-		client: newClient(scope),
+		client: newClient(scope, subscriptionID),
 	}
 }
 
@@ -57,10 +63,11 @@ func (s *Service) Reconcile(ctx context.Context) error {
 
 	zoneSpec := s.Scope.PrivateDNSSpec()
 	if zoneSpec != nil {
+		resourceGroup := s.zoneResourceGroup(zoneSpec)
 		// Skip the reconciliation of private DNS zone which is not managed by capz.
-		isManaged, err := s.isPrivateDNSManaged(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName)
+		isManaged, err := s.isPrivateDNSManaged(ctx, resourceGroup, zoneSpec.ZoneName)
 		if err != nil && !azure.ResourceNotFound(err) {
-			return errors.Wrapf(err, "could not get private DNS zone state of %s in resource group %s", zoneSpec.ZoneName, s.Scope.ResourceGroup())
+			return errors.Wrapf(err, "could not get private DNS zone state of %s in resource group %s", zoneSpec.ZoneName, resourceGroup)
 		}
 		// If resource is not found, it means it should be created and hence setting isVnetLinkManaged to true
 		// will allow the reconciliation to continue
@@ -83,16 +90,16 @@ func (s *Service) Reconcile(ctx context.Context) error {
 				Additional:  s.Scope.AdditionalTags(),
 			})),
 		}
-		err = s.client.CreateOrUpdateZone(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName, pDNS)
+		err = s.client.CreateOrUpdateZone(ctx, resourceGroup, zoneSpec.ZoneName, pDNS)
 		if err != nil {
 			return errors.Wrapf(err, "failed to create private DNS zone %s", zoneSpec.ZoneName)
 		}
 		log.V(2).Info("successfully created private DNS zone", "private dns zone", zoneSpec.ZoneName)
 		for _, linkSpec := range zoneSpec.Links {
 			// If the virtual network link is not managed by capz, skip its reconciliation
-			isVnetLinkManaged, err := s.isVnetLinkManaged(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName, linkSpec.LinkName)
+			isVnetLinkManaged, err := s.isVnetLinkManaged(ctx, resourceGroup, zoneSpec.ZoneName, linkSpec.LinkName)
 			if err != nil && !azure.ResourceNotFound(err) {
-				return errors.Wrapf(err, "could not get vnet link state of %s in resource group %s", zoneSpec.ZoneName, s.Scope.ResourceGroup())
+				return errors.Wrapf(err, "could not get vnet link state of %s in resource group %s", zoneSpec.ZoneName, resourceGroup)
 			}
 			// If resource is not found, it means it should be created and hence setting isVnetLinkManaged to true
 			// will allow the reconciliation to continue
@@ -105,10 +112,14 @@ func (s *Service) Reconcile(ctx context.Context) error {
 			}
 			// Link each virtual network.
 			log.V(2).Info("creating a virtual network link", "virtual network", linkSpec.VNetName, "private dns zone", zoneSpec.ZoneName)
+			vnetSubscriptionID := linkSpec.VNetSubscriptionID
+			if vnetSubscriptionID == "" {
+				vnetSubscriptionID = s.Scope.SubscriptionID()
+			}
 			link := privatedns.VirtualNetworkLink{
 				VirtualNetworkLinkProperties: &privatedns.VirtualNetworkLinkProperties{
 					VirtualNetwork: &privatedns.SubResource{
-						ID: to.StringPtr(azure.VNetID(s.Scope.SubscriptionID(), linkSpec.VNetResourceGroup, linkSpec.VNetName)),
+						ID: to.StringPtr(azure.VNetID(vnetSubscriptionID, linkSpec.VNetResourceGroup, linkSpec.VNetName)),
 					},
 					RegistrationEnabled: to.BoolPtr(false),
 				},
@@ -119,7 +130,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 					Additional:  s.Scope.AdditionalTags(),
 				})),
 			}
-			err = s.client.CreateOrUpdateLink(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName, linkSpec.LinkName, link)
+			err = s.client.CreateOrUpdateLink(ctx, resourceGroup, zoneSpec.ZoneName, linkSpec.LinkName, link)
 			if err != nil {
 				return errors.Wrapf(err, "failed to create virtual network link %s", linkSpec.LinkName)
 			}
@@ -143,7 +154,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 					Ipv6Address: &record.IP,
 				}}
 			}
-			err := s.client.CreateOrUpdateRecordSet(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName, recordType, record.Hostname, set)
+			err := s.client.CreateOrUpdateRecordSet(ctx, resourceGroup, zoneSpec.ZoneName, recordType, record.Hostname, set)
 			if err != nil {
 				return errors.Wrapf(err, "failed to create record %s in private DNS zone %s", record.Hostname, zoneSpec.ZoneName)
 			}
@@ -160,26 +171,27 @@ func (s *Service) Delete(ctx context.Context) error {
 
 	zoneSpec := s.Scope.PrivateDNSSpec()
 	if zoneSpec != nil {
+		resourceGroup := s.zoneResourceGroup(zoneSpec)
 		for _, linkSpec := range zoneSpec.Links {
 			// If the virtual network link is not managed by capz, skip its removal
-			isVnetLinkManaged, err := s.isVnetLinkManaged(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName, linkSpec.LinkName)
+			isVnetLinkManaged, err := s.isVnetLinkManaged(ctx, resourceGroup, zoneSpec.ZoneName, linkSpec.LinkName)
 			if err != nil && !azure.ResourceNotFound(err) {
-				return errors.Wrapf(err, "could not get vnet link state of %s in resource group %s", zoneSpec.ZoneName, s.Scope.ResourceGroup())
+				return errors.Wrapf(err, "could not get vnet link state of %s in resource group %s", zoneSpec.ZoneName, resourceGroup)
 			}
 			if !isVnetLinkManaged {
 				log.V(2).Info("Skipping vnet link deletion for unmanaged vnet link", "vnet link", linkSpec.LinkName, "private dns zone", zoneSpec.ZoneName)
 				continue
 			}
 			log.V(2).Info("removing virtual network link", "virtual network", linkSpec.VNetName, "private dns zone", zoneSpec.ZoneName)
-			err = s.client.DeleteLink(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName, linkSpec.LinkName)
+			err = s.client.DeleteLink(ctx, resourceGroup, zoneSpec.ZoneName, linkSpec.LinkName)
 			if err != nil && !azure.ResourceNotFound(err) {
-				return errors.Wrapf(err, "failed to delete virtual network link %s with zone %s in resource group %s", linkSpec.VNetName, zoneSpec.ZoneName, s.Scope.ResourceGroup())
+				return errors.Wrapf(err, "failed to delete virtual network link %s with zone %s in resource group %s", linkSpec.VNetName, zoneSpec.ZoneName, resourceGroup)
 			}
 		}
 		// Skip the deletion of private DNS zone which is not managed by capz.
-		isManaged, err := s.isPrivateDNSManaged(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName)
+		isManaged, err := s.isPrivateDNSManaged(ctx, resourceGroup, zoneSpec.ZoneName)
 		if err != nil && !azure.ResourceNotFound(err) {
-			return errors.Wrapf(err, "could not get private DNS zone state of %s in resource group %s", zoneSpec.ZoneName, s.Scope.ResourceGroup())
+			return errors.Wrapf(err, "could not get private DNS zone state of %s in resource group %s", zoneSpec.ZoneName, resourceGroup)
 		}
 		if !isManaged {
 			log.V(1).Info("Skipping private DNS zone deletion for unmanaged private DNS zone", "private DNS", zoneSpec.ZoneName)
@@ -187,19 +199,27 @@ func (s *Service) Delete(ctx context.Context) error {
 		}
 		// Delete the private DNS zone, which also deletes all records.
 		log.V(2).Info("deleting private dns zone", "private dns zone", zoneSpec.ZoneName)
-		err = s.client.DeleteZone(ctx, s.Scope.ResourceGroup(), zoneSpec.ZoneName)
+		err = s.client.DeleteZone(ctx, resourceGroup, zoneSpec.ZoneName)
 		if err != nil && azure.ResourceNotFound(err) {
 			// already deleted
 			return nil
 		}
 		if err != nil && !azure.ResourceNotFound(err) {
-			return errors.Wrapf(err, "failed to delete private dns zone %s in resource group %s", zoneSpec.ZoneName, s.Scope.ResourceGroup())
+			return errors.Wrapf(err, "failed to delete private dns zone %s in resource group %s", zoneSpec.ZoneName, resourceGroup)
 		}
 		log.V(2).Info("successfully deleted private dns zone", "private dns zone", zoneSpec.ZoneName)
 	}
 	return nil
 }
 
+// zoneResourceGroup returns the resource group of the private DNS zone, which defaults to the one of the cluster.
+func (s *Service) zoneResourceGroup(zoneSpec *azure.PrivateDNSSpec) string {
+	if zoneSpec.ResourceGroup != "" {
+		return zoneSpec.ResourceGroup
+	}
+	return s.Scope.ResourceGroup()
+}
+
 // isPrivateDNSManaged returns true if the private DNS has an owned tag with the cluster name as value,
 // meaning that the DNS lifecycle is managed.
 func (s *Service) isPrivateDNSManaged(ctx context.Context, resourceGroup, zoneName string) (bool, error) {
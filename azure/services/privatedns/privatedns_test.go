@@ -103,6 +103,65 @@ func TestReconcilePrivateDNS(t *testing.T) {
 				})
 			},
 		},
+		{
+			name:          "create private dns in another resource group linked to a vnet of another subscription",
+			expectedError: "",
+			expect: func(s *mock_privatedns.MockScopeMockRecorder, m *mock_privatedns.MockclientMockRecorder) {
+				s.PrivateDNSSpec().Return(&azure.PrivateDNSSpec{
+					ZoneName:       "my-dns-zone",
+					ResourceGroup:  "dns-rg",
+					SubscriptionID: "456",
+					Links: []azure.PrivateDNSLinkSpec{
+						{
+							VNetName:           "hub-vnet",
+							VNetResourceGroup:  "hub-rg",
+							VNetSubscriptionID: "456",
+							LinkName:           "hub-link",
+						},
+					},
+					Records: []infrav1.AddressRecord{
+						{
+							Hostname: "hostname-1",
+							IP:       "10.0.0.8",
+						},
+					},
+				})
+				s.ClusterName().AnyTimes().Return("my-cluster")
+				s.AdditionalTags().AnyTimes().Return(infrav1.Tags{})
+				m.GetZone(gomockinternal.AContext(), "dns-rg", "my-dns-zone").
+					Return(privatedns.PrivateZone{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdateZone(gomockinternal.AContext(), "dns-rg", "my-dns-zone", privatedns.PrivateZone{
+					Location: to.StringPtr(azure.Global),
+					Tags: map[string]*string{
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+					},
+				})
+				m.GetLink(gomockinternal.AContext(), "dns-rg", "my-dns-zone", "hub-link").
+					Return(privatedns.VirtualNetworkLink{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				m.CreateOrUpdateLink(gomockinternal.AContext(), "dns-rg", "my-dns-zone", "hub-link", privatedns.VirtualNetworkLink{
+					VirtualNetworkLinkProperties: &privatedns.VirtualNetworkLinkProperties{
+						VirtualNetwork: &privatedns.SubResource{
+							ID: to.StringPtr("/subscriptions/456/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet"),
+						},
+						RegistrationEnabled: to.BoolPtr(false),
+					},
+					Location: to.StringPtr(azure.Global),
+					Tags: map[string]*string{
+						"sigs.k8s.io_cluster-api-provider-azure_cluster_my-cluster": to.StringPtr("owned"),
+					},
+				})
+				m.CreateOrUpdateRecordSet(gomockinternal.AContext(), "dns-rg", "my-dns-zone", privatedns.A, "hostname-1", privatedns.RecordSet{
+					RecordSetProperties: &privatedns.RecordSetProperties{
+						TTL: to.Int64Ptr(300),
+						ARecords: &[]privatedns.ARecord{
+							{
+								Ipv4Address: to.StringPtr("10.0.0.8"),
+							},
+						},
+					},
+				})
+			},
+		},
 		{
 			name:          "create multiple ipv4 private dns successfully",
 			expectedError: "",
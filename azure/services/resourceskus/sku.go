@@ -52,6 +52,10 @@ const (
 const (
 	// EphemeralOSDisk identifies the capability for ephemeral os support.
 	EphemeralOSDisk = "EphemeralOSDiskSupported"
+	// PremiumIO identifies the capability for premium storage.
+	PremiumIO = "PremiumIO"
+	// HyperVGenerations identifies the comma-separated list of hypervisor generations supported by a VM size.
+	HyperVGenerations = "HyperVGenerations"
 	// AcceleratedNetworking identifies the capability for accelerated networking support.
 	AcceleratedNetworking = "AcceleratedNetworkingEnabled"
 	//VCPUs identifies the capability for the number of vCPUS.
@@ -68,6 +72,12 @@ const (
 	MaximumPlatformFaultDomainCount = "MaximumPlatformFaultDomainCount"
 	// UltraSSDAvailable identifies the capability for the support of UltraSSD data disks.
 	UltraSSDAvailable = "UltraSSDAvailable"
+	// GPUs identifies the capability for the number of GPUs.
+	GPUs = "GPUs"
+	// CPUArchitectureType identifies the capability for the CPU architecture, e.g. x64 or Arm64.
+	CPUArchitectureType = "CpuArchitectureType"
+	// ArchitectureArm64 is the CPUArchitectureType value of Arm64 based SKUs.
+	ArchitectureArm64 = "Arm64"
 )
 
 // HasCapability return true for a capability which can be either
@@ -18,6 +18,7 @@ package roleassignments
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/authorization/mgmt/authorization"
 	"github.com/Azure/go-autorest/autorest"
@@ -28,12 +29,16 @@ import (
 
 // client wraps go-sdk.
 type client interface {
+	Get(context.Context, string, string) (authorization.RoleAssignment, error)
 	Create(context.Context, string, string, authorization.RoleAssignmentCreateParameters) (authorization.RoleAssignment, error)
+	Delete(context.Context, string, string) error
+	ListRoleDefinitions(context.Context, string, string) ([]authorization.RoleDefinition, error)
 }
 
 // azureClient contains the Azure go-sdk Client.
 type azureClient struct {
 	roleassignments authorization.RoleAssignmentsClient
+	roledefinitions authorization.RoleDefinitionsClient
 }
 
 var _ client = (*azureClient)(nil)
@@ -41,7 +46,8 @@ var _ client = (*azureClient)(nil)
 // newClient creates a new role assignment client from subscription ID.
 func newClient(auth azure.Authorizer) *azureClient {
 	c := newRoleAssignmentClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
-	return &azureClient{c}
+	d := newRoleDefinitionClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &azureClient{roleassignments: c, roledefinitions: d}
 }
 
 // newRoleAssignmentClient creates a role assignments client from subscription ID.
@@ -51,6 +57,21 @@ func newRoleAssignmentClient(subscriptionID string, baseURI string, authorizer a
 	return roleClient
 }
 
+// newRoleDefinitionClient creates a role definitions client from subscription ID.
+func newRoleDefinitionClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) authorization.RoleDefinitionsClient {
+	roleDefinitionClient := authorization.NewRoleDefinitionsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&roleDefinitionClient.Client, authorizer)
+	return roleDefinitionClient
+}
+
+// Get gets the role assignment with the given name at the given scope.
+func (ac *azureClient) Get(ctx context.Context, scope string, roleAssignmentName string) (authorization.RoleAssignment, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "roleassignments.AzureClient.Get")
+	defer done()
+
+	return ac.roleassignments.Get(ctx, scope, roleAssignmentName)
+}
+
 // Create creates a role assignment.
 // Parameters:
 // scope - the scope of the role assignment to create. The scope can be any REST resource instance. For
@@ -66,3 +87,33 @@ func (ac *azureClient) Create(ctx context.Context, scope string, roleAssignmentN
 
 	return ac.roleassignments.Create(ctx, scope, roleAssignmentName, parameters)
 }
+
+// Delete deletes the role assignment with the given name at the given scope.
+func (ac *azureClient) Delete(ctx context.Context, scope string, roleAssignmentName string) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "roleassignments.AzureClient.Delete")
+	defer done()
+
+	_, err := ac.roleassignments.Delete(ctx, scope, roleAssignmentName)
+	return err
+}
+
+// ListRoleDefinitions lists the role definitions available at the given scope, optionally filtered, e.g. by role name
+// with "roleName eq '{name}'".
+func (ac *azureClient) ListRoleDefinitions(ctx context.Context, scope string, filter string) ([]authorization.RoleDefinition, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "roleassignments.AzureClient.ListRoleDefinitions")
+	defer done()
+
+	itr, err := ac.roledefinitions.ListComplete(ctx, scope, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleDefinitions []authorization.RoleDefinition
+	for ; itr.NotDone(); err = itr.NextWithContext(ctx) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate role definitions [%w]", err)
+		}
+		roleDefinitions = append(roleDefinitions, itr.Value())
+	}
+	return roleDefinitions, nil
+}
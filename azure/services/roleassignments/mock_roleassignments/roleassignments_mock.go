@@ -21,6 +21,7 @@ limitations under the License.
 package mock_roleassignments
 
 import (
+	context "context"
 	reflect "reflect"
 
 	autorest "github.com/Azure/go-autorest/autorest"
@@ -206,6 +207,21 @@ func (mr *MockRoleAssignmentScopeMockRecorder) HashKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockRoleAssignmentScope)(nil).HashKey))
 }
 
+// IsRoleAssignmentShared mocks base method.
+func (m *MockRoleAssignmentScope) IsRoleAssignmentShared(ctx context.Context, spec azure.RoleAssignmentSpec) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRoleAssignmentShared", ctx, spec)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRoleAssignmentShared indicates an expected call of IsRoleAssignmentShared.
+func (mr *MockRoleAssignmentScopeMockRecorder) IsRoleAssignmentShared(ctx, spec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRoleAssignmentShared", reflect.TypeOf((*MockRoleAssignmentScope)(nil).IsRoleAssignmentShared), ctx, spec)
+}
+
 // Location mocks base method.
 func (m *MockRoleAssignmentScope) Location() string {
 	m.ctrl.T.Helper()
@@ -234,6 +250,20 @@ func (mr *MockRoleAssignmentScopeMockRecorder) ResourceGroup() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroup", reflect.TypeOf((*MockRoleAssignmentScope)(nil).ResourceGroup))
 }
 
+// RoleAssignmentIDs mocks base method.
+func (m *MockRoleAssignmentScope) RoleAssignmentIDs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleAssignmentIDs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// RoleAssignmentIDs indicates an expected call of RoleAssignmentIDs.
+func (mr *MockRoleAssignmentScopeMockRecorder) RoleAssignmentIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleAssignmentIDs", reflect.TypeOf((*MockRoleAssignmentScope)(nil).RoleAssignmentIDs))
+}
+
 // RoleAssignmentSpecs mocks base method.
 func (m *MockRoleAssignmentScope) RoleAssignmentSpecs() []azure.RoleAssignmentSpec {
 	m.ctrl.T.Helper()
@@ -248,6 +278,18 @@ func (mr *MockRoleAssignmentScopeMockRecorder) RoleAssignmentSpecs() *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleAssignmentSpecs", reflect.TypeOf((*MockRoleAssignmentScope)(nil).RoleAssignmentSpecs))
 }
 
+// SetRoleAssignmentIDs mocks base method.
+func (m *MockRoleAssignmentScope) SetRoleAssignmentIDs(ids []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRoleAssignmentIDs", ids)
+}
+
+// SetRoleAssignmentIDs indicates an expected call of SetRoleAssignmentIDs.
+func (mr *MockRoleAssignmentScopeMockRecorder) SetRoleAssignmentIDs(ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoleAssignmentIDs", reflect.TypeOf((*MockRoleAssignmentScope)(nil).SetRoleAssignmentIDs), ids)
+}
+
 // SubscriptionID mocks base method.
 func (m *MockRoleAssignmentScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -19,6 +19,7 @@ package roleassignments
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/profiles/2019-03-01/authorization/mgmt/authorization"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
@@ -32,12 +33,18 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
-const azureBuiltInContributorID = "b24988ac-6180-42a0-ab88-20f7382dd24c"
+const (
+	azureBuiltInContributorID = "b24988ac-6180-42a0-ab88-20f7382dd24c"
+	roleAssignmentsProvider   = "providers/Microsoft.Authorization/roleAssignments"
+)
 
 // RoleAssignmentScope defines the scope interface for a role assignment service.
 type RoleAssignmentScope interface {
 	azure.ClusterDescriber
 	RoleAssignmentSpecs() []azure.RoleAssignmentSpec
+	IsRoleAssignmentShared(ctx context.Context, spec azure.RoleAssignmentSpec) (bool, error)
+	RoleAssignmentIDs() []string
+	SetRoleAssignmentIDs(ids []string)
 }
 
 // Service provides operations on Azure resources.
@@ -58,22 +65,43 @@ func New(scope RoleAssignmentScope) *Service {
 	}
 }
 
-// Reconcile creates a role assignment.
+// Reconcile creates the role assignments.
 func (s *Service) Reconcile(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "roleassignments.Service.Reconcile")
 	defer done()
 
-	for _, roleSpec := range s.Scope.RoleAssignmentSpecs() {
+	roleSpecs := s.Scope.RoleAssignmentSpecs()
+	previousIDs := s.Scope.RoleAssignmentIDs()
+	ids := make([]string, 0, len(roleSpecs))
+	for _, roleSpec := range roleSpecs {
+		if roleSpec.Name != "" {
+			ids = append(ids, roleAssignmentID(s.roleAssignmentScope(roleSpec), roleSpec.Name))
+		}
+	}
+	// keep track of the role assignments about to be created along with the previous ones, so that the ones removed
+	// from the spec are deleted even if creating the new ones fails
+	s.Scope.SetRoleAssignmentIDs(mergeIDs(previousIDs, ids))
+
+	for _, roleSpec := range roleSpecs {
+		var err error
 		switch roleSpec.ResourceType {
 		case azure.VirtualMachine:
-			return s.reconcileVM(ctx, roleSpec)
+			err = s.reconcileVM(ctx, roleSpec)
 		case azure.VirtualMachineScaleSet:
-			return s.reconcileVMSS(ctx, roleSpec)
+			err = s.reconcileVMSS(ctx, roleSpec)
 		default:
 			return errors.Errorf("unexpected resource type %q. Expected one of [%s, %s]", roleSpec.ResourceType,
 				azure.VirtualMachine, azure.VirtualMachineScaleSet)
 		}
+		if err != nil {
+			return err
+		}
 	}
+
+	if err := s.deleteStale(ctx, previousIDs, ids); err != nil {
+		return err
+	}
+	s.Scope.SetRoleAssignmentIDs(ids)
 	return nil
 }
 
@@ -88,19 +116,24 @@ func (s *Service) reconcileVM(ctx context.Context, roleSpec azure.RoleAssignment
 
 	resultVMIface, err := s.virtualMachinesGetter.Get(ctx, spec)
 	if err != nil {
-		return errors.Wrap(err, "cannot get VM to assign role to system assigned identity")
+		return errors.Wrapf(err, "cannot get VM to assign role to %s", identityKind(roleSpec))
 	}
 	resultVM, ok := resultVMIface.(compute.VirtualMachine)
 	if !ok {
 		return errors.Errorf("%T is not a compute.VirtualMachine", resultVMIface)
 	}
 
-	err = s.assignRole(ctx, roleSpec.Name, resultVM.Identity.PrincipalID)
+	principalID, err := vmPrincipalID(resultVM.Identity, roleSpec.UserAssignedIdentityID)
+	if err != nil {
+		return errors.Wrapf(err, "cannot assign role to VM %s", identityKind(roleSpec))
+	}
+
+	err = s.assignRole(ctx, roleSpec, principalID)
 	if err != nil {
-		return errors.Wrap(err, "cannot assign role to VM system assigned identity")
+		return errors.Wrapf(err, "cannot assign role to VM %s", identityKind(roleSpec))
 	}
 
-	log.V(2).Info("successfully created role assignment for generated Identity for VM", "virtual machine", roleSpec.MachineName)
+	log.V(2).Info("successfully created role assignment for Identity for VM", "virtual machine", roleSpec.MachineName, "role assignment", roleSpec.Name)
 
 	return nil
 }
@@ -111,40 +144,240 @@ func (s *Service) reconcileVMSS(ctx context.Context, roleSpec azure.RoleAssignme
 
 	resultVMSS, err := s.virtualMachineScaleSetClient.Get(ctx, s.Scope.ResourceGroup(), roleSpec.MachineName)
 	if err != nil {
-		return errors.Wrap(err, "cannot get VMSS to assign role to system assigned identity")
+		return errors.Wrapf(err, "cannot get VMSS to assign role to %s", identityKind(roleSpec))
 	}
 
-	err = s.assignRole(ctx, roleSpec.Name, resultVMSS.Identity.PrincipalID)
+	principalID, err := vmssPrincipalID(resultVMSS.Identity, roleSpec.UserAssignedIdentityID)
 	if err != nil {
-		return errors.Wrap(err, "cannot assign role to VMSS system assigned identity")
+		return errors.Wrapf(err, "cannot assign role to VMSS %s", identityKind(roleSpec))
 	}
 
-	log.V(2).Info("successfully created role assignment for generated Identity for VMSS", "virtual machine scale set", roleSpec.MachineName)
+	err = s.assignRole(ctx, roleSpec, principalID)
+	if err != nil {
+		return errors.Wrapf(err, "cannot assign role to VMSS %s", identityKind(roleSpec))
+	}
+
+	log.V(2).Info("successfully created role assignment for Identity for VMSS", "virtual machine scale set", roleSpec.MachineName, "role assignment", roleSpec.Name)
 
 	return nil
 }
 
-func (s *Service) assignRole(ctx context.Context, roleAssignmentName string, principalID *string) error {
+// assignRole creates the role assignment unless it already exists for the principal. Role assignments cannot be
+// updated, so one created for another principal, e.g. the system-assigned identity of a VM that was recreated, is
+// replaced.
+func (s *Service) assignRole(ctx context.Context, roleSpec azure.RoleAssignmentSpec, principalID *string) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "roleassignments.Service.assignRole")
 	defer done()
 
-	scope := fmt.Sprintf("/subscriptions/%s/", s.Scope.SubscriptionID())
-	// Azure built-in roles https://docs.microsoft.com/en-us/azure/role-based-access-control/built-in-roles
-	contributorRoleDefinitionID := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", s.Scope.SubscriptionID(), azureBuiltInContributorID)
+	scope := s.roleAssignmentScope(roleSpec)
+	roleDefinitionID, err := s.roleDefinitionID(ctx, roleSpec, scope)
+	if err != nil {
+		return err
+	}
+
+	existing, err := s.client.Get(ctx, scope, roleSpec.Name)
+	switch {
+	case err == nil:
+		if existing.Properties != nil && strings.EqualFold(to.String(existing.Properties.PrincipalID), to.String(principalID)) &&
+			strings.EqualFold(to.String(existing.Properties.RoleDefinitionID), roleDefinitionID) {
+			return nil
+		}
+		if err := s.client.Delete(ctx, scope, roleSpec.Name); err != nil {
+			return errors.Wrapf(err, "failed to delete outdated role assignment %s", roleSpec.Name)
+		}
+	case !azure.ResourceNotFound(err):
+		return errors.Wrapf(err, "failed to get role assignment %s", roleSpec.Name)
+	}
+
 	params := authorization.RoleAssignmentCreateParameters{
 		Properties: &authorization.RoleAssignmentProperties{
-			RoleDefinitionID: to.StringPtr(contributorRoleDefinitionID),
+			RoleDefinitionID: to.StringPtr(roleDefinitionID),
 			PrincipalID:      principalID,
 		},
 	}
-	_, err := s.client.Create(ctx, scope, roleAssignmentName, params)
+	_, err = s.client.Create(ctx, scope, roleSpec.Name, params)
+	if azure.ResourceConflict(err) {
+		// the role assignment may have been created concurrently, e.g. by another machine sharing the user-assigned
+		// identity, which is only the case if it now exists under its name
+		if _, getErr := s.client.Get(ctx, scope, roleSpec.Name); getErr == nil {
+			return nil
+		}
+	}
 	return err
 }
 
-// Delete is a no-op as the role assignments get deleted as part of VM deletion.
+// roleAssignmentScope returns the scope of the role assignment, the subscription by default.
+func (s *Service) roleAssignmentScope(roleSpec azure.RoleAssignmentSpec) string {
+	if roleSpec.Scope != "" {
+		return roleSpec.Scope
+	}
+	return fmt.Sprintf("/subscriptions/%s/", s.Scope.SubscriptionID())
+}
+
+// roleDefinitionID returns the resource ID of the role definition of the role assignment, the Contributor role by
+// default.
+func (s *Service) roleDefinitionID(ctx context.Context, roleSpec azure.RoleAssignmentSpec, scope string) (string, error) {
+	switch {
+	case roleSpec.RoleDefinitionName != "":
+		roleDefinitions, err := s.client.ListRoleDefinitions(ctx, scope, fmt.Sprintf("roleName eq '%s'", roleSpec.RoleDefinitionName))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to get role definition %s", roleSpec.RoleDefinitionName)
+		}
+		if len(roleDefinitions) == 0 || roleDefinitions[0].ID == nil {
+			return "", errors.Errorf("role definition %s not found", roleSpec.RoleDefinitionName)
+		}
+		return *roleDefinitions[0].ID, nil
+	case strings.HasPrefix(roleSpec.RoleDefinitionID, "/"):
+		return roleSpec.RoleDefinitionID, nil
+	case roleSpec.RoleDefinitionID != "":
+		return fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", s.Scope.SubscriptionID(), roleSpec.RoleDefinitionID), nil
+	default:
+		// Azure built-in roles https://docs.microsoft.com/en-us/azure/role-based-access-control/built-in-roles
+		return fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleDefinitions/%s", s.Scope.SubscriptionID(), azureBuiltInContributorID), nil
+	}
+}
+
+// Delete deletes the role assignments, except the ones of user-assigned identities still used by other machines.
 func (s *Service) Delete(ctx context.Context) error {
-	_, _, done := tele.StartSpanWithLogger(ctx, "roleassignments.Service.Delete")
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "roleassignments.Service.Delete")
+	defer done()
+
+	roleSpecs := s.Scope.RoleAssignmentSpecs()
+	ids := make([]string, 0, len(roleSpecs))
+	for _, roleSpec := range roleSpecs {
+		if roleSpec.Name != "" {
+			ids = append(ids, roleAssignmentID(s.roleAssignmentScope(roleSpec), roleSpec.Name))
+		}
+	}
+	if err := s.deleteStale(ctx, s.Scope.RoleAssignmentIDs(), ids); err != nil {
+		return err
+	}
+
+	for _, roleSpec := range roleSpecs {
+		if roleSpec.Name == "" {
+			continue
+		}
+		if roleSpec.UserAssignedIdentityID != "" {
+			shared, err := s.Scope.IsRoleAssignmentShared(ctx, roleSpec)
+			if err != nil {
+				return errors.Wrapf(err, "failed to check whether role assignment %s is used by other machines", roleSpec.Name)
+			}
+			if shared {
+				log.V(2).Info("skipping deletion of role assignment used by other machines", "role assignment", roleSpec.Name)
+				continue
+			}
+		}
+		if err := s.client.Delete(ctx, s.roleAssignmentScope(roleSpec), roleSpec.Name); err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete role assignment %s", roleSpec.Name)
+		}
+		log.V(2).Info("successfully deleted role assignment", "role assignment", roleSpec.Name)
+	}
+
+	return nil
+}
+
+// deleteStale deletes the previously created role assignments that are not declared anymore, except the ones still
+// used by other machines.
+func (s *Service) deleteStale(ctx context.Context, previousIDs, ids []string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "roleassignments.Service.deleteStale")
 	defer done()
 
+	for _, id := range previousIDs {
+		if containsID(ids, id) {
+			continue
+		}
+		scope, name, err := parseRoleAssignmentID(id)
+		if err != nil {
+			return err
+		}
+		shared, err := s.Scope.IsRoleAssignmentShared(ctx, azure.RoleAssignmentSpec{Name: name, Scope: scope})
+		if err != nil {
+			return errors.Wrapf(err, "failed to check whether role assignment %s is used by other machines", name)
+		}
+		if shared {
+			log.V(2).Info("skipping deletion of removed role assignment used by other machines", "role assignment", name)
+			continue
+		}
+		if err := s.client.Delete(ctx, scope, name); err != nil && !azure.ResourceNotFound(err) {
+			return errors.Wrapf(err, "failed to delete removed role assignment %s", name)
+		}
+		log.V(2).Info("successfully deleted removed role assignment", "role assignment", name)
+	}
 	return nil
 }
+
+// roleAssignmentID returns the resource ID of the role assignment with the given name at the given scope.
+func roleAssignmentID(scope, name string) string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(scope, "/"), roleAssignmentsProvider, name)
+}
+
+// parseRoleAssignmentID returns the scope and the name of the role assignment with the given resource ID.
+func parseRoleAssignmentID(id string) (string, string, error) {
+	i := strings.LastIndex(strings.ToLower(id), "/"+strings.ToLower(roleAssignmentsProvider)+"/")
+	if i < 0 {
+		return "", "", errors.Errorf("invalid role assignment ID %s", id)
+	}
+	return id[:i], id[i+len(roleAssignmentsProvider)+2:], nil
+}
+
+// containsID returns true if the resource IDs contain the given one, ignoring case.
+func containsID(ids []string, id string) bool {
+	for _, other := range ids {
+		if strings.EqualFold(other, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeIDs returns the resource IDs of both lists, without duplicates.
+func mergeIDs(ids, others []string) []string {
+	merged := append([]string{}, ids...)
+	for _, id := range others {
+		if !containsID(merged, id) {
+			merged = append(merged, id)
+		}
+	}
+	return merged
+}
+
+// identityKind describes the identity the role is assigned to.
+func identityKind(roleSpec azure.RoleAssignmentSpec) string {
+	if roleSpec.UserAssignedIdentityID != "" {
+		return "user assigned identity"
+	}
+	return "system assigned identity"
+}
+
+// vmPrincipalID returns the principal ID of the system-assigned identity of a VM, or of the given user-assigned one.
+func vmPrincipalID(identity *compute.VirtualMachineIdentity, userAssignedIdentityID string) (*string, error) {
+	if identity == nil {
+		return nil, errors.New("VM has no identity")
+	}
+	if userAssignedIdentityID == "" {
+		return identity.PrincipalID, nil
+	}
+	for id, userAssignedIdentity := range identity.UserAssignedIdentities {
+		if strings.EqualFold(id, userAssignedIdentityID) && userAssignedIdentity != nil {
+			return userAssignedIdentity.PrincipalID, nil
+		}
+	}
+	return nil, errors.Errorf("user assigned identity %s is not assigned to the VM", userAssignedIdentityID)
+}
+
+// vmssPrincipalID returns the principal ID of the system-assigned identity of a VMSS, or of the given user-assigned
+// one.
+func vmssPrincipalID(identity *compute.VirtualMachineScaleSetIdentity, userAssignedIdentityID string) (*string, error) {
+	if identity == nil {
+		return nil, errors.New("VMSS has no identity")
+	}
+	if userAssignedIdentityID == "" {
+		return identity.PrincipalID, nil
+	}
+	for id, userAssignedIdentity := range identity.UserAssignedIdentities {
+		if strings.EqualFold(id, userAssignedIdentityID) && userAssignedIdentity != nil {
+			return userAssignedIdentity.PrincipalID, nil
+		}
+	}
+	return nil, errors.Errorf("user assigned identity %s is not assigned to the VMSS", userAssignedIdentityID)
+}
@@ -40,6 +40,8 @@ var (
 		Name:          "test-vm",
 		ResourceGroup: "my-rg",
 	}
+	fakeUserAssignedIdentityID = "/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/acr-pull"
+	notFoundError              = autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not Found")
 )
 
 func TestReconcileRoleAssignmentsVM(t *testing.T) {
@@ -54,6 +56,8 @@ func TestReconcileRoleAssignmentsVM(t *testing.T) {
 			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
 				s.SubscriptionID().AnyTimes().Return("12345")
 				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
 				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
 					{
 						MachineName:  "test-vm",
@@ -65,6 +69,7 @@ func TestReconcileRoleAssignmentsVM(t *testing.T) {
 						PrincipalID: to.StringPtr("000"),
 					},
 				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid")).Return(authorization.RoleAssignment{}, notFoundError)
 				m.Create(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid"), gomock.AssignableToTypeOf(authorization.RoleAssignmentCreateParameters{
 					Properties: &authorization.RoleAssignmentProperties{
 						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c"),
@@ -79,6 +84,8 @@ func TestReconcileRoleAssignmentsVM(t *testing.T) {
 			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
 				s.SubscriptionID().AnyTimes().Return("12345")
 				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
 				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
 					{
 						MachineName:  "test-vm",
@@ -94,6 +101,8 @@ func TestReconcileRoleAssignmentsVM(t *testing.T) {
 			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
 				s.SubscriptionID().AnyTimes().Return("12345")
 				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
 				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
 					{
 						MachineName:  "test-vm",
@@ -105,9 +114,138 @@ func TestReconcileRoleAssignmentsVM(t *testing.T) {
 						PrincipalID: to.StringPtr("000"),
 					},
 				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid")).Return(authorization.RoleAssignment{}, notFoundError)
 				m.Create(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid"), gomock.AssignableToTypeOf(authorization.RoleAssignmentCreateParameters{})).Return(authorization.RoleAssignment{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
 			},
 		},
+		{
+			name:          "create a role assignment by role name for a user assigned identity",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:            "test-vm",
+						Name:                   "acr-pull-assignment",
+						ResourceType:           azure.VirtualMachine,
+						RoleDefinitionName:     "AcrPull",
+						Scope:                  "/subscriptions/12345/resourceGroups/acr-rg/providers/Microsoft.ContainerRegistry/registries/myacr",
+						UserAssignedIdentityID: fakeUserAssignedIdentityID,
+					},
+				})
+				v.Get(gomockinternal.AContext(), &fakeVMSpec).Return(compute.VirtualMachine{
+					Identity: &compute.VirtualMachineIdentity{
+						UserAssignedIdentities: map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue{
+							"/subscriptions/12345/resourcegroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/acr-pull": {
+								PrincipalID: to.StringPtr("111"),
+							},
+						},
+					},
+				}, nil)
+				m.ListRoleDefinitions(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/acr-rg/providers/Microsoft.ContainerRegistry/registries/myacr", "roleName eq 'AcrPull'").Return([]authorization.RoleDefinition{
+					{ID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/7f951dda-4ed3-4680-a7ca-43fe172d538d")},
+				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/acr-rg/providers/Microsoft.ContainerRegistry/registries/myacr", "acr-pull-assignment").Return(authorization.RoleAssignment{}, notFoundError)
+				m.Create(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/acr-rg/providers/Microsoft.ContainerRegistry/registries/myacr", "acr-pull-assignment", authorization.RoleAssignmentCreateParameters{
+					Properties: &authorization.RoleAssignmentProperties{
+						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/7f951dda-4ed3-4680-a7ca-43fe172d538d"),
+						PrincipalID:      to.StringPtr("111"),
+					},
+				})
+			},
+		},
+		{
+			name:          "role assignment already exists",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:      "test-vm",
+						Name:             "reader-assignment",
+						ResourceType:     azure.VirtualMachine,
+						RoleDefinitionID: "acdd72a7-3385-48ef-bd42-f606fba81ae7",
+						Scope:            "/subscriptions/12345/resourceGroups/my-rg",
+					},
+				})
+				v.Get(gomockinternal.AContext(), &fakeVMSpec).Return(compute.VirtualMachine{
+					Identity: &compute.VirtualMachineIdentity{
+						PrincipalID: to.StringPtr("000"),
+					},
+				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "reader-assignment").Return(authorization.RoleAssignment{
+					Properties: &authorization.RoleAssignmentPropertiesWithScope{
+						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"),
+						PrincipalID:      to.StringPtr("000"),
+					},
+				}, nil)
+			},
+		},
+		{
+			name:          "replace a role assignment of a previous identity",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:      "test-vm",
+						Name:             "reader-assignment",
+						ResourceType:     azure.VirtualMachine,
+						RoleDefinitionID: "acdd72a7-3385-48ef-bd42-f606fba81ae7",
+						Scope:            "/subscriptions/12345/resourceGroups/my-rg",
+					},
+				})
+				v.Get(gomockinternal.AContext(), &fakeVMSpec).Return(compute.VirtualMachine{
+					Identity: &compute.VirtualMachineIdentity{
+						PrincipalID: to.StringPtr("000"),
+					},
+				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "reader-assignment").Return(authorization.RoleAssignment{
+					Properties: &authorization.RoleAssignmentPropertiesWithScope{
+						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"),
+						PrincipalID:      to.StringPtr("999"),
+					},
+				}, nil)
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "reader-assignment")
+				m.Create(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "reader-assignment", authorization.RoleAssignmentCreateParameters{
+					Properties: &authorization.RoleAssignmentProperties{
+						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"),
+						PrincipalID:      to.StringPtr("000"),
+					},
+				})
+			},
+		},
+		{
+			name:          "user assigned identity not assigned to the VM",
+			expectedError: "cannot assign role to VM user assigned identity: user assigned identity /subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/acr-pull is not assigned to the VM",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_async.MockGetterMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:            "test-vm",
+						Name:                   "acr-pull-assignment",
+						ResourceType:           azure.VirtualMachine,
+						RoleDefinitionName:     "AcrPull",
+						UserAssignedIdentityID: fakeUserAssignedIdentityID,
+					},
+				})
+				v.Get(gomockinternal.AContext(), &fakeVMSpec).Return(compute.VirtualMachine{
+					Identity: &compute.VirtualMachineIdentity{},
+				}, nil)
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -151,6 +289,8 @@ func TestReconcileRoleAssignmentsVMSS(t *testing.T) {
 			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_scalesets.MockClientMockRecorder) {
 				s.SubscriptionID().AnyTimes().Return("12345")
 				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
 				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
 					{
 						MachineName:  "test-vmss",
@@ -162,6 +302,7 @@ func TestReconcileRoleAssignmentsVMSS(t *testing.T) {
 						PrincipalID: to.StringPtr("000"),
 					},
 				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid")).Return(authorization.RoleAssignment{}, notFoundError)
 				m.Create(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid"), gomock.AssignableToTypeOf(authorization.RoleAssignmentCreateParameters{
 					Properties: &authorization.RoleAssignmentProperties{
 						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/b24988ac-6180-42a0-ab88-20f7382dd24c"),
@@ -176,6 +317,8 @@ func TestReconcileRoleAssignmentsVMSS(t *testing.T) {
 			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_scalesets.MockClientMockRecorder) {
 				s.SubscriptionID().AnyTimes().Return("12345")
 				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
 				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
 					{
 						MachineName:  "test-vmss",
@@ -191,6 +334,8 @@ func TestReconcileRoleAssignmentsVMSS(t *testing.T) {
 			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_scalesets.MockClientMockRecorder) {
 				s.SubscriptionID().AnyTimes().Return("12345")
 				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
 				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
 					{
 						MachineName:  "test-vmss",
@@ -202,9 +347,118 @@ func TestReconcileRoleAssignmentsVMSS(t *testing.T) {
 						PrincipalID: to.StringPtr("000"),
 					},
 				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid")).Return(authorization.RoleAssignment{}, notFoundError)
 				m.Create(gomockinternal.AContext(), "/subscriptions/12345/", gomock.AssignableToTypeOf("uuid"), gomock.AssignableToTypeOf(authorization.RoleAssignmentCreateParameters{})).Return(authorization.RoleAssignment{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
 			},
 		},
+		{
+			name:          "role assignment created concurrently",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_scalesets.MockClientMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:            "test-vmss",
+						Name:                   "acr-pull-assignment",
+						ResourceType:           azure.VirtualMachineScaleSet,
+						RoleDefinitionID:       "7f951dda-4ed3-4680-a7ca-43fe172d538d",
+						Scope:                  "/subscriptions/12345/resourceGroups/my-rg",
+						UserAssignedIdentityID: fakeUserAssignedIdentityID,
+					},
+				})
+				v.Get(gomockinternal.AContext(), "my-rg", "test-vmss").Return(compute.VirtualMachineScaleSet{
+					Identity: &compute.VirtualMachineScaleSetIdentity{
+						UserAssignedIdentities: map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{
+							fakeUserAssignedIdentityID: {
+								PrincipalID: to.StringPtr("111"),
+							},
+						},
+					},
+				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment").Return(authorization.RoleAssignment{}, notFoundError)
+				m.Create(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment", gomock.AssignableToTypeOf(authorization.RoleAssignmentCreateParameters{})).Return(authorization.RoleAssignment{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 409}, "RoleAssignmentExists"))
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment").Return(authorization.RoleAssignment{}, nil)
+			},
+		},
+		{
+			name:          "conflicting role assignment under another name is returned",
+			expectedError: "cannot assign role to VMSS user assigned identity: #: RoleAssignmentExists: StatusCode=409",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_scalesets.MockClientMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs()
+				s.SetRoleAssignmentIDs(gomock.Any()).AnyTimes()
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:            "test-vmss",
+						Name:                   "acr-pull-assignment",
+						ResourceType:           azure.VirtualMachineScaleSet,
+						RoleDefinitionID:       "7f951dda-4ed3-4680-a7ca-43fe172d538d",
+						Scope:                  "/subscriptions/12345/resourceGroups/my-rg",
+						UserAssignedIdentityID: fakeUserAssignedIdentityID,
+					},
+				})
+				v.Get(gomockinternal.AContext(), "my-rg", "test-vmss").Return(compute.VirtualMachineScaleSet{
+					Identity: &compute.VirtualMachineScaleSetIdentity{
+						UserAssignedIdentities: map[string]*compute.VirtualMachineScaleSetIdentityUserAssignedIdentitiesValue{
+							fakeUserAssignedIdentityID: {
+								PrincipalID: to.StringPtr("111"),
+							},
+						},
+					},
+				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment").Return(authorization.RoleAssignment{}, notFoundError)
+				m.Create(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment", gomock.AssignableToTypeOf(authorization.RoleAssignmentCreateParameters{})).Return(authorization.RoleAssignment{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 409}, "RoleAssignmentExists"))
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment").Return(authorization.RoleAssignment{}, notFoundError)
+			},
+		},
+		{
+			name:          "delete role assignments removed from the spec",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder, v *mock_scalesets.MockClientMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.ResourceGroup().Return("my-rg")
+				s.RoleAssignmentIDs().Return([]string{
+					"/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.Authorization/roleAssignments/reader-assignment",
+					"/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.Authorization/roleAssignments/contributor-assignment",
+					"/subscriptions/12345/resourceGroups/acr-rg/providers/Microsoft.Authorization/roleAssignments/acr-pull-assignment",
+				})
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:      "test-vmss",
+						Name:             "reader-assignment",
+						ResourceType:     azure.VirtualMachineScaleSet,
+						RoleDefinitionID: "acdd72a7-3385-48ef-bd42-f606fba81ae7",
+						Scope:            "/subscriptions/12345/resourceGroups/my-rg",
+					},
+				})
+				s.SetRoleAssignmentIDs([]string{
+					"/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.Authorization/roleAssignments/reader-assignment",
+					"/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.Authorization/roleAssignments/contributor-assignment",
+					"/subscriptions/12345/resourceGroups/acr-rg/providers/Microsoft.Authorization/roleAssignments/acr-pull-assignment",
+				})
+				v.Get(gomockinternal.AContext(), "my-rg", "test-vmss").Return(compute.VirtualMachineScaleSet{
+					Identity: &compute.VirtualMachineScaleSetIdentity{
+						PrincipalID: to.StringPtr("000"),
+					},
+				}, nil)
+				m.Get(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "reader-assignment").Return(authorization.RoleAssignment{
+					Properties: &authorization.RoleAssignmentPropertiesWithScope{
+						RoleDefinitionID: to.StringPtr("/subscriptions/12345/providers/Microsoft.Authorization/roleDefinitions/acdd72a7-3385-48ef-bd42-f606fba81ae7"),
+						PrincipalID:      to.StringPtr("000"),
+					},
+				}, nil)
+				s.IsRoleAssignmentShared(gomockinternal.AContext(), azure.RoleAssignmentSpec{Name: "contributor-assignment", Scope: "/subscriptions/12345/resourceGroups/my-rg"}).Return(false, nil)
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "contributor-assignment")
+				s.IsRoleAssignmentShared(gomockinternal.AContext(), azure.RoleAssignmentSpec{Name: "acr-pull-assignment", Scope: "/subscriptions/12345/resourceGroups/acr-rg"}).Return(true, nil)
+				s.SetRoleAssignmentIDs([]string{
+					"/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.Authorization/roleAssignments/reader-assignment",
+				})
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -236,3 +490,120 @@ func TestReconcileRoleAssignmentsVMSS(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteRoleAssignments(t *testing.T) {
+	testcases := []struct {
+		name          string
+		expect        func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder)
+		expectedError string
+	}{
+		{
+			name:          "delete role assignments",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder) {
+				userAssignedSpec := azure.RoleAssignmentSpec{
+					MachineName:            "test-vm",
+					Name:                   "acr-pull-assignment",
+					ResourceType:           azure.VirtualMachine,
+					RoleDefinitionName:     "AcrPull",
+					Scope:                  "/subscriptions/12345/resourceGroups/my-rg",
+					UserAssignedIdentityID: fakeUserAssignedIdentityID,
+				}
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:  "test-vm",
+						Name:         "contributor-assignment",
+						ResourceType: azure.VirtualMachine,
+					},
+					userAssignedSpec,
+				})
+				s.RoleAssignmentIDs()
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/", "contributor-assignment")
+				s.IsRoleAssignmentShared(gomockinternal.AContext(), userAssignedSpec).Return(false, nil)
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "acr-pull-assignment").Return(notFoundError)
+			},
+		},
+		{
+			name:          "keep role assignments of user assigned identities used by other machines",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder) {
+				userAssignedSpec := azure.RoleAssignmentSpec{
+					MachineName:            "test-vm",
+					Name:                   "acr-pull-assignment",
+					ResourceType:           azure.VirtualMachine,
+					RoleDefinitionName:     "AcrPull",
+					Scope:                  "/subscriptions/12345/resourceGroups/my-rg",
+					UserAssignedIdentityID: fakeUserAssignedIdentityID,
+				}
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{userAssignedSpec})
+				s.RoleAssignmentIDs()
+				s.IsRoleAssignmentShared(gomockinternal.AContext(), userAssignedSpec).Return(true, nil)
+			},
+		},
+		{
+			name:          "delete role assignments removed from the spec",
+			expectedError: "",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:  "test-vmss",
+						Name:         "contributor-assignment",
+						ResourceType: azure.VirtualMachineScaleSet,
+					},
+				})
+				s.RoleAssignmentIDs().Return([]string{
+					"/subscriptions/12345/providers/Microsoft.Authorization/roleAssignments/contributor-assignment",
+					"/subscriptions/12345/resourceGroups/my-rg/providers/Microsoft.Authorization/roleAssignments/reader-assignment",
+				})
+				s.IsRoleAssignmentShared(gomockinternal.AContext(), azure.RoleAssignmentSpec{Name: "reader-assignment", Scope: "/subscriptions/12345/resourceGroups/my-rg"}).Return(false, nil)
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/resourceGroups/my-rg", "reader-assignment")
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/", "contributor-assignment")
+			},
+		},
+		{
+			name:          "error deleting a role assignment",
+			expectedError: "failed to delete role assignment contributor-assignment: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_roleassignments.MockRoleAssignmentScopeMockRecorder, m *mock_roleassignments.MockclientMockRecorder) {
+				s.SubscriptionID().AnyTimes().Return("12345")
+				s.RoleAssignmentSpecs().Return([]azure.RoleAssignmentSpec{
+					{
+						MachineName:  "test-vm",
+						Name:         "contributor-assignment",
+						ResourceType: azure.VirtualMachine,
+					},
+				})
+				s.RoleAssignmentIDs()
+				m.Delete(gomockinternal.AContext(), "/subscriptions/12345/", "contributor-assignment").Return(autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 500}, "Internal Server Error"))
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			scopeMock := mock_roleassignments.NewMockRoleAssignmentScope(mockCtrl)
+			clientMock := mock_roleassignments.NewMockclient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				client: clientMock,
+			}
+
+			err := s.Delete(context.TODO())
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
@@ -41,6 +42,7 @@ import (
 type Client interface {
 	List(context.Context, string) ([]compute.VirtualMachineScaleSet, error)
 	ListInstances(context.Context, string, string) ([]compute.VirtualMachineScaleSetVM, error)
+	ListFlexInstances(context.Context, string, string) ([]compute.VirtualMachine, error)
 	Get(context.Context, string, string) (compute.VirtualMachineScaleSet, error)
 	CreateOrUpdateAsync(context.Context, string, string, compute.VirtualMachineScaleSet) (*infrav1.Future, error)
 	UpdateAsync(context.Context, string, string, compute.VirtualMachineScaleSetUpdate) (*infrav1.Future, error)
@@ -52,8 +54,9 @@ type Client interface {
 type (
 	// AzureClient contains the Azure go-sdk Client.
 	AzureClient struct {
-		scalesetvms compute.VirtualMachineScaleSetVMsClient
-		scalesets   compute.VirtualMachineScaleSetsClient
+		scalesetvms     compute.VirtualMachineScaleSetVMsClient
+		scalesets       compute.VirtualMachineScaleSetsClient
+		virtualmachines compute.VirtualMachinesClient
 	}
 
 	genericScaleSetFuture interface {
@@ -73,11 +76,16 @@ type (
 
 var _ Client = &AzureClient{}
 
+// flexInstancesAPIVersion is the version of the compute API used to list the virtual machines of a Flexible virtual
+// machine scale set, the first one filtering virtual machines by scale set.
+const flexInstancesAPIVersion = "2022-03-01"
+
 // NewClient creates a new VMSS client from subscription ID.
 func NewClient(auth azure.Authorizer) *AzureClient {
 	return &AzureClient{
-		scalesetvms: newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
-		scalesets:   newVirtualMachineScaleSetsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesetvms:     newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesets:       newVirtualMachineScaleSetsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		virtualmachines: newVirtualMachinesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
 	}
 }
 
@@ -95,6 +103,13 @@ func newVirtualMachineScaleSetsClient(subscriptionID string, baseURI string, aut
 	return c
 }
 
+// newVirtualMachinesClient creates a new vm client from subscription ID.
+func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachinesClient {
+	c := compute.NewVirtualMachinesClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&c.Client, authorizer)
+	return c
+}
+
 // ListInstances retrieves information about the model views of a virtual machine scale set.
 func (ac *AzureClient) ListInstances(ctx context.Context, resourceGroupName, vmssName string) ([]compute.VirtualMachineScaleSetVM, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.ListInstances")
@@ -116,6 +131,56 @@ func (ac *AzureClient) ListInstances(ctx context.Context, resourceGroupName, vms
 	return instances, nil
 }
 
+// ListFlexInstances retrieves the virtual machines of a Flexible virtual machine scale set. Flexible instances are
+// standalone virtual machines, so they are listed from the resource group, filtered by their scale set ID.
+func (ac *AzureClient) ListFlexInstances(ctx context.Context, resourceGroupName, vmssID string) ([]compute.VirtualMachine, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.ListFlexInstances")
+	defer done()
+
+	pathParameters := map[string]interface{}{
+		"resourceGroupName": autorest.Encode("path", resourceGroupName),
+		"subscriptionId":    autorest.Encode("path", ac.virtualmachines.SubscriptionID),
+	}
+	queryParameters := map[string]interface{}{
+		"$filter":     autorest.Encode("query", fmt.Sprintf("'virtualMachineScaleSet/id' eq '%s'", vmssID)),
+		"api-version": flexInstancesAPIVersion,
+	}
+	req, err := autorest.Prepare((&http.Request{}).WithContext(ctx),
+		autorest.AsGet(),
+		autorest.WithBaseURL(ac.virtualmachines.BaseURI),
+		autorest.WithPathParameters("/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Compute/virtualMachines", pathParameters),
+		autorest.WithQueryParameters(queryParameters))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to prepare the request to list vms")
+	}
+
+	var instances []compute.VirtualMachine
+	for req != nil {
+		resp, err := ac.virtualmachines.ListSender(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to send the request to list vms")
+		}
+		result, err := ac.virtualmachines.ListResponder(resp)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list vms")
+		}
+		if result.Value != nil {
+			instances = append(instances, *result.Value...)
+		}
+
+		req = nil
+		if nextLink := to.String(result.NextLink); nextLink != "" {
+			req, err = autorest.Prepare((&http.Request{}).WithContext(ctx),
+				autorest.AsGet(),
+				autorest.WithBaseURL(nextLink))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to prepare the request to list the next page of vms")
+			}
+		}
+	}
+	return instances, nil
+}
+
 // List returns all scale sets in a resource group.
 func (ac *AzureClient) List(ctx context.Context, resourceGroupName string) ([]compute.VirtualMachineScaleSet, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.AzureClient.List")
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalesets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+func TestListFlexInstances(t *testing.T) {
+	g := NewWithT(t)
+
+	vmssID := "/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachineScaleSets/my-vmss"
+	var requests []*http.Request
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"value": [{"name": "my-vmss_1"}], "nextLink": "%s%s?page=2"}`, server.URL, r.URL.Path)
+			return
+		}
+		fmt.Fprint(w, `{"value": [{"name": "my-vmss_2"}]}`)
+	}))
+	defer server.Close()
+
+	ac := &AzureClient{virtualmachines: newVirtualMachinesClient("123", server.URL, autorest.NullAuthorizer{})}
+	instances, err := ac.ListFlexInstances(context.TODO(), "my-rg", vmssID)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(instances).To(HaveLen(2))
+	g.Expect(to.String(instances[0].Name)).To(Equal("my-vmss_1"))
+	g.Expect(to.String(instances[1].Name)).To(Equal("my-vmss_2"))
+
+	g.Expect(requests).To(HaveLen(2))
+	g.Expect(requests[0].URL.Path).To(Equal("/subscriptions/123/resourceGroups/my-rg/providers/Microsoft.Compute/virtualMachines"))
+	g.Expect(requests[0].URL.Query().Get("$filter")).To(Equal(fmt.Sprintf("'virtualMachineScaleSet/id' eq '%s'", vmssID)))
+	g.Expect(requests[0].URL.Query().Get("api-version")).To(Equal(flexInstancesAPIVersion))
+}
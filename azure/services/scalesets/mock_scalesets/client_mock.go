@@ -128,6 +128,21 @@ func (mr *MockClientMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockClient)(nil).List), arg0, arg1)
 }
 
+// ListFlexInstances mocks base method.
+func (m *MockClient) ListFlexInstances(arg0 context.Context, arg1, arg2 string) ([]compute.VirtualMachine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlexInstances", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]compute.VirtualMachine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlexInstances indicates an expected call of ListFlexInstances.
+func (mr *MockClientMockRecorder) ListFlexInstances(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlexInstances", reflect.TypeOf((*MockClient)(nil).ListFlexInstances), arg0, arg1, arg2)
+}
+
 // ListInstances mocks base method.
 func (m *MockClient) ListInstances(arg0 context.Context, arg1, arg2 string) ([]compute.VirtualMachineScaleSetVM, error) {
 	m.ctrl.T.Helper()
@@ -206,6 +206,18 @@ func (mr *MockScaleSetScopeMockRecorder) FailureDomains() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureDomains", reflect.TypeOf((*MockScaleSetScope)(nil).FailureDomains))
 }
 
+// FallBackToOnDemand mocks base method.
+func (m *MockScaleSetScope) FallBackToOnDemand(arg0 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FallBackToOnDemand", arg0)
+}
+
+// FallBackToOnDemand indicates an expected call of FallBackToOnDemand.
+func (mr *MockScaleSetScopeMockRecorder) FallBackToOnDemand(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FallBackToOnDemand", reflect.TypeOf((*MockScaleSetScope)(nil).FallBackToOnDemand), arg0)
+}
+
 // GetBootstrapData mocks base method.
 func (m *MockScaleSetScope) GetBootstrapData(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -293,6 +305,20 @@ func (mr *MockScaleSetScopeMockRecorder) MaxSurge() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxSurge", reflect.TypeOf((*MockScaleSetScope)(nil).MaxSurge))
 }
 
+// OnDemandScaleSetSpec mocks base method.
+func (m *MockScaleSetScope) OnDemandScaleSetSpec() *azure.ScaleSetSpec {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OnDemandScaleSetSpec")
+	ret0, _ := ret[0].(*azure.ScaleSetSpec)
+	return ret0
+}
+
+// OnDemandScaleSetSpec indicates an expected call of OnDemandScaleSetSpec.
+func (mr *MockScaleSetScopeMockRecorder) OnDemandScaleSetSpec() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnDemandScaleSetSpec", reflect.TypeOf((*MockScaleSetScope)(nil).OnDemandScaleSetSpec))
+}
+
 // ResourceGroup mocks base method.
 func (m *MockScaleSetScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -357,6 +383,18 @@ func (mr *MockScaleSetScopeMockRecorder) SetLongRunningOperationState(arg0 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLongRunningOperationState", reflect.TypeOf((*MockScaleSetScope)(nil).SetLongRunningOperationState), arg0)
 }
 
+// SetOnDemandVMSSState mocks base method.
+func (m *MockScaleSetScope) SetOnDemandVMSSState(arg0 *azure.VMSS) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetOnDemandVMSSState", arg0)
+}
+
+// SetOnDemandVMSSState indicates an expected call of SetOnDemandVMSSState.
+func (mr *MockScaleSetScopeMockRecorder) SetOnDemandVMSSState(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOnDemandVMSSState", reflect.TypeOf((*MockScaleSetScope)(nil).SetOnDemandVMSSState), arg0)
+}
+
 // SetProviderID mocks base method.
 func (m *MockScaleSetScope) SetProviderID(arg0 string) {
 	m.ctrl.T.Helper()
@@ -369,6 +407,18 @@ func (mr *MockScaleSetScopeMockRecorder) SetProviderID(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProviderID", reflect.TypeOf((*MockScaleSetScope)(nil).SetProviderID), arg0)
 }
 
+// SetSpotCapacityAvailable mocks base method.
+func (m *MockScaleSetScope) SetSpotCapacityAvailable() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetSpotCapacityAvailable")
+}
+
+// SetSpotCapacityAvailable indicates an expected call of SetSpotCapacityAvailable.
+func (mr *MockScaleSetScopeMockRecorder) SetSpotCapacityAvailable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSpotCapacityAvailable", reflect.TypeOf((*MockScaleSetScope)(nil).SetSpotCapacityAvailable))
+}
+
 // SetVMSSState mocks base method.
 func (m *MockScaleSetScope) SetVMSSState(arg0 *azure.VMSS) {
 	m.ctrl.T.Helper()
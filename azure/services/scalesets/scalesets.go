@@ -49,6 +49,10 @@ type (
 		SetAnnotation(string, string)
 		SetProviderID(string)
 		SetVMSSState(*azure.VMSS)
+		OnDemandScaleSetSpec() *azure.ScaleSetSpec
+		SetOnDemandVMSSState(*azure.VMSS)
+		FallBackToOnDemand(error)
+		SetSpotCapacityAvailable()
 	}
 
 	// Service provides operations on Azure resources.
@@ -78,13 +82,43 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 		return err
 	}
 
-	var err error
-
 	scaleSetSpec := s.Scope.ScaleSetSpec()
+	err := s.reconcileScaleSet(ctx, scaleSetSpec, func(vmss *azure.VMSS) {
+		s.Scope.SetProviderID(azure.ProviderIDPrefix + vmss.ID)
+		s.Scope.SetVMSSState(vmss)
+	})
+
+	onDemandSpec := s.Scope.OnDemandScaleSetSpec()
+	if onDemandSpec == nil {
+		return err
+	}
+
+	// the machine pool mixes on-demand and Spot instances, so reconcile the on-demand scale set as well
+	switch {
+	case err != nil && azure.CapacityUnavailable(err):
+		log.Info("spot capacity is unavailable, falling back to on-demand instances", "scale set", scaleSetSpec.Name, "error", err.Error())
+		s.Scope.DeleteLongRunningOperationState(scaleSetSpec.Name, scope.ScalesetsServiceName)
+		s.Scope.FallBackToOnDemand(err)
+		onDemandSpec = s.Scope.OnDemandScaleSetSpec()
+	case err != nil:
+		return err
+	default:
+		s.Scope.SetSpotCapacityAvailable()
+	}
+
+	return s.reconcileScaleSet(ctx, *onDemandSpec, s.Scope.SetOnDemandVMSSState)
+}
+
+// reconcileScaleSet idempotently gets, creates, and updates a single scale set, saving its state with setState.
+func (s *Service) reconcileScaleSet(ctx context.Context, scaleSetSpec azure.ScaleSetSpec, setState func(*azure.VMSS)) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.reconcileScaleSet")
+	defer done()
+
+	var err error
 
 	// check if there is an ongoing long running operation
 	var (
-		future      = s.Scope.GetLongRunningOperationState(s.Scope.ScaleSetSpec().Name, scope.ScalesetsServiceName)
+		future      = s.Scope.GetLongRunningOperationState(scaleSetSpec.Name, scope.ScalesetsServiceName)
 		fetchedVMSS *azure.VMSS
 	)
 
@@ -98,8 +132,7 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 		}
 
 		if fetchedVMSS != nil {
-			s.Scope.SetProviderID(azure.ProviderIDPrefix + fetchedVMSS.ID)
-			s.Scope.SetVMSSState(fetchedVMSS)
+			setState(fetchedVMSS)
 		}
 	}()
 
@@ -115,7 +148,7 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 		return errors.Wrapf(err, "failed to get VMSS %s", scaleSetSpec.Name)
 	case err != nil && azure.ResourceNotFound(err):
 		// HTTP(404) resource was not found, so we need to create it with a PUT
-		future, err = s.createVMSS(ctx)
+		future, err = s.createVMSS(ctx, scaleSetSpec)
 		if err != nil {
 			return errors.Wrap(err, "failed to start creating VMSS")
 		}
@@ -123,7 +156,7 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 		// HTTP(200)
 		// VMSS already exists and may have changes; update it with a PATCH
 		// we do this to avoid overwriting fields in networkProfile modified by cloud-provider
-		future, err = s.patchVMSSIfNeeded(ctx, fetchedVMSS)
+		future, err = s.patchVMSSIfNeeded(ctx, scaleSetSpec, fetchedVMSS)
 		if err != nil {
 			return errors.Wrap(err, "failed to start updating VMSS")
 		}
@@ -139,34 +172,48 @@ func (s *Service) Reconcile(ctx context.Context) (retErr error) {
 	}
 
 	// if we get to here, we have completed any long running VMSS operations (creates / updates)
-	s.Scope.DeleteLongRunningOperationState(s.Scope.ScaleSetSpec().Name, scope.ScalesetsServiceName)
+	s.Scope.DeleteLongRunningOperationState(scaleSetSpec.Name, scope.ScalesetsServiceName)
 	return nil
 }
 
 // Delete deletes a scale set asynchronously. Delete sends a DELETE request to Azure and if accepted without error,
 // the VMSS will be considered deleted. The actual delete in Azure may take longer, but should eventually complete.
 func (s *Service) Delete(ctx context.Context) error {
-	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.Delete")
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.Delete")
 	defer done()
 
-	var err error
+	if err := s.deleteScaleSet(ctx, s.Scope.ScaleSetSpec().Name, s.Scope.SetVMSSState); err != nil {
+		return err
+	}
+
+	if onDemandSpec := s.Scope.OnDemandScaleSetSpec(); onDemandSpec != nil {
+		return s.deleteScaleSet(ctx, onDemandSpec.Name, s.Scope.SetOnDemandVMSSState)
+	}
 
-	vmssSpec := s.Scope.ScaleSetSpec()
+	return nil
+}
+
+// deleteScaleSet deletes a single scale set asynchronously, saving its state with setState.
+func (s *Service) deleteScaleSet(ctx context.Context, vmssName string, setState func(*azure.VMSS)) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.deleteScaleSet")
+	defer done()
+
+	var err error
 
 	defer func() {
 		// save the updated state of the VMSS for the MachinePoolScope to use for updating K8s state
-		fetchedVMSS, err := s.getVirtualMachineScaleSet(ctx, vmssSpec.Name)
+		fetchedVMSS, err := s.getVirtualMachineScaleSet(ctx, vmssName)
 		if err != nil && !azure.ResourceNotFound(err) {
 			log.Error(err, "failed to get vmss in deferred update")
 		}
 
 		if fetchedVMSS != nil {
-			s.Scope.SetVMSSState(fetchedVMSS)
+			setState(fetchedVMSS)
 		}
 	}()
 
 	// check if there is an ongoing long running operation
-	future := s.Scope.GetLongRunningOperationState(vmssSpec.Name, scope.ScalesetsServiceName)
+	future := s.Scope.GetLongRunningOperationState(vmssName, scope.ScalesetsServiceName)
 	if future != nil {
 		// if the operation is not complete this will return an error
 		_, err := s.GetResultIfDone(ctx, future)
@@ -175,19 +222,19 @@ func (s *Service) Delete(ctx context.Context) error {
 		}
 
 		// ScaleSet has been deleted
-		s.Scope.DeleteLongRunningOperationState(vmssSpec.Name, scope.ScalesetsServiceName)
+		s.Scope.DeleteLongRunningOperationState(vmssName, scope.ScalesetsServiceName)
 		return nil
 	}
 
 	// no long running delete operation is active, so delete the ScaleSet
-	log.V(2).Info("deleting VMSS", "scale set", vmssSpec.Name)
-	future, err = s.Client.DeleteAsync(ctx, s.Scope.ResourceGroup(), vmssSpec.Name)
+	log.V(2).Info("deleting VMSS", "scale set", vmssName)
+	future, err = s.Client.DeleteAsync(ctx, s.Scope.ResourceGroup(), vmssName)
 	if err != nil {
 		if azure.ResourceNotFound(err) {
 			// already deleted
 			return nil
 		}
-		return errors.Wrapf(err, "failed to delete VMSS %s in resource group %s", vmssSpec.Name, s.Scope.ResourceGroup())
+		return errors.Wrapf(err, "failed to delete VMSS %s in resource group %s", vmssName, s.Scope.ResourceGroup())
 	}
 
 	s.Scope.SetLongRunningOperationState(future)
@@ -199,16 +246,14 @@ func (s *Service) Delete(ctx context.Context) error {
 	}
 
 	// future is either nil, or the result of the future is complete
-	s.Scope.DeleteLongRunningOperationState(vmssSpec.Name, scope.ScalesetsServiceName)
+	s.Scope.DeleteLongRunningOperationState(vmssName, scope.ScalesetsServiceName)
 	return nil
 }
 
-func (s *Service) createVMSS(ctx context.Context) (*infrav1.Future, error) {
+func (s *Service) createVMSS(ctx context.Context, spec azure.ScaleSetSpec) (*infrav1.Future, error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.createVMSS")
 	defer done()
 
-	spec := s.Scope.ScaleSetSpec()
-
 	vmss, err := s.buildVMSSFromSpec(ctx, spec)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed building VMSS from spec")
@@ -224,12 +269,10 @@ func (s *Service) createVMSS(ctx context.Context) (*infrav1.Future, error) {
 	return future, err
 }
 
-func (s *Service) patchVMSSIfNeeded(ctx context.Context, infraVMSS *azure.VMSS) (*infrav1.Future, error) {
+func (s *Service) patchVMSSIfNeeded(ctx context.Context, spec azure.ScaleSetSpec, infraVMSS *azure.VMSS) (*infrav1.Future, error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.patchVMSSIfNeeded")
 	defer done()
 
-	spec := s.Scope.ScaleSetSpec()
-
 	vmss, err := s.buildVMSSFromSpec(ctx, spec)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to generate scale set update parameters for %s", spec.Name)
@@ -253,9 +296,18 @@ func (s *Service) patchVMSSIfNeeded(ctx context.Context, infraVMSS *azure.VMSS)
 		patch.Sku.Capacity = to.Int64Ptr(surge)
 	}
 
+	// The automatic repairs policy does not change the model of the instances, so it is patched without surging.
+	hasRepairsPolicyChanges := infraVMSS.AutomaticRepairsGracePeriod != spec.AutomaticRepairsGracePeriod
+	if hasRepairsPolicyChanges && spec.AutomaticRepairsGracePeriod == "" && patch.VirtualMachineScaleSetUpdateProperties != nil {
+		// omitting the policy from the patch would leave it enabled
+		patch.VirtualMachineScaleSetUpdateProperties.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+			Enabled: to.BoolPtr(false),
+		}
+	}
+
 	// If there are no model changes and no increase in the replica count, do not update the VMSS.
 	// Decreases in replica count is handled by deleting AzureMachinePoolMachine instances in the MachinePoolScope
-	if *patch.Sku.Capacity <= infraVMSS.Capacity && !hasModelChanges {
+	if *patch.Sku.Capacity <= infraVMSS.Capacity && !hasModelChanges && !hasRepairsPolicyChanges {
 		log.V(4).Info("nothing to update on vmss", "scale set", spec.Name, "newReplicas", *patch.Sku.Capacity, "oldReplicas", infraVMSS.Capacity, "hasChanges", hasModelChanges)
 		return nil, nil
 	}
@@ -319,6 +371,11 @@ func (s *Service) validateSpec(ctx context.Context) error {
 		return azure.WithTerminalError(errors.Errorf("encryption at host is not supported for VM type %s", spec.Size))
 	}
 
+	// Azure can only balance instances across zones the scale set is spread over
+	if to.Bool(spec.ZoneBalance) && len(spec.FailureDomains) == 0 {
+		return azure.WithTerminalError(errors.Errorf("zone balance of scale set %s requires failure domains", spec.Name))
+	}
+
 	// check the support for ultra disks based on location and vm size
 	for _, disks := range spec.DataDisks {
 		location := s.Scope.Location()
@@ -364,7 +421,12 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 		vmssSpec.AcceleratedNetworking = &accelNet
 	}
 
-	extensions := s.generateExtensions()
+	vnetSubscriptionID := vmssSpec.VNetSubscriptionID
+	if vnetSubscriptionID == "" {
+		vnetSubscriptionID = s.Scope.SubscriptionID()
+	}
+
+	extensions := s.generateExtensions(vmssSpec.Name)
 
 	storageProfile, err := s.generateStorageProfile(ctx, vmssSpec, sku)
 	if err != nil {
@@ -413,14 +475,10 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 			},
 			Overprovision: to.BoolPtr(false),
 			VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
-				OsProfile:       osProfile,
-				StorageProfile:  storageProfile,
-				SecurityProfile: securityProfile,
-				DiagnosticsProfile: &compute.DiagnosticsProfile{
-					BootDiagnostics: &compute.BootDiagnostics{
-						Enabled: to.BoolPtr(true),
-					},
-				},
+				OsProfile:          osProfile,
+				StorageProfile:     storageProfile,
+				SecurityProfile:    securityProfile,
+				DiagnosticsProfile: converters.GetDiagnosticsProfile(vmssSpec.Diagnostics),
 				NetworkProfile: &compute.VirtualMachineScaleSetNetworkProfile{
 					NetworkInterfaceConfigurations: &[]compute.VirtualMachineScaleSetNetworkConfiguration{
 						{
@@ -433,7 +491,7 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 										Name: to.StringPtr(vmssSpec.Name + "-ipconfig"),
 										VirtualMachineScaleSetIPConfigurationProperties: &compute.VirtualMachineScaleSetIPConfigurationProperties{
 											Subnet: &compute.APIEntityReference{
-												ID: to.StringPtr(azure.SubnetID(s.Scope.SubscriptionID(), vmssSpec.VNetResourceGroup, vmssSpec.VNetName, vmssSpec.SubnetName)),
+												ID: to.StringPtr(azure.SubnetID(vnetSubscriptionID, vmssSpec.VNetResourceGroup, vmssSpec.VNetName, vmssSpec.SubnetName)),
 											},
 											Primary:                         to.BoolPtr(true),
 											PrivateIPAddressVersion:         compute.IPVersionIPv4,
@@ -472,6 +530,25 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 		}
 	}
 
+	if vmssSpec.OrchestrationMode == infrav1.FlexibleOrchestrationMode {
+		// Flexible scale sets do not support upgrade policies or overprovisioning, and create a network interface
+		// resource for each instance.
+		vmss.VirtualMachineScaleSetProperties.OrchestrationMode = compute.OrchestrationModeFlexible
+		vmss.VirtualMachineScaleSetProperties.UpgradePolicy = nil
+		vmss.VirtualMachineScaleSetProperties.Overprovision = nil
+		// A fault domain count of 1 spreads the instances across as many fault domains as possible.
+		vmss.VirtualMachineScaleSetProperties.PlatformFaultDomainCount = to.Int32Ptr(1)
+		vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.NetworkProfile.NetworkAPIVersion = compute.NetworkAPIVersionTwoZeroTwoZeroHyphenMinusOneOneHyphenMinusZeroOne
+	}
+
+	if vmssSpec.PlatformFaultDomainCount != nil {
+		vmss.VirtualMachineScaleSetProperties.PlatformFaultDomainCount = vmssSpec.PlatformFaultDomainCount
+	}
+
+	if vmssSpec.ZoneBalance != nil && len(vmssSpec.FailureDomains) > 0 {
+		vmss.VirtualMachineScaleSetProperties.ZoneBalance = vmssSpec.ZoneBalance
+	}
+
 	for _, dataDisk := range vmssSpec.DataDisks {
 		if dataDisk.ManagedDisk != nil && dataDisk.ManagedDisk.StorageAccountType == string(compute.StorageAccountTypesUltraSSDLRS) {
 			vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{
@@ -489,6 +566,13 @@ func (s *Service) buildVMSSFromSpec(ctx context.Context, vmssSpec azure.ScaleSet
 		}
 	}
 
+	if vmssSpec.AutomaticRepairsGracePeriod != "" {
+		vmss.VirtualMachineScaleSetProperties.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+			Enabled:     to.BoolPtr(true),
+			GracePeriod: to.StringPtr(vmssSpec.AutomaticRepairsGracePeriod),
+		}
+	}
+
 	tags := infrav1.Build(infrav1.BuildParams{
 		ClusterName: s.Scope.ClusterName(),
 		Lifecycle:   infrav1.ResourceLifecycleOwned,
@@ -511,12 +595,7 @@ func (s *Service) getVirtualMachineScaleSet(ctx context.Context, vmssName string
 		return nil, errors.Wrap(err, "failed to get existing vmss")
 	}
 
-	vmssInstances, err := s.Client.ListInstances(ctx, s.Scope.ResourceGroup(), vmssName)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list instances")
-	}
-
-	return converters.SDKToVMSS(vmss, vmssInstances), nil
+	return s.withInstances(ctx, s.Scope.ResourceGroup(), vmssName, vmss)
 }
 
 // getVirtualMachineScaleSetIfDone gets a Virtual Machine Scale Set and its instances from Azure if the future is completed.
@@ -529,7 +608,29 @@ func (s *Service) getVirtualMachineScaleSetIfDone(ctx context.Context, future *i
 		return nil, errors.Wrap(err, "failed to get result from future")
 	}
 
-	vmssInstances, err := s.Client.ListInstances(ctx, future.ResourceGroup, future.Name)
+	return s.withInstances(ctx, future.ResourceGroup, future.Name, vmss)
+}
+
+// withInstances lists the instances of a Virtual Machine Scale Set and converts both to an azure.VMSS. Instances of
+// a Flexible scale set are standalone virtual machines, which the scale set VM API does not return.
+func (s *Service) withInstances(ctx context.Context, resourceGroup, vmssName string, vmss compute.VirtualMachineScaleSet) (*azure.VMSS, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesets.Service.withInstances")
+	defer done()
+
+	if vmss.VirtualMachineScaleSetProperties != nil && vmss.OrchestrationMode == compute.OrchestrationModeFlexible {
+		vms, err := s.Client.ListFlexInstances(ctx, resourceGroup, to.String(vmss.ID))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list instances")
+		}
+
+		result := converters.SDKToVMSS(vmss, nil)
+		for _, vm := range vms {
+			result.Instances = append(result.Instances, *converters.SDKVMToVMSSVM(vm))
+		}
+		return result, nil
+	}
+
+	vmssInstances, err := s.Client.ListInstances(ctx, resourceGroup, vmssName)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list instances")
 	}
@@ -537,20 +638,28 @@ func (s *Service) getVirtualMachineScaleSetIfDone(ctx context.Context, future *i
 	return converters.SDKToVMSS(vmss, vmssInstances), nil
 }
 
-func (s *Service) generateExtensions() []compute.VirtualMachineScaleSetExtension {
-	extensions := make([]compute.VirtualMachineScaleSetExtension, len(s.Scope.VMSSExtensionSpecs()))
-	for i, extensionSpec := range s.Scope.VMSSExtensionSpecs() {
+func (s *Service) generateExtensions(vmssName string) []compute.VirtualMachineScaleSetExtension {
+	extensions := []compute.VirtualMachineScaleSetExtension{}
+	for _, extensionSpec := range s.Scope.VMSSExtensionSpecs() {
 		extensionSpec := extensionSpec
-		extensions[i] = compute.VirtualMachineScaleSetExtension{
+		if extensionSpec.VMName != vmssName {
+			continue
+		}
+		// leave settings unset rather than null for extensions without public settings
+		var settings interface{}
+		if len(extensionSpec.Settings) > 0 {
+			settings = extensionSpec.Settings
+		}
+		extensions = append(extensions, compute.VirtualMachineScaleSetExtension{
 			Name: &extensionSpec.Name,
 			VirtualMachineScaleSetExtensionProperties: &compute.VirtualMachineScaleSetExtensionProperties{
 				Publisher:          to.StringPtr(extensionSpec.Publisher),
 				Type:               to.StringPtr(extensionSpec.Name),
 				TypeHandlerVersion: to.StringPtr(extensionSpec.Version),
-				Settings:           nil,
+				Settings:           settings,
 				ProtectedSettings:  extensionSpec.ProtectedSettings,
 			},
-		}
+		})
 	}
 	return extensions
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
 	"github.com/Azure/go-autorest/autorest"
+	autorestazure "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
@@ -47,6 +48,7 @@ const (
 	defaultSubscriptionID = "123"
 	defaultResourceGroup  = "my-rg"
 	defaultVMSSName       = "my-vmss"
+	onDemandVMSSName      = "my-vmss-ondemand"
 )
 
 func init() {
@@ -164,6 +166,53 @@ func TestGetExistingVMSS(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name:     "get existing flexible vmss",
+			vmssName: "my-vmss",
+			result: &azure.VMSS{
+				ID:       "my-id",
+				Name:     "my-vmss",
+				State:    "Succeeded",
+				Sku:      "Standard_D2",
+				Capacity: int64(1),
+				Instances: []azure.VMSSVM{
+					{
+						ID:         "my-vm-id",
+						InstanceID: "my-vmss_0123abcd",
+						Name:       "my-vmssa1b2c3",
+						State:      "Succeeded",
+					},
+				},
+			},
+			expectedError: "",
+			expect: func(s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				s.ResourceGroup().AnyTimes().Return("my-rg")
+				m.Get(gomockinternal.AContext(), "my-rg", "my-vmss").Return(compute.VirtualMachineScaleSet{
+					ID:   to.StringPtr("my-id"),
+					Name: to.StringPtr("my-vmss"),
+					Sku: &compute.Sku{
+						Capacity: to.Int64Ptr(1),
+						Name:     to.StringPtr("Standard_D2"),
+					},
+					VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+						OrchestrationMode: compute.OrchestrationModeFlexible,
+						ProvisioningState: to.StringPtr("Succeeded"),
+					},
+				}, nil)
+				m.ListFlexInstances(gomockinternal.AContext(), "my-rg", "my-id").Return([]compute.VirtualMachine{
+					{
+						ID:   to.StringPtr("my-vm-id"),
+						Name: to.StringPtr("my-vmss_0123abcd"),
+						VirtualMachineProperties: &compute.VirtualMachineProperties{
+							ProvisioningState: to.StringPtr("Succeeded"),
+							OsProfile: &compute.OSProfile{
+								ComputerName: to.StringPtr("my-vmssa1b2c3"),
+							},
+						},
+					},
+				}, nil)
+			},
+		},
 		{
 			name:          "list instances fails",
 			vmssName:      "my-vmss",
@@ -298,6 +347,100 @@ func TestReconcileVMSS(t *testing.T) {
 				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE_AN"), putFuture)
 			},
 		},
+		{
+			name:          "should start creating a flexible vmss",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.OrchestrationMode = infrav1.FlexibleOrchestrationMode
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.OrchestrationMode = compute.OrchestrationModeFlexible
+				vmss.VirtualMachineScaleSetProperties.UpgradePolicy = nil
+				vmss.VirtualMachineScaleSetProperties.Overprovision = nil
+				vmss.VirtualMachineScaleSetProperties.PlatformFaultDomainCount = to.Int32Ptr(1)
+				vmss.VirtualMachineScaleSetProperties.VirtualMachineProfile.NetworkProfile.NetworkAPIVersion = compute.NetworkAPIVersionTwoZeroTwoZeroHyphenMinusOneOneHyphenMinusZeroOne
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should start creating a vmss with automatic repairs",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.AutomaticRepairsGracePeriod = "PT30M"
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+					Enabled:     to.BoolPtr(true),
+					GracePeriod: to.StringPtr("PT30M"),
+				}
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should start creating a zone balanced vmss with a fault domain count",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.ZoneBalance = to.BoolPtr(true)
+				spec.PlatformFaultDomainCount = to.Int32Ptr(2)
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+				setupDefaultVMSSStartCreatingExpectations(s, m)
+				vmss := newDefaultVMSS("VM_SIZE")
+				vmss.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				vmss.VirtualMachineScaleSetProperties.ZoneBalance = to.BoolPtr(true)
+				vmss.VirtualMachineScaleSetProperties.PlatformFaultDomainCount = to.Int32Ptr(2)
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(vmss)).
+					Return(putFuture, nil)
+				setupCreatingSucceededExpectations(s, m, newDefaultExistingVMSS("VM_SIZE"), putFuture)
+			},
+		},
+		{
+			name:          "should fail to create a zone balanced vmss without failure domains",
+			expectedError: "reconcile error that cannot be recovered occurred: zone balance of scale set my-vmss requires failure domains. Object will not be requeued",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				s.ScaleSetSpec().Return(azure.ScaleSetSpec{
+					Name:        defaultVMSSName,
+					Size:        "VM_SIZE",
+					Capacity:    2,
+					SSHKeyData:  "ZmFrZXNzaGtleQo=",
+					ZoneBalance: to.BoolPtr(true),
+				})
+			},
+		},
 		{
 			name:          "should start creating a vmss with spot vm",
 			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss is not done",
@@ -493,6 +636,127 @@ func TestReconcileVMSS(t *testing.T) {
 				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(instances, nil)
 			},
 		},
+		{
+			name:          "should disable automatic repairs when updating a scale set which no longer has them",
+			expectedError: "failed to get VMSS my-vmss after create or update: failed to get result from future: operation type PATCH on Azure resource my-rg/my-vmss is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spec := newDefaultVMSSSpec()
+				spec.Capacity = 2
+				spec.DataDisks = append(spec.DataDisks, infrav1.DataDisk{
+					NameSuffix: "my_disk_with_ultra_disks",
+					DiskSizeGB: 128,
+					Lun:        to.Int32Ptr(3),
+					ManagedDisk: &infrav1.ManagedDiskParameters{
+						StorageAccountType: "UltraSSD_LRS",
+					},
+				})
+				s.ScaleSetSpec().Return(spec).AnyTimes()
+
+				setupDefaultVMSSUpdateExpectations(s)
+				existingVMSS := newDefaultExistingVMSS("VM_SIZE")
+				existingVMSS.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+				existingVMSS.VirtualMachineScaleSetProperties.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{
+					Enabled:     to.BoolPtr(true),
+					GracePeriod: to.StringPtr("PT30M"),
+				}
+				existingVMSS.Sku.Capacity = to.Int64Ptr(2)
+				instances := newDefaultInstances()
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(existingVMSS, nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(instances, nil)
+
+				clone := newDefaultExistingVMSS("VM_SIZE")
+				clone.Sku.Capacity = to.Int64Ptr(3)
+				clone.VirtualMachineScaleSetProperties.AdditionalCapabilities = &compute.AdditionalCapabilities{UltraSSDEnabled: pointer.Bool(true)}
+
+				patchVMSS, err := getVMSSUpdateFromVMSS(clone)
+				g.Expect(err).NotTo(HaveOccurred())
+				patchVMSS.VirtualMachineProfile.StorageProfile.ImageReference.Version = to.StringPtr("2.0")
+				patchVMSS.VirtualMachineProfile.NetworkProfile = nil
+				patchVMSS.AutomaticRepairsPolicy = &compute.AutomaticRepairsPolicy{Enabled: to.BoolPtr(false)}
+				m.UpdateAsync(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName, gomockinternal.DiffEq(patchVMSS)).
+					Return(patchFuture, nil)
+				s.SetLongRunningOperationState(patchFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), patchFuture).Return(compute.VirtualMachineScaleSet{}, azure.NewOperationNotDoneError(patchFuture))
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(clone, nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(instances, nil)
+			},
+		},
+		{
+			name:          "should fall back to on-demand instances when spot capacity is unavailable",
+			expectedError: "failed to get VMSS my-vmss-ondemand after create or update: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss-ondemand is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				spotSpec := newDefaultVMSSSpec()
+				spotSpec.SpotVMOptions = &infrav1.SpotVMOptions{}
+				onDemandSpec := newDefaultVMSSSpec()
+				onDemandSpec.Name = onDemandVMSSName
+				onDemandSpec.Capacity = 3
+				s.ScaleSetSpec().Return(spotSpec).AnyTimes()
+				s.OnDemandScaleSetSpec().Return(&onDemandSpec).Times(2)
+				setupDefaultVMSSExpectations(s)
+
+				spotFuture := &infrav1.Future{
+					Type:          infrav1.PatchFuture,
+					ResourceGroup: defaultResourceGroup,
+					Name:          defaultVMSSName,
+				}
+				s.GetLongRunningOperationState(defaultVMSSName, scope.ScalesetsServiceName).Return(spotFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), spotFuture).
+					Return(compute.VirtualMachineScaleSet{}, &autorestazure.ServiceError{Code: "OverconstrainedAllocationRequest", Message: "Allocation failed."})
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(newDefaultExistingVMSS("VM_SIZE"), nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, defaultVMSSName).Return(newDefaultInstances(), nil)
+				s.SetVMSSState(gomock.Any())
+				s.SetProviderID(azure.ProviderIDPrefix + "vmss-id")
+				s.DeleteLongRunningOperationState(defaultVMSSName, scope.ScalesetsServiceName)
+				s.FallBackToOnDemand(gomock.Any())
+
+				onDemandFuture := &infrav1.Future{
+					Type:          infrav1.PutFuture,
+					ResourceGroup: defaultResourceGroup,
+					Name:          onDemandVMSSName,
+				}
+				s.GetLongRunningOperationState(onDemandVMSSName, scope.ScalesetsServiceName).Return(nil)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, onDemandVMSSName).
+					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found")).Times(2)
+				m.CreateOrUpdateAsync(gomockinternal.AContext(), defaultResourceGroup, onDemandVMSSName, gomock.AssignableToTypeOf(compute.VirtualMachineScaleSet{})).
+					DoAndReturn(func(_ context.Context, _, _ string, vmss compute.VirtualMachineScaleSet) (*infrav1.Future, error) {
+						g.Expect(vmss.Sku.Capacity).To(Equal(to.Int64Ptr(3)))
+						g.Expect(vmss.VirtualMachineProfile.Priority).To(BeEmpty())
+						g.Expect(*vmss.VirtualMachineProfile.ExtensionProfile.Extensions).To(BeEmpty())
+						return onDemandFuture, nil
+					})
+				s.SetLongRunningOperationState(onDemandFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), onDemandFuture).Return(compute.VirtualMachineScaleSet{}, azure.NewOperationNotDoneError(onDemandFuture))
+			},
+		},
+		{
+			name:          "should reconcile the on-demand scale set once the spot scale set is done",
+			expectedError: "failed to get VMSS my-vmss-ondemand: failed to get result from future: operation type PUT on Azure resource my-rg/my-vmss-ondemand is not done",
+			expect: func(g *WithT, s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				defaultSpec := newDefaultVMSSSpec()
+				onDemandSpec := newDefaultVMSSSpec()
+				onDemandSpec.Name = onDemandVMSSName
+				s.ScaleSetSpec().Return(defaultSpec).AnyTimes()
+				s.OnDemandScaleSetSpec().Return(&onDemandSpec)
+				createdVMSS := newDefaultVMSS("VM_SIZE")
+				instances := newDefaultInstances()
+				setupDefaultVMSSInProgressOperationDoneExpectations(s, m, createdVMSS, instances)
+				s.DeleteLongRunningOperationState(defaultSpec.Name, scope.ScalesetsServiceName)
+				s.SetSpotCapacityAvailable()
+
+				onDemandFuture := &infrav1.Future{
+					Type:          infrav1.PutFuture,
+					ResourceGroup: defaultResourceGroup,
+					Name:          onDemandVMSSName,
+				}
+				s.GetLongRunningOperationState(onDemandVMSSName, scope.ScalesetsServiceName).Return(onDemandFuture)
+				m.GetResultIfDone(gomockinternal.AContext(), onDemandFuture).Return(compute.VirtualMachineScaleSet{}, azure.NewOperationNotDoneError(onDemandFuture))
+				onDemandVMSS := newDefaultExistingVMSS("VM_SIZE")
+				onDemandVMSS.Name = to.StringPtr(onDemandVMSSName)
+				m.Get(gomockinternal.AContext(), defaultResourceGroup, onDemandVMSSName).Return(onDemandVMSS, nil)
+				m.ListInstances(gomockinternal.AContext(), defaultResourceGroup, onDemandVMSSName).Return(instances, nil)
+				s.SetOnDemandVMSSState(gomock.Any())
+			},
+		},
 		{
 			name:          "less than 2 vCPUs",
 			expectedError: "reconcile error that cannot be recovered occurred: vm size should be bigger or equal to at least 2 vCPUs. Object will not be requeued",
@@ -576,6 +840,7 @@ func TestReconcileVMSS(t *testing.T) {
 			clientMock := mock_scalesets.NewMockClient(mockCtrl)
 
 			tc.expect(g, scopeMock.EXPECT(), clientMock.EXPECT())
+			scopeMock.EXPECT().OnDemandScaleSetSpec().Return(nil).AnyTimes()
 
 			s := &Service{
 				Scope:            scopeMock,
@@ -623,6 +888,35 @@ func TestDeleteVMSS(t *testing.T) {
 				s.DeleteLongRunningOperationState("my-existing-vmss", scope.ScalesetsServiceName)
 			},
 		},
+		{
+			name:          "successfully delete an existing vmss and its on-demand scale set",
+			expectedError: "",
+			expect: func(s *mock_scalesets.MockScaleSetScopeMockRecorder, m *mock_scalesets.MockClientMockRecorder) {
+				s.ScaleSetSpec().Return(azure.ScaleSetSpec{
+					Name:     name,
+					Size:     "VM_SIZE",
+					Capacity: 3,
+				}).AnyTimes()
+				s.OnDemandScaleSetSpec().Return(&azure.ScaleSetSpec{
+					Name:     onDemandVMSSName,
+					Size:     "VM_SIZE",
+					Capacity: 1,
+				})
+				s.ResourceGroup().AnyTimes().Return(resourceGroup)
+				s.GetLongRunningOperationState(name, scope.ScalesetsServiceName).Return(nil)
+				m.DeleteAsync(gomockinternal.AContext(), resourceGroup, name).Return(nil, nil)
+				s.SetLongRunningOperationState(nil)
+				s.DeleteLongRunningOperationState(name, scope.ScalesetsServiceName)
+				m.Get(gomockinternal.AContext(), resourceGroup, name).
+					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				s.GetLongRunningOperationState(onDemandVMSSName, scope.ScalesetsServiceName).Return(nil)
+				m.DeleteAsync(gomockinternal.AContext(), resourceGroup, onDemandVMSSName).Return(nil, nil)
+				s.SetLongRunningOperationState(nil)
+				s.DeleteLongRunningOperationState(onDemandVMSSName, scope.ScalesetsServiceName)
+				m.Get(gomockinternal.AContext(), resourceGroup, onDemandVMSSName).
+					Return(compute.VirtualMachineScaleSet{}, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+			},
+		},
 		{
 			name:          "vmss already deleted",
 			expectedError: "",
@@ -672,6 +966,7 @@ func TestDeleteVMSS(t *testing.T) {
 			clientMock := mock_scalesets.NewMockClient(mockCtrl)
 
 			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+			scopeMock.EXPECT().OnDemandScaleSetSpec().Return(nil).AnyTimes()
 
 			s := &Service{
 				Scope:  scopeMock,
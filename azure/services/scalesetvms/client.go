@@ -38,12 +38,28 @@ type client interface {
 	Get(context.Context, string, string, string) (compute.VirtualMachineScaleSetVM, error)
 	GetResultIfDone(ctx context.Context, future *infrav1.Future) (compute.VirtualMachineScaleSetVM, error)
 	DeleteAsync(context.Context, string, string, string) (*infrav1.Future, error)
+	Start(context.Context, string, string, string) error
+	GetVM(context.Context, string, string) (compute.VirtualMachine, error)
+	DeleteVMAsync(context.Context, string, string) (*infrav1.Future, error)
+	StartVM(context.Context, string, string) error
+	UpdateInstancesAsync(context.Context, string, string, string) (*infrav1.Future, error)
+	ReimageAsync(context.Context, string, string, string) (*infrav1.Future, error)
+	UpdateProtectionPolicy(context.Context, string, string, string, compute.VirtualMachineScaleSetVMProtectionPolicy) error
+	CreateOrUpdateRunCommandAsync(context.Context, string, string, string, string, compute.VirtualMachineRunCommand) (*infrav1.Future, error)
+	GetRunCommand(context.Context, string, string, string, string) (compute.VirtualMachineRunCommand, error)
+	CreateOrUpdateVMRunCommandAsync(context.Context, string, string, string, compute.VirtualMachineRunCommand) (*infrav1.Future, error)
+	GetVMRunCommand(context.Context, string, string, string) (compute.VirtualMachineRunCommand, error)
+	IsRunCommandDone(ctx context.Context, future *infrav1.Future) (bool, error)
 }
 
 type (
 	// azureClient contains the Azure go-sdk Client.
 	azureClient struct {
-		scalesetvms compute.VirtualMachineScaleSetVMsClient
+		scalesetvms        compute.VirtualMachineScaleSetVMsClient
+		scalesets          compute.VirtualMachineScaleSetsClient
+		virtualmachines    compute.VirtualMachinesClient
+		scalesetvmcommands compute.VirtualMachineScaleSetVMRunCommandsClient
+		vmcommands         compute.VirtualMachineRunCommandsClient
 	}
 
 	genericScaleSetVMFuture interface {
@@ -54,6 +70,10 @@ type (
 	deleteFutureAdapter struct {
 		compute.VirtualMachineScaleSetVMsDeleteFuture
 	}
+
+	reimageFutureAdapter struct {
+		compute.VirtualMachineScaleSetVMsReimageFuture
+	}
 )
 
 var _ client = &azureClient{}
@@ -61,7 +81,11 @@ var _ client = &azureClient{}
 // newClient creates a new VMSS client from subscription ID.
 func newClient(auth azure.Authorizer) *azureClient {
 	return &azureClient{
-		scalesetvms: newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesetvms:        newVirtualMachineScaleSetVMsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesets:          newVirtualMachineScaleSetsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		virtualmachines:    newVirtualMachinesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		scalesetvmcommands: newVirtualMachineScaleSetVMRunCommandsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
+		vmcommands:         newVirtualMachineRunCommandsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer()),
 	}
 }
 
@@ -74,12 +98,90 @@ func newVirtualMachineScaleSetVMsClient(subscriptionID string, baseURI string, a
 	return c
 }
 
+// newVirtualMachineScaleSetsClient creates a new vmss client from subscription ID.
+func newVirtualMachineScaleSetsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineScaleSetsClient {
+	c := compute.NewVirtualMachineScaleSetsClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.RetryAttempts = 1
+	_ = c.AddToUserAgent(azure.UserAgent()) // intentionally ignore error as it doesn't matter
+	return c
+}
+
+// newVirtualMachinesClient creates a new vm client from subscription ID.
+func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachinesClient {
+	c := compute.NewVirtualMachinesClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.RetryAttempts = 1
+	_ = c.AddToUserAgent(azure.UserAgent()) // intentionally ignore error as it doesn't matter
+	return c
+}
+
+// newVirtualMachineScaleSetVMRunCommandsClient creates a new vmss VM run commands client from subscription ID.
+func newVirtualMachineScaleSetVMRunCommandsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineScaleSetVMRunCommandsClient {
+	c := compute.NewVirtualMachineScaleSetVMRunCommandsClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.RetryAttempts = 1
+	_ = c.AddToUserAgent(azure.UserAgent()) // intentionally ignore error as it doesn't matter
+	return c
+}
+
+// newVirtualMachineRunCommandsClient creates a new vm run commands client from subscription ID.
+func newVirtualMachineRunCommandsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineRunCommandsClient {
+	c := compute.NewVirtualMachineRunCommandsClientWithBaseURI(baseURI, subscriptionID)
+	c.Authorizer = authorizer
+	c.RetryAttempts = 1
+	_ = c.AddToUserAgent(azure.UserAgent()) // intentionally ignore error as it doesn't matter
+	return c
+}
+
 // Get retrieves the Virtual Machine Scale Set Virtual Machine.
 func (ac *azureClient) Get(ctx context.Context, resourceGroupName, vmssName, instanceID string) (compute.VirtualMachineScaleSetVM, error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.Get")
 	defer done()
 
-	return ac.scalesetvms.Get(ctx, resourceGroupName, vmssName, instanceID, "")
+	return ac.scalesetvms.Get(ctx, resourceGroupName, vmssName, instanceID, compute.InstanceViewTypesInstanceView)
+}
+
+// Start starts a deallocated Virtual Machine Scale Set Virtual Machine. It returns once Azure accepted the request
+// without waiting for the instance to be running.
+func (ac *azureClient) Start(ctx context.Context, resourceGroupName, vmssName, instanceID string) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.Start")
+	defer done()
+
+	_, err := ac.scalesetvms.Start(ctx, resourceGroupName, vmssName, instanceID)
+	return err
+}
+
+// UpdateProtectionPolicy updates the protection policy of a Virtual Machine Scale Set Virtual Machine. It returns once
+// Azure accepted the request without waiting for the update to complete.
+func (ac *azureClient) UpdateProtectionPolicy(ctx context.Context, resourceGroupName, vmssName, instanceID string, policy compute.VirtualMachineScaleSetVMProtectionPolicy) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.UpdateProtectionPolicy")
+	defer done()
+
+	_, err := ac.scalesetvms.Update(ctx, resourceGroupName, vmssName, instanceID, compute.VirtualMachineScaleSetVM{
+		VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+			ProtectionPolicy: &policy,
+		},
+	})
+	return err
+}
+
+// GetVM retrieves a Virtual Machine of a Flexible Virtual Machine Scale Set.
+func (ac *azureClient) GetVM(ctx context.Context, resourceGroupName, vmName string) (compute.VirtualMachine, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.GetVM")
+	defer done()
+
+	return ac.virtualmachines.Get(ctx, resourceGroupName, vmName, compute.InstanceViewTypesInstanceView)
+}
+
+// StartVM starts a deallocated Virtual Machine of a Flexible Virtual Machine Scale Set. It returns once Azure accepted
+// the request without waiting for the VM to be running.
+func (ac *azureClient) StartVM(ctx context.Context, resourceGroupName, vmName string) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.StartVM")
+	defer done()
+
+	_, err := ac.virtualmachines.Start(ctx, resourceGroupName, vmName)
+	return err
 }
 
 // GetResultIfDone fetches the result of a long-running operation future if it is done.
@@ -103,6 +205,16 @@ func (ac *azureClient) GetResultIfDone(ctx context.Context, future *infrav1.Futu
 		genericFuture = &deleteFutureAdapter{
 			VirtualMachineScaleSetVMsDeleteFuture: future,
 		}
+	case infrav1.PostFuture:
+		// reimage and update instances futures only carry the polling state, so both are tracked as a reimage future
+		var future compute.VirtualMachineScaleSetVMsReimageFuture
+		if err := json.Unmarshal(futureData, &future); err != nil {
+			return compute.VirtualMachineScaleSetVM{}, errors.Wrap(err, "failed to unmarshal future data")
+		}
+
+		genericFuture = &reimageFutureAdapter{
+			VirtualMachineScaleSetVMsReimageFuture: future,
+		}
 	default:
 		return compute.VirtualMachineScaleSetVM{}, errors.Errorf("unknown furture type %q", future.Type)
 	}
@@ -144,9 +256,122 @@ func (ac *azureClient) DeleteAsync(ctx context.Context, resourceGroupName, vmssN
 	return converters.SDKToFuture(&future, infrav1.DeleteFuture, serviceName, instanceID, resourceGroupName)
 }
 
+// DeleteVMAsync is the operation to delete a Virtual Machine of a Flexible Virtual Machine Scale Set asynchronously.
+// The returned future has the same serialized form as the future of a scale set instance delete, so it is tracked
+// by GetResultIfDone as well.
+func (ac *azureClient) DeleteVMAsync(ctx context.Context, resourceGroupName, vmName string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.DeleteVMAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.Delete(ctx, resourceGroupName, vmName, to.BoolPtr(false))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed deleting vm named %q", vmName)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.DeleteFuture, serviceName, vmName, resourceGroupName)
+}
+
+// UpdateInstancesAsync is the operation to upgrade a virtual machine scale set instance to the latest model of the
+// scale set asynchronously. If accepted without error, the func will return a Future which can be used to track the
+// ongoing progress of the operation.
+func (ac *azureClient) UpdateInstancesAsync(ctx context.Context, resourceGroupName, vmssName, instanceID string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.UpdateInstancesAsync")
+	defer done()
+
+	instanceIDs := compute.VirtualMachineScaleSetVMInstanceRequiredIDs{InstanceIds: &[]string{instanceID}}
+	future, err := ac.scalesets.UpdateInstances(ctx, resourceGroupName, vmssName, instanceIDs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed updating instance %s of vmss named %q", instanceID, vmssName)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.PostFuture, reimageServiceName, instanceID, resourceGroupName)
+}
+
+// ReimageAsync is the operation to reimage a virtual machine scale set instance asynchronously. If accepted without
+// error, the func will return a Future which can be used to track the ongoing progress of the operation.
+func (ac *azureClient) ReimageAsync(ctx context.Context, resourceGroupName, vmssName, instanceID string) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.ReimageAsync")
+	defer done()
+
+	future, err := ac.scalesetvms.Reimage(ctx, resourceGroupName, vmssName, instanceID, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed reimaging instance %s of vmss named %q", instanceID, vmssName)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.PostFuture, reimageServiceName, instanceID, resourceGroupName)
+}
+
+// CreateOrUpdateRunCommandAsync creates or updates a managed run command of a virtual machine scale set instance
+// asynchronously, which runs its script again. If accepted without error, the func will return a Future which can be
+// used to track the ongoing progress of the operation.
+func (ac *azureClient) CreateOrUpdateRunCommandAsync(ctx context.Context, resourceGroupName, vmssName, instanceID, runCommandName string, runCommand compute.VirtualMachineRunCommand) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.CreateOrUpdateRunCommandAsync")
+	defer done()
+
+	future, err := ac.scalesetvmcommands.CreateOrUpdate(ctx, resourceGroupName, vmssName, instanceID, runCommandName, runCommand)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed creating run command %s of instance %s of vmss named %q", runCommandName, instanceID, vmssName)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.PutFuture, cloudProviderConfigServiceName, instanceID, resourceGroupName)
+}
+
+// GetRunCommand retrieves a managed run command of a virtual machine scale set instance, along with the instance view
+// holding the exit code and the output of its last execution.
+func (ac *azureClient) GetRunCommand(ctx context.Context, resourceGroupName, vmssName, instanceID, runCommandName string) (compute.VirtualMachineRunCommand, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.GetRunCommand")
+	defer done()
+
+	return ac.scalesetvmcommands.Get(ctx, resourceGroupName, vmssName, instanceID, runCommandName, "instanceView")
+}
+
+// CreateOrUpdateVMRunCommandAsync creates or updates a managed run command of a Virtual Machine of a Flexible Virtual
+// Machine Scale Set asynchronously. The returned future has the same serialized form as the future of a run command of
+// a scale set instance, so it is tracked by IsRunCommandDone as well.
+func (ac *azureClient) CreateOrUpdateVMRunCommandAsync(ctx context.Context, resourceGroupName, vmName, runCommandName string, runCommand compute.VirtualMachineRunCommand) (*infrav1.Future, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.CreateOrUpdateVMRunCommandAsync")
+	defer done()
+
+	future, err := ac.vmcommands.CreateOrUpdate(ctx, resourceGroupName, vmName, runCommandName, runCommand)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed creating run command %s of vm named %q", runCommandName, vmName)
+	}
+
+	return converters.SDKToFuture(&future, infrav1.PutFuture, cloudProviderConfigServiceName, vmName, resourceGroupName)
+}
+
+// GetVMRunCommand retrieves a managed run command of a Virtual Machine of a Flexible Virtual Machine Scale Set, along
+// with the instance view holding the exit code and the output of its last execution.
+func (ac *azureClient) GetVMRunCommand(ctx context.Context, resourceGroupName, vmName, runCommandName string) (compute.VirtualMachineRunCommand, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.GetVMRunCommand")
+	defer done()
+
+	return ac.vmcommands.GetByVirtualMachine(ctx, resourceGroupName, vmName, runCommandName, "instanceView")
+}
+
+// IsRunCommandDone returns true if the long-running operation of a run command is done. The operation is done once the
+// script exited, so its result is fetched from the instance view of the run command.
+func (ac *azureClient) IsRunCommandDone(ctx context.Context, future *infrav1.Future) (bool, error) {
+	ctx, _, spanDone := tele.StartSpanWithLogger(ctx, "scalesetvms.azureClient.IsRunCommandDone")
+	defer spanDone()
+
+	sdkFuture, err := converters.FutureToSDK(*future)
+	if err != nil {
+		return false, err
+	}
+	return sdkFuture.DoneWithContext(ctx, ac.scalesetvmcommands)
+}
+
 // Result wraps the delete result so that we can treat it generically. The only thing we care about is if the delete
 // was successful. If it wasn't, an error will be returned.
 func (da *deleteFutureAdapter) Result(client compute.VirtualMachineScaleSetVMsClient) (compute.VirtualMachineScaleSetVM, error) {
 	_, err := da.VirtualMachineScaleSetVMsDeleteFuture.Result(client)
 	return compute.VirtualMachineScaleSetVM{}, err
 }
+
+// Result wraps the reimage result so that we can treat it generically. The only thing we care about is if the
+// operation was successful. If it wasn't, an error will be returned.
+func (ra *reimageFutureAdapter) Result(client compute.VirtualMachineScaleSetVMsClient) (compute.VirtualMachineScaleSetVM, error) {
+	_, err := ra.VirtualMachineScaleSetVMsReimageFuture.Result(client)
+	return compute.VirtualMachineScaleSetVM{}, err
+}
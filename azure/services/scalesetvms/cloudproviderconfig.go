@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalesetvms
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// cloudProviderConfigServiceName tracks the long-running operation of an update of the cloud provider config apart
+	// from the other operations on the instance.
+	cloudProviderConfigServiceName = "scalesetvmcloudproviderconfig"
+
+	// cloudProviderConfigRequeue is how long to wait before checking on the update of the cloud provider config again.
+	cloudProviderConfigRequeue = 30 * time.Second
+)
+
+// reconcileCloudProviderConfig writes the cloud provider config on a running instance with a managed run command when
+// it changed since the instance was created, e.g. because the credentials of the cluster were rotated. Instances of a
+// Flexible scale set are standalone virtual machines, so their run command is managed through the virtual machines API.
+func (s *Service) reconcileCloudProviderConfig(ctx context.Context, resourceGroup, vmssName, instanceID string, statuses *[]compute.InstanceViewStatus) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesetvms.Service.reconcileCloudProviderConfig")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(instanceID, cloudProviderConfigServiceName); future != nil {
+		isDone, err := s.Client.IsRunCommandDone(ctx, future)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(instanceID, cloudProviderConfigServiceName)
+			s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return errors.Wrap(err, "failed to update cloud provider config")
+		}
+		if !isDone {
+			return azure.WithTransientError(azure.NewOperationNotDoneError(future), cloudProviderConfigRequeue)
+		}
+		s.Scope.DeleteLongRunningOperationState(instanceID, cloudProviderConfigServiceName)
+
+		// the operation is done once the script exited, which may have failed
+		runCommand, err := s.getRunCommand(ctx, resourceGroup, vmssName, instanceID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the result of the cloud provider config update")
+		}
+		if err := converters.SDKRunCommandError(runCommand); err != nil {
+			s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return errors.Wrap(err, "failed to update cloud provider config")
+		}
+		log.V(2).Info("updated cloud provider config", "instanceID", instanceID)
+		s.Scope.UpdatePutStatus(infrav1.CloudProviderConfigUpdatedCondition, cloudProviderConfigServiceName, nil)
+		return nil
+	}
+
+	// commands only run on running instances, and a reimaged instance is bootstrapped again
+	if converters.IsDeallocated(statuses) || s.Scope.ReimageRequested() {
+		return nil
+	}
+
+	config, err := s.Scope.CloudProviderConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cloud provider config")
+	}
+	switch {
+	case config == nil:
+		return nil
+	case config.LastAppliedHash == "":
+		// the instance was bootstrapped with the current config
+		s.Scope.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, config.Hash)
+		return nil
+	case config.LastAppliedHash == config.Hash && !s.Scope.IsConditionFalse(infrav1.CloudProviderConfigUpdatedCondition):
+		return nil
+	}
+
+	log.V(2).Info("updating cloud provider config", "instanceID", instanceID)
+	s.Scope.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, config.Hash)
+	runCommand := converters.CloudProviderConfigToSDKRunCommand(*config, s.Scope.Location(), time.Now())
+	future, err := s.createOrUpdateRunCommandAsync(ctx, resourceGroup, vmssName, instanceID, runCommand)
+	if err != nil {
+		s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return errors.Wrap(err, "failed to update cloud provider config")
+	}
+	s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdatingReason, clusterv1.ConditionSeverityInfo, "writing the cloud provider config on the instance")
+	s.Scope.SetLongRunningOperationState(future)
+	return azure.WithTransientError(azure.NewOperationNotDoneError(future), cloudProviderConfigRequeue)
+}
+
+// createOrUpdateRunCommandAsync starts running the cloud provider config run command on an instance of the scale set.
+func (s *Service) createOrUpdateRunCommandAsync(ctx context.Context, resourceGroup, vmssName, instanceID string, runCommand compute.VirtualMachineRunCommand) (*infrav1.Future, error) {
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return s.Client.CreateOrUpdateVMRunCommandAsync(ctx, resourceGroup, instanceID, azure.CloudProviderConfigRunCommandName, runCommand)
+	}
+	return s.Client.CreateOrUpdateRunCommandAsync(ctx, resourceGroup, vmssName, instanceID, azure.CloudProviderConfigRunCommandName, runCommand)
+}
+
+// getRunCommand fetches the cloud provider config run command of an instance of the scale set.
+func (s *Service) getRunCommand(ctx context.Context, resourceGroup, vmssName, instanceID string) (compute.VirtualMachineRunCommand, error) {
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return s.Client.GetVMRunCommand(ctx, resourceGroup, instanceID, azure.CloudProviderConfigRunCommandName)
+	}
+	return s.Client.GetRunCommand(ctx, resourceGroup, vmssName, instanceID, azure.CloudProviderConfigRunCommandName)
+}
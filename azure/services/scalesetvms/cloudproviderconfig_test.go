@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalesetvms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scalesetvms/mock_scalesetvms"
+	gomock2 "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func fakeRunCommand(state compute.ExecutionState, exitCode int32, stderr string) compute.VirtualMachineRunCommand {
+	return compute.VirtualMachineRunCommand{
+		VirtualMachineRunCommandProperties: &compute.VirtualMachineRunCommandProperties{
+			InstanceView: &compute.VirtualMachineRunCommandInstanceView{
+				ExecutionState: state,
+				ExitCode:       to.Int32Ptr(exitCode),
+				Error:          to.StringPtr(stderr),
+			},
+		},
+	}
+}
+
+func TestService_reconcileCloudProviderConfig(t *testing.T) {
+	runCommandFuture := &infrav1.Future{
+		Type:          infrav1.PutFuture,
+		ServiceName:   cloudProviderConfigServiceName,
+		Name:          "0",
+		ResourceGroup: "rg",
+	}
+	running := &[]compute.InstanceViewStatus{{Code: to.StringPtr("PowerState/running")}}
+
+	cases := []struct {
+		Name              string
+		OrchestrationMode infrav1.OrchestrationModeType
+		Statuses          *[]compute.InstanceViewStatus
+		Setup             func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
+		Err               error
+	}{
+		{
+			Name:     "should do nothing if the cloud provider config is not generated by capz",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+				s.CloudProviderConfig(gomock2.AContext()).Return(nil, nil)
+			},
+		},
+		{
+			Name:     "should record the hash of the cloud provider config the instance was bootstrapped with",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+				s.CloudProviderConfig(gomock2.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", Script: "script"}, nil)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+			},
+		},
+		{
+			Name:     "should do nothing if the cloud provider config is up to date",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+				s.CloudProviderConfig(gomock2.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "new", Script: "script"}, nil)
+				s.IsConditionFalse(infrav1.CloudProviderConfigUpdatedCondition).Return(false)
+			},
+		},
+		{
+			Name:     "should not update a deallocated instance",
+			Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr("PowerState/deallocated")}},
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+			},
+		},
+		{
+			Name:     "should not update an instance being reimaged",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(true)
+			},
+		},
+		{
+			Name:     "should write the rotated cloud provider config on the instance",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+				s.CloudProviderConfig(gomock2.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "old", Script: "script"}, nil)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+				s.Location().Return("westus2")
+				m.CreateOrUpdateRunCommandAsync(gomock2.AContext(), "rg", "scaleset", "0", azure.CloudProviderConfigRunCommandName, gomock.Any()).Return(runCommandFuture, nil)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdatingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.SetLongRunningOperationState(runCommandFuture)
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(runCommandFuture), cloudProviderConfigRequeue),
+		},
+		{
+			Name:              "should write the rotated cloud provider config on a flexible instance",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			Statuses:          running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+				s.CloudProviderConfig(gomock2.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "old", Script: "script"}, nil)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+				s.Location().Return("westus2")
+				m.CreateOrUpdateVMRunCommandAsync(gomock2.AContext(), "rg", "0", azure.CloudProviderConfigRunCommandName, gomock.Any()).Return(runCommandFuture, nil)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdatingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.SetLongRunningOperationState(runCommandFuture)
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(runCommandFuture), cloudProviderConfigRequeue),
+		},
+		{
+			Name:     "should retry a failed update of the cloud provider config",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+				s.CloudProviderConfig(gomock2.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "new", Script: "script"}, nil)
+				s.IsConditionFalse(infrav1.CloudProviderConfigUpdatedCondition).Return(true)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+				s.Location().Return("westus2")
+				m.CreateOrUpdateRunCommandAsync(gomock2.AContext(), "rg", "scaleset", "0", azure.CloudProviderConfigRunCommandName, gomock.Any()).Return(nil, errors.New("boom"))
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, "boom")
+			},
+			Err: errors.Wrap(errors.New("boom"), "failed to update cloud provider config"),
+		},
+		{
+			Name:     "should wait for the update of the cloud provider config in progress",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(runCommandFuture)
+				m.IsRunCommandDone(gomock2.AContext(), runCommandFuture).Return(false, nil)
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(runCommandFuture), cloudProviderConfigRequeue),
+		},
+		{
+			Name:     "should mark the cloud provider config as updated once the script succeeded",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(runCommandFuture)
+				m.IsRunCommandDone(gomock2.AContext(), runCommandFuture).Return(true, nil)
+				s.DeleteLongRunningOperationState("0", cloudProviderConfigServiceName)
+				m.GetRunCommand(gomock2.AContext(), "rg", "scaleset", "0", azure.CloudProviderConfigRunCommandName).Return(fakeRunCommand(compute.ExecutionStateSucceeded, 0, ""), nil)
+				s.UpdatePutStatus(infrav1.CloudProviderConfigUpdatedCondition, cloudProviderConfigServiceName, nil)
+			},
+		},
+		{
+			Name:     "should mark the cloud provider config update as failed if the script exited with an error",
+			Statuses: running,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", cloudProviderConfigServiceName).Return(runCommandFuture)
+				m.IsRunCommandDone(gomock2.AContext(), runCommandFuture).Return(true, nil)
+				s.DeleteLongRunningOperationState("0", cloudProviderConfigServiceName)
+				m.GetRunCommand(gomock2.AContext(), "rg", "scaleset", "0", azure.CloudProviderConfigRunCommandName).Return(fakeRunCommand(compute.ExecutionStateFailed, 1, "crictl: command failed"), nil)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, "run command failed with exit code 1: crictl: command failed")
+			},
+			Err: errors.Wrap(errors.New("run command failed with exit code 1: crictl: command failed"), "failed to update cloud provider config"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				g          = NewWithT(t)
+				mockCtrl   = gomock.NewController(t)
+				scopeMock  = mock_scalesetvms.NewMockScaleSetVMScope(mockCtrl)
+				clientMock = mock_scalesetvms.NewMockclient(mockCtrl)
+			)
+			defer mockCtrl.Finish()
+
+			orchestrationMode := infrav1.UniformOrchestrationMode
+			if c.OrchestrationMode != "" {
+				orchestrationMode = c.OrchestrationMode
+			}
+			scopeMock.EXPECT().OrchestrationMode().Return(orchestrationMode).AnyTimes()
+			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			service := &Service{
+				Client: clientMock,
+				Scope:  scopeMock,
+			}
+			if err := service.reconcileCloudProviderConfig(context.TODO(), "rg", "scaleset", "0", c.Statuses); c.Err == nil {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(c.Err.Error()))
+			}
+		})
+	}
+}
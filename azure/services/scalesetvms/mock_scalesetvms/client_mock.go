@@ -53,6 +53,36 @@ func (m *Mockclient) EXPECT() *MockclientMockRecorder {
 	return m.recorder
 }
 
+// CreateOrUpdateRunCommandAsync mocks base method.
+func (m *Mockclient) CreateOrUpdateRunCommandAsync(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 compute.VirtualMachineRunCommand) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateRunCommandAsync", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrUpdateRunCommandAsync indicates an expected call of CreateOrUpdateRunCommandAsync.
+func (mr *MockclientMockRecorder) CreateOrUpdateRunCommandAsync(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateRunCommandAsync", reflect.TypeOf((*Mockclient)(nil).CreateOrUpdateRunCommandAsync), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// CreateOrUpdateVMRunCommandAsync mocks base method.
+func (m *Mockclient) CreateOrUpdateVMRunCommandAsync(arg0 context.Context, arg1, arg2, arg3 string, arg4 compute.VirtualMachineRunCommand) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateVMRunCommandAsync", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrUpdateVMRunCommandAsync indicates an expected call of CreateOrUpdateVMRunCommandAsync.
+func (mr *MockclientMockRecorder) CreateOrUpdateVMRunCommandAsync(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateVMRunCommandAsync", reflect.TypeOf((*Mockclient)(nil).CreateOrUpdateVMRunCommandAsync), arg0, arg1, arg2, arg3, arg4)
+}
+
 // DeleteAsync mocks base method.
 func (m *Mockclient) DeleteAsync(arg0 context.Context, arg1, arg2, arg3 string) (*v1beta1.Future, error) {
 	m.ctrl.T.Helper()
@@ -68,6 +98,21 @@ func (mr *MockclientMockRecorder) DeleteAsync(arg0, arg1, arg2, arg3 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAsync", reflect.TypeOf((*Mockclient)(nil).DeleteAsync), arg0, arg1, arg2, arg3)
 }
 
+// DeleteVMAsync mocks base method.
+func (m *Mockclient) DeleteVMAsync(arg0 context.Context, arg1, arg2 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVMAsync", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVMAsync indicates an expected call of DeleteVMAsync.
+func (mr *MockclientMockRecorder) DeleteVMAsync(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVMAsync", reflect.TypeOf((*Mockclient)(nil).DeleteVMAsync), arg0, arg1, arg2)
+}
+
 // Get mocks base method.
 func (m *Mockclient) Get(arg0 context.Context, arg1, arg2, arg3 string) (compute.VirtualMachineScaleSetVM, error) {
 	m.ctrl.T.Helper()
@@ -98,6 +143,138 @@ func (mr *MockclientMockRecorder) GetResultIfDone(ctx, future interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResultIfDone", reflect.TypeOf((*Mockclient)(nil).GetResultIfDone), ctx, future)
 }
 
+// GetRunCommand mocks base method.
+func (m *Mockclient) GetRunCommand(arg0 context.Context, arg1, arg2, arg3, arg4 string) (compute.VirtualMachineRunCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunCommand", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(compute.VirtualMachineRunCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunCommand indicates an expected call of GetRunCommand.
+func (mr *MockclientMockRecorder) GetRunCommand(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunCommand", reflect.TypeOf((*Mockclient)(nil).GetRunCommand), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetVM mocks base method.
+func (m *Mockclient) GetVM(arg0 context.Context, arg1, arg2 string) (compute.VirtualMachine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVM", arg0, arg1, arg2)
+	ret0, _ := ret[0].(compute.VirtualMachine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVM indicates an expected call of GetVM.
+func (mr *MockclientMockRecorder) GetVM(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVM", reflect.TypeOf((*Mockclient)(nil).GetVM), arg0, arg1, arg2)
+}
+
+// GetVMRunCommand mocks base method.
+func (m *Mockclient) GetVMRunCommand(arg0 context.Context, arg1, arg2, arg3 string) (compute.VirtualMachineRunCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVMRunCommand", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(compute.VirtualMachineRunCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVMRunCommand indicates an expected call of GetVMRunCommand.
+func (mr *MockclientMockRecorder) GetVMRunCommand(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVMRunCommand", reflect.TypeOf((*Mockclient)(nil).GetVMRunCommand), arg0, arg1, arg2, arg3)
+}
+
+// IsRunCommandDone mocks base method.
+func (m *Mockclient) IsRunCommandDone(ctx context.Context, future *v1beta1.Future) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRunCommandDone", ctx, future)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRunCommandDone indicates an expected call of IsRunCommandDone.
+func (mr *MockclientMockRecorder) IsRunCommandDone(ctx, future interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRunCommandDone", reflect.TypeOf((*Mockclient)(nil).IsRunCommandDone), ctx, future)
+}
+
+// ReimageAsync mocks base method.
+func (m *Mockclient) ReimageAsync(arg0 context.Context, arg1, arg2, arg3 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReimageAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReimageAsync indicates an expected call of ReimageAsync.
+func (mr *MockclientMockRecorder) ReimageAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReimageAsync", reflect.TypeOf((*Mockclient)(nil).ReimageAsync), arg0, arg1, arg2, arg3)
+}
+
+// Start mocks base method.
+func (m *Mockclient) Start(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockclientMockRecorder) Start(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*Mockclient)(nil).Start), arg0, arg1, arg2, arg3)
+}
+
+// StartVM mocks base method.
+func (m *Mockclient) StartVM(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartVM", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartVM indicates an expected call of StartVM.
+func (mr *MockclientMockRecorder) StartVM(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartVM", reflect.TypeOf((*Mockclient)(nil).StartVM), arg0, arg1, arg2)
+}
+
+// UpdateInstancesAsync mocks base method.
+func (m *Mockclient) UpdateInstancesAsync(arg0 context.Context, arg1, arg2, arg3 string) (*v1beta1.Future, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstancesAsync", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*v1beta1.Future)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateInstancesAsync indicates an expected call of UpdateInstancesAsync.
+func (mr *MockclientMockRecorder) UpdateInstancesAsync(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstancesAsync", reflect.TypeOf((*Mockclient)(nil).UpdateInstancesAsync), arg0, arg1, arg2, arg3)
+}
+
+// UpdateProtectionPolicy mocks base method.
+func (m *Mockclient) UpdateProtectionPolicy(arg0 context.Context, arg1, arg2, arg3 string, arg4 compute.VirtualMachineScaleSetVMProtectionPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProtectionPolicy", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProtectionPolicy indicates an expected call of UpdateProtectionPolicy.
+func (mr *MockclientMockRecorder) UpdateProtectionPolicy(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProtectionPolicy", reflect.TypeOf((*Mockclient)(nil).UpdateProtectionPolicy), arg0, arg1, arg2, arg3, arg4)
+}
+
 // MockgenericScaleSetVMFuture is a mock of genericScaleSetVMFuture interface.
 type MockgenericScaleSetVMFuture struct {
 	ctrl     *gomock.Controller
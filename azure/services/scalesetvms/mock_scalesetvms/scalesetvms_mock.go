@@ -21,13 +21,15 @@ limitations under the License.
 package mock_scalesetvms
 
 import (
+	context "context"
 	reflect "reflect"
 
 	autorest "github.com/Azure/go-autorest/autorest"
 	gomock "github.com/golang/mock/gomock"
 	v1beta1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	azure "sigs.k8s.io/cluster-api-provider-azure/azure"
-	v1beta10 "sigs.k8s.io/cluster-api/api/v1beta1"
+	v1beta10 "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	v1beta11 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 // MockScaleSetVMScope is a mock of ScaleSetVMScope interface.
@@ -151,6 +153,21 @@ func (mr *MockScaleSetVMScopeMockRecorder) CloudEnvironment() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockScaleSetVMScope)(nil).CloudEnvironment))
 }
 
+// CloudProviderConfig mocks base method.
+func (m *MockScaleSetVMScope) CloudProviderConfig(ctx context.Context) (*azure.CloudProviderConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudProviderConfig", ctx)
+	ret0, _ := ret[0].(*azure.CloudProviderConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloudProviderConfig indicates an expected call of CloudProviderConfig.
+func (mr *MockScaleSetVMScopeMockRecorder) CloudProviderConfig(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudProviderConfig", reflect.TypeOf((*MockScaleSetVMScope)(nil).CloudProviderConfig), ctx)
+}
+
 // CloudProviderConfigOverrides mocks base method.
 func (m *MockScaleSetVMScope) CloudProviderConfigOverrides() *v1beta1.CloudProviderConfigOverrides {
 	m.ctrl.T.Helper()
@@ -179,6 +196,32 @@ func (mr *MockScaleSetVMScopeMockRecorder) ClusterName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterName", reflect.TypeOf((*MockScaleSetVMScope)(nil).ClusterName))
 }
 
+// CompleteReimage mocks base method.
+func (m *MockScaleSetVMScope) CompleteReimage() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompleteReimage")
+}
+
+// CompleteReimage indicates an expected call of CompleteReimage.
+func (mr *MockScaleSetVMScopeMockRecorder) CompleteReimage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteReimage", reflect.TypeOf((*MockScaleSetVMScope)(nil).CompleteReimage))
+}
+
+// CordonAndDrainForReimage mocks base method.
+func (m *MockScaleSetVMScope) CordonAndDrainForReimage(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CordonAndDrainForReimage", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CordonAndDrainForReimage indicates an expected call of CordonAndDrainForReimage.
+func (mr *MockScaleSetVMScopeMockRecorder) CordonAndDrainForReimage(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CordonAndDrainForReimage", reflect.TypeOf((*MockScaleSetVMScope)(nil).CordonAndDrainForReimage), ctx)
+}
+
 // DeleteLongRunningOperationState mocks base method.
 func (m *MockScaleSetVMScope) DeleteLongRunningOperationState(arg0, arg1 string) {
 	m.ctrl.T.Helper()
@@ -205,6 +248,20 @@ func (mr *MockScaleSetVMScopeMockRecorder) FailureDomains() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailureDomains", reflect.TypeOf((*MockScaleSetVMScope)(nil).FailureDomains))
 }
 
+// GetConditionReason mocks base method.
+func (m *MockScaleSetVMScope) GetConditionReason(arg0 v1beta11.ConditionType) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConditionReason", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetConditionReason indicates an expected call of GetConditionReason.
+func (mr *MockScaleSetVMScopeMockRecorder) GetConditionReason(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConditionReason", reflect.TypeOf((*MockScaleSetVMScope)(nil).GetConditionReason), arg0)
+}
+
 // GetLongRunningOperationState mocks base method.
 func (m *MockScaleSetVMScope) GetLongRunningOperationState(arg0, arg1 string) *v1beta1.Future {
 	m.ctrl.T.Helper()
@@ -247,6 +304,49 @@ func (mr *MockScaleSetVMScopeMockRecorder) InstanceID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceID", reflect.TypeOf((*MockScaleSetVMScope)(nil).InstanceID))
 }
 
+// InstanceProtection mocks base method.
+func (m *MockScaleSetVMScope) InstanceProtection() v1beta10.InstanceProtectionType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstanceProtection")
+	ret0, _ := ret[0].(v1beta10.InstanceProtectionType)
+	return ret0
+}
+
+// InstanceProtection indicates an expected call of InstanceProtection.
+func (mr *MockScaleSetVMScopeMockRecorder) InstanceProtection() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceProtection", reflect.TypeOf((*MockScaleSetVMScope)(nil).InstanceProtection))
+}
+
+// IsConditionFalse mocks base method.
+func (m *MockScaleSetVMScope) IsConditionFalse(arg0 v1beta11.ConditionType) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsConditionFalse", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsConditionFalse indicates an expected call of IsConditionFalse.
+func (mr *MockScaleSetVMScopeMockRecorder) IsConditionFalse(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConditionFalse", reflect.TypeOf((*MockScaleSetVMScope)(nil).IsConditionFalse), arg0)
+}
+
+// IsNodeReadyAfterReimage mocks base method.
+func (m *MockScaleSetVMScope) IsNodeReadyAfterReimage(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsNodeReadyAfterReimage", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsNodeReadyAfterReimage indicates an expected call of IsNodeReadyAfterReimage.
+func (mr *MockScaleSetVMScopeMockRecorder) IsNodeReadyAfterReimage(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsNodeReadyAfterReimage", reflect.TypeOf((*MockScaleSetVMScope)(nil).IsNodeReadyAfterReimage), ctx)
+}
+
 // Location mocks base method.
 func (m *MockScaleSetVMScope) Location() string {
 	m.ctrl.T.Helper()
@@ -261,6 +361,62 @@ func (mr *MockScaleSetVMScopeMockRecorder) Location() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Location", reflect.TypeOf((*MockScaleSetVMScope)(nil).Location))
 }
 
+// OrchestrationMode mocks base method.
+func (m *MockScaleSetVMScope) OrchestrationMode() v1beta1.OrchestrationModeType {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OrchestrationMode")
+	ret0, _ := ret[0].(v1beta1.OrchestrationModeType)
+	return ret0
+}
+
+// OrchestrationMode indicates an expected call of OrchestrationMode.
+func (mr *MockScaleSetVMScopeMockRecorder) OrchestrationMode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrchestrationMode", reflect.TypeOf((*MockScaleSetVMScope)(nil).OrchestrationMode))
+}
+
+// ProvisioningState mocks base method.
+func (m *MockScaleSetVMScope) ProvisioningState() v1beta1.ProvisioningState {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisioningState")
+	ret0, _ := ret[0].(v1beta1.ProvisioningState)
+	return ret0
+}
+
+// ProvisioningState indicates an expected call of ProvisioningState.
+func (mr *MockScaleSetVMScopeMockRecorder) ProvisioningState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisioningState", reflect.TypeOf((*MockScaleSetVMScope)(nil).ProvisioningState))
+}
+
+// ReimageRequested mocks base method.
+func (m *MockScaleSetVMScope) ReimageRequested() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReimageRequested")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ReimageRequested indicates an expected call of ReimageRequested.
+func (mr *MockScaleSetVMScopeMockRecorder) ReimageRequested() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReimageRequested", reflect.TypeOf((*MockScaleSetVMScope)(nil).ReimageRequested))
+}
+
+// RequestRemediation mocks base method.
+func (m *MockScaleSetVMScope) RequestRemediation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRemediation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestRemediation indicates an expected call of RequestRemediation.
+func (mr *MockScaleSetVMScopeMockRecorder) RequestRemediation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRemediation", reflect.TypeOf((*MockScaleSetVMScope)(nil).RequestRemediation), arg0, arg1)
+}
+
 // ResourceGroup mocks base method.
 func (m *MockScaleSetVMScope) ResourceGroup() string {
 	m.ctrl.T.Helper()
@@ -289,6 +445,30 @@ func (mr *MockScaleSetVMScopeMockRecorder) ScaleSetName() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleSetName", reflect.TypeOf((*MockScaleSetVMScope)(nil).ScaleSetName))
 }
 
+// SetAnnotation mocks base method.
+func (m *MockScaleSetVMScope) SetAnnotation(arg0, arg1 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetAnnotation", arg0, arg1)
+}
+
+// SetAnnotation indicates an expected call of SetAnnotation.
+func (mr *MockScaleSetVMScopeMockRecorder) SetAnnotation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAnnotation", reflect.TypeOf((*MockScaleSetVMScope)(nil).SetAnnotation), arg0, arg1)
+}
+
+// SetConditionFalse mocks base method.
+func (m *MockScaleSetVMScope) SetConditionFalse(arg0 v1beta11.ConditionType, arg1 string, arg2 v1beta11.ConditionSeverity, arg3 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConditionFalse", arg0, arg1, arg2, arg3)
+}
+
+// SetConditionFalse indicates an expected call of SetConditionFalse.
+func (mr *MockScaleSetVMScopeMockRecorder) SetConditionFalse(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConditionFalse", reflect.TypeOf((*MockScaleSetVMScope)(nil).SetConditionFalse), arg0, arg1, arg2, arg3)
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockScaleSetVMScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -313,6 +493,20 @@ func (mr *MockScaleSetVMScopeMockRecorder) SetVMSSVM(vmssvm interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVMSSVM", reflect.TypeOf((*MockScaleSetVMScope)(nil).SetVMSSVM), vmssvm)
 }
 
+// SpotVMOptions mocks base method.
+func (m *MockScaleSetVMScope) SpotVMOptions() *v1beta1.SpotVMOptions {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpotVMOptions")
+	ret0, _ := ret[0].(*v1beta1.SpotVMOptions)
+	return ret0
+}
+
+// SpotVMOptions indicates an expected call of SpotVMOptions.
+func (mr *MockScaleSetVMScopeMockRecorder) SpotVMOptions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpotVMOptions", reflect.TypeOf((*MockScaleSetVMScope)(nil).SpotVMOptions))
+}
+
 // SubscriptionID mocks base method.
 func (m *MockScaleSetVMScope) SubscriptionID() string {
 	m.ctrl.T.Helper()
@@ -341,8 +535,22 @@ func (mr *MockScaleSetVMScopeMockRecorder) TenantID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantID", reflect.TypeOf((*MockScaleSetVMScope)(nil).TenantID))
 }
 
+// Uncordon mocks base method.
+func (m *MockScaleSetVMScope) Uncordon(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Uncordon", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Uncordon indicates an expected call of Uncordon.
+func (mr *MockScaleSetVMScopeMockRecorder) Uncordon(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uncordon", reflect.TypeOf((*MockScaleSetVMScope)(nil).Uncordon), ctx)
+}
+
 // UpdateDeleteStatus mocks base method.
-func (m *MockScaleSetVMScope) UpdateDeleteStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+func (m *MockScaleSetVMScope) UpdateDeleteStatus(arg0 v1beta11.ConditionType, arg1 string, arg2 error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "UpdateDeleteStatus", arg0, arg1, arg2)
 }
@@ -354,7 +562,7 @@ func (mr *MockScaleSetVMScopeMockRecorder) UpdateDeleteStatus(arg0, arg1, arg2 i
 }
 
 // UpdatePatchStatus mocks base method.
-func (m *MockScaleSetVMScope) UpdatePatchStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+func (m *MockScaleSetVMScope) UpdatePatchStatus(arg0 v1beta11.ConditionType, arg1 string, arg2 error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "UpdatePatchStatus", arg0, arg1, arg2)
 }
@@ -366,7 +574,7 @@ func (mr *MockScaleSetVMScopeMockRecorder) UpdatePatchStatus(arg0, arg1, arg2 in
 }
 
 // UpdatePutStatus mocks base method.
-func (m *MockScaleSetVMScope) UpdatePutStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
+func (m *MockScaleSetVMScope) UpdatePutStatus(arg0 v1beta11.ConditionType, arg1 string, arg2 error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "UpdatePutStatus", arg0, arg1, arg2)
 }
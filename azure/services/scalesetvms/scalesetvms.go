@@ -20,15 +20,37 @@ import (
 	"context"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
-const serviceName = "scalesetvms"
+const (
+	serviceName = "scalesetvms"
+
+	// reimageServiceName tracks the long-running operations of a reimage in place apart from a delete of the instance.
+	reimageServiceName = "scalesetvmreimage"
+
+	// reimageRequeue is how long to wait before checking on a step of a reimage in place again.
+	reimageRequeue = 15 * time.Second
+
+	// protectionRequeue is how long to wait before checking on an update of the protection of an instance.
+	protectionRequeue = 30 * time.Second
+)
+
+// ErrInstanceRemoved is returned when an instance which was seen before no longer exists in the scale set, e.g. because
+// an automatic repair replaced it with a new instance.
+var ErrInstanceRemoved = errors.New("instance no longer exists in the scale set")
+
+// spotVMRestartRequeue is how long to wait before checking on a deallocated spot instance that is being restarted.
+const spotVMRestartRequeue = 2 * time.Minute
 
 type (
 	// ScaleSetVMScope defines the scope interface for a scale sets service.
@@ -37,7 +59,22 @@ type (
 		azure.AsyncStatusUpdater
 		InstanceID() string
 		ScaleSetName() string
+		OrchestrationMode() infrav1.OrchestrationModeType
+		ProvisioningState() infrav1.ProvisioningState
 		SetVMSSVM(vmssvm *azure.VMSSVM)
+		SpotVMOptions() *infrav1.SpotVMOptions
+		SetConditionFalse(clusterv1.ConditionType, string, clusterv1.ConditionSeverity, string)
+		GetConditionReason(clusterv1.ConditionType) string
+		ReimageRequested() bool
+		CordonAndDrainForReimage(ctx context.Context) error
+		IsNodeReadyAfterReimage(ctx context.Context) (bool, error)
+		Uncordon(ctx context.Context) error
+		CompleteReimage()
+		InstanceProtection() infrav1exp.InstanceProtectionType
+		CloudProviderConfig(ctx context.Context) (*azure.CloudProviderConfig, error)
+		SetAnnotation(string, string)
+		IsConditionFalse(clusterv1.ConditionType) bool
+		RequestRemediation(context.Context, string) error
 	}
 
 	// Service provides operations on Azure resources.
@@ -67,16 +104,193 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	)
 
 	// fetch the latest data about the instance -- model mutations are handled by the AzureMachinePoolReconciler
-	instance, err := s.Client.Get(ctx, resourceGroup, vmssName, instanceID)
+	instance, statuses, err := s.getInstance(ctx, resourceGroup, vmssName, instanceID)
 	if err != nil {
 		if azure.ResourceNotFound(err) {
+			if s.Scope.ProvisioningState() != "" {
+				// the instance was found before, so it has been removed rather than not created yet
+				return ErrInstanceRemoved
+			}
 			return azure.WithTransientError(errors.New("instance does not exist yet"), 30*time.Second)
 		}
 		return errors.Wrap(err, "failed getting instance")
 	}
 
-	s.Scope.SetVMSSVM(converters.SDKToVMSSVM(instance))
-	return nil
+	s.Scope.SetVMSSVM(instance)
+	if err := s.reconcileReimage(ctx, resourceGroup, vmssName, instanceID); err != nil {
+		return err
+	}
+	if err := s.reconcileSpotEviction(ctx, resourceGroup, vmssName, instanceID, statuses); err != nil {
+		return err
+	}
+	if err := s.reconcileProtection(ctx, resourceGroup, vmssName, instanceID, instance); err != nil {
+		return err
+	}
+	return s.reconcileCloudProviderConfig(ctx, resourceGroup, vmssName, instanceID, statuses)
+}
+
+// getInstance fetches an instance of the scale set along with the statuses of its instance view. Instances of a
+// Flexible scale set are standalone virtual machines, so they are fetched by name through the virtual machines API.
+func (s *Service) getInstance(ctx context.Context, resourceGroup, vmssName, instanceID string) (*azure.VMSSVM, *[]compute.InstanceViewStatus, error) {
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		vm, err := s.Client.GetVM(ctx, resourceGroup, instanceID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var statuses *[]compute.InstanceViewStatus
+		if vm.VirtualMachineProperties != nil && vm.InstanceView != nil {
+			statuses = vm.InstanceView.Statuses
+		}
+		return converters.SDKVMToVMSSVM(vm), statuses, nil
+	}
+
+	instance, err := s.Client.Get(ctx, resourceGroup, vmssName, instanceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var statuses *[]compute.InstanceViewStatus
+	if instance.VirtualMachineScaleSetVMProperties != nil && instance.InstanceView != nil {
+		statuses = instance.InstanceView.Statuses
+	}
+	return converters.SDKToVMSSVM(instance), statuses, nil
+}
+
+// reconcileSpotEviction handles a spot instance that was deallocated by an eviction according to its recovery policy.
+func (s *Service) reconcileSpotEviction(ctx context.Context, resourceGroup, vmssName, instanceID string, statuses *[]compute.InstanceViewStatus) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesetvms.Service.reconcileSpotEviction")
+	defer done()
+
+	spotVMOptions := s.Scope.SpotVMOptions()
+	if spotVMOptions == nil || (spotVMOptions.EvictionPolicy != nil && *spotVMOptions.EvictionPolicy != infrav1.SpotEvictionPolicyDeallocate) {
+		return nil
+	}
+	if !converters.IsDeallocated(statuses) {
+		s.Scope.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, nil)
+		return nil
+	}
+
+	if spotVMOptions.RecoveryPolicy == nil || *spotVMOptions.RecoveryPolicy == infrav1.SpotRecoveryPolicyRemediate {
+		log.V(2).Info("spot instance was deallocated by an eviction", "instanceID", instanceID)
+		s.Scope.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityError, "spot instance was deallocated by an eviction")
+		return s.Scope.RequestRemediation(ctx, "spot instance was deallocated by an eviction")
+	}
+
+	log.V(2).Info("restarting spot instance deallocated by an eviction", "instanceID", instanceID)
+	s.Scope.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, "restarting spot instance deallocated by an eviction")
+	if err := s.startInstance(ctx, resourceGroup, vmssName, instanceID); err != nil {
+		return azure.WithTransientError(errors.Wrap(err, "failed to restart spot instance deallocated by an eviction"), spotVMRestartRequeue)
+	}
+	return azure.WithTransientError(errors.New("spot instance deallocated by an eviction is restarting"), spotVMRestartRequeue)
+}
+
+// reconcileReimage reimages an instance of a Uniform scale set in place when the ReimageInPlace deployment strategy of
+// the machine pool selected it. The node is drained, the latest model is applied to the instance, the instance is
+// reimaged and the node is uncordoned once it is Ready again, one long-running operation at a time.
+func (s *Service) reconcileReimage(ctx context.Context, resourceGroup, vmssName, instanceID string) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesetvms.Service.reconcileReimage")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(instanceID, reimageServiceName); future != nil {
+		if _, err := s.Client.GetResultIfDone(ctx, future); err != nil {
+			if azure.IsOperationNotDoneError(err) {
+				return err
+			}
+			s.Scope.DeleteLongRunningOperationState(instanceID, reimageServiceName)
+			s.Scope.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return errors.Wrap(err, "failed to reimage instance")
+		}
+		s.Scope.DeleteLongRunningOperationState(instanceID, reimageServiceName)
+	}
+
+	if !s.Scope.ReimageRequested() || s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return nil
+	}
+
+	switch s.Scope.GetConditionReason(infrav1.InstanceReimagedCondition) {
+	case infrav1.InstanceReimageUpdatingModelReason:
+		log.V(2).Info("reimaging instance", "instanceID", instanceID)
+		s.Scope.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimagingReason, clusterv1.ConditionSeverityInfo, "reimaging the instance")
+		future, err := s.Client.ReimageAsync(ctx, resourceGroup, vmssName, instanceID)
+		return s.trackReimageOperation(instanceID, future, err)
+
+	case infrav1.InstanceReimagingReason, infrav1.InstanceReimageWaitingForNodeReason:
+		s.Scope.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageWaitingForNodeReason, clusterv1.ConditionSeverityInfo, "waiting for the node of the reimaged instance to be Ready")
+		ready, err := s.Scope.IsNodeReadyAfterReimage(ctx)
+		if err != nil {
+			return err
+		}
+		if !ready {
+			return azure.WithTransientError(errors.New("waiting for the node of the reimaged instance to be Ready"), reimageRequeue)
+		}
+
+		if err := s.Scope.Uncordon(ctx); err != nil {
+			return err
+		}
+		log.V(2).Info("reimaged instance", "instanceID", instanceID)
+		s.Scope.CompleteReimage()
+		return nil
+
+	default:
+		log.V(2).Info("draining node before reimaging instance", "instanceID", instanceID)
+		s.Scope.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageDrainingReason, clusterv1.ConditionSeverityInfo, "draining the node before reimaging the instance")
+		if err := s.Scope.CordonAndDrainForReimage(ctx); err != nil {
+			return err
+		}
+
+		s.Scope.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageUpdatingModelReason, clusterv1.ConditionSeverityInfo, "applying the latest model to the instance")
+		future, err := s.Client.UpdateInstancesAsync(ctx, resourceGroup, vmssName, instanceID)
+		return s.trackReimageOperation(instanceID, future, err)
+	}
+}
+
+// trackReimageOperation stores the future of a step of a reimage in place so it can be checked on the next reconciles.
+func (s *Service) trackReimageOperation(instanceID string, future *infrav1.Future, err error) error {
+	if err != nil {
+		s.Scope.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return errors.Wrapf(err, "failed to reimage instance %s", instanceID)
+	}
+
+	s.Scope.SetLongRunningOperationState(future)
+	return azure.WithTransientError(azure.NewOperationNotDoneError(future), reimageRequeue)
+}
+
+// reconcileProtection applies the protection from scale in or from all the actions of the scale set requested for the
+// instance. Instances of a Flexible scale set are standalone virtual machines without a protection policy.
+func (s *Service) reconcileProtection(ctx context.Context, resourceGroup, vmssName, instanceID string, instance *azure.VMSSVM) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scalesetvms.Service.reconcileProtection")
+	defer done()
+
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return nil
+	}
+
+	protection := s.Scope.InstanceProtection()
+	protectFromScaleSetActions := protection == infrav1exp.ScaleSetActionsInstanceProtection
+	// an instance protected from all the actions of its scale set is protected from scale in as well
+	protectFromScaleIn := protectFromScaleSetActions || protection == infrav1exp.ScaleInInstanceProtection
+	if instance.ProtectFromScaleIn == protectFromScaleIn && instance.ProtectFromScaleSetActions == protectFromScaleSetActions {
+		return nil
+	}
+
+	log.V(2).Info("updating instance protection", "instanceID", instanceID, "protectFromScaleIn", protectFromScaleIn, "protectFromScaleSetActions", protectFromScaleSetActions)
+	policy := compute.VirtualMachineScaleSetVMProtectionPolicy{
+		ProtectFromScaleIn:         to.BoolPtr(protectFromScaleIn),
+		ProtectFromScaleSetActions: to.BoolPtr(protectFromScaleSetActions),
+	}
+	if err := s.Client.UpdateProtectionPolicy(ctx, resourceGroup, vmssName, instanceID, policy); err != nil {
+		return errors.Wrap(err, "failed to update instance protection")
+	}
+	return azure.WithTransientError(errors.New("instance protection is being updated"), protectionRequeue)
+}
+
+// startInstance starts a deallocated instance of the scale set.
+func (s *Service) startInstance(ctx context.Context, resourceGroup, vmssName, instanceID string) error {
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return s.Client.StartVM(ctx, resourceGroup, instanceID)
+	}
+	return s.Client.Start(ctx, resourceGroup, vmssName, instanceID)
 }
 
 // Delete deletes a scaleset instance asynchronously returning a future which encapsulates the long-running operation.
@@ -97,9 +311,9 @@ func (s *Service) Delete(ctx context.Context) error {
 	defer done()
 
 	defer func() {
-		if instance, err := s.Client.Get(ctx, resourceGroup, vmssName, instanceID); err == nil && instance.VirtualMachineScaleSetVMProperties != nil {
-			log.V(4).Info("updating vmss vm state", "state", instance.ProvisioningState)
-			s.Scope.SetVMSSVM(converters.SDKToVMSSVM(instance))
+		if instance, _, err := s.getInstance(ctx, resourceGroup, vmssName, instanceID); err == nil && instance.State != "" {
+			log.V(4).Info("updating vmss vm state", "state", instance.State)
+			s.Scope.SetVMSSVM(instance)
 		}
 	}()
 
@@ -123,7 +337,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	}
 
 	// since the future was nil, there is no ongoing activity; start deleting the instance
-	future, err := s.Client.DeleteAsync(ctx, resourceGroup, vmssName, instanceID)
+	future, err := s.deleteInstanceAsync(ctx, resourceGroup, vmssName, instanceID)
 	if err != nil {
 		if azure.ResourceNotFound(err) {
 			// already deleted
@@ -143,3 +357,11 @@ func (s *Service) Delete(ctx context.Context) error {
 	s.Scope.DeleteLongRunningOperationState(instanceID, serviceName)
 	return nil
 }
+
+// deleteInstanceAsync starts deleting an instance of the scale set.
+func (s *Service) deleteInstanceAsync(ctx context.Context, resourceGroup, vmssName, instanceID string) (*infrav1.Future, error) {
+	if s.Scope.OrchestrationMode() == infrav1.FlexibleOrchestrationMode {
+		return s.Client.DeleteVMAsync(ctx, resourceGroup, instanceID)
+	}
+	return s.Client.DeleteAsync(ctx, resourceGroup, vmssName, instanceID)
+}
@@ -90,10 +90,11 @@ func TestNewService(t *testing.T) {
 
 func TestService_Reconcile(t *testing.T) {
 	cases := []struct {
-		Name       string
-		Setup      func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
-		Err        error
-		CheckIsErr bool
+		Name              string
+		OrchestrationMode infrav1.OrchestrationModeType
+		Setup             func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
+		Err               error
+		CheckIsErr        bool
 	}{
 		{
 			Name: "should reconcile successfully",
@@ -106,8 +107,107 @@ func TestService_Reconcile(t *testing.T) {
 				}
 				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
 				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.SpotVMOptions().Return(nil)
 			},
 		},
+		{
+			Name: "should mark a running spot instance as running",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := spotInstance("PowerState/running")
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.SpotVMOptions().Return(&infrav1.SpotVMOptions{})
+				s.UpdatePutStatus(infrav1.VMRunningCondition, serviceName, nil)
+			},
+		},
+		{
+			Name: "should mark a spot instance deallocated by an eviction as not running and replace it",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := spotInstance("PowerState/deallocated")
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				s.SpotVMOptions().Return(&infrav1.SpotVMOptions{})
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityError, gomock.Any())
+				s.RequestRemediation(gomock2.AContext(), "spot instance was deallocated by an eviction").Return(nil)
+			},
+		},
+		{
+			Name: "should restart a spot instance deallocated by an eviction",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := spotInstance("PowerState/deallocated")
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				restart := infrav1.SpotRecoveryPolicyRestart
+				s.SpotVMOptions().Return(&infrav1.SpotVMOptions{RecoveryPolicy: &restart})
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+				m.Start(gomock2.AContext(), "rg", "scaleset", "0").Return(nil)
+			},
+			Err: azure.WithTransientError(errors.New("spot instance deallocated by an eviction is restarting"), spotVMRestartRequeue),
+		},
+		{
+			Name: "should ignore a deallocated spot instance with the delete eviction policy",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				vm := spotInstance("PowerState/deallocated")
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKToVMSSVM(vm))
+				deletePolicy := infrav1.SpotEvictionPolicyDelete
+				s.SpotVMOptions().Return(&infrav1.SpotVMOptions{EvictionPolicy: &deletePolicy})
+			},
+		},
+		{
+			Name:              "should reconcile a flexible instance successfully",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("scaleset_0123abcd")
+				s.ScaleSetName().Return("scaleset")
+				vm := compute.VirtualMachine{
+					Name: to.StringPtr("scaleset_0123abcd"),
+				}
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_0123abcd").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKVMToVMSSVM(vm))
+				s.SpotVMOptions().Return(nil)
+			},
+		},
+		{
+			Name:              "should restart a flexible spot instance deallocated by an eviction",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("scaleset_0123abcd")
+				s.ScaleSetName().Return("scaleset")
+				vm := compute.VirtualMachine{
+					Name: to.StringPtr("scaleset_0123abcd"),
+					VirtualMachineProperties: &compute.VirtualMachineProperties{
+						InstanceView: &compute.VirtualMachineInstanceView{
+							Statuses: &[]compute.InstanceViewStatus{
+								{Code: to.StringPtr("ProvisioningState/succeeded")},
+								{Code: to.StringPtr("PowerState/deallocated")},
+							},
+						},
+					},
+				}
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_0123abcd").Return(vm, nil)
+				s.SetVMSSVM(converters.SDKVMToVMSSVM(vm))
+				restart := infrav1.SpotRecoveryPolicyRestart
+				s.SpotVMOptions().Return(&infrav1.SpotVMOptions{RecoveryPolicy: &restart})
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+				m.StartVM(gomock2.AContext(), "rg", "scaleset_0123abcd").Return(nil)
+			},
+			Err: azure.WithTransientError(errors.New("spot instance deallocated by an eviction is restarting"), spotVMRestartRequeue),
+		},
 		{
 			Name: "if 404, then should respond with transient error",
 			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
@@ -115,10 +215,36 @@ func TestService_Reconcile(t *testing.T) {
 				s.InstanceID().Return("0")
 				s.ScaleSetName().Return("scaleset")
 				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(compute.VirtualMachineScaleSetVM{}, autorest404)
+				s.ProvisioningState().Return(infrav1.ProvisioningState(""))
 			},
 			Err:        azure.WithTransientError(errors.New("instance does not exist yet"), 30*time.Second),
 			CheckIsErr: true,
 		},
+		{
+			Name: "if 404 for an instance which was found before, then should respond with instance removed error",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("0")
+				s.ScaleSetName().Return("scaleset")
+				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(compute.VirtualMachineScaleSetVM{}, autorest404)
+				s.ProvisioningState().Return(infrav1.Succeeded)
+			},
+			Err:        ErrInstanceRemoved,
+			CheckIsErr: true,
+		},
+		{
+			Name:              "if 404 for a flexible instance which was found before, then should respond with instance removed error",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("scaleset_0123abcd")
+				s.ScaleSetName().Return("scaleset")
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_0123abcd").Return(compute.VirtualMachine{}, autorest404)
+				s.ProvisioningState().Return(infrav1.Succeeded)
+			},
+			Err:        ErrInstanceRemoved,
+			CheckIsErr: true,
+		},
 		{
 			Name: "if other error, then should respond with error",
 			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
@@ -141,12 +267,22 @@ func TestService_Reconcile(t *testing.T) {
 			)
 			defer mockCtrl.Finish()
 
-			scopeMock.EXPECT().SubscriptionID().Return("subID")
-			scopeMock.EXPECT().BaseURI().Return("https://localhost/")
-			scopeMock.EXPECT().Authorizer().Return(nil)
+			scopeMock.EXPECT().SubscriptionID().Return("subID").AnyTimes()
+			scopeMock.EXPECT().BaseURI().Return("https://localhost/").AnyTimes()
+			scopeMock.EXPECT().Authorizer().Return(nil).AnyTimes()
 
 			service := NewService(scopeMock)
 			service.Client = clientMock
+			orchestrationMode := infrav1.UniformOrchestrationMode
+			if c.OrchestrationMode != "" {
+				orchestrationMode = c.OrchestrationMode
+			}
+			scopeMock.EXPECT().OrchestrationMode().Return(orchestrationMode).AnyTimes()
+			scopeMock.EXPECT().GetLongRunningOperationState(gomock.Any(), reimageServiceName).Return(nil).AnyTimes()
+			scopeMock.EXPECT().ReimageRequested().Return(false).AnyTimes()
+			scopeMock.EXPECT().InstanceProtection().Return(infrav1exp.InstanceProtectionType("")).AnyTimes()
+			scopeMock.EXPECT().GetLongRunningOperationState(gomock.Any(), cloudProviderConfigServiceName).Return(nil).AnyTimes()
+			scopeMock.EXPECT().CloudProviderConfig(gomock2.AContext()).Return(nil, nil).AnyTimes()
 			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
 
 			if err := service.Reconcile(context.TODO()); c.Err == nil {
@@ -162,12 +298,261 @@ func TestService_Reconcile(t *testing.T) {
 	}
 }
 
+func spotInstance(powerState string) compute.VirtualMachineScaleSetVM {
+	return compute.VirtualMachineScaleSetVM{
+		InstanceID: to.StringPtr("0"),
+		VirtualMachineScaleSetVMProperties: &compute.VirtualMachineScaleSetVMProperties{
+			InstanceView: &compute.VirtualMachineScaleSetVMInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ProvisioningState/succeeded")},
+					{Code: to.StringPtr(powerState)},
+				},
+			},
+		},
+	}
+}
+
+func TestService_reconcileReimage(t *testing.T) {
+	reimageFuture := &infrav1.Future{
+		Type:          infrav1.PostFuture,
+		ServiceName:   reimageServiceName,
+		Name:          "0",
+		ResourceGroup: "rg",
+	}
+
+	cases := []struct {
+		Name  string
+		Setup func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
+		Err   error
+	}{
+		{
+			Name: "should do nothing if no reimage was requested",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(nil)
+				s.ReimageRequested().Return(false)
+			},
+		},
+		{
+			Name: "should drain the node and apply the latest model to the instance",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(nil)
+				s.ReimageRequested().Return(true)
+				s.GetConditionReason(infrav1.InstanceReimagedCondition).Return("")
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageDrainingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.CordonAndDrainForReimage(gomock2.AContext()).Return(nil)
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageUpdatingModelReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.UpdateInstancesAsync(gomock2.AContext(), "rg", "scaleset", "0").Return(reimageFuture, nil)
+				s.SetLongRunningOperationState(reimageFuture)
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(reimageFuture), reimageRequeue),
+		},
+		{
+			Name: "should not apply the latest model while the node is draining",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(nil)
+				s.ReimageRequested().Return(true)
+				s.GetConditionReason(infrav1.InstanceReimagedCondition).Return(infrav1.InstanceReimageDrainingReason)
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageDrainingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.CordonAndDrainForReimage(gomock2.AContext()).Return(azure.WithTransientError(errors.New("Drain failed, retry in 20s"), 20*time.Second))
+			},
+			Err: azure.WithTransientError(errors.New("Drain failed, retry in 20s"), 20*time.Second),
+		},
+		{
+			Name: "should wait for an operation in progress",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(reimageFuture)
+				m.GetResultIfDone(gomock2.AContext(), reimageFuture).Return(compute.VirtualMachineScaleSetVM{}, azure.WithTransientError(azure.NewOperationNotDoneError(reimageFuture), 15*time.Second))
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(reimageFuture), 15*time.Second),
+		},
+		{
+			Name: "should reimage the instance once the latest model was applied",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(reimageFuture)
+				m.GetResultIfDone(gomock2.AContext(), reimageFuture).Return(compute.VirtualMachineScaleSetVM{}, nil)
+				s.DeleteLongRunningOperationState("0", reimageServiceName)
+				s.ReimageRequested().Return(true)
+				s.GetConditionReason(infrav1.InstanceReimagedCondition).Return(infrav1.InstanceReimageUpdatingModelReason)
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimagingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.ReimageAsync(gomock2.AContext(), "rg", "scaleset", "0").Return(reimageFuture, nil)
+				s.SetLongRunningOperationState(reimageFuture)
+			},
+			Err: azure.WithTransientError(azure.NewOperationNotDoneError(reimageFuture), reimageRequeue),
+		},
+		{
+			Name: "should mark the reimage as failed if an operation failed",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(reimageFuture)
+				m.GetResultIfDone(gomock2.AContext(), reimageFuture).Return(compute.VirtualMachineScaleSetVM{}, errors.New("boom"))
+				s.DeleteLongRunningOperationState("0", reimageServiceName)
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageFailedReason, clusterv1.ConditionSeverityError, "boom")
+			},
+			Err: errors.Wrap(errors.New("boom"), "failed to reimage instance"),
+		},
+		{
+			Name: "should wait for the node once the instance was reimaged",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(reimageFuture)
+				m.GetResultIfDone(gomock2.AContext(), reimageFuture).Return(compute.VirtualMachineScaleSetVM{}, nil)
+				s.DeleteLongRunningOperationState("0", reimageServiceName)
+				s.ReimageRequested().Return(true)
+				s.GetConditionReason(infrav1.InstanceReimagedCondition).Return(infrav1.InstanceReimagingReason)
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageWaitingForNodeReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.IsNodeReadyAfterReimage(gomock2.AContext()).Return(false, nil)
+			},
+			Err: azure.WithTransientError(errors.New("waiting for the node of the reimaged instance to be Ready"), reimageRequeue),
+		},
+		{
+			Name: "should uncordon the node and complete the reimage once the node is Ready",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.GetLongRunningOperationState("0", reimageServiceName).Return(nil)
+				s.ReimageRequested().Return(true)
+				s.GetConditionReason(infrav1.InstanceReimagedCondition).Return(infrav1.InstanceReimageWaitingForNodeReason)
+				s.SetConditionFalse(infrav1.InstanceReimagedCondition, infrav1.InstanceReimageWaitingForNodeReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.IsNodeReadyAfterReimage(gomock2.AContext()).Return(true, nil)
+				s.Uncordon(gomock2.AContext()).Return(nil)
+				s.CompleteReimage()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				g          = NewWithT(t)
+				mockCtrl   = gomock.NewController(t)
+				scopeMock  = mock_scalesetvms.NewMockScaleSetVMScope(mockCtrl)
+				clientMock = mock_scalesetvms.NewMockclient(mockCtrl)
+			)
+			defer mockCtrl.Finish()
+
+			scopeMock.EXPECT().OrchestrationMode().Return(infrav1.UniformOrchestrationMode).AnyTimes()
+			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			service := &Service{
+				Client: clientMock,
+				Scope:  scopeMock,
+			}
+			if err := service.reconcileReimage(context.TODO(), "rg", "scaleset", "0"); c.Err == nil {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(c.Err.Error()))
+			}
+		})
+	}
+}
+
+func TestService_reconcileProtection(t *testing.T) {
+	cases := []struct {
+		Name              string
+		OrchestrationMode infrav1.OrchestrationModeType
+		Instance          azure.VMSSVM
+		Setup             func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
+		Err               error
+	}{
+		{
+			Name: "should do nothing if the instance is not protected",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.InstanceProtection().Return(infrav1exp.InstanceProtectionType(""))
+			},
+		},
+		{
+			Name: "should protect the instance from scale in",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.InstanceProtection().Return(infrav1exp.ScaleInInstanceProtection)
+				m.UpdateProtectionPolicy(gomock2.AContext(), "rg", "scaleset", "0", compute.VirtualMachineScaleSetVMProtectionPolicy{
+					ProtectFromScaleIn:         to.BoolPtr(true),
+					ProtectFromScaleSetActions: to.BoolPtr(false),
+				}).Return(nil)
+			},
+			Err: azure.WithTransientError(errors.New("instance protection is being updated"), protectionRequeue),
+		},
+		{
+			Name: "should protect the instance from scale set actions and scale in",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.InstanceProtection().Return(infrav1exp.ScaleSetActionsInstanceProtection)
+				m.UpdateProtectionPolicy(gomock2.AContext(), "rg", "scaleset", "0", compute.VirtualMachineScaleSetVMProtectionPolicy{
+					ProtectFromScaleIn:         to.BoolPtr(true),
+					ProtectFromScaleSetActions: to.BoolPtr(true),
+				}).Return(nil)
+			},
+			Err: azure.WithTransientError(errors.New("instance protection is being updated"), protectionRequeue),
+		},
+		{
+			Name:     "should do nothing if the instance is already protected",
+			Instance: azure.VMSSVM{ProtectFromScaleIn: true},
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.InstanceProtection().Return(infrav1exp.ScaleInInstanceProtection)
+			},
+		},
+		{
+			Name:     "should remove the protection of an instance which is no longer protected",
+			Instance: azure.VMSSVM{ProtectFromScaleIn: true, ProtectFromScaleSetActions: true},
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.InstanceProtection().Return(infrav1exp.InstanceProtectionType(""))
+				m.UpdateProtectionPolicy(gomock2.AContext(), "rg", "scaleset", "0", compute.VirtualMachineScaleSetVMProtectionPolicy{
+					ProtectFromScaleIn:         to.BoolPtr(false),
+					ProtectFromScaleSetActions: to.BoolPtr(false),
+				}).Return(nil)
+			},
+			Err: azure.WithTransientError(errors.New("instance protection is being updated"), protectionRequeue),
+		},
+		{
+			Name: "should return an error if the protection could not be updated",
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.InstanceProtection().Return(infrav1exp.ScaleInInstanceProtection)
+				m.UpdateProtectionPolicy(gomock2.AContext(), "rg", "scaleset", "0", gomock.Any()).Return(errors.New("boom"))
+			},
+			Err: errors.Wrap(errors.New("boom"), "failed to update instance protection"),
+		},
+		{
+			Name:              "should ignore the protection of a flexible instance",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				g          = NewWithT(t)
+				mockCtrl   = gomock.NewController(t)
+				scopeMock  = mock_scalesetvms.NewMockScaleSetVMScope(mockCtrl)
+				clientMock = mock_scalesetvms.NewMockclient(mockCtrl)
+			)
+			defer mockCtrl.Finish()
+
+			orchestrationMode := infrav1.UniformOrchestrationMode
+			if c.OrchestrationMode != "" {
+				orchestrationMode = c.OrchestrationMode
+			}
+			scopeMock.EXPECT().OrchestrationMode().Return(orchestrationMode).AnyTimes()
+			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			service := &Service{
+				Client: clientMock,
+				Scope:  scopeMock,
+			}
+			instance := c.Instance
+			if err := service.reconcileProtection(context.TODO(), "rg", "scaleset", "0", &instance); c.Err == nil {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(c.Err.Error()))
+			}
+		})
+	}
+}
+
 func TestService_Delete(t *testing.T) {
 	cases := []struct {
-		Name       string
-		Setup      func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
-		Err        error
-		CheckIsErr bool
+		Name              string
+		OrchestrationMode infrav1.OrchestrationModeType
+		Setup             func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder)
+		Err               error
+		CheckIsErr        bool
 	}{
 		{
 			Name: "should start deleting successfully if no long running operation is active",
@@ -204,6 +589,24 @@ func TestService_Delete(t *testing.T) {
 				m.Get(gomock2.AContext(), "rg", "scaleset", "0").Return(compute.VirtualMachineScaleSetVM{}, nil)
 			},
 		},
+		{
+			Name:              "should start deleting a flexible instance successfully if no long running operation is active",
+			OrchestrationMode: infrav1.FlexibleOrchestrationMode,
+			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
+				s.ResourceGroup().Return("rg")
+				s.InstanceID().Return("scaleset_0123abcd")
+				s.ScaleSetName().Return("scaleset")
+				s.GetLongRunningOperationState("scaleset_0123abcd", serviceName).Return(nil)
+				future := &infrav1.Future{
+					Type: infrav1.DeleteFuture,
+				}
+				m.DeleteVMAsync(gomock2.AContext(), "rg", "scaleset_0123abcd").Return(future, nil)
+				s.SetLongRunningOperationState(future)
+				m.GetResultIfDone(gomock2.AContext(), future).Return(compute.VirtualMachineScaleSetVM{}, nil)
+				s.DeleteLongRunningOperationState("scaleset_0123abcd", serviceName)
+				m.GetVM(gomock2.AContext(), "rg", "scaleset_0123abcd").Return(compute.VirtualMachine{}, autorest404)
+			},
+		},
 		{
 			Name: "should not error when deleting, but resource is 404",
 			Setup: func(s *mock_scalesetvms.MockScaleSetVMScopeMockRecorder, m *mock_scalesetvms.MockclientMockRecorder) {
@@ -254,12 +657,17 @@ func TestService_Delete(t *testing.T) {
 			)
 			defer mockCtrl.Finish()
 
-			scopeMock.EXPECT().SubscriptionID().Return("subID")
-			scopeMock.EXPECT().BaseURI().Return("https://localhost/")
-			scopeMock.EXPECT().Authorizer().Return(nil)
+			scopeMock.EXPECT().SubscriptionID().Return("subID").AnyTimes()
+			scopeMock.EXPECT().BaseURI().Return("https://localhost/").AnyTimes()
+			scopeMock.EXPECT().Authorizer().Return(nil).AnyTimes()
 
 			service := NewService(scopeMock)
 			service.Client = clientMock
+			orchestrationMode := infrav1.UniformOrchestrationMode
+			if c.OrchestrationMode != "" {
+				orchestrationMode = c.OrchestrationMode
+			}
+			scopeMock.EXPECT().OrchestrationMode().Return(orchestrationMode).AnyTimes()
 			c.Setup(scopeMock.EXPECT(), clientMock.EXPECT())
 
 			if err := service.Delete(context.TODO()); c.Err == nil {
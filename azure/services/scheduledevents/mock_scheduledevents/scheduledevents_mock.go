@@ -0,0 +1,176 @@
+/*
+Copyright The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ../scheduledevents.go
+
+// Package mock_scheduledevents is a generated GoMock package.
+package mock_scheduledevents
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "k8s.io/api/core/v1"
+	v1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// MockScheduledEventsScope is a mock of ScheduledEventsScope interface.
+type MockScheduledEventsScope struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledEventsScopeMockRecorder
+}
+
+// MockScheduledEventsScopeMockRecorder is the mock recorder for MockScheduledEventsScope.
+type MockScheduledEventsScopeMockRecorder struct {
+	mock *MockScheduledEventsScope
+}
+
+// NewMockScheduledEventsScope creates a new mock instance.
+func NewMockScheduledEventsScope(ctrl *gomock.Controller) *MockScheduledEventsScope {
+	mock := &MockScheduledEventsScope{ctrl: ctrl}
+	mock.recorder = &MockScheduledEventsScopeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduledEventsScope) EXPECT() *MockScheduledEventsScopeMockRecorder {
+	return m.recorder
+}
+
+// CordonAndDrainForScheduledEvent mocks base method.
+func (m *MockScheduledEventsScope) CordonAndDrainForScheduledEvent(ctx context.Context, eventType string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CordonAndDrainForScheduledEvent", ctx, eventType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CordonAndDrainForScheduledEvent indicates an expected call of CordonAndDrainForScheduledEvent.
+func (mr *MockScheduledEventsScopeMockRecorder) CordonAndDrainForScheduledEvent(ctx, eventType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CordonAndDrainForScheduledEvent", reflect.TypeOf((*MockScheduledEventsScope)(nil).CordonAndDrainForScheduledEvent), ctx, eventType)
+}
+
+// GetNode mocks base method.
+func (m *MockScheduledEventsScope) GetNode(ctx context.Context) (*v1.Node, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNode", ctx)
+	ret0, _ := ret[0].(*v1.Node)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNode indicates an expected call of GetNode.
+func (mr *MockScheduledEventsScopeMockRecorder) GetNode(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNode", reflect.TypeOf((*MockScheduledEventsScope)(nil).GetNode), ctx)
+}
+
+// IsConditionFalse mocks base method.
+func (m *MockScheduledEventsScope) IsConditionFalse(arg0 v1beta1.ConditionType) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsConditionFalse", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsConditionFalse indicates an expected call of IsConditionFalse.
+func (mr *MockScheduledEventsScopeMockRecorder) IsConditionFalse(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConditionFalse", reflect.TypeOf((*MockScheduledEventsScope)(nil).IsConditionFalse), arg0)
+}
+
+// IsReady mocks base method.
+func (m *MockScheduledEventsScope) IsReady() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsReady")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsReady indicates an expected call of IsReady.
+func (mr *MockScheduledEventsScopeMockRecorder) IsReady() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsReady", reflect.TypeOf((*MockScheduledEventsScope)(nil).IsReady))
+}
+
+// ScheduledEventsEnabled mocks base method.
+func (m *MockScheduledEventsScope) ScheduledEventsEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduledEventsEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ScheduledEventsEnabled indicates an expected call of ScheduledEventsEnabled.
+func (mr *MockScheduledEventsScopeMockRecorder) ScheduledEventsEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduledEventsEnabled", reflect.TypeOf((*MockScheduledEventsScope)(nil).ScheduledEventsEnabled))
+}
+
+// SetConditionFalse mocks base method.
+func (m *MockScheduledEventsScope) SetConditionFalse(arg0 v1beta1.ConditionType, arg1 string, arg2 v1beta1.ConditionSeverity, arg3 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConditionFalse", arg0, arg1, arg2, arg3)
+}
+
+// SetConditionFalse indicates an expected call of SetConditionFalse.
+func (mr *MockScheduledEventsScopeMockRecorder) SetConditionFalse(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConditionFalse", reflect.TypeOf((*MockScheduledEventsScope)(nil).SetConditionFalse), arg0, arg1, arg2, arg3)
+}
+
+// SetScheduledEventApproval mocks base method.
+func (m *MockScheduledEventsScope) SetScheduledEventApproval(ctx context.Context, eventID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetScheduledEventApproval", ctx, eventID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetScheduledEventApproval indicates an expected call of SetScheduledEventApproval.
+func (mr *MockScheduledEventsScopeMockRecorder) SetScheduledEventApproval(ctx, eventID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetScheduledEventApproval", reflect.TypeOf((*MockScheduledEventsScope)(nil).SetScheduledEventApproval), ctx, eventID)
+}
+
+// Uncordon mocks base method.
+func (m *MockScheduledEventsScope) Uncordon(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Uncordon", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Uncordon indicates an expected call of Uncordon.
+func (mr *MockScheduledEventsScopeMockRecorder) Uncordon(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uncordon", reflect.TypeOf((*MockScheduledEventsScope)(nil).Uncordon), ctx)
+}
+
+// UpdatePutStatus mocks base method.
+func (m *MockScheduledEventsScope) UpdatePutStatus(arg0 v1beta1.ConditionType, arg1 string, arg2 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatePutStatus", arg0, arg1, arg2)
+}
+
+// UpdatePutStatus indicates an expected call of UpdatePutStatus.
+func (mr *MockScheduledEventsScopeMockRecorder) UpdatePutStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePutStatus", reflect.TypeOf((*MockScheduledEventsScope)(nil).UpdatePutStatus), arg0, arg1, arg2)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const serviceName = "scheduledevents"
+
+// Scheduled event types disrupting the workloads of an instance.
+const (
+	eventTypeTerminate = "Terminate"
+	eventTypePreempt   = "Preempt"
+	eventTypeReboot    = "Reboot"
+	eventTypeRedeploy  = "Redeploy"
+)
+
+type (
+	// ScheduledEventsScope defines the scope interface for a scheduled events service.
+	ScheduledEventsScope interface {
+		IsReady() bool
+		ScheduledEventsEnabled() bool
+		GetNode(ctx context.Context) (*corev1.Node, error)
+		CordonAndDrainForScheduledEvent(ctx context.Context, eventType string) error
+		SetScheduledEventApproval(ctx context.Context, eventID string) error
+		Uncordon(ctx context.Context) error
+		IsConditionFalse(clusterv1.ConditionType) bool
+		SetConditionFalse(clusterv1.ConditionType, string, clusterv1.ConditionSeverity, string)
+		UpdatePutStatus(clusterv1.ConditionType, string, error)
+	}
+
+	// Service provides operations on the Azure scheduled events of a scale set instance.
+	Service struct {
+		Scope ScheduledEventsScope
+	}
+)
+
+// NewService creates a new service.
+func NewService(scope ScheduledEventsScope) *Service {
+	return &Service{
+		Scope: scope,
+	}
+}
+
+// Reconcile drains the node of the instance ahead of a pending scheduled event disrupting it, then approves the event so
+// that it starts without waiting for the rest of the notification window. The node is uncordoned again once the
+// instance is back from an event it survives, such as a reboot.
+//
+// Scheduled events are only served to the instance itself by the instance metadata service, so they are reported on the
+// node by the ScheduledEventNodeCondition of a handler running on it, which also approves them.
+func (s *Service) Reconcile(ctx context.Context) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "scheduledevents.Service.Reconcile")
+	defer done()
+
+	if !s.Scope.ScheduledEventsEnabled() || !s.Scope.IsReady() {
+		return nil
+	}
+
+	node, err := s.Scope.GetNode(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get node")
+	}
+	if node == nil {
+		return nil
+	}
+
+	eventType, eventID := pendingEvent(node)
+	if eventID == "" {
+		// the node is only cordoned when the condition is false
+		if s.Scope.IsConditionFalse(infrav1.ScheduledEventCondition) {
+			log.V(2).Info("scheduled event completed, uncordoning node")
+			if err := s.Scope.Uncordon(ctx); err != nil {
+				return err
+			}
+			if err := s.Scope.SetScheduledEventApproval(ctx, ""); err != nil {
+				return errors.Wrap(err, "failed to remove scheduled event approval")
+			}
+		}
+		s.Scope.UpdatePutStatus(infrav1.ScheduledEventCondition, serviceName, nil)
+		return nil
+	}
+
+	if node.Annotations[infrav1exp.ApprovedScheduledEventAnnotation] == eventID {
+		// the event was approved, wait for it to start and complete
+		return nil
+	}
+
+	log.V(2).Info("draining node ahead of scheduled event", "eventID", eventID, "eventType", eventType)
+	s.Scope.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventDrainingReason, clusterv1.ConditionSeverityWarning, fmt.Sprintf("draining node before %s event %s", eventType, eventID))
+	if err := s.Scope.CordonAndDrainForScheduledEvent(ctx, eventType); err != nil {
+		return err
+	}
+
+	if err := s.Scope.SetScheduledEventApproval(ctx, eventID); err != nil {
+		return errors.Wrap(err, "failed to approve scheduled event")
+	}
+
+	log.V(2).Info("approved scheduled event", "eventID", eventID, "eventType", eventType)
+	s.Scope.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventApprovedReason, clusterv1.ConditionSeverityInfo, fmt.Sprintf("approved %s event %s after draining the node", eventType, eventID))
+	return nil
+}
+
+// pendingEvent returns the type and ID of the scheduled event disrupting the instance reported on its node, or empty
+// strings if there is none.
+func pendingEvent(node *corev1.Node) (eventType, eventID string) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != infrav1exp.ScheduledEventNodeCondition || condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Reason {
+		case eventTypeTerminate, eventTypePreempt, eventTypeReboot, eventTypeRedeploy:
+			return condition.Reason, condition.Message
+		}
+	}
+	return "", ""
+}
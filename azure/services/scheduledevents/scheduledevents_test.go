@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledevents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scheduledevents/mock_scheduledevents"
+	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	gomock2 "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestService_Reconcile(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Setup func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder)
+		Err   string
+	}{
+		{
+			Name: "should do nothing if terminate notifications are disabled",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(false)
+			},
+		},
+		{
+			Name: "should do nothing if the instance is not ready",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(false)
+			},
+		},
+		{
+			Name: "should do nothing if the instance has no node",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(nil, nil)
+			},
+		},
+		{
+			Name: "should mark the condition true without pending events",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionFalse, "NoScheduledEvents", "", ""), nil)
+				s.IsConditionFalse(infrav1.ScheduledEventCondition).Return(false)
+				s.UpdatePutStatus(infrav1.ScheduledEventCondition, serviceName, nil)
+			},
+		},
+		{
+			Name: "should ignore event types which do not disrupt the instance",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionTrue, "Freeze", "event-3", ""), nil)
+				s.IsConditionFalse(infrav1.ScheduledEventCondition).Return(false)
+				s.UpdatePutStatus(infrav1.ScheduledEventCondition, serviceName, nil)
+			},
+		},
+		{
+			Name: "should drain the node and approve a pending event",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				gomock.InOrder(
+					s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionTrue, "Reboot", "event-1", ""), nil),
+					s.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventDrainingReason, clusterv1.ConditionSeverityWarning, gomock.Any()),
+					s.CordonAndDrainForScheduledEvent(gomock2.AContext(), "Reboot").Return(nil),
+					s.SetScheduledEventApproval(gomock2.AContext(), "event-1").Return(nil),
+					s.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventApprovedReason, clusterv1.ConditionSeverityInfo, gomock.Any()),
+				)
+			},
+		},
+		{
+			Name: "should not approve a pending event until the node is drained",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionTrue, "Preempt", "event-4", ""), nil)
+				s.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventDrainingReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+				s.CordonAndDrainForScheduledEvent(gomock2.AContext(), "Preempt").Return(errors.New("drain failed"))
+			},
+			Err: "drain failed",
+		},
+		{
+			Name: "should wait for an approved event to start",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionTrue, "Reboot", "event-1", "event-1"), nil)
+			},
+		},
+		{
+			Name: "should drain the node again for a new event",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionTrue, "Redeploy", "event-2", "event-1"), nil)
+				s.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventDrainingReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+				s.CordonAndDrainForScheduledEvent(gomock2.AContext(), "Redeploy").Return(nil)
+				s.SetScheduledEventApproval(gomock2.AContext(), "event-2").Return(nil)
+				s.SetConditionFalse(infrav1.ScheduledEventCondition, infrav1.ScheduledEventApprovedReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+			},
+		},
+		{
+			Name: "should uncordon the node after the event completed",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(newNode(corev1.ConditionFalse, "NoScheduledEvents", "", "event-1"), nil)
+				s.IsConditionFalse(infrav1.ScheduledEventCondition).Return(true)
+				s.Uncordon(gomock2.AContext()).Return(nil)
+				s.SetScheduledEventApproval(gomock2.AContext(), "").Return(nil)
+				s.UpdatePutStatus(infrav1.ScheduledEventCondition, serviceName, nil)
+			},
+		},
+		{
+			Name: "should return an error if the node cannot be fetched",
+			Setup: func(s *mock_scheduledevents.MockScheduledEventsScopeMockRecorder) {
+				s.ScheduledEventsEnabled().Return(true)
+				s.IsReady().Return(true)
+				s.GetNode(gomock2.AContext()).Return(nil, errors.New("connection refused"))
+			},
+			Err: "failed to get node: connection refused",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var (
+				g         = NewWithT(t)
+				mockCtrl  = gomock.NewController(t)
+				scopeMock = mock_scheduledevents.NewMockScheduledEventsScope(mockCtrl)
+			)
+			defer mockCtrl.Finish()
+
+			service := NewService(scopeMock)
+			c.Setup(scopeMock.EXPECT())
+
+			err := service.Reconcile(context.TODO())
+			if c.Err == "" {
+				g.Expect(err).To(Succeed())
+			} else {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(c.Err))
+			}
+		})
+	}
+}
+
+// newNode returns a node with the given ScheduledEventNodeCondition and approved scheduled event.
+func newNode(status corev1.ConditionStatus, reason, message, approved string) *corev1.Node {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-0",
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: infrav1exp.ScheduledEventNodeCondition, Status: status, Reason: reason, Message: message},
+			},
+		},
+	}
+	if approved != "" {
+		node.Annotations = map[string]string{infrav1exp.ApprovedScheduledEventAnnotation: approved}
+	}
+	return node
+}
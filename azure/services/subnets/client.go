@@ -46,6 +46,16 @@ func NewClient(auth azure.Authorizer) *AzureClient {
 	return &AzureClient{c}
 }
 
+// newSubscriptionClient creates a new subnets client for the subscription of the virtual network, which defaults to the
+// subscription of the cluster.
+func newSubscriptionClient(auth azure.CrossSubscriptionAuthorizer, subscriptionID string) *AzureClient {
+	if subscriptionID == "" {
+		subscriptionID = auth.SubscriptionID()
+	}
+	c := newSubnetsClient(subscriptionID, auth.BaseURI(), azure.AuthorizerForSubscription(auth, subscriptionID))
+	return &AzureClient{c}
+}
+
 // newSubnetsClient creates a new subnets client from subscription ID.
 func newSubnetsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) network.SubnetsClient {
 	subnetsClient := network.NewSubnetsClientWithBaseURI(baseURI, subscriptionID)
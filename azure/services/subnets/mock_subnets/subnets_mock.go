@@ -402,6 +402,20 @@ func (mr *MockSubnetScopeMockRecorder) ResourceGroup() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceGroup", reflect.TypeOf((*MockSubnetScope)(nil).ResourceGroup))
 }
 
+// SecondaryAuthorizer mocks base method.
+func (m *MockSubnetScope) SecondaryAuthorizer() autorest.Authorizer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecondaryAuthorizer")
+	ret0, _ := ret[0].(autorest.Authorizer)
+	return ret0
+}
+
+// SecondaryAuthorizer indicates an expected call of SecondaryAuthorizer.
+func (mr *MockSubnetScopeMockRecorder) SecondaryAuthorizer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecondaryAuthorizer", reflect.TypeOf((*MockSubnetScope)(nil).SecondaryAuthorizer))
+}
+
 // SetSubnet mocks base method.
 func (m *MockSubnetScope) SetSubnet(arg0 v1beta1.SubnetSpec) {
 	m.ctrl.T.Helper()
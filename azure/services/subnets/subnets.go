@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/pkg/errors"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -31,6 +32,7 @@ import (
 // SubnetScope defines the scope interface for a subnet service.
 type SubnetScope interface {
 	azure.ClusterScoper
+	SecondaryAuthorizer() autorest.Authorizer
 	SubnetSpecs() []azure.SubnetSpec
 }
 
@@ -44,7 +46,7 @@ type Service struct {
 func New(scope SubnetScope) *Service {
 	return &Service{
 		Scope:  scope,
-		Client: NewClient(scope),
+		Client: newSubscriptionClient(scope, scope.Vnet().SubscriptionID),
 	}
 }
 
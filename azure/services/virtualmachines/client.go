@@ -19,6 +19,8 @@ package virtualmachines
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
 	"github.com/Azure/go-autorest/autorest"
@@ -32,15 +34,40 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
 )
 
+// Client wraps go-sdk.
+type Client interface {
+	GetSerialConsoleLog(ctx context.Context, resourceGroupName, vmName string) ([]byte, error)
+	StartAsync(ctx context.Context, resourceGroupName, vmName string) (azureautorest.FutureAPI, error)
+	DeallocateAsync(ctx context.Context, resourceGroupName, vmName string) (azureautorest.FutureAPI, error)
+	ResizeAsync(ctx context.Context, resourceGroupName, vmName, vmSize string) (azureautorest.FutureAPI, error)
+	CreateOrUpdateRunCommandAsync(ctx context.Context, resourceGroupName, vmName, runCommandName string, runCommand compute.VirtualMachineRunCommand) (azureautorest.FutureAPI, error)
+	GetRunCommand(ctx context.Context, resourceGroupName, vmName, runCommandName string) (compute.VirtualMachineRunCommand, error)
+	ListUsages(ctx context.Context, location string) ([]compute.Usage, error)
+	IsDone(ctx context.Context, future azureautorest.FutureAPI) (bool, error)
+}
+
 // AzureClient contains the Azure go-sdk Client.
 type AzureClient struct {
 	virtualmachines compute.VirtualMachinesClient
+	runcommands     compute.VirtualMachineRunCommandsClient
+	usages          compute.UsageClient
 }
 
+var _ Client = &AzureClient{}
+
+const (
+	// serialConsoleLogSASExpirationMinutes is how long the SAS URI used to download the serial console log is valid for.
+	serialConsoleLogSASExpirationMinutes = 5
+	// serialConsoleLogTailBytes is how much of the end of the serial console log is kept when downloading it.
+	serialConsoleLogTailBytes = 128 * 1024
+)
+
 // NewClient creates a new VM client from subscription ID.
 func NewClient(auth azure.Authorizer) *AzureClient {
 	c := newVirtualMachinesClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
-	return &AzureClient{c}
+	rc := newRunCommandsClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	uc := newUsageClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+	return &AzureClient{c, rc, uc}
 }
 
 // newVirtualMachinesClient creates a new VM client from subscription ID.
@@ -50,12 +77,169 @@ func newVirtualMachinesClient(subscriptionID string, baseURI string, authorizer
 	return vmClient
 }
 
+// newRunCommandsClient creates a new run commands client from subscription ID.
+func newRunCommandsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.VirtualMachineRunCommandsClient {
+	runCommandsClient := compute.NewVirtualMachineRunCommandsClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&runCommandsClient.Client, authorizer)
+	return runCommandsClient
+}
+
+// newUsageClient creates a new compute usage client from subscription ID.
+func newUsageClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) compute.UsageClient {
+	usageClient := compute.NewUsageClientWithBaseURI(baseURI, subscriptionID)
+	azure.SetAutoRestClientDefaults(&usageClient.Client, authorizer)
+	return usageClient
+}
+
 // Get retrieves information about the model view or the instance view of a virtual machine.
 func (ac *AzureClient) Get(ctx context.Context, spec azure.ResourceSpecGetter) (result interface{}, err error) {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.Get")
 	defer done()
 
-	return ac.virtualmachines.Get(ctx, spec.ResourceGroupName(), spec.ResourceName(), "")
+	return ac.virtualmachines.Get(ctx, spec.ResourceGroupName(), spec.ResourceName(), compute.InstanceViewTypesInstanceView)
+}
+
+// StartAsync starts a deallocated virtual machine asynchronously. It returns a Future which can be used to track the
+// progress of the operation.
+func (ac *AzureClient) StartAsync(ctx context.Context, resourceGroupName, vmName string) (azureautorest.FutureAPI, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.StartAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.Start(ctx, resourceGroupName, vmName)
+	if err != nil {
+		return nil, err
+	}
+	return &future, nil
+}
+
+// DeallocateAsync stops and deallocates a virtual machine asynchronously. It returns a Future which can be used to
+// track the progress of the operation.
+func (ac *AzureClient) DeallocateAsync(ctx context.Context, resourceGroupName, vmName string) (azureautorest.FutureAPI, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.DeallocateAsync")
+	defer done()
+
+	future, err := ac.virtualmachines.Deallocate(ctx, resourceGroupName, vmName)
+	if err != nil {
+		return nil, err
+	}
+	return &future, nil
+}
+
+// ResizeAsync changes the size of a virtual machine asynchronously. It returns a Future which can be used to track the
+// progress of the operation.
+func (ac *AzureClient) ResizeAsync(ctx context.Context, resourceGroupName, vmName, vmSize string) (azureautorest.FutureAPI, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.ResizeAsync")
+	defer done()
+
+	update := compute.VirtualMachineUpdate{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{
+				VMSize: compute.VirtualMachineSizeTypes(vmSize),
+			},
+		},
+	}
+	future, err := ac.virtualmachines.Update(ctx, resourceGroupName, vmName, update)
+	if err != nil {
+		return nil, err
+	}
+	return &future, nil
+}
+
+// CreateOrUpdateRunCommandAsync creates or updates a managed run command of a virtual machine asynchronously, which
+// runs its script again. It returns a Future which can be used to track the progress of the operation.
+func (ac *AzureClient) CreateOrUpdateRunCommandAsync(ctx context.Context, resourceGroupName, vmName, runCommandName string, runCommand compute.VirtualMachineRunCommand) (azureautorest.FutureAPI, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.CreateOrUpdateRunCommandAsync")
+	defer done()
+
+	future, err := ac.runcommands.CreateOrUpdate(ctx, resourceGroupName, vmName, runCommandName, runCommand)
+	if err != nil {
+		return nil, err
+	}
+	return &future, nil
+}
+
+// GetRunCommand retrieves a managed run command of a virtual machine, along with the instance view holding the exit
+// code and the output of its last execution.
+func (ac *AzureClient) GetRunCommand(ctx context.Context, resourceGroupName, vmName, runCommandName string) (compute.VirtualMachineRunCommand, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.GetRunCommand")
+	defer done()
+
+	return ac.runcommands.GetByVirtualMachine(ctx, resourceGroupName, vmName, runCommandName, "instanceView")
+}
+
+// ListUsages returns the current compute resource usage of the subscription in a location, along with its limits.
+func (ac *AzureClient) ListUsages(ctx context.Context, location string) ([]compute.Usage, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.ListUsages")
+	defer done()
+
+	iter, err := ac.usages.ListComplete(ctx, location)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list compute usages")
+	}
+
+	var usages []compute.Usage
+	for iter.NotDone() {
+		usages = append(usages, iter.Value())
+		if err := iter.NextWithContext(ctx); err != nil {
+			return usages, errors.Wrap(err, "could not iterate compute usages")
+		}
+	}
+
+	return usages, nil
+}
+
+// GetSerialConsoleLog downloads the serial console log of a virtual machine from its boot diagnostics storage. Only
+// the last serialConsoleLogTailBytes of the log are kept, as the log of a long running VM can grow large.
+func (ac *AzureClient) GetSerialConsoleLog(ctx context.Context, resourceGroupName, vmName string) ([]byte, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.AzureClient.GetSerialConsoleLog")
+	defer done()
+
+	data, err := ac.virtualmachines.RetrieveBootDiagnosticsData(ctx, resourceGroupName, vmName, to.Int32Ptr(serialConsoleLogSASExpirationMinutes))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve boot diagnostics data")
+	}
+	if to.String(data.SerialConsoleLogBlobURI) == "" {
+		return nil, errors.New("boot diagnostics data does not include a serial console log")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, to.String(data.SerialConsoleLogBlobURI), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create serial console log request")
+	}
+	resp, err := ac.virtualmachines.Send(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download serial console log")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to download serial console log: unexpected status %s", resp.Status)
+	}
+
+	tail := &tailWriter{max: serialConsoleLogTailBytes}
+	if _, err := io.Copy(tail, resp.Body); err != nil {
+		return nil, errors.Wrap(err, "failed to download serial console log")
+	}
+	return tail.buf, nil
+}
+
+// tailWriter is an io.Writer keeping only the last max bytes written to it.
+type tailWriter struct {
+	buf []byte
+	max int
+}
+
+// Write implements io.Writer.
+func (w *tailWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= w.max {
+		w.buf = append(w.buf[:0], p[n-w.max:]...)
+		return n, nil
+	}
+	if over := len(w.buf) + n - w.max; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+	}
+	w.buf = append(w.buf, p...)
+	return n, nil
 }
 
 // CreateOrUpdateAsync creates or updates a virtual machine asynchronously.
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachines
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	. "github.com/onsi/gomega"
+)
+
+func TestGetSerialConsoleLog(t *testing.T) {
+	var b strings.Builder
+	for i := 0; b.Len() < 3*serialConsoleLogTailBytes; i++ {
+		fmt.Fprintf(&b, "[%8d] cloud-init: line %d\n", i, i)
+	}
+	serialLog := b.String()
+
+	testcases := []struct {
+		name     string
+		log      string
+		expected string
+	}{
+		{
+			name:     "short log is returned whole",
+			log:      "line 1\nline 2\n",
+			expected: "line 1\nline 2\n",
+		},
+		{
+			name:     "only the end of a long log is kept",
+			log:      serialLog,
+			expected: serialLog[len(serialLog)-serialConsoleLogTailBytes:],
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, "/retrieveBootDiagnosticsData") {
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprintf(w, `{"serialConsoleLogBlobUri": "%s/serial-console.log"}`, server.URL)
+					return
+				}
+				fmt.Fprint(w, tc.log)
+			}))
+			defer server.Close()
+
+			ac := &AzureClient{virtualmachines: newVirtualMachinesClient("123", server.URL, autorest.NullAuthorizer{})}
+			serialLog, err := ac.GetSerialConsoleLog(context.TODO(), "my-rg", "my-vm")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(serialLog)).To(Equal(tc.expected))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachines
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	cloudProviderConfigServiceName = "virtualmachinecloudproviderconfig"
+
+	// cloudProviderConfigRequeue is how long to wait before checking on the update of the cloud provider config again.
+	cloudProviderConfigRequeue = 30 * time.Second
+)
+
+// reconcileCloudProviderConfig writes the cloud provider config on a running virtual machine with a managed run command
+// when it changed since the virtual machine was created, e.g. because the credentials of the cluster were rotated.
+func (s *Service) reconcileCloudProviderConfig(ctx context.Context, spec *VMSpec, vm compute.VirtualMachine) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.reconcileCloudProviderConfig")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(spec.Name, cloudProviderConfigServiceName); future != nil {
+		sdkFuture, err := converters.FutureToSDK(*future)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(spec.Name, cloudProviderConfigServiceName)
+			return errors.Wrap(err, "failed to convert cloud provider config future")
+		}
+		isDone, err := s.client.IsDone(ctx, sdkFuture)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(spec.Name, cloudProviderConfigServiceName)
+			s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return errors.Wrap(err, "failed to update cloud provider config")
+		}
+		if !isDone {
+			return azure.WithTransientError(azure.NewOperationNotDoneError(future), cloudProviderConfigRequeue)
+		}
+		s.Scope.DeleteLongRunningOperationState(spec.Name, cloudProviderConfigServiceName)
+
+		// the operation is done once the script exited, which may have failed
+		runCommand, err := s.client.GetRunCommand(ctx, spec.ResourceGroup, spec.Name, azure.CloudProviderConfigRunCommandName)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the result of the cloud provider config update")
+		}
+		if err := converters.SDKRunCommandError(runCommand); err != nil {
+			s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return errors.Wrap(err, "failed to update cloud provider config")
+		}
+		log.V(2).Info("updated cloud provider config", "vm", spec.Name)
+		s.Scope.UpdatePutStatus(infrav1.CloudProviderConfigUpdatedCondition, cloudProviderConfigServiceName, nil)
+		return nil
+	}
+
+	// commands only run on running virtual machines, which get the current config once started again
+	if vm.VirtualMachineProperties == nil || (vm.InstanceView != nil && converters.IsDeallocated(vm.InstanceView.Statuses)) {
+		return nil
+	}
+
+	config, err := s.Scope.CloudProviderConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cloud provider config")
+	}
+	switch {
+	case config == nil:
+		return nil
+	case config.LastAppliedHash == "":
+		// the virtual machine was bootstrapped with the current config
+		s.Scope.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, config.Hash)
+		return nil
+	case config.LastAppliedHash == config.Hash && !s.Scope.IsConditionFalse(infrav1.CloudProviderConfigUpdatedCondition):
+		return nil
+	}
+
+	log.V(2).Info("updating cloud provider config", "vm", spec.Name)
+	s.Scope.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, config.Hash)
+	runCommand := converters.CloudProviderConfigToSDKRunCommand(*config, spec.Location, time.Now())
+	sdkFuture, err := s.client.CreateOrUpdateRunCommandAsync(ctx, spec.ResourceGroup, spec.Name, azure.CloudProviderConfigRunCommandName, runCommand)
+	if err != nil {
+		s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return errors.Wrap(err, "failed to update cloud provider config")
+	}
+	s.Scope.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdatingReason, clusterv1.ConditionSeverityInfo, "writing the cloud provider config on the virtual machine")
+
+	future, err := converters.SDKToFuture(sdkFuture, infrav1.PutFuture, cloudProviderConfigServiceName, spec.Name, spec.ResourceGroup)
+	if err != nil {
+		return err
+	}
+	s.Scope.SetLongRunningOperationState(future)
+	return azure.WithTransientError(azure.NewOperationNotDoneError(future), cloudProviderConfigRequeue)
+}
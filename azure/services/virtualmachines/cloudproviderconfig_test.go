@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines/mock_virtualmachines"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var fakeCloudProviderConfigFuture = infrav1.Future{
+	Type:          infrav1.PutFuture,
+	ServiceName:   cloudProviderConfigServiceName,
+	Name:          "test-vm",
+	ResourceGroup: "test-group",
+	Data:          "eyJtZXRob2QiOiJQT1NUIiwicG9sbGluZ01ldGhvZCI6IkxvY2F0aW9uIiwibHJvU3RhdGUiOiJJblByb2dyZXNzIn0=",
+}
+
+func fakeRunCommand(state compute.ExecutionState, exitCode int32, stderr string) compute.VirtualMachineRunCommand {
+	return compute.VirtualMachineRunCommand{
+		VirtualMachineRunCommandProperties: &compute.VirtualMachineRunCommandProperties{
+			InstanceView: &compute.VirtualMachineRunCommandInstanceView{
+				ExecutionState: state,
+				ExitCode:       to.Int32Ptr(exitCode),
+				Error:          to.StringPtr(stderr),
+			},
+		},
+	}
+}
+
+func TestReconcileCloudProviderConfig(t *testing.T) {
+	sdkFuture, err := converters.FutureToSDK(fakeCloudProviderConfigFuture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name          string
+		vm            compute.VirtualMachine
+		expectedError string
+		expect        func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name: "cloud provider config is not generated by capz",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(nil, nil)
+			},
+		},
+		{
+			name: "hash of the cloud provider config the vm was bootstrapped with is recorded",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", Script: "script"}, nil)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+			},
+		},
+		{
+			name: "cloud provider config is up to date",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "new", Script: "script"}, nil)
+				s.IsConditionFalse(infrav1.CloudProviderConfigUpdatedCondition).Return(false)
+			},
+		},
+		{
+			name:          "rotated cloud provider config is written on the vm",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expectedError: "operation type PUT on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "old", Script: "script"}, nil)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+				m.CreateOrUpdateRunCommandAsync(gomockinternal.AContext(), "test-group", "test-vm", azure.CloudProviderConfigRunCommandName, gomock.Any()).Return(sdkFuture, nil)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdatingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name:          "failed update of the cloud provider config is retried",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expectedError: "operation type PUT on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "new", Script: "script"}, nil)
+				s.IsConditionFalse(infrav1.CloudProviderConfigUpdatedCondition).Return(true)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+				m.CreateOrUpdateRunCommandAsync(gomockinternal.AContext(), "test-group", "test-vm", azure.CloudProviderConfigRunCommandName, gomock.Any()).Return(sdkFuture, nil)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdatingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name:          "running the command fails",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expectedError: "failed to update cloud provider config: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(&azure.CloudProviderConfig{Hash: "new", LastAppliedHash: "old", Script: "script"}, nil)
+				s.SetAnnotation(infrav1.CloudProviderConfigLastAppliedAnnotation, "new")
+				m.CreateOrUpdateRunCommandAsync(gomockinternal.AContext(), "test-group", "test-vm", azure.CloudProviderConfigRunCommandName, gomock.Any()).Return(nil, internalError)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name: "deallocated vm is not updated",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/deallocated"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "update of the cloud provider config is in progress",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expectedError: "operation type PUT on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(&fakeCloudProviderConfigFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(false, nil)
+			},
+		},
+		{
+			name: "update of the cloud provider config is done",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(&fakeCloudProviderConfigFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", cloudProviderConfigServiceName)
+				m.GetRunCommand(gomockinternal.AContext(), "test-group", "test-vm", azure.CloudProviderConfigRunCommandName).Return(fakeRunCommand(compute.ExecutionStateSucceeded, 0, ""), nil)
+				s.UpdatePutStatus(infrav1.CloudProviderConfigUpdatedCondition, cloudProviderConfigServiceName, nil)
+			},
+		},
+		{
+			name:          "script writing the cloud provider config exited with an error",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expectedError: "failed to update cloud provider config: run command failed with exit code 1: crictl: command failed",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(&fakeCloudProviderConfigFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", cloudProviderConfigServiceName)
+				m.GetRunCommand(gomockinternal.AContext(), "test-group", "test-vm", azure.CloudProviderConfigRunCommandName).Return(fakeRunCommand(compute.ExecutionStateFailed, 1, "crictl: command failed"), nil)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, "run command failed with exit code 1: crictl: command failed")
+			},
+		},
+		{
+			name:          "update of the cloud provider config failed",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expectedError: "failed to update cloud provider config: command failed",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(&fakeCloudProviderConfigFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(false, errors.New("command failed"))
+				s.DeleteLongRunningOperationState("test-vm", cloudProviderConfigServiceName)
+				s.SetConditionFalse(infrav1.CloudProviderConfigUpdatedCondition, infrav1.CloudProviderConfigUpdateFailedReason, clusterv1.ConditionSeverityError, "command failed")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			scopeMock := mock_virtualmachines.NewMockVMScope(mockCtrl)
+			clientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				client: clientMock,
+			}
+
+			spec := fakeVMSpec
+			err := s.reconcileCloudProviderConfig(context.TODO(), &spec, tc.vm)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
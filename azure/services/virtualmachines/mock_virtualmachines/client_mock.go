@@ -19,3 +19,155 @@ limitations under the License.
 
 // Package mock_virtualmachines is a generated GoMock package.
 package mock_virtualmachines
+
+import (
+	context "context"
+	reflect "reflect"
+
+	compute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	azure "github.com/Azure/go-autorest/autorest/azure"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// CreateOrUpdateRunCommandAsync mocks base method.
+func (m *MockClient) CreateOrUpdateRunCommandAsync(ctx context.Context, resourceGroupName, vmName, runCommandName string, runCommand compute.VirtualMachineRunCommand) (azure.FutureAPI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrUpdateRunCommandAsync", ctx, resourceGroupName, vmName, runCommandName, runCommand)
+	ret0, _ := ret[0].(azure.FutureAPI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrUpdateRunCommandAsync indicates an expected call of CreateOrUpdateRunCommandAsync.
+func (mr *MockClientMockRecorder) CreateOrUpdateRunCommandAsync(ctx, resourceGroupName, vmName, runCommandName, runCommand interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdateRunCommandAsync", reflect.TypeOf((*MockClient)(nil).CreateOrUpdateRunCommandAsync), ctx, resourceGroupName, vmName, runCommandName, runCommand)
+}
+
+// DeallocateAsync mocks base method.
+func (m *MockClient) DeallocateAsync(ctx context.Context, resourceGroupName, vmName string) (azure.FutureAPI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeallocateAsync", ctx, resourceGroupName, vmName)
+	ret0, _ := ret[0].(azure.FutureAPI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeallocateAsync indicates an expected call of DeallocateAsync.
+func (mr *MockClientMockRecorder) DeallocateAsync(ctx, resourceGroupName, vmName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeallocateAsync", reflect.TypeOf((*MockClient)(nil).DeallocateAsync), ctx, resourceGroupName, vmName)
+}
+
+// GetRunCommand mocks base method.
+func (m *MockClient) GetRunCommand(ctx context.Context, resourceGroupName, vmName, runCommandName string) (compute.VirtualMachineRunCommand, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunCommand", ctx, resourceGroupName, vmName, runCommandName)
+	ret0, _ := ret[0].(compute.VirtualMachineRunCommand)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunCommand indicates an expected call of GetRunCommand.
+func (mr *MockClientMockRecorder) GetRunCommand(ctx, resourceGroupName, vmName, runCommandName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunCommand", reflect.TypeOf((*MockClient)(nil).GetRunCommand), ctx, resourceGroupName, vmName, runCommandName)
+}
+
+// GetSerialConsoleLog mocks base method.
+func (m *MockClient) GetSerialConsoleLog(ctx context.Context, resourceGroupName, vmName string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSerialConsoleLog", ctx, resourceGroupName, vmName)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSerialConsoleLog indicates an expected call of GetSerialConsoleLog.
+func (mr *MockClientMockRecorder) GetSerialConsoleLog(ctx, resourceGroupName, vmName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSerialConsoleLog", reflect.TypeOf((*MockClient)(nil).GetSerialConsoleLog), ctx, resourceGroupName, vmName)
+}
+
+// IsDone mocks base method.
+func (m *MockClient) IsDone(ctx context.Context, future azure.FutureAPI) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDone", ctx, future)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDone indicates an expected call of IsDone.
+func (mr *MockClientMockRecorder) IsDone(ctx, future interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDone", reflect.TypeOf((*MockClient)(nil).IsDone), ctx, future)
+}
+
+// ListUsages mocks base method.
+func (m *MockClient) ListUsages(ctx context.Context, location string) ([]compute.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsages", ctx, location)
+	ret0, _ := ret[0].([]compute.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsages indicates an expected call of ListUsages.
+func (mr *MockClientMockRecorder) ListUsages(ctx, location interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsages", reflect.TypeOf((*MockClient)(nil).ListUsages), ctx, location)
+}
+
+// ResizeAsync mocks base method.
+func (m *MockClient) ResizeAsync(ctx context.Context, resourceGroupName, vmName, vmSize string) (azure.FutureAPI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResizeAsync", ctx, resourceGroupName, vmName, vmSize)
+	ret0, _ := ret[0].(azure.FutureAPI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResizeAsync indicates an expected call of ResizeAsync.
+func (mr *MockClientMockRecorder) ResizeAsync(ctx, resourceGroupName, vmName, vmSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResizeAsync", reflect.TypeOf((*MockClient)(nil).ResizeAsync), ctx, resourceGroupName, vmName, vmSize)
+}
+
+// StartAsync mocks base method.
+func (m *MockClient) StartAsync(ctx context.Context, resourceGroupName, vmName string) (azure.FutureAPI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartAsync", ctx, resourceGroupName, vmName)
+	ret0, _ := ret[0].(azure.FutureAPI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartAsync indicates an expected call of StartAsync.
+func (mr *MockClientMockRecorder) StartAsync(ctx, resourceGroupName, vmName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartAsync", reflect.TypeOf((*MockClient)(nil).StartAsync), ctx, resourceGroupName, vmName)
+}
@@ -21,6 +21,7 @@ limitations under the License.
 package mock_virtualmachines
 
 import (
+	context "context"
 	reflect "reflect"
 
 	autorest "github.com/Azure/go-autorest/autorest"
@@ -124,6 +125,35 @@ func (mr *MockVMScopeMockRecorder) CloudEnvironment() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudEnvironment", reflect.TypeOf((*MockVMScope)(nil).CloudEnvironment))
 }
 
+// CloudProviderConfig mocks base method.
+func (m *MockVMScope) CloudProviderConfig(arg0 context.Context) (*azure.CloudProviderConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloudProviderConfig", arg0)
+	ret0, _ := ret[0].(*azure.CloudProviderConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloudProviderConfig indicates an expected call of CloudProviderConfig.
+func (mr *MockVMScopeMockRecorder) CloudProviderConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloudProviderConfig", reflect.TypeOf((*MockVMScope)(nil).CloudProviderConfig), arg0)
+}
+
+// CordonAndDrain mocks base method.
+func (m *MockVMScope) CordonAndDrain(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CordonAndDrain", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CordonAndDrain indicates an expected call of CordonAndDrain.
+func (mr *MockVMScopeMockRecorder) CordonAndDrain(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CordonAndDrain", reflect.TypeOf((*MockVMScope)(nil).CordonAndDrain), arg0)
+}
+
 // DeleteLongRunningOperationState mocks base method.
 func (m *MockVMScope) DeleteLongRunningOperationState(arg0, arg1 string) {
 	m.ctrl.T.Helper()
@@ -164,6 +194,48 @@ func (mr *MockVMScopeMockRecorder) HashKey() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashKey", reflect.TypeOf((*MockVMScope)(nil).HashKey))
 }
 
+// InPlaceResizeAllowed mocks base method.
+func (m *MockVMScope) InPlaceResizeAllowed() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InPlaceResizeAllowed")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// InPlaceResizeAllowed indicates an expected call of InPlaceResizeAllowed.
+func (mr *MockVMScopeMockRecorder) InPlaceResizeAllowed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InPlaceResizeAllowed", reflect.TypeOf((*MockVMScope)(nil).InPlaceResizeAllowed))
+}
+
+// IsConditionFalse mocks base method.
+func (m *MockVMScope) IsConditionFalse(arg0 v1beta10.ConditionType) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsConditionFalse", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsConditionFalse indicates an expected call of IsConditionFalse.
+func (mr *MockVMScopeMockRecorder) IsConditionFalse(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsConditionFalse", reflect.TypeOf((*MockVMScope)(nil).IsConditionFalse), arg0)
+}
+
+// RequestRemediation mocks base method.
+func (m *MockVMScope) RequestRemediation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestRemediation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestRemediation indicates an expected call of RequestRemediation.
+func (mr *MockVMScopeMockRecorder) RequestRemediation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestRemediation", reflect.TypeOf((*MockVMScope)(nil).RequestRemediation), arg0, arg1)
+}
+
 // SetAddresses mocks base method.
 func (m *MockVMScope) SetAddresses(arg0 []v1.NodeAddress) {
 	m.ctrl.T.Helper()
@@ -188,6 +260,18 @@ func (mr *MockVMScopeMockRecorder) SetAnnotation(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAnnotation", reflect.TypeOf((*MockVMScope)(nil).SetAnnotation), arg0, arg1)
 }
 
+// SetConditionFalse mocks base method.
+func (m *MockVMScope) SetConditionFalse(arg0 v1beta10.ConditionType, arg1 string, arg2 v1beta10.ConditionSeverity, arg3 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetConditionFalse", arg0, arg1, arg2, arg3)
+}
+
+// SetConditionFalse indicates an expected call of SetConditionFalse.
+func (mr *MockVMScopeMockRecorder) SetConditionFalse(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetConditionFalse", reflect.TypeOf((*MockVMScope)(nil).SetConditionFalse), arg0, arg1, arg2, arg3)
+}
+
 // SetLongRunningOperationState mocks base method.
 func (m *MockVMScope) SetLongRunningOperationState(arg0 *v1beta1.Future) {
 	m.ctrl.T.Helper()
@@ -252,6 +336,20 @@ func (mr *MockVMScopeMockRecorder) TenantID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantID", reflect.TypeOf((*MockVMScope)(nil).TenantID))
 }
 
+// Uncordon mocks base method.
+func (m *MockVMScope) Uncordon(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Uncordon", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Uncordon indicates an expected call of Uncordon.
+func (mr *MockVMScopeMockRecorder) Uncordon(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Uncordon", reflect.TypeOf((*MockVMScope)(nil).Uncordon), arg0)
+}
+
 // UpdateDeleteStatus mocks base method.
 func (m *MockVMScope) UpdateDeleteStatus(arg0 v1beta10.ConditionType, arg1 string, arg2 error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,286 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachines
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/util/slice"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	resizeServiceName = "virtualmachineresize"
+
+	// resizeRequeue is how long to wait before checking on a step of an in-place resize again.
+	resizeRequeue = 15 * time.Second
+
+	// resizeQuotaRequeue is how long to wait before checking the vCPU quota for an in-place resize again.
+	resizeQuotaRequeue = 5 * time.Minute
+
+	// totalCoresUsage is the name of the compute usage of the total regional vCPUs of a subscription.
+	totalCoresUsage = "cores"
+
+	// lowPriorityCoresUsage is the name of the compute usage of the regional vCPUs of the spot VMs of a subscription.
+	lowPriorityCoresUsage = "lowPriorityCores"
+)
+
+// reconcileResize resizes a virtual machine in place when its size differs from the spec and the scope allows it.
+// The node is drained, then the VM is deallocated, resized and started again, one long-running operation at a time.
+func (s *Service) reconcileResize(ctx context.Context, spec *VMSpec, vm compute.VirtualMachine) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.reconcileResize")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(spec.Name, resizeServiceName); future != nil {
+		sdkFuture, err := converters.FutureToSDK(*future)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(spec.Name, resizeServiceName)
+			return errors.Wrap(err, "failed to convert resize future")
+		}
+		isDone, err := s.client.IsDone(ctx, sdkFuture)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(spec.Name, resizeServiceName)
+			s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return errors.Wrap(err, "failed to resize virtual machine")
+		}
+		if !isDone {
+			return azure.WithTransientError(azure.NewOperationNotDoneError(future), resizeRequeue)
+		}
+		// The VM was fetched before the operation completed, so move on to the next step on the next reconcile.
+		s.Scope.DeleteLongRunningOperationState(spec.Name, resizeServiceName)
+		return azure.WithTransientError(errors.Errorf("virtual machine resize operation %s completed", future.Type), resizeRequeue)
+	}
+
+	if vm.VirtualMachineProperties == nil || vm.HardwareProfile == nil {
+		return nil
+	}
+	deallocated := vm.InstanceView != nil && converters.IsDeallocated(vm.InstanceView.Statuses)
+
+	switch {
+	case string(vm.HardwareProfile.VMSize) != spec.Size:
+		if !s.Scope.InPlaceResizeAllowed() {
+			return nil
+		}
+		if err := s.validateResize(ctx, spec, vm); err != nil {
+			s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, err.Error())
+			return err
+		}
+
+		if !deallocated {
+			log.V(2).Info("draining node before resizing virtual machine", "from", vm.HardwareProfile.VMSize, "to", spec.Size)
+			s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeDrainingReason, clusterv1.ConditionSeverityInfo, fmt.Sprintf("draining node before resizing to %s", spec.Size))
+			if err := s.Scope.CordonAndDrain(ctx); err != nil {
+				return err
+			}
+
+			s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeDeallocatingReason, clusterv1.ConditionSeverityInfo, fmt.Sprintf("deallocating before resizing to %s", spec.Size))
+			future, err := s.client.DeallocateAsync(ctx, spec.ResourceGroup, spec.Name)
+			return s.trackResizeOperation(spec, future, infrav1.PostFuture, err)
+		}
+
+		log.V(2).Info("resizing virtual machine", "from", vm.HardwareProfile.VMSize, "to", spec.Size)
+		s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizingReason, clusterv1.ConditionSeverityInfo, fmt.Sprintf("resizing to %s", spec.Size))
+		future, err := s.client.ResizeAsync(ctx, spec.ResourceGroup, spec.Name, spec.Size)
+		return s.trackResizeOperation(spec, future, infrav1.PatchFuture, err)
+
+	case s.Scope.IsConditionFalse(infrav1.VMResizedCondition):
+		if deallocated {
+			s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeStartingReason, clusterv1.ConditionSeverityInfo, fmt.Sprintf("starting after resizing to %s", spec.Size))
+			future, err := s.client.StartAsync(ctx, spec.ResourceGroup, spec.Name)
+			return s.trackResizeOperation(spec, future, infrav1.PostFuture, err)
+		}
+
+		if err := s.Scope.Uncordon(ctx); err != nil {
+			return err
+		}
+		log.V(2).Info("resized virtual machine", "size", spec.Size)
+		s.Scope.UpdatePutStatus(infrav1.VMResizedCondition, resizeServiceName, nil)
+	}
+
+	return nil
+}
+
+// validateResize checks that the virtual machine can run with the new size where it runs, before it is drained: the
+// new size must be available in its location and zone, support the features the virtual machine uses, and fit in the
+// vCPU quota of the subscription.
+func (s *Service) validateResize(ctx context.Context, spec *VMSpec, vm compute.VirtualMachine) error {
+	sku, err := s.resourceSKUCache.Get(ctx, spec.Size, resourceskus.VirtualMachines)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SKU %s", spec.Size)
+	}
+	currentSize := string(vm.HardwareProfile.VMSize)
+	currentSKU, err := s.resourceSKUCache.Get(ctx, currentSize, resourceskus.VirtualMachines)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SKU %s", currentSize)
+	}
+
+	if isRestrictedInLocation(sku, spec.Location) {
+		return azure.WithTerminalError(errors.Errorf("VM size %s is not available in location %s", spec.Size, spec.Location))
+	}
+	if spec.Zone != "" {
+		zones, err := s.resourceSKUCache.GetZonesWithVMSize(ctx, spec.Size, spec.Location)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get zones for VM size %s", spec.Size)
+		}
+		if !slice.Contains(zones, spec.Zone) {
+			return azure.WithTerminalError(errors.Errorf("VM size %s is not available in zone %s of location %s", spec.Size, spec.Zone, spec.Location))
+		}
+	}
+
+	// network interfaces without an explicit setting got the capability of the size the virtual machine was created with
+	acceleratedNetworking := to.Bool(spec.AcceleratedNetworking) || (spec.AcceleratedNetworking == nil && currentSKU.HasCapability(resourceskus.AcceleratedNetworking))
+	if acceleratedNetworking && !sku.HasCapability(resourceskus.AcceleratedNetworking) {
+		return azure.WithTerminalError(errors.Errorf("VM size %s does not support accelerated networking", spec.Size))
+	}
+	if usesPremiumStorage(spec) && !sku.HasCapability(resourceskus.PremiumIO) {
+		return azure.WithTerminalError(errors.Errorf("VM size %s does not support premium storage", spec.Size))
+	}
+	if spec.OSDisk.DiffDiskSettings != nil && !sku.HasCapability(resourceskus.EphemeralOSDisk) {
+		return azure.WithTerminalError(errors.Errorf("VM size %s does not support ephemeral os", spec.Size))
+	}
+	if vm.InstanceView != nil && vm.InstanceView.HyperVGeneration != "" {
+		// sizes which do not list their hypervisor generations only support the first one
+		generations, ok := sku.GetCapability(resourceskus.HyperVGenerations)
+		if !ok {
+			generations = string(compute.HyperVGenerationTypeV1)
+		}
+		if !slice.Contains(strings.Split(generations, ","), string(vm.InstanceView.HyperVGeneration)) {
+			return azure.WithTerminalError(errors.Errorf("VM size %s does not support hypervisor generation %s of the image", spec.Size, vm.InstanceView.HyperVGeneration))
+		}
+	}
+
+	return s.validateResizeQuota(ctx, spec, sku, currentSKU, vm)
+}
+
+// validateResizeQuota checks that the subscription has enough vCPU quota left for the new size. The vCPUs of the
+// current size are released when the virtual machine is deallocated, so they count as available.
+func (s *Service) validateResizeQuota(ctx context.Context, spec *VMSpec, sku, currentSKU resourceskus.SKU, vm compute.VirtualMachine) error {
+	vCPUs, err := skuVCPUs(sku)
+	if err != nil {
+		return err
+	}
+	var currentVCPUs int64
+	if vm.InstanceView == nil || !converters.IsDeallocated(vm.InstanceView.Statuses) {
+		if currentVCPUs, err = skuVCPUs(currentSKU); err != nil {
+			return err
+		}
+	}
+
+	// spot VMs only count against the low priority quota of the region
+	quotas := map[string]int64{totalCoresUsage: currentVCPUs, to.String(sku.Family): 0}
+	if to.String(sku.Family) == to.String(currentSKU.Family) {
+		quotas[to.String(sku.Family)] = currentVCPUs
+	}
+	if spec.SpotVMOptions != nil {
+		quotas = map[string]int64{lowPriorityCoresUsage: currentVCPUs}
+	}
+
+	usages, err := s.client.ListUsages(ctx, spec.Location)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the vCPU quota of location %s", spec.Location)
+	}
+	for _, usage := range usages {
+		if usage.Name == nil || usage.Limit == nil || usage.CurrentValue == nil {
+			continue
+		}
+		released, ok := quotas[to.String(usage.Name.Value)]
+		if !ok {
+			continue
+		}
+		if available := *usage.Limit - int64(*usage.CurrentValue) + released; available < vCPUs {
+			// quota is freed or raised outside of the cluster, so check again later
+			return azure.WithTransientError(errors.Errorf("VM size %s needs %d vCPUs but only %d are available in quota %s of location %s", spec.Size, vCPUs, available, to.String(usage.Name.Value), spec.Location), resizeQuotaRequeue)
+		}
+	}
+	return nil
+}
+
+// isRestrictedInLocation returns whether a SKU cannot be deployed in a location by the subscription.
+func isRestrictedInLocation(sku resourceskus.SKU, location string) bool {
+	if sku.Restrictions == nil {
+		return false
+	}
+	for _, restriction := range *sku.Restrictions {
+		if restriction.Type != compute.ResourceSkuRestrictionsTypeLocation || restriction.Values == nil {
+			continue
+		}
+		for _, value := range *restriction.Values {
+			if strings.EqualFold(value, location) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// usesPremiumStorage returns whether the OS disk or a data disk of a virtual machine is on premium storage.
+func usesPremiumStorage(spec *VMSpec) bool {
+	isPremium := func(disk *infrav1.ManagedDiskParameters) bool {
+		return disk != nil && (disk.StorageAccountType == string(compute.StorageAccountTypesPremiumLRS) || disk.StorageAccountType == string(compute.StorageAccountTypesPremiumZRS))
+	}
+	if isPremium(spec.OSDisk.ManagedDisk) {
+		return true
+	}
+	for _, disk := range spec.DataDisks {
+		if isPremium(disk.ManagedDisk) {
+			return true
+		}
+	}
+	return false
+}
+
+// skuVCPUs returns the number of vCPUs of a VM size.
+func skuVCPUs(sku resourceskus.SKU) (int64, error) {
+	value, ok := sku.GetCapability(resourceskus.VCPUs)
+	if !ok {
+		return 0, errors.Errorf("VM size %s does not report its vCPUs", to.String(sku.Name))
+	}
+	vCPUs, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse the vCPUs of VM size %s", to.String(sku.Name))
+	}
+	return vCPUs, nil
+}
+
+// trackResizeOperation stores the future of a step of an in-place resize so it can be checked on the next reconciles.
+func (s *Service) trackResizeOperation(spec *VMSpec, sdkFuture azureautorest.FutureAPI, futureType string, err error) error {
+	if err != nil {
+		s.Scope.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, err.Error())
+		return errors.Wrap(err, "failed to resize virtual machine")
+	}
+
+	future, err := converters.SDKToFuture(sdkFuture, futureType, resizeServiceName, spec.Name, spec.ResourceGroup)
+	if err != nil {
+		return err
+	}
+	s.Scope.SetLongRunningOperationState(future)
+	return azure.WithTransientError(azure.NewOperationNotDoneError(future), resizeRequeue)
+}
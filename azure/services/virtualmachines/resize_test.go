@@ -0,0 +1,430 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualmachines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines/mock_virtualmachines"
+	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var fakeResizeFuture = infrav1.Future{
+	Type:          infrav1.PostFuture,
+	ServiceName:   resizeServiceName,
+	Name:          "test-vm",
+	ResourceGroup: "test-group",
+	Data:          "eyJtZXRob2QiOiJQT1NUIiwicG9sbGluZ01ldGhvZCI6IkxvY2F0aW9uIiwibHJvU3RhdGUiOiJJblByb2dyZXNzIn0=",
+}
+
+func fakeResizeVM(size, powerState string) compute.VirtualMachine {
+	return compute.VirtualMachine{
+		Name: to.StringPtr("test-vm"),
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{VMSize: compute.VirtualMachineSizeTypes(size)},
+			InstanceView: &compute.VirtualMachineInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ProvisioningState/succeeded")},
+					{Code: to.StringPtr(powerState)},
+				},
+			},
+		},
+	}
+}
+
+func TestReconcileResize(t *testing.T) {
+	sdkFuture, err := converters.FutureToSDK(fakeResizeFuture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name          string
+		vm            compute.VirtualMachine
+		zone          string
+		spec          func(spec *VMSpec)
+		expectedError string
+		terminal      bool
+		expect        func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name: "vm size is up to date",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.IsConditionFalse(infrav1.VMResizedCondition).Return(false)
+			},
+		},
+		{
+			name: "vm size changed but in-place resize is not allowed",
+			vm:   fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(false)
+			},
+		},
+		{
+			name:          "running vm is drained and deallocated",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(10, 2), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeDrainingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.CordonAndDrain(gomockinternal.AContext()).Return(nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeDeallocatingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.DeallocateAsync(gomockinternal.AContext(), "test-group", "test-vm").Return(sdkFuture, nil)
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name:          "draining the node is not done",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			expectedError: "Drain failed, retry in 20s",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(10, 2), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeDrainingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				s.CordonAndDrain(gomockinternal.AContext()).Return(errors.New("Drain failed, retry in 20s"))
+			},
+		},
+		{
+			name:          "new vm size is not available in the zone of the vm",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			zone:          "3",
+			expectedError: "VM size Standard_Fake_Size is not available in zone 3 of location test-location",
+			terminal:      true,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name:          "new vm size is not available in the location of a regional vm",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			spec:          func(spec *VMSpec) { spec.Size = "Standard_Restricted_Size" },
+			expectedError: "VM size Standard_Restricted_Size is not available in location test-location",
+			terminal:      true,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name:          "new vm size does not support the accelerated networking of the current size",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			spec:          func(spec *VMSpec) { spec.Size = "Standard_Basic_Size" },
+			expectedError: "VM size Standard_Basic_Size does not support accelerated networking",
+			terminal:      true,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name: "new vm size does not support premium storage",
+			vm:   fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			spec: func(spec *VMSpec) {
+				spec.Size = "Standard_Basic_Size"
+				spec.AcceleratedNetworking = to.BoolPtr(false)
+				spec.OSDisk.ManagedDisk = &infrav1.ManagedDiskParameters{StorageAccountType: string(compute.StorageAccountTypesPremiumLRS)}
+			},
+			expectedError: "VM size Standard_Basic_Size does not support premium storage",
+			terminal:      true,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name: "new vm size does not support ephemeral os",
+			vm:   fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			spec: func(spec *VMSpec) {
+				spec.Size = "Standard_Basic_Size"
+				spec.AcceleratedNetworking = to.BoolPtr(false)
+				spec.OSDisk.DiffDiskSettings = &infrav1.DiffDiskSettings{Option: string(compute.DiffDiskOptionsLocal)}
+			},
+			expectedError: "VM size Standard_Basic_Size does not support ephemeral os",
+			terminal:      true,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name: "new vm size does not support the hypervisor generation of the image",
+			vm: func() compute.VirtualMachine {
+				vm := fakeResizeVM("Standard_Old_Size", "PowerState/running")
+				vm.InstanceView.HyperVGeneration = compute.HyperVGenerationTypeV2
+				return vm
+			}(),
+			spec: func(spec *VMSpec) {
+				spec.Size = "Standard_Basic_Size"
+				spec.AcceleratedNetworking = to.BoolPtr(false)
+			},
+			expectedError: "VM size Standard_Basic_Size does not support hypervisor generation V2 of the image",
+			terminal:      true,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name:          "new vm size does not fit in the vcpu quota",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			expectedError: "VM size Standard_Fake_Size needs 4 vCPUs but only 3 are available in quota standardFakeFamily of location test-location",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(5, 4), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name:          "the vcpus of a deallocated vm are already released from the quota",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/deallocated"),
+			expectedError: "VM size Standard_Fake_Size needs 4 vCPUs but only 1 are available in quota standardFakeFamily of location test-location",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(5, 4), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name:          "new size of a spot vm only needs the low priority vcpu quota",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/deallocated"),
+			spec:          func(spec *VMSpec) { spec.SpotVMOptions = &infrav1.SpotVMOptions{} },
+			expectedError: "operation type PATCH on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(5, 4), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.ResizeAsync(gomockinternal.AContext(), "test-group", "test-vm", "Standard_Fake_Size").Return(sdkFuture, nil)
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name:          "deallocated vm is resized",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/deallocated"),
+			zone:          "1",
+			expectedError: "operation type PATCH on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(10, 2), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.ResizeAsync(gomockinternal.AContext(), "test-group", "test-vm", "Standard_Fake_Size").Return(sdkFuture, nil)
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name:          "resizing the vm fails",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/deallocated"),
+			expectedError: "failed to resize virtual machine: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.InPlaceResizeAllowed().Return(true)
+				m.ListUsages(gomockinternal.AContext(), "test-location").Return(fakeResizeUsages(10, 2), nil)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.ResizeAsync(gomockinternal.AContext(), "test-group", "test-vm", "Standard_Fake_Size").Return(nil, internalError)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, gomock.Any())
+			},
+		},
+		{
+			name:          "resized vm is started",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/deallocated"),
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.IsConditionFalse(infrav1.VMResizedCondition).Return(true)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeStartingReason, clusterv1.ConditionSeverityInfo, gomock.Any())
+				m.StartAsync(gomockinternal.AContext(), "test-group", "test-vm").Return(sdkFuture, nil)
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name: "resized vm is running and the node is uncordoned",
+			vm:   fakeResizeVM("Standard_Fake_Size", "PowerState/running"),
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.IsConditionFalse(infrav1.VMResizedCondition).Return(true)
+				s.Uncordon(gomockinternal.AContext()).Return(nil)
+				s.UpdatePutStatus(infrav1.VMResizedCondition, resizeServiceName, nil)
+			},
+		},
+		{
+			name:          "resize operation is in progress",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(&fakeResizeFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(false, nil)
+			},
+		},
+		{
+			name:          "resize operation is done",
+			vm:            fakeResizeVM("Standard_Old_Size", "PowerState/running"),
+			expectedError: "virtual machine resize operation POST completed",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(&fakeResizeFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", resizeServiceName)
+			},
+		},
+		{
+			name:          "resize operation failed",
+			vm:            fakeResizeVM("Standard_Fake_Size", "PowerState/deallocated"),
+			expectedError: "failed to resize virtual machine: allocation failed",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(&fakeResizeFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(false, errors.New("allocation failed"))
+				s.DeleteLongRunningOperationState("test-vm", resizeServiceName)
+				s.SetConditionFalse(infrav1.VMResizedCondition, infrav1.VMResizeFailedReason, clusterv1.ConditionSeverityError, "allocation failed")
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			scopeMock := mock_virtualmachines.NewMockVMScope(mockCtrl)
+			clientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:            scopeMock,
+				client:           clientMock,
+				resourceSKUCache: resourceskus.NewStaticCache(fakeResizeSKUs(), "test-location"),
+			}
+
+			spec := fakeVMSpec
+			spec.Zone = tc.zone
+			if tc.spec != nil {
+				tc.spec(&spec)
+			}
+			err := s.reconcileResize(context.TODO(), &spec, tc.vm)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+				var recErr azure.ReconcileError
+				g.Expect(errors.As(err, &recErr) && recErr.IsTerminal()).To(Equal(tc.terminal))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func fakeResizeSKUs() []compute.ResourceSku {
+	locationInfo := &[]compute.ResourceSkuLocationInfo{
+		{
+			Location: to.StringPtr("test-location"),
+			Zones:    &[]string{"1", "2"},
+		},
+	}
+	return []compute.ResourceSku{
+		{
+			Name:         to.StringPtr("Standard_Fake_Size"),
+			ResourceType: to.StringPtr(string(resourceskus.VirtualMachines)),
+			Family:       to.StringPtr("standardFakeFamily"),
+			LocationInfo: locationInfo,
+			Capabilities: &[]compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(resourceskus.VCPUs), Value: to.StringPtr("4")},
+				{Name: to.StringPtr(resourceskus.AcceleratedNetworking), Value: to.StringPtr(string(resourceskus.CapabilitySupported))},
+				{Name: to.StringPtr(resourceskus.PremiumIO), Value: to.StringPtr(string(resourceskus.CapabilitySupported))},
+				{Name: to.StringPtr(resourceskus.EphemeralOSDisk), Value: to.StringPtr(string(resourceskus.CapabilitySupported))},
+				{Name: to.StringPtr(resourceskus.HyperVGenerations), Value: to.StringPtr("V1,V2")},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_Old_Size"),
+			ResourceType: to.StringPtr(string(resourceskus.VirtualMachines)),
+			Family:       to.StringPtr("standardFakeFamily"),
+			LocationInfo: locationInfo,
+			Capabilities: &[]compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(resourceskus.VCPUs), Value: to.StringPtr("2")},
+				{Name: to.StringPtr(resourceskus.AcceleratedNetworking), Value: to.StringPtr(string(resourceskus.CapabilitySupported))},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_Basic_Size"),
+			ResourceType: to.StringPtr(string(resourceskus.VirtualMachines)),
+			Family:       to.StringPtr("basicFakeFamily"),
+			LocationInfo: locationInfo,
+			Capabilities: &[]compute.ResourceSkuCapabilities{
+				{Name: to.StringPtr(resourceskus.VCPUs), Value: to.StringPtr("2")},
+			},
+		},
+		{
+			Name:         to.StringPtr("Standard_Restricted_Size"),
+			ResourceType: to.StringPtr(string(resourceskus.VirtualMachines)),
+			Family:       to.StringPtr("standardFakeFamily"),
+			LocationInfo: locationInfo,
+			Restrictions: &[]compute.ResourceSkuRestrictions{
+				{
+					Type:   compute.ResourceSkuRestrictionsTypeLocation,
+					Values: &[]string{"test-location"},
+				},
+			},
+		},
+	}
+}
+
+// fakeResizeUsages returns the compute usages of a subscription whose standardFakeFamily quota has the given limit
+// and current value, with plenty of total and low priority vCPUs left.
+func fakeResizeUsages(familyLimit int64, familyCurrent int32) []compute.Usage {
+	usage := func(name string, limit int64, current int32) compute.Usage {
+		return compute.Usage{
+			Name:         &compute.UsageName{Value: to.StringPtr(name)},
+			Limit:        to.Int64Ptr(limit),
+			CurrentValue: to.Int32Ptr(current),
+		}
+	}
+	return []compute.Usage{
+		usage(totalCoresUsage, 100, 10),
+		usage(lowPriorityCoresUsage, 100, 0),
+		usage("standardFakeFamily", familyLimit, familyCurrent),
+	}
+}
@@ -40,6 +40,8 @@ type VMSpec struct {
 	ClusterName            string
 	Role                   string
 	NICIDs                 []string
+	PrimaryNICID           string
+	AcceleratedNetworking  *bool
 	SSHKeyData             string
 	Size                   string
 	AvailabilitySetID      string
@@ -50,6 +52,7 @@ type VMSpec struct {
 	UserAssignedIdentities []infrav1.UserAssignedIdentity
 	SpotVMOptions          *infrav1.SpotVMOptions
 	SecurityProfile        *infrav1.SecurityProfile
+	Diagnostics            *infrav1.Diagnostics
 	AdditionalTags         infrav1.Tags
 	SKU                    resourceskus.SKU
 	Image                  *infrav1.Image
@@ -134,14 +137,10 @@ func (s *VMSpec) Parameters(existing interface{}) (params interface{}, err error
 			NetworkProfile: &compute.NetworkProfile{
 				NetworkInterfaces: s.generateNICRefs(),
 			},
-			Priority:       priority,
-			EvictionPolicy: evictionPolicy,
-			BillingProfile: billingProfile,
-			DiagnosticsProfile: &compute.DiagnosticsProfile{
-				BootDiagnostics: &compute.BootDiagnostics{
-					Enabled: to.BoolPtr(true),
-				},
-			},
+			Priority:           priority,
+			EvictionPolicy:     evictionPolicy,
+			BillingProfile:     billingProfile,
+			DiagnosticsProfile: converters.GetDiagnosticsProfile(s.Diagnostics),
 		},
 		Identity: identity,
 		Zones:    s.getZones(),
@@ -295,7 +294,7 @@ func (s *VMSpec) generateSecurityProfile() (*compute.SecurityProfile, error) {
 func (s *VMSpec) generateNICRefs() *[]compute.NetworkInterfaceReference {
 	nicRefs := make([]compute.NetworkInterfaceReference, len(s.NICIDs))
 	for i, id := range s.NICIDs {
-		primary := i == 0
+		primary := id == s.PrimaryNICID || (s.PrimaryNICID == "" && i == 0)
 		nicRefs[i] = compute.NetworkInterfaceReference{
 			ID: to.StringPtr(id),
 			NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{
@@ -245,6 +245,35 @@ func TestParameters(t *testing.T) {
 			},
 			expectedError: "",
 		},
+		{
+			name: "can create a vm with multiple network interfaces",
+			spec: &VMSpec{
+				Name:         "my-vm",
+				Role:         infrav1.Node,
+				NICIDs:       []string{"my-storage-nic", "my-nic"},
+				PrimaryNICID: "my-nic",
+				SSHKeyData:   "fakesshpublickey",
+				Size:         "Standard_D2v3",
+				Zone:         "1",
+				Image:        &infrav1.Image{ID: to.StringPtr("fake-image-id")},
+				SKU:          validSKU,
+			},
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(compute.VirtualMachine{}))
+				g.Expect(*result.(compute.VirtualMachine).NetworkProfile.NetworkInterfaces).To(Equal([]compute.NetworkInterfaceReference{
+					{
+						ID:                                  to.StringPtr("my-storage-nic"),
+						NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(false)},
+					},
+					{
+						ID:                                  to.StringPtr("my-nic"),
+						NetworkInterfaceReferenceProperties: &compute.NetworkInterfaceReferenceProperties{Primary: to.BoolPtr(true)},
+					},
+				}))
+			},
+			expectedError: "",
+		},
 		{
 			name: "can create a spot vm",
 			spec: &VMSpec{
@@ -19,6 +19,7 @@ package virtualmachines
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
@@ -32,11 +33,19 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/publicips"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
-const serviceName = "virtualmachine"
+const (
+	serviceName              = "virtualmachine"
+	spotVMRestartServiceName = "spotvmrestart"
+
+	// spotVMRestartRequeue is how long to wait before checking on a deallocated spot VM that is being restarted.
+	spotVMRestartRequeue = 2 * time.Minute
+)
 
 // VMScope defines the scope interface for a virtual machines service.
 type VMScope interface {
@@ -47,6 +56,13 @@ type VMScope interface {
 	SetProviderID(string)
 	SetAddresses([]corev1.NodeAddress)
 	SetVMState(infrav1.ProvisioningState)
+	SetConditionFalse(clusterv1.ConditionType, string, clusterv1.ConditionSeverity, string)
+	IsConditionFalse(clusterv1.ConditionType) bool
+	InPlaceResizeAllowed() bool
+	CordonAndDrain(context.Context) error
+	Uncordon(context.Context) error
+	CloudProviderConfig(context.Context) (*azure.CloudProviderConfig, error)
+	RequestRemediation(context.Context, string) error
 }
 
 // Service provides operations on Azure resources.
@@ -55,15 +71,19 @@ type Service struct {
 	async.Reconciler
 	interfacesGetter async.Getter
 	publicIPsClient  publicips.Client
+	client           Client
+	resourceSKUCache *resourceskus.Cache
 }
 
 // New creates a new service.
-func New(scope VMScope) *Service {
+func New(scope VMScope, skuCache *resourceskus.Cache) *Service {
 	Client := NewClient(scope)
 	return &Service{
 		Scope:            scope,
 		interfacesGetter: networkinterfaces.NewClient(scope),
 		publicIPsClient:  publicips.NewClient(scope),
+		client:           Client,
+		resourceSKUCache: skuCache,
 		Reconciler:       async.New(scope, Client, Client),
 	}
 }
@@ -101,10 +121,72 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		}
 		s.Scope.SetAddresses(addresses)
 		s.Scope.SetVMState(infraVM.State)
+
+		if spec, ok := vmSpec.(*VMSpec); ok {
+			if err := s.reconcileResize(ctx, spec, vm); err != nil {
+				return err
+			}
+			if err := s.reconcileSpotEviction(ctx, spec, vm); err != nil {
+				return err
+			}
+			return s.reconcileCloudProviderConfig(ctx, spec, vm)
+		}
 	}
 	return err
 }
 
+// reconcileSpotEviction handles a spot VM that was deallocated by an eviction according to its recovery policy.
+func (s *Service) reconcileSpotEviction(ctx context.Context, spec *VMSpec, vm compute.VirtualMachine) error {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.reconcileSpotEviction")
+	defer done()
+
+	if future := s.Scope.GetLongRunningOperationState(spec.Name, spotVMRestartServiceName); future != nil {
+		sdkFuture, err := converters.FutureToSDK(*future)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(spec.Name, spotVMRestartServiceName)
+			return errors.Wrap(err, "failed to convert spot VM restart future")
+		}
+		isDone, err := s.client.IsDone(ctx, sdkFuture)
+		if err != nil {
+			s.Scope.DeleteLongRunningOperationState(spec.Name, spotVMRestartServiceName)
+			return azure.WithTransientError(errors.Wrap(err, "failed to restart spot VM deallocated by an eviction"), spotVMRestartRequeue)
+		}
+		if !isDone {
+			return azure.WithTransientError(azure.NewOperationNotDoneError(future), spotVMRestartRequeue)
+		}
+		// The VM was fetched before the restart completed, so check its power state again on the next reconcile.
+		s.Scope.DeleteLongRunningOperationState(spec.Name, spotVMRestartServiceName)
+		return azure.WithTransientError(errors.New("spot VM deallocated by an eviction was restarted"), spotVMRestartRequeue)
+	}
+
+	spotVMOptions := spec.SpotVMOptions
+	if spotVMOptions == nil || (spotVMOptions.EvictionPolicy != nil && *spotVMOptions.EvictionPolicy != infrav1.SpotEvictionPolicyDeallocate) {
+		return nil
+	}
+	if vm.VirtualMachineProperties == nil || vm.InstanceView == nil || !converters.IsDeallocated(vm.InstanceView.Statuses) {
+		return nil
+	}
+
+	if spotVMOptions.RecoveryPolicy == nil || *spotVMOptions.RecoveryPolicy == infrav1.SpotRecoveryPolicyRemediate {
+		log.V(2).Info("spot VM was deallocated by an eviction", "vm", to.String(vm.Name))
+		s.Scope.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityError, "spot VM was deallocated by an eviction")
+		return s.Scope.RequestRemediation(ctx, "spot VM was deallocated by an eviction")
+	}
+
+	log.V(2).Info("restarting spot VM deallocated by an eviction", "vm", to.String(vm.Name))
+	s.Scope.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, "restarting spot VM deallocated by an eviction")
+	sdkFuture, err := s.client.StartAsync(ctx, spec.ResourceGroupName(), spec.Name)
+	if err != nil {
+		return azure.WithTransientError(errors.Wrap(err, "failed to restart spot VM deallocated by an eviction"), spotVMRestartRequeue)
+	}
+	future, err := converters.SDKToFuture(sdkFuture, infrav1.PostFuture, spotVMRestartServiceName, spec.Name, spec.ResourceGroup)
+	if err != nil {
+		return err
+	}
+	s.Scope.SetLongRunningOperationState(future)
+	return azure.WithTransientError(azure.NewOperationNotDoneError(future), spotVMRestartRequeue)
+}
+
 // Delete deletes the virtual machine with the provided name.
 func (s *Service) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "virtualmachines.Service.Delete")
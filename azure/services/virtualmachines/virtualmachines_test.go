@@ -27,13 +27,16 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/converters"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/publicips/mock_publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines/mock_virtualmachines"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 var (
@@ -121,6 +124,10 @@ func TestReconcileVM(t *testing.T) {
 				mpip.Get(gomockinternal.AContext(), "test-group", "pip-1").Return(fakePublicIPs, nil)
 				s.SetAddresses(fakeNodeAddresses)
 				s.SetVMState(infrav1.Succeeded)
+				s.GetLongRunningOperationState("test-vm", resizeServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+				s.GetLongRunningOperationState("test-vm", cloudProviderConfigServiceName).Return(nil)
+				s.CloudProviderConfig(gomockinternal.AContext()).Return(nil, nil)
 			},
 		},
 		{
@@ -195,6 +202,177 @@ func TestReconcileVM(t *testing.T) {
 	}
 }
 
+func TestReconcileSpotEviction(t *testing.T) {
+	deletePolicy := infrav1.SpotEvictionPolicyDelete
+	restart := infrav1.SpotRecoveryPolicyRestart
+	runningVM := fakeSpotVM("PowerState/running")
+	deallocatedVM := fakeSpotVM("PowerState/deallocated")
+	restartFuture := infrav1.Future{
+		Type:          infrav1.PostFuture,
+		ServiceName:   spotVMRestartServiceName,
+		Name:          "test-vm",
+		ResourceGroup: "test-group",
+		Data:          "eyJtZXRob2QiOiJQT1NUIiwicG9sbGluZ01ldGhvZCI6IkxvY2F0aW9uIiwibHJvU3RhdGUiOiJJblByb2dyZXNzIn0=",
+	}
+	sdkFuture, err := converters.FutureToSDK(restartFuture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name          string
+		spotVMOptions *infrav1.SpotVMOptions
+		vm            compute.VirtualMachine
+		expectedError string
+		expect        func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder)
+	}{
+		{
+			name:          "regular vm is ignored",
+			spotVMOptions: nil,
+			vm:            deallocatedVM,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "running spot vm is ignored",
+			spotVMOptions: &infrav1.SpotVMOptions{},
+			vm:            runningVM,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "spot vm with the delete eviction policy is ignored",
+			spotVMOptions: &infrav1.SpotVMOptions{EvictionPolicy: &deletePolicy},
+			vm:            deallocatedVM,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+			},
+		},
+		{
+			name:          "deallocated spot vm is marked as not running and its machine is remediated",
+			spotVMOptions: &infrav1.SpotVMOptions{},
+			vm:            deallocatedVM,
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityError, gomock.Any())
+				s.RequestRemediation(gomockinternal.AContext(), "spot VM was deallocated by an eviction").Return(nil)
+			},
+		},
+		{
+			name:          "failure to remediate the machine of a deallocated spot vm is returned",
+			spotVMOptions: &infrav1.SpotVMOptions{},
+			vm:            deallocatedVM,
+			expectedError: "boom",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityError, gomock.Any())
+				s.RequestRemediation(gomockinternal.AContext(), "spot VM was deallocated by an eviction").Return(errors.New("boom"))
+			},
+		},
+		{
+			name:          "deallocated spot vm is restarted",
+			spotVMOptions: &infrav1.SpotVMOptions{RecoveryPolicy: &restart},
+			vm:            deallocatedVM,
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+				m.StartAsync(gomockinternal.AContext(), "test-group", "test-vm").Return(sdkFuture, nil)
+				s.SetLongRunningOperationState(gomock.Any())
+			},
+		},
+		{
+			name:          "restarting deallocated spot vm fails",
+			spotVMOptions: &infrav1.SpotVMOptions{RecoveryPolicy: &restart},
+			vm:            deallocatedVM,
+			expectedError: "failed to restart spot VM deallocated by an eviction: #: Internal Server Error: StatusCode=500",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(nil)
+				s.SetConditionFalse(infrav1.VMRunningCondition, infrav1.VMDeallocatedReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+				m.StartAsync(gomockinternal.AContext(), "test-group", "test-vm").Return(nil, internalError)
+			},
+		},
+		{
+			name:          "restart of a deallocated spot vm is in progress",
+			spotVMOptions: &infrav1.SpotVMOptions{RecoveryPolicy: &restart},
+			vm:            deallocatedVM,
+			expectedError: "operation type POST on Azure resource test-group/test-vm is not done",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(&restartFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(false, nil)
+			},
+		},
+		{
+			name:          "restart of a deallocated spot vm is done",
+			spotVMOptions: &infrav1.SpotVMOptions{RecoveryPolicy: &restart},
+			vm:            deallocatedVM,
+			expectedError: "spot VM deallocated by an eviction was restarted",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(&restartFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(true, nil)
+				s.DeleteLongRunningOperationState("test-vm", spotVMRestartServiceName)
+			},
+		},
+		{
+			name:          "restart of a deallocated spot vm failed",
+			spotVMOptions: &infrav1.SpotVMOptions{RecoveryPolicy: &restart},
+			vm:            deallocatedVM,
+			expectedError: "failed to restart spot VM deallocated by an eviction: allocation failed",
+			expect: func(s *mock_virtualmachines.MockVMScopeMockRecorder, m *mock_virtualmachines.MockClientMockRecorder) {
+				s.GetLongRunningOperationState("test-vm", spotVMRestartServiceName).Return(&restartFuture)
+				m.IsDone(gomockinternal.AContext(), gomock.Any()).Return(false, errors.New("allocation failed"))
+				s.DeleteLongRunningOperationState("test-vm", spotVMRestartServiceName)
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			scopeMock := mock_virtualmachines.NewMockVMScope(mockCtrl)
+			clientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+
+			tc.expect(scopeMock.EXPECT(), clientMock.EXPECT())
+
+			s := &Service{
+				Scope:  scopeMock,
+				client: clientMock,
+			}
+
+			spec := fakeVMSpec
+			spec.SpotVMOptions = tc.spotVMOptions
+			err := s.reconcileSpotEviction(context.TODO(), &spec, tc.vm)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func fakeSpotVM(powerState string) compute.VirtualMachine {
+	return compute.VirtualMachine{
+		Name: to.StringPtr("test-vm"),
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			InstanceView: &compute.VirtualMachineInstanceView{
+				Statuses: &[]compute.InstanceViewStatus{
+					{Code: to.StringPtr("ProvisioningState/succeeded")},
+					{Code: to.StringPtr(powerState)},
+				},
+			},
+		},
+	}
+}
+
 func TestDeleteVM(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -36,9 +36,13 @@ type azureClient struct {
 	virtualnetworks network.VirtualNetworksClient
 }
 
-// newClient creates a new VM client from subscription ID.
-func newClient(auth azure.Authorizer) *azureClient {
-	c := newVirtualNetworksClient(auth.SubscriptionID(), auth.BaseURI(), auth.Authorizer())
+// newClient creates a new virtual networks client for the subscription of the virtual network, which defaults to the
+// subscription of the cluster.
+func newClient(auth azure.CrossSubscriptionAuthorizer, subscriptionID string) *azureClient {
+	if subscriptionID == "" {
+		subscriptionID = auth.SubscriptionID()
+	}
+	c := newVirtualNetworksClient(subscriptionID, auth.BaseURI(), azure.AuthorizerForSubscription(auth, subscriptionID))
 	return &azureClient{
 		virtualnetworks: c,
 	}
@@ -25,7 +25,9 @@ import (
 
 // VNetSpec defines the specification for a Virtual Network.
 type VNetSpec struct {
-	ResourceGroup  string
+	ResourceGroup string
+	// SubscriptionID defaults to the subscription of the cluster when empty.
+	SubscriptionID string
 	Name           string
 	CIDRs          []string
 	Location       string
@@ -34,7 +34,7 @@ const serviceName = "virtualnetworks"
 
 // VNetScope defines the scope interface for a virtual network service.
 type VNetScope interface {
-	azure.Authorizer
+	azure.CrossSubscriptionAuthorizer
 	azure.AsyncStatusUpdater
 	Vnet() *infrav1.VnetSpec
 	VNetSpec() azure.ResourceSpecGetter
@@ -50,7 +50,7 @@ type Service struct {
 
 // New creates a new service.
 func New(scope VNetScope) *Service {
-	client := newClient(scope)
+	client := newClient(scope, scope.Vnet().SubscriptionID)
 	return &Service{
 		Scope:      scope,
 		Getter:     client,
@@ -67,6 +67,18 @@ func (s *Service) Reconcile(ctx context.Context) error {
 
 	vnetSpec := s.Scope.VNetSpec()
 
+	// a virtual network in another subscription is never created, since the security groups and route tables of the
+	// cluster could not be associated with its subnets
+	if subscriptionID := s.Scope.Vnet().SubscriptionID; subscriptionID != "" && subscriptionID != s.Scope.SubscriptionID() {
+		if _, err := s.Get(ctx, vnetSpec); err != nil {
+			if azure.ResourceNotFound(err) {
+				err = errors.Errorf("virtual network %s must already exist in resource group %s of subscription %s", vnetSpec.ResourceName(), vnetSpec.ResourceGroupName(), subscriptionID)
+			}
+			s.Scope.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, err)
+			return err
+		}
+	}
+
 	result, err := s.CreateResource(ctx, vnetSpec, serviceName)
 	s.Scope.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, err)
 	if err == nil && result != nil {
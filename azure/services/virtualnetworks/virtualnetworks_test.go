@@ -72,6 +72,7 @@ func TestReconcileVnet(t *testing.T) {
 			expectedError: "",
 			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VNetSpec().Return(&fakeVNetSpec)
+				s.Vnet().Return(&infrav1.VnetSpec{ResourceGroup: "test-group", Name: "test-vnet"})
 				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, nil)
 				s.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, nil)
 			},
@@ -81,10 +82,34 @@ func TestReconcileVnet(t *testing.T) {
 			expectedError: internalError.Error(),
 			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
 				s.VNetSpec().Return(&fakeVNetSpec)
+				s.Vnet().Return(&infrav1.VnetSpec{ResourceGroup: "test-group", Name: "test-vnet"})
 				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(nil, internalError)
 				s.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, internalError)
 			},
 		},
+		{
+			name:          "existing vnet in another subscription succeeds, should not return an error",
+			expectedError: "",
+			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.VNetSpec().Return(&fakeVNetSpec)
+				s.Vnet().AnyTimes().Return(&infrav1.VnetSpec{ResourceGroup: "test-group", Name: "test-vnet", SubscriptionID: "other-subscription"})
+				s.SubscriptionID().AnyTimes().Return("subscription")
+				m.Get(gomockinternal.AContext(), &fakeVNetSpec).Return(customVnet, nil)
+				r.CreateResource(gomockinternal.AContext(), &fakeVNetSpec, serviceName).Return(customVnet, nil)
+				s.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, nil)
+			},
+		},
+		{
+			name:          "missing vnet in another subscription, should return an error without creating it",
+			expectedError: "virtual network test-vnet must already exist in resource group test-group of subscription other-subscription",
+			expect: func(s *mock_virtualnetworks.MockVNetScopeMockRecorder, m *mock_async.MockGetterMockRecorder, r *mock_async.MockReconcilerMockRecorder) {
+				s.VNetSpec().Return(&fakeVNetSpec)
+				s.Vnet().AnyTimes().Return(&infrav1.VnetSpec{ResourceGroup: "test-group", Name: "test-vnet", SubscriptionID: "other-subscription"})
+				s.SubscriptionID().AnyTimes().Return("subscription")
+				m.Get(gomockinternal.AContext(), &fakeVNetSpec).Return(nil, autorest.NewErrorWithResponse("", "", &http.Response{StatusCode: 404}, "Not found"))
+				s.UpdatePutStatus(infrav1.VNetReadyCondition, serviceName, gomock.Any())
+			},
+		},
 	}
 
 	for _, tc := range testcases {
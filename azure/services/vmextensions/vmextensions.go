@@ -65,6 +65,11 @@ func (s *Service) Reconcile(ctx context.Context) error {
 		}
 
 		log.V(2).Info("creating VM extension", "vm extension", extensionSpec.Name)
+		// leave settings unset rather than null for extensions without public settings
+		var settings interface{}
+		if len(extensionSpec.Settings) > 0 {
+			settings = extensionSpec.Settings
+		}
 		err := s.client.CreateOrUpdateAsync(
 			ctx,
 			s.Scope.ResourceGroup(),
@@ -75,7 +80,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 					Publisher:          to.StringPtr(extensionSpec.Publisher),
 					Type:               to.StringPtr(extensionSpec.Name),
 					TypeHandlerVersion: to.StringPtr(extensionSpec.Version),
-					Settings:           nil,
+					Settings:           settings,
 					ProtectedSettings:  extensionSpec.ProtectedSettings,
 				},
 				Location: to.StringPtr(s.Scope.Location()),
@@ -42,6 +42,13 @@ func NewClient(auth azure.Authorizer) *AzureClient {
 	return &AzureClient{c}
 }
 
+// newSubscriptionClient creates a new virtual network peerings client for the virtual networks of another subscription
+// than the one of the cluster.
+func newSubscriptionClient(auth azure.CrossSubscriptionAuthorizer, subscriptionID string) *AzureClient {
+	c := newPeeringsClient(subscriptionID, auth.BaseURI(), azure.AuthorizerForSubscription(auth, subscriptionID))
+	return &AzureClient{c}
+}
+
 // newPeeringsClient creates a new virtual network peerings client from subscription ID.
 func newPeeringsClient(subscriptionID string, baseURI string, authorizer autorest.Authorizer) network.VirtualNetworkPeeringsClient {
 	peeringsClient := network.NewVirtualNetworkPeeringsClientWithBaseURI(baseURI, subscriptionID)
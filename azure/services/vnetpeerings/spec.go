@@ -25,12 +25,17 @@ import (
 
 // VnetPeeringSpec defines the specification for a virtual network peering.
 type VnetPeeringSpec struct {
-	SourceResourceGroup string
-	SourceVnetName      string
-	RemoteResourceGroup string
-	RemoteVnetName      string
-	PeeringName         string
-	SubscriptionID      string
+	SourceSubscriptionID string
+	SourceResourceGroup  string
+	SourceVnetName       string
+	RemoteSubscriptionID string
+	RemoteResourceGroup  string
+	RemoteVnetName       string
+	PeeringName          string
+	// AllowForwardedTraffic, AllowGatewayTransit and UseRemoteGateways are left to the Azure defaults when nil.
+	AllowForwardedTraffic *bool
+	AllowGatewayTransit   *bool
+	UseRemoteGateways     *bool
 }
 
 // ResourceName returns the name of the virtual network peering.
@@ -51,20 +56,44 @@ func (s *VnetPeeringSpec) OwnerResourceName() string {
 // Parameters returns the parameters for the virtual network peering.
 func (s *VnetPeeringSpec) Parameters(existing interface{}) (params interface{}, err error) {
 	if existing != nil {
-		if _, ok := existing.(network.VirtualNetworkPeering); !ok {
+		existingPeering, ok := existing.(network.VirtualNetworkPeering)
+		if !ok {
 			return nil, errors.Errorf("%T is not a network.VnetPeering", existing)
 		}
-		// virtual network peering already exists
-		return nil, nil
+
+		if s.isUpToDate(existingPeering) {
+			// virtual network peering already exists with the expected properties
+			return nil, nil
+		}
 	}
-	vnetID := azure.VNetID(s.SubscriptionID, s.RemoteResourceGroup, s.RemoteVnetName)
+
+	vnetID := azure.VNetID(s.RemoteSubscriptionID, s.RemoteResourceGroup, s.RemoteVnetName)
 	peeringProperties := network.VirtualNetworkPeeringPropertiesFormat{
 		RemoteVirtualNetwork: &network.SubResource{
 			ID: to.StringPtr(vnetID),
 		},
+		AllowForwardedTraffic: s.AllowForwardedTraffic,
+		AllowGatewayTransit:   s.AllowGatewayTransit,
+		UseRemoteGateways:     s.UseRemoteGateways,
 	}
 	return network.VirtualNetworkPeering{
 		Name:                                  to.StringPtr(s.PeeringName),
 		VirtualNetworkPeeringPropertiesFormat: &peeringProperties,
 	}, nil
 }
+
+// isUpToDate returns true if the properties of the existing peering match the ones set in the spec.
+func (s *VnetPeeringSpec) isUpToDate(existing network.VirtualNetworkPeering) bool {
+	properties := existing.VirtualNetworkPeeringPropertiesFormat
+	if properties == nil {
+		return s.AllowForwardedTraffic == nil && s.AllowGatewayTransit == nil && s.UseRemoteGateways == nil
+	}
+	return boolUpToDate(s.AllowForwardedTraffic, properties.AllowForwardedTraffic) &&
+		boolUpToDate(s.AllowGatewayTransit, properties.AllowGatewayTransit) &&
+		boolUpToDate(s.UseRemoteGateways, properties.UseRemoteGateways)
+}
+
+// boolUpToDate returns true if the expected value is not set or equals the actual one, a missing actual value being false.
+func boolUpToDate(expected, actual *bool) bool {
+	return expected == nil || *expected == to.Bool(actual)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vnetpeerings
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2021-02-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+)
+
+var fakeGatewayTransitPeeringSpec = VnetPeeringSpec{
+	PeeringName:           "spoke-vnet-To-hub-vnet",
+	SourceSubscriptionID:  "spoke-sub",
+	SourceResourceGroup:   "spoke-rg",
+	SourceVnetName:        "spoke-vnet",
+	RemoteSubscriptionID:  "hub-sub",
+	RemoteResourceGroup:   "hub-rg",
+	RemoteVnetName:        "hub-vnet",
+	AllowForwardedTraffic: to.BoolPtr(true),
+	UseRemoteGateways:     to.BoolPtr(true),
+}
+
+func TestParameters(t *testing.T) {
+	testcases := []struct {
+		name          string
+		spec          *VnetPeeringSpec
+		existing      interface{}
+		expect        func(g *WithT, result interface{})
+		expectedError string
+	}{
+		{
+			name:     "new peering",
+			spec:     &fakeGatewayTransitPeeringSpec,
+			existing: nil,
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(Equal(network.VirtualNetworkPeering{
+					Name: to.StringPtr("spoke-vnet-To-hub-vnet"),
+					VirtualNetworkPeeringPropertiesFormat: &network.VirtualNetworkPeeringPropertiesFormat{
+						RemoteVirtualNetwork: &network.SubResource{
+							ID: to.StringPtr("/subscriptions/hub-sub/resourceGroups/hub-rg/providers/Microsoft.Network/virtualNetworks/hub-vnet"),
+						},
+						AllowForwardedTraffic: to.BoolPtr(true),
+						UseRemoteGateways:     to.BoolPtr(true),
+					},
+				}))
+			},
+		},
+		{
+			name: "existing peering with the expected properties",
+			spec: &fakeGatewayTransitPeeringSpec,
+			existing: network.VirtualNetworkPeering{
+				Name: to.StringPtr("spoke-vnet-To-hub-vnet"),
+				VirtualNetworkPeeringPropertiesFormat: &network.VirtualNetworkPeeringPropertiesFormat{
+					AllowForwardedTraffic: to.BoolPtr(true),
+					AllowGatewayTransit:   to.BoolPtr(false),
+					UseRemoteGateways:     to.BoolPtr(true),
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "existing peering not using the remote gateways",
+			spec: &fakeGatewayTransitPeeringSpec,
+			existing: network.VirtualNetworkPeering{
+				Name: to.StringPtr("spoke-vnet-To-hub-vnet"),
+				VirtualNetworkPeeringPropertiesFormat: &network.VirtualNetworkPeeringPropertiesFormat{
+					AllowForwardedTraffic: to.BoolPtr(true),
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeAssignableToTypeOf(network.VirtualNetworkPeering{}))
+				g.Expect(result.(network.VirtualNetworkPeering).UseRemoteGateways).To(Equal(to.BoolPtr(true)))
+			},
+		},
+		{
+			name: "existing peering without properties set in the spec",
+			spec: &VnetPeeringSpec{
+				PeeringName:    "vnet-To-remote-vnet",
+				RemoteVnetName: "remote-vnet",
+			},
+			existing: network.VirtualNetworkPeering{
+				Name: to.StringPtr("vnet-To-remote-vnet"),
+				VirtualNetworkPeeringPropertiesFormat: &network.VirtualNetworkPeeringPropertiesFormat{
+					AllowGatewayTransit: to.BoolPtr(true),
+				},
+			},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name:     "existing resource is not a peering",
+			spec:     &fakeGatewayTransitPeeringSpec,
+			existing: network.VirtualNetwork{},
+			expect: func(g *WithT, result interface{}) {
+				g.Expect(result).To(BeNil())
+			},
+			expectedError: "network.VirtualNetwork is not a network.VnetPeering",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			t.Parallel()
+
+			result, err := tc.spec.Parameters(tc.existing)
+			if tc.expectedError != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).To(MatchError(tc.expectedError))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			tc.expect(g, result)
+		})
+	}
+}
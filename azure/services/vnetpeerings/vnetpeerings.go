@@ -30,7 +30,7 @@ const serviceName = "vnetpeerings"
 
 // VnetPeeringScope defines the scope interface for a subnet service.
 type VnetPeeringScope interface {
-	azure.Authorizer
+	azure.CrossSubscriptionAuthorizer
 	azure.AsyncStatusUpdater
 	VnetPeeringSpecs() []azure.ResourceSpecGetter
 }
@@ -39,6 +39,9 @@ type VnetPeeringScope interface {
 type Service struct {
 	Scope VnetPeeringScope
 	async.Reconciler
+	// subscriptionReconcilers are the reconcilers of the peerings of the virtual networks in other subscriptions than
+	// the one of the cluster, by subscription.
+	subscriptionReconcilers map[string]async.Reconciler
 }
 
 // New creates a new service.
@@ -63,7 +66,7 @@ func (s *Service) Reconcile(ctx context.Context) error {
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error creating) -> operationNotDoneError (i.e. creating in progress) -> no error (i.e. created)
 	var result error
 	for _, peeringSpec := range s.Scope.VnetPeeringSpecs() {
-		if _, err := s.CreateResource(ctx, peeringSpec, serviceName); err != nil {
+		if _, err := s.reconcilerFor(peeringSpec).CreateResource(ctx, peeringSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
@@ -88,7 +91,7 @@ func (s *Service) Delete(ctx context.Context) error {
 	// If multiple errors occur, we return the most pressing one.
 	//  Order of precedence (highest -> lowest) is: error that is not an operationNotDoneError (i.e. error deleting) -> operationNotDoneError (i.e. deleting in progress) -> no error (i.e. deleted)
 	for _, peeringSpec := range s.Scope.VnetPeeringSpecs() {
-		if err := s.DeleteResource(ctx, peeringSpec, serviceName); err != nil {
+		if err := s.reconcilerFor(peeringSpec).DeleteResource(ctx, peeringSpec, serviceName); err != nil {
 			if !azure.IsOperationNotDoneError(err) || result == nil {
 				result = err
 			}
@@ -97,3 +100,23 @@ func (s *Service) Delete(ctx context.Context) error {
 	s.Scope.UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, result)
 	return result
 }
+
+// reconcilerFor returns the reconciler of the peering, whose client targets the subscription of its source virtual
+// network.
+func (s *Service) reconcilerFor(spec azure.ResourceSpecGetter) async.Reconciler {
+	peeringSpec, ok := spec.(*VnetPeeringSpec)
+	if !ok || peeringSpec.SourceSubscriptionID == "" || peeringSpec.SourceSubscriptionID == s.Scope.SubscriptionID() {
+		return s.Reconciler
+	}
+
+	subscriptionID := peeringSpec.SourceSubscriptionID
+	if reconciler, ok := s.subscriptionReconcilers[subscriptionID]; ok {
+		return reconciler
+	}
+	if s.subscriptionReconcilers == nil {
+		s.subscriptionReconcilers = make(map[string]async.Reconciler)
+	}
+	client := newSubscriptionClient(s.Scope, subscriptionID)
+	s.subscriptionReconcilers[subscriptionID] = async.New(s.Scope, client, client)
+	return s.subscriptionReconcilers[subscriptionID]
+}
@@ -27,6 +27,7 @@ import (
 	. "github.com/onsi/gomega"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/async/mock_async"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/vnetpeerings/mock_vnetpeerings"
 	gomockinternal "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
@@ -34,44 +35,44 @@ import (
 
 var (
 	fakePeering1To2 = VnetPeeringSpec{
-		PeeringName:         "vnet1-to-vnet2",
-		SourceVnetName:      "vnet1",
-		SourceResourceGroup: "group1",
-		RemoteVnetName:      "vnet2",
-		RemoteResourceGroup: "group2",
-		SubscriptionID:      "sub1",
+		PeeringName:          "vnet1-to-vnet2",
+		SourceVnetName:       "vnet1",
+		SourceResourceGroup:  "group1",
+		RemoteVnetName:       "vnet2",
+		RemoteResourceGroup:  "group2",
+		RemoteSubscriptionID: "sub1",
 	}
 	fakePeering2To1 = VnetPeeringSpec{
-		PeeringName:         "vnet2-to-vnet1",
-		SourceVnetName:      "vnet2",
-		SourceResourceGroup: "group2",
-		RemoteVnetName:      "vnet1",
-		RemoteResourceGroup: "group1",
-		SubscriptionID:      "sub1",
+		PeeringName:          "vnet2-to-vnet1",
+		SourceVnetName:       "vnet2",
+		SourceResourceGroup:  "group2",
+		RemoteVnetName:       "vnet1",
+		RemoteResourceGroup:  "group1",
+		RemoteSubscriptionID: "sub1",
 	}
 	fakePeering1To3 = VnetPeeringSpec{
-		PeeringName:         "vnet1-to-vnet3",
-		SourceVnetName:      "vnet1",
-		SourceResourceGroup: "group1",
-		RemoteVnetName:      "vnet3",
-		RemoteResourceGroup: "group3",
-		SubscriptionID:      "sub1",
+		PeeringName:          "vnet1-to-vnet3",
+		SourceVnetName:       "vnet1",
+		SourceResourceGroup:  "group1",
+		RemoteVnetName:       "vnet3",
+		RemoteResourceGroup:  "group3",
+		RemoteSubscriptionID: "sub1",
 	}
 	fakePeering3To1 = VnetPeeringSpec{
-		PeeringName:         "vnet3-to-vnet1",
-		SourceVnetName:      "vnet3",
-		SourceResourceGroup: "group3",
-		RemoteVnetName:      "vnet1",
-		RemoteResourceGroup: "group1",
-		SubscriptionID:      "sub1",
+		PeeringName:          "vnet3-to-vnet1",
+		SourceVnetName:       "vnet3",
+		SourceResourceGroup:  "group3",
+		RemoteVnetName:       "vnet1",
+		RemoteResourceGroup:  "group1",
+		RemoteSubscriptionID: "sub1",
 	}
 	fakePeeringExtra = VnetPeeringSpec{
-		PeeringName:         "extra-peering",
-		SourceVnetName:      "vnet3",
-		SourceResourceGroup: "group3",
-		RemoteVnetName:      "vnet4",
-		RemoteResourceGroup: "group4",
-		SubscriptionID:      "sub1",
+		PeeringName:          "extra-peering",
+		SourceVnetName:       "vnet3",
+		SourceResourceGroup:  "group3",
+		RemoteVnetName:       "vnet4",
+		RemoteResourceGroup:  "group4",
+		RemoteSubscriptionID: "sub1",
 	}
 	fakePeeringSpecs      = []azure.ResourceSpecGetter{&fakePeering1To2, &fakePeering2To1, &fakePeering1To3, &fakePeering3To1}
 	fakePeeringExtraSpecs = []azure.ResourceSpecGetter{&fakePeering1To2, &fakePeering2To1, &fakePeeringExtra}
@@ -226,6 +227,55 @@ func TestReconcileVnetPeerings(t *testing.T) {
 	}
 }
 
+func TestVnetPeeringsInOtherSubscription(t *testing.T) {
+	g := NewWithT(t)
+
+	forwardPeering := VnetPeeringSpec{
+		PeeringName:          "vnet1-to-hub",
+		SourceSubscriptionID: "sub1",
+		SourceVnetName:       "vnet1",
+		SourceResourceGroup:  "group1",
+		RemoteSubscriptionID: "hub-sub",
+		RemoteVnetName:       "hub",
+		RemoteResourceGroup:  "hub-group",
+	}
+	reversePeering := VnetPeeringSpec{
+		PeeringName:          "hub-to-vnet1",
+		SourceSubscriptionID: "hub-sub",
+		SourceVnetName:       "hub",
+		SourceResourceGroup:  "hub-group",
+		RemoteSubscriptionID: "sub1",
+		RemoteVnetName:       "vnet1",
+		RemoteResourceGroup:  "group1",
+	}
+	specs := []azure.ResourceSpecGetter{&forwardPeering, &reversePeering}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	scopeMock := mock_vnetpeerings.NewMockVnetPeeringScope(mockCtrl)
+	asyncMock := mock_async.NewMockReconciler(mockCtrl)
+	hubAsyncMock := mock_async.NewMockReconciler(mockCtrl)
+
+	// the peering of the remote virtual network is reconciled in its own subscription
+	scopeMock.EXPECT().SubscriptionID().AnyTimes().Return("sub1")
+	scopeMock.EXPECT().VnetPeeringSpecs().Times(2).Return(specs)
+	asyncMock.EXPECT().CreateResource(gomockinternal.AContext(), &forwardPeering, serviceName).Return(&forwardPeering, nil)
+	hubAsyncMock.EXPECT().CreateResource(gomockinternal.AContext(), &reversePeering, serviceName).Return(&reversePeering, nil)
+	scopeMock.EXPECT().UpdatePutStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
+	asyncMock.EXPECT().DeleteResource(gomockinternal.AContext(), &forwardPeering, serviceName).Return(nil)
+	hubAsyncMock.EXPECT().DeleteResource(gomockinternal.AContext(), &reversePeering, serviceName).Return(nil)
+	scopeMock.EXPECT().UpdateDeleteStatus(infrav1.VnetPeeringReadyCondition, serviceName, nil)
+
+	s := &Service{
+		Scope:                   scopeMock,
+		Reconciler:              asyncMock,
+		subscriptionReconcilers: map[string]async.Reconciler{"hub-sub": hubAsyncMock},
+	}
+
+	g.Expect(s.Reconcile(context.TODO())).To(Succeed())
+	g.Expect(s.Delete(context.TODO())).To(Succeed())
+}
+
 func TestDeleteVnetPeerings(t *testing.T) {
 	testcases := []struct {
 		name          string
@@ -47,6 +47,15 @@ type RoleAssignmentSpec struct {
 	MachineName  string
 	Name         string
 	ResourceType string
+	// RoleDefinitionID is the GUID or resource ID of the role definition, RoleDefinitionName its name. The Contributor
+	// role is assigned when both are empty.
+	RoleDefinitionID   string
+	RoleDefinitionName string
+	// Scope defaults to the subscription.
+	Scope string
+	// UserAssignedIdentityID is the resource ID of the user-assigned identity to assign the role to. The role is
+	// assigned to the system-assigned identity when it is empty.
+	UserAssignedIdentityID string
 }
 
 // ResourceType defines the type azure resource being reconciled.
@@ -79,6 +88,7 @@ type ScaleSetSpec struct {
 	SubnetName                   string
 	VNetName                     string
 	VNetResourceGroup            string
+	VNetSubscriptionID           string
 	PublicLBName                 string
 	PublicLBAddressPoolName      string
 	AcceleratedNetworking        *bool
@@ -86,8 +96,17 @@ type ScaleSetSpec struct {
 	Identity                     infrav1.VMIdentity
 	UserAssignedIdentities       []infrav1.UserAssignedIdentity
 	SecurityProfile              *infrav1.SecurityProfile
+	Diagnostics                  *infrav1.Diagnostics
 	SpotVMOptions                *infrav1.SpotVMOptions
 	FailureDomains               []string
+	OrchestrationMode            infrav1.OrchestrationModeType
+	// AutomaticRepairsGracePeriod is the ISO 8601 grace period of automatic instance repairs, e.g. PT30M.
+	// Automatic repairs are disabled when it is empty.
+	AutomaticRepairsGracePeriod string
+	// ZoneBalance strictly balances the instances across the FailureDomains.
+	ZoneBalance *bool
+	// PlatformFaultDomainCount is the number of fault domains the instances are spread across.
+	PlatformFaultDomainCount *int32
 }
 
 // TagsSpec defines the specification for a set of tags.
@@ -103,15 +122,34 @@ type TagsSpec struct {
 // PrivateDNSSpec defines the specification for a private DNS zone.
 type PrivateDNSSpec struct {
 	ZoneName string
-	Links    []PrivateDNSLinkSpec
-	Records  []infrav1.AddressRecord
+	// ResourceGroup and SubscriptionID of the zone default to the ones of the cluster when empty.
+	ResourceGroup  string
+	SubscriptionID string
+	Links          []PrivateDNSLinkSpec
+	Records        []infrav1.AddressRecord
 }
 
 // PrivateDNSLinkSpec defines the specification for a virtual network link in a private DNS zone.
 type PrivateDNSLinkSpec struct {
 	VNetName          string
 	VNetResourceGroup string
-	LinkName          string
+	// VNetSubscriptionID defaults to the subscription of the cluster when empty.
+	VNetSubscriptionID string
+	LinkName           string
+}
+
+// CloudProviderConfig defines the cloud provider config of a virtual machine.
+type CloudProviderConfig struct {
+	// Hash is the hash of the current cloud provider config.
+	Hash string
+	// LastAppliedHash is the hash of the cloud provider config last written on the virtual machine, if any.
+	LastAppliedHash string
+	// Script is the shell script writing the current cloud provider config on the virtual machine. It does not
+	// contain the config, which is either fetched from the Key Vault or passed in ProtectedParameters.
+	Script string
+	// ProtectedParameters are passed to the script as environment variables, and are neither logged nor returned by
+	// the Azure API.
+	ProtectedParameters map[string]string
 }
 
 // ExtensionSpec defines the specification for a VM or VMScaleSet extension.
@@ -120,6 +158,7 @@ type ExtensionSpec struct {
 	VMName            string
 	Publisher         string
 	Version           string
+	Settings          map[string]interface{}
 	ProtectedSettings map[string]string
 }
 
@@ -132,6 +171,10 @@ type (
 		Name             string                    `json:"name,omitempty"`
 		AvailabilityZone string                    `json:"availabilityZone,omitempty"`
 		State            infrav1.ProvisioningState `json:"vmState,omitempty"`
+		// ProtectFromScaleIn is true if the instance is protected from scale in.
+		ProtectFromScaleIn bool `json:"protectFromScaleIn,omitempty"`
+		// ProtectFromScaleSetActions is true if the instance is protected from all the actions of its scale set.
+		ProtectFromScaleSetActions bool `json:"protectFromScaleSetActions,omitempty"`
 	}
 
 	// VMSS defines a virtual machine scale set.
@@ -146,6 +189,9 @@ type (
 		Identity  infrav1.VMIdentity        `json:"identity,omitempty"`
 		Tags      infrav1.Tags              `json:"tags,omitempty"`
 		Instances []VMSSVM                  `json:"instances,omitempty"`
+		// AutomaticRepairsGracePeriod is the ISO 8601 grace period of automatic instance repairs, or empty if
+		// automatic repairs are disabled.
+		AutomaticRepairsGracePeriod string `json:"automaticRepairsGracePeriod,omitempty"`
 	}
 )
 
@@ -24,6 +24,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/bastionhosts"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/loadbalancers"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/natgateways"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/privatedns"
@@ -55,6 +56,7 @@ type azureClusterService struct {
 	natGatewaySvc    azure.Reconciler
 	peeringsSvc      azure.Reconciler
 	tagsSvc          azure.Reconciler
+	keyVaultSvc      azure.Reconciler
 }
 
 // newAzureClusterService populates all the services based on input scope.
@@ -79,6 +81,7 @@ func newAzureClusterService(scope *scope.ClusterScope) (*azureClusterService, er
 		skuCache:         skuCache,
 		peeringsSvc:      vnetpeerings.New(scope),
 		tagsSvc:          tags.New(scope),
+		keyVaultSvc:      keyvaults.New(scope),
 	}, nil
 }
 
@@ -100,6 +103,10 @@ func (s *azureClusterService) Reconcile(ctx context.Context) error {
 		return errors.Wrap(err, "failed to reconcile resource group")
 	}
 
+	if err := s.keyVaultSvc.Reconcile(ctx); err != nil {
+		return errors.Wrap(err, "failed to reconcile Key Vault")
+	}
+
 	if err := s.vnetSvc.Reconcile(ctx); err != nil {
 		return errors.Wrap(err, "failed to reconcile virtual network")
 	}
@@ -152,6 +159,11 @@ func (s *azureClusterService) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureClusterService.Delete")
 	defer done()
 
+	// the Key Vault is purged rather than deleted with the resource group, so that its name can be reused.
+	if err := s.keyVaultSvc.Delete(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete Key Vault")
+	}
+
 	if err := s.groupsSvc.Delete(ctx); err != nil {
 		if errors.Is(err, azure.ErrNotOwned) {
 			if err := s.bastionSvc.Delete(ctx); err != nil {
@@ -119,7 +119,9 @@ func TestAzureClusterReconcilerDelete(t *testing.T) {
 			dnsMock := mock_azure.NewMockReconciler(mockCtrl)
 			bastionMock := mock_azure.NewMockReconciler(mockCtrl)
 			peeringsMock := mock_azure.NewMockReconciler(mockCtrl)
+			keyVaultMock := mock_azure.NewMockReconciler(mockCtrl)
 
+			keyVaultMock.EXPECT().Delete(gomockinternal.AContext()).Return(nil)
 			tc.expect(groupsMock.EXPECT(), vnetMock.EXPECT(), sgMock.EXPECT(), rtMock.EXPECT(), subnetsMock.EXPECT(), natGatewaysMock.EXPECT(), publicIPMock.EXPECT(), lbMock.EXPECT(), dnsMock.EXPECT(), bastionMock.EXPECT(), peeringsMock.EXPECT())
 
 			s := &azureClusterService{
@@ -137,6 +139,7 @@ func TestAzureClusterReconcilerDelete(t *testing.T) {
 				privateDNSSvc:    dnsMock,
 				bastionSvc:       bastionMock,
 				peeringsSvc:      peeringsMock,
+				keyVaultSvc:      keyVaultMock,
 				skuCache:         resourceskus.NewStaticCache([]compute.ResourceSku{}, ""),
 			}
 
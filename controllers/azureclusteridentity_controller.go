@@ -0,0 +1,414 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2021-01-01/subscriptions"
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	infraexpv1 "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/feature"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+const (
+	// identityValidationInterval is how often the credentials of an identity are validated against Azure.
+	identityValidationInterval = 30 * time.Minute
+	// secretExpiryWarningPeriod is how long before the credentials in the secret of an identity expire that warnings
+	// are emitted.
+	secretExpiryWarningPeriod = 30 * 24 * time.Hour
+	// clientSecretIndex is the field index of AzureClusterIdentities by the namespace and name of their secret.
+	clientSecretIndex = "spec.clientSecret"
+)
+
+var (
+	identitySecretExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capz_identity_secret_expiry_timestamp_seconds",
+		Help: "Unix time the credentials in the secret of an AzureClusterIdentity expire, when it can be determined.",
+	}, []string{"namespace", "name"})
+	identitySecretExpiring = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "capz_identity_secret_expiring",
+		Help: "Whether the credentials in the secret of an AzureClusterIdentity expire within 30 days (1) or not (0).",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(identitySecretExpiry, identitySecretExpiring)
+}
+
+// identityChecker validates the credentials of an identity against Azure.
+type identityChecker interface {
+	// CheckToken requests a token from Azure Active Directory and returns an authorizer using it.
+	CheckToken(ctx context.Context, provider *scope.AzureCredentialsProvider, env azureautorest.Environment) (autorest.Authorizer, error)
+	// CheckSubscription reads the subscription with the authorizer.
+	CheckSubscription(ctx context.Context, authorizer autorest.Authorizer, env azureautorest.Environment, subscriptionID string) error
+}
+
+// azureIdentityChecker is an identityChecker calling Azure.
+type azureIdentityChecker struct{}
+
+// CheckToken implements identityChecker.
+func (azureIdentityChecker) CheckToken(ctx context.Context, provider *scope.AzureCredentialsProvider, env azureautorest.Environment) (autorest.Authorizer, error) {
+	spt, err := provider.GetToken(ctx, env.ResourceManagerEndpoint, env.ActiveDirectoryEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	return autorest.NewBearerAuthorizer(spt), nil
+}
+
+// CheckSubscription implements identityChecker.
+func (azureIdentityChecker) CheckSubscription(ctx context.Context, authorizer autorest.Authorizer, env azureautorest.Environment, subscriptionID string) error {
+	c := subscriptions.NewClientWithBaseURI(env.ResourceManagerEndpoint)
+	azure.SetAutoRestClientDefaults(&c.Client, authorizer)
+	_, err := c.Get(ctx, subscriptionID)
+	return err
+}
+
+// AzureClusterIdentityReconciler periodically validates that AzureClusterIdentities can obtain a token and read the
+// subscriptions of the clusters using them, and reports the expiry of their secrets.
+type AzureClusterIdentityReconciler struct {
+	client.Client
+	Recorder         record.EventRecorder
+	ReconcileTimeout time.Duration
+	WatchFilterValue string
+
+	checker identityChecker
+}
+
+// SetupWithManager initializes this controller with a manager.
+func (r *AzureClusterIdentityReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	_, log, done := tele.StartSpanWithLogger(ctx,
+		"controllers.AzureClusterIdentityReconciler.SetupWithManager",
+	)
+	defer done()
+
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &infrav1.AzureClusterIdentity{}, clientSecretIndex, clientSecretIndexValue); err != nil {
+		return errors.Wrap(err, "failed to index AzureClusterIdentities by secret")
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
+		For(&infrav1.AzureClusterIdentity{}, builder.WithPredicates(predicates.ResourceHasFilterLabel(log, r.WatchFilterValue))).
+		// validate the identity again as soon as its secret is rotated
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToAzureClusterIdentities),
+		).
+		Complete(r)
+}
+
+// clientSecretIndexValue returns the namespace and name of the secret of an AzureClusterIdentity, if any.
+func clientSecretIndexValue(o client.Object) []string {
+	identity, ok := o.(*infrav1.AzureClusterIdentity)
+	if !ok || identity.Spec.ClientSecret.Name == "" {
+		return nil
+	}
+	return []string{secretIndexKey(identity.Spec.ClientSecret.Namespace, identity.Spec.ClientSecret.Name)}
+}
+
+// secretIndexKey returns the key of a secret in the clientSecretIndex.
+func secretIndexKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// secretToAzureClusterIdentities maps a Secret to the AzureClusterIdentities referencing it. Only the identities in
+// the clientSecretIndex of the Secret are listed, so that the many Secrets unrelated to identities are cheap to map.
+func (r *AzureClusterIdentityReconciler) secretToAzureClusterIdentities(o client.Object) []ctrl.Request {
+	identities := &infrav1.AzureClusterIdentityList{}
+	if err := r.List(context.Background(), identities, client.MatchingFields{clientSecretIndex: secretIndexKey(o.GetNamespace(), o.GetName())}); err != nil {
+		return nil
+	}
+
+	// the references are still compared, as clients that do not serve the index ignore the field selector
+	var requests []ctrl.Request
+	for i := range identities.Items {
+		identity := &identities.Items[i]
+		secretRef := identity.Spec.ClientSecret
+		if secretRef.Name == o.GetName() && secretRef.Namespace == o.GetNamespace() {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(identity)})
+		}
+	}
+	return requests
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusteridentities,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azureclusteridentities/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile validates the credentials of an AzureClusterIdentity and records the result in its Ready condition.
+func (r *AzureClusterIdentityReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
+	defer cancel()
+
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureClusterIdentityReconciler.Reconcile",
+		tele.KVP("namespace", req.Namespace),
+		tele.KVP("name", req.Name),
+		tele.KVP("kind", "AzureClusterIdentity"),
+	)
+	defer done()
+
+	identity := &infrav1.AzureClusterIdentity{}
+	if err := r.Get(ctx, req.NamespacedName, identity); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("object was not found")
+			identitySecretExpiry.DeleteLabelValues(req.Namespace, req.Name)
+			identitySecretExpiring.DeleteLabelValues(req.Namespace, req.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if identity.Spec.Type == infrav1.UserAssignedMSI {
+		log.V(4).Info("skipping validation of identity", "type", identity.Spec.Type)
+		return reconcile.Result{}, nil
+	}
+
+	patchHelper, err := patch.NewHelper(identity, r.Client)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+
+	defer func() {
+		if err := patchHelper.Patch(ctx, identity, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{clusterv1.ReadyCondition}}); err != nil && reterr == nil {
+			reterr = errors.Wrap(err, "failed to patch AzureClusterIdentity status")
+		}
+	}()
+
+	if err := r.validate(ctx, identity); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: identityValidationInterval}, nil
+}
+
+// validate checks the expiry of the secret of the identity, then that it can obtain a token and read the
+// subscriptions of the clusters using it. Failures to validate the credentials are reported in the Ready condition,
+// only errors reading the clusters are returned.
+func (r *AzureClusterIdentityReconciler) validate(ctx context.Context, identity *infrav1.AzureClusterIdentity) error {
+	provider := &scope.AzureCredentialsProvider{
+		Client:   r.Client,
+		Identity: identity,
+	}
+
+	expiry, err := provider.GetSecretExpiry(ctx)
+	if err != nil {
+		r.markFalse(identity, infrav1.TokenRequestFailedReason, "failed to read the secret of the identity: %v", err)
+		return nil
+	}
+	if !r.checkExpiry(identity, expiry) {
+		return nil
+	}
+
+	targets, err := r.subscriptionsByEnvironment(ctx, identity)
+	if err != nil {
+		return err
+	}
+
+	checker := r.checker
+	if checker == nil {
+		checker = azureIdentityChecker{}
+	}
+
+	for _, envName := range sortedKeys(targets) {
+		env, err := environmentFromName(envName)
+		if err != nil {
+			r.markFalse(identity, infrav1.TokenRequestFailedReason, "%v", err)
+			return nil
+		}
+
+		authorizer, err := checker.CheckToken(ctx, provider, env)
+		if err != nil {
+			r.markFalse(identity, infrav1.TokenRequestFailedReason, "failed to obtain a token in %s: %v", env.Name, err)
+			return nil
+		}
+
+		for _, subscriptionID := range targets[envName] {
+			if err := checker.CheckSubscription(ctx, authorizer, env, subscriptionID); err != nil {
+				r.markFalse(identity, infrav1.SubscriptionAccessFailedReason, "failed to read subscription %s: %v", subscriptionID, err)
+				return nil
+			}
+		}
+	}
+
+	conditions.MarkTrue(identity, clusterv1.ReadyCondition)
+	return nil
+}
+
+// checkExpiry records the expiry of the secret of the identity in its status and the metrics, and warns when it
+// expires soon. It returns false when the secret has already expired.
+func (r *AzureClusterIdentityReconciler) checkExpiry(identity *infrav1.AzureClusterIdentity, expiry *time.Time) bool {
+	if expiry == nil {
+		identity.Status.SecretExpiryTime = nil
+		identitySecretExpiry.DeleteLabelValues(identity.Namespace, identity.Name)
+		identitySecretExpiring.DeleteLabelValues(identity.Namespace, identity.Name)
+		return true
+	}
+
+	identity.Status.SecretExpiryTime = &metav1.Time{Time: *expiry}
+	identitySecretExpiry.WithLabelValues(identity.Namespace, identity.Name).Set(float64(expiry.Unix()))
+
+	remaining := time.Until(*expiry)
+	if remaining > secretExpiryWarningPeriod {
+		identitySecretExpiring.WithLabelValues(identity.Namespace, identity.Name).Set(0)
+		return true
+	}
+	identitySecretExpiring.WithLabelValues(identity.Namespace, identity.Name).Set(1)
+
+	if remaining <= 0 {
+		r.markFalse(identity, infrav1.SecretExpiredReason, "the secret of the identity expired at %s", expiry.UTC().Format(time.RFC3339))
+		return false
+	}
+
+	r.Recorder.Eventf(identity, corev1.EventTypeWarning, "SecretExpiring", "the secret of the identity expires at %s", expiry.UTC().Format(time.RFC3339))
+	return true
+}
+
+// subscriptionsByEnvironment returns the subscriptions of the AzureClusters and AzureManagedControlPlanes using the
+// identity, by the name of their cloud environment. When no cluster uses the identity, only its token is validated
+// in the public cloud.
+func (r *AzureClusterIdentityReconciler) subscriptionsByEnvironment(ctx context.Context, identity *infrav1.AzureClusterIdentity) (map[string][]string, error) {
+	targets := map[string][]string{}
+	add := func(ref *corev1.ObjectReference, namespace, envName, subscriptionID string) {
+		if ref == nil || ref.Kind == infrav1.AzureNamespacedIdentityKind || ref.Name != identity.Name {
+			return
+		}
+		if ref.Namespace != "" {
+			namespace = ref.Namespace
+		}
+		if namespace != identity.Namespace {
+			return
+		}
+		if subscriptionID == "" {
+			// like the scopes, fall back to the subscription of the controller
+			subscriptionID = os.Getenv(auth.SubscriptionID)
+		}
+		// the token is validated in the environment even when the subscription is unknown
+		subscriptionIDs := targets[envName]
+		for _, s := range subscriptionIDs {
+			if s == subscriptionID {
+				return
+			}
+		}
+		if subscriptionID != "" {
+			subscriptionIDs = append(subscriptionIDs, subscriptionID)
+		}
+		targets[envName] = subscriptionIDs
+	}
+
+	azureClusters := &infrav1.AzureClusterList{}
+	if err := r.List(ctx, azureClusters); err != nil {
+		return nil, errors.Wrap(err, "failed to list AzureClusters")
+	}
+	for _, c := range azureClusters.Items {
+		add(c.Spec.IdentityRef, c.Namespace, c.Spec.AzureEnvironment, c.Spec.SubscriptionID)
+		for _, subscriptionID := range secondarySubscriptionIDs(c.Spec) {
+			add(c.Spec.SecondaryIdentityRef, c.Namespace, c.Spec.AzureEnvironment, subscriptionID)
+		}
+	}
+
+	if feature.Gates.Enabled(feature.AKS) {
+		controlPlanes := &infraexpv1.AzureManagedControlPlaneList{}
+		if err := r.List(ctx, controlPlanes); err != nil {
+			return nil, errors.Wrap(err, "failed to list AzureManagedControlPlanes")
+		}
+		for _, cp := range controlPlanes.Items {
+			add(cp.Spec.IdentityRef, cp.Namespace, "", cp.Spec.SubscriptionID)
+		}
+	}
+
+	if len(targets) == 0 {
+		targets[""] = nil
+	}
+	return targets, nil
+}
+
+// secondarySubscriptionIDs returns the subscriptions other than its own the cluster accesses with its secondary identity,
+// i.e. the ones of the remote virtual networks of its peerings and of its private DNS zone.
+func secondarySubscriptionIDs(spec infrav1.AzureClusterSpec) []string {
+	var subscriptionIDs []string
+	for _, peering := range spec.NetworkSpec.Vnet.Peerings {
+		subscriptionIDs = append(subscriptionIDs, peering.SubscriptionID)
+	}
+	subscriptionIDs = append(subscriptionIDs, spec.NetworkSpec.PrivateDNSZoneSubscriptionID)
+
+	var secondary []string
+	for _, subscriptionID := range subscriptionIDs {
+		if subscriptionID != "" && subscriptionID != spec.SubscriptionID {
+			secondary = append(secondary, subscriptionID)
+		}
+	}
+	return secondary
+}
+
+// markFalse sets the Ready condition of the identity to false and emits a warning event with the reason, unless the
+// condition was already false for the same reason, so that the periodic validation of a broken identity does not
+// repeat the event.
+func (r *AzureClusterIdentityReconciler) markFalse(identity *infrav1.AzureClusterIdentity, reason, messageFormat string, messageArgs ...interface{}) {
+	unchanged := conditions.IsFalse(identity, clusterv1.ReadyCondition) && conditions.GetReason(identity, clusterv1.ReadyCondition) == reason
+	conditions.MarkFalse(identity, clusterv1.ReadyCondition, reason, clusterv1.ConditionSeverityError, messageFormat, messageArgs...)
+	if !unchanged {
+		r.Recorder.Eventf(identity, corev1.EventTypeWarning, reason, messageFormat, messageArgs...)
+	}
+}
+
+// environmentFromName returns the Azure cloud environment with the name, defaulting to the public cloud.
+func environmentFromName(name string) (azureautorest.Environment, error) {
+	if name == "" {
+		return azureautorest.PublicCloud, nil
+	}
+	return azureautorest.EnvironmentFromName(name)
+}
+
+// sortedKeys returns the keys of the map in order, so that the environments are validated deterministically.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
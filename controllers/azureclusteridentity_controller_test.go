@@ -0,0 +1,427 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	azureautorest "github.com/Azure/go-autorest/autorest/azure"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+)
+
+// fakeIdentityChecker is an identityChecker recording the subscriptions it reads.
+type fakeIdentityChecker struct {
+	tokenErr        error
+	subscriptionErr error
+	environments    []string
+	subscriptions   []string
+}
+
+func (f *fakeIdentityChecker) CheckToken(_ context.Context, _ *scope.AzureCredentialsProvider, env azureautorest.Environment) (autorest.Authorizer, error) {
+	f.environments = append(f.environments, env.Name)
+	if f.tokenErr != nil {
+		return nil, f.tokenErr
+	}
+	return autorest.NullAuthorizer{}, nil
+}
+
+func (f *fakeIdentityChecker) CheckSubscription(_ context.Context, _ autorest.Authorizer, _ azureautorest.Environment, subscriptionID string) error {
+	f.subscriptions = append(f.subscriptions, subscriptionID)
+	return f.subscriptionErr
+}
+
+func TestAzureClusterIdentityReconciler(t *testing.T) {
+	cases := map[string]struct {
+		identityType   infrav1.IdentityType
+		notAfter       time.Duration
+		checker        *fakeIdentityChecker
+		expectedReady  corev1.ConditionStatus
+		expectedReason string
+		expectedEvent  string
+		expectExpiry   bool
+		expectedSubs   []string
+	}{
+		"identity with valid client secret is ready": {
+			identityType:  infrav1.ManualServicePrincipal,
+			checker:       &fakeIdentityChecker{},
+			expectedReady: corev1.ConditionTrue,
+			expectedSubs:  []string{"123"},
+		},
+		"identity failing to obtain a token is not ready": {
+			identityType:   infrav1.ManualServicePrincipal,
+			checker:        &fakeIdentityChecker{tokenErr: errors.New("invalid client secret")},
+			expectedReady:  corev1.ConditionFalse,
+			expectedReason: infrav1.TokenRequestFailedReason,
+			expectedEvent:  infrav1.TokenRequestFailedReason,
+		},
+		"identity failing to read the subscription is not ready": {
+			identityType:   infrav1.ManualServicePrincipal,
+			checker:        &fakeIdentityChecker{subscriptionErr: errors.New("authorization failed")},
+			expectedReady:  corev1.ConditionFalse,
+			expectedReason: infrav1.SubscriptionAccessFailedReason,
+			expectedEvent:  infrav1.SubscriptionAccessFailedReason,
+			expectedSubs:   []string{"123"},
+		},
+		"identity with a certificate expiring soon is ready with a warning": {
+			identityType:  infrav1.ServicePrincipalCertificate,
+			notAfter:      24 * time.Hour,
+			checker:       &fakeIdentityChecker{},
+			expectedReady: corev1.ConditionTrue,
+			expectedEvent: "SecretExpiring",
+			expectExpiry:  true,
+			expectedSubs:  []string{"123"},
+		},
+		"identity with an expired certificate is not ready": {
+			identityType:   infrav1.ServicePrincipalCertificate,
+			notAfter:       -time.Hour,
+			checker:        &fakeIdentityChecker{},
+			expectedReady:  corev1.ConditionFalse,
+			expectedReason: infrav1.SecretExpiredReason,
+			expectedEvent:  infrav1.SecretExpiredReason,
+			expectExpiry:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+			scheme, err := newScheme()
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// the parsed certificates are cached by secret, so each case needs its own
+			secretName := "my-identity-secret-" + strings.ReplaceAll(name, " ", "-")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"clientSecret": []byte("fooSecret"),
+				},
+			}
+			if tc.identityType == infrav1.ServicePrincipalCertificate {
+				secret.Data = map[string][]byte{
+					"certificate": newIdentityCertificate(t, time.Now().Add(tc.notAfter)),
+				}
+			}
+
+			identity := &infrav1.AzureClusterIdentity{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-identity",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureClusterIdentitySpec{
+					Type:         tc.identityType,
+					ClientID:     "my-client-id",
+					ClientSecret: corev1.SecretReference{Name: secretName, Namespace: "default"},
+					TenantID:     "my-tenant-id",
+				},
+			}
+
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-azure-cluster",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureClusterSpec{
+					SubscriptionID: "123",
+					IdentityRef: &corev1.ObjectReference{
+						Name: "my-identity",
+					},
+				},
+			}
+			otherCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-azure-cluster",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureClusterSpec{
+					SubscriptionID: "456",
+					IdentityRef: &corev1.ObjectReference{
+						Name: "other-identity",
+					},
+				},
+			}
+
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(identity, secret, azureCluster, otherCluster).Build()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &AzureClusterIdentityReconciler{
+				Client:   fakeClient,
+				Recorder: recorder,
+				checker:  tc.checker,
+			}
+
+			key := types.NamespacedName{Namespace: "default", Name: "my-identity"}
+			result, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(identityValidationInterval))
+			g.Expect(tc.checker.subscriptions).To(Equal(tc.expectedSubs))
+
+			updated := &infrav1.AzureClusterIdentity{}
+			g.Expect(fakeClient.Get(context.Background(), key, updated)).To(Succeed())
+			ready := conditions.Get(updated, clusterv1.ReadyCondition)
+			g.Expect(ready).NotTo(BeNil())
+			g.Expect(ready.Status).To(Equal(tc.expectedReady))
+			g.Expect(ready.Reason).To(Equal(tc.expectedReason))
+
+			if tc.expectedEvent == "" {
+				g.Expect(recorder.Events).To(BeEmpty())
+			} else {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring(tc.expectedEvent)))
+			}
+
+			if tc.expectExpiry {
+				g.Expect(updated.Status.SecretExpiryTime).NotTo(BeNil())
+				g.Expect(testutil.ToFloat64(identitySecretExpiring.WithLabelValues("default", "my-identity"))).To(Equal(float64(1)))
+			} else {
+				g.Expect(updated.Status.SecretExpiryTime).To(BeNil())
+			}
+		})
+	}
+}
+
+func TestAzureClusterIdentityReconcilerWithoutClusters(t *testing.T) {
+	g := NewWithT(t)
+	scheme, err := newScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-identity",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:     infrav1.WorkloadIdentity,
+			ClientID: "my-client-id",
+			TenantID: "my-tenant-id",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(identity).Build()
+	checker := &fakeIdentityChecker{}
+	reconciler := &AzureClusterIdentityReconciler{
+		Client:   fakeClient,
+		Recorder: record.NewFakeRecorder(10),
+		checker:  checker,
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "my-identity"}
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// only the token is validated, in the public cloud
+	g.Expect(checker.environments).To(Equal([]string{azureautorest.PublicCloud.Name}))
+	g.Expect(checker.subscriptions).To(BeEmpty())
+
+	updated := &infrav1.AzureClusterIdentity{}
+	g.Expect(fakeClient.Get(context.Background(), key, updated)).To(Succeed())
+	g.Expect(conditions.IsTrue(updated, clusterv1.ReadyCondition)).To(BeTrue())
+
+	// a deleted identity is ignored
+	g.Expect(fakeClient.Delete(context.Background(), updated)).To(Succeed())
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestAzureClusterIdentityReconcilerEventsOnConditionChange(t *testing.T) {
+	g := NewWithT(t)
+	scheme, err := newScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-identity",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:     infrav1.WorkloadIdentity,
+			ClientID: "my-client-id",
+			TenantID: "my-tenant-id",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(identity).Build()
+	recorder := record.NewFakeRecorder(10)
+	checker := &fakeIdentityChecker{tokenErr: errors.New("invalid client secret")}
+	reconciler := &AzureClusterIdentityReconciler{
+		Client:   fakeClient,
+		Recorder: recorder,
+		checker:  checker,
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "my-identity"}
+
+	// the first failed validation is reported
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(infrav1.TokenRequestFailedReason)))
+
+	// the periodic validation failing for the same reason is not
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(BeEmpty())
+
+	// a failure for another reason is
+	checker.tokenErr = nil
+	checker.subscriptionErr = errors.New("subscription not found")
+	cluster := &infrav1.AzureCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-azure-cluster",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterSpec{
+			SubscriptionID: "123",
+			IdentityRef:    &corev1.ObjectReference{Name: "my-identity"},
+		},
+	}
+	g.Expect(fakeClient.Create(context.Background(), cluster)).To(Succeed())
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(recorder.Events).To(Receive(ContainSubstring(infrav1.SubscriptionAccessFailedReason)))
+}
+
+func TestSecondarySubscriptionIDs(t *testing.T) {
+	cases := map[string]struct {
+		spec     infrav1.AzureClusterSpec
+		expected []string
+	}{
+		"cluster without resources in other subscriptions": {
+			spec: infrav1.AzureClusterSpec{
+				SubscriptionID: "123",
+				NetworkSpec: infrav1.NetworkSpec{
+					Vnet: infrav1.VnetSpec{
+						Peerings: infrav1.VnetPeerings{
+							{RemoteVnetName: "vnet1"},
+							{RemoteVnetName: "vnet2", SubscriptionID: "123"},
+						},
+					},
+				},
+			},
+		},
+		"cluster peered with a hub and using its private DNS zone": {
+			spec: infrav1.AzureClusterSpec{
+				SubscriptionID: "123",
+				NetworkSpec: infrav1.NetworkSpec{
+					Vnet: infrav1.VnetSpec{
+						Peerings: infrav1.VnetPeerings{
+							{RemoteVnetName: "vnet1"},
+							{RemoteVnetName: "hub-vnet", SubscriptionID: "456"},
+						},
+					},
+					PrivateDNSZoneSubscriptionID: "789",
+				},
+			},
+			expected: []string{"456", "789"},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(secondarySubscriptionIDs(tc.spec)).To(Equal(tc.expected))
+		})
+	}
+}
+
+func TestSecretToAzureClusterIdentities(t *testing.T) {
+	g := NewWithT(t)
+	scheme, err := newScheme()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	identity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-identity",
+			Namespace: "default",
+		},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			Type:         infrav1.ManualServicePrincipal,
+			ClientSecret: corev1.SecretReference{Name: "my-identity-secret", Namespace: "secrets"},
+		},
+	}
+
+	reconciler := &AzureClusterIdentityReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(identity).Build(),
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-identity-secret", Namespace: "secrets"}}
+	g.Expect(reconciler.secretToAzureClusterIdentities(secret)).To(ConsistOf(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(identity)}))
+
+	other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-identity-secret", Namespace: "default"}}
+	g.Expect(reconciler.secretToAzureClusterIdentities(other)).To(BeEmpty())
+}
+
+func TestClientSecretIndexValue(t *testing.T) {
+	g := NewWithT(t)
+
+	identity := &infrav1.AzureClusterIdentity{
+		Spec: infrav1.AzureClusterIdentitySpec{
+			ClientSecret: corev1.SecretReference{Name: "my-identity-secret", Namespace: "secrets"},
+		},
+	}
+	g.Expect(clientSecretIndexValue(identity)).To(Equal([]string{"secrets/my-identity-secret"}))
+
+	// identities without a secret, e.g. workload identities, are not indexed
+	g.Expect(clientSecretIndexValue(&infrav1.AzureClusterIdentity{})).To(BeEmpty())
+	g.Expect(clientSecretIndexValue(&corev1.Secret{})).To(BeEmpty())
+}
+
+// newIdentityCertificate returns a PEM encoded self-signed certificate expiring at notAfter followed by its private key.
+func newIdentityCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	g := NewWithT(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "service-principal"},
+		NotBefore:    notAfter.Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return append(certPEM, keyPEM...)
+}
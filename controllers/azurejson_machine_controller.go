@@ -36,8 +36,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
@@ -60,15 +62,45 @@ func (r *AzureJSONMachineReconciler) SetupWithManager(ctx context.Context, mgr c
 	)
 	defer done()
 
+	// regenerate the azure json secrets when the credentials of the identity of their cluster are rotated
+	identityMapper := identityToAzureClusterObjectsMapper(ctx, r.Client, log, r.azureClusterToAzureMachines)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		For(&infrav1.AzureMachine{}).
 		WithEventFilter(filterUnclonedMachinesPredicate{log: log}).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
 		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &infrav1.AzureClusterIdentity{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
+		Watches(&source.Kind{Type: &infrav1.AzureNamespacedIdentity{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
 		Complete(r)
 }
 
+// azureClusterToAzureMachines returns the requests for the AzureMachines of an AzureCluster which are not cloned from
+// an AzureMachineTemplate, as the azure json secrets of the others are reconciled with their template.
+func (r *AzureJSONMachineReconciler) azureClusterToAzureMachines(ctx context.Context, azureCluster *infrav1.AzureCluster) []ctrl.Request {
+	clusterName, ok := GetOwnerClusterName(azureCluster.ObjectMeta)
+	if !ok {
+		return nil
+	}
+
+	azureMachines := &infrav1.AzureMachineList{}
+	if err := r.List(ctx, azureMachines, client.InNamespace(azureCluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return nil
+	}
+
+	gk := infrav1.GroupVersion.WithKind("AzureMachineTemplate").GroupKind().String()
+	var results []ctrl.Request
+	for _, azureMachine := range azureMachines.Items {
+		if azureMachine.GetAnnotations()[clusterv1.TemplateClonedFromGroupKindAnnotation] == gk {
+			continue
+		}
+		results = append(results, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: azureMachine.Namespace, Name: azureMachine.Name}})
+	}
+	return results
+}
+
 type filterUnclonedMachinesPredicate struct {
 	log logr.Logger
 	predicate.Funcs
@@ -206,6 +238,11 @@ func (r *AzureJSONMachineReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, errors.Wrap(err, "failed to create cloud provider config")
 	}
 
+	if err := storeCloudProviderSecretInKeyVault(ctx, clusterScope, azureMachine.Name, azureMachine.Spec.OSDisk.OSType, newSecret); err != nil {
+		r.Recorder.Eventf(azureMachine, corev1.EventTypeWarning, "Error storing cloud provider secret for AzureMachine in Key Vault", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	if err := reconcileAzureSecret(ctx, r.Client, owner, newSecret, clusterScope.ClusterName()); err != nil {
 		r.Recorder.Eventf(azureMachine, corev1.EventTypeWarning, "Error reconciling cloud provider secret for AzureMachine", err.Error())
 		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile azure secret")
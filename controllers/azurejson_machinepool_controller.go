@@ -27,12 +27,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
@@ -56,14 +59,39 @@ func (r *AzureJSONMachinePoolReconciler) SetupWithManager(ctx context.Context, m
 	)
 	defer done()
 
+	// regenerate the azure json secrets when the credentials of the identity of their cluster are rotated
+	identityMapper := identityToAzureClusterObjectsMapper(ctx, r.Client, log, r.azureClusterToAzureMachinePools)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		For(&expv1.AzureMachinePool{}).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
 		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &infrav1.AzureClusterIdentity{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
+		Watches(&source.Kind{Type: &infrav1.AzureNamespacedIdentity{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
 		Complete(r)
 }
 
+// azureClusterToAzureMachinePools returns the requests for the AzureMachinePools of an AzureCluster.
+func (r *AzureJSONMachinePoolReconciler) azureClusterToAzureMachinePools(ctx context.Context, azureCluster *infrav1.AzureCluster) []ctrl.Request {
+	clusterName, ok := GetOwnerClusterName(azureCluster.ObjectMeta)
+	if !ok {
+		return nil
+	}
+
+	azureMachinePools := &expv1.AzureMachinePoolList{}
+	if err := r.List(ctx, azureMachinePools, client.InNamespace(azureCluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return nil
+	}
+
+	results := make([]ctrl.Request, len(azureMachinePools.Items))
+	for i, azureMachinePool := range azureMachinePools.Items {
+		results[i] = ctrl.Request{NamespacedName: client.ObjectKey{Namespace: azureMachinePool.Namespace, Name: azureMachinePool.Name}}
+	}
+	return results
+}
+
 // Reconcile reconciles the Azure json for AzureMachinePool objects.
 func (r *AzureJSONMachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
@@ -174,6 +202,11 @@ func (r *AzureJSONMachinePoolReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, errors.Wrap(err, "failed to create cloud provider config")
 	}
 
+	if err := storeCloudProviderSecretInKeyVault(ctx, clusterScope, azureMachinePool.Name, azureMachinePool.Spec.Template.OSDisk.OSType, newSecret); err != nil {
+		r.Recorder.Eventf(azureMachinePool, corev1.EventTypeWarning, "Error storing cloud provider secret for AzureMachinePool in Key Vault", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	if err := reconcileAzureSecret(ctx, r.Client, owner, newSecret, clusterScope.ClusterName()); err != nil {
 		r.Recorder.Eventf(azureMachinePool, corev1.EventTypeWarning, "Error reconciling cloud provider secret for AzureMachinePool", err.Error())
 		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile azure secret")
@@ -33,7 +33,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
@@ -56,14 +58,43 @@ func (r *AzureJSONTemplateReconciler) SetupWithManager(ctx context.Context, mgr
 	)
 	defer done()
 
+	// regenerate the azure json secrets when the credentials of the identity of their cluster are rotated
+	identityMapper := identityToAzureClusterObjectsMapper(ctx, r.Client, log, r.azureClusterToAzureMachineTemplates)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(options).
 		For(&infrav1.AzureMachineTemplate{}).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
 		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &infrav1.AzureClusterIdentity{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
+		Watches(&source.Kind{Type: &infrav1.AzureNamespacedIdentity{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(identityMapper)).
 		Complete(r)
 }
 
+// azureClusterToAzureMachineTemplates returns the requests for the AzureMachineTemplates owned by the Cluster of an
+// AzureCluster.
+func (r *AzureJSONTemplateReconciler) azureClusterToAzureMachineTemplates(ctx context.Context, azureCluster *infrav1.AzureCluster) []ctrl.Request {
+	clusterName, ok := GetOwnerClusterName(azureCluster.ObjectMeta)
+	if !ok {
+		return nil
+	}
+
+	azureMachineTemplates := &infrav1.AzureMachineTemplateList{}
+	if err := r.List(ctx, azureMachineTemplates, client.InNamespace(azureCluster.Namespace)); err != nil {
+		return nil
+	}
+
+	var results []ctrl.Request
+	for _, azureMachineTemplate := range azureMachineTemplates.Items {
+		if ownerName, ok := GetOwnerClusterName(azureMachineTemplate.ObjectMeta); !ok || ownerName != clusterName {
+			continue
+		}
+		results = append(results, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: azureMachineTemplate.Namespace, Name: azureMachineTemplate.Name}})
+	}
+	return results
+}
+
 // Reconcile reconciles Azure json secrets for Azure machine templates.
 func (r *AzureJSONTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
 	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
@@ -165,6 +196,11 @@ func (r *AzureJSONTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, errors.Wrap(err, "failed to create cloud provider config")
 	}
 
+	if err := storeCloudProviderSecretInKeyVault(ctx, clusterScope, azureMachineTemplate.Name, azureMachineTemplate.Spec.Template.Spec.OSDisk.OSType, newSecret); err != nil {
+		r.Recorder.Eventf(azureMachineTemplate, corev1.EventTypeWarning, "Error storing cloud provider secret for AzureMachineTemplate in Key Vault", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	if err := reconcileAzureSecret(ctx, r.Client, owner, newSecret, clusterScope.ClusterName()); err != nil {
 		r.Recorder.Eventf(azureMachineTemplate, corev1.EventTypeWarning, "Error reconciling cloud provider secret for AzureMachineTemplate", err.Error())
 		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile azure secret")
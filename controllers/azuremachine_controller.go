@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
@@ -128,9 +129,10 @@ func (amr *AzureMachineReconciler) SetupWithManager(ctx context.Context, mgr ctr
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachines/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch
 
 // Reconcile idempotently gets, creates, and updates a machine.
 func (amr *AzureMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
@@ -210,10 +212,11 @@ func (amr *AzureMachineReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Create the machine scope
 	machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
-		Client:       amr.Client,
-		Machine:      machine,
-		AzureMachine: azureMachine,
-		ClusterScope: clusterScope,
+		Client:        amr.Client,
+		Machine:       machine,
+		AzureMachine:  azureMachine,
+		ClusterScope:  clusterScope,
+		KeyVaultScope: clusterScope,
 	})
 	if err != nil {
 		amr.Recorder.Eventf(azureMachine, corev1.EventTypeWarning, "Error creating the machine scope", err.Error())
@@ -268,6 +271,16 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 		return reconcile.Result{}, nil
 	}
 
+	// The identity of an AzureMachine is immutable, so a machine which cannot fetch its secrets has to be replaced.
+	if err := machineScope.ValidateKeyVaultIdentity(); err != nil {
+		amr.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "InvalidIdentity", err.Error())
+		log.Error(err, "Invalid identity for the Key Vault of the cluster")
+		machineScope.SetFailureReason(capierrors.InvalidConfigurationMachineError)
+		machineScope.SetFailureMessage(err)
+		machineScope.SetNotReady()
+		return reconcile.Result{}, nil
+	}
+
 	var reconcileError azure.ReconcileError
 
 	// Initialize the cache to be used by the AzureMachine services.
@@ -306,6 +319,9 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 			if reconcileError.IsTerminal() {
 				amr.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "ReconcileError", errors.Wrapf(err, "failed to reconcile AzureMachine").Error())
 				log.Error(err, "failed to reconcile AzureMachine", "name", machineScope.Name())
+				if conditions.GetReason(machineScope.AzureMachine, infrav1.BootstrapSucceededCondition) == infrav1.BootstrapFailedReason {
+					amr.recordSerialConsoleLog(ctx, machineScope, ams)
+				}
 				machineScope.SetFailureReason(capierrors.CreateMachineError)
 				machineScope.SetFailureMessage(err)
 				machineScope.SetNotReady()
@@ -331,6 +347,77 @@ func (amr *AzureMachineReconciler) reconcileNormal(ctx context.Context, machineS
 	return reconcile.Result{}, nil
 }
 
+// recordSerialConsoleLog attaches the tail of the serial console log of a virtual machine that failed to bootstrap to
+// a Warning event, and writes a longer tail to the ConfigMap referenced by its boot diagnostics settings, if any.
+func (amr *AzureMachineReconciler) recordSerialConsoleLog(ctx context.Context, machineScope *scope.MachineScope, ams *azureMachineService) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureMachineReconciler.recordSerialConsoleLog")
+	defer done()
+
+	var boot *infrav1.BootDiagnostics
+	if machineScope.AzureMachine.Spec.Diagnostics != nil {
+		boot = machineScope.AzureMachine.Spec.Diagnostics.Boot
+	}
+	if boot != nil && boot.StorageAccountType == infrav1.DisabledDiagnosticsStorage {
+		return
+	}
+
+	serialLog, err := ams.SerialConsoleLog(ctx)
+	if err != nil {
+		log.Error(err, "failed to get serial console log", "name", machineScope.Name())
+		return
+	}
+
+	amr.Recorder.Eventf(machineScope.AzureMachine, corev1.EventTypeWarning, "BootstrapFailed", "Tail of the serial console log:\n%s",
+		tailLines(serialLog, serialConsoleLogEventLines, serialConsoleLogEventBytes))
+
+	if boot == nil || boot.SerialConsoleLogConfigMapName == "" {
+		return
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      boot.SerialConsoleLogConfigMapName,
+			Namespace: machineScope.Namespace(),
+		},
+	}
+	if _, err := controllerutil.CreateOrPatch(ctx, amr.Client, configMap, func() error {
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[machineScope.Name()] = tailLines(serialLog, serialConsoleLogConfigMapLines, serialConsoleLogConfigMapBytes)
+		return nil
+	}); err != nil {
+		log.Error(err, "failed to write serial console log to ConfigMap", "name", machineScope.Name(), "configMap", boot.SerialConsoleLogConfigMapName)
+	}
+}
+
+// deleteSerialConsoleLog removes the serial console log of the machine from the ConfigMap referenced by its boot
+// diagnostics settings, so that the ConfigMap shared by the machines does not keep the logs of deleted machines.
+func (amr *AzureMachineReconciler) deleteSerialConsoleLog(ctx context.Context, machineScope *scope.MachineScope) error {
+	diagnostics := machineScope.AzureMachine.Spec.Diagnostics
+	if diagnostics == nil || diagnostics.Boot == nil || diagnostics.Boot.SerialConsoleLogConfigMapName == "" {
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: machineScope.Namespace(), Name: diagnostics.Boot.SerialConsoleLogConfigMapName}
+	if err := amr.Client.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get serial console log ConfigMap %s", key.Name)
+	}
+	if _, ok := configMap.Data[machineScope.Name()]; !ok {
+		return nil
+	}
+
+	patch := client.MergeFrom(configMap.DeepCopy())
+	delete(configMap.Data, machineScope.Name())
+	if err := amr.Client.Patch(ctx, configMap, patch); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to remove serial console log from ConfigMap %s", key.Name)
+	}
+	return nil
+}
+
 func (amr *AzureMachineReconciler) reconcileDelete(ctx context.Context, machineScope *scope.MachineScope, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureMachineReconciler.reconcileDelete")
 	defer done()
@@ -369,6 +456,10 @@ func (amr *AzureMachineReconciler) reconcileDelete(ctx context.Context, machineS
 		log.Info("Skipping AzureMachine Deletion; will delete whole resource group.")
 	}
 
+	if err := amr.deleteSerialConsoleLog(ctx, machineScope); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// we're done deleting this AzureMachine so remove the finalizer.
 	log.Info("Removing finalizer from AzureMachine")
 	controllerutil.RemoveFinalizer(machineScope.AzureMachine, infrav1.MachineFinalizer)
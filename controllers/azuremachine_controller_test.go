@@ -18,17 +18,22 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/Azure/go-autorest/autorest"
+	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/virtualmachines/mock_virtualmachines"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -191,6 +196,256 @@ func TestConditions(t *testing.T) {
 	}
 }
 
+func TestRecordSerialConsoleLog(t *testing.T) {
+	g := NewWithT(t)
+	scheme := setupScheme(g)
+
+	testcases := []struct {
+		name              string
+		diagnostics       *infrav1.Diagnostics
+		expect            func(m *mock_virtualmachines.MockClientMockRecorder)
+		expectedEvent     string
+		expectedConfigMap map[string]string
+	}{
+		{
+			name: "attach the tail of the serial console log to an event",
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {
+				m.GetSerialConsoleLog(gomock.Any(), "my-rg", "my-machine").Return([]byte("booting\ncloud-init failed\n"), nil)
+			},
+			expectedEvent: "Warning BootstrapFailed Tail of the serial console log:\nbooting\ncloud-init failed",
+		},
+		{
+			name: "write the serial console log to a ConfigMap",
+			diagnostics: &infrav1.Diagnostics{
+				Boot: &infrav1.BootDiagnostics{
+					StorageAccountType:            infrav1.ManagedDiagnosticsStorage,
+					SerialConsoleLogConfigMapName: "serial-console-logs",
+				},
+			},
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {
+				m.GetSerialConsoleLog(gomock.Any(), "my-rg", "my-machine").Return([]byte("booting\r\ncloud-init failed\r\n"), nil)
+			},
+			expectedEvent:     "Warning BootstrapFailed Tail of the serial console log:\nbooting\ncloud-init failed",
+			expectedConfigMap: map[string]string{"my-machine": "booting\ncloud-init failed"},
+		},
+		{
+			name: "no event when the serial console log cannot be retrieved",
+			diagnostics: &infrav1.Diagnostics{
+				Boot: &infrav1.BootDiagnostics{
+					StorageAccountType:            infrav1.ManagedDiagnosticsStorage,
+					SerialConsoleLogConfigMapName: "serial-console-logs",
+				},
+			},
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {
+				m.GetSerialConsoleLog(gomock.Any(), "my-rg", "my-machine").Return(nil, errors.New("boot diagnostics are not enabled"))
+			},
+		},
+		{
+			name: "no serial console log when boot diagnostics are disabled",
+			diagnostics: &infrav1.Diagnostics{
+				Boot: &infrav1.BootDiagnostics{
+					StorageAccountType: infrav1.DisabledDiagnosticsStorage,
+				},
+			},
+			expect: func(m *mock_virtualmachines.MockClientMockRecorder) {},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			vmClientMock := mock_virtualmachines.NewMockClient(mockCtrl)
+			tc.expect(vmClientMock.EXPECT())
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cluster",
+					Namespace: "default",
+				},
+			}
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-azure-cluster",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureClusterSpec{
+					ResourceGroup:  "my-rg",
+					SubscriptionID: "123",
+				},
+			}
+			machine := newMachine("my-cluster", "my-machine")
+			azureMachine := &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-machine",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureMachineSpec{
+					Diagnostics: tc.diagnostics,
+				},
+			}
+			client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cluster, azureCluster, machine, azureMachine).Build()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := NewAzureMachineReconciler(client, recorder, reconciler.DefaultLoopTimeout, "")
+
+			clusterScope, err := scope.NewClusterScope(context.TODO(), scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					Authorizer: autorest.NullAuthorizer{},
+				},
+				Client:       client,
+				Cluster:      cluster,
+				AzureCluster: azureCluster,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
+				Client:       client,
+				ClusterScope: clusterScope,
+				Machine:      machine,
+				AzureMachine: azureMachine,
+				Cache:        &scope.MachineCache{},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			reconciler.recordSerialConsoleLog(context.TODO(), machineScope, &azureMachineService{
+				scope:    machineScope,
+				vmClient: vmClientMock,
+			})
+
+			if tc.expectedEvent != "" {
+				g.Expect(recorder.Events).To(Receive(Equal(tc.expectedEvent)))
+			}
+			g.Expect(recorder.Events).NotTo(Receive())
+
+			configMap := &corev1.ConfigMap{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "serial-console-logs"}, configMap)
+			if tc.expectedConfigMap != nil {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(configMap.Data).To(Equal(tc.expectedConfigMap))
+			} else {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+	}
+}
+
+func TestDeleteSerialConsoleLog(t *testing.T) {
+	g := NewWithT(t)
+	scheme := setupScheme(g)
+
+	withConfigMap := &infrav1.Diagnostics{
+		Boot: &infrav1.BootDiagnostics{
+			StorageAccountType:            infrav1.ManagedDiagnosticsStorage,
+			SerialConsoleLogConfigMapName: "serial-console-logs",
+		},
+	}
+
+	testcases := []struct {
+		name              string
+		diagnostics       *infrav1.Diagnostics
+		configMapData     map[string]string
+		expectedConfigMap map[string]string
+	}{
+		{
+			name:              "remove the serial console log of the machine from the ConfigMap",
+			diagnostics:       withConfigMap,
+			configMapData:     map[string]string{"my-machine": "cloud-init failed", "other-machine": "cloud-init failed"},
+			expectedConfigMap: map[string]string{"other-machine": "cloud-init failed"},
+		},
+		{
+			name:              "ConfigMap without the serial console log of the machine is left alone",
+			diagnostics:       withConfigMap,
+			configMapData:     map[string]string{"other-machine": "cloud-init failed"},
+			expectedConfigMap: map[string]string{"other-machine": "cloud-init failed"},
+		},
+		{
+			name:        "missing ConfigMap is ignored",
+			diagnostics: withConfigMap,
+		},
+		{
+			name:              "ConfigMap not referenced by the machine is left alone",
+			configMapData:     map[string]string{"my-machine": "cloud-init failed"},
+			expectedConfigMap: map[string]string{"my-machine": "cloud-init failed"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-cluster",
+					Namespace: "default",
+				},
+			}
+			azureCluster := &infrav1.AzureCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-azure-cluster",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureClusterSpec{
+					ResourceGroup:  "my-rg",
+					SubscriptionID: "123",
+				},
+			}
+			machine := newMachine("my-cluster", "my-machine")
+			azureMachine := &infrav1.AzureMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-machine",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureMachineSpec{
+					Diagnostics: tc.diagnostics,
+				},
+			}
+			objects := []runtime.Object{cluster, azureCluster, machine, azureMachine}
+			if tc.configMapData != nil {
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "serial-console-logs",
+						Namespace: "default",
+					},
+					Data: tc.configMapData,
+				})
+			}
+			client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+			reconciler := NewAzureMachineReconciler(client, record.NewFakeRecorder(10), reconciler.DefaultLoopTimeout, "")
+
+			clusterScope, err := scope.NewClusterScope(context.TODO(), scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					Authorizer: autorest.NullAuthorizer{},
+				},
+				Client:       client,
+				Cluster:      cluster,
+				AzureCluster: azureCluster,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			machineScope, err := scope.NewMachineScope(scope.MachineScopeParams{
+				Client:       client,
+				ClusterScope: clusterScope,
+				Machine:      machine,
+				AzureMachine: azureMachine,
+				Cache:        &scope.MachineCache{},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(reconciler.deleteSerialConsoleLog(context.TODO(), machineScope)).To(Succeed())
+
+			configMap := &corev1.ConfigMap{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "serial-console-logs"}, configMap)
+			if tc.expectedConfigMap != nil {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(configMap.Data).To(Equal(tc.expectedConfigMap))
+			} else {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+	}
+}
+
 func conditionsMatch(i, j clusterv1.Condition) bool {
 	return i.Type == j.Type &&
 		i.Status == j.Status &&
@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/availabilitysets"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/disks"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/inboundnatrules"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/networkinterfaces"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/publicips"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
@@ -48,7 +49,9 @@ type azureMachineService struct {
 	tagsSvc              azure.Reconciler
 	vmExtensionsSvc      azure.Reconciler
 	availabilitySetsSvc  azure.Reconciler
+	keyVaultSecretsSvc   azure.Reconciler
 	skuCache             *resourceskus.Cache
+	vmClient             virtualmachines.Client
 }
 
 var _ azure.Reconciler = (*azureMachineService)(nil)
@@ -64,14 +67,16 @@ func newAzureMachineService(machineScope *scope.MachineScope) (*azureMachineServ
 		scope:                machineScope,
 		inboundNatRulesSvc:   inboundnatrules.New(machineScope),
 		networkInterfacesSvc: networkinterfaces.New(machineScope, cache),
-		virtualMachinesSvc:   virtualmachines.New(machineScope),
+		virtualMachinesSvc:   virtualmachines.New(machineScope, cache),
 		roleAssignmentsSvc:   roleassignments.New(machineScope),
 		disksSvc:             disks.New(machineScope),
 		publicIPsSvc:         publicips.New(machineScope),
 		tagsSvc:              tags.New(machineScope),
 		vmExtensionsSvc:      vmextensions.New(machineScope),
 		availabilitySetsSvc:  availabilitysets.New(machineScope, cache),
+		keyVaultSecretsSvc:   keyvaults.NewSecretsService(machineScope),
 		skuCache:             cache,
+		vmClient:             virtualmachines.NewClient(machineScope),
 	}, nil
 }
 
@@ -100,6 +105,10 @@ func (s *azureMachineService) Reconcile(ctx context.Context) error {
 		return errors.Wrap(err, "failed to create availability set")
 	}
 
+	if err := s.keyVaultSecretsSvc.Reconcile(ctx); err != nil {
+		return errors.Wrap(err, "failed to create Key Vault secrets")
+	}
+
 	if err := s.virtualMachinesSvc.Reconcile(ctx); err != nil {
 		return errors.Wrap(err, "failed to create virtual machine")
 	}
@@ -119,6 +128,14 @@ func (s *azureMachineService) Reconcile(ctx context.Context) error {
 	return nil
 }
 
+// SerialConsoleLog returns the serial console log of the virtual machine.
+func (s *azureMachineService) SerialConsoleLog(ctx context.Context) ([]byte, error) {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachineService.SerialConsoleLog")
+	defer done()
+
+	return s.vmClient.GetSerialConsoleLog(ctx, s.scope.ResourceGroup(), s.scope.Name())
+}
+
 // Delete deletes all the services in a predetermined order.
 func (s *azureMachineService) Delete(ctx context.Context) error {
 	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.azureMachineService.Delete")
@@ -128,6 +145,14 @@ func (s *azureMachineService) Delete(ctx context.Context) error {
 		return errors.Wrap(err, "failed to delete machine")
 	}
 
+	if err := s.roleAssignmentsSvc.Delete(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete role assignments")
+	}
+
+	if err := s.keyVaultSecretsSvc.Delete(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete Key Vault secrets")
+	}
+
 	if err := s.networkInterfacesSvc.Delete(ctx); err != nil {
 		return errors.Wrap(err, "failed to delete network interface")
 	}
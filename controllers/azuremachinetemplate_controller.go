@@ -0,0 +1,234 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
+	"sigs.k8s.io/cluster-api-provider-azure/util/tele"
+)
+
+// AzureMachineTemplateReconciler reconciles the status of AzureMachineTemplate objects, which describes the nodes
+// created from a template so that the cluster autoscaler can scale a MachineDeployment up from zero replicas.
+type AzureMachineTemplateReconciler struct {
+	client.Client
+	Recorder         record.EventRecorder
+	ReconcileTimeout time.Duration
+	WatchFilterValue string
+}
+
+// SetupWithManager initializes this controller with a manager.
+func (r *AzureMachineTemplateReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	_, log, done := tele.StartSpanWithLogger(ctx,
+		"controllers.AzureMachineTemplateReconciler.SetupWithManager",
+	)
+	defer done()
+
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(options).
+		For(&infrav1.AzureMachineTemplate{}).
+		// the bootstrap config template of a MachineDeployment sets the labels and taints of its nodes
+		Watches(&source.Kind{Type: &clusterv1.MachineDeployment{}}, handler.EnqueueRequestsFromMapFunc(machineDeploymentToAzureMachineTemplate)).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(log, r.WatchFilterValue)).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinetemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=azuremachinetemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kubeadmconfigtemplates,verbs=get;list;watch
+
+// Reconcile records the capacity, labels and taints of the nodes created from an AzureMachineTemplate in its status.
+func (r *AzureMachineTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultedLoopTimeout(r.ReconcileTimeout))
+	defer cancel()
+
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureMachineTemplateReconciler.Reconcile",
+		tele.KVP("namespace", req.Namespace),
+		tele.KVP("name", req.Name),
+		tele.KVP("kind", "AzureMachineTemplate"),
+	)
+	defer done()
+
+	azureMachineTemplate := &infrav1.AzureMachineTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, azureMachineTemplate); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("object was not found")
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, azureMachineTemplate.ObjectMeta)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if cluster == nil {
+		log.Info("Cluster Controller has not yet set OwnerRef")
+		return reconcile.Result{}, nil
+	}
+
+	log = log.WithValues("cluster", cluster.Name)
+
+	if annotations.IsPaused(cluster, azureMachineTemplate) {
+		log.Info("AzureMachineTemplate or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// only look at azure clusters
+	if cluster.Spec.InfrastructureRef == nil || cluster.Spec.InfrastructureRef.Kind != "AzureCluster" {
+		log.Info("infra ref was not an AzureCluster")
+		return ctrl.Result{}, nil
+	}
+
+	azureCluster := &infrav1.AzureCluster{}
+	azureClusterName := types.NamespacedName{
+		Namespace: req.Namespace,
+		Name:      cluster.Spec.InfrastructureRef.Name,
+	}
+	if err := r.Get(ctx, azureClusterName, azureCluster); err != nil {
+		log.Error(err, "failed to fetch AzureCluster")
+		return reconcile.Result{}, err
+	}
+
+	clusterScope, err := scope.NewClusterScope(ctx, scope.ClusterScopeParams{
+		Client:       r.Client,
+		Cluster:      cluster,
+		AzureCluster: azureCluster,
+	})
+	if err != nil {
+		return reconcile.Result{}, errors.Errorf("failed to create scope: %+v", err)
+	}
+
+	skuCache, err := resourceskus.GetCache(clusterScope, clusterScope.Location())
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to create a NewCache")
+	}
+
+	spec := azureMachineTemplate.Spec.Template.Spec
+	sku, err := skuCache.Get(ctx, spec.VMSize, resourceskus.VirtualMachines)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrapf(err, "failed to get SKU %s", spec.VMSize)
+	}
+
+	patchHelper, err := patch.NewHelper(azureMachineTemplate, r.Client)
+	if err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+
+	if err := r.setNodeCapacity(ctx, azureMachineTemplate, cluster.Name, sku, clusterScope.Location()); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := patchHelper.Patch(ctx, azureMachineTemplate); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "failed to patch AzureMachineTemplate status")
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// setNodeCapacity records the capacity, labels and taints of the nodes created from the AzureMachineTemplate in its
+// status.
+func (r *AzureMachineTemplateReconciler) setNodeCapacity(ctx context.Context, azureMachineTemplate *infrav1.AzureMachineTemplate, clusterName string, sku resourceskus.SKU, location string) error {
+	spec := azureMachineTemplate.Spec.Template.Spec
+	capacity, err := scope.NodeCapacity(sku, spec.OSDisk)
+	if err != nil {
+		return errors.Wrap(err, "failed to get node capacity")
+	}
+
+	var zone string
+	if spec.FailureDomain != nil {
+		zone = *spec.FailureDomain
+	}
+	labels := scope.NodeLabels(sku, spec.OSDisk.OSType, location, zone)
+
+	configRef, err := r.getBootstrapConfigRef(ctx, azureMachineTemplate, clusterName)
+	if err != nil {
+		return err
+	}
+	bootstrapLabels, taints, err := scope.BootstrapNodeRegistration(ctx, r.Client, azureMachineTemplate.Namespace, configRef)
+	if err != nil {
+		return err
+	}
+	scope.AddBootstrapNodeLabels(labels, bootstrapLabels)
+
+	azureMachineTemplate.Status.Capacity = capacity
+	azureMachineTemplate.Status.NodeLabels = labels
+	azureMachineTemplate.Status.NodeTaints = taints
+	return nil
+}
+
+// getBootstrapConfigRef returns the bootstrap config template of the MachineDeployments of the cluster created from
+// the AzureMachineTemplate, taking the first one by name, or nil when no MachineDeployment uses the template.
+func (r *AzureMachineTemplateReconciler) getBootstrapConfigRef(ctx context.Context, azureMachineTemplate *infrav1.AzureMachineTemplate, clusterName string) (*corev1.ObjectReference, error) {
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := r.List(ctx, machineDeployments, client.InNamespace(azureMachineTemplate.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: clusterName}); err != nil {
+		return nil, errors.Wrap(err, "failed to list MachineDeployments")
+	}
+
+	sort.Slice(machineDeployments.Items, func(i, j int) bool {
+		return machineDeployments.Items[i].Name < machineDeployments.Items[j].Name
+	})
+	for _, machineDeployment := range machineDeployments.Items {
+		infraRef := machineDeployment.Spec.Template.Spec.InfrastructureRef
+		if infraRef.Kind == "AzureMachineTemplate" && infraRef.Name == azureMachineTemplate.Name {
+			return machineDeployment.Spec.Template.Spec.Bootstrap.ConfigRef, nil
+		}
+	}
+	return nil, nil
+}
+
+// machineDeploymentToAzureMachineTemplate maps a MachineDeployment to the AzureMachineTemplate it creates machines
+// from.
+func machineDeploymentToAzureMachineTemplate(o client.Object) []reconcile.Request {
+	machineDeployment, ok := o.(*clusterv1.MachineDeployment)
+	if !ok {
+		return nil
+	}
+
+	infraRef := machineDeployment.Spec.Template.Spec.InfrastructureRef
+	if infraRef.Kind != "AzureMachineTemplate" || infraRef.GroupVersionKind().Group != infrav1.GroupVersion.Group {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: client.ObjectKey{Namespace: machineDeployment.Namespace, Name: infraRef.Name},
+		},
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2021-04-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/resourceskus"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAzureMachineTemplateReconciler_setNodeCapacity(t *testing.T) {
+	scheme, err := newScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bootstrapv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name          string
+		failureDomain *string
+		diskSizeGB    *int32
+		capabilities  map[string]string
+		objects       []client.Object
+		wantCapacity  corev1.ResourceList
+		wantLabels    map[string]string
+		wantTaints    []corev1.Taint
+		wantErr       string
+	}{
+		{
+			name:       "capacity and well-known labels of a template without MachineDeployment",
+			diskSizeGB: to.Int32Ptr(128),
+			wantCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:              resource.MustParse("2"),
+				corev1.ResourceMemory:           resource.MustParse("8Gi"),
+				corev1.ResourcePods:             resource.MustParse("110"),
+				corev1.ResourceEphemeralStorage: resource.MustParse("128Gi"),
+			},
+			wantLabels: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+				corev1.LabelOSStable:           "linux",
+				corev1.LabelArchStable:         "amd64",
+				corev1.LabelTopologyRegion:     "eastus",
+			},
+		},
+		{
+			name:          "zone label of a template in a failure domain",
+			failureDomain: to.StringPtr("2"),
+			wantCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			wantLabels: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+				corev1.LabelOSStable:           "linux",
+				corev1.LabelArchStable:         "amd64",
+				corev1.LabelTopologyRegion:     "eastus",
+				corev1.LabelTopologyZone:       "eastus-2",
+			},
+		},
+		{
+			name: "labels and taints of the KubeadmConfigTemplate of the MachineDeployment",
+			objects: []client.Object{
+				newMachineDeployment("md-other", "other-template", "other-config"),
+				newMachineDeployment("md-0", "my-template", "my-config"),
+				newKubeadmConfigTemplate("other-config", "workload=other", nil),
+				newKubeadmConfigTemplate("my-config", "workload=gpu, kubernetes.io/os=other", []corev1.Taint{
+					{Key: "workload", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				}),
+			},
+			wantCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			wantLabels: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+				corev1.LabelOSStable:           "linux",
+				corev1.LabelArchStable:         "amd64",
+				corev1.LabelTopologyRegion:     "eastus",
+				"workload":                     "gpu",
+			},
+			wantTaints: []corev1.Taint{
+				{Key: "workload", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+		{
+			name: "missing KubeadmConfigTemplate of the MachineDeployment",
+			objects: []client.Object{
+				newMachineDeployment("md-0", "my-template", "my-config"),
+			},
+			wantCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			wantLabels: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+				corev1.LabelOSStable:           "linux",
+				corev1.LabelArchStable:         "amd64",
+				corev1.LabelTopologyRegion:     "eastus",
+			},
+		},
+		{
+			name:         "GPUs and arm64 architecture",
+			capabilities: map[string]string{resourceskus.GPUs: "1", resourceskus.CPUArchitectureType: "Arm64"},
+			wantCapacity: corev1.ResourceList{
+				corev1.ResourceCPU:                    resource.MustParse("2"),
+				corev1.ResourceMemory:                 resource.MustParse("8Gi"),
+				corev1.ResourcePods:                   resource.MustParse("110"),
+				corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+			},
+			wantLabels: map[string]string{
+				corev1.LabelInstanceTypeStable: "Standard_D2s_v3",
+				corev1.LabelOSStable:           "linux",
+				corev1.LabelArchStable:         "arm64",
+				corev1.LabelTopologyRegion:     "eastus",
+			},
+		},
+		{
+			name:         "invalid vCPUs",
+			capabilities: map[string]string{resourceskus.VCPUs: "two"},
+			wantErr:      "failed to get node capacity",
+		},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &AzureMachineTemplateReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build(),
+			}
+			azureMachineTemplate := &infrav1.AzureMachineTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "my-template",
+					Namespace: "default",
+				},
+				Spec: infrav1.AzureMachineTemplateSpec{
+					Template: infrav1.AzureMachineTemplateResource{
+						Spec: infrav1.AzureMachineSpec{
+							VMSize:        "Standard_D2s_v3",
+							FailureDomain: tc.failureDomain,
+							OSDisk: infrav1.OSDisk{
+								OSType:     "Linux",
+								DiskSizeGB: tc.diskSizeGB,
+							},
+						},
+					},
+				},
+			}
+
+			capabilities := map[string]string{resourceskus.VCPUs: "2", resourceskus.MemoryGB: "8"}
+			for name, value := range tc.capabilities {
+				capabilities[name] = value
+			}
+			err := r.setNodeCapacity(context.TODO(), azureMachineTemplate, "my-cluster", newTemplateSKU("Standard_D2s_v3", capabilities), "eastus")
+			if tc.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tc.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(azureMachineTemplate.Status.Capacity).To(HaveLen(len(tc.wantCapacity)))
+			for name, quantity := range tc.wantCapacity {
+				g.Expect(azureMachineTemplate.Status.Capacity).To(HaveKey(name))
+				actual := azureMachineTemplate.Status.Capacity[name]
+				g.Expect(actual.Cmp(quantity)).To(BeZero(), "capacity of %s", name)
+			}
+			g.Expect(azureMachineTemplate.Status.NodeLabels).To(Equal(tc.wantLabels))
+			g.Expect(azureMachineTemplate.Status.NodeTaints).To(Equal(tc.wantTaints))
+		})
+	}
+}
+
+func newTemplateSKU(name string, capabilities map[string]string) resourceskus.SKU {
+	skuCapabilities := []compute.ResourceSkuCapabilities{}
+	for capability, value := range capabilities {
+		skuCapabilities = append(skuCapabilities, compute.ResourceSkuCapabilities{
+			Name:  to.StringPtr(capability),
+			Value: to.StringPtr(value),
+		})
+	}
+
+	return resourceskus.SKU{
+		Name:         to.StringPtr(name),
+		Capabilities: &skuCapabilities,
+	}
+}
+
+func newMachineDeployment(name, templateName, configName string) *clusterv1.MachineDeployment {
+	return &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "my-cluster"},
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "my-cluster",
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "my-cluster",
+					Bootstrap: clusterv1.Bootstrap{
+						ConfigRef: &corev1.ObjectReference{
+							APIVersion: bootstrapv1.GroupVersion.String(),
+							Kind:       "KubeadmConfigTemplate",
+							Name:       configName,
+						},
+					},
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: infrav1.GroupVersion.String(),
+						Kind:       "AzureMachineTemplate",
+						Name:       templateName,
+					},
+				},
+			},
+		},
+	}
+}
+
+func newKubeadmConfigTemplate(name, nodeLabels string, taints []corev1.Taint) *bootstrapv1.KubeadmConfigTemplate {
+	return &bootstrapv1.KubeadmConfigTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: bootstrapv1.KubeadmConfigTemplateSpec{
+			Template: bootstrapv1.KubeadmConfigTemplateResource{
+				Spec: bootstrapv1.KubeadmConfigSpec{
+					JoinConfiguration: &bootstrapv1.JoinConfiguration{
+						NodeRegistration: bootstrapv1.NodeRegistrationOptions{
+							KubeletExtraArgs: map[string]string{"node-labels": nodeLabels},
+							Taints:           taints,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -18,8 +18,12 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
@@ -35,6 +39,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/groups"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/keyvaults"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/pkg/coalescing"
 	"sigs.k8s.io/cluster-api-provider-azure/util/reconciler"
@@ -49,6 +54,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
+const (
+	// serialConsoleLogEventLines is the maximum number of lines of the serial console log attached to an event.
+	serialConsoleLogEventLines = 20
+	// serialConsoleLogEventBytes is the maximum size of the serial console log attached to an event.
+	serialConsoleLogEventBytes = 1024
+	// serialConsoleLogConfigMapLines is the maximum number of lines of the serial console log written to a ConfigMap.
+	serialConsoleLogConfigMapLines = 500
+	// serialConsoleLogConfigMapBytes is the maximum size of the serial console log written to a ConfigMap.
+	serialConsoleLogConfigMapBytes = 64 * 1024
+)
+
 const (
 	spIdentityWarning = "You are using Service Principal authentication for Cloud Provider Azure which is less secure than Managed Identity. " +
 		"Your Service Principal credentials will be written to a file on the disk of each VM in order to be accessible by Cloud Provider. " +
@@ -166,8 +182,19 @@ func referSameObject(a, b metav1.OwnerReference) bool {
 	return aGV.Group == bGV.Group && a.Kind == b.Kind && a.Name == b.Name
 }
 
+// CloudProviderConfigScoper describes the cluster the cloud provider config is generated for, and the type of the
+// credentials it is generated from.
+type CloudProviderConfigScoper interface {
+	azure.ClusterScoper
+	CredentialsType() infrav1.IdentityType
+}
+
+// federatedTokenFile is the path the service account token of a workload identity is projected to in the pods of the
+// cloud provider.
+const federatedTokenFile = "/var/run/secrets/azure/tokens/azure-identity-token"
+
 // GetCloudProviderSecret returns the required azure json secret for the provided parameters.
-func GetCloudProviderSecret(d azure.ClusterScoper, namespace, name string, owner metav1.OwnerReference, identityType infrav1.VMIdentity, userIdentityID string) (*corev1.Secret, error) {
+func GetCloudProviderSecret(d CloudProviderConfigScoper, namespace, name string, owner metav1.OwnerReference, identityType infrav1.VMIdentity, userIdentityID string) (*corev1.Secret, error) {
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
@@ -190,7 +217,10 @@ func GetCloudProviderSecret(d azure.ClusterScoper, namespace, name string, owner
 		}
 		controlPlaneConfig, workerNodeConfig = userAssignedIdentityCloudProviderConfig(d, userIdentityID)
 	case infrav1.VMIdentityNone:
-		controlPlaneConfig, workerNodeConfig = newCloudProviderConfig(d)
+		var err error
+		if controlPlaneConfig, workerNodeConfig, err = clusterIdentityCloudProviderConfig(d); err != nil {
+			return nil, err
+		}
 	}
 
 	controlPlaneData, err := json.MarshalIndent(controlPlaneConfig, "", "    ")
@@ -208,10 +238,50 @@ func GetCloudProviderSecret(d azure.ClusterScoper, namespace, name string, owner
 		// added for backwards compatibility
 		"azure.json": controlPlaneData,
 	}
+	secret.Annotations = map[string]string{
+		infrav1.CloudProviderConfigHashAnnotation: cloudProviderConfigHash(secret.Data),
+	}
 
 	return secret, nil
 }
 
+// cloudProviderConfigHash returns the hash of the data of an azure json secret.
+func cloudProviderConfigHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(data[key])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// clusterIdentityCloudProviderConfig returns the cloud provider configs authenticating with the credentials of the
+// cluster identity. Workload identities authenticate with the service account token projected in the pods of the
+// cloud provider, while the certificate of a service principal is not available on the machines, which must then use
+// a managed identity.
+func clusterIdentityCloudProviderConfig(d CloudProviderConfigScoper) (*CloudProviderConfig, *CloudProviderConfig, error) {
+	controlPlaneConfig, workerConfig := newCloudProviderConfig(d)
+	switch d.CredentialsType() {
+	case infrav1.ServicePrincipalCertificate:
+		return nil, nil, errors.Errorf("the cloud provider cannot authenticate with the certificate of the identity of cluster %s: use a SystemAssigned or UserAssigned identity", d.ClusterName())
+	case infrav1.WorkloadIdentity:
+		for _, config := range []*CloudProviderConfig{controlPlaneConfig, workerConfig} {
+			config.AadClientSecret = ""
+			config.AadFederatedTokenFile = federatedTokenFile
+			config.UseFederatedWorkloadIdentityExtension = true
+		}
+	}
+	return controlPlaneConfig, workerConfig, nil
+}
+
 func systemAssignedIdentityCloudProviderConfig(d azure.ClusterScoper) (*CloudProviderConfig, *CloudProviderConfig) {
 	controlPlaneConfig, workerConfig := newCloudProviderConfig(d)
 	controlPlaneConfig.AadClientID = ""
@@ -352,25 +422,27 @@ func toCloudProviderRateLimitConfig(source infrav1.RateLimitConfig) *RateLimitCo
 
 // CloudProviderConfig is an abbreviated version of the same struct in k/k.
 type CloudProviderConfig struct {
-	Cloud                        string `json:"cloud"`
-	TenantID                     string `json:"tenantId"`
-	SubscriptionID               string `json:"subscriptionId"`
-	AadClientID                  string `json:"aadClientId,omitempty"`
-	AadClientSecret              string `json:"aadClientSecret,omitempty"`
-	ResourceGroup                string `json:"resourceGroup"`
-	SecurityGroupName            string `json:"securityGroupName"`
-	SecurityGroupResourceGroup   string `json:"securityGroupResourceGroup"`
-	Location                     string `json:"location"`
-	VMType                       string `json:"vmType"`
-	VnetName                     string `json:"vnetName"`
-	VnetResourceGroup            string `json:"vnetResourceGroup"`
-	SubnetName                   string `json:"subnetName"`
-	RouteTableName               string `json:"routeTableName"`
-	LoadBalancerSku              string `json:"loadBalancerSku"`
-	MaximumLoadBalancerRuleCount int    `json:"maximumLoadBalancerRuleCount"`
-	UseManagedIdentityExtension  bool   `json:"useManagedIdentityExtension"`
-	UseInstanceMetadata          bool   `json:"useInstanceMetadata"`
-	UserAssignedIdentityID       string `json:"userAssignedIdentityId,omitempty"`
+	Cloud                                 string `json:"cloud"`
+	TenantID                              string `json:"tenantId"`
+	SubscriptionID                        string `json:"subscriptionId"`
+	AadClientID                           string `json:"aadClientId,omitempty"`
+	AadClientSecret                       string `json:"aadClientSecret,omitempty"`
+	AadFederatedTokenFile                 string `json:"aadFederatedTokenFile,omitempty"`
+	ResourceGroup                         string `json:"resourceGroup"`
+	SecurityGroupName                     string `json:"securityGroupName"`
+	SecurityGroupResourceGroup            string `json:"securityGroupResourceGroup"`
+	Location                              string `json:"location"`
+	VMType                                string `json:"vmType"`
+	VnetName                              string `json:"vnetName"`
+	VnetResourceGroup                     string `json:"vnetResourceGroup"`
+	SubnetName                            string `json:"subnetName"`
+	RouteTableName                        string `json:"routeTableName"`
+	LoadBalancerSku                       string `json:"loadBalancerSku"`
+	MaximumLoadBalancerRuleCount          int    `json:"maximumLoadBalancerRuleCount"`
+	UseManagedIdentityExtension           bool   `json:"useManagedIdentityExtension"`
+	UseInstanceMetadata                   bool   `json:"useInstanceMetadata"`
+	UserAssignedIdentityID                string `json:"userAssignedIdentityId,omitempty"`
+	UseFederatedWorkloadIdentityExtension bool   `json:"useFederatedWorkloadIdentityExtension,omitempty"`
 	CloudProviderRateLimitConfig
 	BackOffConfig
 }
@@ -432,6 +504,77 @@ func toCloudProviderBackOffConfig(source infrav1.BackOffConfig) BackOffConfig {
 	return backOffConfig
 }
 
+// cloudProviderConfigKeyVaultScope is the scope of a cluster storing the cloud provider config of its machines in its
+// Key Vault.
+type cloudProviderConfigKeyVaultScope interface {
+	azure.AsyncStatusUpdater
+	azure.KeyVaultDescriber
+	ResourceGroup() string
+}
+
+// cloudProviderConfigSecretsScope is the scope of the Key Vault secrets storing the cloud provider config of the
+// machines of an AzureMachine, AzureMachineTemplate or AzureMachinePool.
+type cloudProviderConfigSecretsScope struct {
+	cloudProviderConfigKeyVaultScope
+	specs []azure.ResourceSpecGetter
+}
+
+// KeyVaultSecretSpecs returns the specs of the secrets storing the cloud provider config.
+func (s *cloudProviderConfigSecretsScope) KeyVaultSecretSpecs() []azure.ResourceSpecGetter {
+	return s.specs
+}
+
+// storeCloudProviderSecretInKeyVault stores the cloud provider config of the control plane and worker nodes of an
+// AzureMachine, AzureMachineTemplate or AzureMachinePool in the Key Vault of the cluster when its machines fetch it from
+// there, and then removes the client secret from the azure json secret.
+func storeCloudProviderSecretInKeyVault(ctx context.Context, clusterScope cloudProviderConfigKeyVaultScope, ownerName string, osType string, secret *corev1.Secret) error {
+	ctx, _, done := tele.StartSpanWithLogger(ctx, "controllers.storeCloudProviderSecretInKeyVault")
+	defer done()
+
+	keyVault := clusterScope.KeyVault()
+	// machines running Windows keep getting their cloud provider config from the azure json secret.
+	if keyVault == nil || !keyVault.CloudProviderCredentials || osType == azure.WindowsOS {
+		return nil
+	}
+
+	secretsScope := &cloudProviderConfigSecretsScope{cloudProviderConfigKeyVaultScope: clusterScope}
+	for _, controlPlane := range []bool{true, false} {
+		key := "worker-node-azure.json"
+		if controlPlane {
+			key = "control-plane-azure.json"
+		}
+		secretsScope.specs = append(secretsScope.specs, &keyvaults.SecretSpec{
+			Name:          keyvaults.CloudProviderConfigSecretName(ownerName, controlPlane),
+			KeyVaultName:  keyVault.Name,
+			ResourceGroup: clusterScope.ResourceGroup(),
+			Value:         string(secret.Data[key]),
+			ContentType:   keyvaults.CloudProviderConfigContentType,
+		})
+	}
+	if err := keyvaults.NewSecretsService(secretsScope).Reconcile(ctx); err != nil {
+		return errors.Wrap(err, "failed to store cloud provider config in Key Vault")
+	}
+
+	return redactCloudProviderSecret(secret)
+}
+
+// redactCloudProviderSecret removes the client secret from the cloud provider config of an azure json secret.
+func redactCloudProviderSecret(secret *corev1.Secret) error {
+	for key, data := range secret.Data {
+		config := &CloudProviderConfig{}
+		if err := json.Unmarshal(data, config); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal %s", key)
+		}
+		config.AadClientSecret = ""
+		redacted, err := json.MarshalIndent(config, "", "    ")
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal %s", key)
+		}
+		secret.Data[key] = redacted
+	}
+	return nil
+}
+
 func reconcileAzureSecret(ctx context.Context, kubeclient client.Client, owner metav1.OwnerReference, new *corev1.Secret, clusterName string) error {
 	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.reconcileAzureSecret")
 	defer done()
@@ -471,7 +614,9 @@ func reconcileAzureSecret(ctx context.Context, kubeclient client.Client, owner m
 		}
 	}
 
-	hasData := equality.Semantic.DeepEqual(old.Data, new.Data)
+	// the data of a secret redacted because the cloud provider config is stored in a Key Vault only changes with its hash
+	newHash := new.Annotations[infrav1.CloudProviderConfigHashAnnotation]
+	hasData := equality.Semantic.DeepEqual(old.Data, new.Data) && old.Annotations[infrav1.CloudProviderConfigHashAnnotation] == newHash
 	if hasData && hasOwner {
 		// no update required
 		log.V(2).Info("returning early from json reconcile, no update needed")
@@ -484,6 +629,12 @@ func reconcileAzureSecret(ctx context.Context, kubeclient client.Client, owner m
 
 	if !hasData {
 		old.Data = new.Data
+		if newHash != "" {
+			if old.Annotations == nil {
+				old.Annotations = map[string]string{}
+			}
+			old.Annotations[infrav1.CloudProviderConfigHashAnnotation] = newHash
+		}
 	}
 
 	log.V(2).Info("updating azure json")
@@ -581,19 +732,114 @@ func ShouldDeleteIndividualResources(ctx context.Context, clusterScope *scope.Cl
 	return err != nil || !managed
 }
 
-// GetClusterIdentityFromRef returns the AzureClusterIdentity referenced by the AzureCluster.
+// GetClusterIdentityFromRef returns the AzureClusterIdentity referenced by the AzureCluster. A reference to an
+// AzureNamespacedIdentity resolves to its equivalent AzureClusterIdentity, only allowed in its own namespace.
 func GetClusterIdentityFromRef(ctx context.Context, c client.Client, azureClusterNamespace string, ref *corev1.ObjectReference) (*infrav1.AzureClusterIdentity, error) {
-	identity := &infrav1.AzureClusterIdentity{}
-	if ref != nil {
-		namespace := ref.Namespace
-		if namespace == "" {
-			namespace = azureClusterNamespace
-		}
-		key := client.ObjectKey{Name: ref.Name, Namespace: namespace}
-		if err := c.Get(ctx, key, identity); err != nil {
-			return nil, err
+	return scope.GetClusterIdentityFromRef(ctx, c, azureClusterNamespace, ref)
+}
+
+// azureClusterRequestsFunc returns the requests for the objects of an AzureCluster reconciled by a controller.
+type azureClusterRequestsFunc func(ctx context.Context, azureCluster *infrav1.AzureCluster) []ctrl.Request
+
+// identityToAzureClusterObjectsMapper returns a handler.MapFunc mapping an AzureClusterIdentity, an
+// AzureNamespacedIdentity or the Secret of one of them to the objects of the AzureClusters using the identity, so that
+// the azure json secrets of these objects are regenerated when the credentials of the identity are rotated.
+func identityToAzureClusterObjectsMapper(ctx context.Context, c client.Client, log logr.Logger, toRequests azureClusterRequestsFunc) handler.MapFunc {
+	return func(o client.Object) []ctrl.Request {
+		ctx, cancel := context.WithTimeout(ctx, reconciler.DefaultMappingTimeout)
+		defer cancel()
+
+		identities, err := identitiesForObject(ctx, c, o)
+		if err != nil {
+			log.Error(err, "failed to map object to identities", "namespace", o.GetNamespace(), "name", o.GetName())
+			return nil
+		}
+		if len(identities) == 0 {
+			return nil
+		}
+
+		azureClusters := &infrav1.AzureClusterList{}
+		if err := c.List(ctx, azureClusters); err != nil {
+			log.Error(err, "failed to list AzureClusters")
+			return nil
+		}
+
+		var results []ctrl.Request
+		for i := range azureClusters.Items {
+			azureCluster := &azureClusters.Items[i]
+			if !azureCluster.ObjectMeta.DeletionTimestamp.IsZero() {
+				continue
+			}
+			for _, identity := range identities {
+				if usesIdentity(azureCluster, identity) {
+					results = append(results, toRequests(ctx, azureCluster)...)
+					break
+				}
+			}
 		}
-		return identity, nil
+		return results
 	}
-	return nil, nil
+}
+
+// identitiesForObject returns references to the identities an object is part of: the object itself when it is an
+// AzureClusterIdentity or an AzureNamespacedIdentity, or the identities whose client secret it is when it is a Secret.
+func identitiesForObject(ctx context.Context, c client.Client, o client.Object) ([]corev1.ObjectReference, error) {
+	switch o.(type) {
+	case *infrav1.AzureClusterIdentity:
+		return []corev1.ObjectReference{{Kind: infrav1.AzureClusterIdentityKind, Namespace: o.GetNamespace(), Name: o.GetName()}}, nil
+	case *infrav1.AzureNamespacedIdentity:
+		return []corev1.ObjectReference{{Kind: infrav1.AzureNamespacedIdentityKind, Namespace: o.GetNamespace(), Name: o.GetName()}}, nil
+	case *corev1.Secret:
+	default:
+		return nil, errors.Errorf("expected an identity or a Secret, got %T instead", o)
+	}
+
+	var identities []corev1.ObjectReference
+	clusterIdentities := &infrav1.AzureClusterIdentityList{}
+	if err := c.List(ctx, clusterIdentities); err != nil {
+		return nil, errors.Wrap(err, "failed to list AzureClusterIdentities")
+	}
+	for _, identity := range clusterIdentities.Items {
+		if identity.Spec.ClientSecret.Name == o.GetName() && identity.Spec.ClientSecret.Namespace == o.GetNamespace() {
+			identities = append(identities, corev1.ObjectReference{Kind: infrav1.AzureClusterIdentityKind, Namespace: identity.Namespace, Name: identity.Name})
+		}
+	}
+
+	namespacedIdentities := &infrav1.AzureNamespacedIdentityList{}
+	if err := c.List(ctx, namespacedIdentities, client.InNamespace(o.GetNamespace())); err != nil {
+		return nil, errors.Wrap(err, "failed to list AzureNamespacedIdentities")
+	}
+	for _, identity := range namespacedIdentities.Items {
+		if identity.Spec.ClientSecret.Name == o.GetName() {
+			identities = append(identities, corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Namespace: identity.Namespace, Name: identity.Name})
+		}
+	}
+	return identities, nil
+}
+
+// usesIdentity returns true if the IdentityRef of an AzureCluster resolves to the given identity.
+func usesIdentity(azureCluster *infrav1.AzureCluster, identity corev1.ObjectReference) bool {
+	ref := azureCluster.Spec.IdentityRef
+	if ref == nil || ref.Kind != identity.Kind || ref.Name != identity.Name {
+		return false
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = azureCluster.Namespace
+	}
+	return namespace == identity.Namespace
+}
+
+// tailLines returns at most the last maxLines lines of a log, truncated to its last maxBytes bytes.
+func tailLines(log []byte, maxLines, maxBytes int) string {
+	text := strings.TrimRight(strings.ReplaceAll(string(log), "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	tail := strings.Join(lines, "\n")
+	if len(tail) > maxBytes {
+		tail = tail[len(tail)-maxBytes:]
+	}
+	return strings.ToValidUTF8(tail, "")
 }
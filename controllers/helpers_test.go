@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"testing"
@@ -38,6 +39,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/internal/test/mock_log"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -79,6 +82,91 @@ func TestAzureClusterToAzureMachinesMapper(t *testing.T) {
 	g.Expect(requests).To(HaveLen(2))
 }
 
+func TestIdentityToAzureClusterObjectsMapper(t *testing.T) {
+	g := NewWithT(t)
+	scheme := setupScheme(g)
+
+	newAzureClusterWithIdentity := func(name string, ref *corev1.ObjectReference) *infrav1.AzureCluster {
+		return &infrav1.AzureCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       infrav1.AzureClusterSpec{IdentityRef: ref},
+		}
+	}
+	clusterIdentity := &infrav1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-identity", Namespace: "identities"},
+		Spec: infrav1.AzureClusterIdentitySpec{
+			ClientSecret: corev1.SecretReference{Name: "cluster-identity-secret", Namespace: "identities"},
+		},
+	}
+	namespacedIdentity := &infrav1.AzureNamespacedIdentity{
+		ObjectMeta: metav1.ObjectMeta{Name: "namespaced-identity", Namespace: "default"},
+		Spec: infrav1.AzureNamespacedIdentitySpec{
+			ClientSecret: corev1.LocalObjectReference{Name: "namespaced-identity-secret"},
+		},
+	}
+	initObjects := []runtime.Object{
+		clusterIdentity,
+		namespacedIdentity,
+		newAzureClusterWithIdentity("cluster-identity-user", &corev1.ObjectReference{Kind: infrav1.AzureClusterIdentityKind, Name: "cluster-identity", Namespace: "identities"}),
+		newAzureClusterWithIdentity("namespaced-identity-user", &corev1.ObjectReference{Kind: infrav1.AzureNamespacedIdentityKind, Name: "namespaced-identity"}),
+		newAzureClusterWithIdentity("other-identity-user", &corev1.ObjectReference{Kind: infrav1.AzureClusterIdentityKind, Name: "cluster-identity", Namespace: "default"}),
+		newAzureClusterWithIdentity("no-identity", nil),
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(initObjects...).Build()
+
+	toRequests := func(_ context.Context, azureCluster *infrav1.AzureCluster) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: azureCluster.Namespace, Name: azureCluster.Name}}}
+	}
+	mapper := identityToAzureClusterObjectsMapper(context.Background(), fakeClient, logr.Discard(), toRequests)
+
+	tests := []struct {
+		name     string
+		object   client.Object
+		expected []string
+	}{
+		{
+			name:     "AzureClusterIdentity maps to the AzureClusters referencing it",
+			object:   clusterIdentity,
+			expected: []string{"cluster-identity-user"},
+		},
+		{
+			name:     "AzureNamespacedIdentity maps to the AzureClusters of its namespace referencing it",
+			object:   namespacedIdentity,
+			expected: []string{"namespaced-identity-user"},
+		},
+		{
+			name: "client secret of an AzureClusterIdentity maps to the AzureClusters using the identity",
+			object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-identity-secret", Namespace: "identities"},
+			},
+			expected: []string{"cluster-identity-user"},
+		},
+		{
+			name: "client secret of an AzureNamespacedIdentity maps to the AzureClusters using the identity",
+			object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "namespaced-identity-secret", Namespace: "default"},
+			},
+			expected: []string{"namespaced-identity-user"},
+		},
+		{
+			name: "unrelated secret is not mapped",
+			object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-identity-secret", Namespace: "default"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			var names []string
+			for _, request := range mapper(tc.object) {
+				names = append(names, request.Name)
+			}
+			g.Expect(names).To(Equal(tc.expected))
+		})
+	}
+}
+
 func TestGetCloudProviderConfig(t *testing.T) {
 	g := NewWithT(t)
 	scheme := runtime.NewScheme()
@@ -181,6 +269,99 @@ func TestGetCloudProviderConfig(t *testing.T) {
 	}
 }
 
+// credentialsTypeClusterScope overrides the type of the credentials of a cluster scope.
+type credentialsTypeClusterScope struct {
+	*scope.ClusterScope
+	credentialsType infrav1.IdentityType
+}
+
+func (s *credentialsTypeClusterScope) CredentialsType() infrav1.IdentityType {
+	return s.credentialsType
+}
+
+func TestGetCloudProviderSecretClusterIdentityCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+	_ = infrav1.AddToScheme(scheme)
+
+	cluster := newCluster("foo")
+	cluster.Default()
+	azureCluster := newAzureCluster("bar")
+	azureCluster.Default()
+
+	os.Setenv(auth.ClientID, "fooClient")
+	os.Setenv(auth.ClientSecret, "fooSecret")
+	os.Setenv(auth.TenantID, "fooTenant")
+
+	cases := map[string]struct {
+		credentialsType infrav1.IdentityType
+		identityType    infrav1.VMIdentity
+		expectedError   string
+		verify          func(g *WithT, config *CloudProviderConfig)
+	}{
+		"service principal authenticates with its client secret": {
+			credentialsType: infrav1.ServicePrincipal,
+			identityType:    infrav1.VMIdentityNone,
+			verify: func(g *WithT, config *CloudProviderConfig) {
+				g.Expect(config.AadClientSecret).To(Equal("fooSecret"))
+				g.Expect(config.UseFederatedWorkloadIdentityExtension).To(BeFalse())
+			},
+		},
+		"workload identity authenticates with the projected service account token": {
+			credentialsType: infrav1.WorkloadIdentity,
+			identityType:    infrav1.VMIdentityNone,
+			verify: func(g *WithT, config *CloudProviderConfig) {
+				g.Expect(config.AadClientID).To(Equal("fooClient"))
+				g.Expect(config.AadClientSecret).To(BeEmpty())
+				g.Expect(config.AadFederatedTokenFile).To(Equal("/var/run/secrets/azure/tokens/azure-identity-token"))
+				g.Expect(config.UseFederatedWorkloadIdentityExtension).To(BeTrue())
+			},
+		},
+		"service principal certificate is not available on the machines": {
+			credentialsType: infrav1.ServicePrincipalCertificate,
+			identityType:    infrav1.VMIdentityNone,
+			expectedError:   "the cloud provider cannot authenticate with the certificate of the identity of cluster foo: use a SystemAssigned or UserAssigned identity",
+		},
+		"service principal certificate with a managed identity": {
+			credentialsType: infrav1.ServicePrincipalCertificate,
+			identityType:    infrav1.VMIdentitySystemAssigned,
+			verify: func(g *WithT, config *CloudProviderConfig) {
+				g.Expect(config.AadClientSecret).To(BeEmpty())
+				g.Expect(config.UseManagedIdentityExtension).To(BeTrue())
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			g := NewWithT(t)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(cluster, azureCluster).Build()
+			clusterScope, err := scope.NewClusterScope(context.Background(), scope.ClusterScopeParams{
+				AzureClients: scope.AzureClients{
+					Authorizer: autorest.NullAuthorizer{},
+				},
+				Cluster:      cluster,
+				AzureCluster: azureCluster,
+				Client:       fakeClient,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			secret, err := GetCloudProviderSecret(&credentialsTypeClusterScope{ClusterScope: clusterScope, credentialsType: tc.credentialsType}, "default", "foo", metav1.OwnerReference{}, tc.identityType, "")
+			if tc.expectedError != "" {
+				g.Expect(err).To(MatchError(tc.expectedError))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, key := range []string{"control-plane-azure.json", "worker-node-azure.json"} {
+				config := &CloudProviderConfig{}
+				g.Expect(json.Unmarshal(secret.Data[key], config)).To(Succeed())
+				tc.verify(g, config)
+			}
+		})
+	}
+}
+
 func TestReconcileAzureSecret(t *testing.T) {
 	g := NewWithT(t)
 
@@ -311,11 +492,46 @@ func TestReconcileAzureSecret(t *testing.T) {
 			} else {
 				g.Expect(cloudConfig.Data).To(Equal(found.Data))
 				g.Expect(found.OwnerReferences).To(Equal(cloudConfig.OwnerReferences))
+				g.Expect(found.Annotations).To(HaveKeyWithValue(infrav1.CloudProviderConfigHashAnnotation, cloudConfig.Annotations[infrav1.CloudProviderConfigHashAnnotation]))
 			}
 		})
 	}
 }
 
+func TestReconcileAzureSecretHashChange(t *testing.T) {
+	g := NewWithT(t)
+	scheme := setupScheme(g)
+
+	owner := metav1.OwnerReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+		Kind:       "AzureMachine",
+		Name:       "azureMachineName",
+	}
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "azureMachineName-azure-json",
+			Namespace:       "default",
+			Labels:          map[string]string{"testCluster": string(infrav1.ResourceLifecycleOwned)},
+			Annotations:     map[string]string{infrav1.CloudProviderConfigHashAnnotation: "old"},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Data: map[string][]byte{
+			"azure.json": []byte("redacted"),
+		},
+	}
+	kubeclient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(existing).Build()
+
+	// a secret redacted for a Key Vault keeps its data when the credentials are rotated, only its hash changes
+	updated := existing.DeepCopy()
+	updated.ResourceVersion = ""
+	updated.Annotations = map[string]string{infrav1.CloudProviderConfigHashAnnotation: "new"}
+	g.Expect(reconcileAzureSecret(context.Background(), kubeclient, owner, updated, "testCluster")).To(Succeed())
+
+	found := &corev1.Secret{}
+	g.Expect(kubeclient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "azureMachineName-azure-json"}, found)).To(Succeed())
+	g.Expect(found.Annotations).To(HaveKeyWithValue(infrav1.CloudProviderConfigHashAnnotation, "new"))
+}
+
 func setupScheme(g *WithT) *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	g.Expect(clientgoscheme.AddToScheme(scheme)).ToNot(HaveOccurred())
@@ -671,3 +887,64 @@ const (
     "cloudProviderBackoffJitter": 1.2000000000000002
 }`
 )
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		maxLines int
+		maxBytes int
+		want     string
+	}{
+		{
+			name:     "short log",
+			log:      "line 1\r\nline 2\r\n",
+			maxLines: 5,
+			maxBytes: 1024,
+			want:     "line 1\nline 2",
+		},
+		{
+			name:     "log longer than max lines",
+			log:      "line 1\nline 2\nline 3\nline 4\n",
+			maxLines: 2,
+			maxBytes: 1024,
+			want:     "line 3\nline 4",
+		},
+		{
+			name:     "log longer than max bytes",
+			log:      "line 1\nline 2\n",
+			maxLines: 5,
+			maxBytes: 8,
+			want:     "1\nline 2",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(tailLines([]byte(tc.log), tc.maxLines, tc.maxBytes)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestRedactCloudProviderSecret(t *testing.T) {
+	g := NewWithT(t)
+
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			"control-plane-azure.json": []byte(`{"cloud":"AzurePublicCloud","aadClientId":"fooClient","aadClientSecret":"fooSecret"}`),
+			"worker-node-azure.json":   []byte(`{"cloud":"AzurePublicCloud","aadClientId":"fooClient","aadClientSecret":"fooSecret"}`),
+		},
+	}
+	g.Expect(redactCloudProviderSecret(secret)).To(Succeed())
+	for key, data := range secret.Data {
+		g.Expect(string(data)).NotTo(ContainSubstring("fooSecret"), key)
+		g.Expect(string(data)).To(ContainSubstring(`"aadClientId": "fooClient"`), key)
+	}
+
+	invalid := &corev1.Secret{
+		Data: map[string][]byte{
+			"azure.json": []byte("not json"),
+		},
+	}
+	g.Expect(redactCloudProviderSecret(invalid)).NotTo(Succeed())
+}
@@ -48,6 +48,28 @@ func (src *AzureMachinePool) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	}
 
 	dst.Spec.Template.SubnetName = restored.Spec.Template.SubnetName
+	dst.Spec.Template.Diagnostics = restored.Spec.Template.Diagnostics
+	if restored.Spec.Template.SpotVMOptions != nil && dst.Spec.Template.SpotVMOptions != nil {
+		dst.Spec.Template.SpotVMOptions.EvictionPolicy = restored.Spec.Template.SpotVMOptions.EvictionPolicy
+		dst.Spec.Template.SpotVMOptions.RecoveryPolicy = restored.Spec.Template.SpotVMOptions.RecoveryPolicy
+	}
+	dst.Spec.OrchestrationMode = restored.Spec.OrchestrationMode
+	dst.Spec.SpotCapacityPolicy = restored.Spec.SpotCapacityPolicy
+	dst.Spec.HealthProbe = restored.Spec.HealthProbe
+	dst.Spec.AutomaticRepairsPolicy = restored.Spec.AutomaticRepairsPolicy
+	dst.Spec.RoleAssignments = restored.Spec.RoleAssignments
+	dst.Status.SpotFallbackTime = restored.Status.SpotFallbackTime
+	dst.Status.Capacity = restored.Status.Capacity
+	dst.Status.NodeLabels = restored.Status.NodeLabels
+	dst.Status.NodeTaints = restored.Status.NodeTaints
+	dst.Status.RoleAssignmentIDs = restored.Status.RoleAssignmentIDs
+	dst.Status.Rollout = restored.Status.Rollout
+	dst.Status.ProtectedInstances = restored.Status.ProtectedInstances
+	dst.Status.Zones = restored.Status.Zones
+	dst.Spec.ZoneBalance = restored.Spec.ZoneBalance
+	dst.Spec.PlatformFaultDomainCount = restored.Spec.PlatformFaultDomainCount
+	dst.Spec.Strategy.Canary = restored.Spec.Strategy.Canary
+	dst.Spec.Strategy.ReimageInPlace = restored.Spec.Strategy.ReimageInPlace
 
 	dst.Spec.Strategy.Type = restored.Spec.Strategy.Type
 	if restored.Spec.Strategy.RollingUpdate != nil {
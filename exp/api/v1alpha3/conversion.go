@@ -44,6 +44,16 @@ func Convert_v1beta1_Image_To_v1alpha3_Image(in *v1beta1.Image, out *v1alpha3.Im
 	return v1alpha3.Convert_v1beta1_Image_To_v1alpha3_Image(in, out, s)
 }
 
+// Convert_v1alpha3_SpotVMOptions_To_v1beta1_SpotVMOptions is a conversion function.
+func Convert_v1alpha3_SpotVMOptions_To_v1beta1_SpotVMOptions(in *v1alpha3.SpotVMOptions, out *v1beta1.SpotVMOptions, s conversion.Scope) error {
+	return v1alpha3.Convert_v1alpha3_SpotVMOptions_To_v1beta1_SpotVMOptions(in, out, s)
+}
+
+// Convert_v1beta1_SpotVMOptions_To_v1alpha3_SpotVMOptions is a conversion function.
+func Convert_v1beta1_SpotVMOptions_To_v1alpha3_SpotVMOptions(in *v1beta1.SpotVMOptions, out *v1alpha3.SpotVMOptions, s conversion.Scope) error {
+	return v1alpha3.Convert_v1beta1_SpotVMOptions_To_v1alpha3_SpotVMOptions(in, out, s)
+}
+
 // Convert_v1alpha3_APIEndpoint_To_v1beta1_APIEndpoint is an autogenerated conversion function.
 func Convert_v1alpha3_APIEndpoint_To_v1beta1_APIEndpoint(in *clusterapiapiv1alpha3.APIEndpoint, out *clusterapiapiv1beta1.APIEndpoint, s conversion.Scope) error {
 	return clusterapiapiv1alpha3.Convert_v1alpha3_APIEndpoint_To_v1beta1_APIEndpoint(in, out, s)
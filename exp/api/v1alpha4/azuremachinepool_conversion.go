@@ -17,20 +17,78 @@ limitations under the License.
 package v1alpha4
 
 import (
+	apiconversion "k8s.io/apimachinery/pkg/conversion"
 	expv1beta1 "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 )
 
 // ConvertTo converts this AzureMachinePool to the Hub version (v1beta1).
 func (src *AzureMachinePool) ConvertTo(dstRaw conversion.Hub) error { // nolint
 	dst := dstRaw.(*expv1beta1.AzureMachinePool)
+	if err := Convert_v1alpha4_AzureMachinePool_To_v1beta1_AzureMachinePool(src, dst, nil); err != nil {
+		return err
+	}
 
-	return Convert_v1alpha4_AzureMachinePool_To_v1beta1_AzureMachinePool(src, dst, nil)
+	// Manually restore data.
+	restored := &expv1beta1.AzureMachinePool{}
+	if ok, err := utilconversion.UnmarshalData(src, restored); err != nil || !ok {
+		return err
+	}
+
+	dst.Spec.Template.Diagnostics = restored.Spec.Template.Diagnostics
+	if restored.Spec.Template.SpotVMOptions != nil && dst.Spec.Template.SpotVMOptions != nil {
+		dst.Spec.Template.SpotVMOptions.EvictionPolicy = restored.Spec.Template.SpotVMOptions.EvictionPolicy
+		dst.Spec.Template.SpotVMOptions.RecoveryPolicy = restored.Spec.Template.SpotVMOptions.RecoveryPolicy
+	}
+	dst.Spec.OrchestrationMode = restored.Spec.OrchestrationMode
+	dst.Spec.SpotCapacityPolicy = restored.Spec.SpotCapacityPolicy
+	dst.Spec.HealthProbe = restored.Spec.HealthProbe
+	dst.Spec.AutomaticRepairsPolicy = restored.Spec.AutomaticRepairsPolicy
+	dst.Spec.RoleAssignments = restored.Spec.RoleAssignments
+	dst.Status.SpotFallbackTime = restored.Status.SpotFallbackTime
+	dst.Status.Capacity = restored.Status.Capacity
+	dst.Status.NodeLabels = restored.Status.NodeLabels
+	dst.Status.NodeTaints = restored.Status.NodeTaints
+	dst.Status.RoleAssignmentIDs = restored.Status.RoleAssignmentIDs
+	dst.Status.Rollout = restored.Status.Rollout
+	dst.Status.ProtectedInstances = restored.Status.ProtectedInstances
+	dst.Status.Zones = restored.Status.Zones
+	dst.Spec.ZoneBalance = restored.Spec.ZoneBalance
+	dst.Spec.PlatformFaultDomainCount = restored.Spec.PlatformFaultDomainCount
+	dst.Spec.Strategy.Canary = restored.Spec.Strategy.Canary
+	dst.Spec.Strategy.ReimageInPlace = restored.Spec.Strategy.ReimageInPlace
+
+	return nil
 }
 
 // ConvertFrom converts from the Hub version (v1beta1) to this version.
 func (dst *AzureMachinePool) ConvertFrom(srcRaw conversion.Hub) error { // nolint
 	src := srcRaw.(*expv1beta1.AzureMachinePool)
+	if err := Convert_v1beta1_AzureMachinePool_To_v1alpha4_AzureMachinePool(src, dst, nil); err != nil {
+		return err
+	}
+
+	// Preserve Hub data on down-conversion.
+	return utilconversion.MarshalData(src, dst)
+}
+
+// Convert_v1beta1_AzureMachinePoolSpec_To_v1alpha4_AzureMachinePoolSpec converts an Azure Machine Pool Spec from v1beta1 to v1alpha4.
+func Convert_v1beta1_AzureMachinePoolSpec_To_v1alpha4_AzureMachinePoolSpec(in *expv1beta1.AzureMachinePoolSpec, out *AzureMachinePoolSpec, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureMachinePoolSpec_To_v1alpha4_AzureMachinePoolSpec(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStatus converts an Azure Machine Pool Status from v1beta1 to v1alpha4.
+func Convert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStatus(in *expv1beta1.AzureMachinePoolStatus, out *AzureMachinePoolStatus, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureMachinePoolStatus_To_v1alpha4_AzureMachinePoolStatus(in, out, s)
+}
+
+// Convert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha4_AzureMachinePoolMachineTemplate converts an Azure Machine Pool Machine Template from v1beta1 to v1alpha4.
+func Convert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha4_AzureMachinePoolMachineTemplate(in *expv1beta1.AzureMachinePoolMachineTemplate, out *AzureMachinePoolMachineTemplate, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureMachinePoolMachineTemplate_To_v1alpha4_AzureMachinePoolMachineTemplate(in, out, s)
+}
 
-	return Convert_v1beta1_AzureMachinePool_To_v1alpha4_AzureMachinePool(src, dst, nil)
+// Convert_v1beta1_AzureMachinePoolDeploymentStrategy_To_v1alpha4_AzureMachinePoolDeploymentStrategy converts an Azure Machine Pool Deployment Strategy from v1beta1 to v1alpha4.
+func Convert_v1beta1_AzureMachinePoolDeploymentStrategy_To_v1alpha4_AzureMachinePoolDeploymentStrategy(in *expv1beta1.AzureMachinePoolDeploymentStrategy, out *AzureMachinePoolDeploymentStrategy, s apiconversion.Scope) error { //nolint
+	return autoConvert_v1beta1_AzureMachinePoolDeploymentStrategy_To_v1alpha4_AzureMachinePoolDeploymentStrategy(in, out, s)
 }
@@ -44,6 +44,16 @@ func Convert_v1beta1_Image_To_v1alpha4_Image(in *v1beta1.Image, out *v1alpha4.Im
 	return v1alpha4.Convert_v1beta1_Image_To_v1alpha4_Image(in, out, s)
 }
 
+// Convert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions is a conversion function.
+func Convert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions(in *v1alpha4.SpotVMOptions, out *v1beta1.SpotVMOptions, s conversion.Scope) error {
+	return v1alpha4.Convert_v1alpha4_SpotVMOptions_To_v1beta1_SpotVMOptions(in, out, s)
+}
+
+// Convert_v1beta1_SpotVMOptions_To_v1alpha4_SpotVMOptions is a conversion function.
+func Convert_v1beta1_SpotVMOptions_To_v1alpha4_SpotVMOptions(in *v1beta1.SpotVMOptions, out *v1alpha4.SpotVMOptions, s conversion.Scope) error {
+	return v1alpha4.Convert_v1beta1_SpotVMOptions_To_v1alpha4_SpotVMOptions(in, out, s)
+}
+
 // Convert_v1alpha4_APIEndpoint_To_v1beta1_APIEndpoint is an autogenerated conversion function.
 func Convert_v1alpha4_APIEndpoint_To_v1beta1_APIEndpoint(in *clusterapiapiv1alpha4.APIEndpoint, out *clusterapiapiv1beta1.APIEndpoint, s conversion.Scope) error {
 	return clusterapiapiv1alpha4.Convert_v1alpha4_APIEndpoint_To_v1beta1_APIEndpoint(in, out, s)
@@ -18,8 +18,11 @@ package v1beta1
 
 import (
 	"encoding/base64"
+	"time"
 
+	"github.com/Azure/go-autorest/autorest/to"
 	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
@@ -47,3 +50,38 @@ func (amp *AzureMachinePool) SetIdentityDefaults() {
 		}
 	}
 }
+
+// SetOrchestrationModeDefaults sets the default orchestration mode of the VMSS.
+func (amp *AzureMachinePool) SetOrchestrationModeDefaults() {
+	if amp.Spec.OrchestrationMode == "" {
+		amp.Spec.OrchestrationMode = infrav1.UniformOrchestrationMode
+	}
+}
+
+// SetSpotCapacityPolicyDefaults sets the defaults for the Spot capacity policy of the VMSS.
+func (amp *AzureMachinePool) SetSpotCapacityPolicyDefaults() {
+	policy := amp.Spec.SpotCapacityPolicy
+	if policy == nil {
+		return
+	}
+
+	if policy.BaseOnDemandCount == nil {
+		policy.BaseOnDemandCount = to.Int32Ptr(0)
+	}
+
+	if policy.SpotPercentageAboveBase == nil {
+		policy.SpotPercentageAboveBase = to.Int32Ptr(100)
+	}
+}
+
+// SetAutomaticRepairsPolicyDefaults sets the defaults for the automatic repairs policy of the VMSS.
+func (amp *AzureMachinePool) SetAutomaticRepairsPolicyDefaults() {
+	policy := amp.Spec.AutomaticRepairsPolicy
+	if policy == nil {
+		return
+	}
+
+	if policy.GracePeriod == nil {
+		policy.GracePeriod = &metav1.Duration{Duration: 30 * time.Minute}
+	}
+}
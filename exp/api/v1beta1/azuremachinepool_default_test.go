@@ -18,9 +18,12 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/google/uuid"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
@@ -77,6 +80,52 @@ func TestAzureMachinePool_SetIdentityDefaults(t *testing.T) {
 	g.Expect(notSystemAssignedTest.machinePool.Spec.RoleAssignmentName).To(BeEmpty())
 }
 
+func TestAzureMachinePool_SetOrchestrationModeDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	unsetTest := &AzureMachinePool{}
+	unsetTest.SetOrchestrationModeDefaults()
+	g.Expect(unsetTest.Spec.OrchestrationMode).To(Equal(infrav1.UniformOrchestrationMode))
+
+	flexibleTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{OrchestrationMode: infrav1.FlexibleOrchestrationMode}}
+	flexibleTest.SetOrchestrationModeDefaults()
+	g.Expect(flexibleTest.Spec.OrchestrationMode).To(Equal(infrav1.FlexibleOrchestrationMode))
+}
+
+func TestAzureMachinePool_SetSpotCapacityPolicyDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	unsetTest := &AzureMachinePool{}
+	unsetTest.SetSpotCapacityPolicyDefaults()
+	g.Expect(unsetTest.Spec.SpotCapacityPolicy).To(BeNil())
+
+	emptyTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{SpotCapacityPolicy: &SpotCapacityPolicy{}}}
+	emptyTest.SetSpotCapacityPolicyDefaults()
+	g.Expect(emptyTest.Spec.SpotCapacityPolicy.BaseOnDemandCount).To(Equal(to.Int32Ptr(0)))
+	g.Expect(emptyTest.Spec.SpotCapacityPolicy.SpotPercentageAboveBase).To(Equal(to.Int32Ptr(100)))
+
+	setTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{SpotCapacityPolicy: &SpotCapacityPolicy{BaseOnDemandCount: to.Int32Ptr(2), SpotPercentageAboveBase: to.Int32Ptr(50)}}}
+	setTest.SetSpotCapacityPolicyDefaults()
+	g.Expect(setTest.Spec.SpotCapacityPolicy.BaseOnDemandCount).To(Equal(to.Int32Ptr(2)))
+	g.Expect(setTest.Spec.SpotCapacityPolicy.SpotPercentageAboveBase).To(Equal(to.Int32Ptr(50)))
+}
+
+func TestAzureMachinePool_SetAutomaticRepairsPolicyDefaults(t *testing.T) {
+	g := NewWithT(t)
+
+	unsetTest := &AzureMachinePool{}
+	unsetTest.SetAutomaticRepairsPolicyDefaults()
+	g.Expect(unsetTest.Spec.AutomaticRepairsPolicy).To(BeNil())
+
+	emptyTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{AutomaticRepairsPolicy: &AutomaticRepairsPolicy{}}}
+	emptyTest.SetAutomaticRepairsPolicyDefaults()
+	g.Expect(emptyTest.Spec.AutomaticRepairsPolicy.GracePeriod).To(Equal(&metav1.Duration{Duration: 30 * time.Minute}))
+
+	setTest := &AzureMachinePool{Spec: AzureMachinePoolSpec{AutomaticRepairsPolicy: &AutomaticRepairsPolicy{GracePeriod: &metav1.Duration{Duration: 45 * time.Minute}}}}
+	setTest.SetAutomaticRepairsPolicyDefaults()
+	g.Expect(setTest.Spec.AutomaticRepairsPolicy.GracePeriod).To(Equal(&metav1.Duration{Duration: 45 * time.Minute}))
+}
+
 func createMachinePoolWithSSHPublicKey(sshPublicKey string) *AzureMachinePool {
 	return hardcodedAzureMachinePoolWithSSHKey(sshPublicKey)
 }
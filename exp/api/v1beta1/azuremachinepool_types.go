@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -29,17 +30,54 @@ const (
 	// MachinePoolNameLabel indicates the AzureMachinePool name the AzureMachinePoolMachine belongs.
 	MachinePoolNameLabel = "azuremachinepool.infrastructure.cluster.x-k8s.io/machine-pool"
 
+	// ApproveRolloutAnnotation approves the rollout of an AzureMachinePool paused after its canary batch, which
+	// continues the rollout to the remaining machines. The annotation is removed once the rollout continues.
+	ApproveRolloutAnnotation = "azuremachinepool.infrastructure.cluster.x-k8s.io/approve-rollout"
+
 	// RollingUpdateAzureMachinePoolDeploymentStrategyType replaces AzureMachinePoolMachines with older models with
 	// AzureMachinePoolMachines based on the latest model.
 	// i.e. gradually scale down the old AzureMachinePoolMachines and scale up the new ones.
 	RollingUpdateAzureMachinePoolDeploymentStrategyType AzureMachinePoolDeploymentStrategyType = "RollingUpdate"
 
+	// ReimageInPlaceAzureMachinePoolDeploymentStrategyType updates AzureMachinePoolMachines with older models in place,
+	// i.e. drains the node of a machine, applies the latest model to its instance and reimages it, keeping its
+	// instance, network interfaces and IP addresses.
+	ReimageInPlaceAzureMachinePoolDeploymentStrategyType AzureMachinePoolDeploymentStrategyType = "ReimageInPlace"
+
 	// OldestDeletePolicyType will delete machines with the oldest creation date first.
 	OldestDeletePolicyType AzureMachinePoolDeletePolicyType = "Oldest"
 	// NewestDeletePolicyType will delete machines with the newest creation date first.
 	NewestDeletePolicyType AzureMachinePoolDeletePolicyType = "Newest"
 	// RandomDeletePolicyType will delete machines in random order.
 	RandomDeletePolicyType AzureMachinePoolDeletePolicyType = "Random"
+	// ZoneBalancedDeletePolicyType will delete machines from the availability zone with the most machines first, keeping
+	// the remaining machines spread evenly across zones. Machines within a zone are deleted oldest first.
+	ZoneBalancedDeletePolicyType AzureMachinePoolDeletePolicyType = "ZoneBalanced"
+	// UnhealthyFirstDeletePolicyType will delete machines whose node is not Ready or is cordoned first, followed by
+	// the oldest machines.
+	UnhealthyFirstDeletePolicyType AzureMachinePoolDeletePolicyType = "UnhealthyFirst"
+
+	// HTTPApplicationHealthProbeProtocol probes an instance with an HTTP request.
+	HTTPApplicationHealthProbeProtocol ApplicationHealthProbeProtocol = "http"
+	// HTTPSApplicationHealthProbeProtocol probes an instance with an HTTPS request.
+	HTTPSApplicationHealthProbeProtocol ApplicationHealthProbeProtocol = "https"
+	// TCPApplicationHealthProbeProtocol probes an instance by opening a TCP connection.
+	TCPApplicationHealthProbeProtocol ApplicationHealthProbeProtocol = "tcp"
+
+	// CanaryRolloutPhase is the phase of a rollout replacing the machines of the canary batch.
+	CanaryRolloutPhase AzureMachinePoolRolloutPhase = "Canary"
+	// PausedRolloutPhase is the phase of a rollout waiting for the approval or the health gate of its canary batch.
+	PausedRolloutPhase AzureMachinePoolRolloutPhase = "Paused"
+	// ProgressingRolloutPhase is the phase of a rollout replacing the remaining machines.
+	ProgressingRolloutPhase AzureMachinePoolRolloutPhase = "Progressing"
+	// CompletedRolloutPhase is the phase of a rollout once all machines run the latest model.
+	CompletedRolloutPhase AzureMachinePoolRolloutPhase = "Completed"
+
+	// ScaleInInstanceProtection protects an instance from being removed when its scale set scales in.
+	ScaleInInstanceProtection InstanceProtectionType = "ScaleIn"
+	// ScaleSetActionsInstanceProtection protects an instance from being removed or modified by any action of its scale
+	// set, including scaling in and applying a new model.
+	ScaleSetActionsInstanceProtection InstanceProtectionType = "ScaleSetActions"
 )
 
 type (
@@ -81,6 +119,11 @@ type (
 		// +optional
 		SecurityProfile *infrav1.SecurityProfile `json:"securityProfile,omitempty"`
 
+		// Diagnostics specifies the diagnostics settings for a virtual machine.
+		// If not specified, boot diagnostics are enabled with a managed storage account.
+		// +optional
+		Diagnostics *infrav1.Diagnostics `json:"diagnostics,omitempty"`
+
 		// SpotVMOptions allows the ability to specify the Machine should use a Spot VM
 		// +optional
 		SpotVMOptions *infrav1.SpotVMOptions `json:"spotVMOptions,omitempty"`
@@ -134,6 +177,12 @@ type (
 		// +optional
 		RoleAssignmentName string `json:"roleAssignmentName,omitempty"`
 
+		// RoleAssignments is a list of roles to assign to the system-assigned or user-assigned identities of the
+		// scale set, e.g. to pull images from a container registry. The role assignments removed from the list are
+		// deleted, and all of them are deleted with the AzureMachinePool.
+		// +optional
+		RoleAssignments []infrav1.RoleAssignment `json:"roleAssignments,omitempty"`
+
 		// The deployment strategy to use to replace existing AzureMachinePoolMachines with new ones.
 		// +optional
 		// +kubebuilder:default={type: "RollingUpdate", rollingUpdate: {maxSurge: 1, maxUnavailable: 0, deletePolicy: Oldest}}
@@ -144,6 +193,91 @@ type (
 		// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 		// +optional
 		NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+		// OrchestrationMode specifies the orchestration mode for the Virtual Machine Scale Set.
+		// Uniform scale sets manage identical instances through the scale set VM API. Flexible scale sets
+		// manage their instances as standalone virtual machines, spread across fault domains.
+		// OrchestrationMode cannot be changed after the AzureMachinePool is created.
+		// +kubebuilder:default=Uniform
+		// +optional
+		OrchestrationMode infrav1.OrchestrationModeType `json:"orchestrationMode,omitempty"`
+
+		// SpotCapacityPolicy mixes on-demand and Spot instances in the machine pool. It requires
+		// Template.SpotVMOptions to be set. The on-demand instances run in a separate scale set, which also takes
+		// over the Spot share of the replicas while Azure cannot allocate Spot capacity.
+		// +optional
+		SpotCapacityPolicy *SpotCapacityPolicy `json:"spotCapacityPolicy,omitempty"`
+
+		// HealthProbe installs the application health extension on the instances of the scale set, which reports
+		// the health of each instance from the given HTTP, HTTPS or TCP endpoint.
+		// +optional
+		HealthProbe *ApplicationHealthProbe `json:"healthProbe,omitempty"`
+
+		// AutomaticRepairsPolicy enables the automatic repair of instances which the HealthProbe reports as
+		// unhealthy. Azure replaces a repaired instance with a new one, which has a different instance ID.
+		// It requires HealthProbe to be set.
+		// +optional
+		AutomaticRepairsPolicy *AutomaticRepairsPolicy `json:"automaticRepairsPolicy,omitempty"`
+
+		// ZoneBalance strictly balances the instances across the availability zones of the scale set, i.e. the
+		// numbers of instances in any two zones differ by at most one. Scaling out fails rather than unbalancing the
+		// zones when a zone is out of capacity. It requires the MachinePool to have failure domains and is only
+		// supported in the Uniform orchestration mode. ZoneBalance cannot be changed after the AzureMachinePool is
+		// created.
+		// +optional
+		ZoneBalance *bool `json:"zoneBalance,omitempty"`
+
+		// PlatformFaultDomainCount is the number of fault domains the instances of the scale set are spread across.
+		// PlatformFaultDomainCount cannot be changed after the AzureMachinePool is created.
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=5
+		// +optional
+		PlatformFaultDomainCount *int32 `json:"platformFaultDomainCount,omitempty"`
+	}
+
+	// ApplicationHealthProbeProtocol is the protocol used by the application health extension to probe an instance.
+	ApplicationHealthProbeProtocol string
+
+	// ApplicationHealthProbe defines the endpoint probed by the application health extension.
+	ApplicationHealthProbe struct {
+		// Protocol used to probe the instance.
+		// +kubebuilder:validation:Enum=http;https;tcp
+		Protocol ApplicationHealthProbeProtocol `json:"protocol"`
+
+		// Port probed on the instance.
+		// +kubebuilder:validation:Minimum=1
+		// +kubebuilder:validation:Maximum=65535
+		Port int32 `json:"port"`
+
+		// RequestPath is the path of the HTTP or HTTPS request, e.g. /healthz. It is required for the http and https
+		// protocols and must be empty for tcp.
+		// +optional
+		RequestPath string `json:"requestPath,omitempty"`
+	}
+
+	// AutomaticRepairsPolicy defines the automatic repairs of unhealthy instances of the scale set.
+	AutomaticRepairsPolicy struct {
+		// GracePeriod is how long Azure waits after a state change of an instance before it repairs the instance
+		// when it is unhealthy, which gives new instances time to bootstrap. It must be a whole number of minutes
+		// between 10 and 90. Defaults to 30 minutes.
+		// +optional
+		GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+	}
+
+	// SpotCapacityPolicy defines how the replicas of a machine pool are split between on-demand and Spot instances.
+	SpotCapacityPolicy struct {
+		// BaseOnDemandCount is the number of replicas that always run as on-demand instances before any Spot
+		// instances are added to the pool.
+		// +kubebuilder:validation:Minimum=0
+		// +optional
+		BaseOnDemandCount *int32 `json:"baseOnDemandCount,omitempty"`
+
+		// SpotPercentageAboveBase is the percentage of the replicas above BaseOnDemandCount that run as Spot
+		// instances. The remaining replicas above the base run as on-demand instances. Defaults to 100.
+		// +kubebuilder:validation:Minimum=0
+		// +kubebuilder:validation:Maximum=100
+		// +optional
+		SpotPercentageAboveBase *int32 `json:"spotPercentageAboveBase,omitempty"`
 	}
 
 	// AzureMachinePoolDeploymentStrategyType is the type of deployment strategy employed to rollout a new version of
@@ -152,9 +286,9 @@ type (
 
 	// AzureMachinePoolDeploymentStrategy describes how to replace existing machines with new ones.
 	AzureMachinePoolDeploymentStrategy struct {
-		// Type of deployment. Currently the only supported strategy is RollingUpdate
+		// Type of deployment. Supported strategies are RollingUpdate and ReimageInPlace.
 		// +optional
-		// +kubebuilder:validation:Enum=RollingUpdate
+		// +kubebuilder:validation:Enum=RollingUpdate;ReimageInPlace
 		// +optional
 		// +kubebuilder:default=RollingUpdate
 		Type AzureMachinePoolDeploymentStrategyType `json:"type,omitempty"`
@@ -163,6 +297,108 @@ type (
 		// MachineDeploymentStrategyType = RollingUpdate.
 		// +optional
 		RollingUpdate *MachineRollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+
+		// Reimage in place config params. Present only if
+		// MachineDeploymentStrategyType = ReimageInPlace.
+		// +optional
+		ReimageInPlace *MachineReimageInPlaceDeployment `json:"reimageInPlace,omitempty"`
+
+		// Canary rolls out a new model to a canary batch of machines first, and then pauses the rollout until it is
+		// approved with the ApproveRolloutAnnotation or the canary batch passes its health gate.
+		// +optional
+		Canary *AzureMachinePoolCanary `json:"canary,omitempty"`
+	}
+
+	// AzureMachinePoolCanary describes the canary batch of a rollout.
+	AzureMachinePoolCanary struct {
+		// Replicas is the number of machines in the canary batch.
+		// Value can be an absolute number (ex: 2) or a percentage of desired machines (ex: 10%).
+		// Absolute number is calculated from percentage by rounding up.
+		// Defaults to 1.
+		// +optional
+		// +kubebuilder:default:=1
+		Replicas *intstr.IntOrString `json:"replicas,omitempty"`
+
+		// HealthGate continues the rollout without an approval once the canary batch is healthy. When omitted, the
+		// rollout only continues once it is approved.
+		// +optional
+		HealthGate *AzureMachinePoolHealthGate `json:"healthGate,omitempty"`
+	}
+
+	// AzureMachinePoolHealthGate describes when the canary batch of a rollout is healthy.
+	AzureMachinePoolHealthGate struct {
+		// StableMinutes is the number of minutes the nodes of the canary batch must be Ready without any of its
+		// AzureMachinePoolMachines failing before the rollout continues.
+		// +kubebuilder:validation:Minimum=1
+		StableMinutes int32 `json:"stableMinutes"`
+	}
+
+	// AzureMachinePoolRolloutPhase is the phase of the rollout of a new model to the machines of an AzureMachinePool.
+	AzureMachinePoolRolloutPhase string
+
+	// AzureMachinePoolRolloutStatus reports the progress of the rollout of a new model to the machines of an
+	// AzureMachinePool.
+	AzureMachinePoolRolloutStatus struct {
+		// Phase is the phase of the rollout.
+		Phase AzureMachinePoolRolloutPhase `json:"phase"`
+
+		// Replicas is the number of machines of the machine pool.
+		// +optional
+		Replicas int32 `json:"replicas"`
+
+		// UpdatedReplicas is the number of machines running the latest model.
+		// +optional
+		UpdatedReplicas int32 `json:"updatedReplicas"`
+
+		// CanaryReplicas is the number of machines in the canary batch of the rollout.
+		// +optional
+		CanaryReplicas int32 `json:"canaryReplicas,omitempty"`
+
+		// ModelHash is a hash of the model rolled out. A new model pushed before the rollout completes starts the
+		// rollout over with its canary batch.
+		// +optional
+		ModelHash string `json:"modelHash,omitempty"`
+
+		// StartTime is the time the rollout started.
+		// +optional
+		StartTime *metav1.Time `json:"startTime,omitempty"`
+
+		// CanaryHealthySince is the time since which the canary batch has been healthy.
+		// +optional
+		CanaryHealthySince *metav1.Time `json:"canaryHealthySince,omitempty"`
+
+		// Message describes the progress of the rollout.
+		// +optional
+		Message string `json:"message,omitempty"`
+	}
+
+	// AzureMachinePoolZoneStatus reports the instances of an AzureMachinePool in an availability zone.
+	AzureMachinePoolZoneStatus struct {
+		// Zone is the availability zone.
+		Zone string `json:"zone"`
+
+		// Replicas is the number of instances in the zone.
+		Replicas int32 `json:"replicas"`
+
+		// ReadyReplicas is the number of instances in the zone whose node is Ready.
+		ReadyReplicas int32 `json:"readyReplicas"`
+	}
+
+	// InstanceProtectionType describes how an instance of a scale set is protected from the actions of the scale set.
+	InstanceProtectionType string
+
+	// AzureMachinePoolProtectedInstance reports an instance of an AzureMachinePool which is protected from the actions
+	// of its scale set.
+	AzureMachinePoolProtectedInstance struct {
+		// InstanceID is the identification of the instance within the scale set.
+		InstanceID string `json:"instanceID"`
+
+		// ProviderID is the provider identification of the instance.
+		// +optional
+		ProviderID string `json:"providerID,omitempty"`
+
+		// Protection is the protection of the instance.
+		Protection InstanceProtectionType `json:"protection"`
 	}
 
 	// AzureMachinePoolDeletePolicyType is the type of DeletePolicy employed to select machines to be deleted during an
@@ -205,10 +441,33 @@ type (
 		MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
 
 		// DeletePolicy defines the policy used by the MachineDeployment to identify nodes to delete when downscaling.
-		// Valid values are "Random, "Newest", "Oldest"
+		// Valid values are "Random, "Newest", "Oldest", "ZoneBalanced", "UnhealthyFirst"
 		// When no value is supplied, the default is Oldest
+		// Regardless of the policy, machines annotated with "cluster.x-k8s.io/delete-machine" are deleted first.
 		// +optional
-		// +kubebuilder:validation:Enum=Random;Newest;Oldest
+		// +kubebuilder:validation:Enum=Random;Newest;Oldest;ZoneBalanced;UnhealthyFirst
+		// +kubebuilder:default:=Oldest
+		DeletePolicy AzureMachinePoolDeletePolicyType `json:"deletePolicy,omitempty"`
+	}
+
+	// MachineReimageInPlaceDeployment is used to control the desired behavior of reimage in place.
+	MachineReimageInPlaceDeployment struct {
+		// The maximum number of machines that can be unavailable during the update, including the machines being
+		// reimaged.
+		// Value can be an absolute number (ex: 5) or a percentage of desired
+		// machines (ex: 10%).
+		// Absolute number is calculated from percentage by rounding down, but is at least 1.
+		// Defaults to 1.
+		// +optional
+		// +kubebuilder:default:=1
+		MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+		// DeletePolicy defines the policy used to identify nodes to delete when downscaling.
+		// Valid values are "Random, "Newest", "Oldest", "ZoneBalanced", "UnhealthyFirst"
+		// When no value is supplied, the default is Oldest
+		// Regardless of the policy, machines annotated with "cluster.x-k8s.io/delete-machine" are deleted first.
+		// +optional
+		// +kubebuilder:validation:Enum=Random;Newest;Oldest;ZoneBalanced;UnhealthyFirst
 		// +kubebuilder:default:=Oldest
 		DeletePolicy AzureMachinePoolDeletePolicyType `json:"deletePolicy,omitempty"`
 	}
@@ -240,6 +499,25 @@ type (
 		// +optional
 		ProvisioningState *infrav1.ProvisioningState `json:"provisioningState,omitempty"`
 
+		// Capacity is the resource capacity of a node of the machine pool, which lets the cluster autoscaler scale
+		// the machine pool up from zero replicas.
+		// +optional
+		Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+		// NodeLabels are the labels of a node of the machine pool. They include well-known labels, e.g. the instance
+		// type and operating system, and the node labels set by the KubeadmConfig of the machine pool.
+		// +optional
+		NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+		// NodeTaints are the taints of a node of the machine pool, as set by the KubeadmConfig of the machine pool.
+		// +optional
+		NodeTaints []corev1.Taint `json:"nodeTaints,omitempty"`
+
+		// RoleAssignmentIDs are the resource IDs of the role assignments created for the identities of the scale set,
+		// which lets the ones removed from the spec be deleted.
+		// +optional
+		RoleAssignmentIDs []string `json:"roleAssignmentIDs,omitempty"`
+
 		// FailureReason will be set in the event that there is a terminal problem
 		// reconciling the MachinePool and will contain a succinct value suitable
 		// for machine interpretation.
@@ -286,6 +564,24 @@ type (
 		// next reconciliation loop.
 		// +optional
 		LongRunningOperationStates infrav1.Futures `json:"longRunningOperationStates,omitempty"`
+
+		// SpotFallbackTime is the last time Azure could not allocate Spot capacity for the machine pool and its Spot
+		// replicas fell back to on-demand instances. Spot capacity is retried after the fallback expires.
+		// +optional
+		SpotFallbackTime *metav1.Time `json:"spotFallbackTime,omitempty"`
+
+		// Rollout reports the progress of the latest rollout of a new model to the machines of the machine pool.
+		// +optional
+		Rollout *AzureMachinePoolRolloutStatus `json:"rollout,omitempty"`
+
+		// ProtectedInstances lists the instances of the scale set which are protected from scale in or from all the
+		// actions of the scale set.
+		// +optional
+		ProtectedInstances []AzureMachinePoolProtectedInstance `json:"protectedInstances,omitempty"`
+
+		// Zones reports the number of instances in each availability zone of the machine pool.
+		// +optional
+		Zones []AzureMachinePoolZoneStatus `json:"zones,omitempty"`
 	}
 
 	// AzureMachinePoolInstanceStatus provides status information for each instance in the VMSS.
@@ -20,11 +20,13 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -47,6 +49,9 @@ func (amp *AzureMachinePool) Default() {
 		ctrl.Log.WithName("AzureMachinePoolLogger").Error(err, "SetDefaultSshPublicKey failed")
 	}
 	amp.SetIdentityDefaults()
+	amp.SetOrchestrationModeDefaults()
+	amp.SetSpotCapacityPolicyDefaults()
+	amp.SetAutomaticRepairsPolicyDefaults()
 }
 
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-azuremachinepool,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=azuremachinepools,versions=v1beta1,name=validation.azuremachinepool.infrastructure.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1;v1beta1
@@ -77,6 +82,14 @@ func (amp *AzureMachinePool) Validate(old runtime.Object) error {
 		amp.ValidateUserAssignedIdentity,
 		amp.ValidateStrategy(),
 		amp.ValidateSystemAssignedIdentity(old),
+		amp.ValidateRoleAssignments,
+		amp.ValidateDiagnostics,
+		amp.ValidateSpotVMOptions,
+		amp.ValidateSpotCapacityPolicy(old),
+		amp.ValidateOrchestrationMode(old),
+		amp.ValidateZoneBalance(old),
+		amp.ValidateHealthProbe,
+		amp.ValidateAutomaticRepairsPolicy,
 	}
 
 	var errs []error
@@ -102,6 +115,138 @@ func (amp *AzureMachinePool) ValidateImage() error {
 	return nil
 }
 
+// ValidateDiagnostics validates the diagnostics settings of an AzureMachinePool.
+func (amp *AzureMachinePool) ValidateDiagnostics() error {
+	fldPath := field.NewPath("diagnostics")
+	errs := infrav1.ValidateDiagnostics(amp.Spec.Template.Diagnostics, fldPath)
+	if diagnostics := amp.Spec.Template.Diagnostics; diagnostics != nil && diagnostics.Boot != nil && diagnostics.Boot.SerialConsoleLogConfigMapName != "" {
+		errs = append(errs, field.Forbidden(fldPath.Child("boot", "serialConsoleLogConfigMapName"), "the serial console log is only collected for AzureMachines"))
+	}
+	if len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateSpotVMOptions validates the spot options of an AzureMachinePool.
+func (amp *AzureMachinePool) ValidateSpotVMOptions() error {
+	if errs := infrav1.ValidateSpotVMOptions(amp.Spec.Template.SpotVMOptions, field.NewPath("spotVMOptions")); len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateSpotCapacityPolicy validates the Spot capacity policy of an AzureMachinePool. The policy cannot be removed
+// once set, as its on-demand scale set would no longer be managed.
+func (amp *AzureMachinePool) ValidateSpotCapacityPolicy(old runtime.Object) func() error {
+	return func() error {
+		if old != nil {
+			oldMachinePool, ok := old.(*AzureMachinePool)
+			if !ok {
+				return fmt.Errorf("unexpected type for old azure machine pool object. Expected: %q, Got: %q",
+					"AzureMachinePool", reflect.TypeOf(old))
+			}
+
+			if oldMachinePool.Spec.SpotCapacityPolicy != nil && amp.Spec.SpotCapacityPolicy == nil {
+				return field.Forbidden(field.NewPath("Spec", "SpotCapacityPolicy"), "field cannot be removed once set")
+			}
+		}
+
+		return amp.validateSpotCapacityPolicy()
+	}
+}
+
+func (amp *AzureMachinePool) validateSpotCapacityPolicy() error {
+	policy := amp.Spec.SpotCapacityPolicy
+	if policy == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("spotCapacityPolicy")
+	var allErrs field.ErrorList
+	if amp.Spec.Template.SpotVMOptions == nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "spotCapacityPolicy requires template.spotVMOptions to be set"))
+	}
+
+	if policy.BaseOnDemandCount != nil && *policy.BaseOnDemandCount < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("baseOnDemandCount"), *policy.BaseOnDemandCount, "must be greater than or equal to 0"))
+	}
+
+	if policy.SpotPercentageAboveBase != nil && (*policy.SpotPercentageAboveBase < 0 || *policy.SpotPercentageAboveBase > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spotPercentageAboveBase"), *policy.SpotPercentageAboveBase, "must be between 0 and 100"))
+	}
+
+	if len(allErrs) > 0 {
+		return kerrors.NewAggregate(allErrs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateHealthProbe validates the application health probe of an AzureMachinePool.
+func (amp *AzureMachinePool) ValidateHealthProbe() error {
+	probe := amp.Spec.HealthProbe
+	if probe == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("healthProbe")
+	var allErrs field.ErrorList
+	switch probe.Protocol {
+	case HTTPApplicationHealthProbeProtocol, HTTPSApplicationHealthProbeProtocol:
+		if probe.RequestPath == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("requestPath"), "requestPath is required for the http and https protocols"))
+		}
+	case TCPApplicationHealthProbeProtocol:
+		if probe.RequestPath != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("requestPath"), "requestPath must be empty for the tcp protocol"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), probe.Protocol, []string{
+			string(HTTPApplicationHealthProbeProtocol), string(HTTPSApplicationHealthProbeProtocol), string(TCPApplicationHealthProbeProtocol),
+		}))
+	}
+
+	if probe.Port < 1 || probe.Port > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), probe.Port, "must be between 1 and 65535"))
+	}
+
+	if len(allErrs) > 0 {
+		return kerrors.NewAggregate(allErrs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
+// ValidateAutomaticRepairsPolicy validates the automatic repairs policy of an AzureMachinePool. Azure only repairs
+// instances whose health is reported by the application health extension, so the policy requires a health probe.
+func (amp *AzureMachinePool) ValidateAutomaticRepairsPolicy() error {
+	policy := amp.Spec.AutomaticRepairsPolicy
+	if policy == nil {
+		return nil
+	}
+
+	fldPath := field.NewPath("automaticRepairsPolicy")
+	var allErrs field.ErrorList
+	if amp.Spec.HealthProbe == nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "automaticRepairsPolicy requires healthProbe to be set"))
+	}
+
+	if gracePeriod := policy.GracePeriod; gracePeriod != nil {
+		if gracePeriod.Duration%time.Minute != 0 || gracePeriod.Duration < 10*time.Minute || gracePeriod.Duration > 90*time.Minute {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("gracePeriod"), gracePeriod.Duration.String(), "must be a whole number of minutes between 10 and 90"))
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return kerrors.NewAggregate(allErrs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
 // ValidateTerminateNotificationTimeout termination notification timeout to be between 5 and 15.
 func (amp *AzureMachinePool) ValidateTerminateNotificationTimeout() error {
 	if amp.Spec.Template.TerminateNotificationTimeout == nil {
@@ -154,6 +299,33 @@ func (amp *AzureMachinePool) ValidateStrategy() func() error {
 			}
 		}
 
+		if amp.Spec.Strategy.Type == ReimageInPlaceAzureMachinePoolDeploymentStrategyType {
+			if amp.Spec.OrchestrationMode == infrav1.FlexibleOrchestrationMode {
+				return errors.New("reimage in place strategy is not supported with the Flexible orchestration mode")
+			}
+			if reimageInPlace := amp.Spec.Strategy.ReimageInPlace; reimageInPlace != nil && reimageInPlace.MaxUnavailable != nil {
+				// a percentage of 100 desired machines is at least 1 unless the percentage is 0
+				maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(reimageInPlace.MaxUnavailable, 100, true)
+				if err != nil {
+					return fmt.Errorf("invalid reimage in place MaxUnavailable: %w", err)
+				}
+				if maxUnavailable < 1 {
+					return errors.New("reimage in place strategy MaxUnavailable must be at least 1")
+				}
+			}
+		}
+
+		if canary := amp.Spec.Strategy.Canary; canary != nil && canary.Replicas != nil {
+			// a percentage of 100 desired machines is at least 1 unless the percentage is 0
+			replicas, err := intstr.GetScaledValueFromIntOrPercent(canary.Replicas, 100, true)
+			if err != nil {
+				return fmt.Errorf("invalid canary replicas: %w", err)
+			}
+			if replicas < 1 {
+				return errors.New("canary replicas must be at least 1")
+			}
+		}
+
 		return nil
 	}
 }
@@ -179,3 +351,73 @@ func (amp *AzureMachinePool) ValidateSystemAssignedIdentity(old runtime.Object)
 		return nil
 	}
 }
+
+// ValidateRoleAssignments validates the role assignments of the identities of the scale set.
+func (amp *AzureMachinePool) ValidateRoleAssignments() error {
+	fldPath := field.NewPath("RoleAssignments")
+	if errs := infrav1.ValidateRoleAssignments(amp.Spec.Identity, amp.Spec.UserAssignedIdentities, amp.Spec.RoleAssignments, fldPath); len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+	return nil
+}
+
+// ValidateOrchestrationMode validates that the orchestration mode of the VMSS is not changed.
+func (amp *AzureMachinePool) ValidateOrchestrationMode(old runtime.Object) func() error {
+	return func() error {
+		if old == nil {
+			return nil
+		}
+
+		oldMachinePool, ok := old.(*AzureMachinePool)
+		if !ok {
+			return fmt.Errorf("unexpected type for old azure machine pool object. Expected: %q, Got: %q",
+				"AzureMachinePool", reflect.TypeOf(old))
+		}
+
+		if orchestrationModeOrDefault(oldMachinePool.Spec.OrchestrationMode) != orchestrationModeOrDefault(amp.Spec.OrchestrationMode) {
+			return field.Forbidden(field.NewPath("Spec", "OrchestrationMode"), "field is immutable")
+		}
+
+		return nil
+	}
+}
+
+// ValidateZoneBalance validates that zone balancing is only enabled for Uniform scale sets and that neither it nor the
+// platform fault domain count, which Azure only sets when creating the VMSS, is changed.
+func (amp *AzureMachinePool) ValidateZoneBalance(old runtime.Object) func() error {
+	return func() error {
+		var allErrs field.ErrorList
+		if pointer.BoolDeref(amp.Spec.ZoneBalance, false) && amp.Spec.OrchestrationMode == infrav1.FlexibleOrchestrationMode {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("Spec", "ZoneBalance"), "zone balancing is not supported with the Flexible orchestration mode"))
+		}
+
+		if old != nil {
+			oldMachinePool, ok := old.(*AzureMachinePool)
+			if !ok {
+				return fmt.Errorf("unexpected type for old azure machine pool object. Expected: %q, Got: %q",
+					"AzureMachinePool", reflect.TypeOf(old))
+			}
+
+			if !reflect.DeepEqual(oldMachinePool.Spec.ZoneBalance, amp.Spec.ZoneBalance) {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("Spec", "ZoneBalance"), "field is immutable"))
+			}
+			if !reflect.DeepEqual(oldMachinePool.Spec.PlatformFaultDomainCount, amp.Spec.PlatformFaultDomainCount) {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("Spec", "PlatformFaultDomainCount"), "field is immutable"))
+			}
+		}
+
+		if len(allErrs) > 0 {
+			return kerrors.NewAggregate(allErrs.ToAggregate().Errors())
+		}
+
+		return nil
+	}
+}
+
+// orchestrationModeOrDefault returns the orchestration mode, treating an unset mode as Uniform.
+func orchestrationModeOrDefault(mode infrav1.OrchestrationModeType) infrav1.OrchestrationModeType {
+	if mode == "" {
+		return infrav1.UniformOrchestrationMode
+	}
+	return mode
+}
@@ -21,7 +21,9 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
 
@@ -42,8 +44,10 @@ func TestAzureMachinePool_ValidateCreate(t *testing.T) {
 	g := NewWithT(t)
 
 	var (
-		zero = intstr.FromInt(0)
-		one  = intstr.FromInt(1)
+		zero           = intstr.FromInt(0)
+		one            = intstr.FromInt(1)
+		tenPercent     = intstr.FromString("10%")
+		invalidPercent = intstr.FromString("ten")
 	)
 
 	tests := []struct {
@@ -138,6 +142,179 @@ func TestAzureMachinePool_ValidateCreate(t *testing.T) {
 			}),
 			wantErr: false,
 		},
+		{
+			name: "azuremachinepool with a canary rollout",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				Canary: &AzureMachinePoolCanary{
+					Replicas: &tenPercent,
+					HealthGate: &AzureMachinePoolHealthGate{
+						StableMinutes: 10,
+					},
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with zero canary replicas",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				Canary: &AzureMachinePoolCanary{
+					Replicas: &zero,
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with invalid canary replicas percentage",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: RollingUpdateAzureMachinePoolDeploymentStrategyType,
+				Canary: &AzureMachinePoolCanary{
+					Replicas: &invalidPercent,
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with a reimage in place rollout",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: ReimageInPlaceAzureMachinePoolDeploymentStrategyType,
+				ReimageInPlace: &MachineReimageInPlaceDeployment{
+					MaxUnavailable: &tenPercent,
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with zero reimage in place MaxUnavailable",
+			amp: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+				Type: ReimageInPlaceAzureMachinePoolDeploymentStrategyType,
+				ReimageInPlace: &MachineReimageInPlaceDeployment{
+					MaxUnavailable: &zero,
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with a reimage in place rollout in Flexible orchestration mode",
+			amp: func() *AzureMachinePool {
+				amp := createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{
+					Type: ReimageInPlaceAzureMachinePoolDeploymentStrategyType,
+				})
+				amp.Spec.OrchestrationMode = infrav1.FlexibleOrchestrationMode
+				return amp
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with zone balance in Uniform orchestration mode",
+			amp:     createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, to.BoolPtr(true), to.Int32Ptr(1)),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with zone balance in Flexible orchestration mode",
+			amp:     createMachinePoolWithZoneBalance(infrav1.FlexibleOrchestrationMode, to.BoolPtr(true), nil),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with user-managed boot diagnostics",
+			amp: createMachinePoolWithDiagnostics(&infrav1.BootDiagnostics{
+				StorageAccountType: infrav1.UserManagedDiagnosticsStorage,
+				UserManaged: &infrav1.UserManagedBootDiagnostics{
+					StorageAccountURI: "https://fakestorage.blob.core.windows.net/",
+				},
+			}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with user-managed boot diagnostics, but without a storage account",
+			amp: createMachinePoolWithDiagnostics(&infrav1.BootDiagnostics{
+				StorageAccountType: infrav1.UserManagedDiagnosticsStorage,
+			}),
+			wantErr: true,
+		},
+		{
+			name: "azuremachinepool with a serial console log ConfigMap",
+			amp: createMachinePoolWithDiagnostics(&infrav1.BootDiagnostics{
+				StorageAccountType:            infrav1.ManagedDiagnosticsStorage,
+				SerialConsoleLogConfigMapName: "boot-logs",
+			}),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with a spot recovery policy",
+			amp:     createMachinePoolWithSpotVMOptions(infrav1.SpotEvictionPolicyDeallocate, infrav1.SpotRecoveryPolicyRestart),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with a spot recovery policy and the delete eviction policy",
+			amp:     createMachinePoolWithSpotVMOptions(infrav1.SpotEvictionPolicyDelete, infrav1.SpotRecoveryPolicyRestart),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with a spot capacity policy",
+			amp:     createMachinePoolWithSpotCapacityPolicy(true, 2, 50),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with a spot capacity policy, but without spot vm options",
+			amp:     createMachinePoolWithSpotCapacityPolicy(false, 2, 50),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with a negative base on-demand count",
+			amp:     createMachinePoolWithSpotCapacityPolicy(true, -1, 50),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with a spot percentage above 100",
+			amp:     createMachinePoolWithSpotCapacityPolicy(true, 0, 101),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with an http health probe",
+			amp:     createMachinePoolWithHealthProbe(HTTPApplicationHealthProbeProtocol, 10256, "/healthz", nil),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with an http health probe, but without a request path",
+			amp:     createMachinePoolWithHealthProbe(HTTPApplicationHealthProbeProtocol, 10256, "", nil),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with a tcp health probe and a request path",
+			amp:     createMachinePoolWithHealthProbe(TCPApplicationHealthProbeProtocol, 10250, "/healthz", nil),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with a health probe on an invalid port",
+			amp:     createMachinePoolWithHealthProbe(TCPApplicationHealthProbeProtocol, 0, "", nil),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with automatic repairs",
+			amp:     createMachinePoolWithHealthProbe(TCPApplicationHealthProbeProtocol, 10250, "", &metav1.Duration{Duration: 30 * time.Minute}),
+			wantErr: false,
+		},
+		{
+			name: "azuremachinepool with automatic repairs, but without a health probe",
+			amp: &AzureMachinePool{
+				Spec: AzureMachinePoolSpec{
+					AutomaticRepairsPolicy: &AutomaticRepairsPolicy{GracePeriod: &metav1.Duration{Duration: 30 * time.Minute}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with an automatic repairs grace period below 10 minutes",
+			amp:     createMachinePoolWithHealthProbe(TCPApplicationHealthProbeProtocol, 10250, "", &metav1.Duration{Duration: 5 * time.Minute}),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with an automatic repairs grace period which is not a whole number of minutes",
+			amp:     createMachinePoolWithHealthProbe(TCPApplicationHealthProbeProtocol, 10250, "", &metav1.Duration{Duration: 30*time.Minute + 30*time.Second}),
+			wantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -189,6 +366,24 @@ func TestAzureMachinePool_ValidateUpdate(t *testing.T) {
 			amp:     createMachinePoolWithSystemAssignedIdentity(string(uuid.NewUUID())),
 			wantErr: true,
 		},
+		{
+			name:    "azuremachinepool with role assignments unchanged",
+			oldAMP:  createMachinePoolWithRoleAssignments(infrav1.RoleAssignment{RoleDefinitionName: "Reader"}),
+			amp:     createMachinePoolWithRoleAssignments(infrav1.RoleAssignment{RoleDefinitionName: "Reader"}),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with role assignments changed",
+			oldAMP:  createMachinePoolWithRoleAssignments(infrav1.RoleAssignment{RoleDefinitionName: "Reader"}),
+			amp:     createMachinePoolWithRoleAssignments(infrav1.RoleAssignment{RoleDefinitionName: "Contributor"}),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with invalid role assignments",
+			oldAMP:  createMachinePoolWithRoleAssignments(infrav1.RoleAssignment{RoleDefinitionID: "acdd72a7-3385-48ef-bd42-f606fba81ae7", RoleDefinitionName: "Reader"}),
+			amp:     createMachinePoolWithRoleAssignments(infrav1.RoleAssignment{RoleDefinitionID: "acdd72a7-3385-48ef-bd42-f606fba81ae7", RoleDefinitionName: "Reader"}),
+			wantErr: true,
+		},
 		{
 			name:   "azuremachinepool with invalid MaxSurge and MaxUnavailable rolling upgrade configuration",
 			oldAMP: createMachinePoolWithStrategy(AzureMachinePoolDeploymentStrategy{}),
@@ -213,6 +408,48 @@ func TestAzureMachinePool_ValidateUpdate(t *testing.T) {
 			}),
 			wantErr: false,
 		},
+		{
+			name:    "azuremachinepool with orchestration mode unchanged",
+			oldAMP:  createMachinePoolWithOrchestrationMode(""),
+			amp:     createMachinePoolWithOrchestrationMode(infrav1.UniformOrchestrationMode),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with orchestration mode changed",
+			oldAMP:  createMachinePoolWithOrchestrationMode(infrav1.UniformOrchestrationMode),
+			amp:     createMachinePoolWithOrchestrationMode(infrav1.FlexibleOrchestrationMode),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with zone balance and platform fault domain count unchanged",
+			oldAMP:  createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, to.BoolPtr(true), to.Int32Ptr(2)),
+			amp:     createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, to.BoolPtr(true), to.Int32Ptr(2)),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with zone balance changed",
+			oldAMP:  createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, nil, nil),
+			amp:     createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, to.BoolPtr(true), nil),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with platform fault domain count changed",
+			oldAMP:  createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, nil, to.Int32Ptr(1)),
+			amp:     createMachinePoolWithZoneBalance(infrav1.UniformOrchestrationMode, nil, to.Int32Ptr(3)),
+			wantErr: true,
+		},
+		{
+			name:    "azuremachinepool with spot capacity policy changed",
+			oldAMP:  createMachinePoolWithSpotCapacityPolicy(true, 2, 50),
+			amp:     createMachinePoolWithSpotCapacityPolicy(true, 0, 100),
+			wantErr: false,
+		},
+		{
+			name:    "azuremachinepool with spot capacity policy removed",
+			oldAMP:  createMachinePoolWithSpotCapacityPolicy(true, 2, 50),
+			amp:     createMachinePoolWithSpotVMOptions(infrav1.SpotEvictionPolicyDeallocate, infrav1.SpotRecoveryPolicyRestart),
+			wantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -322,6 +559,62 @@ func createMachinePoolWithImageByID(imageID string, terminateNotificationTimeout
 	}
 }
 
+func createMachinePoolWithDiagnostics(boot *infrav1.BootDiagnostics) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			Template: AzureMachinePoolMachineTemplate{
+				Diagnostics: &infrav1.Diagnostics{
+					Boot: boot,
+				},
+			},
+		},
+	}
+}
+
+func createMachinePoolWithSpotVMOptions(evictionPolicy infrav1.SpotEvictionPolicy, recoveryPolicy infrav1.SpotRecoveryPolicy) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			Template: AzureMachinePoolMachineTemplate{
+				SpotVMOptions: &infrav1.SpotVMOptions{
+					EvictionPolicy: &evictionPolicy,
+					RecoveryPolicy: &recoveryPolicy,
+				},
+			},
+		},
+	}
+}
+
+func createMachinePoolWithSpotCapacityPolicy(spot bool, baseOnDemandCount, spotPercentageAboveBase int32) *AzureMachinePool {
+	amp := &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			SpotCapacityPolicy: &SpotCapacityPolicy{
+				BaseOnDemandCount:       &baseOnDemandCount,
+				SpotPercentageAboveBase: &spotPercentageAboveBase,
+			},
+		},
+	}
+	if spot {
+		amp.Spec.Template.SpotVMOptions = &infrav1.SpotVMOptions{}
+	}
+	return amp
+}
+
+func createMachinePoolWithHealthProbe(protocol ApplicationHealthProbeProtocol, port int32, requestPath string, gracePeriod *metav1.Duration) *AzureMachinePool {
+	amp := &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			HealthProbe: &ApplicationHealthProbe{
+				Protocol:    protocol,
+				Port:        port,
+				RequestPath: requestPath,
+			},
+		},
+	}
+	if gracePeriod != nil {
+		amp.Spec.AutomaticRepairsPolicy = &AutomaticRepairsPolicy{GracePeriod: gracePeriod}
+	}
+	return amp
+}
+
 func createMachinePoolWithSystemAssignedIdentity(role string) *AzureMachinePool {
 	return &AzureMachinePool{
 		Spec: AzureMachinePoolSpec{
@@ -331,6 +624,16 @@ func createMachinePoolWithSystemAssignedIdentity(role string) *AzureMachinePool
 	}
 }
 
+func createMachinePoolWithRoleAssignments(roleAssignments ...infrav1.RoleAssignment) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			Identity:           infrav1.VMIdentitySystemAssigned,
+			RoleAssignmentName: "30a757d8-fcf0-4c8b-acf0-9253a7e093ea",
+			RoleAssignments:    roleAssignments,
+		},
+	}
+}
+
 func createMachinePoolWithUserAssignedIdentity(providerIds []string) *AzureMachinePool {
 	userAssignedIdentities := make([]infrav1.UserAssignedIdentity, len(providerIds))
 
@@ -364,3 +667,21 @@ func createMachinePoolWithStrategy(strategy AzureMachinePoolDeploymentStrategy)
 		},
 	}
 }
+
+func createMachinePoolWithZoneBalance(mode infrav1.OrchestrationModeType, zoneBalance *bool, platformFaultDomainCount *int32) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			OrchestrationMode:        mode,
+			ZoneBalance:              zoneBalance,
+			PlatformFaultDomainCount: platformFaultDomainCount,
+		},
+	}
+}
+
+func createMachinePoolWithOrchestrationMode(mode infrav1.OrchestrationModeType) *AzureMachinePool {
+	return &AzureMachinePool{
+		Spec: AzureMachinePoolSpec{
+			OrchestrationMode: mode,
+		},
+	}
+}
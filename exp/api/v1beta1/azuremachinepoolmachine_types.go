@@ -28,6 +28,31 @@ import (
 const (
 	// AzureMachinePoolMachineFinalizer is used to ensure deletion of dependencies (nodes, infra).
 	AzureMachinePoolMachineFinalizer = "azuremachinepoolmachine.infrastructure.cluster.x-k8s.io"
+
+	// ReimageMachineAnnotation requests the instance of an AzureMachinePoolMachine to be reimaged in place with the
+	// latest model. The AzureMachinePool sets it when its deployment strategy is ReimageInPlace, and it is removed once
+	// the node of the reimaged instance is Ready again.
+	ReimageMachineAnnotation = "azuremachinepoolmachine.infrastructure.cluster.x-k8s.io/reimage"
+
+	// ProtectFromScaleInAnnotation protects the instance of an AzureMachinePoolMachine from scale in. The instance is
+	// neither selected for deletion when the AzureMachinePool scales in or rolls out a new model, nor removed by Azure
+	// when the capacity of its scale set is lowered.
+	ProtectFromScaleInAnnotation = "azuremachinepoolmachine.infrastructure.cluster.x-k8s.io/protect-from-scale-in"
+
+	// ProtectFromScaleSetActionsAnnotation protects the instance of an AzureMachinePoolMachine from all the actions of
+	// its scale set. In addition to the protection from scale in, the instance is not updated to a new model, neither
+	// by a rollout of the AzureMachinePool nor by Azure.
+	ProtectFromScaleSetActionsAnnotation = "azuremachinepoolmachine.infrastructure.cluster.x-k8s.io/protect-from-scale-set-actions"
+
+	// ScheduledEventNodeCondition is the condition of a Node reporting an Azure scheduled event pending for its
+	// instance. It is set by the scheduled events handler running on the node, with the type of the event as its reason
+	// and the ID of the event as its message, and is True until the event completed.
+	ScheduledEventNodeCondition corev1.NodeConditionType = "ScheduledEvent"
+
+	// ApprovedScheduledEventAnnotation is set on a Node to the ID of the scheduled event reported by its
+	// ScheduledEventNodeCondition once the node was drained. The scheduled events handler running on the node then
+	// approves the event so that it starts without waiting for the rest of the notification window.
+	ApprovedScheduledEventAnnotation = "azuremachinepoolmachine.infrastructure.cluster.x-k8s.io/approved-scheduled-event"
 )
 
 type (
@@ -96,6 +121,14 @@ type (
 		// Ready is true when the provider resource is ready.
 		// +optional
 		Ready bool `json:"ready"`
+
+		// Cordoned is true when the node backing the instance has been marked unschedulable.
+		// +optional
+		Cordoned bool `json:"cordoned,omitempty"`
+
+		// AvailabilityZone is the availability zone the instance is running in.
+		// +optional
+		AvailabilityZone string `json:"availabilityZone,omitempty"`
 	}
 
 	// +kubebuilder:object:root=true
@@ -257,6 +257,7 @@ func (r *AzureManagedControlPlane) Validate() error {
 		r.validateSSHKey,
 		r.validateLoadBalancerProfile,
 		r.validateAPIServerAccessProfile,
+		r.validateIdentityRef,
 	}
 
 	var errs []error
@@ -288,6 +289,15 @@ func (r *AzureManagedControlPlane) validateVersion() error {
 	return nil
 }
 
+// validateIdentityRef validates the reference to the identity of the control plane.
+func (r *AzureManagedControlPlane) validateIdentityRef() error {
+	if errs := infrav1.ValidateIdentityRef(r.Spec.IdentityRef, r.Namespace, field.NewPath("Spec", "IdentityRef")); len(errs) > 0 {
+		return kerrors.NewAggregate(errs.ToAggregate().Errors())
+	}
+
+	return nil
+}
+
 // ValidateSSHKey validates an SSHKey.
 func (r *AzureManagedControlPlane) validateSSHKey() error {
 	if r.Spec.SSHPublicKey != "" {
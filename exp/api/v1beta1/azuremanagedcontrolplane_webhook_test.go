@@ -22,8 +22,11 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
+
+	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 )
 
 func TestDefaultingWebhook(t *testing.T) {
@@ -301,6 +304,25 @@ func TestAzureManagedControlPlane_ValidateCreate(t *testing.T) {
 			wantErr:  true,
 			errorLen: 1,
 		},
+		{
+			name: "namespaced identity in the same namespace",
+			amcp: createAzureManagedControlPlaneWithIdentityRef(&corev1.ObjectReference{
+				Kind:      infrav1.AzureNamespacedIdentityKind,
+				Name:      "tenant-identity",
+				Namespace: "tenant",
+			}),
+			wantErr: false,
+		},
+		{
+			name: "namespaced identity in another namespace",
+			amcp: createAzureManagedControlPlaneWithIdentityRef(&corev1.ObjectReference{
+				Kind:      infrav1.AzureNamespacedIdentityKind,
+				Name:      "tenant-identity",
+				Namespace: "other-tenant",
+			}),
+			wantErr:  true,
+			errorLen: 1,
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -746,3 +768,10 @@ func createAzureManagedControlPlane(serviceIP, version, sshKey string) *AzureMan
 		},
 	}
 }
+
+func createAzureManagedControlPlaneWithIdentityRef(ref *corev1.ObjectReference) *AzureManagedControlPlane {
+	amcp := createAzureManagedControlPlane("192.168.0.0", "v1.18.0", generateSSHPublicKey(true))
+	amcp.Namespace = "tenant"
+	amcp.Spec.IdentityRef = ref
+	return amcp
+}
@@ -86,6 +86,41 @@ func (in *APIServerAccessProfile) DeepCopy() *APIServerAccessProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationHealthProbe) DeepCopyInto(out *ApplicationHealthProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationHealthProbe.
+func (in *ApplicationHealthProbe) DeepCopy() *ApplicationHealthProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationHealthProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutomaticRepairsPolicy) DeepCopyInto(out *AutomaticRepairsPolicy) {
+	*out = *in
+	if in.GracePeriod != nil {
+		in, out := &in.GracePeriod, &out.GracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutomaticRepairsPolicy.
+func (in *AutomaticRepairsPolicy) DeepCopy() *AutomaticRepairsPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutomaticRepairsPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachinePool) DeepCopyInto(out *AzureMachinePool) {
 	*out = *in
@@ -113,6 +148,31 @@ func (in *AzureMachinePool) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachinePoolCanary) DeepCopyInto(out *AzureMachinePoolCanary) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.HealthGate != nil {
+		in, out := &in.HealthGate, &out.HealthGate
+		*out = new(AzureMachinePoolHealthGate)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolCanary.
+func (in *AzureMachinePoolCanary) DeepCopy() *AzureMachinePoolCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachinePoolCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachinePoolDeploymentStrategy) DeepCopyInto(out *AzureMachinePoolDeploymentStrategy) {
 	*out = *in
@@ -121,6 +181,16 @@ func (in *AzureMachinePoolDeploymentStrategy) DeepCopyInto(out *AzureMachinePool
 		*out = new(MachineRollingUpdateDeployment)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReimageInPlace != nil {
+		in, out := &in.ReimageInPlace, &out.ReimageInPlace
+		*out = new(MachineReimageInPlaceDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(AzureMachinePoolCanary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolDeploymentStrategy.
@@ -133,6 +203,21 @@ func (in *AzureMachinePoolDeploymentStrategy) DeepCopy() *AzureMachinePoolDeploy
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachinePoolHealthGate) DeepCopyInto(out *AzureMachinePoolHealthGate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolHealthGate.
+func (in *AzureMachinePoolHealthGate) DeepCopy() *AzureMachinePoolHealthGate {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachinePoolHealthGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachinePoolInstanceStatus) DeepCopyInto(out *AzureMachinePoolInstanceStatus) {
 	*out = *in
@@ -337,6 +422,11 @@ func (in *AzureMachinePoolMachineTemplate) DeepCopyInto(out *AzureMachinePoolMac
 		*out = new(apiv1beta1.SecurityProfile)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(apiv1beta1.Diagnostics)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SpotVMOptions != nil {
 		in, out := &in.SpotVMOptions, &out.SpotVMOptions
 		*out = new(apiv1beta1.SpotVMOptions)
@@ -354,6 +444,44 @@ func (in *AzureMachinePoolMachineTemplate) DeepCopy() *AzureMachinePoolMachineTe
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachinePoolProtectedInstance) DeepCopyInto(out *AzureMachinePoolProtectedInstance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolProtectedInstance.
+func (in *AzureMachinePoolProtectedInstance) DeepCopy() *AzureMachinePoolProtectedInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachinePoolProtectedInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachinePoolRolloutStatus) DeepCopyInto(out *AzureMachinePoolRolloutStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CanaryHealthySince != nil {
+		in, out := &in.CanaryHealthySince, &out.CanaryHealthySince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolRolloutStatus.
+func (in *AzureMachinePoolRolloutStatus) DeepCopy() *AzureMachinePoolRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachinePoolRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureMachinePoolSpec) DeepCopyInto(out *AzureMachinePoolSpec) {
 	*out = *in
@@ -375,12 +503,42 @@ func (in *AzureMachinePoolSpec) DeepCopyInto(out *AzureMachinePoolSpec) {
 		*out = make([]apiv1beta1.UserAssignedIdentity, len(*in))
 		copy(*out, *in)
 	}
+	if in.RoleAssignments != nil {
+		in, out := &in.RoleAssignments, &out.RoleAssignments
+		*out = make([]apiv1beta1.RoleAssignment, len(*in))
+		copy(*out, *in)
+	}
 	in.Strategy.DeepCopyInto(&out.Strategy)
 	if in.NodeDrainTimeout != nil {
 		in, out := &in.NodeDrainTimeout, &out.NodeDrainTimeout
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.SpotCapacityPolicy != nil {
+		in, out := &in.SpotCapacityPolicy, &out.SpotCapacityPolicy
+		*out = new(SpotCapacityPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthProbe != nil {
+		in, out := &in.HealthProbe, &out.HealthProbe
+		*out = new(ApplicationHealthProbe)
+		**out = **in
+	}
+	if in.AutomaticRepairsPolicy != nil {
+		in, out := &in.AutomaticRepairsPolicy, &out.AutomaticRepairsPolicy
+		*out = new(AutomaticRepairsPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ZoneBalance != nil {
+		in, out := &in.ZoneBalance, &out.ZoneBalance
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PlatformFaultDomainCount != nil {
+		in, out := &in.PlatformFaultDomainCount, &out.PlatformFaultDomainCount
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolSpec.
@@ -417,6 +575,32 @@ func (in *AzureMachinePoolStatus) DeepCopyInto(out *AzureMachinePoolStatus) {
 		*out = new(apiv1beta1.ProvisioningState)
 		**out = **in
 	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeTaints != nil {
+		in, out := &in.NodeTaints, &out.NodeTaints
+		*out = make([]corev1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RoleAssignmentIDs != nil {
+		in, out := &in.RoleAssignmentIDs, &out.RoleAssignmentIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(errors.MachineStatusError)
@@ -439,6 +623,25 @@ func (in *AzureMachinePoolStatus) DeepCopyInto(out *AzureMachinePoolStatus) {
 		*out = make(apiv1beta1.Futures, len(*in))
 		copy(*out, *in)
 	}
+	if in.SpotFallbackTime != nil {
+		in, out := &in.SpotFallbackTime, &out.SpotFallbackTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(AzureMachinePoolRolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProtectedInstances != nil {
+		in, out := &in.ProtectedInstances, &out.ProtectedInstances
+		*out = make([]AzureMachinePoolProtectedInstance, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zones != nil {
+		in, out := &in.Zones, &out.Zones
+		*out = make([]AzureMachinePoolZoneStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolStatus.
@@ -451,6 +654,21 @@ func (in *AzureMachinePoolStatus) DeepCopy() *AzureMachinePoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMachinePoolZoneStatus) DeepCopyInto(out *AzureMachinePoolZoneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMachinePoolZoneStatus.
+func (in *AzureMachinePoolZoneStatus) DeepCopy() *AzureMachinePoolZoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMachinePoolZoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AzureManagedCluster) DeepCopyInto(out *AzureManagedCluster) {
 	*out = *in
@@ -889,6 +1107,26 @@ func (in *LoadBalancerProfile) DeepCopy() *LoadBalancerProfile {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineReimageInPlaceDeployment) DeepCopyInto(out *MachineReimageInPlaceDeployment) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineReimageInPlaceDeployment.
+func (in *MachineReimageInPlaceDeployment) DeepCopy() *MachineReimageInPlaceDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineReimageInPlaceDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineRollingUpdateDeployment) DeepCopyInto(out *MachineRollingUpdateDeployment) {
 	*out = *in
@@ -984,3 +1222,28 @@ func (in *SKU) DeepCopy() *SKU {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpotCapacityPolicy) DeepCopyInto(out *SpotCapacityPolicy) {
+	*out = *in
+	if in.BaseOnDemandCount != nil {
+		in, out := &in.BaseOnDemandCount, &out.BaseOnDemandCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SpotPercentageAboveBase != nil {
+		in, out := &in.SpotPercentageAboveBase, &out.SpotPercentageAboveBase
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpotCapacityPolicy.
+func (in *SpotCapacityPolicy) DeepCopy() *SpotCapacityPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SpotCapacityPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
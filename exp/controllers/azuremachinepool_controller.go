@@ -153,6 +153,7 @@ func (ampr *AzureMachinePoolReconciler) SetupWithManager(ctx context.Context, mg
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets;,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=bootstrap.cluster.x-k8s.io,resources=kubeadmconfigs,verbs=get;list;watch
 
 // Reconcile idempotently gets, creates, and updates a machine pool.
 func (ampr *AzureMachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
@@ -232,6 +233,7 @@ func (ampr *AzureMachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.
 		MachinePool:      machinePool,
 		AzureMachinePool: azMachinePool,
 		ClusterScope:     clusterScope,
+		KeyVaultScope:    clusterScope,
 	})
 	if err != nil {
 		return reconcile.Result{}, errors.Errorf("failed to create scope: %+v", err)
@@ -282,12 +284,24 @@ func (ampr *AzureMachinePoolReconciler) reconcileNormal(ctx context.Context, mac
 		return reconcile.Result{}, nil
 	}
 
+	// The scale set is created once the identity of the AzureMachinePool allows it to fetch its secrets.
+	if err := machinePoolScope.ValidateKeyVaultIdentity(); err != nil {
+		log.Error(err, "Invalid identity for the Key Vault of the cluster")
+		ampr.Recorder.Eventf(machinePoolScope.AzureMachinePool, corev1.EventTypeWarning, "InvalidIdentity", err.Error())
+		return reconcile.Result{}, nil
+	}
+
 	ams, err := ampr.createAzureMachinePoolService(machinePoolScope)
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed creating a newAzureMachinePoolService")
 	}
 
-	if err := ams.Reconcile(ctx); err != nil {
+	err = ams.Reconcile(ctx)
+	if machinePoolScope.FellBackToOnDemand() {
+		ampr.Recorder.Eventf(machinePoolScope.AzureMachinePool, corev1.EventTypeWarning, "SpotCapacityFallback", "Spot capacity is unavailable, falling back to on-demand instances")
+	}
+
+	if err != nil {
 		// Handle transient and terminal errors
 		var reconcileError azure.ReconcileError
 		if errors.As(err, &reconcileError) {
@@ -70,6 +70,16 @@ func (s *azureMachinePoolService) Reconcile(ctx context.Context) error {
 		return errors.Wrap(err, "failed to create scale set")
 	}
 
+	vmSize := s.scope.AzureMachinePool.Spec.Template.VMSize
+	sku, err := s.skuCache.Get(ctx, vmSize, resourceskus.VirtualMachines)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get SKU %s", vmSize)
+	}
+
+	if err := s.scope.SetNodeCapacity(ctx, sku); err != nil {
+		return errors.Wrap(err, "failed to set node capacity")
+	}
+
 	if err := s.roleAssignmentsSvc.Reconcile(ctx); err != nil {
 		return errors.Wrap(err, "unable to create role assignment")
 	}
@@ -89,5 +99,9 @@ func (s *azureMachinePoolService) Delete(ctx context.Context) error {
 	if err := s.virtualMachinesScaleSetSvc.Delete(ctx); err != nil {
 		return errors.Wrap(err, "failed to delete scale set")
 	}
+
+	if err := s.roleAssignmentsSvc.Delete(ctx); err != nil {
+		return errors.Wrap(err, "failed to delete role assignments")
+	}
 	return nil
 }
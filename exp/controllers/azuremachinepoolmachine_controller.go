@@ -30,6 +30,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scalesetvms"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scheduledevents"
 	infracontroller "sigs.k8s.io/cluster-api-provider-azure/controllers"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/pkg/coalescing"
@@ -47,6 +48,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+// scheduledEventsPollInterval is how often the node of a running instance is checked for scheduled events reported by
+// the handler running on it when terminate notifications are enabled. Only the node is read from the workload cluster,
+// so it is checked often enough to drain a node ahead of the shortest notification window, a Spot preemption.
+const scheduledEventsPollInterval = 10 * time.Second
+
 type (
 	azureMachinePoolMachineReconcilerFactory func(*scope.MachinePoolMachineScope) azure.Reconciler
 
@@ -61,8 +67,9 @@ type (
 	}
 
 	azureMachinePoolMachineReconciler struct {
-		Scope              *scope.MachinePoolMachineScope
-		scalesetVMsService *scalesetvms.Service
+		Scope                  *scope.MachinePoolMachineScope
+		scalesetVMsService     *scalesetvms.Service
+		scheduledEventsService *scheduledevents.Service
 	}
 )
 
@@ -215,6 +222,7 @@ func (ampmr *AzureMachinePoolMachineController) Reconcile(ctx context.Context, r
 		AzureMachinePool:        azureMachinePool,
 		AzureMachinePoolMachine: machine,
 		ClusterScope:            clusterScope,
+		KeyVaultScope:           clusterScope,
 	})
 	if err != nil {
 		return reconcile.Result{}, errors.Errorf("failed to create scope: %+v", err)
@@ -254,6 +262,10 @@ func (ampmr *AzureMachinePoolMachineController) reconcileNormal(ctx context.Cont
 
 	ampms := ampmr.reconcilerFactory(machineScope)
 	if err := ampms.Reconcile(ctx); err != nil {
+		if errors.Is(err, scalesetvms.ErrInstanceRemoved) {
+			return ampmr.reconcileRemovedInstance(ctx, machineScope)
+		}
+
 		// Handle transient and terminal errors
 		var reconcileError azure.ReconcileError
 		if errors.As(err, &reconcileError) {
@@ -295,6 +307,36 @@ func (ampmr *AzureMachinePoolMachineController) reconcileNormal(ctx context.Cont
 		}, nil
 	}
 
+	if machineScope.ScheduledEventsEnabled() {
+		return reconcile.Result{
+			RequeueAfter: scheduledEventsPollInterval,
+		}, nil
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// reconcileRemovedInstance deletes an AzureMachinePoolMachine whose instance no longer exists in the scale set. Automatic
+// repairs replace an unhealthy instance with a new one, which has a different instance ID and gets its own
+// AzureMachinePoolMachine, so the stale one is deleted right away to keep the provider IDs and replicas of the machine
+// pool accurate.
+func (ampmr *AzureMachinePoolMachineController) reconcileRemovedInstance(ctx context.Context, machineScope *scope.MachinePoolMachineScope) (reconcile.Result, error) {
+	ctx, log, done := tele.StartSpanWithLogger(ctx, "controllers.AzureMachinePoolMachineController.reconcileRemovedInstance")
+	defer done()
+
+	if machineScope.AzureMachinePool.Spec.AutomaticRepairsPolicy != nil {
+		ampmr.Recorder.Eventf(machineScope.AzureMachinePoolMachine, corev1.EventTypeNormal, "InstanceRepaired",
+			"Azure scale set instance %s was replaced by an automatic repair", machineScope.InstanceID())
+	} else {
+		ampmr.Recorder.Eventf(machineScope.AzureMachinePoolMachine, corev1.EventTypeWarning, "InstanceRemoved",
+			"Azure scale set instance %s no longer exists", machineScope.InstanceID())
+	}
+
+	log.V(2).Info("deleting AzureMachinePoolMachine of removed instance", "instanceID", machineScope.InstanceID())
+	if err := ampmr.Client.Delete(ctx, machineScope.AzureMachinePoolMachine); err != nil {
+		return reconcile.Result{}, errors.Wrap(err, "machine pool machine failed to be deleted after its instance was removed")
+	}
+
 	return reconcile.Result{}, nil
 }
 
@@ -341,8 +383,9 @@ func (ampmr *AzureMachinePoolMachineController) reconcileDelete(ctx context.Cont
 
 func newAzureMachinePoolMachineReconciler(scope *scope.MachinePoolMachineScope) azure.Reconciler {
 	return &azureMachinePoolMachineReconciler{
-		Scope:              scope,
-		scalesetVMsService: scalesetvms.NewService(scope),
+		Scope:                  scope,
+		scalesetVMsService:     scalesetvms.NewService(scope),
+		scheduledEventsService: scheduledevents.NewService(scope),
 	}
 }
 
@@ -359,6 +402,10 @@ func (r *azureMachinePoolMachineReconciler) Reconcile(ctx context.Context) error
 		return errors.Wrap(err, "failed to update vmss vm status")
 	}
 
+	if err := r.scheduledEventsService.Reconcile(ctx); err != nil {
+		return errors.Wrap(err, "failed to reconcile scheduled events")
+	}
+
 	return nil
 }
 
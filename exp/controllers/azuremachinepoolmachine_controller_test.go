@@ -26,19 +26,24 @@ import (
 
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	infrav1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	"sigs.k8s.io/cluster-api-provider-azure/azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/mock_azure"
 	"sigs.k8s.io/cluster-api-provider-azure/azure/scope"
+	"sigs.k8s.io/cluster-api-provider-azure/azure/services/scalesetvms"
 	infrav1exp "sigs.k8s.io/cluster-api-provider-azure/exp/api/v1beta1"
 	gomock2 "sigs.k8s.io/cluster-api-provider-azure/internal/test/matchers/gomock"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterv1exp "sigs.k8s.io/cluster-api/exp/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -46,7 +51,7 @@ func TestAzureMachinePoolMachineReconciler_Reconcile(t *testing.T) {
 	cases := []struct {
 		Name   string
 		Setup  func(cb *fake.ClientBuilder, reconciler *mock_azure.MockReconcilerMockRecorder)
-		Verify func(g *WithT, result ctrl.Result, err error)
+		Verify func(g *WithT, c client.Client, result ctrl.Result, err error)
 	}{
 		{
 			Name: "should successfully reconcile",
@@ -55,10 +60,27 @@ func TestAzureMachinePoolMachineReconciler_Reconcile(t *testing.T) {
 				reconciler.Reconcile(gomock2.AContext()).Return(nil)
 				cb.WithObjects(cluster, azCluster, mp, amp, ampm)
 			},
-			Verify: func(g *WithT, result ctrl.Result, err error) {
+			Verify: func(g *WithT, c client.Client, result ctrl.Result, err error) {
 				g.Expect(err).NotTo(HaveOccurred())
 			},
 		},
+		{
+			Name: "should delete the machine when its instance was replaced by an automatic repair",
+			Setup: func(cb *fake.ClientBuilder, reconciler *mock_azure.MockReconcilerMockRecorder) {
+				cluster, azCluster, mp, amp, ampm := getAReadyMachinePoolMachineCluster()
+				amp.Spec.HealthProbe = &infrav1exp.ApplicationHealthProbe{Protocol: infrav1exp.TCPApplicationHealthProbeProtocol, Port: 10250}
+				amp.Spec.AutomaticRepairsPolicy = &infrav1exp.AutomaticRepairsPolicy{GracePeriod: &metav1.Duration{Duration: 30 * time.Minute}}
+				reconciler.Reconcile(gomock2.AContext()).Return(errors.Wrap(scalesetvms.ErrInstanceRemoved, "failed to reconcile scalesetVMs"))
+				cb.WithObjects(cluster, azCluster, mp, amp, ampm)
+			},
+			Verify: func(g *WithT, c client.Client, result ctrl.Result, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.RequeueAfter).To(BeZero())
+				ampm := &infrav1exp.AzureMachinePoolMachine{}
+				err = c.Get(context.TODO(), types.NamespacedName{Name: "ampm1", Namespace: "default"}, ampm)
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			},
+		},
 		{
 			Name: "should successfully delete",
 			Setup: func(cb *fake.ClientBuilder, reconciler *mock_azure.MockReconcilerMockRecorder) {
@@ -69,7 +91,7 @@ func TestAzureMachinePoolMachineReconciler_Reconcile(t *testing.T) {
 				reconciler.Delete(gomock2.AContext()).Return(nil)
 				cb.WithObjects(cluster, azCluster, mp, amp, ampm)
 			},
-			Verify: func(g *WithT, result ctrl.Result, err error) {
+			Verify: func(g *WithT, c client.Client, result ctrl.Result, err error) {
 				g.Expect(err).NotTo(HaveOccurred())
 			},
 		},
@@ -104,7 +126,8 @@ func TestAzureMachinePoolMachineReconciler_Reconcile(t *testing.T) {
 			defer mockCtrl.Finish()
 
 			c.Setup(cb, reconciler.EXPECT())
-			controller := NewAzureMachinePoolMachineController(cb.Build(), nil, 30*time.Second, "foo")
+			cl := cb.Build()
+			controller := NewAzureMachinePoolMachineController(cl, record.NewFakeRecorder(10), 30*time.Second, "foo")
 			controller.reconcilerFactory = func(_ *scope.MachinePoolMachineScope) azure.Reconciler {
 				return reconciler
 			}
@@ -114,7 +137,7 @@ func TestAzureMachinePoolMachineReconciler_Reconcile(t *testing.T) {
 					Namespace: "default",
 				},
 			})
-			c.Verify(g, res, err)
+			c.Verify(g, cl, res, err)
 		})
 	}
 }